@@ -5,10 +5,20 @@ import (
 )
 
 var (
-	errNoMultiaddr      = errors.New("must provide peer's multiaddress with --multiaddr")
-	errNoMinAmount      = errors.New("must provide non-zero --min-amount")
-	errNoMaxAmount      = errors.New("must provide non-zero --max-amount")
-	errNoExchangeRate   = errors.New("must provide non-zero --exchange-rate")
-	errNoOfferID        = errors.New("must provide --offer-id")
-	errNoProvidesAmount = errors.New("must provide --provides-amount")
+	errNoMultiaddr       = errors.New("must provide peer's multiaddress with --multiaddr")
+	errNoMinAmount       = errors.New("must provide non-zero --min-amount")
+	errNoMaxAmount       = errors.New("must provide non-zero --max-amount")
+	errNoExchangeRate    = errors.New("must provide non-zero --exchange-rate, or --pegged")
+	errNoOfferID         = errors.New("must provide --offer-id")
+	errNoOfferIDs        = errors.New("must provide --offer-ids, or --all")
+	errNoSwapID          = errors.New("must provide the ID of the swap to watch")
+	errNoInfoFile        = errors.New("must provide --infofile or --instructions")
+	errInvalidBundlePath = errors.New("--out must end in .bundle")
+	errNoProvidesAmount  = errors.New("must provide --provides-amount")
+	errNoToken           = errors.New("must provide the offer's --token")
+	errInvalidSpeedTier  = errors.New("--speed-tiers must be of the form name:xmr-confirmations:timeout,...")
+	errNoPriority        = errors.New("must provide --priority")
+	errNoPeerIDOrIP      = errors.New("must provide one of --peer-id or --ip")
+	errNoAdminTokenFile  = errors.New("must provide --admin-token-file")
+	errNoBackupName      = errors.New("must provide --name")
 )
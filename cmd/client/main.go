@@ -2,10 +2,20 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"math/big"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/txsender"
+	"github.com/noot/atomic-swap/rpc"
 	"github.com/noot/atomic-swap/rpcclient"
 	"github.com/noot/atomic-swap/rpcclient/wsclient"
 
@@ -14,15 +24,21 @@ import (
 )
 
 const (
-	defaultSwapdAddress = "http://localhost:5001"
+	defaultSwapdAddress   = "http://localhost:5001"
+	defaultSwapdWSAddress = "ws://localhost:6005"
 )
 
 var log = logging.Logger("cmd")
 
+// tlsConfig is set from --tls-ca, to connect to swapd over TLS with its certificate authority
+// pinned; if it's nil, HTTPS and WSS endpoints are verified with the system's authorities
+var tlsConfig *tls.Config
+
 var (
 	app = &cli.App{
-		Name:  "swapcli",
-		Usage: "Client for swapd",
+		Name:   "swapcli",
+		Usage:  "Client for swapd",
+		Before: loadTLSConfig,
 		Commands: []cli.Command{
 			{
 				Name:    "addresses",
@@ -81,6 +97,46 @@ var (
 						Name:  "exchange-rate",
 						Usage: "desired exchange rate of XMR:ETH, eg. --exchange-rate=0.1 means 10XMR = 1ETH",
 					},
+					&cli.BoolFlag{
+						Name:  "pegged",
+						Usage: "peg the offer's exchange rate to the daemon's price oracle instead of setting --exchange-rate",
+					},
+					&cli.Float64Flag{
+						Name:  "spread",
+						Usage: "fraction above the index rate that a pegged offer's rate is set to, eg. --spread=0.01 is 1% above; for an offer that isn't pegged, it's only advertised", //nolint:lll
+					},
+					&cli.Float64Flag{
+						Name:  "min-taker-amount",
+						Usage: "minimum amount of ETH a taker may provide",
+					},
+					&cli.DurationFlag{
+						Name:  "ttl",
+						Usage: "how long the offer is listed for before it expires, eg. --ttl=1h; defaults to swapd's --offer-ttl",
+					},
+					&cli.UintFlag{
+						Name:  "required-confirmations",
+						Usage: "number of confirmations to wait for after the taker locks their ETH, if more than the daemon's default",
+					},
+					&cli.StringFlag{
+						Name:  "speed-tiers",
+						Usage: "comma-separated settlement speed tiers of the form name:xmr-confirmations:timeout, eg. --speed-tiers=fast:5:10m,cheap:10:1h", //nolint:lll
+					},
+					&cli.BoolFlag{
+						Name:  "one-shot",
+						Usage: "don't list the offer again if a swap taking it is aborted or refunded",
+					},
+					&cli.DurationFlag{
+						Name:  "relist-cooldown",
+						Usage: "how long to wait before listing the offer again after a swap taking it fails",
+					},
+					&cli.StringFlag{
+						Name:  "allow-takers",
+						Usage: "comma-separated peer IDs that are the only ones allowed to take the offer",
+					},
+					&cli.StringFlag{
+						Name:  "deny-takers",
+						Usage: "comma-separated peer IDs that aren't allowed to take the offer",
+					},
 					&cli.BoolFlag{
 						Name:  "subscribe",
 						Usage: "subscribe to push notifications about the swap's status",
@@ -88,6 +144,114 @@ var (
 					daemonAddrFlag,
 				},
 			},
+			{
+				Name:   "make-private",
+				Usage:  "make a swap offer that isn't advertised, to be taken by whoever it's shared with",
+				Action: runMakePrivate,
+				Flags: []cli.Flag{
+					&cli.Float64Flag{
+						Name:  "min-amount",
+						Usage: "minimum amount to be swapped, in XMR",
+					},
+					&cli.Float64Flag{
+						Name:  "max-amount",
+						Usage: "maximum amount to be swapped, in XMR",
+					},
+					&cli.Float64Flag{
+						Name:  "exchange-rate",
+						Usage: "desired exchange rate of XMR:ETH, eg. --exchange-rate=0.1 means 10XMR = 1ETH",
+					},
+					&cli.BoolFlag{
+						Name:  "pegged",
+						Usage: "peg the offer's exchange rate to the daemon's price oracle instead of setting --exchange-rate",
+					},
+					&cli.Float64Flag{
+						Name:  "spread",
+						Usage: "fraction above the index rate that a pegged offer's rate is set to, eg. --spread=0.01 is 1% above",
+					},
+					&cli.Float64Flag{
+						Name:  "min-taker-amount",
+						Usage: "minimum amount of ETH a taker may provide",
+					},
+					&cli.DurationFlag{
+						Name:  "ttl",
+						Usage: "how long the offer can be taken for before it expires, eg. --ttl=1h; defaults to swapd's --offer-ttl",
+					},
+					&cli.UintFlag{
+						Name:  "required-confirmations",
+						Usage: "number of confirmations to wait for after the taker locks their ETH, if more than the daemon's default",
+					},
+					&cli.StringFlag{
+						Name:  "speed-tiers",
+						Usage: "comma-separated settlement speed tiers of the form name:xmr-confirmations:timeout, eg. --speed-tiers=fast:5:10m,cheap:10:1h", //nolint:lll
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "market",
+				Usage:  "List the offers makers have published on the network",
+				Action: runMarket,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "orderbook",
+				Usage:  "List the offers of the makers found by swapd's orderbook crawler",
+				Action: runOrderbook,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "peer-scores",
+				Usage:  "List the reputation scores of peers",
+				Action: runPeerScores,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "peers",
+				Usage:  "List the peers we're connected to",
+				Action: runPeers,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "peer-stats",
+				Usage:  "List the bandwidth used with each peer, and its latency",
+				Action: runPeerStats,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "dht-status",
+				Usage:  "Show the state of the DHT that offers are discovered through",
+				Action: runDHTStatus,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "clear-offers",
+				Usage:  "Remove offers, so they're no longer advertised",
+				Action: runClearOffers,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-ids",
+						Usage: "comma-separated IDs of the offers to remove",
+					},
+					&cli.BoolFlag{
+						Name:  "all",
+						Usage: "remove all offers",
+					},
+					daemonAddrFlag,
+				},
+			},
 			{
 				Name:    "take",
 				Aliases: []string{"t"},
@@ -106,6 +270,22 @@ var (
 						Name:  "provides-amount",
 						Usage: "amount of coin to send in the swap",
 					},
+					&cli.StringFlag{
+						Name:  "speed-tier",
+						Usage: "name of the offer's settlement speed tier to use; defaults to the offer's first tier",
+					},
+					&cli.StringFlag{
+						Name:  "quote-id",
+						Usage: "ID of a quote from get-quote to take the offer at the quoted rate",
+					},
+					&cli.Float64Flag{
+						Name:  "max-exchange-rate",
+						Usage: "abort the swap before locking ETH if its rate is above this, in ETH per XMR",
+					},
+					&cli.Float64Flag{
+						Name:  "min-received-xmr",
+						Usage: "abort the swap before locking ETH if it would receive less XMR than this",
+					},
 					&cli.BoolFlag{
 						Name:  "subscribe",
 						Usage: "subscribe to push notifications about the swap's status",
@@ -113,6 +293,118 @@ var (
 					daemonAddrFlag,
 				},
 			},
+			{
+				Name:   "take-private",
+				Usage:  "initiate a swap by taking a private offer, using the address and token the maker shared",
+				Action: runTakePrivate,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "multiaddr",
+						Usage: "maker's multiaddress, as shared by the maker",
+					},
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "the offer's token, as shared by the maker",
+					},
+					&cli.Float64Flag{
+						Name:  "provides-amount",
+						Usage: "amount of coin to send in the swap",
+					},
+					&cli.StringFlag{
+						Name:  "speed-tier",
+						Usage: "name of the offer's settlement speed tier to use; defaults to the offer's first tier",
+					},
+					&cli.Float64Flag{
+						Name:  "max-exchange-rate",
+						Usage: "abort the swap before locking ETH if its rate is above this, in ETH per XMR",
+					},
+					&cli.Float64Flag{
+						Name:  "min-received-xmr",
+						Usage: "abort the swap before locking ETH if it would receive less XMR than this",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "get-quote",
+				Usage:  "ask a maker for a firm quote on taking one of its offers",
+				Action: runGetQuote,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "multiaddr",
+						Usage: "peer's multiaddress, as provided by discover",
+					},
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of the offer to get a quote for",
+					},
+					&cli.Float64Flag{
+						Name:  "provides-amount",
+						Usage: "amount of coin to send in the swap",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "take-best",
+				Usage:  "discover makers and take the best offer for the given amount",
+				Action: runTakeBest,
+				Flags: []cli.Flag{
+					&cli.Float64Flag{
+						Name:  "provides-amount",
+						Usage: "amount of coin to send in the swap",
+					},
+					&cli.UintFlag{
+						Name:  "search-time",
+						Usage: "duration of time to search for makers, in seconds",
+					},
+					&cli.StringFlag{
+						Name:  "speed-tier",
+						Usage: "name of the settlement speed tier the offer must have; defaults to the offer's first tier",
+					},
+					&cli.Float64Flag{
+						Name:  "max-exchange-rate",
+						Usage: "only consider offers with a rate at or below this, in ETH per XMR",
+					},
+					&cli.Float64Flag{
+						Name:  "min-received-xmr",
+						Usage: "only consider offers that would send at least this much XMR",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "take-route",
+				Usage:  "split a swap that's too large for any one maker across several makers",
+				Action: runTakeRoute,
+				Flags: []cli.Flag{
+					&cli.Float64Flag{
+						Name:  "provides-amount",
+						Usage: "amount of coin to send in the swap",
+					},
+					&cli.UintFlag{
+						Name:  "search-time",
+						Usage: "duration of time to search for makers, in seconds",
+					},
+					&cli.Float64Flag{
+						Name:  "max-exchange-rate",
+						Usage: "only use offers with a rate at or below this, in ETH per XMR",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "get-route",
+				Usage:  "get the status of a swap split across several makers",
+				Action: runGetRoute,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "route-id",
+						Usage: "ID of the route, as returned by take-route",
+					},
+					daemonAddrFlag,
+				},
+			},
 			{
 				Name:   "get-past-swap-ids",
 				Usage:  "get past swap IDs",
@@ -144,94 +436,1366 @@ var (
 				},
 			},
 			{
-				Name:   "refund",
-				Usage:  "if we are the ETH provider for an ongoing swap, refund it if possible.",
-				Action: runRefund,
+				Name:   "past-swaps",
+				Usage:  "list past swaps, optionally filtered by start date, status, direction and counterparty",
+				Action: runGetPastSwaps,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "offer-id",
-						Usage: "ID of swap to retrieve info for",
+						Name:  "from",
+						Usage: "only list swaps started at or after this date (YYYY-MM-DD) or RFC 3339 time",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "only list swaps started before this date (YYYY-MM-DD) or RFC 3339 time",
+					},
+					&cli.StringSliceFlag{
+						Name:  "status",
+						Usage: "only list swaps with this status, eg. Success, Refunded or Aborted; may be repeated",
+					},
+					&cli.StringFlag{
+						Name:  "peer-id",
+						Usage: "only list swaps with this counterparty",
+					},
+					&cli.StringFlag{
+						Name:  "provides",
+						Usage: "only list swaps in which we provided this coin: ETH or XMR",
+					},
+					&cli.UintFlag{
+						Name:  "limit",
+						Usage: "list at most this many swaps; the command to list the next page is printed",
+					},
+					&cli.StringFlag{
+						Name:  "cursor",
+						Usage: "list the page of swaps after the one this cursor was printed for",
 					},
 					daemonAddrFlag,
 				},
 			},
 			{
-				Name:   "cancel",
-				Usage:  "cancel a ongoing swap if possible.",
-				Action: runCancel,
+				Name:   "export-history",
+				Usage:  "export past swaps as CSV or JSON, for accounting",
+				Action: runExportHistory,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "offer-id",
-						Usage: "ID of swap to retrieve info for",
+						Name:  "format",
+						Usage: "format to export in: csv or json",
+						Value: "csv",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "file to write the export to; if unset, it's written to stdout",
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "only export swaps started at or after this date (YYYY-MM-DD) or RFC 3339 time",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "only export swaps started before this date (YYYY-MM-DD) or RFC 3339 time",
+					},
+					&cli.StringSliceFlag{
+						Name:  "status",
+						Usage: "only export swaps with this status, eg. Success, Refunded or Aborted; may be repeated",
+					},
+					&cli.StringFlag{
+						Name:  "peer-id",
+						Usage: "only export swaps with this counterparty",
+					},
+					&cli.StringFlag{
+						Name:  "provides",
+						Usage: "only export swaps in which we provided this coin: ETH or XMR",
 					},
 					daemonAddrFlag,
 				},
 			},
 			{
-				Name:   "get-stage",
-				Usage:  "get the stage of a current swap.",
-				Action: runGetStage,
+				Name:   "backup-db",
+				Usage:  "back up swapd's database to a new directory in its backup directory; not allowed while swaps are ongoing", //nolint:lll
+				Action: runBackupDB,
 				Flags: []cli.Flag{
 					&cli.StringFlag{
-						Name:  "offer-id",
-						Usage: "ID of swap to retrieve info for",
+						Name:  "name",
+						Usage: "name of the directory in swapd's backup directory to write the backup to",
 					},
 					daemonAddrFlag,
+					adminTokenFileFlag,
 				},
 			},
 			{
-				Name:   "set-swap-timeout",
-				Usage:  "set the duration between swap initiation and t0 and t0 and t1, in seconds",
-				Action: runSetSwapTimeout,
+				Name:   "restore-db",
+				Usage:  "restore swapd's database from a backup when swapd is next started",
+				Action: runRestoreDB,
 				Flags: []cli.Flag{
-					&cli.UintFlag{
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "name of the backup's directory in swapd's backup directory",
+					},
+					daemonAddrFlag,
+					adminTokenFileFlag,
+				},
+			},
+			{
+				Name:   "ban-peer",
+				Usage:  "ban a peer or IP address, disconnecting from it",
+				Action: runBanPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "peer-id",
+						Usage: "ID of the peer to ban",
+					},
+					&cli.StringFlag{
+						Name:  "ip",
+						Usage: "IP address to ban",
+					},
+					&cli.DurationFlag{
 						Name:  "duration",
-						Usage: "duration of timeout, in seconds",
+						Usage: "how long to ban for, eg. --duration=24h; if it isn't given, the ban lasts until it's lifted",
 					},
 					daemonAddrFlag,
+					adminTokenFileFlag,
 				},
 			},
-		},
-		Flags: []cli.Flag{daemonAddrFlag},
+			{
+				Name:   "unban-peer",
+				Usage:  "lift the ban of a peer or IP address",
+				Action: runUnbanPeer,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "peer-id",
+						Usage: "ID of the peer to unban",
+					},
+					&cli.StringFlag{
+						Name:  "ip",
+						Usage: "IP address to unban",
+					},
+					daemonAddrFlag,
+					adminTokenFileFlag,
+				},
+			},
+			{
+				Name:   "stats",
+				Usage:  "show statistics about past swaps: volume, success rates, durations and fees",
+				Action: runStats,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "only count swaps started at or after this date (YYYY-MM-DD) or RFC 3339 time",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "only count swaps started before this date (YYYY-MM-DD) or RFC 3339 time",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "refund",
+				Usage:  "if we are the ETH provider for an ongoing swap or one pending recovery, refund it if the contract allows it now.", //nolint:lll
+				Action: runRefund,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of swap to retrieve info for",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "claim",
+				Usage:  "if we are the XMR provider for an ongoing swap or one pending recovery, claim it now if the contract allows it.", //nolint:lll
+				Action: runClaim,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of swap to claim",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "cancel",
+				Usage:  "cancel a ongoing swap if possible.",
+				Action: runCancel,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of swap to retrieve info for",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "confirm-ready",
+				Usage:  "allow a swap to be set to ready, if swapd was started with --manual-ready.",
+				Action: runConfirmReady,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of swap to confirm",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "get-stage",
+				Usage:  "get the stage of a current swap.",
+				Action: runGetStage,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of swap to retrieve info for",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "get-statuses",
+				Usage:  "get the stages of several swaps at once, or of all the ongoing swaps if none are given.",
+				Action: runGetStatuses,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "offer-id",
+						Usage: "ID of swap to retrieve the stage of; may be repeated",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:      "watch",
+				Aliases:   []string{"w"},
+				Usage:     "follow a swap's progress live; exits with 0 on success, 2 if refunded, 3 if aborted, 1 otherwise",
+				ArgsUsage: "<swap-id>",
+				Action:    runWatch,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "explorer-url",
+						Usage: "block explorer URL to link transactions to, eg. https://goerli.etherscan.io",
+					},
+					&cli.StringFlag{
+						Name:  "daemon-ws-addr",
+						Usage: "websockets address of swap daemon; default ws://localhost:6005",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "pending-recovery",
+				Usage:  "list the swaps interrupted by a restart of swapd that haven't been recovered yet",
+				Action: runGetPendingRecovery,
+				Flags: []cli.Flag{
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "resolve-recovery",
+				Usage:  "recover a swap listed by pending-recovery, claiming or refunding its funds",
+				Action: runResolvePendingRecovery,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of the swap to recover",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "export-recovery-bundle",
+				Usage:  "export everything needed to recover a swap without swapd as a passphrase-encrypted file",
+				Action: runExportRecoveryBundle,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "offer-id",
+						Usage: "ID of the swap to export",
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "file to write the bundle to; defaults to <offer-id>.bundle",
+					},
+					&cli.StringFlag{
+						Name:  "bundle-passphrase-file",
+						Usage: "file containing the passphrase to encrypt the bundle with; prompted for if unset",
+					},
+					daemonAddrFlag,
+					adminTokenFileFlag,
+				},
+			},
+			{
+				Name: "recover",
+				Usage: "claim, refund or sweep the funds of a swap from its info file or recovery instructions, " +
+					"without a running swapd",
+				Action: runRecover,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "infofile",
+						Usage: "path to the swap's info file, or to a recovery bundle exported with export-recovery-bundle",
+					},
+					&cli.StringFlag{
+						Name:  "bundle-passphrase-file",
+						Usage: "file containing the recovery bundle's passphrase; prompted for if unset",
+					},
+					&cli.StringFlag{
+						Name:  "instructions",
+						Usage: "path to the swap's JSON recovery instructions; the info file is read from the path in them",
+					},
+					&cli.StringFlag{
+						Name:  "contract-address",
+						Usage: "address of the swap contract; required if the info file only has the swap's keys",
+					},
+					&cli.Uint64Flag{
+						Name:  "from-block",
+						Usage: "block to search the swap contract's logs from if the info file doesn't have the contract swap",
+					},
+					&cli.StringFlag{
+						Name:  "db",
+						Usage: "directory of swapd's database; if set, the info file is read from it. swapd must be stopped",
+					},
+					&cli.StringFlag{
+						Name:  "backup-password-file",
+						Usage: "file containing the backup password; required if the info file is an encrypted backup (.enc)",
+					},
+					&cli.StringFlag{
+						Name:  "env",
+						Usage: "environment to use: one of mainnet, stagenet, or dev",
+					},
+					&cli.StringFlag{
+						Name:  "monero-endpoint",
+						Usage: "monero-wallet-rpc endpoint",
+					},
+					&cli.StringFlag{
+						Name:  "ethereum-endpoint",
+						Usage: "ethereum client endpoint",
+					},
+					&cli.StringFlag{
+						Name:  "ethereum-privkey",
+						Usage: "file containing a private key hex string",
+					},
+					&cli.StringFlag{
+						Name:  "ethereum-keystore",
+						Usage: "encrypted ethereum keystore file to load the private key from",
+					},
+					&cli.StringFlag{
+						Name:  "ethereum-keystore-password-file",
+						Usage: "file containing the keystore password; prompted for if unset",
+					},
+					&cli.StringFlag{
+						Name:  "ethereum-hd-wallet",
+						Usage: "file containing the HD wallet seed the swap's account was derived from",
+					},
+					&cli.UintFlag{
+						Name:  "ethereum-chain-id",
+						Usage: "ethereum chain ID; eg. mainnet=1, goerli=5, ganache=1337",
+					},
+					&cli.UintFlag{
+						Name:  "gas-price",
+						Usage: "ethereum gas price to use for transactions (in gwei). if not set, the gas price is set via oracle.",
+					},
+					&cli.UintFlag{
+						Name:  "gas-limit",
+						Usage: "ethereum gas limit to use for transactions. if not set, the gas limit is estimated for each transaction.",
+					},
+				},
+			},
+			{
+				Name:   "set-swap-timeout",
+				Usage:  "set the duration between swap initiation and t0 and t0 and t1, in seconds",
+				Action: runSetSwapTimeout,
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:  "duration",
+						Usage: "duration of timeout, in seconds",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name: "set-gas-config",
+				Usage: "set the gas settings of new ethereum transactions, in wei; " +
+					"settings that aren't given are suggested by the ethereum node",
+				Action: runSetGasConfig,
+				Flags: []cli.Flag{
+					&cli.Uint64Flag{
+						Name:  "gas-price",
+						Usage: "gas price of legacy transactions; can't be given with the fee cap or priority fee",
+					},
+					&cli.Uint64Flag{
+						Name:  "max-fee-per-gas",
+						Usage: "fee cap of dynamic fee transactions",
+					},
+					&cli.Uint64Flag{
+						Name:  "max-priority-fee-per-gas",
+						Usage: "priority fee of dynamic fee transactions",
+					},
+					&cli.Uint64Flag{
+						Name:  "gas-limit",
+						Usage: "gas limit of transactions",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "set-monero-fee-priority",
+				Usage:  "set the fee priority of new monero transfers",
+				Action: runSetMoneroFeePriority,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "priority",
+						Usage: "one of default, unimportant, normal, elevated or priority",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "capital",
+				Usage:  "show how much ETH and XMR is locked in swaps, idle and reserved by offers",
+				Action: runCapital,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "history",
+						Usage: "also show the utilization sampled over time",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "preflight",
+				Usage:  "check that the daemon is ready for a swap providing the given amount",
+				Action: runPreflight,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "provides",
+						Usage: "coin to provide: one of [ETH, XMR]",
+					},
+					&cli.Float64Flag{
+						Name:  "amount",
+						Usage: "amount to provide, in standard units",
+					},
+					daemonAddrFlag,
+				},
+			},
+			{
+				Name:   "version",
+				Usage:  "show the daemon's version, supported protocol features and configured chains",
+				Action: runVersion,
+				Flags:  []cli.Flag{daemonAddrFlag},
+			},
+		},
+		Flags: []cli.Flag{daemonAddrFlag, tlsCAFlag},
+	}
+
+	daemonAddrFlag = &cli.StringFlag{
+		Name:  "daemon-addr",
+		Usage: "address of swap daemon; default http://localhost:5001",
+	}
+
+	tlsCAFlag = &cli.StringFlag{
+		Name:   "tls-ca",
+		Usage:  "PEM file of the certificate authority to trust for swapd's HTTPS and WSS endpoints, instead of the system's",
+		EnvVar: "SWAPCLI_TLS_CA",
+	}
+
+	adminTokenFileFlag = &cli.StringFlag{
+		Name:   "admin-token-file",
+		Usage:  "file containing swapd's admin token, which swapd writes to <basepath>/rpc-admin-token",
+		EnvVar: "SWAPCLI_ADMIN_TOKEN_FILE",
+	}
+)
+
+// newAdminClient returns a client that sends the admin token in --admin-token-file, for the methods
+// that require it.
+func newAdminClient(ctx *cli.Context, endpoint string) (*rpcclient.Client, error) {
+	file := ctx.String("admin-token-file")
+	if file == "" {
+		return nil, errNoAdminTokenFile
+	}
+
+	token, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admin token: %w", err)
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	c.SetAdminToken(strings.TrimSpace(string(token)))
+	return c, nil
+}
+
+func loadTLSConfig(ctx *cli.Context) error {
+	caFile := ctx.GlobalString("tls-ca")
+	if caFile == "" {
+		return nil
+	}
+
+	var err error
+	tlsConfig, err = rpcclient.TLSConfig(caFile)
+	return err
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+}
+
+func runAddresses(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	addrs, err := c.Addresses()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Listening addresses: %v\n", addrs)
+	return nil
+}
+
+func runDiscover(ctx *cli.Context) error {
+	provides, err := types.NewProvidesCoin(ctx.String("provides"))
+	if err != nil {
+		return err
+	}
+
+	if provides == "" {
+		provides = types.ProvidesXMR
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	searchTime := ctx.Uint("search-time")
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	peers, err := c.Discover(provides, uint64(searchTime))
+	if err != nil {
+		return err
+	}
+
+	for i, peer := range peers {
+		fmt.Printf("Peer %d: %v\n", i, peer)
+	}
+
+	return nil
+}
+
+func runQuery(ctx *cli.Context) error {
+	maddr := ctx.String("multiaddr")
+	if maddr == "" {
+		return errNoMultiaddr
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	res, err := c.Query(maddr)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range res.Offers {
+		fmt.Printf("%v\n", o)
+	}
+	return nil
+}
+
+func runMarket(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	peers, err := c.GetMarketOffers()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range peers {
+		fmt.Printf("Peer %s: %v\n", p.PeerID, p.Multiaddrs)
+		for _, o := range p.Offers {
+			fmt.Printf("\t%v\n", o)
+		}
+	}
+	return nil
+}
+
+func runOrderbook(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	ob, err := c.Orderbook()
+	if err != nil {
+		return err
+	}
+
+	if ob.LastCrawl == nil {
+		fmt.Printf("No crawl has finished yet\n")
+	} else {
+		fmt.Printf("Last crawl: %s\n", ob.LastCrawl.Format(time.RFC3339))
+	}
+
+	for _, p := range ob.Peers {
+		fmt.Printf("Peer %s (updated %s): %v\n", p.PeerID, p.UpdatedAt.Format(time.RFC3339), p.Multiaddrs)
+		for _, o := range p.Offers {
+			fmt.Printf("\t%v\n", o)
+		}
+	}
+	return nil
+}
+
+func runPeerScores(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	scores, err := c.GetPeerScores()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range scores {
+		fmt.Printf("Peer %s: score=%d completed=%d aborted=%d refunded=%d invalid messages=%d banned=%t\n",
+			s.PeerID, s.Score, s.CompletedSwaps, s.AbortedSwaps, s.RefundedSwaps, s.InvalidMessages, s.Banned)
+	}
+	return nil
+}
+
+func runPeers(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	peers, err := c.GetPeers()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range peers {
+		age := time.Duration(p.AgeSeconds * float64(time.Second)).Round(time.Second)
+		fmt.Printf("Peer %s: direction=%s connected for=%s score=%d deprioritized=%t\n",
+			p.PeerID, p.Direction, age, p.Score, p.Deprioritized)
+		fmt.Printf("\tAddresses: %s\n", strings.Join(p.Multiaddrs, ", "))
+		fmt.Printf("\tProtocols: %s\n", strings.Join(p.Protocols, ", "))
+	}
+	return nil
+}
+
+func runPeerStats(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	stats, err := c.GetPeerStats()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		fmt.Printf("Peer %s: connected=%t in=%dB (%.0fB/s) out=%dB (%.0fB/s) latency=%.1fms\n",
+			s.PeerID, s.Connected, s.BytesIn, s.RateIn, s.BytesOut, s.RateOut, s.LatencyMs)
+	}
+	return nil
+}
+
+func runDHTStatus(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	status, err := c.GetDHTStatus()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Mode: %s\n", status.Mode)
+	fmt.Printf("LAN routing table size: %d\n", status.LANRoutingTableSize)
+	fmt.Printf("WAN routing table size: %d\n", status.WANRoutingTableSize)
+	if status.LastAdvertised != nil {
+		fmt.Printf("Last advertised: %s\n", status.LastAdvertised.Format(time.RFC3339))
+	} else {
+		fmt.Printf("Not advertised yet\n")
+	}
+	return nil
+}
+
+func runClearOffers(ctx *cli.Context) error {
+	var ids []string
+	if offerIDs := ctx.String("offer-ids"); offerIDs != "" {
+		ids = strings.Split(offerIDs, ",")
+	}
+
+	if len(ids) == 0 && !ctx.Bool("all") {
+		return errNoOfferIDs
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	if err := c.ClearOffers(ids); err != nil {
+		return err
+	}
+
+	fmt.Println("Cleared offers")
+	return nil
+}
+
+func runMake(ctx *cli.Context) error {
+	min := ctx.Float64("min-amount")
+	if min == 0 {
+		return errNoMinAmount
+	}
+
+	max := ctx.Float64("max-amount")
+	if max == 0 {
+		return errNoMaxAmount
+	}
+
+	var peg *types.RatePeg
+	if ctx.Bool("pegged") {
+		peg = &types.RatePeg{
+			Spread: ctx.Float64("spread"),
+		}
+	}
+
+	exchangeRate := ctx.Float64("exchange-rate")
+	if exchangeRate == 0 && peg == nil {
+		return errNoExchangeRate
+	}
+
+	speedTiers, err := parseSpeedTiers(ctx.String("speed-tiers"))
+	if err != nil {
+		return err
+	}
+
+	var relist *types.RelistPolicy
+	if ctx.Bool("one-shot") || ctx.Duration("relist-cooldown") != 0 {
+		relist = &types.RelistPolicy{
+			OneShot:  ctx.Bool("one-shot"),
+			Cooldown: uint64(ctx.Duration("relist-cooldown").Seconds()),
+		}
+	}
+
+	takers := parseTakerFilter(ctx.String("allow-takers"), ctx.String("deny-takers"))
+	terms := parseOfferTerms(ctx, peg)
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	if ctx.Bool("subscribe") {
+		c, err := wsclient.NewWsClientWithTLS(context.Background(), endpoint, tlsConfig)
+		if err != nil {
+			return err
+		}
+
+		id, statusCh, err := c.MakeOfferAndSubscribe(min, max, types.ExchangeRate(exchangeRate), speedTiers, relist,
+			peg, takers, terms)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Made offer with ID %s\n", id)
+
+		for stage := range statusCh {
+			fmt.Printf("> Stage updated: %s\n", stage)
+			if !stage.IsOngoing() {
+				return nil
+			}
+		}
+
+		return nil
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	id, err := c.MakeOffer(min, max, exchangeRate, speedTiers, relist, peg, takers, terms)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Published offer with ID %s\n", id)
+	return nil
+}
+
+func runMakePrivate(ctx *cli.Context) error {
+	min := ctx.Float64("min-amount")
+	if min == 0 {
+		return errNoMinAmount
+	}
+
+	max := ctx.Float64("max-amount")
+	if max == 0 {
+		return errNoMaxAmount
+	}
+
+	var peg *types.RatePeg
+	if ctx.Bool("pegged") {
+		peg = &types.RatePeg{
+			Spread: ctx.Float64("spread"),
+		}
+	}
+
+	exchangeRate := ctx.Float64("exchange-rate")
+	if exchangeRate == 0 && peg == nil {
+		return errNoExchangeRate
+	}
+
+	speedTiers, err := parseSpeedTiers(ctx.String("speed-tiers"))
+	if err != nil {
+		return err
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.MakePrivateOffer(min, max, exchangeRate, speedTiers, peg, parseOfferTerms(ctx, peg))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Made private offer with ID %s\n", resp.ID)
+	fmt.Printf("Token: %s\n", resp.Token)
+	fmt.Printf("Share the token and one of these addresses with the taker:\n")
+	for _, addr := range resp.Addresses {
+		fmt.Printf("\t%s\n", addr)
+	}
+	return nil
+}
+
+// parseOfferTerms returns the offer's optional terms, or nil if none are set.
+func parseOfferTerms(ctx *cli.Context, peg *types.RatePeg) *rpctypes.OfferTerms {
+	if !ctx.IsSet("min-taker-amount") && !ctx.IsSet("required-confirmations") && !ctx.IsSet("ttl") &&
+		(!ctx.IsSet("spread") || peg != nil) {
+		return nil
+	}
+
+	return &rpctypes.OfferTerms{
+		MinimumTakerAmount:    ctx.Float64("min-taker-amount"),
+		Spread:                ctx.Float64("spread"),
+		RequiredConfirmations: uint64(ctx.Uint("required-confirmations")),
+		TTL:                   uint64(ctx.Duration("ttl").Seconds()),
+	}
+}
+
+// parseTakerFilter parses comma-separated lists of peer IDs allowed and denied to take an offer.
+// It returns nil if neither is set.
+func parseTakerFilter(allow, deny string) *types.TakerFilter {
+	if allow == "" && deny == "" {
+		return nil
+	}
+
+	filter := new(types.TakerFilter)
+	if allow != "" {
+		filter.Allow = strings.Split(allow, ",")
+	}
+	if deny != "" {
+		filter.Deny = strings.Split(deny, ",")
+	}
+	return filter
+}
+
+// parseSpeedTiers parses speed tiers of the form name:xmr-confirmations:timeout,...
+func parseSpeedTiers(s string) ([]*types.SpeedTier, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var tiers []*types.SpeedTier
+	for _, str := range strings.Split(s, ",") {
+		parts := strings.Split(str, ":")
+		if len(parts) != 3 {
+			return nil, errInvalidSpeedTier
+		}
+
+		confirmations, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidSpeedTier, err)
+		}
+
+		timeout, err := time.ParseDuration(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidSpeedTier, err)
+		}
+
+		tiers = append(tiers, &types.SpeedTier{
+			Name:                parts[0],
+			MoneroConfirmations: confirmations,
+			Timeout:             uint64(timeout.Seconds()),
+		})
+	}
+
+	return tiers, nil
+}
+
+func runTake(ctx *cli.Context) error {
+	maddr := ctx.String("multiaddr")
+	if maddr == "" {
+		return errNoMultiaddr
+	}
+
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	providesAmount := ctx.Float64("provides-amount")
+	if providesAmount == 0 {
+		return errNoProvidesAmount
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	var limits *types.SlippageLimits
+	if ctx.IsSet("max-exchange-rate") || ctx.IsSet("min-received-xmr") {
+		limits = &types.SlippageLimits{
+			MaxExchangeRate: types.ExchangeRate(ctx.Float64("max-exchange-rate")),
+			MinReceivedXMR:  ctx.Float64("min-received-xmr"),
+		}
+	}
+
+	if ctx.Bool("subscribe") {
+		c, err := wsclient.NewWsClientWithTLS(context.Background(), endpoint, tlsConfig)
+		if err != nil {
+			return err
+		}
+
+		statusCh, err := c.TakeOfferAndSubscribe(maddr, offerID, providesAmount, ctx.String("speed-tier"),
+			ctx.String("quote-id"), limits)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Initiated swap with ID %s\n", offerID)
+
+		for stage := range statusCh {
+			fmt.Printf("> Stage updated: %s\n", stage)
+			if !stage.IsOngoing() {
+				return nil
+			}
+		}
+
+		return nil
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	err := c.TakeOffer(maddr, offerID, providesAmount, ctx.String("speed-tier"), ctx.String("quote-id"), limits)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Initiated swap with ID %s\n", offerID)
+	return nil
+}
+
+func runTakePrivate(ctx *cli.Context) error {
+	maddr := ctx.String("multiaddr")
+	if maddr == "" {
+		return errNoMultiaddr
+	}
+
+	token := ctx.String("token")
+	if token == "" {
+		return errNoToken
+	}
+
+	providesAmount := ctx.Float64("provides-amount")
+	if providesAmount == 0 {
+		return errNoProvidesAmount
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	var limits *types.SlippageLimits
+	if ctx.IsSet("max-exchange-rate") || ctx.IsSet("min-received-xmr") {
+		limits = &types.SlippageLimits{
+			MaxExchangeRate: types.ExchangeRate(ctx.Float64("max-exchange-rate")),
+			MinReceivedXMR:  ctx.Float64("min-received-xmr"),
+		}
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	offerID, err := c.TakePrivateOffer(maddr, token, providesAmount, ctx.String("speed-tier"), limits)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Initiated swap with ID %s\n", offerID)
+	return nil
+}
+
+func runGetQuote(ctx *cli.Context) error {
+	maddr := ctx.String("multiaddr")
+	if maddr == "" {
+		return errNoMultiaddr
+	}
+
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	providesAmount := ctx.Float64("provides-amount")
+	if providesAmount == 0 {
+		return errNoProvidesAmount
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	quote, err := c.GetQuote(maddr, offerID, providesAmount)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Quote %s: exchange rate %v, receiving %v XMR, valid until %s\n", quote.ID, quote.ExchangeRate,
+		quote.ReceivedAmount, quote.ExpiresAt)
+	return nil
+}
+
+func runTakeBest(ctx *cli.Context) error {
+	providesAmount := ctx.Float64("provides-amount")
+	if providesAmount == 0 {
+		return errNoProvidesAmount
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	var limits *types.SlippageLimits
+	if ctx.IsSet("max-exchange-rate") || ctx.IsSet("min-received-xmr") {
+		limits = &types.SlippageLimits{
+			MaxExchangeRate: types.ExchangeRate(ctx.Float64("max-exchange-rate")),
+			MinReceivedXMR:  ctx.Float64("min-received-xmr"),
+		}
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.TakeBestOffer(providesAmount, uint64(ctx.Uint("search-time")), ctx.String("speed-tier"), limits)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Initiated swap with ID %s from peer %s at exchange rate %v\n", resp.OfferID, resp.Multiaddr,
+		resp.ExchangeRate)
+	return nil
+}
+
+func runTakeRoute(ctx *cli.Context) error {
+	providesAmount := ctx.Float64("provides-amount")
+	if providesAmount == 0 {
+		return errNoProvidesAmount
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	route, err := c.TakeRoute(providesAmount, uint64(ctx.Uint("search-time")),
+		types.ExchangeRate(ctx.Float64("max-exchange-rate")))
+	if err != nil {
+		return err
+	}
+
+	printRoute(route)
+	return nil
+}
+
+func runGetRoute(ctx *cli.Context) error {
+	id, err := types.HexToHash(ctx.String("route-id"))
+	if err != nil {
+		return err
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	route, err := c.GetRoute(id)
+	if err != nil {
+		return err
+	}
+
+	printRoute(route)
+	return nil
+}
+
+func printRoute(route *rpctypes.RouteResponse) {
+	fmt.Printf("Route %s: status=%s provided=%v ETH received=%v XMR\n", route.ID, route.Status,
+		route.ProvidesAmount, route.ReceivedAmount)
+	for _, leg := range route.Legs {
+		fmt.Printf("> offer %s from %s: provided=%v ETH rate=%v status=%s %s\n", leg.OfferID, leg.Multiaddr,
+			leg.ProvidesAmount, leg.ExchangeRate, leg.Status, leg.Error)
+	}
+}
+
+func runGetPastSwapIDs(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	ids, err := c.GetPastSwapIDs()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Past swap IDs: %v\n", ids)
+	return nil
+}
+
+func runGetOngoingSwap(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	info, err := c.GetOngoingSwap(offerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Provided: %s\n ProvidedAmount: %v\n ReceivedAmount: %v\n ExchangeRate: %v\n Status: %s\n "+
+		"PeerVersion: %s\n",
+		info.Provided,
+		info.ProvidedAmount,
+		info.ReceivedAmount,
+		info.ExchangeRate,
+		info.Status,
+		info.PeerVersion,
+	)
+	return nil
+}
+
+func runGetPastSwap(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	info, err := c.GetPastSwap(offerID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Provided: %s\n ProvidedAmount: %v\n ReceivedAmount: %v\n ExchangeRate: %v\n Status: %s\n "+
+		"PeerVersion: %s\n",
+		info.Provided,
+		info.ProvidedAmount,
+		info.ReceivedAmount,
+		info.ExchangeRate,
+		info.Status,
+		info.PeerVersion,
+	)
+	return nil
+}
+
+func runGetPastSwaps(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	req := &rpc.GetPastSwapsRequest{
+		Statuses: ctx.StringSlice("status"),
+		PeerID:   ctx.String("peer-id"),
+		Provides: ctx.String("provides"),
+		Limit:    int(ctx.Uint("limit")),
+		Cursor:   ctx.String("cursor"),
+	}
+
+	var err error
+	if req.From, err = parseTimeFlag(ctx, "from"); err != nil {
+		return err
+	}
+
+	if req.To, err = parseTimeFlag(ctx, "to"); err != nil {
+		return err
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.GetPastSwaps(req)
+	if err != nil {
+		return err
+	}
+
+	swaps := resp.Swaps
+	if len(swaps) == 0 {
+		fmt.Printf("No past swaps found\n")
+		return nil
+	}
+
+	for _, s := range swaps {
+		fmt.Printf("ID=%s Started=%s Status=%s Provided=%v %s Received=%v ExchangeRate=%v",
+			s.ID, s.StartTime.Format(time.RFC3339), s.Status, s.ProvidedAmount, s.Provided, s.ReceivedAmount,
+			s.ExchangeRate)
+		if s.PeerID != "" {
+			fmt.Printf(" Peer=%s", s.PeerID)
+		}
+		fmt.Printf("\n")
+	}
+
+	if resp.NextCursor != "" {
+		fmt.Printf("To list the next page of swaps, add: --cursor %s\n", resp.NextCursor)
+	}
+
+	return nil
+}
+
+func runExportHistory(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	req := &rpc.ExportHistoryRequest{
+		GetPastSwapsRequest: rpc.GetPastSwapsRequest{
+			Statuses: ctx.StringSlice("status"),
+			PeerID:   ctx.String("peer-id"),
+			Provides: ctx.String("provides"),
+		},
+		Format: ctx.String("format"),
+	}
+
+	var err error
+	if req.From, err = parseTimeFlag(ctx, "from"); err != nil {
+		return err
+	}
+
+	if req.To, err = parseTimeFlag(ctx, "to"); err != nil {
+		return err
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	data, err := c.ExportHistory(req)
+	if err != nil {
+		return err
+	}
+
+	out := ctx.String("out")
+	if out == "" {
+		fmt.Print(data)
+		return nil
+	}
+
+	if err = os.WriteFile(filepath.Clean(out), []byte(data), 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Exported swap history to %s\n", out)
+	return nil
+}
+
+func runBackupDB(ctx *cli.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		return errNoBackupName
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c, err := newAdminClient(ctx, endpoint)
+	if err != nil {
+		return err
 	}
 
-	daemonAddrFlag = &cli.StringFlag{
-		Name:  "daemon-addr",
-		Usage: "address of swap daemon; default http://localhost:5001",
+	version, err := c.BackupDB(name)
+	if err != nil {
+		return err
 	}
-)
 
-func main() {
-	if err := app.Run(os.Args); err != nil {
-		log.Error(err)
-		os.Exit(1)
-	}
+	fmt.Printf("Backed up database (schema version %d) to %s in swapd's backup directory\n", version, name)
+	return nil
 }
 
-func runAddresses(ctx *cli.Context) error {
+func runRestoreDB(ctx *cli.Context) error {
+	name := ctx.String("name")
+	if name == "" {
+		return errNoBackupName
+	}
+
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	addrs, err := c.Addresses()
+	c, err := newAdminClient(ctx, endpoint)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Listening addresses: %v\n", addrs)
-	return nil
-}
-
-func runDiscover(ctx *cli.Context) error {
-	provides, err := types.NewProvidesCoin(ctx.String("provides"))
+	version, err := c.RestoreDB(name)
 	if err != nil {
 		return err
 	}
 
-	if provides == "" {
-		provides = types.ProvidesXMR
+	fmt.Printf("Staged restore of database (schema version %d) from %s; restart swapd to apply it\n",
+		version, name)
+	return nil
+}
+
+func runBanPeer(ctx *cli.Context) error {
+	req := &rpc.BanPeerRequest{
+		PeerID:   ctx.String("peer-id"),
+		IP:       ctx.String("ip"),
+		Duration: uint64(ctx.Duration("duration").Seconds()),
+	}
+	if (req.PeerID == "") == (req.IP == "") {
+		return errNoPeerIDOrIP
 	}
 
 	endpoint := ctx.String("daemon-addr")
@@ -239,25 +1803,26 @@ func runDiscover(ctx *cli.Context) error {
 		endpoint = defaultSwapdAddress
 	}
 
-	searchTime := ctx.Uint("search-time")
-
-	c := rpcclient.NewClient(endpoint)
-	peers, err := c.Discover(provides, uint64(searchTime))
+	c, err := newAdminClient(ctx, endpoint)
 	if err != nil {
 		return err
 	}
 
-	for i, peer := range peers {
-		fmt.Printf("Peer %d: %v\n", i, peer)
+	if err = c.BanPeer(req); err != nil {
+		return err
 	}
 
+	fmt.Printf("Banned %s%s\n", req.PeerID, req.IP)
 	return nil
 }
 
-func runQuery(ctx *cli.Context) error {
-	maddr := ctx.String("multiaddr")
-	if maddr == "" {
-		return errNoMultiaddr
+func runUnbanPeer(ctx *cli.Context) error {
+	req := &rpc.UnbanPeerRequest{
+		PeerID: ctx.String("peer-id"),
+		IP:     ctx.String("ip"),
+	}
+	if (req.PeerID == "") == (req.IP == "") {
+		return errNoPeerIDOrIP
 	}
 
 	endpoint := ctx.String("daemon-addr")
@@ -265,76 +1830,92 @@ func runQuery(ctx *cli.Context) error {
 		endpoint = defaultSwapdAddress
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	res, err := c.Query(maddr)
+	c, err := newAdminClient(ctx, endpoint)
 	if err != nil {
 		return err
 	}
 
-	for _, o := range res.Offers {
-		fmt.Printf("%v\n", o)
+	if err = c.UnbanPeer(req); err != nil {
+		return err
 	}
+
+	fmt.Printf("Unbanned %s%s\n", req.PeerID, req.IP)
 	return nil
 }
 
-func runMake(ctx *cli.Context) error {
-	min := ctx.Float64("min-amount")
-	if min == 0 {
-		return errNoMinAmount
+func runStats(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
 	}
 
-	max := ctx.Float64("max-amount")
-	if max == 0 {
-		return errNoMaxAmount
+	req := &rpc.StatsRequest{}
+
+	var err error
+	if req.From, err = parseTimeFlag(ctx, "from"); err != nil {
+		return err
 	}
 
-	exchangeRate := ctx.Float64("exchange-rate")
-	if exchangeRate == 0 {
-		return errNoExchangeRate
+	if req.To, err = parseTimeFlag(ctx, "to"); err != nil {
+		return err
 	}
 
-	endpoint := ctx.String("daemon-addr")
-	if endpoint == "" {
-		endpoint = defaultSwapdAddress
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	stats, err := c.Stats(req)
+	if err != nil {
+		return err
 	}
 
-	if ctx.Bool("subscribe") {
-		c, err := wsclient.NewWsClient(context.Background(), endpoint)
-		if err != nil {
-			return err
-		}
+	fmt.Printf("Swaps: %d past, %d ongoing\n", stats.Swaps, stats.Ongoing)
+	fmt.Printf("Succeeded: %d (%.1f%%)\n", stats.Succeeded, stats.SuccessRate*100)
+	fmt.Printf("Refunded: %d (%.1f%%)\n", stats.Refunded, stats.RefundRate*100)
+	fmt.Printf("Aborted: %d (%.1f%%)\n", stats.Aborted, stats.AbortRate*100)
+	if stats.Interrupted != 0 {
+		fmt.Printf("Interrupted: %d\n", stats.Interrupted)
+	}
+	fmt.Printf("Volume: %v ETH, %v XMR\n", stats.VolumeETH, stats.VolumeXMR)
+	fmt.Printf("Average duration: %s\n", secondsToDuration(stats.AverageDuration))
 
-		id, statusCh, err := c.MakeOfferAndSubscribe(min, max, types.ExchangeRate(exchangeRate))
-		if err != nil {
-			return err
-		}
+	statuses := make([]string, 0, len(stats.AverageStageDurations))
+	for status := range stats.AverageStageDurations {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Printf("  %s: %s\n", status, secondsToDuration(stats.AverageStageDurations[status]))
+	}
 
-		fmt.Printf("Made offer with ID %s\n", id)
+	fmt.Printf("Gas used: %d\n", stats.GasUsed)
+	fmt.Printf("Fees: %v ETH\n", stats.Fees)
+	return nil
+}
 
-		for stage := range statusCh {
-			fmt.Printf("> Stage updated: %s\n", stage)
-			if !stage.IsOngoing() {
-				return nil
-			}
-		}
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
 
-		return nil
+// parseTimeFlag parses the flag as either a date or an RFC 3339 time, returning nil if it's unset.
+func parseTimeFlag(ctx *cli.Context, name string) (*time.Time, error) {
+	str := ctx.String(name)
+	if str == "" {
+		return nil, nil
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	id, err := c.MakeOffer(min, max, exchangeRate)
+	t, err := time.Parse("2006-01-02", str)
 	if err != nil {
-		return err
+		t, err = time.Parse(time.RFC3339, str)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", name, err)
 	}
 
-	fmt.Printf("Published offer with ID %s\n", id)
-	return nil
+	return &t, nil
 }
 
-func runTake(ctx *cli.Context) error {
-	maddr := ctx.String("multiaddr")
-	if maddr == "" {
-		return errNoMultiaddr
+func runRefund(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
 	}
 
 	offerID := ctx.String("offer-id")
@@ -342,66 +1923,69 @@ func runTake(ctx *cli.Context) error {
 		return errNoOfferID
 	}
 
-	providesAmount := ctx.Float64("provides-amount")
-	if providesAmount == 0 {
-		return errNoProvidesAmount
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.Refund(offerID)
+	if err != nil {
+		return err
 	}
 
+	if resp.Recovering {
+		fmt.Println("Refunding swap pending recovery; see `swapcli pending-recovery` for its progress")
+		return nil
+	}
+
+	fmt.Printf("Refunded successfully, transaction hash: %s\n", resp.TxHash)
+	return nil
+}
+
+func runClaim(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	if ctx.Bool("subscribe") {
-		c, err := wsclient.NewWsClient(context.Background(), endpoint)
-		if err != nil {
-			return err
-		}
-
-		statusCh, err := c.TakeOfferAndSubscribe(maddr, offerID, providesAmount)
-		if err != nil {
-			return err
-		}
-
-		fmt.Printf("Initiated swap with ID %s\n", offerID)
-
-		for stage := range statusCh {
-			fmt.Printf("> Stage updated: %s\n", stage)
-			if !stage.IsOngoing() {
-				return nil
-			}
-		}
-
-		return nil
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	err := c.TakeOffer(maddr, offerID, providesAmount)
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.Claim(offerID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Initiated swap with ID %s\n", offerID)
+	if resp.Recovering {
+		fmt.Println("Claiming swap pending recovery; see `swapcli pending-recovery` for its progress")
+		return nil
+	}
+
+	fmt.Printf("Claimed successfully, transaction hash: %s\n", resp.TxHash)
 	return nil
 }
 
-func runGetPastSwapIDs(ctx *cli.Context) error {
+func runCancel(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	ids, err := c.GetPastSwapIDs()
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.Cancel(offerID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Past swap IDs: %v\n", ids)
+	fmt.Printf("Cancelled successfully, exit status: %s\n", resp)
 	return nil
 }
 
-func runGetOngoingSwap(ctx *cli.Context) error {
+func runConfirmReady(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
@@ -412,23 +1996,16 @@ func runGetOngoingSwap(ctx *cli.Context) error {
 		return errNoOfferID
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	info, err := c.GetOngoingSwap(offerID)
-	if err != nil {
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	if err := c.ConfirmReady(offerID); err != nil {
 		return err
 	}
 
-	fmt.Printf("Provided: %s\n ProvidedAmount: %v\n ReceivedAmount: %v\n ExchangeRate: %v\n Status: %s\n",
-		info.Provided,
-		info.ProvidedAmount,
-		info.ReceivedAmount,
-		info.ExchangeRate,
-		info.Status,
-	)
+	fmt.Printf("Confirmed swap %s can be set to ready\n", offerID)
 	return nil
 }
 
-func runGetPastSwap(ctx *cli.Context) error {
+func runGetStage(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
@@ -439,99 +2016,195 @@ func runGetPastSwap(ctx *cli.Context) error {
 		return errNoOfferID
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	info, err := c.GetPastSwap(offerID)
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.GetStage(offerID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Provided: %s\n ProvidedAmount: %v\n ReceivedAmount: %v\n ExchangeRate: %v\n Status: %s\n",
-		info.Provided,
-		info.ProvidedAmount,
-		info.ReceivedAmount,
-		info.ExchangeRate,
-		info.Status,
-	)
+	fmt.Printf("Stage=%s: %s\n", resp.Stage, resp.Info)
 	return nil
 }
 
-func runRefund(ctx *cli.Context) error {
+func runGetStatuses(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	offerID := ctx.String("offer-id")
-	if offerID == "" {
-		return errNoOfferID
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	statuses, err := c.GetStatuses(ctx.StringSlice("offer-id"))
+	if err != nil {
+		return err
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	resp, err := c.Refund(offerID)
+	if len(statuses) == 0 {
+		fmt.Printf("No ongoing swaps\n")
+		return nil
+	}
+
+	for _, s := range statuses {
+		fmt.Printf("ID=%s Stage=%s Since=%s Ongoing=%t: %s\n", s.ID, s.Stage, s.Since.Format(time.RFC3339),
+			s.Ongoing, s.Info)
+	}
+
+	return nil
+}
+
+func runSetSwapTimeout(ctx *cli.Context) error {
+	duration := ctx.Uint("duration")
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	err := c.SetSwapTimeout(uint64(duration))
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Refunded successfully, transaction hash: %s\n", resp.TxHash)
+	fmt.Printf("Set timeout duration to %ds", duration)
 	return nil
 }
 
-func runCancel(ctx *cli.Context) error {
+func runSetGasConfig(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	offerID := ctx.String("offer-id")
-	if offerID == "" {
-		return errNoOfferID
+	weiFlag := func(name string) *big.Int {
+		if !ctx.IsSet(name) {
+			return nil
+		}
+		return new(big.Int).SetUint64(ctx.Uint64(name))
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	resp, err := c.Cancel(offerID)
-	if err != nil {
+	cfg := &txsender.GasConfig{
+		GasPrice:  weiFlag("gas-price"),
+		GasFeeCap: weiFlag("max-fee-per-gas"),
+		GasTipCap: weiFlag("max-priority-fee-per-gas"),
+		GasLimit:  ctx.Uint64("gas-limit"),
+	}
+
+	if err := cfg.Validate(); err != nil {
 		return err
 	}
 
-	fmt.Printf("Cancelled successfully, exit status: %s\n", resp)
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	if err := c.SetGasConfig(cfg); err != nil {
+		return err
+	}
+
+	fmt.Println("Set gas config")
 	return nil
 }
 
-func runGetStage(ctx *cli.Context) error {
+func runSetMoneroFeePriority(ctx *cli.Context) error {
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	offerID := ctx.String("offer-id")
-	if offerID == "" {
-		return errNoOfferID
+	priority := ctx.String("priority")
+	if priority == "" {
+		return errNoPriority
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	resp, err := c.GetStage(offerID)
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	if err := c.SetMoneroFeePriority(priority); err != nil {
+		return err
+	}
+
+	fmt.Printf("Set monero fee priority to %s\n", priority)
+	return nil
+}
+
+func runCapital(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	resp, err := c.GetCapitalUtilization()
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Stage=%s: %s\n", resp.Stage, resp.Info)
+	cur := resp.Current
+	fmt.Printf("Ongoing swaps: %d, offers: %d\n", cur.OngoingSwaps, cur.Offers)
+	fmt.Printf("ETH: locked=%v idle=%v utilization=%.1f%%\n", cur.LockedETH, cur.IdleETH, cur.ETHUtilization*100)
+	fmt.Printf("XMR: locked=%v idle=%v reserved=%v utilization=%.1f%%\n",
+		cur.LockedXMR, cur.IdleXMR, cur.ReservedXMR, cur.XMRUtilization*100)
+
+	if !ctx.Bool("history") {
+		return nil
+	}
+
+	fmt.Println("History:")
+	for _, s := range resp.History {
+		fmt.Printf("%s ETH=%.1f%% XMR=%.1f%% swaps=%d\n", s.Timestamp.Format(time.RFC3339),
+			s.ETHUtilization*100, s.XMRUtilization*100, s.OngoingSwaps)
+	}
 	return nil
 }
 
-func runSetSwapTimeout(ctx *cli.Context) error {
-	duration := ctx.Uint("duration")
+func runPreflight(ctx *cli.Context) error {
+	provides, err := types.NewProvidesCoin(ctx.String("provides"))
+	if err != nil {
+		return err
+	}
 
 	endpoint := ctx.String("daemon-addr")
 	if endpoint == "" {
 		endpoint = defaultSwapdAddress
 	}
 
-	c := rpcclient.NewClient(endpoint)
-	err := c.SetSwapTimeout(uint64(duration))
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	report, err := c.Preflight(provides, ctx.Float64("amount"))
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Set timeout duration to %ds", duration)
+	for _, check := range report.Checks {
+		result := "ok"
+		if !check.Passed {
+			result = "FAILED"
+		}
+		fmt.Printf("%-14s %-6s %s\n", check.Name, result, check.Message)
+	}
+
+	if !report.Passed {
+		return cli.NewExitError("preflight checks failed", 1)
+	}
+
+	return nil
+}
+
+func runVersion(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	v, err := c.DaemonVersion()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Version: %s\n", v)
+	fmt.Printf("Protocol features: %s\n", strings.Join(v.ProtocolFeatures, ", "))
+	fmt.Printf("Contract versions: %s\n", strings.Join(v.ContractVersions, ", "))
+	for _, chain := range v.Chains {
+		if chain.ChainID != 0 {
+			fmt.Printf("Chain: %s %s (chain ID %d)\n", chain.Name, chain.Network, chain.ChainID)
+			continue
+		}
+		fmt.Printf("Chain: %s %s\n", chain.Name, chain.Network)
+	}
 	return nil
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/noot/atomic-swap/cmd/utils"
+	"github.com/noot/atomic-swap/common/types"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	recovery "github.com/noot/atomic-swap/recover"
+	"github.com/noot/atomic-swap/rpcclient"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli"
+)
+
+// runRecover claims, refunds or sweeps the funds of a swap from its info file, without a daemon.
+func runRecover(ctx *cli.Context) error {
+	env, cfg, err := utils.GetEnvironment(ctx)
+	if err != nil {
+		return err
+	}
+
+	var instructions *pcommon.RecoveryInstructions
+	infofilePath := ctx.String("infofile")
+	if path := ctx.String("instructions"); path != "" {
+		instructions, err = pcommon.ReadRecoveryInstructions(path)
+		if err != nil {
+			return err
+		}
+
+		if infofilePath == "" {
+			infofilePath = instructions.InfoFile
+		}
+	}
+
+	if infofilePath == "" {
+		return errNoInfoFile
+	}
+
+	infofile, err := utils.ReadInfoFile(ctx, infofilePath)
+	if err != nil {
+		return err
+	}
+
+	if instructions != nil {
+		fillFromInstructions(infofile, instructions)
+	}
+
+	if infofile.ContractAddress == "" {
+		infofile.ContractAddress = ctx.String("contract-address")
+	}
+
+	contractAddr := ethcommon.HexToAddress(infofile.ContractAddress)
+	b, err := utils.NewRecoveryBackend(context.Background(), ctx, env, cfg, contractAddr)
+	if err != nil {
+		return err
+	}
+
+	// if the info file only has the swap's keys, the contract swap is searched for in the contract's logs
+	if infofile.ContractAddress != "" {
+		if err = recovery.FillContractSwap(b, infofile, ctx.Uint64("from-block")); err != nil {
+			return err
+		}
+	}
+
+	status, err := recovery.RecoverSwap(b, filepath.Dir(filepath.Clean(infofilePath)), infofile)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case types.CompletedSuccess:
+		fmt.Printf("Claimed the swap's funds\n")
+	case types.CompletedRefund:
+		fmt.Printf("Refunded the swap's funds\n")
+	case types.CompletedAbort:
+		fmt.Printf("No funds were locked in the swap, so there's nothing to recover\n")
+	default:
+		fmt.Printf("Nothing left to recover; see the log for any wallet that was restored\n")
+	}
+
+	return nil
+}
+
+// runExportRecoveryBundle writes the swap's recovery bundle, encrypted with a passphrase, to a file.
+func runExportRecoveryBundle(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	out := ctx.String("out")
+	if out == "" {
+		out = strings.TrimPrefix(offerID, "0x") + pcommon.RecoveryBundleSuffix
+	}
+	if !strings.HasSuffix(out, pcommon.RecoveryBundleSuffix) {
+		return errInvalidBundlePath
+	}
+
+	passphrase, err := utils.GetBundlePassphrase(ctx)
+	if err != nil {
+		return err
+	}
+
+	c, err := newAdminClient(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := c.ExportRecoveryBundle(offerID, string(passphrase))
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(filepath.Clean(out), bundle, 0600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote recovery bundle to %s; recover the swap with `swapcli recover --infofile=%s`\n", out, out)
+	return nil
+}
+
+func runGetPendingRecovery(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	swaps, err := c.GetPendingRecovery()
+	if err != nil {
+		return err
+	}
+
+	if len(swaps) == 0 {
+		fmt.Printf("No swaps are pending recovery\n")
+		return nil
+	}
+
+	for _, s := range swaps {
+		fmt.Printf("ID=%s Status=%s Class=%s Provides=%s Resolving=%v", s.ID, s.Status, s.Class, s.Provides, s.Resolving)
+		if !s.Timeout0.IsZero() {
+			fmt.Printf(" Timeout0=%s Timeout1=%s", s.Timeout0, s.Timeout1)
+		}
+		if s.Error != "" {
+			fmt.Printf(" Error=%q", s.Error)
+		}
+		fmt.Printf("\n")
+	}
+
+	return nil
+}
+
+func runResolvePendingRecovery(ctx *cli.Context) error {
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	offerID := ctx.String("offer-id")
+	if offerID == "" {
+		return errNoOfferID
+	}
+
+	c := rpcclient.NewClientWithTLS(endpoint, tlsConfig)
+	if err := c.ResolvePendingRecovery(offerID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recovering swap %s; check its progress with pending-recovery\n", offerID)
+	return nil
+}
+
+// fillFromInstructions fills in the contract details missing from the info file, eg. if the daemon
+// stopped before writing them, from the recovery instructions.
+func fillFromInstructions(infofile *pcommon.InfoFileContents, instructions *pcommon.RecoveryInstructions) {
+	if infofile.ContractAddress == "" {
+		infofile.ContractAddress = instructions.ContractAddress.Hex()
+	}
+
+	if infofile.ContractSwapID == [32]byte{} {
+		infofile.ContractSwapID = instructions.ContractSwapID
+		infofile.ContractSwap = instructions.ContractSwap
+		infofile.ContractSwapBlock = instructions.ContractSwapBlock
+	}
+
+	if infofile.EthereumKeyIndex == nil {
+		infofile.EthereumKeyIndex = instructions.EthereumKeyIndex
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/rpcclient"
+	"github.com/noot/atomic-swap/rpcclient/wsclient"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	watchPollInterval = time.Second * 5
+	watchTimeFormat   = "15:04:05"
+
+	// exit codes of the watch command, so scripts can tell how the swap ended
+	exitCodeFailed   = 1
+	exitCodeRefunded = 2
+	exitCodeAborted  = 3
+)
+
+// swapWatcher prints the progress of a swap as it happens.
+type swapWatcher struct {
+	c           *rpcclient.Client
+	id          string
+	explorerURL string
+
+	seenTxs      map[string]struct{}
+	moneroHeight uint
+}
+
+func runWatch(ctx *cli.Context) error {
+	id := ctx.Args().First()
+	if id == "" {
+		id = ctx.String("offer-id")
+	}
+	if id == "" {
+		return errNoSwapID
+	}
+
+	hash, err := types.HexToHash(id)
+	if err != nil {
+		return err
+	}
+
+	endpoint := ctx.String("daemon-addr")
+	if endpoint == "" {
+		endpoint = defaultSwapdAddress
+	}
+
+	w := &swapWatcher{
+		c:           rpcclient.NewClientWithTLS(endpoint, tlsConfig),
+		id:          id,
+		explorerURL: ctx.String("explorer-url"),
+		seenTxs:     make(map[string]struct{}),
+	}
+
+	// a swap that has already finished has nothing to follow
+	if past, err := w.c.GetPastSwap(id); err == nil {
+		w.printTxs(past.TxHashes)
+		return w.exit(types.NewStatus(past.Status))
+	}
+
+	wsEndpoint := ctx.String("daemon-ws-addr")
+	if wsEndpoint == "" {
+		wsEndpoint = defaultSwapdWSAddress
+	}
+
+	wsc, err := wsclient.NewWsClientWithTLS(context.Background(), wsEndpoint, tlsConfig)
+	if err != nil {
+		return err
+	}
+	defer wsc.Close()
+
+	statusCh, err := wsc.SubscribeSwapStatus(hash)
+	if err != nil {
+		return err
+	}
+
+	w.printf("watching swap %s", id)
+	w.poll()
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case status, ok := <-statusCh:
+			if !ok {
+				return cli.NewExitError("lost connection to swapd", exitCodeFailed)
+			}
+
+			w.printf("stage: %s (%s)", status, status.Info())
+			if !status.IsOngoing() {
+				// pick up the txs sent just before the swap completed
+				if past, err := w.c.GetPastSwap(id); err == nil {
+					w.printTxs(past.TxHashes)
+				}
+				return w.exit(status)
+			}
+
+			w.poll()
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// poll prints any new transactions and monero block progress of the ongoing swap.
+func (w *swapWatcher) poll() {
+	info, err := w.c.GetOngoingSwap(w.id)
+	if err != nil {
+		return
+	}
+
+	w.printTxs(info.TxHashes)
+
+	if info.MoneroTargetHeight != 0 && info.MoneroHeight != w.moneroHeight {
+		w.moneroHeight = info.MoneroHeight
+		w.printf("waiting for monero blocks: height %d/%d", info.MoneroHeight, info.MoneroTargetHeight)
+	}
+}
+
+func (w *swapWatcher) printTxs(txHashes []string) {
+	for _, txHash := range txHashes {
+		if _, has := w.seenTxs[txHash]; has {
+			continue
+		}
+
+		w.seenTxs[txHash] = struct{}{}
+		if w.explorerURL == "" {
+			w.printf("transaction: %s", txHash)
+			continue
+		}
+
+		w.printf("transaction: %s/tx/%s", strings.TrimSuffix(w.explorerURL, "/"), txHash)
+	}
+}
+
+func (w *swapWatcher) printf(format string, args ...interface{}) {
+	fmt.Printf("[%s] %s\n", time.Now().Format(watchTimeFormat), fmt.Sprintf(format, args...))
+}
+
+// exit returns nil if the swap succeeded, or an error with an exit code describing how it ended.
+func (w *swapWatcher) exit(status types.Status) error {
+	switch status {
+	case types.CompletedSuccess:
+		w.printf("swap completed successfully")
+		return nil
+	case types.CompletedRefund:
+		return cli.NewExitError(fmt.Sprintf("swap %s was refunded", w.id), exitCodeRefunded)
+	case types.CompletedAbort:
+		return cli.NewExitError(fmt.Sprintf("swap %s was aborted", w.id), exitCodeAborted)
+	default:
+		return cli.NewExitError(fmt.Sprintf("swap %s ended with status %s", w.id, status), exitCodeFailed)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backup"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	errNoBackupPassword = errors.New("must provide --backup-password-file when using --backup-target")
+)
+
+// setupSecondaryBackup configures the secondary backup target for swap info files, if one is set.
+func setupSecondaryBackup(c *cli.Context) error {
+	uri := c.String(flagBackupTarget)
+	if uri == "" {
+		return nil
+	}
+
+	password, err := readBackupPassword(c)
+	if err != nil {
+		return err
+	}
+
+	target, err := backup.NewTarget(uri)
+	if err != nil {
+		return err
+	}
+
+	b, err := backup.NewBackup(target, password)
+	if err != nil {
+		return err
+	}
+
+	pcommon.SetSecondaryBackup(b)
+	log.Infof("writing encrypted swap info files to secondary backup %s", b)
+	return nil
+}
+
+func readBackupPassword(c *cli.Context) ([]byte, error) {
+	passwordFile := c.String(flagBackupPasswordFile)
+	if passwordFile == "" {
+		return nil, errNoBackupPassword
+	}
+
+	password, err := os.ReadFile(filepath.Clean(passwordFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.TrimSpace(string(password))), nil
+}
@@ -9,20 +9,20 @@ import (
 
 	"github.com/noot/atomic-swap/common"
 	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
 	"github.com/noot/atomic-swap/swapfactory"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 var (
-	errNoEthereumPrivateKey = errors.New("must provide --ethereum-privkey file for non-development environment")
+	errNoEthereumPrivateKey = errors.New("deploying the swap contract requires an ethereum private key, please provide --contract-address") //nolint:lll
 )
 
 func getOrDeploySwapFactory(address ethcommon.Address, env common.Environment, basePath string, chainID *big.Int,
-	privkey *ecdsa.PrivateKey, ec *ethclient.Client) (*swapfactory.SwapFactory, ethcommon.Address, error) {
+	privkey *ecdsa.PrivateKey, ec backend.EthClient) (*swapfactory.SwapFactory, ethcommon.Address, error) {
 	var (
 		sf *swapfactory.SwapFactory
 	)
@@ -48,7 +48,7 @@ func getOrDeploySwapFactory(address ethcommon.Address, env common.Environment, b
 
 		// store the contract address on disk
 		fp := path.Join(basePath, "contractaddress")
-		if err = pcommon.WriteContractAddressToFile(fp, address.String()); err != nil {
+		if err = pcommon.WriteContractAddressToFile(nil, fp, address.String()); err != nil {
 			return nil, ethcommon.Address{}, fmt.Errorf("failed to write contract address to file: %w", err)
 		}
 	} else {
@@ -63,10 +63,10 @@ func getOrDeploySwapFactory(address ethcommon.Address, env common.Environment, b
 	return sf, address, nil
 }
 
-func getSwapFactory(client *ethclient.Client, addr ethcommon.Address) (*swapfactory.SwapFactory, error) {
+func getSwapFactory(client backend.EthClient, addr ethcommon.Address) (*swapfactory.SwapFactory, error) {
 	return swapfactory.NewSwapFactory(addr, client)
 }
 
-func deploySwapFactory(client *ethclient.Client, txOpts *bind.TransactOpts) (ethcommon.Address, *ethtypes.Transaction, *swapfactory.SwapFactory, error) { //nolint:lll
+func deploySwapFactory(client backend.EthClient, txOpts *bind.TransactOpts) (ethcommon.Address, *ethtypes.Transaction, *swapfactory.SwapFactory, error) { //nolint:lll
 	return swapfactory.DeploySwapFactory(txOpts, client)
 }
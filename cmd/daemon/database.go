@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/db"
+
+	"github.com/urfave/cli"
+)
+
+// openDatabase opens the database that swaps, their recovery info and offers are persisted to.
+// In the development environment, the database is named after the libp2p port by default, so that
+// several daemons can share the base path.
+func openDatabase(c *cli.Context, env common.Environment, cfg common.Config, libp2pPort uint16) (db.Database, error) {
+	dir := c.String(flagDatabase)
+	if dir == "" {
+		name := db.Filename
+		if env == common.Development {
+			name = fmt.Sprintf("%s-%d", db.Filename, libp2pPort)
+		}
+
+		dir = filepath.Join(cfg.Basepath, name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0700); err != nil {
+		return nil, err
+	}
+
+	d, err := db.NewDatabase(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", dir, err)
+	}
+
+	log.Infof("using database %s", dir)
+	return d, nil
+}
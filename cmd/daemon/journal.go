@@ -0,0 +1,89 @@
+package main
+
+import (
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/protocol/journal"
+	"github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/protocol/txsender"
+	recovery "github.com/noot/atomic-swap/recover"
+)
+
+// setupJournal opens the journal of swaps' protocol transitions, and has transactions recorded in it.
+func setupJournal(d db.Database) (*journal.Journal, error) {
+	j, err := journal.NewJournal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	txsender.SetJournal(j)
+	return j, nil
+}
+
+// resumeSwaps replays the journal to find the swaps that were interrupted when the daemon stopped.
+// The counterparty can't pick up the protocol where it left off, so swaps that hadn't locked any
+// funds are aborted, and the rest are classified by the state of their contract swaps and tracked
+// by the returned manager. Unless manual is set, they're recovered in the background; otherwise
+// they're left for the user to resolve.
+func resumeSwaps(j *journal.Journal, d db.Database, b backend.Backend, sm swap.Manager, basepath string,
+	manual bool) (*recovery.Manager, error) {
+	positions, err := j.Replay()
+	if err != nil {
+		return nil, err
+	}
+
+	m := recovery.NewManager(b, d, basepath, func(id types.Hash, status types.Status) {
+		setResumedStatus(j, sm, id, status)
+	})
+
+	for _, p := range positions {
+		if !p.Ongoing() {
+			continue
+		}
+
+		if !p.FundsMayBeLocked() {
+			log.Infof("swap %s was interrupted before any funds were locked, aborting it", p.ID)
+			setResumedStatus(j, sm, p.ID, types.CompletedAbort)
+			continue
+		}
+
+		pending := m.Add(p.ID, p.Status)
+		if pending.Error != "" {
+			log.Warnf("failed to classify interrupted swap %s: %s", p.ID, pending.Error)
+		}
+
+		log.Infof("swap %s was interrupted with status %s (last sent %q, last received %q, %d pending transactions), "+
+			"class=%s", p.ID, p.Status, p.LastSent, p.LastReceived, len(p.Pending()), pending.Class)
+
+		if manual && pending.Class != recovery.ClassAbortable {
+			log.Infof("swap %s is pending recovery; see swap_getPendingRecovery", p.ID)
+			sm.Events().Publish(&events.Event{
+				Type:   events.NeedsRecovery,
+				SwapID: p.ID,
+				Status: p.Status,
+			})
+			continue
+		}
+
+		if err = m.Resolve(p.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// setResumedStatus sets the status of an interrupted swap. Swaps the manager doesn't know of
+// only have the status recorded in the journal.
+func setResumedStatus(j *journal.Journal, sm swap.Manager, id types.Hash, status types.Status) {
+	if info := sm.GetPastSwap(id); info != nil {
+		info.SetStatus(status)
+		return
+	}
+
+	if err := j.StatusChanged(id, status); err != nil {
+		log.Warnf("failed to record status of swap %s in journal: %s", id, err)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
@@ -16,9 +18,19 @@ import (
 
 	"github.com/noot/atomic-swap/cmd/utils"
 	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/metrics"
 	"github.com/noot/atomic-swap/net"
+	pcommon "github.com/noot/atomic-swap/protocol"
 	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/oracle"
+	"github.com/noot/atomic-swap/protocol/preflight"
+	"github.com/noot/atomic-swap/protocol/rebalance"
 	"github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/protocol/txsender"
+	"github.com/noot/atomic-swap/protocol/utilization"
 	"github.com/noot/atomic-swap/protocol/xmrmaker"
 	"github.com/noot/atomic-swap/protocol/xmrtaker"
 	"github.com/noot/atomic-swap/rpc"
@@ -45,46 +57,116 @@ const (
 	defaultWSPort         = 6005
 	defaultXMRTakerWSPort = 8081
 	defaultXMRMakerWSPort = 8082
+
+	defaultLeaseTTL = time.Second * 30
 )
 
 var (
 	log = logging.Logger("cmd")
+
+	errInvalidPayoutAddress = errors.New("--payout-address must be a hex-encoded ethereum address")
+	errRPCTLSIncomplete     = errors.New("--rpc-tls-cert and --rpc-tls-key must be set together")
+	errInitiateWorkTooHigh  = fmt.Errorf("--initiate-work must be at most %d, the most takers will do",
+		net.MaxInitiateDifficulty)
 )
 
 const (
-	flagRPCPort    = "rpc-port"
-	flagWSPort     = "ws-port"
-	flagBasepath   = "basepath"
-	flagLibp2pKey  = "libp2p-key"
-	flagLibp2pPort = "libp2p-port"
-	flagBootnodes  = "bootnodes"
-
-	flagWalletFile           = "wallet-file"
-	flagWalletPassword       = "wallet-password"
-	flagEnv                  = "env"
-	flagMoneroWalletEndpoint = "monero-endpoint"
-	flagMoneroDaemonEndpoint = "monero-daemon-endpoint"
-	flagEthereumEndpoint     = "ethereum-endpoint"
-	flagEthereumPrivKey      = "ethereum-privkey"
-	flagEthereumChainID      = "ethereum-chain-id"
-	flagContractAddress      = "contract-address"
-	flagGasPrice             = "gas-price"
-	flagGasLimit             = "gas-limit"
-	flagUseExternalSigner    = "external-signer"
+	flagRPCPort     = "rpc-port"
+	flagWSPort      = "ws-port"
+	flagGRPCPort    = "grpc-port"
+	flagWSMaxConns  = "ws-max-connections"
+	flagWSMaxSubs   = "ws-max-subscriptions"
+	flagWSMaxMsg    = "ws-max-message-size"
+	flagWSRate      = "ws-rate-limit"
+	flagWSBurst     = "ws-rate-burst"
+	flagWSTimeout   = "ws-write-timeout"
+	flagRPCTLSCert  = "rpc-tls-cert"
+	flagRPCTLSKey   = "rpc-tls-key"
+	flagRPCOrigins  = "rpc-allowed-origins"
+	flagMetricsPort = "metrics-port"
+	flagBasepath    = "basepath"
+	flagDatabase    = "db"
+	flagDBBackupDir = "db-backup-dir"
+	flagLibp2pKey   = "libp2p-key"
+	flagLibp2pPort  = "libp2p-port"
+	flagBootnodes   = "bootnodes"
+	flagAllowTakers = "allow-takers"
+	flagDenyTakers  = "deny-takers"
+	flagTorProxy    = "tor-proxy"
+	flagOnionAddr   = "onion-address"
+	flagRelays      = "relays"
+	flagRelay       = "relay"
+	flagNoPortMap   = "no-port-mapping"
+	flagMaxStreams  = "max-streams-per-peer"
+	flagMaxIPStream = "max-streams-per-ip"
+	flagNoCompress  = "no-compression"
+	flagInitWork    = "initiate-work"
+	flagMDNS        = "mdns"
+	flagDHTMode     = "dht-mode"
+	flagDHTRefresh  = "dht-refresh-interval"
+	flagAdvertise   = "advertise-interval"
+	flagOrderbook   = "orderbook-interval"
+
+	flagWalletFile                   = "wallet-file"
+	flagWalletPassword               = "wallet-password"
+	flagEnv                          = "env"
+	flagMoneroWalletEndpoint         = "monero-endpoint"
+	flagMoneroDaemonEndpoint         = "monero-daemon-endpoint"
+	flagEthereumEndpoint             = "ethereum-endpoint"
+	flagEthereumPrivKey              = "ethereum-privkey"
+	flagEthereumKeystore             = "ethereum-keystore"
+	flagEthereumKeystorePasswordFile = "ethereum-keystore-password-file"
+	flagEthereumHDWallet             = "ethereum-hd-wallet"
+	flagEthereumChainID              = "ethereum-chain-id"
+	flagContractAddress              = "contract-address"
+	flagGasPrice                     = "gas-price"
+	flagGasLimit                     = "gas-limit"
+	flagEthConfirmations             = "eth-confirmations"
+	flagMoneroConfirmations          = "monero-confirmations"
+	flagReadyMinDelay                = "ready-min-delay"
+	flagManualReady                  = "manual-ready"
+	flagQuoteValidity                = "quote-validity"
+	flagPriceOracle                  = "price-oracle"
+	flagRateRefreshInterval          = "rate-refresh-interval"
+	flagMinSwapAmount                = "min-swap-amount"
+	flagPayoutAddress                = "payout-address"
+	flagOfferTTL                     = "offer-ttl"
+	flagRebalanceThreshold           = "rebalance-threshold"
+	flagRebalanceMinXMRRatio         = "rebalance-min-xmr-ratio"
+	flagRebalanceMaxXMRRatio         = "rebalance-max-xmr-ratio"
+	flagRebalanceReserve             = "rebalance-reserve"
+	flagRebalanceMaxSpread           = "rebalance-max-spread"
+	flagRebalanceInterval            = "rebalance-interval"
+	flagUseExternalSigner            = "external-signer"
+	flagBroadcastConfig              = "broadcast-config"
+	flagBackupTarget                 = "backup-target"
+	flagBackupPasswordFile           = "backup-password-file"
+	flagRecoveryWebhook              = "recovery-webhook"
+	flagAlertWebhook                 = "alert-webhook"
+	flagWebhooks                     = "webhooks"
+	flagWebhookSecretFile            = "webhook-secret-file"
+	flagWebhookEvents                = "webhook-events"
+	flagHALeaseFile                  = "ha-lease-file"
+	flagHALeaseTTL                   = "ha-lease-ttl"
+	flagStandby                      = "standby"
+	flagManualRecovery               = "manual-recovery"
+	flagWatchtowerDir                = "watchtower-dir"
 
 	flagDevXMRTaker  = "dev-xmrtaker"
 	flagDevXMRMaker  = "dev-xmrmaker"
 	flagDeploy       = "deploy"
 	flagTransferBack = "transfer-back"
+	flagSweepAddress = "xmr-sweep-address"
 
 	flagLog = "log"
 )
 
 var (
 	app = &cli.App{
-		Name:   "swapd",
-		Usage:  "A program for doing atomic swaps between ETH and XMR",
-		Action: runDaemon,
+		Name:    "swapd",
+		Usage:   "A program for doing atomic swaps between ETH and XMR",
+		Version: common.Version,
+		Action:  runDaemon,
 		Flags: []cli.Flag{
 			&cli.UintFlag{
 				Name:  flagRPCPort,
@@ -94,10 +176,62 @@ var (
 				Name:  flagWSPort,
 				Usage: "port for the daemon RPC websockets server to run on; default 8080",
 			},
+			&cli.UintFlag{
+				Name:  flagGRPCPort,
+				Usage: "port to serve the gRPC API on; it's not served if it's not set",
+			},
+			&cli.UintFlag{
+				Name:  flagWSMaxConns,
+				Usage: "most websockets connections that may be open at once; default 64",
+			},
+			&cli.UintFlag{
+				Name:  flagWSMaxSubs,
+				Usage: "most subscriptions each websockets connection may have running at once; default 16",
+			},
+			&cli.UintFlag{
+				Name:  flagWSMaxMsg,
+				Usage: "largest message, in bytes, websockets clients may send; default 65536",
+			},
+			&cli.Float64Flag{
+				Name:  flagWSRate,
+				Usage: "messages per second each websockets client may send, on average; default 10",
+			},
+			&cli.UintFlag{
+				Name:  flagWSBurst,
+				Usage: "messages each websockets client may send at once, above --ws-rate-limit; default 20",
+			},
+			&cli.DurationFlag{
+				Name:  flagWSTimeout,
+				Usage: "how long a message to a websockets client may take to send before the client is disconnected; default 10s", //nolint:lll
+			},
+			&cli.StringFlag{
+				Name:  flagRPCTLSCert,
+				Usage: "PEM file of the TLS certificate to serve the RPC and websockets servers over HTTPS and WSS with; it's reloaded when it changes", //nolint:lll
+			},
+			&cli.StringFlag{
+				Name:  flagRPCTLSKey,
+				Usage: "PEM file of the key of --rpc-tls-cert",
+			},
+			&cli.UintFlag{
+				Name:  flagMetricsPort,
+				Usage: "port to serve Prometheus metrics on, at /metrics; they're not served if it's not set",
+			},
+			&cli.StringFlag{
+				Name:  flagRPCOrigins,
+				Usage: "comma-separated websites browsers may make RPC and websockets requests from, or * for any; default http://localhost:8080,http://127.0.0.1:8080", //nolint:lll
+			},
 			&cli.StringFlag{
 				Name:  flagBasepath,
 				Usage: "path to store swap artefacts",
 			},
+			&cli.StringFlag{
+				Name:  flagDatabase,
+				Usage: "directory of the database swaps and offers are stored in. default: <basepath>/db",
+			},
+			&cli.StringFlag{
+				Name:  flagDBBackupDir,
+				Usage: "directory that admin_backupDB writes database backups to and admin_restoreDB restores them from. default: <basepath>/db-backups", //nolint:lll
+			},
 			&cli.StringFlag{
 				Name:  flagLibp2pKey,
 				Usage: "libp2p private key",
@@ -128,11 +262,23 @@ var (
 			},
 			&cli.StringFlag{
 				Name:  flagEthereumEndpoint,
-				Usage: "ethereum client endpoint",
+				Usage: "ethereum client endpoint; if a comma-separated list of endpoints is given, they are health-checked and failed over between in order", //nolint:lll
 			},
 			&cli.StringFlag{
 				Name:  flagEthereumPrivKey,
-				Usage: "file containing a private key hex string",
+				Usage: "file containing a private key hex string; prefer --ethereum-keystore",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumKeystore,
+				Usage: "ethereum keystore (v3) file containing the encrypted private key",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumKeystorePasswordFile,
+				Usage: "file containing the ethereum keystore password; if not set, the password is prompted for",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumHDWallet,
+				Usage: "file containing a BIP-39 mnemonic or BIP-32 xprv; each swap uses a fresh ethereum account derived from it", //nolint:lll
 			},
 			&cli.UintFlag{
 				Name:  flagEthereumChainID,
@@ -146,6 +292,78 @@ var (
 				Name:  flagBootnodes,
 				Usage: "comma-separated string of libp2p bootnodes",
 			},
+			&cli.StringFlag{
+				Name:  flagAllowTakers,
+				Usage: "comma-separated peer IDs that are the only ones allowed to take any of our offers",
+			},
+			&cli.StringFlag{
+				Name:  flagDenyTakers,
+				Usage: "comma-separated peer IDs that aren't allowed to take any of our offers",
+			},
+			&cli.StringFlag{
+				Name: flagTorProxy,
+				Usage: "host:port of a SOCKS5 proxy, eg. Tor's 127.0.0.1:9050, to make all libp2p connections through; " +
+					"libp2p then only listens on localhost and doesn't advertise our IP address",
+			},
+			&cli.StringFlag{
+				Name:  flagOnionAddr,
+				Usage: "address of a Tor onion service that forwards to the libp2p port, to advertise; requires --tor-proxy",
+			},
+			&cli.StringFlag{
+				Name: flagRelays,
+				Usage: "comma-separated libp2p relays that peers can reach us through if we aren't publicly reachable; " +
+					"defaults to the bootnodes",
+			},
+			&cli.BoolFlag{
+				Name:  flagRelay,
+				Usage: "relay connections for peers that aren't publicly reachable",
+			},
+			&cli.BoolFlag{
+				Name:  flagNoPortMap,
+				Usage: "don't ask the router to forward the libp2p port by UPnP or NAT-PMP",
+			},
+			&cli.UintFlag{
+				Name:  flagMaxStreams,
+				Usage: "most query, quote and swap streams a peer may have open with us at once",
+				Value: net.DefaultMaxStreamsPerPeer,
+			},
+			&cli.UintFlag{
+				Name:  flagMaxIPStream,
+				Usage: "most query, quote and swap streams the peers at one IP address may have open with us at once",
+				Value: net.DefaultMaxStreamsPerIP,
+			},
+			&cli.BoolFlag{
+				Name:  flagNoCompress,
+				Usage: "don't compress swap messages to peers that support it",
+			},
+			&cli.UintFlag{
+				Name:  flagInitWork,
+				Usage: "leading zero bits of proof of work that takers must do to initiate a swap with us, eg. 20; 0 requires none",
+			},
+			&cli.BoolFlag{
+				Name:  flagMDNS,
+				Usage: "find and connect to other nodes on the local network by mDNS; can't be used with --tor-proxy",
+			},
+			&cli.StringFlag{
+				Name:  flagDHTMode,
+				Usage: "whether to serve DHT records to other peers: auto, client or server",
+				Value: string(net.DHTModeAuto),
+			},
+			&cli.DurationFlag{
+				Name:  flagDHTRefresh,
+				Usage: "how often the DHT's routing table is refreshed",
+				Value: net.DefaultDHTRefreshInterval,
+			},
+			&cli.DurationFlag{
+				Name:  flagAdvertise,
+				Usage: "how often we advertise ourselves and the coins we provide in the DHT",
+				Value: net.DefaultAdvertiseInterval,
+			},
+			&cli.DurationFlag{
+				Name:  flagOrderbook,
+				Usage: "how often makers are discovered and queried for the orderbook; 0 disables the orderbook",
+				Value: net.DefaultOrderbookInterval,
+			},
 			&cli.UintFlag{
 				Name:  flagGasPrice,
 				Usage: "ethereum gas price to use for transactions (in gwei). if not set, the gas price is set via oracle.",
@@ -154,6 +372,130 @@ var (
 				Name:  flagGasLimit,
 				Usage: "ethereum gas limit to use for transactions. if not set, the gas limit is estimated for each transaction.",
 			},
+			&cli.UintFlag{
+				Name:  flagEthConfirmations,
+				Usage: "number of confirmations after which an ethereum transaction is considered final (default depends on --env)",
+			},
+			&cli.UintFlag{
+				Name:  flagMoneroConfirmations,
+				Usage: "minimum number of confirmations of a counterparty's locked XMR before setting a swap to ready, whatever its speed tier", //nolint:lll
+			},
+			&cli.DurationFlag{
+				Name:  flagReadyMinDelay,
+				Usage: "minimum time between a counterparty notifying us of its XMR lock and setting the swap to ready",
+			},
+			&cli.BoolFlag{
+				Name:  flagManualReady,
+				Usage: "only set swaps to ready once confirmed with the swap_confirmReady RPC method",
+			},
+			&cli.DurationFlag{
+				Name:  flagQuoteValidity,
+				Usage: "how long quotes given to takers are honoured for",
+				Value: xmrmaker.DefaultQuoteValidity,
+			},
+			&cli.StringFlag{
+				Name:  flagPriceOracle,
+				Usage: "price oracle that pegged offers' exchange rates follow: coingecko, or chainlink (read via the ethereum endpoint)", //nolint:lll
+			},
+			&cli.DurationFlag{
+				Name:  flagRateRefreshInterval,
+				Usage: "how often pegged offers' exchange rates are refreshed from the price oracle",
+				Value: xmrmaker.DefaultRateRefreshInterval,
+			},
+			&cli.Float64Flag{
+				Name:  flagMinSwapAmount,
+				Usage: "least XMR to swap, whatever our offers' minimums are",
+			},
+			&cli.StringFlag{
+				Name:  flagPayoutAddress,
+				Usage: "ethereum address that ether claimed by our offers' swaps is forwarded to, eg. a cold wallet",
+			},
+			&cli.DurationFlag{
+				Name:  flagOfferTTL,
+				Usage: "how long offers are listed for before they expire; by default, they don't",
+			},
+			&cli.Float64Flag{
+				Name:  flagRebalanceThreshold,
+				Usage: "ETH balance above which claimed ETH is swapped back to XMR by taking other makers' offers; requires --price-oracle", //nolint:lll
+			},
+			&cli.Float64Flag{
+				Name:  flagRebalanceMinXMRRatio,
+				Usage: "fraction of our inventory's value held in XMR below which ETH is swapped back to XMR",
+				Value: 0.4,
+			},
+			&cli.Float64Flag{
+				Name:  flagRebalanceMaxXMRRatio,
+				Usage: "fraction of our inventory's value held in XMR that rebalancing aims to stay below",
+				Value: 0.6,
+			},
+			&cli.Float64Flag{
+				Name:  flagRebalanceReserve,
+				Usage: "ETH that's never swapped when rebalancing, to pay for gas",
+				Value: 0.05,
+			},
+			&cli.Float64Flag{
+				Name:  flagRebalanceMaxSpread,
+				Usage: "how far above the price oracle's rate offers taken when rebalancing may be; eg. 0.02 is 2%",
+				Value: 0.02,
+			},
+			&cli.DurationFlag{
+				Name:  flagRebalanceInterval,
+				Usage: "how often balances are checked when rebalancing",
+				Value: rebalance.DefaultInterval,
+			},
+			&cli.StringFlag{
+				Name:  flagBroadcastConfig,
+				Usage: "JSON file selecting, per chain ID, how each method's transactions are broadcast: direct, relay:<url>, relayer:<url> or external", //nolint:lll
+			},
+			&cli.StringFlag{
+				Name:  flagBackupTarget,
+				Usage: "secondary location to write encrypted swap secrets to: a directory, s3://bucket/prefix, sftp://user@host/dir, or http(s):// backup agent URL", //nolint:lll
+			},
+			&cli.StringFlag{
+				Name:  flagBackupPasswordFile,
+				Usage: "file containing the password used to encrypt secondary backups",
+			},
+			&cli.StringFlag{
+				Name:  flagRecoveryWebhook,
+				Usage: "URL that the recovery instructions written when ether is locked are POSTed to, as JSON",
+			},
+			&cli.StringFlag{
+				Name:  flagAlertWebhook,
+				Usage: "URL that alerts needing the operator's attention, eg. claims that keep failing, are POSTed to, as JSON",
+			},
+			&cli.StringFlag{
+				Name:  flagWebhooks,
+				Usage: "comma-separated URLs that swap events are POSTed to, as signed JSON",
+			},
+			&cli.StringFlag{
+				Name:  flagWebhookSecretFile,
+				Usage: "file containing the secret that webhook payloads are signed with",
+			},
+			&cli.StringFlag{
+				Name:  flagWebhookEvents,
+				Usage: "comma-separated events sent to --webhooks: taken, locked, ready, claimed, refunded and needs-recovery; defaults to all", //nolint:lll
+			},
+			&cli.StringFlag{
+				Name:  flagHALeaseFile,
+				Usage: "lease file shared with a standby daemon; only the daemon holding the lease signs transactions",
+			},
+			&cli.DurationFlag{
+				Name:  flagHALeaseTTL,
+				Usage: "duration after which the lease expires if the active daemon stops renewing it",
+				Value: defaultLeaseTTL,
+			},
+			&cli.BoolFlag{
+				Name:  flagStandby,
+				Usage: "run as a standby: wait for the active daemon's lease to expire, then finish its swaps and take over", //nolint:lll
+			},
+			&cli.StringFlag{
+				Name:  flagWatchtowerDir,
+				Usage: "directory to register swaps with swapwatchtower in; it should only be readable by us",
+			},
+			&cli.BoolFlag{
+				Name:  flagManualRecovery,
+				Usage: "don't recover swaps interrupted by a restart automatically; list them with swap_getPendingRecovery",
+			},
 			&cli.BoolFlag{
 				Name:  flagDevXMRTaker,
 				Usage: "run in development mode and use ETH provider default values",
@@ -170,6 +512,10 @@ var (
 				Name:  flagTransferBack,
 				Usage: "when receiving XMR in a swap, transfer it back to the original wallet.",
 			},
+			&cli.StringFlag{
+				Name:  flagSweepAddress,
+				Usage: "when receiving XMR in a swap, sweep it to this address. implies --transfer-back",
+			},
 			&cli.StringFlag{
 				Name:  flagLog,
 				Usage: "set log level: one of [error|warn|info|debug]",
@@ -199,8 +545,9 @@ type xmrmakerHandler interface {
 }
 
 type daemon struct {
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx      context.Context
+	cancel   context.CancelFunc
+	database db.Database
 }
 
 func setLogLevels(c *cli.Context) error {
@@ -228,6 +575,14 @@ func setLogLevels(c *cli.Context) error {
 	_ = logging.SetLogLevel("cmd", level)
 	_ = logging.SetLogLevel("net", level)
 	_ = logging.SetLogLevel("rpc", level)
+	_ = logging.SetLogLevel("protocol", level)
+	_ = logging.SetLogLevel("backup", level)
+	_ = logging.SetLogLevel("standby", level)
+	_ = logging.SetLogLevel("utilization", level)
+	_ = logging.SetLogLevel("swap", level)
+	_ = logging.SetLogLevel("recovery", level)
+	_ = logging.SetLogLevel("db", level)
+	_ = logging.SetLogLevel("events", level)
 	return nil
 }
 
@@ -250,6 +605,12 @@ func runDaemon(c *cli.Context) error {
 	}
 
 	d.wait()
+	if d.database != nil {
+		if err = d.database.Close(); err != nil {
+			log.Warnf("failed to close database: %s", err)
+		}
+	}
+
 	os.Exit(0)
 	return nil
 }
@@ -263,6 +624,23 @@ func (d *daemon) make(c *cli.Context) error {
 	devXMRTaker := c.Bool(flagDevXMRTaker)
 	devXMRMaker := c.Bool(flagDevXMRMaker)
 
+	if err = setupSecondaryBackup(c); err != nil {
+		return err
+	}
+
+	if url := c.String(flagRecoveryWebhook); url != "" {
+		pcommon.SetRecoveryWebhook(url)
+	}
+
+	if url := c.String(flagAlertWebhook); url != "" {
+		pcommon.SetAlertWebhook(url)
+	}
+
+	lease, err := setupLease(c)
+	if err != nil {
+		return err
+	}
+
 	chainID := int64(c.Uint(flagEthereumChainID))
 	if chainID == 0 {
 		chainID = cfg.EthereumChainID
@@ -273,6 +651,11 @@ func (d *daemon) make(c *cli.Context) error {
 		bootnodes = strings.Split(c.String(flagBootnodes), ",")
 	}
 
+	var relays []string
+	if c.String(flagRelays) != "" {
+		relays = strings.Split(c.String(flagRelays), ",")
+	}
+
 	k := c.String(flagLibp2pKey)
 	p := uint16(c.Uint(flagLibp2pPort))
 	var (
@@ -303,13 +686,63 @@ func (d *daemon) make(c *cli.Context) error {
 		libp2pPort = defaultLibp2pPort
 	}
 
+	d.database, err = openDatabase(c, env, cfg, libp2pPort)
+	if err != nil {
+		return err
+	}
+
+	j, err := setupJournal(d.database)
+	if err != nil {
+		return err
+	}
+
+	dhtMode, err := net.NewDHTMode(c.String(flagDHTMode))
+	if err != nil {
+		return err
+	}
+
+	hello := pcommon.NewHello(env, big.NewInt(chainID))
+	if c.Bool(flagNoCompress) {
+		hello.Compression = nil
+	}
+
+	if c.Uint(flagInitWork) > net.MaxInitiateDifficulty {
+		return errInitiateWorkTooHigh
+	}
+	hello.InitiateDifficulty = uint8(c.Uint(flagInitWork))
+
 	netCfg := &net.Config{
-		Ctx:         d.ctx,
-		Environment: env,
-		ChainID:     chainID,
-		Port:        libp2pPort,
-		KeyFile:     libp2pKey,
-		Bootnodes:   bootnodes,
+		Ctx:                d.ctx,
+		Environment:        env,
+		ChainID:            chainID,
+		Port:               libp2pPort,
+		KeyFile:            libp2pKey,
+		Bootnodes:          bootnodes,
+		Journal:            j,
+		Hello:              hello,
+		ProxyAddress:       c.String(flagTorProxy),
+		OnionAddress:       c.String(flagOnionAddr),
+		Relays:             relays,
+		RelayService:       c.Bool(flagRelay),
+		NoPortMapping:      c.Bool(flagNoPortMap),
+		MaxStreamsPerPeer:  int(c.Uint(flagMaxStreams)),
+		MaxStreamsPerIP:    int(c.Uint(flagMaxIPStream)),
+		PeerStore:          &peerStore{db: d.database},
+		MDNS:               c.Bool(flagMDNS),
+		DHTMode:            dhtMode,
+		DHTRefreshInterval: c.Duration(flagDHTRefresh),
+		AdvertiseInterval:  c.Duration(flagAdvertise),
+		OrderbookInterval:  c.Duration(flagOrderbook),
+	}
+
+	if c.String(flagAllowTakers) != "" || c.String(flagDenyTakers) != "" {
+		netCfg.TakerFilter = new(types.TakerFilter)
+		if c.String(flagAllowTakers) != "" {
+			netCfg.TakerFilter.Allow = strings.Split(c.String(flagAllowTakers), ",")
+		}
+		if c.String(flagDenyTakers) != "" {
+			netCfg.TakerFilter.Deny = strings.Split(c.String(flagDenyTakers), ",")
+		}
 	}
 
 	host, err := net.NewHost(netCfg)
@@ -317,13 +750,53 @@ func (d *daemon) make(c *cli.Context) error {
 		return err
 	}
 
-	sm := swap.NewManager()
-	backend, err := newBackend(d.ctx, c, env, cfg, chainID, devXMRMaker, sm, host)
+	var fence func() error
+	if lease != nil {
+		fence = lease.Check
+	}
+
+	var (
+		sj swap.StatusJournal = j
+		wj *watchtowerJournal
+	)
+	if dir := c.String(flagWatchtowerDir); dir != "" {
+		wj = newWatchtowerJournal(j, d.database, dir)
+		sj = wj
+	}
+
+	sm, err := swap.NewManagerWithDatabase(d.database, sj)
 	if err != nil {
 		return err
 	}
 
-	a, b, err := getProtocolInstances(c, cfg, backend)
+	trackSwapOutcomes(d.ctx, sm.Events(), host)
+
+	// webhooks are set up before swaps are resumed, so that they're told about swaps needing recovery
+	if err = setupWebhooks(d.ctx, c, sm.Events()); err != nil {
+		return err
+	}
+
+	backend, err := newBackend(d.ctx, c, env, cfg, chainID, devXMRMaker, sm, host, fence, d.database)
+	if err != nil {
+		return err
+	}
+
+	if wj != nil {
+		wj.setBackend(backend)
+	}
+
+	if lease != nil {
+		if err = d.activate(c, cfg, backend, lease); err != nil {
+			return err
+		}
+	}
+
+	pending, err := resumeSwaps(j, d.database, backend, sm, cfg.Basepath, c.Bool(flagManualRecovery))
+	if err != nil {
+		return err
+	}
+
+	a, b, err := getProtocolInstances(c, cfg, backend, d.database)
 	if err != nil {
 		return err
 	}
@@ -336,6 +809,11 @@ func (d *daemon) make(c *cli.Context) error {
 		return err
 	}
 
+	// offers listed before a restart are advertised again straight away
+	if len(b.GetOffers()) != 0 {
+		host.Advertise()
+	}
+
 	p = uint16(c.Uint(flagRPCPort))
 	switch {
 	case p != 0:
@@ -359,6 +837,27 @@ func (d *daemon) make(c *cli.Context) error {
 		wsPort = defaultWSPort
 	}
 
+	tracker := utilization.NewTracker(&utilization.Config{
+		Backend: backend,
+		Offers:  b,
+	})
+	tracker.Start(d.ctx)
+
+	adminTokenFile := filepath.Join(cfg.Basepath, rpc.AdminTokenFilename)
+	adminToken, err := rpc.NewAdminToken(adminTokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to write RPC admin token: %w", err)
+	}
+	log.Infof("wrote RPC admin token to %s", adminTokenFile)
+
+	backupDir := c.String(flagDBBackupDir)
+	if backupDir == "" {
+		backupDir = filepath.Join(cfg.Basepath, "db-backups")
+	}
+	if err = os.MkdirAll(backupDir, 0700); err != nil {
+		return err
+	}
+
 	rpcCfg := &rpc.Config{
 		Ctx:             d.ctx,
 		Port:            rpcPort,
@@ -367,6 +866,31 @@ func (d *daemon) make(c *cli.Context) error {
 		XMRTaker:        a,
 		XMRMaker:        b,
 		ProtocolBackend: backend,
+		Utilization:     tracker,
+		Preflight:       preflight.NewChecker(backend),
+		Database:        d.database,
+		PendingRecovery: pending,
+		AdminToken:      adminToken,
+		BackupDir:       backupDir,
+		TLSCertFile:     c.String(flagRPCTLSCert),
+		TLSKeyFile:      c.String(flagRPCTLSKey),
+		GRPCPort:        uint16(c.Uint(flagGRPCPort)),
+		WsLimits: &rpc.WsLimits{
+			MaxConnections:          int(c.Uint(flagWSMaxConns)),
+			MaxSubscriptionsPerConn: int(c.Uint(flagWSMaxSubs)),
+			MaxMessageSize:          int64(c.Uint(flagWSMaxMsg)),
+			MessagesPerSecond:       c.Float64(flagWSRate),
+			MessageBurst:            int(c.Uint(flagWSBurst)),
+			WriteTimeout:            c.Duration(flagWSTimeout),
+		},
+	}
+
+	if (rpcCfg.TLSCertFile == "") != (rpcCfg.TLSKeyFile == "") {
+		return errRPCTLSIncomplete
+	}
+
+	if c.String(flagRPCOrigins) != "" {
+		rpcCfg.AllowedOrigins = strings.Split(c.String(flagRPCOrigins), ",")
 	}
 
 	s, err := rpc.NewServer(rpcCfg)
@@ -374,6 +898,10 @@ func (d *daemon) make(c *cli.Context) error {
 		return err
 	}
 
+	if err = setupRebalancer(d.ctx, c, backend, s.NetService()); err != nil {
+		return err
+	}
+
 	errCh := s.Start()
 	go func() {
 		select {
@@ -386,16 +914,63 @@ func (d *daemon) make(c *cli.Context) error {
 		}
 	}()
 
-	log.Infof("started swapd with basepath %s",
+	if port := uint16(c.Uint(flagMetricsPort)); port != 0 {
+		if err = d.startMetrics(port, sm, host, tracker); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("started swapd %s with basepath %s",
+		pcommon.NewVersionInfo(env, big.NewInt(chainID)),
 		cfg.Basepath,
 	)
 	return nil
 }
 
+// startMetrics serves the daemon's metrics on the given port.
+func (d *daemon) startMetrics(port uint16, sm swap.Manager, host metrics.Net, tracker *utilization.Tracker) error {
+	s, err := metrics.NewServer(&metrics.Config{
+		Ctx:         d.ctx,
+		Port:        port,
+		SwapManager: sm,
+		Net:         host,
+		Balances: func(ctx context.Context) (*metrics.Balances, error) {
+			snapshot, err := tracker.Snapshot(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return &metrics.Balances{
+				ETH: snapshot.IdleETH,
+				XMR: snapshot.IdleXMR,
+			}, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	errCh := s.Start()
+	go func() {
+		select {
+		case <-d.ctx.Done():
+			return
+		case err := <-errCh:
+			log.Errorf("failed to start metrics server: %s", err)
+			d.cancel()
+			os.Exit(1)
+		}
+	}()
+
+	return nil
+}
+
 func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg common.Config,
-	chainID int64, devXMRMaker bool, sm swap.Manager, net net.Host) (backend.Backend, error) {
+	chainID int64, devXMRMaker bool, sm swap.Manager, net net.Host, fence func() error,
+	database db.Database) (backend.Backend, error) {
 	var (
-		moneroEndpoint, daemonEndpoint, ethEndpoint string
+		moneroEndpoint, daemonEndpoint string
+		ethEndpoints                   []string
 	)
 
 	if c.String(flagMoneroWalletEndpoint) != "" {
@@ -407,9 +982,9 @@ func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg
 	}
 
 	if c.String(flagEthereumEndpoint) != "" {
-		ethEndpoint = c.String(flagEthereumEndpoint)
+		ethEndpoints = strings.Split(c.String(flagEthereumEndpoint), ",")
 	} else {
-		ethEndpoint = common.DefaultEthEndpoint
+		ethEndpoints = []string{common.DefaultEthEndpoint}
 	}
 
 	ethPrivKey, err := utils.GetEthereumPrivateKey(c, env, devXMRMaker, c.Bool(flagUseExternalSigner))
@@ -425,6 +1000,11 @@ func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg
 		}
 	}
 
+	hdWallet, err := utils.GetEthereumHDWallet(c)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.String(flagMoneroDaemonEndpoint) != "" {
 		daemonEndpoint = c.String(flagMoneroDaemonEndpoint)
 	} else {
@@ -437,6 +1017,19 @@ func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg
 		gasPrice = big.NewInt(int64(c.Uint(flagGasPrice)))
 	}
 
+	confirmations := uint64(c.Uint(flagEthConfirmations))
+	if confirmations == 0 {
+		confirmations = cfg.EthereumConfirmations
+	}
+
+	var broadcastCfg *txsender.BroadcastConfig
+	if c.String(flagBroadcastConfig) != "" {
+		broadcastCfg, err = txsender.LoadBroadcastConfig(c.String(flagBroadcastConfig), chainID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var contractAddr ethcommon.Address
 	contractAddrStr := c.String(flagContractAddress)
 	if contractAddrStr == "" {
@@ -445,10 +1038,16 @@ func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg
 		contractAddr = ethcommon.HexToAddress(contractAddrStr)
 	}
 
-	ec, err := ethclient.Dial(ethEndpoint)
+	var ec backend.EthClient
+	if len(ethEndpoints) == 1 {
+		ec, err = ethclient.Dial(ethEndpoints[0])
+	} else {
+		ec, err = backend.NewFailoverClient(ctx, ethEndpoints)
+	}
 	if err != nil {
 		return nil, err
 	}
+	ec = backend.NewInstrumentedClient(ec)
 
 	deploy := c.Bool(flagDeploy)
 	if deploy {
@@ -467,14 +1066,19 @@ func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg
 		MoneroDaemonEndpoint: daemonEndpoint,
 		EthereumClient:       ec,
 		EthereumPrivateKey:   pk,
+		EthereumHDWallet:     hdWallet,
+		Database:             database,
 		Environment:          env,
 		ChainID:              big.NewInt(chainID),
 		GasPrice:             gasPrice,
 		GasLimit:             uint64(c.Uint(flagGasLimit)),
+		Confirmations:        confirmations,
 		SwapManager:          sm,
 		SwapContract:         contract,
 		SwapContractAddress:  contractAddr,
 		Net:                  net,
+		Broadcast:            broadcastCfg,
+		Fence:                fence,
 	}
 
 	b, err := backend.NewBackend(bcfg)
@@ -482,16 +1086,16 @@ func newBackend(ctx context.Context, c *cli.Context, env common.Environment, cfg
 		return nil, fmt.Errorf("failed to make backend: %w", err)
 	}
 
-	log.Infof("created backend with monero endpoint %s and ethereum endpoint %s",
+	log.Infof("created backend with monero endpoint %s and ethereum endpoints %v",
 		moneroEndpoint,
-		ethEndpoint,
+		ethEndpoints,
 	)
 
 	return b, nil
 }
 
 func getProtocolInstances(c *cli.Context, cfg common.Config,
-	b backend.Backend) (xmrtakerHandler, xmrmakerHandler, error) {
+	b backend.Backend, database db.Database) (xmrtakerHandler, xmrmakerHandler, error) {
 	walletFile := c.String("wallet-file")
 
 	// empty password is ok
@@ -502,7 +1106,15 @@ func getProtocolInstances(c *cli.Context, cfg common.Config,
 		Basepath:             cfg.Basepath,
 		MoneroWalletFile:     walletFile,
 		MoneroWalletPassword: walletPassword,
-		TransferBack:         c.Bool(flagTransferBack),
+		// XMR swapped back when rebalancing must end up in our wallet
+		TransferBack: c.Bool(flagTransferBack) || c.Float64(flagRebalanceThreshold) != 0,
+		ReadyPolicy: xmrtaker.ReadyPolicy{
+			MoneroConfirmations: uint64(c.Uint(flagMoneroConfirmations)),
+			MinDelay:            c.Duration(flagReadyMinDelay),
+			Manual:              c.Bool(flagManualReady),
+		},
+		SweepAddress: mcrypto.Address(c.String(flagSweepAddress)),
+		Database:     database,
 	}
 
 	xmrtaker, err := xmrtaker.NewInstance(xmrtakerCfg)
@@ -511,10 +1123,30 @@ func getProtocolInstances(c *cli.Context, cfg common.Config,
 	}
 
 	xmrmakerCfg := &xmrmaker.Config{
-		Backend:        b,
-		Basepath:       cfg.Basepath,
-		WalletFile:     walletFile,
-		WalletPassword: walletPassword,
+		Backend:             b,
+		Basepath:            cfg.Basepath,
+		WalletFile:          walletFile,
+		WalletPassword:      walletPassword,
+		QuoteValidity:       c.Duration(flagQuoteValidity),
+		RateRefreshInterval: c.Duration(flagRateRefreshInterval),
+		MinSwapAmount:       c.Float64(flagMinSwapAmount),
+		OfferTTL:            c.Duration(flagOfferTTL),
+		Database:            database,
+	}
+
+	if addr := c.String(flagPayoutAddress); addr != "" {
+		if !ethcommon.IsHexAddress(addr) {
+			return nil, nil, errInvalidPayoutAddress
+		}
+
+		xmrmakerCfg.PayoutAddress = ethcommon.HexToAddress(addr)
+	}
+
+	if name := c.String(flagPriceOracle); name != "" {
+		xmrmakerCfg.Oracle, err = oracle.NewSource(name, b)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	xmrmaker, err := xmrmaker.NewInstance(xmrmakerCfg)
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/net"
+)
+
+// peerStore persists the libp2p peers we've been connected to in the database, so that we can
+// reconnect to them after a restart.
+type peerStore struct {
+	db db.Database
+}
+
+func (s *peerStore) SavePeers(peers []*net.SavedPeer) error {
+	kvs := make(map[string][]byte, len(peers))
+	for _, p := range peers {
+		value, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		kvs[p.Peer.ID.String()] = value
+	}
+
+	return s.db.ReplaceBucket(db.PeersBucket, kvs)
+}
+
+func (s *peerStore) LoadPeers() ([]*net.SavedPeer, error) {
+	var peers []*net.SavedPeer
+	err := s.db.Iterate(db.PeersBucket, func(key, value []byte) error {
+		var p *net.SavedPeer
+		if err := json.Unmarshal(value, &p); err != nil {
+			// a peer that can't be decoded is only a peer we won't reconnect to
+			log.Warnf("failed to decode saved peer %s: %s", key, err)
+			return nil
+		}
+		peers = append(peers, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return peers, nil
+}
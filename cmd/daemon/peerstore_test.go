@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/net"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerStore(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close()
+
+	info, err := peer.AddrInfoFromP2pAddr(ma.StringCast(
+		"/ip4/127.0.0.1/tcp/9934/p2p/12D3KooWC547RfLcveQi1vBxACjnT6Uv15V11ortDTuxRWuhubGv"))
+	require.NoError(t, err)
+
+	s := &peerStore{db: d}
+	peers, err := s.LoadPeers()
+	require.NoError(t, err)
+	require.Empty(t, peers)
+
+	saved := &net.SavedPeer{
+		Peer:     *info,
+		LastSeen: time.Unix(1600000000, 0).UTC(),
+	}
+	require.NoError(t, s.SavePeers([]*net.SavedPeer{saved}))
+
+	peers, err = s.LoadPeers()
+	require.NoError(t, err)
+	require.Len(t, peers, 1)
+	require.Equal(t, saved.Peer.ID, peers[0].Peer.ID)
+	require.Equal(t, saved.Peer.Addrs[0].String(), peers[0].Peer.Addrs[0].String())
+	require.True(t, saved.LastSeen.Equal(peers[0].LastSeen))
+
+	// saving replaces the peers saved before
+	require.NoError(t, s.SavePeers(nil))
+	peers, err = s.LoadPeers()
+	require.NoError(t, err)
+	require.Empty(t, peers)
+}
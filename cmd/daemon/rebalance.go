@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/oracle"
+	"github.com/noot/atomic-swap/protocol/rebalance"
+	"github.com/noot/atomic-swap/rpc"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	errRebalanceRequiresOracle = errors.New("--rebalance-threshold requires --price-oracle to value our XMR")
+)
+
+// setupRebalancer starts swapping claimed ETH back to XMR, by taking other makers' offers, once
+// our ETH balance exceeds --rebalance-threshold. It does nothing if that isn't set.
+func setupRebalancer(ctx context.Context, c *cli.Context, b backend.Backend, ns *rpc.NetService) error {
+	threshold := c.Float64(flagRebalanceThreshold)
+	if threshold == 0 {
+		return nil
+	}
+
+	name := c.String(flagPriceOracle)
+	if name == "" {
+		return errRebalanceRequiresOracle
+	}
+
+	source, err := oracle.NewSource(name, b)
+	if err != nil {
+		return err
+	}
+
+	cfg := &rebalance.Config{
+		ThresholdETH: threshold,
+		MinXMRRatio:  c.Float64(flagRebalanceMinXMRRatio),
+		MaxXMRRatio:  c.Float64(flagRebalanceMaxXMRRatio),
+		ReserveETH:   c.Float64(flagRebalanceReserve),
+		MaxSpread:    c.Float64(flagRebalanceMaxSpread),
+		Interval:     c.Duration(flagRebalanceInterval),
+	}
+
+	take := func(amount float64, maxRate types.ExchangeRate) (types.Hash, error) {
+		req := &rpctypes.TakeBestOfferRequest{
+			ProvidesAmount:  amount,
+			MaxExchangeRate: maxRate,
+		}
+
+		resp := new(rpctypes.TakeBestOfferResponse)
+		if takeErr := ns.TakeBestOffer(nil, req, resp); takeErr != nil {
+			return types.Hash{}, takeErr
+		}
+
+		// the taker's swap ID is the ID of the offer it took
+		return types.HexToHash(resp.OfferID)
+	}
+
+	r, err := rebalance.NewRebalancer(cfg, rebalance.NewBackendBalances(b), source.Rate, take, b.SwapManager())
+	if err != nil {
+		return err
+	}
+
+	r.Start(ctx)
+	log.Infof("rebalancing once our ETH balance exceeds %v ETH, using the %s price oracle", threshold, source)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	"github.com/noot/atomic-swap/net"
+	"github.com/noot/atomic-swap/protocol/events"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// trackSwapOutcomes updates the reputation of each swap's counterparty once the swap completes.
+func trackSwapOutcomes(ctx context.Context, bus *events.Bus, host net.Host) {
+	eventCh, unsubscribe := bus.Subscribe()
+	go func() {
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-eventCh:
+				if !ok {
+					return
+				}
+
+				if e.Status.IsOngoing() || e.PeerID == "" {
+					continue
+				}
+
+				who, err := peer.Decode(e.PeerID)
+				if err != nil {
+					log.Warnf("swap %s has invalid peer ID %q: %s", e.SwapID, e.PeerID, err)
+					continue
+				}
+
+				host.RecordSwapOutcome(who, e.Status)
+			}
+		}
+	}()
+}
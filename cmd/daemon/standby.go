@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/standby"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	errStandbyRequiresLease     = errors.New("must provide --ha-lease-file when using --standby")
+	errStandbyRequiresBackupDir = errors.New("--standby requires --backup-target to be a directory shared with the active daemon") //nolint:lll
+	errLeaseWithExternalSigner  = errors.New("--ha-lease-file can't be used with --external-signer")
+)
+
+// setupLease returns the active/standby lease, or nil if the daemon isn't running in
+// active/standby mode.
+func setupLease(c *cli.Context) (*standby.Lease, error) {
+	path := c.String(flagHALeaseFile)
+	if path == "" {
+		if c.Bool(flagStandby) {
+			return nil, errStandbyRequiresLease
+		}
+
+		return nil, nil
+	}
+
+	if c.Bool(flagUseExternalSigner) {
+		return nil, errLeaseWithExternalSigner
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	holder := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	return standby.NewLease(path, holder, c.Duration(flagHALeaseTTL))
+}
+
+// activate makes this daemon the active daemon. If running as a standby, it first waits for
+// the active daemon's lease to expire and finishes its ongoing swaps.
+func (d *daemon) activate(c *cli.Context, cfg common.Config, b backend.Backend, lease *standby.Lease) error {
+	if c.Bool(flagStandby) {
+		if err := d.runStandby(c, cfg, b, lease); err != nil {
+			return err
+		}
+	} else if err := lease.Acquire(); err != nil {
+		return fmt.Errorf("failed to acquire lease %s, is another daemon active? %w", c.String(flagHALeaseFile), err)
+	}
+
+	log.Infof("running as active daemon: lease epoch=%d", lease.Epoch())
+	go lease.KeepAlive(d.ctx, func() {
+		log.Errorf("lost lease to another daemon, shutting down")
+		d.cancel()
+		os.Exit(1)
+	})
+	return nil
+}
+
+func (d *daemon) runStandby(c *cli.Context, cfg common.Config, b backend.Backend, lease *standby.Lease) error {
+	backupDir := c.String(flagBackupTarget)
+	if !strings.HasPrefix(backupDir, "/") {
+		return errStandbyRequiresBackupDir
+	}
+
+	password, err := readBackupPassword(c)
+	if err != nil {
+		return err
+	}
+
+	s := standby.NewStandby(&standby.Config{
+		Backend:        b,
+		Basepath:       cfg.Basepath,
+		Lease:          lease,
+		BackupDir:      backupDir,
+		BackupPassword: password,
+	})
+
+	err = s.Run(d.ctx)
+	if errors.Is(err, context.Canceled) {
+		os.Exit(0)
+	}
+	return err
+}
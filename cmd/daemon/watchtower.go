@@ -0,0 +1,85 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/watchtower"
+)
+
+// watchtowerJournal registers swaps with a watchtower as their status changes: once a swap's ether
+// is locked it's registered, and once it's completed it's unregistered.
+type watchtowerJournal struct {
+	swap.StatusJournal
+	d   db.Database
+	dir string
+
+	mu sync.RWMutex
+	// backend is set once it's created, after the swap manager the journal is passed to
+	backend backend.Backend
+}
+
+func newWatchtowerJournal(j swap.StatusJournal, d db.Database, dir string) *watchtowerJournal {
+	log.Infof("registering swaps with the watchtower in %s", dir)
+	return &watchtowerJournal{
+		StatusJournal: j,
+		d:             d,
+		dir:           dir,
+	}
+}
+
+func (w *watchtowerJournal) setBackend(b backend.Backend) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.backend = b
+}
+
+func (w *watchtowerJournal) StatusChanged(id types.Hash, status types.Status) error {
+	if err := w.StatusJournal.StatusChanged(id, status); err != nil {
+		return err
+	}
+
+	if !status.IsOngoing() {
+		if err := watchtower.RemoveRegistration(w.dir, id); err != nil {
+			log.Warnf("failed to unregister swap %s from watchtower: %s", id, err)
+		}
+		return nil
+	}
+
+	if err := w.register(id); err != nil {
+		log.Warnf("failed to register swap %s with watchtower: %s", id, err)
+	}
+	return nil
+}
+
+// register writes the swap's registration, if its ether has been locked.
+func (w *watchtowerJournal) register(id types.Hash) error {
+	w.mu.RLock()
+	b := w.backend
+	w.mu.RUnlock()
+	if b == nil {
+		return nil
+	}
+
+	info, err := pcommon.FindInfoInDatabase(w.d, id)
+	if err != nil || (info.ContractSwapID == [32]byte{}) {
+		// the ether hasn't been locked yet
+		return nil
+	}
+
+	action := watchtower.ActionRefund
+	if b.HasEthAddress(info.ContractSwap.Claimer) {
+		action = watchtower.ActionClaim
+	}
+
+	r, err := watchtower.NewRegistration(id, info, action)
+	if err != nil {
+		return err
+	}
+
+	return watchtower.WriteRegistration(w.dir, r)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/webhooks"
+
+	"github.com/urfave/cli"
+)
+
+var (
+	errNoWebhookSecret = errors.New("must provide --webhook-secret-file when using --webhooks")
+)
+
+// setupWebhooks starts POSTing swap events to the webhooks, if any are set.
+func setupWebhooks(ctx context.Context, c *cli.Context, bus *events.Bus) error {
+	if c.String(flagWebhooks) == "" {
+		return nil
+	}
+
+	secretFile := c.String(flagWebhookSecretFile)
+	if secretFile == "" {
+		return errNoWebhookSecret
+	}
+
+	secret, err := os.ReadFile(filepath.Clean(secretFile))
+	if err != nil {
+		return err
+	}
+
+	var evts []webhooks.Event
+	if c.String(flagWebhookEvents) != "" {
+		evts, err = webhooks.ParseEvents(c.String(flagWebhookEvents))
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := webhooks.NewNotifier(&webhooks.Config{
+		Ctx:     ctx,
+		Bus:     bus,
+		Targets: strings.Split(c.String(flagWebhooks), ","),
+		Secret:  []byte(strings.TrimSpace(string(secret))),
+		Events:  evts,
+	})
+	if err != nil {
+		return err
+	}
+
+	n.Start()
+	log.Infof("sending swap events to webhooks %s", c.String(flagWebhooks))
+	return nil
+}
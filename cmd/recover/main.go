@@ -2,21 +2,15 @@ package main
 
 import (
 	"context"
-	"encoding/json"
-	"io/ioutil"
-	"math/big"
 	"os"
 	"path/filepath"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/urfave/cli"
 
 	"github.com/noot/atomic-swap/cmd/utils"
 	"github.com/noot/atomic-swap/common"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
-	pcommon "github.com/noot/atomic-swap/protocol"
 	"github.com/noot/atomic-swap/protocol/backend"
 	"github.com/noot/atomic-swap/protocol/xmrmaker"
 	"github.com/noot/atomic-swap/protocol/xmrtaker"
@@ -27,16 +21,24 @@ import (
 )
 
 const (
-	flagEnv                  = "env"
-	flagMoneroWalletEndpoint = "monero-endpoint"
-	flagEthereumEndpoint     = "ethereum-endpoint"
-	flagEthereumPrivateKey   = "ethereum-privkey"
-	flagEthereumChainID      = "ethereum-chain-id"
-	flagGasPrice             = "gas-price"
-	flagGasLimit             = "gas-limit"
-	flagInfoFile             = "infofile"
-	flagXMRMaker             = "xmrmaker"
-	flagXMRTaker             = "xmrtaker"
+	flagEnv                          = "env"
+	flagMoneroWalletEndpoint         = "monero-endpoint"
+	flagEthereumEndpoint             = "ethereum-endpoint"
+	flagEthereumPrivateKey           = "ethereum-privkey"
+	flagEthereumKeystore             = "ethereum-keystore"
+	flagEthereumKeystorePasswordFile = "ethereum-keystore-password-file"
+	flagEthereumHDWallet             = "ethereum-hd-wallet"
+	flagEthereumChainID              = "ethereum-chain-id"
+	flagGasPrice                     = "gas-price"
+	flagGasLimit                     = "gas-limit"
+	flagInfoFile                     = "infofile"
+	flagDatabase                     = "db"
+	flagBackupPasswordFile           = "backup-password-file"
+	flagBundlePassphraseFile         = "bundle-passphrase-file"
+	flagContractAddress              = "contract-address"
+	flagFromBlock                    = "from-block"
+	flagXMRMaker                     = "xmrmaker"
+	flagXMRTaker                     = "xmrtaker"
 )
 
 var (
@@ -69,7 +71,19 @@ var (
 			},
 			&cli.StringFlag{
 				Name:  flagEthereumPrivateKey,
-				Usage: "file containing a private key hex string",
+				Usage: "file containing a private key hex string; prefer --ethereum-keystore",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumKeystore,
+				Usage: "ethereum keystore (v3) file containing the encrypted private key",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumKeystorePasswordFile,
+				Usage: "file containing the ethereum keystore password; if not set, the password is prompted for",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumHDWallet,
+				Usage: "file containing a BIP-39 mnemonic or BIP-32 xprv; each swap uses a fresh ethereum account derived from it", //nolint:lll
 			},
 			&cli.UintFlag{
 				Name:  flagEthereumChainID,
@@ -85,7 +99,27 @@ var (
 			},
 			&cli.StringFlag{
 				Name:  flagInfoFile,
-				Usage: "path to swap infofile",
+				Usage: "path to swap infofile, or to a recovery bundle exported with `swapcli export-recovery-bundle`",
+			},
+			&cli.StringFlag{
+				Name:  flagDatabase,
+				Usage: "directory of the daemon's database; if set, the infofile is read from it. the daemon must be stopped",
+			},
+			&cli.StringFlag{
+				Name:  flagBackupPasswordFile,
+				Usage: "file containing the backup password; required if the infofile is an encrypted backup (.enc)",
+			},
+			&cli.StringFlag{
+				Name:  flagBundlePassphraseFile,
+				Usage: "file containing the recovery bundle's passphrase; prompted for if unset",
+			},
+			&cli.StringFlag{
+				Name:  flagContractAddress,
+				Usage: "address of the swap contract; required if the infofile only has the swap's keys",
+			},
+			&cli.Uint64Flag{
+				Name:  flagFromBlock,
+				Usage: "block to search the swap contract's logs from if the infofile doesn't have the contract swap",
 			},
 			&cli.BoolFlag{
 				Name:  flagXMRMaker,
@@ -109,8 +143,8 @@ func main() {
 // Recoverer is implemented by a backend which is able to recover swap funds
 type Recoverer interface {
 	WalletFromSharedSecret(secret *mcrypto.PrivateKeyInfo) (mcrypto.Address, error)
-	RecoverFromXMRMakerSecretAndContract(b backend.Backend, basepath string, xmrmakerSecret, contractAddr string, swapID [32]byte, swap swapfactory.SwapFactorySwap) (*xmrmaker.RecoveryResult, error) //nolint:lll
-	RecoverFromXMRTakerSecretAndContract(b backend.Backend, basepath string, xmrtakerSecret string, swapID [32]byte, swap swapfactory.SwapFactorySwap) (*xmrtaker.RecoveryResult, error)               //nolint:lll
+	RecoverFromXMRMakerSecretAndContract(b backend.Backend, basepath string, xmrmakerSecret, contractAddr string, swapID [32]byte, swap swapfactory.SwapFactorySwap, swapBlock uint64) (*xmrmaker.RecoveryResult, error) //nolint:lll
+	RecoverFromXMRTakerSecretAndContract(b backend.Backend, basepath string, xmrtakerSecret string, swapID [32]byte, swap swapfactory.SwapFactorySwap, swapBlock uint64) (*xmrtaker.RecoveryResult, error)               //nolint:lll
 }
 
 type instance struct {
@@ -141,16 +175,11 @@ func (inst *instance) recover(c *cli.Context) error {
 		return errMustProvideInfoFile
 	}
 
-	infofileBytes, err := ioutil.ReadFile(filepath.Clean(infofilePath))
+	infofile, err := utils.ReadInfoFile(c, infofilePath)
 	if err != nil {
 		return err
 	}
 
-	var infofile *pcommon.InfoFileContents
-	if err = json.Unmarshal(infofileBytes, &infofile); err != nil {
-		return err
-	}
-
 	r, err := inst.getRecovererFunc(c, env)
 	if err != nil {
 		return err
@@ -166,19 +195,37 @@ func (inst *instance) recover(c *cli.Context) error {
 		return nil
 	}
 
+	if infofile.ContractAddress == "" {
+		infofile.ContractAddress = c.String(flagContractAddress)
+	}
+
 	contractAddr := infofile.ContractAddress
 	addr := ethcommon.HexToAddress(contractAddr)
 
-	b, err := createBackend(context.Background(), c, env, cfg, addr)
+	b, err := utils.NewRecoveryBackend(context.Background(), c, env, cfg, addr)
 	if err != nil {
 		return err
 	}
 
+	// if the infofile only has the swap's keys, the contract swap is searched for in the contract's logs
+	if contractAddr != "" {
+		if err = recovery.FillContractSwap(b, infofile, c.Uint64(flagFromBlock)); err != nil {
+			return err
+		}
+	}
+
+	// the swap may have used an account derived from the HD wallet rather than the base account
+	if infofile.EthereumKeyIndex != nil {
+		if _, err = b.RegisterEthKeyIndex(*infofile.EthereumKeyIndex); err != nil {
+			return err
+		}
+	}
+
 	basepath := filepath.Dir(filepath.Clean(infofilePath))
 
 	if xmrmaker {
 		res, err := r.RecoverFromXMRMakerSecretAndContract(b, basepath, infofile.PrivateKeyInfo.PrivateSpendKey,
-			contractAddr, infofile.ContractSwapID, infofile.ContractSwap)
+			contractAddr, infofile.ContractSwapID, infofile.ContractSwap, infofile.ContractSwapBlock)
 		if err != nil {
 			return err
 		}
@@ -196,7 +243,7 @@ func (inst *instance) recover(c *cli.Context) error {
 
 	if xmrtaker {
 		res, err := r.RecoverFromXMRTakerSecretAndContract(b, basepath, infofile.PrivateKeyInfo.PrivateSpendKey,
-			infofile.ContractSwapID, infofile.ContractSwap)
+			infofile.ContractSwapID, infofile.ContractSwap, infofile.ContractSwapBlock)
 		if err != nil {
 			return err
 		}
@@ -239,70 +286,3 @@ func getRecoverer(c *cli.Context, env common.Environment) (Recoverer, error) {
 	)
 	return recovery.NewRecoverer(env, moneroEndpoint, ethEndpoint)
 }
-
-func createBackend(ctx context.Context, c *cli.Context, env common.Environment,
-	cfg common.Config, contractAddr ethcommon.Address) (backend.Backend, error) {
-	var (
-		moneroEndpoint, ethEndpoint string
-	)
-
-	chainID := int64(c.Uint(flagEthereumChainID))
-	if chainID == 0 {
-		chainID = cfg.EthereumChainID
-	}
-
-	if c.String(flagMoneroWalletEndpoint) != "" {
-		moneroEndpoint = c.String(flagMoneroWalletEndpoint)
-	} else {
-		moneroEndpoint = common.DefaultXMRTakerMoneroEndpoint
-	}
-
-	if c.String(flagEthereumEndpoint) != "" {
-		ethEndpoint = c.String(flagEthereumEndpoint)
-	} else {
-		ethEndpoint = common.DefaultEthEndpoint
-	}
-
-	// TODO: add --external-signer option to allow front-end integration
-	ethPrivKey, err := utils.GetEthereumPrivateKey(c, env, false, false)
-	if err != nil {
-		return nil, err
-	}
-
-	// TODO: add configs for different eth testnets + L2 and set gas limit based on those, if not set
-	var gasPrice *big.Int
-	if c.Uint(flagGasPrice) != 0 {
-		gasPrice = big.NewInt(int64(c.Uint(flagGasPrice)))
-	}
-
-	pk, err := ethcrypto.HexToECDSA(ethPrivKey)
-	if err != nil {
-		return nil, err
-	}
-
-	ec, err := ethclient.Dial(ethEndpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	contract, err := swapfactory.NewSwapFactory(contractAddr, ec)
-	if err != nil {
-		return nil, err
-	}
-
-	bcfg := &backend.Config{
-		Ctx:                  ctx,
-		MoneroWalletEndpoint: moneroEndpoint,
-		MoneroDaemonEndpoint: common.DefaultMoneroDaemonEndpoint, // TODO: only set if env=development
-		EthereumClient:       ec,
-		EthereumPrivateKey:   pk,
-		Environment:          env,
-		ChainID:              big.NewInt(chainID),
-		GasPrice:             gasPrice,
-		GasLimit:             uint64(c.Uint(flagGasLimit)),
-		SwapContract:         contract,
-		SwapContractAddress:  contractAddr,
-	}
-
-	return backend.NewBackend(bcfg)
-}
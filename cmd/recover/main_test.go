@@ -81,14 +81,14 @@ func (r *mockRecoverer) WalletFromSharedSecret(_ *mcrypto.PrivateKeyInfo) (mcryp
 }
 
 func (r *mockRecoverer) RecoverFromXMRMakerSecretAndContract(b backend.Backend, _ string, xmrmakerSecret,
-	contractAddr string, swapID [32]byte, _ swapfactory.SwapFactorySwap) (*xmrmaker.RecoveryResult, error) {
+	contractAddr string, swapID [32]byte, _ swapfactory.SwapFactorySwap, _ uint64) (*xmrmaker.RecoveryResult, error) {
 	return &xmrmaker.RecoveryResult{
 		Claimed: true,
 	}, nil
 }
 
 func (r *mockRecoverer) RecoverFromXMRTakerSecretAndContract(b backend.Backend, _ string, xmrtakerSecret string,
-	swapID [32]byte, _ swapfactory.SwapFactorySwap) (*xmrtaker.RecoveryResult, error) {
+	swapID [32]byte, _ swapfactory.SwapFactorySwap, _ uint64) (*xmrtaker.RecoveryResult, error) {
 	return &xmrtaker.RecoveryResult{
 		Claimed: true,
 	}, nil
@@ -321,7 +321,7 @@ func (d *daemon) takeOffer(done <-chan struct{}) {
 	log.Infof("node %d taking offer %s", d.idx, offer.GetID().String())
 
 	takerStatusCh, err := wsc.TakeOfferAndSubscribe(peer,
-		offer.GetID().String(), providesAmount)
+		offer.GetID().String(), providesAmount, "", "", &types.SlippageLimits{MaxExchangeRate: offer.ExchangeRate})
 	if err != nil {
 		d.errCh <- err
 		return
@@ -373,6 +373,11 @@ func (d *daemon) makeOffer(done <-chan struct{}) {
 	offerID, statusCh, err := wsc.MakeOfferAndSubscribe(minProvidesAmount,
 		maxProvidesAmount,
 		getRandomExchangeRate(),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 	)
 	if err != nil {
 		log.Errorf("failed to make offer (node %d): %s", d.idx, err)
@@ -382,7 +387,7 @@ func (d *daemon) makeOffer(done <-chan struct{}) {
 			if isDev {
 				generateBlocks()
 			} else {
-				_, err := monero.WaitForBlocks(defaultMoneroClient, 10)
+				_, err := monero.NewBlockWaiter(defaultMoneroClient, nil).WaitForConfirmations(context.Background(), 10)
 				if err != nil {
 					log.Errorf("failed to wait for blocks: %s", err)
 				}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/db"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/backup"
+	"github.com/noot/atomic-swap/swapfactory"
+)
+
+// flags used to create a recovery backend and read info files
+const (
+	flagMoneroWalletEndpoint = "monero-endpoint"
+	flagEthereumEndpoint     = "ethereum-endpoint"
+	flagEthereumChainID      = "ethereum-chain-id"
+	flagGasPrice             = "gas-price"
+	flagGasLimit             = "gas-limit"
+	flagDatabase             = "db"
+	flagBackupPasswordFile   = "backup-password-file"
+	flagBundlePassphraseFile = "bundle-passphrase-file"
+)
+
+// ReadInfoFile reads the contents of the info file from the daemon's database if --db is given, and
+// otherwise from disk, decrypting it if it's an encrypted backup. The path may also be that of an
+// exported recovery bundle.
+func ReadInfoFile(c *cli.Context, infofilePath string) (*pcommon.InfoFileContents, error) {
+	if strings.HasSuffix(infofilePath, pcommon.RecoveryBundleSuffix) {
+		return readRecoveryBundle(c, infofilePath)
+	}
+
+	if dir := c.String(flagDatabase); dir != "" {
+		d, err := db.NewDatabase(dir)
+		if err != nil {
+			return nil, err
+		}
+		defer d.Close() //nolint:errcheck
+
+		return pcommon.ReadInfoFromDatabase(d, infofilePath)
+	}
+
+	infofileBytes, err := ioutil.ReadFile(filepath.Clean(infofilePath))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(infofilePath, ".enc") {
+		infofileBytes, err = decryptBackup(c, infofileBytes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var infofile *pcommon.InfoFileContents
+	if err = json.Unmarshal(infofileBytes, &infofile); err != nil {
+		return nil, err
+	}
+
+	return infofile, nil
+}
+
+// NewRecoveryBackend returns a backend for recovering the funds of a swap made with the given swap
+// contract, configured by the CLI options.
+func NewRecoveryBackend(ctx context.Context, c *cli.Context, env common.Environment,
+	cfg common.Config, contractAddr ethcommon.Address) (backend.Backend, error) {
+	var (
+		moneroEndpoint, ethEndpoint string
+	)
+
+	chainID := int64(c.Uint(flagEthereumChainID))
+	if chainID == 0 {
+		chainID = cfg.EthereumChainID
+	}
+
+	if c.String(flagMoneroWalletEndpoint) != "" {
+		moneroEndpoint = c.String(flagMoneroWalletEndpoint)
+	} else {
+		moneroEndpoint = common.DefaultXMRTakerMoneroEndpoint
+	}
+
+	if c.String(flagEthereumEndpoint) != "" {
+		ethEndpoint = c.String(flagEthereumEndpoint)
+	} else {
+		ethEndpoint = common.DefaultEthEndpoint
+	}
+
+	// TODO: add --external-signer option to allow front-end integration
+	ethPrivKey, err := GetEthereumPrivateKey(c, env, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO: add configs for different eth testnets + L2 and set gas limit based on those, if not set
+	var gasPrice *big.Int
+	if c.Uint(flagGasPrice) != 0 {
+		gasPrice = big.NewInt(int64(c.Uint(flagGasPrice)))
+	}
+
+	var pk *ecdsa.PrivateKey
+	if ethPrivKey != "" {
+		pk, err = ethcrypto.HexToECDSA(ethPrivKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hdWallet, err := GetEthereumHDWallet(c)
+	if err != nil {
+		return nil, err
+	}
+
+	ec, err := ethclient.Dial(ethEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := swapfactory.NewSwapFactory(contractAddr, ec)
+	if err != nil {
+		return nil, err
+	}
+
+	bcfg := &backend.Config{
+		Ctx:                  ctx,
+		MoneroWalletEndpoint: moneroEndpoint,
+		MoneroDaemonEndpoint: common.DefaultMoneroDaemonEndpoint, // TODO: only set if env=development
+		EthereumClient:       ec,
+		EthereumPrivateKey:   pk,
+		EthereumHDWallet:     hdWallet,
+		Environment:          env,
+		ChainID:              big.NewInt(chainID),
+		GasPrice:             gasPrice,
+		GasLimit:             uint64(c.Uint(flagGasLimit)),
+		Confirmations:        cfg.EthereumConfirmations,
+		SwapContract:         contract,
+		SwapContractAddress:  contractAddr,
+	}
+
+	return backend.NewBackend(bcfg)
+}
+
+// GetBundlePassphrase returns the recovery bundle passphrase read from --bundle-passphrase-file,
+// or prompts for it if unset.
+func GetBundlePassphrase(c *cli.Context) ([]byte, error) {
+	passphraseFile := c.String(flagBundlePassphraseFile)
+	if passphraseFile == "" {
+		passphrase, err := prompt.Stdin.PromptPassword("Recovery bundle passphrase: ")
+		return []byte(passphrase), err
+	}
+
+	passphrase, err := ioutil.ReadFile(filepath.Clean(passphraseFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recovery bundle passphrase file: %w", err)
+	}
+
+	// only strip the trailing newline, as the passphrase may contain other whitespace
+	return []byte(strings.TrimRight(string(passphrase), "\r\n")), nil
+}
+
+// readRecoveryBundle decrypts the recovery bundle exported by swapd, and returns the swap's info.
+func readRecoveryBundle(c *cli.Context, path string) (*pcommon.InfoFileContents, error) {
+	env, _, err := GetEnvironment(c)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := GetBundlePassphrase(c)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle, err := pcommon.ImportRecoveryBundle(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import recovery bundle: %w", err)
+	}
+
+	if bundle.Environment != env.String() {
+		return nil, fmt.Errorf("recovery bundle is for environment %s, but --env is %s", bundle.Environment, env)
+	}
+
+	return bundle.Info, nil
+}
+
+// decryptBackup decrypts an infofile that was written to a secondary backup by swapd.
+func decryptBackup(c *cli.Context, data []byte) ([]byte, error) {
+	passwordFile := c.String(flagBackupPasswordFile)
+	if passwordFile == "" {
+		return nil, errMustProvideBackupPassword
+	}
+
+	password, err := ioutil.ReadFile(filepath.Clean(passwordFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Decrypt([]byte(strings.TrimSpace(string(password))), data)
+}
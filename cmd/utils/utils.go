@@ -7,15 +7,22 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/console/prompt"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	logging "github.com/ipfs/go-log"
 	"github.com/urfave/cli"
 
 	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/crypto/hdwallet"
 )
 
 const (
-	flagEthereumPrivKey = "ethereum-privkey"
-	flagEnv             = "env"
+	flagEthereumPrivKey              = "ethereum-privkey"
+	flagEthereumKeystore             = "ethereum-keystore"
+	flagEthereumKeystorePasswordFile = "ethereum-keystore-password-file"
+	flagEthereumHDWallet             = "ethereum-hd-wallet"
+	flagEnv                          = "env"
 )
 
 var log = logging.Logger("cmd")
@@ -23,13 +30,42 @@ var log = logging.Logger("cmd")
 var defaultEnvironment = common.Development
 
 var (
-	errNoEthereumPrivateKey = errors.New("must provide --ethereum-privkey file for non-development environment")
-	errInvalidEnv           = errors.New("--env must be one of mainnet, stagenet, or dev")
+	errNoEthereumPrivateKey      = errors.New("must provide --ethereum-keystore, --ethereum-privkey, --ethereum-hd-wallet or --external-signer for non-development environment") //nolint:lll
+	errInvalidEnv                = errors.New("--env must be one of mainnet, stagenet, or dev")
+	errKeystoreAndPrivKey        = errors.New("must provide only one of --ethereum-keystore and --ethereum-privkey")
+	errMustProvideBackupPassword = errors.New("must provide --backup-password-file to decrypt an encrypted infofile")
+	errExternalSignerAndKey      = errors.New("--external-signer can't be used with --ethereum-keystore, --ethereum-privkey or --ethereum-hd-wallet") //nolint:lll
 )
 
 // GetEthereumPrivateKey returns an ethereum private key hex string given the CLI options.
+// The key is loaded from an encrypted keystore file if --ethereum-keystore is set; the
+// keystore password is read from --ethereum-keystore-password-file, or prompted for if unset.
 func GetEthereumPrivateKey(c *cli.Context, env common.Environment, devXMRMaker,
 	useExternal bool) (ethPrivKeyHex string, err error) {
+	if useExternal {
+		// all transactions are signed by the external signer; the daemon never holds a key
+		if c.String(flagEthereumKeystore) != "" || c.String(flagEthereumPrivKey) != "" ||
+			c.String(flagEthereumHDWallet) != "" {
+			return "", errExternalSignerAndKey
+		}
+
+		log.Info("no ethereum private key loaded, transactions will be signed by the external signer")
+		return "", nil
+	}
+
+	if c.String(flagEthereumKeystore) != "" {
+		if c.String(flagEthereumPrivKey) != "" {
+			return "", errKeystoreAndPrivKey
+		}
+
+		password, err := getKeystorePassword(c)
+		if err != nil {
+			return "", err
+		}
+
+		return LoadKeystore(c.String(flagEthereumKeystore), password)
+	}
+
 	if c.String(flagEthereumPrivKey) != "" {
 		ethPrivKeyFile := c.String(flagEthereumPrivKey)
 		key, err := os.ReadFile(filepath.Clean(ethPrivKeyFile))
@@ -38,12 +74,15 @@ func GetEthereumPrivateKey(c *cli.Context, env common.Environment, devXMRMaker,
 		}
 		ethPrivKeyHex = strings.TrimSpace(string(key))
 	} else {
-		if env != common.Development || useExternal {
-			// TODO: allow this to be set via RPC
-			log.Warnf("%s", errNoEthereumPrivateKey)
+		if c.String(flagEthereumHDWallet) != "" {
+			// the HD wallet's first account is used as the base account
 			return "", nil
 		}
 
+		if env != common.Development {
+			return "", errNoEthereumPrivateKey
+		}
+
 		log.Warn("no ethereum private key file provided, using ganache deterministic key")
 		if devXMRMaker {
 			ethPrivKeyHex = common.DefaultPrivKeyXMRMaker
@@ -55,6 +94,64 @@ func GetEthereumPrivateKey(c *cli.Context, env common.Environment, devXMRMaker,
 	return ethPrivKeyHex, nil
 }
 
+// GetEthereumHDWallet returns the HD wallet used to derive a fresh ethereum account for each swap,
+// loaded from the mnemonic or xprv in the --ethereum-hd-wallet file. It returns nil if it's unset.
+func GetEthereumHDWallet(c *cli.Context) (*hdwallet.Wallet, error) {
+	path := c.String(flagEthereumHDWallet)
+	if path == "" {
+		return nil, nil
+	}
+
+	seed, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ethereum-hd-wallet file: %w", err)
+	}
+
+	w, err := hdwallet.NewWallet(string(seed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ethereum HD wallet: %w", err)
+	}
+
+	addr, err := w.Address(0)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("loaded ethereum HD wallet, swaps will use accounts derived from it: base address=%s", addr)
+	return w, nil
+}
+
+// LoadKeystore decrypts the given keystore v3 file and returns the private key as a hex string.
+func LoadKeystore(path, password string) (string, error) {
+	keyJSON, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read ethereum keystore file: %w", err)
+	}
+
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt ethereum keystore: %w", err)
+	}
+
+	log.Infof("loaded ethereum key from keystore: address=%s", key.Address)
+	return fmt.Sprintf("%x", ethcrypto.FromECDSA(key.PrivateKey)), nil
+}
+
+func getKeystorePassword(c *cli.Context) (string, error) {
+	passwordFile := c.String(flagEthereumKeystorePasswordFile)
+	if passwordFile == "" {
+		return prompt.Stdin.PromptPassword("Ethereum keystore password: ")
+	}
+
+	password, err := os.ReadFile(filepath.Clean(passwordFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read ethereum keystore password file: %w", err)
+	}
+
+	// only strip the trailing newline, as the password may contain other whitespace
+	return strings.TrimRight(string(password), "\r\n"), nil
+}
+
 // GetEnvironment returns a common.Environment from the CLI options.
 func GetEnvironment(c *cli.Context) (env common.Environment, cfg common.Config, err error) {
 	switch c.String(flagEnv) {
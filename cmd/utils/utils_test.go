@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli"
+)
+
+func TestLoadKeystore(t *testing.T) {
+	pk, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	key := &keystore.Key{
+		Id:         uuid.New(),
+		Address:    ethcrypto.PubkeyToAddress(pk.PublicKey),
+		PrivateKey: pk,
+	}
+
+	const password = "hunter2"
+	enc, err := keystore.EncryptKey(key, password, keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	require.NoError(t, os.WriteFile(path, enc, 0600))
+
+	hexKey, err := LoadKeystore(path, password)
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("%x", ethcrypto.FromECDSA(pk)), hexKey)
+
+	_, err = LoadKeystore(path, "wrong")
+	require.Error(t, err)
+}
+
+func TestGetEthereumPrivateKey_ExternalSigner(t *testing.T) {
+	set := flag.NewFlagSet("test", 0)
+	set.String(flagEthereumPrivKey, "", "")
+	set.String(flagEthereumKeystore, "", "")
+	c := cli.NewContext(nil, set, nil)
+
+	key, err := GetEthereumPrivateKey(c, common.Stagenet, false, true)
+	require.NoError(t, err)
+	require.Empty(t, key)
+
+	// a key is required outside of development, unless using an external signer
+	_, err = GetEthereumPrivateKey(c, common.Stagenet, false, false)
+	require.Equal(t, errNoEthereumPrivateKey, err)
+
+	require.NoError(t, c.Set(flagEthereumPrivKey, "goerli.key"))
+	_, err = GetEthereumPrivateKey(c, common.Stagenet, false, true)
+	require.Equal(t, errExternalSignerAndKey, err)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"os/signal"
+	"syscall"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+
+	"github.com/noot/atomic-swap/cmd/utils"
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/rpcclient"
+	"github.com/noot/atomic-swap/watchtower"
+
+	logging "github.com/ipfs/go-log"
+)
+
+const (
+	flagEnv                          = "env"
+	flagEthereumEndpoint             = "ethereum-endpoint"
+	flagEthereumPrivateKey           = "ethereum-privkey"
+	flagEthereumKeystore             = "ethereum-keystore"
+	flagEthereumKeystorePasswordFile = "ethereum-keystore-password-file"
+	flagEthereumHDWallet             = "ethereum-hd-wallet"
+	flagEthereumChainID              = "ethereum-chain-id"
+	flagDir                          = "dir"
+	flagDaemonAddr                   = "daemon-addr"
+	flagPollInterval                 = "poll-interval"
+	flagMargin                       = "margin"
+	flagLog                          = "log"
+)
+
+var (
+	log = logging.Logger("cmd")
+
+	errNoDir = errors.New("must provide the directory swapd writes registrations to with --dir")
+)
+
+var (
+	app = &cli.App{
+		Name:   "swapwatchtower",
+		Usage:  "A program that claims or refunds swaps registered by swapd if swapd is down when they need it",
+		Action: runWatchtower,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  flagDir,
+				Usage: "directory swapd writes registrations to; see swapd's --watchtower-dir",
+			},
+			&cli.StringFlag{
+				Name:  flagDaemonAddr,
+				Usage: "address of swapd's RPC server; if set, swapd is left to act unless it's unreachable",
+			},
+			&cli.DurationFlag{
+				Name:  flagPollInterval,
+				Usage: "how often to check the registered swaps; default=30s",
+			},
+			&cli.DurationFlag{
+				Name:  flagMargin,
+				Usage: "how long before a swap's deadline to act if swapd hasn't; default=10m",
+			},
+			&cli.StringFlag{
+				Name:  flagEnv,
+				Usage: "environment to use: one of mainnet, stagenet, or dev",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumEndpoint,
+				Usage: "ethereum client endpoint",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumPrivateKey,
+				Usage: "file containing a private key hex string; prefer --ethereum-keystore",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumKeystore,
+				Usage: "ethereum keystore (v3) file containing the encrypted private key",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumKeystorePasswordFile,
+				Usage: "file containing the ethereum keystore password; if not set, the password is prompted for",
+			},
+			&cli.StringFlag{
+				Name:  flagEthereumHDWallet,
+				Usage: "file containing the BIP-39 mnemonic or BIP-32 xprv swapd derives its swap accounts from",
+			},
+			&cli.UintFlag{
+				Name:  flagEthereumChainID,
+				Usage: "ethereum chain ID; eg. mainnet=1, goerli=5, ganache=1337",
+			},
+			&cli.StringFlag{
+				Name:  flagLog,
+				Usage: "set log level: one of [error|warn|info|debug]",
+			},
+		},
+	}
+)
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+}
+
+func runWatchtower(c *cli.Context) error {
+	level := c.String(flagLog)
+	if level == "" {
+		level = "info"
+	}
+	_ = logging.SetLogLevel("cmd", level)
+	_ = logging.SetLogLevel("watchtower", level)
+
+	dir := c.String(flagDir)
+	if dir == "" {
+		return errNoDir
+	}
+
+	env, cfg, err := utils.GetEnvironment(c)
+	if err != nil {
+		return err
+	}
+
+	chainID := int64(c.Uint(flagEthereumChainID))
+	if chainID == 0 {
+		chainID = cfg.EthereumChainID
+	}
+
+	ethEndpoint := c.String(flagEthereumEndpoint)
+	if ethEndpoint == "" {
+		ethEndpoint = common.DefaultEthEndpoint
+	}
+
+	ethPrivKey, err := utils.GetEthereumPrivateKey(c, env, false, false)
+	if err != nil {
+		return err
+	}
+
+	wcfg := &watchtower.Config{
+		ChainID:      big.NewInt(chainID),
+		Dir:          dir,
+		PollInterval: c.Duration(flagPollInterval),
+		Margin:       c.Duration(flagMargin),
+	}
+
+	if ethPrivKey != "" {
+		wcfg.PrivateKey, err = ethcrypto.HexToECDSA(ethPrivKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	wcfg.HDWallet, err = utils.GetEthereumHDWallet(c)
+	if err != nil {
+		return err
+	}
+
+	wcfg.EthereumClient, err = ethclient.Dial(ethEndpoint)
+	if err != nil {
+		return err
+	}
+
+	if addr := c.String(flagDaemonAddr); addr != "" {
+		client := rpcclient.NewClient(addr)
+		wcfg.DaemonUp = func() bool {
+			_, err := client.DaemonVersion()
+			if err != nil {
+				log.Warnf("swapd is unreachable: %s", err)
+			}
+			return err == nil
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		sigc := make(chan os.Signal, 1)
+		signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+		<-sigc
+		log.Info("signal interrupt, shutting down...")
+		cancel()
+	}()
+
+	watchtower.NewWatchtower(wcfg).Run(ctx)
+	return nil
+}
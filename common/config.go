@@ -12,26 +12,32 @@ type Config struct {
 	Basepath             string
 	MoneroDaemonEndpoint string
 	EthereumChainID      int64
-	Bootnodes            []string // TODO: when it's ready for users to test, add some bootnodes
+	// EthereumConfirmations is the number of confirmations after which an ethereum
+	// transaction is considered final
+	EthereumConfirmations uint64
+	Bootnodes             []string // TODO: when it's ready for users to test, add some bootnodes
 }
 
 // MainnetConfig is the mainnet ethereum and monero configuration
 var MainnetConfig = Config{
-	Basepath:             fmt.Sprintf("%s/.atomicswap/mainnet", homeDir),
-	MoneroDaemonEndpoint: "http://127.0.0.1:18081/json_rpc",
-	EthereumChainID:      MainnetChainID,
+	Basepath:              fmt.Sprintf("%s/.atomicswap/mainnet", homeDir),
+	MoneroDaemonEndpoint:  "http://127.0.0.1:18081/json_rpc",
+	EthereumChainID:       MainnetChainID,
+	EthereumConfirmations: 12,
 }
 
 // StagenetConfig is the monero stagenet and ethereum ropsten configuration
 var StagenetConfig = Config{
-	Basepath:             fmt.Sprintf("%s/.atomicswap/stagenet", homeDir),
-	MoneroDaemonEndpoint: "http://127.0.0.1:38081/json_rpc",
-	EthereumChainID:      RopstenChainID,
+	Basepath:              fmt.Sprintf("%s/.atomicswap/stagenet", homeDir),
+	MoneroDaemonEndpoint:  "http://127.0.0.1:38081/json_rpc",
+	EthereumChainID:       RopstenChainID,
+	EthereumConfirmations: 3,
 }
 
 // DevelopmentConfig is the monero and ethereum development environment configuration
 var DevelopmentConfig = Config{
-	Basepath:             fmt.Sprintf("%s/.atomicswap/dev", homeDir),
-	MoneroDaemonEndpoint: "http://127.0.0.1:18081/json_rpc",
-	EthereumChainID:      GanacheChainID,
+	Basepath:              fmt.Sprintf("%s/.atomicswap/dev", homeDir),
+	MoneroDaemonEndpoint:  "http://127.0.0.1:18081/json_rpc",
+	EthereumChainID:       GanacheChainID,
+	EthereumConfirmations: 1,
 }
@@ -25,4 +25,5 @@ type SwapStateRPC interface {
 	ID() types.Hash
 	InfoFile() string
 	Exit() error
+	Cancel() error
 }
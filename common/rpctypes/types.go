@@ -1,6 +1,8 @@
 package rpctypes
 
 import (
+	"time"
+
 	"github.com/noot/atomic-swap/common/types"
 )
 
@@ -14,6 +16,26 @@ type SubscribeSwapStatusResponse struct {
 	Status string `json:"status"`
 }
 
+// SubscribeEventsResponse is a swap or offer event sent to swap_subscribeEvents subscribers.
+type SubscribeEventsResponse struct {
+	Type   string     `json:"type"`
+	SwapID types.Hash `json:"swapID"`
+	// Status is the swap's status; it's empty for events about offers that aren't being taken
+	Status string       `json:"status,omitempty"`
+	Time   time.Time    `json:"time"`
+	Offer  *types.Offer `json:"offer,omitempty"`
+}
+
+// SubscribeOffersResponse is an offer sent to net_subscribeOffers subscribers as soon as the daemon
+// learns about it.
+type SubscribeOffersResponse struct {
+	PeerID string       `json:"peerID"`
+	Offer  *types.Offer `json:"offer"`
+	// Source is how the daemon learned about the offer: "query" or "gossip"
+	Source string    `json:"source"`
+	Time   time.Time `json:"time"`
+}
+
 // DiscoverRequest ...
 type DiscoverRequest struct {
 	Provides   types.ProvidesCoin `json:"provides"`
@@ -25,6 +47,93 @@ type DiscoverResponse struct {
 	Peers [][]string `json:"peers"`
 }
 
+// PeerOffers are a maker's offers, and the multiaddresses to take them from.
+type PeerOffers struct {
+	PeerID     string         `json:"peerID"`
+	Multiaddrs []string       `json:"multiaddrs"`
+	Offers     []*types.Offer `json:"offers"`
+}
+
+// GetMarketOffersResponse ...
+type GetMarketOffersResponse struct {
+	Peers []*PeerOffers `json:"peers"`
+}
+
+// OrderbookEntry is a maker's offers, and when the orderbook crawler last queried them.
+type OrderbookEntry struct {
+	PeerOffers
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OrderbookResponse ...
+type OrderbookResponse struct {
+	Peers     []*OrderbookEntry `json:"peers"`
+	LastCrawl *time.Time        `json:"lastCrawl,omitempty"`
+}
+
+// PeerScore is a peer's reputation, from the outcomes of our swaps with it and the invalid
+// messages it has sent us.
+type PeerScore struct {
+	PeerID          string `json:"peerID"`
+	CompletedSwaps  uint64 `json:"completedSwaps"`
+	AbortedSwaps    uint64 `json:"abortedSwaps"`
+	RefundedSwaps   uint64 `json:"refundedSwaps"`
+	InvalidMessages uint64 `json:"invalidMessages"`
+	Score           int64  `json:"score"`
+	Deprioritized   bool   `json:"deprioritized"`
+	Banned          bool   `json:"banned"`
+	// BannedUntil is set if the peer is temporarily banned
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+}
+
+// GetPeerScoresResponse ...
+type GetPeerScoresResponse struct {
+	Peers []*PeerScore `json:"peers"`
+}
+
+// PeerStats are the bandwidth we've used with a peer, in bytes and bytes per second, and its
+// round-trip latency in milliseconds, which is 0 if it hasn't been measured.
+type PeerStats struct {
+	PeerID    string  `json:"peerID"`
+	Connected bool    `json:"connected"`
+	BytesIn   int64   `json:"bytesIn"`
+	BytesOut  int64   `json:"bytesOut"`
+	RateIn    float64 `json:"rateIn"`
+	RateOut   float64 `json:"rateOut"`
+	LatencyMs float64 `json:"latencyMs"`
+}
+
+// GetPeerStatsResponse ...
+type GetPeerStatsResponse struct {
+	Peers []*PeerStats `json:"peers"`
+}
+
+// ConnectedPeer is a peer we're connected to. Direction and ConnectedAt are of our oldest connection
+// with the peer, and AgeSeconds is how long that connection has been open.
+type ConnectedPeer struct {
+	PeerID        string    `json:"peerID"`
+	Multiaddrs    []string  `json:"multiaddrs"`
+	Protocols     []string  `json:"protocols"`
+	Direction     string    `json:"direction"`
+	ConnectedAt   time.Time `json:"connectedAt"`
+	AgeSeconds    float64   `json:"ageSeconds"`
+	Score         int64     `json:"score"`
+	Deprioritized bool      `json:"deprioritized"`
+}
+
+// GetPeersResponse ...
+type GetPeersResponse struct {
+	Peers []*ConnectedPeer `json:"peers"`
+}
+
+// GetDHTStatusResponse ...
+type GetDHTStatusResponse struct {
+	Mode                string     `json:"mode"`
+	LANRoutingTableSize int        `json:"lanRoutingTableSize"`
+	WANRoutingTableSize int        `json:"wanRoutingTableSize"`
+	LastAdvertised      *time.Time `json:"lastAdvertised,omitempty"`
+}
+
 // QueryPeerRequest ...
 type QueryPeerRequest struct {
 	// Multiaddr of peer to query
@@ -41,6 +150,28 @@ type TakeOfferRequest struct {
 	Multiaddr      string  `json:"multiaddr"`
 	OfferID        string  `json:"offerID"`
 	ProvidesAmount float64 `json:"providesAmount"`
+	// SpeedTier is the name of the offer's settlement speed tier to use; if empty, the
+	// offer's first tier is used
+	SpeedTier string `json:"speedTier,omitempty"`
+	// MaxExchangeRate and MinReceivedXMR are optional limits on the swap's terms, checked before
+	// our ether is locked; see types.SlippageLimits
+	MaxExchangeRate types.ExchangeRate `json:"maxExchangeRate,omitempty"`
+	MinReceivedXMR  float64            `json:"minReceivedXMR,omitempty"`
+	// QuoteID is the ID of a quote from net_getQuote for the offer and amount; if set, the swap
+	// uses the quote's exchange rate instead of the offer's
+	QuoteID string `json:"quoteID,omitempty"`
+}
+
+// SlippageLimits returns the request's limits on the swap's terms, or nil if it has none.
+func (r *TakeOfferRequest) SlippageLimits() *types.SlippageLimits {
+	if r.MaxExchangeRate == 0 && r.MinReceivedXMR == 0 {
+		return nil
+	}
+
+	return &types.SlippageLimits{
+		MaxExchangeRate: r.MaxExchangeRate,
+		MinReceivedXMR:  r.MinReceivedXMR,
+	}
 }
 
 // TakeOfferResponse ...
@@ -48,17 +179,140 @@ type TakeOfferResponse struct {
 	InfoFile string `json:"infoFile"`
 }
 
+// TakePrivateOfferRequest ...
+type TakePrivateOfferRequest struct {
+	Multiaddr string `json:"multiaddr"`
+	// Token is the token the maker gave out for the offer
+	Token          string  `json:"token"`
+	ProvidesAmount float64 `json:"providesAmount"`
+	// SpeedTier, MaxExchangeRate and MinReceivedXMR are as in TakeOfferRequest
+	SpeedTier       string             `json:"speedTier,omitempty"`
+	MaxExchangeRate types.ExchangeRate `json:"maxExchangeRate,omitempty"`
+	MinReceivedXMR  float64            `json:"minReceivedXMR,omitempty"`
+}
+
+// TakePrivateOfferResponse ...
+type TakePrivateOfferResponse struct {
+	OfferID  string `json:"offerID"`
+	InfoFile string `json:"infoFile"`
+}
+
+// GetQuoteRequest ...
+type GetQuoteRequest struct {
+	Multiaddr      string  `json:"multiaddr"`
+	OfferID        string  `json:"offerID"`
+	ProvidesAmount float64 `json:"providesAmount"`
+}
+
+// GetQuoteResponse ...
+type GetQuoteResponse struct {
+	ID             string             `json:"id"`
+	ExchangeRate   types.ExchangeRate `json:"exchangeRate"`
+	ReceivedAmount float64            `json:"receivedAmount"`
+	ExpiresAt      time.Time          `json:"expiresAt"`
+}
+
+// TakeBestOfferRequest ...
+type TakeBestOfferRequest struct {
+	ProvidesAmount float64 `json:"providesAmount"`
+	SearchTime     uint64  `json:"searchTime"` // in seconds
+	// SpeedTier, MaxExchangeRate and MinReceivedXMR are the same as in TakeOfferRequest; offers
+	// that don't satisfy them aren't considered
+	SpeedTier       string             `json:"speedTier,omitempty"`
+	MaxExchangeRate types.ExchangeRate `json:"maxExchangeRate,omitempty"`
+	MinReceivedXMR  float64            `json:"minReceivedXMR,omitempty"`
+}
+
+// TakeBestOfferResponse ...
+type TakeBestOfferResponse struct {
+	Multiaddr    string             `json:"multiaddr"`
+	OfferID      string             `json:"offerID"`
+	ExchangeRate types.ExchangeRate `json:"exchangeRate"`
+	InfoFile     string             `json:"infoFile"`
+}
+
+// TakeRouteRequest ...
+type TakeRouteRequest struct {
+	ProvidesAmount float64 `json:"providesAmount"`
+	SearchTime     uint64  `json:"searchTime"` // in seconds
+	// MaxExchangeRate is optional; offers with a higher rate aren't used
+	MaxExchangeRate types.ExchangeRate `json:"maxExchangeRate,omitempty"`
+}
+
+// GetRouteRequest ...
+type GetRouteRequest struct {
+	ID types.Hash `json:"id"`
+}
+
+// RouteLeg is one maker's part of a route.
+type RouteLeg struct {
+	Multiaddr      string             `json:"multiaddr"`
+	OfferID        string             `json:"offerID"`
+	ProvidesAmount float64            `json:"providesAmount"`
+	ExchangeRate   types.ExchangeRate `json:"exchangeRate"`
+	Status         string             `json:"status"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// RouteResponse ...
+type RouteResponse struct {
+	ID             types.Hash  `json:"id"`
+	ProvidesAmount float64     `json:"providesAmount"`
+	ReceivedAmount float64     `json:"receivedAmount"`
+	Status         string      `json:"status"`
+	Legs           []*RouteLeg `json:"legs"`
+}
+
 // MakeOfferRequest ...
 type MakeOfferRequest struct {
+	MinimumAmount float64             `json:"minimumAmount"`
+	MaximumAmount float64             `json:"maximumAmount"`
+	ExchangeRate  types.ExchangeRate  `json:"exchangeRate"`
+	SpeedTiers    []*types.SpeedTier  `json:"speedTiers,omitempty"`
+	Relist        *types.RelistPolicy `json:"relist,omitempty"`
+	Peg           *types.RatePeg      `json:"peg,omitempty"`
+	Takers        *types.TakerFilter  `json:"takers,omitempty"`
+	*OfferTerms
+}
+
+// OfferTerms are the optional terms of an offer; see types.Offer.
+type OfferTerms struct {
+	MinimumTakerAmount    float64 `json:"minimumTakerAmount,omitempty"`
+	Spread                float64 `json:"spread,omitempty"`
+	RequiredConfirmations uint64  `json:"requiredConfirmations,omitempty"`
+	// TTL is how long, in seconds, the offer is listed for before it expires
+	TTL uint64 `json:"ttl,omitempty"`
+}
+
+// MakeOfferResponse ...
+type MakeOfferResponse struct {
+	ID       string `json:"offerID"`
+	InfoFile string `json:"infoFile"`
+}
+
+// MakePrivateOfferRequest ...
+type MakePrivateOfferRequest struct {
 	MinimumAmount float64            `json:"minimumAmount"`
 	MaximumAmount float64            `json:"maximumAmount"`
 	ExchangeRate  types.ExchangeRate `json:"exchangeRate"`
+	SpeedTiers    []*types.SpeedTier `json:"speedTiers,omitempty"`
+	Peg           *types.RatePeg     `json:"peg,omitempty"`
+	*OfferTerms
 }
 
-// MakeOfferResponse ...
-type MakeOfferResponse struct {
+// MakePrivateOfferResponse ...
+type MakePrivateOfferResponse struct {
 	ID       string `json:"offerID"`
 	InfoFile string `json:"infoFile"`
+	// Token is what the taker needs, along with one of our addresses, to take the offer
+	Token     string   `json:"token"`
+	Addresses []string `json:"addresses"`
+}
+
+// ClearOffersRequest ...
+type ClearOffersRequest struct {
+	// OfferIDs are the IDs of the offers to clear; if empty, all offers are cleared
+	OfferIDs []string `json:"offerIDs"`
 }
 
 // SignerRequest initiates the signer_subscribe handler from the front-end
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var errInvalidHashLength = errors.New("invalid hash length")
+
+// Hash represents a 32-byte hash. Swaps are identified by the hash of their offer.
+type Hash [32]byte
+
+// String returns the hex-encoded hash
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// MarshalText encodes the hash as a hex string.
+func (h Hash) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalJSON decodes a hex-encoded hash. For compatibility with older peers and
+// clients, it also accepts the legacy encoding as an array of 32 bytes.
+func (h *Hash) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '[' {
+		var legacy [32]byte
+		if err := json.Unmarshal(b, &legacy); err != nil {
+			return err
+		}
+
+		*h = legacy
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	hash, err := HexToHash(s)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimPrefix(s, "0x")) != 2*len(hash) {
+		return errInvalidHashLength
+	}
+
+	*h = hash
+	return nil
+}
+
+// HexToHash decodes a hex-encoded string, optionally prefixed with 0x, into a hash
+func HexToHash(s string) (Hash, error) {
+	h, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	var hash [32]byte
+	copy(hash[:], h)
+	return hash, nil
+}
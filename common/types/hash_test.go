@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash_JSON(t *testing.T) {
+	h := Hash{1, 2, 3}
+
+	bz, err := json.Marshal(h)
+	require.NoError(t, err)
+	require.Equal(t, `"`+h.String()+`"`, string(bz))
+
+	var res Hash
+	err = json.Unmarshal(bz, &res)
+	require.NoError(t, err)
+	require.Equal(t, h, res)
+
+	err = json.Unmarshal([]byte(`"0x`+h.String()+`"`), &res)
+	require.NoError(t, err)
+	require.Equal(t, h, res)
+
+	err = json.Unmarshal([]byte(`"0102"`), &res)
+	require.Equal(t, errInvalidHashLength, err)
+}
+
+func TestHash_UnmarshalJSON_Legacy(t *testing.T) {
+	h := Hash{1, 2, 3}
+	legacy, err := json.Marshal([32]byte(h))
+	require.NoError(t, err)
+
+	var res Hash
+	err = json.Unmarshal(legacy, &res)
+	require.NoError(t, err)
+	require.Equal(t, h, res)
+}
+
+func TestOffer_JSON(t *testing.T) {
+	o := &Offer{
+		Provides:      ProvidesXMR,
+		MinimumAmount: 1,
+		MaximumAmount: 2,
+		ExchangeRate:  0.1,
+	}
+	o.GetID()
+
+	bz, err := json.Marshal(o)
+	require.NoError(t, err)
+
+	var res *Offer
+	err = json.Unmarshal(bz, &res)
+	require.NoError(t, err)
+	require.Equal(t, o, res)
+}
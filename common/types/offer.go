@@ -2,32 +2,26 @@ package types
 
 import (
 	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"golang.org/x/crypto/sha3"
 )
 
-// Hash represents a 32-byte hash
-type Hash [32]byte
+// CurrentOfferVersion is the version of the offer schema that offers are made with. Offers made
+// by older nodes have version 0, and don't have any of the fields added since; their zero values
+// mean the offer has no such terms.
+const CurrentOfferVersion = 1
 
-// String returns the hex-encoded hash
-func (h Hash) String() string {
-	return hex.EncodeToString(h[:])
-}
-
-// HexToHash decodes a hex-encoded string into a hash
-func HexToHash(s string) (Hash, error) {
-	h, err := hex.DecodeString(s)
-	if err != nil {
-		return [32]byte{}, err
-	}
-
-	var hash [32]byte
-	copy(hash[:], h)
-	return hash, nil
-}
+var (
+	errUnsupportedEthAsset       = errors.New("offer's ETH asset is not supported")
+	errInvalidMinimumTakerAmount = errors.New("offer's minimum taker amount must not be negative")
+	errAmountBelowTakerMinimum   = errors.New("amount provided is below the offer's minimum taker amount")
+	errOfferExpired              = errors.New("offer has expired")
+)
 
 // Offer represents a swap offer
 type Offer struct {
@@ -36,6 +30,56 @@ type Offer struct {
 	MinimumAmount float64
 	MaximumAmount float64
 	ExchangeRate  ExchangeRate
+	SpeedTiers    []*SpeedTier
+
+	// Version is the offer schema's version, see CurrentOfferVersion.
+	Version uint64 `json:",omitempty"`
+
+	// ExpiresAt is when the offer expires, if it does.
+	ExpiresAt *time.Time `json:",omitempty"`
+
+	// MinimumTakerAmount is the least ETH a taker may provide, if it's set.
+	MinimumTakerAmount float64 `json:",omitempty"`
+
+	// Spread is the fraction that the exchange rate is above (or, if negative, below) the maker's
+	// index rate. It's informational, as it's already included in the exchange rate.
+	Spread float64 `json:",omitempty"`
+
+	// EthAsset is the asset the taker provides: the zero address for ether, which is the only
+	// asset supported so far.
+	EthAsset ethcommon.Address
+
+	// RequiredConfirmations is the number of confirmations the maker waits for after the taker
+	// locks their ETH, before locking their XMR, if it's more than the maker's default.
+	RequiredConfirmations uint64 `json:",omitempty"`
+
+	// Maker is the libp2p peer ID of the maker, whose identity key signed the offer.
+	Maker string `json:",omitempty"`
+
+	// SignedPayload is the JSON encoding of the offer, without its signature, exactly as the maker
+	// signed it. Its terms are the ones that count; they're checked against the signature as they
+	// were sent, so that fields we don't know of yet don't break the signature.
+	SignedPayload []byte `json:",omitempty"`
+
+	// Signature is the maker's signature of the offer's SigningBytes.
+	Signature []byte `json:",omitempty"`
+}
+
+// offerSigningPrefix separates offer signatures from anything else signed with the same key.
+const offerSigningPrefix = "atomic-swap offer:"
+
+// EncodeForSigning returns the encoding of the offer for its SignedPayload: the offer without
+// its signature.
+func (o *Offer) EncodeForSigning() ([]byte, error) {
+	unsigned := *o
+	unsigned.SignedPayload = nil
+	unsigned.Signature = nil
+	return json.Marshal(&unsigned)
+}
+
+// SigningBytes returns the bytes the maker signs: the offer's SignedPayload.
+func (o *Offer) SigningBytes() []byte {
+	return append([]byte(offerSigningPrefix), o.SignedPayload...)
 }
 
 // GetID returns the ID of the offer
@@ -61,15 +105,117 @@ func (o *Offer) GetID() Hash {
 
 // String ...
 func (o *Offer) String() string {
-	return fmt.Sprintf("Offer ID=%s Provides=%v MinimumAmount=%v MaximumAmount=%v ExchangeRate=%v",
+	return fmt.Sprintf("Offer ID=%s Provides=%v MinimumAmount=%v MaximumAmount=%v ExchangeRate=%v SpeedTiers=%v Version=%d ExpiresAt=%v MinimumTakerAmount=%v Spread=%v EthAsset=%s RequiredConfirmations=%d", //nolint:lll
 		o.ID,
 		o.Provides,
 		o.MinimumAmount,
 		o.MaximumAmount,
 		o.ExchangeRate,
+		o.SpeedTiers,
+		o.Version,
+		o.ExpiresAt,
+		o.MinimumTakerAmount,
+		o.Spread,
+		o.EthAsset,
+		o.RequiredConfirmations,
 	)
 }
 
+// ValidateTerms checks that the offer's terms are supported.
+func (o *Offer) ValidateTerms() error {
+	if o.EthAsset != (ethcommon.Address{}) {
+		return errUnsupportedEthAsset
+	}
+
+	if o.MinimumTakerAmount < 0 {
+		return errInvalidMinimumTakerAmount
+	}
+
+	return nil
+}
+
+// Expired returns whether the offer has expired at the given time.
+func (o *Offer) Expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
+
+// CheckTake checks that the offer may be taken by providing the given amount of ETH.
+func (o *Offer) CheckTake(providesAmount float64) error {
+	if err := o.ValidateTerms(); err != nil {
+		return err
+	}
+
+	if o.Expired(time.Now()) {
+		return errOfferExpired
+	}
+
+	if providesAmount < o.MinimumTakerAmount {
+		return errAmountBelowTakerMinimum
+	}
+
+	return nil
+}
+
+// RelistPolicy configures whether an offer is listed again after a swap taking it is aborted or refunded.
+// It's known only to the maker and isn't sent to peers.
+type RelistPolicy struct {
+	// OneShot offers aren't listed again; by default, they are
+	OneShot bool `json:"oneShot,omitempty"`
+
+	// Cooldown is how long, in seconds, to wait before listing the offer again.
+	Cooldown uint64 `json:"cooldown,omitempty"`
+}
+
+// CooldownDuration returns the policy's cooldown as a time.Duration.
+func (p *RelistPolicy) CooldownDuration() time.Duration {
+	return time.Duration(p.Cooldown) * time.Second
+}
+
+// RatePeg pegs an offer's exchange rate to an index rate given by a price oracle, which the
+// advertised rate is periodically refreshed from. It's known only to the maker.
+type RatePeg struct {
+	// Spread is the fraction above (or, if negative, below) the index that the offer's exchange
+	// rate is set to; eg. 0.01 is 1% above the index.
+	Spread float64 `json:"spread"`
+}
+
+// TakerFilter restricts which peers may take an offer, eg. so that an offer agreed on privately
+// can't be taken by anyone else. Peers are identified by their base58-encoded libp2p peer IDs.
+// It's known only to the maker.
+type TakerFilter struct {
+	// Allow, if set, is the only peers that may take the offer
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny is peers that may not take the offer
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Permits returns whether the peer with the given ID may take offers the filter applies to.
+// A nil filter permits everyone.
+func (f *TakerFilter) Permits(peerID string) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, id := range f.Deny {
+		if id == peerID {
+			return false
+		}
+	}
+
+	if len(f.Allow) == 0 {
+		return true
+	}
+
+	for _, id := range f.Allow {
+		if id == peerID {
+			return true
+		}
+	}
+
+	return false
+}
+
 // OfferExtra represents extra data that is passed when an offer is made.
 type OfferExtra struct {
 	StatusCh chan Status
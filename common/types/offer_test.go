@@ -0,0 +1,50 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakerFilter_Permits(t *testing.T) {
+	var filter *TakerFilter
+	require.True(t, filter.Permits("a"))
+
+	filter = &TakerFilter{Deny: []string{"a"}}
+	require.False(t, filter.Permits("a"))
+	require.True(t, filter.Permits("b"))
+
+	filter = &TakerFilter{Allow: []string{"a", "b"}, Deny: []string{"b"}}
+	require.True(t, filter.Permits("a"))
+	require.False(t, filter.Permits("b"))
+	require.False(t, filter.Permits("c"))
+}
+
+func TestOffer_CheckTake(t *testing.T) {
+	offer := &Offer{MinimumTakerAmount: 0.1}
+	require.NoError(t, offer.CheckTake(0.1))
+	require.ErrorIs(t, offer.CheckTake(0.09), errAmountBelowTakerMinimum)
+
+	offer.EthAsset = ethcommon.Address{1}
+	require.ErrorIs(t, offer.CheckTake(0.1), errUnsupportedEthAsset)
+
+	offer = &Offer{MinimumTakerAmount: -1}
+	require.ErrorIs(t, offer.ValidateTerms(), errInvalidMinimumTakerAmount)
+
+	expiry := time.Now().Add(-time.Second)
+	offer = &Offer{ExpiresAt: &expiry}
+	require.ErrorIs(t, offer.CheckTake(1), errOfferExpired)
+}
+
+func TestOffer_Expired(t *testing.T) {
+	now := time.Now()
+	offer := &Offer{}
+	require.False(t, offer.Expired(now))
+
+	expiry := now.Add(time.Minute)
+	offer.ExpiresAt = &expiry
+	require.False(t, offer.Expired(now))
+	require.True(t, offer.Expired(expiry))
+}
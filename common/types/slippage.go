@@ -0,0 +1,57 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// rateTolerance allows for floating point error when comparing exchange rates
+const rateTolerance = 1e-9
+
+var (
+	errSlippageExceeded     = errors.New("swap terms exceed slippage limits")
+	errInvalidSlippageLimit = errors.New("slippage limits must not be negative")
+)
+
+// SlippageLimits bound the terms a taker accepts when taking an offer, so they can't end up
+// locked into a worse rate than expected if the maker's offer was updated or misrepresented.
+// Zero values mean no limit.
+type SlippageLimits struct {
+	// MaxExchangeRate is the highest exchange rate, in ETH per XMR, the taker accepts
+	MaxExchangeRate ExchangeRate `json:"maxExchangeRate,omitempty"`
+	// MinReceivedXMR is the least XMR the taker accepts for the ETH they provide
+	MinReceivedXMR float64 `json:"minReceivedXMR,omitempty"`
+}
+
+// Check returns an error if providing the given amount of ETH for the given amount of XMR is
+// outside the limits. A nil *SlippageLimits has no limits.
+func (l *SlippageLimits) Check(providedETH, receivedXMR float64) error {
+	if l == nil {
+		return nil
+	}
+
+	if l.MaxExchangeRate < 0 || l.MinReceivedXMR < 0 {
+		return errInvalidSlippageLimit
+	}
+
+	if l.MinReceivedXMR > 0 && receivedXMR < l.MinReceivedXMR {
+		return fmt.Errorf("%w: receiving %v XMR, minimum is %v XMR", errSlippageExceeded,
+			receivedXMR, l.MinReceivedXMR)
+	}
+
+	if l.MaxExchangeRate == 0 {
+		return nil
+	}
+
+	if receivedXMR <= 0 {
+		return fmt.Errorf("%w: receiving no XMR", errSlippageExceeded)
+	}
+
+	rate := providedETH / receivedXMR
+	if rate > float64(l.MaxExchangeRate)*(1+rateTolerance) {
+		return fmt.Errorf("%w: exchange rate is %v ETH/XMR, maximum is %v ETH/XMR", errSlippageExceeded,
+			rate, l.MaxExchangeRate)
+	}
+
+	return nil
+}
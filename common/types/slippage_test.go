@@ -0,0 +1,38 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlippageLimits_Check(t *testing.T) {
+	var limits *SlippageLimits
+	require.NoError(t, limits.Check(1, 0))
+
+	limits = &SlippageLimits{
+		MaxExchangeRate: 0.1,
+		MinReceivedXMR:  5,
+	}
+
+	// the offer's own rate passes, despite floating point error
+	rate := ExchangeRate(0.1)
+	require.NoError(t, limits.Check(0.7, rate.ToXMR(0.7)))
+	require.NoError(t, limits.Check(0.5, 6))
+
+	err := limits.Check(0.5, 4.9)
+	require.True(t, errors.Is(err, errSlippageExceeded))
+
+	err = limits.Check(0.6, 5)
+	require.True(t, errors.Is(err, errSlippageExceeded))
+
+	err = limits.Check(0.6, 0)
+	require.True(t, errors.Is(err, errSlippageExceeded))
+
+	// either limit can be left unset
+	require.NoError(t, (&SlippageLimits{MinReceivedXMR: 5}).Check(100, 5))
+	require.NoError(t, (&SlippageLimits{MaxExchangeRate: 0.1}).Check(0.1, 1))
+
+	require.Equal(t, errInvalidSlippageLimit, (&SlippageLimits{MinReceivedXMR: -1}).Check(1, 1))
+}
@@ -0,0 +1,85 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errUnknownSpeedTier      = errors.New("offer does not have a speed tier with the given name")
+	errEmptySpeedTierName    = errors.New("speed tier name must not be empty")
+	errDuplicateSpeedTier    = errors.New("duplicate speed tier name")
+	errInvalidSpeedTierValue = errors.New("speed tier confirmations and timeout must be greater than zero")
+)
+
+// SpeedTier is a settlement speed tier advertised by a maker in an offer. Faster tiers
+// require fewer monero confirmations before the taker sets the contract to ready, and use
+// a shorter contract timeout.
+type SpeedTier struct {
+	Name string
+
+	// MoneroConfirmations is the number of blocks the taker waits for after the maker
+	// locks their XMR, before checking the locked balance.
+	MoneroConfirmations uint64
+
+	// Timeout is the duration in seconds between the swap being initiated on-chain and t0,
+	// and between t0 and t1.
+	Timeout uint64
+}
+
+// TimeoutDuration returns the tier's contract timeout as a time.Duration.
+func (t *SpeedTier) TimeoutDuration() time.Duration {
+	return time.Duration(t.Timeout) * time.Second
+}
+
+// String ...
+func (t *SpeedTier) String() string {
+	return fmt.Sprintf("SpeedTier Name=%s MoneroConfirmations=%d Timeout=%s",
+		t.Name,
+		t.MoneroConfirmations,
+		t.TimeoutDuration(),
+	)
+}
+
+// GetSpeedTier returns the offer's speed tier with the given name. If the name is empty, the
+// offer's first tier is returned. If the offer doesn't have any tiers and no name is given,
+// it returns nil, meaning the daemons' default confirmations and timeout are used.
+func (o *Offer) GetSpeedTier(name string) (*SpeedTier, error) {
+	if name == "" {
+		if len(o.SpeedTiers) == 0 {
+			return nil, nil
+		}
+
+		return o.SpeedTiers[0], nil
+	}
+
+	for _, tier := range o.SpeedTiers {
+		if tier.Name == name {
+			return tier, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", errUnknownSpeedTier, name)
+}
+
+// ValidateSpeedTiers checks that the offer's speed tiers have unique names and non-zero values.
+func (o *Offer) ValidateSpeedTiers() error {
+	names := make(map[string]struct{})
+	for _, tier := range o.SpeedTiers {
+		if tier.Name == "" {
+			return errEmptySpeedTierName
+		}
+
+		if _, has := names[tier.Name]; has {
+			return fmt.Errorf("%w: %s", errDuplicateSpeedTier, tier.Name)
+		}
+		names[tier.Name] = struct{}{}
+
+		if tier.MoneroConfirmations == 0 || tier.Timeout == 0 {
+			return errInvalidSpeedTierValue
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffer_GetSpeedTier(t *testing.T) {
+	fast := &SpeedTier{Name: "fast", MoneroConfirmations: 5, Timeout: 600}
+	cheap := &SpeedTier{Name: "cheap", MoneroConfirmations: 10, Timeout: 3600}
+
+	offer := &Offer{}
+	tier, err := offer.GetSpeedTier("")
+	require.NoError(t, err)
+	require.Nil(t, tier)
+
+	_, err = offer.GetSpeedTier("fast")
+	require.True(t, errors.Is(err, errUnknownSpeedTier))
+
+	offer.SpeedTiers = []*SpeedTier{fast, cheap}
+	tier, err = offer.GetSpeedTier("")
+	require.NoError(t, err)
+	require.Equal(t, fast, tier)
+
+	tier, err = offer.GetSpeedTier("cheap")
+	require.NoError(t, err)
+	require.Equal(t, cheap, tier)
+	require.Equal(t, time.Hour, tier.TimeoutDuration())
+
+	_, err = offer.GetSpeedTier("instant")
+	require.True(t, errors.Is(err, errUnknownSpeedTier))
+}
+
+func TestOffer_ValidateSpeedTiers(t *testing.T) {
+	offer := &Offer{
+		SpeedTiers: []*SpeedTier{
+			{Name: "fast", MoneroConfirmations: 5, Timeout: 600},
+			{Name: "cheap", MoneroConfirmations: 10, Timeout: 3600},
+		},
+	}
+	require.NoError(t, offer.ValidateSpeedTiers())
+
+	offer.SpeedTiers[1].Name = "fast"
+	require.True(t, errors.Is(offer.ValidateSpeedTiers(), errDuplicateSpeedTier))
+
+	offer.SpeedTiers[1].Name = ""
+	require.Equal(t, errEmptySpeedTierName, offer.ValidateSpeedTiers())
+
+	offer.SpeedTiers[1].Name = "cheap"
+	offer.SpeedTiers[1].Timeout = 0
+	require.Equal(t, errInvalidSpeedTierValue, offer.ValidateSpeedTiers())
+}
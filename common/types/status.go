@@ -16,6 +16,8 @@ const (
 	// CompletedAbort represents the case where the swap aborts before any funds are locked.
 	CompletedAbort
 	UnknownStatus
+	// ClaimFailing represents a swap whose ether we keep failing to claim as t1 approaches.
+	ClaimFailing
 )
 
 const unknownString string = "unknown"
@@ -40,6 +42,8 @@ func NewStatus(str string) Status {
 		return CompletedRefund
 	case "Aborted":
 		return CompletedAbort
+	case "ClaimFailing":
+		return ClaimFailing
 	default:
 		return UnknownStatus
 	}
@@ -64,6 +68,8 @@ func (s Status) String() string {
 		return "Refunded"
 	case CompletedAbort:
 		return "Aborted"
+	case ClaimFailing:
+		return "ClaimFailing"
 	default:
 		return unknownString
 	}
@@ -88,6 +94,8 @@ func (s Status) Info() string {
 		return "the locked funds have been refunded and the swap has completed"
 	case CompletedAbort:
 		return "the swap was aborted before any funds were locked"
+	case ClaimFailing:
+		return "claiming the locked ether keeps failing; if it isn't claimed before t1, it can be refunded"
 	default:
 		return unknownString
 	}
@@ -96,7 +104,7 @@ func (s Status) Info() string {
 // IsOngoing returns true if the status means the swap has not completed
 func (s Status) IsOngoing() bool {
 	switch s {
-	case ExpectingKeys, KeysExchanged, ETHLocked, XMRLocked, ContractReady, UnknownStatus:
+	case ExpectingKeys, KeysExchanged, ETHLocked, XMRLocked, ContractReady, UnknownStatus, ClaimFailing:
 		return true
 	default:
 		return false
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"fmt"
+)
+
+// VersionInfo describes a daemon's build and what it supports. It's reported by daemon_version
+// and exchanged with the counterparty when a swap begins, to help debug swaps between daemons
+// running different versions.
+type VersionInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+
+	// ProtocolFeatures are the optional parts of the swap protocol the daemon supports
+	ProtocolFeatures []string `json:"protocolFeatures"`
+
+	// ContractVersions are the code hashes of the swap contracts the daemon can use
+	ContractVersions []string `json:"contractVersions"`
+
+	Chains []*Chain `json:"chains"`
+}
+
+// Chain is a chain the daemon is configured to use.
+type Chain struct {
+	Name    string `json:"name"`
+	Network string `json:"network"`
+	ChainID int64  `json:"chainID,omitempty"`
+}
+
+// String returns the version and commit, eg. "0.1.0 (commit 4144899)".
+func (v *VersionInfo) String() string {
+	if v == nil {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s (commit %s)", v.Version, v.Commit)
+}
+
+// HasFeature returns true if the daemon supports the given protocol feature.
+func (v *VersionInfo) HasFeature(feature string) bool {
+	if v == nil {
+		return false
+	}
+
+	for _, f := range v.ProtocolFeatures {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
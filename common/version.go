@@ -0,0 +1,32 @@
+package common
+
+// Version is the daemon's semantic version.
+const Version = "0.1.0"
+
+// GitCommit is the commit the daemon was built from. It's set at build time with
+// -ldflags "-X github.com/noot/atomic-swap/common.GitCommit=<commit>".
+var GitCommit = "unknown"
+
+// optional parts of the swap protocol, advertised to the counterparty when a swap begins
+const (
+	FeatureSpeedTiers       = "speed-tiers"
+	FeatureVersionHandshake = "version-handshake"
+	FeatureXMRLockProof     = "xmr-lock-proof"
+	// FeatureCapabilityHandshake is the exchange of Hello messages when a swap stream opens
+	FeatureCapabilityHandshake = "capability-handshake"
+	// FeatureSessionKeys is the signing of swap messages with keys exchanged in SendKeysMessage
+	FeatureSessionKeys = "session-keys"
+	// FeatureInitiateWork is the proof of work a maker may require on a SendKeysMessage initiating
+	// a swap
+	FeatureInitiateWork = "initiate-work"
+)
+
+// ProtocolFeatures are the optional parts of the swap protocol this daemon supports.
+var ProtocolFeatures = []string{
+	FeatureSpeedTiers,
+	FeatureVersionHandshake,
+	FeatureXMRLockProof,
+	FeatureCapabilityHandshake,
+	FeatureSessionKeys,
+	FeatureInitiateWork,
+}
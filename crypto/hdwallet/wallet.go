@@ -0,0 +1,124 @@
+package hdwallet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+var (
+	errInvalidMnemonic = errors.New("invalid BIP-39 mnemonic")
+	errNotPrivateKey   = errors.New("extended key is not a private key")
+	errHardenedIndex   = errors.New("account index must be less than 2^31")
+	errEmptySeedPhrase = errors.New("empty mnemonic or extended key")
+
+	extendedKeyPrefixes = []string{"xprv", "tprv"}
+)
+
+// Wallet derives ethereum accounts from a BIP-32 master key along the standard ethereum
+// derivation path, m/44'/60'/0'/0/i.
+type Wallet struct {
+	// the key at m/44'/60'/0'/0, which account keys are derived from
+	accounts *hdkeychain.ExtendedKey
+}
+
+// NewWallet returns a wallet from either a BIP-39 mnemonic or a BIP-32 extended private key (xprv).
+func NewWallet(seed string) (*Wallet, error) {
+	seed = strings.TrimSpace(seed)
+	if seed == "" {
+		return nil, errEmptySeedPhrase
+	}
+
+	for _, prefix := range extendedKeyPrefixes {
+		if strings.HasPrefix(seed, prefix) {
+			return NewWalletFromExtendedKey(seed)
+		}
+	}
+
+	return NewWalletFromMnemonic(seed, "")
+}
+
+// NewWalletFromMnemonic returns a wallet from a BIP-39 mnemonic and optional passphrase.
+func NewWalletFromMnemonic(mnemonic, passphrase string) (*Wallet, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errInvalidMnemonic
+	}
+
+	master, err := hdkeychain.NewMaster(bip39.NewSeed(mnemonic, passphrase), &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWallet(master)
+}
+
+// NewWalletFromExtendedKey returns a wallet from a base58-encoded BIP-32 master private key.
+func NewWalletFromExtendedKey(xprv string) (*Wallet, error) {
+	master, err := hdkeychain.NewKeyFromString(xprv)
+	if err != nil {
+		return nil, err
+	}
+
+	if !master.IsPrivate() {
+		return nil, errNotPrivateKey
+	}
+
+	return newWallet(master)
+}
+
+func newWallet(master *hdkeychain.ExtendedKey) (*Wallet, error) {
+	key := master
+	for _, i := range accounts.DefaultRootDerivationPath {
+		var err error
+		key, err = key.Derive(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", accounts.DefaultRootDerivationPath, err)
+		}
+	}
+
+	return &Wallet{accounts: key}, nil
+}
+
+// DerivationPath returns the derivation path of the account with the given index.
+func DerivationPath(index uint32) accounts.DerivationPath {
+	path := append(accounts.DerivationPath{}, accounts.DefaultRootDerivationPath...)
+	return append(path, index)
+}
+
+// PrivateKey returns the private key of the account with the given index.
+func (w *Wallet) PrivateKey(index uint32) (*ecdsa.PrivateKey, error) {
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, errHardenedIndex
+	}
+
+	key, err := w.accounts.Derive(index)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := key.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return ethcrypto.ToECDSA(priv.Serialize())
+}
+
+// Address returns the address of the account with the given index.
+func (w *Wallet) Address(index uint32) (ethcommon.Address, error) {
+	pk, err := w.PrivateKey(index)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	return ethcrypto.PubkeyToAddress(pk.PublicKey), nil
+}
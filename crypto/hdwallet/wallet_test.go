@@ -0,0 +1,50 @@
+package hdwallet
+
+import (
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestWallet_Mnemonic(t *testing.T) {
+	w, err := NewWallet(testMnemonic)
+	require.NoError(t, err)
+
+	// well-known addresses for the test mnemonic at m/44'/60'/0'/0/i
+	addr, err := w.Address(0)
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"), addr)
+
+	addr, err = w.Address(1)
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.HexToAddress("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0"), addr)
+
+	require.Equal(t, "m/44'/60'/0'/0/1", DerivationPath(1).String())
+}
+
+func TestWallet_ExtendedKey(t *testing.T) {
+	// the BIP-32 root key of the test mnemonic
+	const xprv = "xprv9s21ZrQH143K3GJpoapnV8SFfukcVBSfeCficPSGfubmSFDxo1kuHnLisriDvSnRRuL2Qrg5ggqHKNVpxR86QEC8w35uxmGoggxtQTPvfUu" //nolint:lll
+	w, err := NewWallet(xprv)
+	require.NoError(t, err)
+
+	addr, err := w.Address(0)
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94"), addr)
+}
+
+func TestWallet_Invalid(t *testing.T) {
+	_, err := NewWallet("abandon abandon abandon")
+	require.ErrorIs(t, err, errInvalidMnemonic)
+
+	_, err = NewWallet("  ")
+	require.ErrorIs(t, err, errEmptySeedPhrase)
+
+	w, err := NewWallet(testMnemonic)
+	require.NoError(t, err)
+	_, err = w.PrivateKey(1 << 31)
+	require.ErrorIs(t, err, errHardenedIndex)
+}
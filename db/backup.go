@@ -0,0 +1,147 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// number of entries written at a time when copying a database
+const copyBatchSize = 1000
+
+var errInMemory = errors.New("database is only held in memory, so it can't be restored")
+
+// restoreDir returns the directory that a restore of the database in dir is staged in.
+func restoreDir(dir string) string {
+	return dir + ".restore"
+}
+
+func (d *database) Backup(dir string) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	snapshot, err := d.ldb.GetSnapshot()
+	if err != nil {
+		return err
+	}
+	defer snapshot.Release()
+
+	iter := snapshot.NewIterator(nil, nil)
+	defer iter.Release()
+	return copyTo(iter, dir)
+}
+
+func (d *database) StageRestore(backupDir string) (uint64, error) {
+	if d.dir == "" {
+		return 0, errInMemory
+	}
+
+	src, err := leveldb.OpenFile(filepath.Clean(backupDir), &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return 0, fmt.Errorf("failed to open backup %s: %w", backupDir, err)
+	}
+	defer src.Close() //nolint:errcheck
+
+	value, err := src.Get(bucketKey(MetaBucket, schemaVersionKey), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		err = ErrNotFound
+	}
+
+	version, err := decodeSchemaVersion(value, err)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = checkSchemaVersion(version, CurrentSchemaVersion()); err != nil {
+		return 0, err
+	}
+
+	// the backup is copied to a temporary directory first, so a partial copy is never applied
+	tmp := restoreDir(d.dir) + ".tmp"
+	if err = os.RemoveAll(tmp); err != nil {
+		return 0, err
+	}
+
+	iter := src.NewIterator(nil, nil)
+	defer iter.Release()
+	if err = copyTo(iter, tmp); err != nil {
+		return 0, err
+	}
+
+	if err = os.RemoveAll(restoreDir(d.dir)); err != nil {
+		return 0, err
+	}
+
+	return version, os.Rename(tmp, restoreDir(d.dir))
+}
+
+// copyTo writes the iterator's entries to a new database in the given directory, which mustn't
+// already contain a database.
+func copyTo(iter iterator.Iterator, dir string) error {
+	dst, err := leveldb.OpenFile(filepath.Clean(dir), &opt.Options{ErrorIfExist: true})
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		// the iterator reuses its buffers, so the batch gets copies
+		batch.Put(append([]byte{}, iter.Key()...), append([]byte{}, iter.Value()...))
+		if batch.Len() < copyBatchSize {
+			continue
+		}
+
+		if err = dst.Write(batch, nil); err != nil {
+			_ = dst.Close()
+			return err
+		}
+		batch.Reset()
+	}
+
+	if err = iter.Error(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	if err = dst.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		_ = dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
+
+// applyStagedRestore replaces the database in dir with the restore staged for it, if there is one.
+// The replaced database is kept next to it, in case it's still needed.
+func applyStagedRestore(dir string) error {
+	staged := restoreDir(dir)
+	if _, err := os.Stat(staged); errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err := os.Stat(dir)
+	switch {
+	case err == nil:
+		replaced := fmt.Sprintf("%s.replaced-%d", dir, time.Now().Unix())
+		if err = os.Rename(dir, replaced); err != nil {
+			return err
+		}
+
+		log.Infof("restoring database %s from backup; the replaced database was moved to %s", dir, replaced)
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	return os.Rename(staged, dir)
+}
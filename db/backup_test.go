@@ -0,0 +1,75 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_BackupAndRestore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), Filename)
+	d, err := NewDatabase(dir)
+	require.NoError(t, err)
+	require.NoError(t, d.Put(SwapsBucket, []byte("a"), []byte("1")))
+
+	backup := filepath.Join(t.TempDir(), "backup")
+	require.NoError(t, d.Backup(backup))
+
+	// a backup can't overwrite an existing database
+	require.Error(t, d.Backup(backup))
+
+	// changes after the backup are undone once the restore is applied, when the database is
+	// next opened
+	require.NoError(t, d.Put(SwapsBucket, []byte("a"), []byte("2")))
+	require.NoError(t, d.Put(SwapsBucket, []byte("b"), []byte("3")))
+	version, err := d.StageRestore(backup)
+	require.NoError(t, err)
+	require.Equal(t, CurrentSchemaVersion(), version)
+
+	value, err := d.Get(SwapsBucket, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), value)
+	require.NoError(t, d.Close())
+
+	d, err = NewDatabase(dir)
+	require.NoError(t, err)
+	defer d.Close() //nolint:errcheck
+
+	value, err = d.Get(SwapsBucket, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), value)
+	_, err = d.Get(SwapsBucket, []byte("b"))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// the replaced database is kept
+	replaced, err := filepath.Glob(dir + ".replaced-*")
+	require.NoError(t, err)
+	require.Len(t, replaced, 1)
+	_, err = os.Stat(restoreDir(dir))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestDatabase_StageRestore_Invalid(t *testing.T) {
+	d, err := NewDatabase(filepath.Join(t.TempDir(), Filename))
+	require.NoError(t, err)
+	defer d.Close() //nolint:errcheck
+
+	_, err = d.StageRestore(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+
+	// backups from newer versions can't be restored
+	newer, err := NewDatabase(filepath.Join(t.TempDir(), "newer"))
+	require.NoError(t, err)
+	require.NoError(t, setSchemaVersion(newer, CurrentSchemaVersion()+1))
+	backup := filepath.Join(t.TempDir(), "backup")
+	require.NoError(t, newer.Backup(backup))
+	require.NoError(t, newer.Close())
+
+	_, err = d.StageRestore(backup)
+	require.ErrorIs(t, err, errSchemaTooNew)
+
+	_, err = NewMemoryDatabase().StageRestore(backup)
+	require.Equal(t, errInMemory, err)
+}
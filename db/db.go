@@ -0,0 +1,233 @@
+// Package db provides the embedded key-value store that swaps, their recovery info, and offers
+// are persisted to, so that they survive restarts.
+package db
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("db")
+
+// Buckets that the daemon's modules store their data in.
+var (
+	// SwapsBucket holds each swap's Info, keyed by swap ID
+	SwapsBucket = []byte("swaps")
+	// InfoBucket holds each swap's recovery info (keys and contract state), keyed by info file
+	InfoBucket = []byte("info")
+	// OffersBucket holds the maker's listed offers, keyed by offer ID
+	OffersBucket = []byte("offers")
+	// JournalBucket holds the journal of each swap's protocol transitions
+	JournalBucket = []byte("journal")
+	// SwapsByTimeBucket indexes swaps by start time, keyed by start time and swap ID
+	SwapsByTimeBucket = []byte("swaps-by-time")
+	// SwapsByStatusBucket indexes swaps by status, keyed by status, start time and swap ID
+	SwapsByStatusBucket = []byte("swaps-by-status")
+	// SwapsByPeerBucket indexes swaps by counterparty, keyed by peer ID, start time and swap ID
+	SwapsByPeerBucket = []byte("swaps-by-peer")
+	// PeersBucket holds the libp2p peers we've been connected to, keyed by peer ID
+	PeersBucket = []byte("peers")
+	// MetaBucket holds the database's own metadata, such as its schema version
+	MetaBucket = []byte("meta")
+)
+
+// Filename is the name of the database directory within the daemon's base path.
+const Filename = "db"
+
+var (
+	// ErrNotFound is returned by Get if there's no value with the given key
+	ErrNotFound = errors.New("not found")
+	// ErrClosed is returned by any operation on a closed database
+	ErrClosed = errors.New("database is closed")
+)
+
+// Database is a key-value store whose keys are grouped into buckets.
+type Database interface {
+	Put(bucket, key, value []byte) error
+	Get(bucket, key []byte) ([]byte, error)
+	Delete(bucket, key []byte) error
+	// Iterate calls fn with each key and value in the bucket, in key order, stopping at the first error
+	Iterate(bucket []byte, fn func(key, value []byte) error) error
+	// IterateRange is like Iterate, but only for the keys in the range [start, limit). A nil limit
+	// means the end of the bucket.
+	IterateRange(bucket, start, limit []byte, fn func(key, value []byte) error) error
+	// ReplaceBucket atomically replaces the contents of the bucket with the given keys and values
+	ReplaceBucket(bucket []byte, kvs map[string][]byte) error
+	// Backup writes a consistent snapshot of the database to a new database in the given directory
+	Backup(dir string) error
+	// StageRestore checks that the backup in the given directory can be restored, and stages it to
+	// replace the database the next time it's opened, returning the backup's schema version.
+	// Restores are staged rather than applied straight away so that data the daemon holds in
+	// memory can't overwrite the restored data.
+	StageRestore(backupDir string) (uint64, error)
+	Close() error
+}
+
+type database struct {
+	mu     sync.RWMutex
+	ldb    *leveldb.DB
+	closed bool
+	// directory the database is stored in; empty if it's only held in memory
+	dir string
+}
+
+// NewDatabase opens the database in the given directory, creating it if it doesn't exist. If a
+// restore has been staged for it, the database is replaced with the restored backup first. The
+// database is then migrated to the current schema version.
+func NewDatabase(dir string) (Database, error) {
+	dir = filepath.Clean(dir)
+	if err := applyStagedRestore(dir); err != nil {
+		return nil, fmt.Errorf("failed to restore database from backup: %w", err)
+	}
+
+	ldb, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &database{ldb: ldb, dir: dir}
+	if err = migrate(d, migrations); err != nil {
+		_ = ldb.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// NewMemoryDatabase returns a database that's only held in memory.
+func NewMemoryDatabase() Database {
+	ldb, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		// opening in-memory storage can't fail
+		panic(err)
+	}
+
+	d := &database{ldb: ldb}
+	if err = migrate(d, migrations); err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// bucketKey returns the key the given key is stored under within the bucket.
+func bucketKey(bucket, key []byte) []byte {
+	bk := make([]byte, 0, len(bucket)+1+len(key))
+	bk = append(bk, bucket...)
+	bk = append(bk, '/')
+	return append(bk, key...)
+}
+
+func (d *database) Put(bucket, key, value []byte) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	return d.ldb.Put(bucketKey(bucket, key), value, &opt.WriteOptions{Sync: true})
+}
+
+func (d *database) Get(bucket, key []byte) ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return nil, ErrClosed
+	}
+
+	value, err := d.ldb.Get(bucketKey(bucket, key), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+
+	return value, err
+}
+
+func (d *database) Delete(bucket, key []byte) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	return d.ldb.Delete(bucketKey(bucket, key), &opt.WriteOptions{Sync: true})
+}
+
+func (d *database) Iterate(bucket []byte, fn func(key, value []byte) error) error {
+	return d.IterateRange(bucket, nil, nil, fn)
+}
+
+func (d *database) IterateRange(bucket, start, limit []byte, fn func(key, value []byte) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	prefix := bucketKey(bucket, nil)
+	rng := util.BytesPrefix(prefix)
+	rng.Start = bucketKey(bucket, start)
+	if limit != nil {
+		rng.Limit = bucketKey(bucket, limit)
+	}
+
+	iter := d.ldb.NewIterator(rng, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		// the iterator reuses its buffers, so callers get copies
+		key := append([]byte{}, iter.Key()[len(prefix):]...)
+		value := append([]byte{}, iter.Value()...)
+		if err := fn(key, value); err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
+
+func (d *database) ReplaceBucket(bucket []byte, kvs map[string][]byte) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	batch := new(leveldb.Batch)
+	iter := d.ldb.NewIterator(util.BytesPrefix(bucketKey(bucket, nil)), nil)
+	for iter.Next() {
+		if _, has := kvs[string(iter.Key()[len(bucket)+1:])]; !has {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	for key, value := range kvs {
+		batch.Put(bucketKey(bucket, []byte(key)), value)
+	}
+
+	return d.ldb.Write(batch, &opt.WriteOptions{Sync: true})
+}
+
+func (d *database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+
+	d.closed = true
+	return d.ldb.Close()
+}
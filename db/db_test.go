@@ -0,0 +1,100 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_Buckets(t *testing.T) {
+	d := NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	require.NoError(t, d.Put(SwapsBucket, []byte("a"), []byte("swap")))
+	require.NoError(t, d.Put(OffersBucket, []byte("a"), []byte("offer")))
+
+	value, err := d.Get(SwapsBucket, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("swap"), value)
+
+	_, err = d.Get(InfoBucket, []byte("a"))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, d.Delete(SwapsBucket, []byte("a")))
+	_, err = d.Get(SwapsBucket, []byte("a"))
+	require.ErrorIs(t, err, ErrNotFound)
+
+	value, err = d.Get(OffersBucket, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("offer"), value)
+}
+
+func TestDatabase_ReplaceBucket(t *testing.T) {
+	d := NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	require.NoError(t, d.Put(OffersBucket, []byte("a"), []byte("1")))
+	require.NoError(t, d.Put(OffersBucket, []byte("b"), []byte("2")))
+	require.NoError(t, d.Put(SwapsBucket, []byte("c"), []byte("3")))
+
+	err := d.ReplaceBucket(OffersBucket, map[string][]byte{
+		"b": []byte("4"),
+		"d": []byte("5"),
+	})
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	err = d.Iterate(OffersBucket, func(key, value []byte) error {
+		got[string(key)] = string(value)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"b": "4", "d": "5"}, got)
+
+	// other buckets are untouched
+	_, err = d.Get(SwapsBucket, []byte("c"))
+	require.NoError(t, err)
+}
+
+func TestDatabase_IterateRange(t *testing.T) {
+	d := NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, d.Put(SwapsByTimeBucket, []byte(key), nil))
+	}
+	require.NoError(t, d.Put(SwapsBucket, []byte("b"), nil))
+
+	keys := func(start, limit []byte) []string {
+		var got []string
+		err := d.IterateRange(SwapsByTimeBucket, start, limit, func(key, _ []byte) error {
+			got = append(got, string(key))
+			return nil
+		})
+		require.NoError(t, err)
+		return got
+	}
+
+	require.Equal(t, []string{"b", "c"}, keys([]byte("b"), []byte("d")))
+	require.Equal(t, []string{"c", "d"}, keys([]byte("c"), nil))
+	require.Equal(t, []string{"a", "b", "c", "d"}, keys(nil, nil))
+}
+
+func TestDatabase_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	d, err := NewDatabase(dir)
+	require.NoError(t, err)
+	require.NoError(t, d.Put(SwapsBucket, []byte("a"), []byte("swap")))
+	require.NoError(t, d.Close())
+
+	_, err = d.Get(SwapsBucket, []byte("a"))
+	require.ErrorIs(t, err, ErrClosed)
+
+	d, err = NewDatabase(dir)
+	require.NoError(t, err)
+	defer d.Close() //nolint:errcheck
+
+	value, err := d.Get(SwapsBucket, []byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("swap"), value)
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	// key in MetaBucket that the schema version is stored under
+	schemaVersionKey = []byte("schema-version")
+
+	errSchemaTooNew         = errors.New("database was written by a newer version of swapd")
+	errInvalidSchemaVersion = errors.New("invalid database schema version")
+)
+
+// Migration moves the database's data from the previous schema version to Version.
+type Migration struct {
+	Version     uint64
+	Description string
+	Migrate     func(Database) error
+}
+
+// migrations are the database's forward migrations, in order of version. A migration is run once,
+// when a database at an older version is opened. Databases that predate schema versions are at
+// version 0.
+var migrations = []*Migration{
+	{
+		Version:     1,
+		Description: "record the schema version",
+		Migrate:     func(Database) error { return nil },
+	},
+}
+
+// CurrentSchemaVersion returns the schema version that databases are migrated to when opened.
+func CurrentSchemaVersion() uint64 {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the database's schema version.
+func SchemaVersion(d Database) (uint64, error) {
+	value, err := d.Get(MetaBucket, schemaVersionKey)
+	return decodeSchemaVersion(value, err)
+}
+
+func decodeSchemaVersion(value []byte, err error) (uint64, error) {
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if len(value) != 8 {
+		return 0, errInvalidSchemaVersion
+	}
+
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func setSchemaVersion(d Database, version uint64) error {
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, version)
+	return d.Put(MetaBucket, schemaVersionKey, value)
+}
+
+// checkSchemaVersion returns an error if a database at the given version is newer than the latest
+// supported version, in which case it can't be opened.
+func checkSchemaVersion(version, latest uint64) error {
+	if version > latest {
+		return fmt.Errorf("%w: it's at schema version %d, but only versions up to %d are supported",
+			errSchemaTooNew, version, latest)
+	}
+
+	return nil
+}
+
+// migrate runs the migrations that are newer than the database's schema version, in order,
+// recording the new version after each.
+func migrate(d Database, migrations []*Migration) error {
+	version, err := SchemaVersion(d)
+	if err != nil {
+		return err
+	}
+
+	if len(migrations) != 0 {
+		if err = checkSchemaVersion(version, migrations[len(migrations)-1].Version); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		log.Infof("migrating database to schema version %d: %s", m.Version, m.Description)
+		if err = m.Migrate(d); err != nil {
+			return fmt.Errorf("failed to migrate database to schema version %d: %w", m.Version, err)
+		}
+
+		if err = setSchemaVersion(d, m.Version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrate(t *testing.T) {
+	d := NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	version, err := SchemaVersion(d)
+	require.NoError(t, err)
+	require.Equal(t, CurrentSchemaVersion(), version)
+
+	var ran []uint64
+	newMigration := func(v uint64) *Migration {
+		return &Migration{
+			Version: v,
+			Migrate: func(Database) error {
+				ran = append(ran, v)
+				return nil
+			},
+		}
+	}
+
+	// only migrations newer than the database's version are run, in order
+	ms := []*Migration{newMigration(1), newMigration(2), newMigration(3)}
+	require.NoError(t, migrate(d, ms))
+	require.Equal(t, []uint64{2, 3}, ran)
+
+	version, err = SchemaVersion(d)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), version)
+
+	// migrating again does nothing
+	require.NoError(t, migrate(d, ms))
+	require.Equal(t, []uint64{2, 3}, ran)
+
+	// a database that's newer than the latest migration can't be opened
+	err = migrate(d, ms[:2])
+	require.ErrorIs(t, err, errSchemaTooNew)
+}
+
+func TestMigrate_Failed(t *testing.T) {
+	d := NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	errMigration := errors.New("migration failed")
+	ms := []*Migration{
+		{Version: 2, Migrate: func(Database) error { return nil }},
+		{Version: 3, Migrate: func(Database) error { return errMigration }},
+	}
+
+	// the version reached before the failed migration is recorded, so it's retried next time
+	require.ErrorIs(t, migrate(d, ms), errMigration)
+	version, err := SchemaVersion(d)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), version)
+}
+
+func TestNewDatabase_SchemaTooNew(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), Filename)
+	d, err := NewDatabase(dir)
+	require.NoError(t, err)
+	require.NoError(t, setSchemaVersion(d, CurrentSchemaVersion()+1))
+	require.NoError(t, d.Close())
+
+	_, err = NewDatabase(dir)
+	require.ErrorIs(t, err, errSchemaTooNew)
+}
@@ -0,0 +1,46 @@
+// Package metrics serves the daemon's metrics in the Prometheus format, so operators can alert
+// on stuck swaps and on problems with the nodes and network the daemon depends on.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("metrics")
+
+const namespace = "swapd"
+
+// The nodes whose RPC calls are timed.
+const (
+	Ethereum = "ethereum"
+	Monero   = "monero"
+)
+
+var (
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_duration_seconds",
+		Help:      "How long calls to the ethereum and monero nodes took, by node and method.",
+		// 5ms to 40s
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"node", "method"})
+
+	rpcErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_request_errors_total",
+		Help:      "How many calls to the ethereum and monero nodes failed, by node and method.",
+	}, []string{"node", "method"})
+)
+
+// ObserveRPC records a call of the given method to the given node, which started at start and
+// returned err.
+func ObserveRPC(node, method string, start time.Time, err error) {
+	rpcDuration.WithLabelValues(node, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		rpcErrors.WithLabelValues(node, method).Inc()
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/noot/atomic-swap/net"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	peersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "p2p", "peers"),
+		"How many peers we're connected to.",
+		nil, nil,
+	)
+
+	dhtPeersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "p2p", "dht_routing_table_peers"),
+		"How many peers are in the routing tables of our local network's DHT and the public DHT.",
+		[]string{"dht"}, nil,
+	)
+
+	balanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "balance"),
+		"The balances of the daemon's hot wallets, in ETH and XMR.",
+		[]string{"coin"}, nil,
+	)
+)
+
+// Net is the subset of net.Host used to report on the p2p network.
+type Net interface {
+	PeerCount() int
+	DHTStatus() *net.DHTStatus
+}
+
+// netCollector reports on the p2p network when the metrics are scraped.
+type netCollector struct {
+	net Net
+}
+
+func newNetCollector(n Net) *netCollector {
+	return &netCollector{
+		net: n,
+	}
+}
+
+// Describe ...
+func (c *netCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- peersDesc
+	ch <- dhtPeersDesc
+}
+
+// Collect ...
+func (c *netCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(peersDesc, prometheus.GaugeValue, float64(c.net.PeerCount()))
+
+	status := c.net.DHTStatus()
+	ch <- prometheus.MustNewConstMetric(dhtPeersDesc, prometheus.GaugeValue, float64(status.LANRoutingTableSize), "lan")
+	ch <- prometheus.MustNewConstMetric(dhtPeersDesc, prometheus.GaugeValue, float64(status.WANRoutingTableSize), "wan")
+}
+
+// balancesCollector gets the wallets' balances when the metrics are scraped.
+type balancesCollector struct {
+	ctx      context.Context
+	balances func(ctx context.Context) (*Balances, error)
+}
+
+func newBalancesCollector(ctx context.Context, balances func(context.Context) (*Balances, error)) *balancesCollector {
+	return &balancesCollector{
+		ctx:      ctx,
+		balances: balances,
+	}
+}
+
+// Describe ...
+func (c *balancesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- balanceDesc
+}
+
+// Collect ...
+func (c *balancesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(c.ctx, balancesTimeout)
+	defer cancel()
+
+	b, err := c.balances(ctx)
+	if err != nil {
+		// the balances are left out, rather than failing the scrape
+		log.Warnf("failed to get balances: %s", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(balanceDesc, prometheus.GaugeValue, b.ETH, "eth")
+	ch <- prometheus.MustNewConstMetric(balanceDesc, prometheus.GaugeValue, b.XMR, "xmr")
+}
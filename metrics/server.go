@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// how long a client may take to send a request's headers
+	readHeaderTimeout = time.Minute
+	// how long a scrape waits for the wallets' balances
+	balancesTimeout = time.Second * 10
+)
+
+// Balances are the balances of the daemon's hot wallets, in ETH and XMR.
+type Balances struct {
+	ETH float64
+	XMR float64
+}
+
+// Config is the config for a Server.
+type Config struct {
+	Ctx         context.Context
+	Port        uint16
+	SwapManager swap.Manager
+	Net         Net
+	// Balances is optional; if it's nil, the balances aren't exported
+	Balances func(ctx context.Context) (*Balances, error)
+}
+
+// Server serves the daemon's metrics on /metrics.
+type Server struct {
+	ctx      context.Context
+	port     uint16
+	sm       swap.Manager
+	registry *prometheus.Registry
+	// how long swaps spent at each status before moving on to the next
+	stageDuration *prometheus.HistogramVec
+}
+
+// NewServer returns a new *Server.
+func NewServer(cfg *Config) (*Server, error) {
+	s := &Server{
+		ctx:      cfg.Ctx,
+		port:     cfg.Port,
+		sm:       cfg.SwapManager,
+		registry: prometheus.NewRegistry(),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "swap_stage_duration_seconds",
+			Help:      "How long swaps spent at each status before moving on to the next.",
+			// 1s to 9h
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"status"}),
+	}
+
+	collectors := []prometheus.Collector{
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		rpcDuration,
+		rpcErrors,
+		s.stageDuration,
+		newSwapsCollector(cfg.SwapManager),
+		newNetCollector(cfg.Net),
+	}
+
+	if cfg.Balances != nil {
+		collectors = append(collectors, newBalancesCollector(cfg.Ctx, cfg.Balances))
+	}
+
+	for _, c := range collectors {
+		if err := s.registry.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Start starts timing the swaps' stages and serving the metrics.
+func (s *Server) Start() <-chan error {
+	errCh := make(chan error)
+
+	ch, unsubscribe := s.sm.Events().Subscribe()
+	go func() {
+		<-s.ctx.Done()
+		unsubscribe()
+	}()
+	go s.observeStages(ch)
+
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{
+			ErrorHandling: promhttp.ContinueOnError,
+		}))
+
+		srv := &http.Server{
+			Addr:              fmt.Sprintf(":%d", s.port),
+			Handler:           mux,
+			ReadHeaderTimeout: readHeaderTimeout,
+		}
+
+		log.Infof("serving metrics on http://localhost:%d/metrics", s.port)
+
+		if err := srv.ListenAndServe(); err != nil {
+			log.Errorf("failed to start metrics server: %s", err)
+			errCh <- err
+		}
+	}()
+
+	return errCh
+}
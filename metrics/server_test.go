@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockNet struct{}
+
+func (*mockNet) PeerCount() int {
+	return 3
+}
+
+func (*mockNet) DHTStatus() *net.DHTStatus {
+	return &net.DHTStatus{
+		LANRoutingTableSize: 1,
+		WANRoutingTableSize: 20,
+	}
+}
+
+func scrape(t *testing.T, port uint16) string {
+	resp, err := http.Get(fmt.Sprintf("http://localhost:%d/metrics", port))
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	sm := swap.NewManager()
+	const port = 6061
+	s, err := NewServer(&Config{
+		Ctx:         ctx,
+		Port:        port,
+		SwapManager: sm,
+		Net:         new(mockNet),
+		Balances: func(context.Context) (*Balances, error) {
+			return &Balances{ETH: 1.5, XMR: 20}, nil
+		},
+	})
+	require.NoError(t, err)
+	errCh := s.Start()
+	go func() {
+		err := <-errCh
+		require.NoError(t, err)
+	}()
+	time.Sleep(time.Millisecond * 300) // let server start up
+
+	done := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 1, 10, 0.05, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(done))
+	done.SetStatus(types.ETHLocked)
+	done.SetStatus(types.CompletedSuccess)
+	sm.CompleteOngoingSwap(done.ID())
+
+	ongoing := swap.NewInfo(types.Hash{2}, types.ProvidesETH, 1, 10, 0.05, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(ongoing))
+
+	ObserveRPC(Monero, "get_balance", time.Now(), nil)
+	ObserveRPC(Ethereum, "eth_call", time.Now(), errors.New("failed"))
+
+	// the stages are timed as the swap events are received
+	require.Eventually(t, func() bool {
+		body := scrape(t, port)
+		return strings.Contains(body, `swapd_swap_stage_duration_seconds_count{status="ETHLocked"} 1`)
+	}, time.Second*5, time.Millisecond*50)
+
+	body := scrape(t, port)
+	for _, expected := range []string{
+		`swapd_swaps{status="Success"} 1`,
+		`swapd_swaps{status="ExpectingKeys"} 1`,
+		`swapd_swap_status_age_seconds{status="ExpectingKeys"}`,
+		`swapd_swap_stage_duration_seconds_count{status="ExpectingKeys"} 1`,
+		`swapd_p2p_peers 3`,
+		`swapd_p2p_dht_routing_table_peers{dht="wan"} 20`,
+		`swapd_balance{coin="eth"} 1.5`,
+		`swapd_balance{coin="xmr"} 20`,
+		`swapd_rpc_request_duration_seconds_count{method="get_balance",node="monero"} 1`,
+		`swapd_rpc_request_errors_total{method="eth_call",node="ethereum"} 1`,
+	} {
+		require.Contains(t, body, expected)
+	}
+
+	// completed swaps aren't stuck at any status
+	require.NotContains(t, body, `swapd_swap_status_age_seconds{status="Success"}`)
+}
+
+func TestServer_BalancesFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	const port = 6062
+	s, err := NewServer(&Config{
+		Ctx:         ctx,
+		Port:        port,
+		SwapManager: swap.NewManager(),
+		Net:         new(mockNet),
+		Balances: func(context.Context) (*Balances, error) {
+			return nil, errors.New("wallet unreachable")
+		},
+	})
+	require.NoError(t, err)
+	_ = s.Start()
+	time.Sleep(time.Millisecond * 300) // let server start up
+
+	// the other metrics are still served
+	body := scrape(t, port)
+	require.Contains(t, body, `swapd_p2p_peers 3`)
+	require.NotContains(t, body, `swapd_balance{`)
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	swapsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "swaps"),
+		"How many swaps, past and ongoing, are at each status.",
+		[]string{"status"}, nil,
+	)
+
+	statusAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "swap_status_age_seconds"),
+		"The longest any ongoing swap has been at each status; a swap that stays at a status for too long is stuck.", //nolint:lll
+		[]string{"status"}, nil,
+	)
+)
+
+// swapsCollector counts the swaps at each status when the metrics are scraped.
+type swapsCollector struct {
+	sm swap.Manager
+}
+
+func newSwapsCollector(sm swap.Manager) *swapsCollector {
+	return &swapsCollector{
+		sm: sm,
+	}
+}
+
+// Describe ...
+func (c *swapsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- swapsDesc
+	ch <- statusAgeDesc
+}
+
+// Collect ...
+func (c *swapsCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+	counts := make(map[types.Status]int)
+	ages := make(map[types.Status]time.Duration)
+
+	for _, info := range c.sm.GetOngoingSwaps() {
+		status := info.Status()
+		counts[status]++
+
+		since := info.StartTime()
+		if times := info.StatusTimes(); len(times) != 0 {
+			since = times[len(times)-1].Time
+		}
+
+		if age := now.Sub(since); age > ages[status] {
+			ages[status] = age
+		}
+	}
+
+	past, err := c.sm.GetPastSwaps(nil)
+	if err != nil {
+		log.Warnf("failed to get past swaps: %s", err)
+	}
+
+	for _, info := range past {
+		counts[info.Status()]++
+	}
+
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(swapsDesc, prometheus.GaugeValue, float64(count), status.String())
+	}
+
+	for status, age := range ages {
+		ch <- prometheus.MustNewConstMetric(statusAgeDesc, prometheus.GaugeValue, age.Seconds(), status.String())
+	}
+}
+
+// observeStages times how long swaps spend at each status, as they move on from it, until the
+// channel is closed.
+func (s *Server) observeStages(ch <-chan *events.Event) {
+	for e := range ch {
+		// offer events aren't status changes
+		if e.Type == events.OfferMade || e.Type == events.OfferTaken {
+			continue
+		}
+
+		s.observeStage(e)
+	}
+}
+
+// observeStage times the stage the swap just moved on from.
+func (s *Server) observeStage(e *events.Event) {
+	info := s.sm.GetOngoingSwap(e.SwapID)
+	if info == nil {
+		info = s.sm.GetPastSwap(e.SwapID)
+	}
+	if info == nil {
+		return
+	}
+
+	times := info.StatusTimes()
+	for i := len(times) - 1; i > 0; i-- {
+		if times[i].Status != e.Status {
+			continue
+		}
+
+		prev := times[i-1]
+		s.stageDuration.WithLabelValues(prev.Status.String()).Observe(times[i].Time.Sub(prev.Time).Seconds())
+		return
+	}
+}
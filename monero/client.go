@@ -1,10 +1,11 @@
 package monero
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/noot/atomic-swap/common"
-	"github.com/noot/atomic-swap/common/rpctypes"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
 )
 
@@ -15,10 +16,14 @@ type Client interface {
 	GetAccounts() (*GetAccountsResponse, error)
 	GetAddress(idx uint) (*GetAddressResponse, error)
 	GetBalance(idx uint) (*GetBalanceResponse, error)
+	GetTransfers(idx uint) (*GetTransfersResponse, error)
+	CheckTxKey(txID, txKey string, address mcrypto.Address) (*CheckTxKeyResponse, error)
 	Transfer(to mcrypto.Address, accountIdx, amount uint) (*TransferResponse, error)
 	SweepAll(to mcrypto.Address, accountIdx uint) (*SweepAllResponse, error)
+	SetTransferPriority(priority TransferPriority) error
 	GenerateFromKeys(kp *mcrypto.PrivateKeyPair, filename, password string, env common.Environment) error
-	GenerateViewOnlyWalletFromKeys(vk *mcrypto.PrivateViewKey, address mcrypto.Address, filename, password string) error
+	GenerateViewOnlyWalletFromKeys(vk *mcrypto.PrivateViewKey, address mcrypto.Address, restoreHeight uint64,
+		filename, password string) error
 	GetHeight() (uint, error)
 	Refresh() error
 	CreateWallet(filename, password string) error
@@ -29,6 +34,8 @@ type Client interface {
 type client struct {
 	sync.Mutex
 	endpoint string
+	// fee priority of transfers and sweeps; accessed atomically
+	priority uint32
 }
 
 // NewClient returns a new monero-wallet-rpc client.
@@ -54,6 +61,17 @@ func (c *client) GetBalance(idx uint) (*GetBalanceResponse, error) {
 	return c.callGetBalance(idx)
 }
 
+// GetTransfers returns the incoming transfers of the given account, including those in the pool.
+func (c *client) GetTransfers(idx uint) (*GetTransfersResponse, error) {
+	return c.callGetTransfers(idx)
+}
+
+// CheckTxKey returns the amount the given transaction sent to the given address, using the
+// transaction's secret key.
+func (c *client) CheckTxKey(txID, txKey string, address mcrypto.Address) (*CheckTxKeyResponse, error) {
+	return c.callCheckTxKey(txID, txKey, string(address))
+}
+
 func (c *client) Transfer(to mcrypto.Address, accountIdx, amount uint) (*TransferResponse, error) {
 	destination := Destination{
 		Amount:  amount,
@@ -67,13 +85,29 @@ func (c *client) SweepAll(to mcrypto.Address, accountIdx uint) (*SweepAllRespons
 	return c.callSweepAll(string(to), accountIdx)
 }
 
+// SetTransferPriority sets the fee priority of the transfers and sweeps made from now on.
+func (c *client) SetTransferPriority(priority TransferPriority) error {
+	if priority > PriorityPriority {
+		return fmt.Errorf("%w: %d", errInvalidTransferPriority, priority)
+	}
+
+	atomic.StoreUint32(&c.priority, uint32(priority))
+	return nil
+}
+
+func (c *client) transferPriority() uint {
+	return uint(atomic.LoadUint32(&c.priority))
+}
+
 func (c *client) GenerateFromKeys(kp *mcrypto.PrivateKeyPair, filename, password string, env common.Environment) error {
-	return c.callGenerateFromKeys(kp.SpendKey(), kp.ViewKey(), kp.Address(env), filename, password)
+	return c.callGenerateFromKeys(kp.SpendKey(), kp.ViewKey(), kp.Address(env), filename, password, 0)
 }
 
+// GenerateViewOnlyWalletFromKeys generates a view-only wallet that scans the chain from the given
+// height, or from the start if it's 0.
 func (c *client) GenerateViewOnlyWalletFromKeys(vk *mcrypto.PrivateViewKey, address mcrypto.Address,
-	filename, password string) error {
-	return c.callGenerateFromKeys(nil, vk, address, filename, password)
+	restoreHeight uint64, filename, password string) error {
+	return c.callGenerateFromKeys(nil, vk, address, filename, password, restoreHeight)
 }
 
 func (c *client) GetAddress(idx uint) (*GetAddressResponse, error) {
@@ -87,7 +121,7 @@ func (c *client) Refresh() error {
 func (c *client) refresh() error {
 	const method = "refresh"
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return err
 	}
@@ -110,7 +144,7 @@ func (c *client) OpenWallet(filename, password string) error {
 func (c *client) CloseWallet() error {
 	const method = "close_wallet"
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return err
 	}
@@ -54,7 +54,7 @@ func TestClient_Transfer(t *testing.T) {
 
 	// generate view-only account for A+B
 	walletFP := fmt.Sprintf("test-wallet-%s", time.Now().Format("2006-01-02-15:04:05.999999999"))
-	err = cXMRTaker.callGenerateFromKeys(nil, vkABPriv, kpABPub.Address(common.Mainnet), walletFP, "")
+	err = cXMRTaker.callGenerateFromKeys(nil, vkABPriv, kpABPub.Address(common.Mainnet), walletFP, "", 0)
 	require.NoError(t, err)
 	err = cXMRTaker.OpenWallet(walletFP, "")
 	require.NoError(t, err)
@@ -86,7 +86,7 @@ func TestClient_Transfer(t *testing.T) {
 	// generate spend account for A+B
 	skAKPriv := mcrypto.SumPrivateSpendKeys(kpA.SpendKey(), kpB.SpendKey())
 	// ignore the error for now, as it can error with "Wallet already exists."
-	_ = cXMRTaker.callGenerateFromKeys(skAKPriv, vkABPriv, kpABPub.Address(common.Mainnet), walletFP, "")
+	_ = cXMRTaker.callGenerateFromKeys(skAKPriv, vkABPriv, kpABPub.Address(common.Mainnet), walletFP, "", 0)
 
 	err = cXMRTaker.refresh()
 	require.NoError(t, err)
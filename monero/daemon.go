@@ -2,13 +2,12 @@ package monero
 
 import (
 	"encoding/json"
-
-	"github.com/noot/atomic-swap/common/rpctypes"
 )
 
 // DaemonClient represents a monerod client.
 type DaemonClient interface {
 	GenerateBlocks(address string, amount uint) error
+	GetInfo() (*GetInfoResponse, error)
 }
 
 // NewDaemonClient returns a new monerod client.
@@ -40,7 +39,7 @@ func (c *client) callGenerateBlocks(address string, amount uint) error {
 		return err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return err
 	}
@@ -51,3 +50,36 @@ func (c *client) callGenerateBlocks(address string, amount uint) error {
 
 	return nil
 }
+
+// GetInfoResponse ...
+type GetInfoResponse struct {
+	Height       uint   `json:"height"`
+	TargetHeight uint   `json:"target_height"` // zero if the daemon doesn't know of a higher chain
+	Synchronized bool   `json:"synchronized"`
+	Offline      bool   `json:"offline"`
+	NetType      string `json:"nettype"`
+}
+
+func (c *client) GetInfo() (*GetInfoResponse, error) {
+	return c.callGetInfo()
+}
+
+func (c *client) callGetInfo() (*GetInfoResponse, error) {
+	const method = "get_info"
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *GetInfoResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,52 @@
+package monero
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// TransferPriority is the fee priority of the transfers made by monero-wallet-rpc. Higher
+// priorities pay higher fees, so that transfers are mined sooner when blocks are full.
+type TransferPriority uint
+
+// The transfer priorities, from the lowest fee to the highest. PriorityDefault leaves the fee to
+// the wallet's default priority.
+const (
+	PriorityDefault TransferPriority = iota
+	PriorityUnimportant
+	PriorityNormal
+	PriorityElevated
+	PriorityPriority
+)
+
+var (
+	priorityNames = []string{"default", "unimportant", "normal", "elevated", "priority"}
+
+	errInvalidTransferPriority = errors.New("invalid transfer priority")
+)
+
+// NewTransferPriority parses a transfer priority, given by its name, eg. "elevated", or number.
+func NewTransferPriority(s string) (TransferPriority, error) {
+	for i, name := range priorityNames {
+		if s == name {
+			return TransferPriority(i), nil
+		}
+	}
+
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || n >= uint64(len(priorityNames)) {
+		return 0, fmt.Errorf("%w: %s", errInvalidTransferPriority, s)
+	}
+
+	return TransferPriority(n), nil
+}
+
+// String ...
+func (p TransferPriority) String() string {
+	if int(p) >= len(priorityNames) {
+		return fmt.Sprintf("TransferPriority(%d)", uint(p))
+	}
+
+	return priorityNames[p]
+}
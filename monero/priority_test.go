@@ -0,0 +1,23 @@
+package monero
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransferPriority(t *testing.T) {
+	p, err := NewTransferPriority("elevated")
+	require.NoError(t, err)
+	require.Equal(t, PriorityElevated, p)
+	require.Equal(t, "elevated", p.String())
+
+	p, err = NewTransferPriority("1")
+	require.NoError(t, err)
+	require.Equal(t, PriorityUnimportant, p)
+
+	for _, s := range []string{"", "5", "-1", "high"} {
+		_, err = NewTransferPriority(s)
+		require.ErrorIs(t, err, errInvalidTransferPriority)
+	}
+}
@@ -4,17 +4,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/noot/atomic-swap/common/rpctypes"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/metrics"
 )
 
+// post posts a JSON-RPC call to the client's endpoint, and records how long it took.
+func (c *client) post(method, params string) (*rpctypes.Response, error) {
+	start := time.Now()
+	resp, err := rpctypes.PostRPC(c.endpoint, method, params)
+	if err == nil && resp.Error != nil {
+		metrics.ObserveRPC(metrics.Monero, method, start, resp.Error)
+	} else {
+		metrics.ObserveRPC(metrics.Monero, method, start, err)
+	}
+
+	return resp, err
+}
+
 type generateFromKeysRequest struct {
 	Filename string `json:"filename"`
 	Address  string `json:"address"`
 	SpendKey string `json:"spendkey"`
 	ViewKey  string `json:"viewkey"`
 	Password string `json:"password"`
+	// RestoreHeight is the height the wallet starts scanning the chain from; 0 scans all of it
+	RestoreHeight uint64 `json:"restore_height,omitempty"`
 }
 
 type generateFromKeysResponse struct {
@@ -23,7 +40,7 @@ type generateFromKeysResponse struct {
 }
 
 func (c *client) callGenerateFromKeys(sk *mcrypto.PrivateSpendKey, vk *mcrypto.PrivateViewKey, address mcrypto.Address,
-	filename, password string) error {
+	filename, password string, restoreHeight uint64) error {
 	const (
 		method                 = "generate_from_keys"
 		successMessage         = "Wallet has been generated successfully."
@@ -31,10 +48,11 @@ func (c *client) callGenerateFromKeys(sk *mcrypto.PrivateSpendKey, vk *mcrypto.P
 	)
 
 	req := &generateFromKeysRequest{
-		Filename: filename,
-		Address:  string(address),
-		ViewKey:  vk.Hex(),
-		Password: password,
+		Filename:      filename,
+		Address:       string(address),
+		ViewKey:       vk.Hex(),
+		Password:      password,
+		RestoreHeight: restoreHeight,
 	}
 
 	if sk != nil {
@@ -46,7 +64,7 @@ func (c *client) callGenerateFromKeys(sk *mcrypto.PrivateSpendKey, vk *mcrypto.P
 		return err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return err
 	}
@@ -77,6 +95,7 @@ type Destination struct {
 type sweepAllRequest struct {
 	Address      string `json:"address"`
 	AccountIndex uint   `json:"account_index"`
+	Priority     uint   `json:"priority"`
 }
 
 // SweepAllResponse ...
@@ -94,6 +113,7 @@ func (c *client) callSweepAll(to string, accountIdx uint) (*SweepAllResponse, er
 	req := &sweepAllRequest{
 		AccountIndex: accountIdx,
 		Address:      to,
+		Priority:     c.transferPriority(),
 	}
 
 	params, err := json.Marshal(req)
@@ -101,7 +121,7 @@ func (c *client) callSweepAll(to string, accountIdx uint) (*SweepAllResponse, er
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +142,7 @@ type transferRequest struct {
 	Destinations []Destination `json:"destinations"`
 	AccountIndex uint          // optional
 	Priority     uint          `json:"priority"`
+	GetTxKey     bool          `json:"get_tx_key"`
 }
 
 // TransferResponse ...
@@ -144,7 +165,8 @@ func (c *client) callTransfer(destinations []Destination, accountIdx uint) (*Tra
 	req := &transferRequest{
 		Destinations: destinations,
 		AccountIndex: accountIdx,
-		Priority:     0,
+		Priority:     c.transferPriority(),
+		GetTxKey:     true,
 	}
 
 	params, err := json.Marshal(req)
@@ -152,7 +174,7 @@ func (c *client) callTransfer(destinations []Destination, accountIdx uint) (*Tra
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -193,7 +215,7 @@ func (c *client) callGetBalance(idx uint) (*GetBalanceResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +232,103 @@ func (c *client) callGetBalance(idx uint) (*GetBalanceResponse, error) {
 	return res, nil
 }
 
+type checkTxKeyRequest struct {
+	TxID    string `json:"txid"`
+	TxKey   string `json:"tx_key"`
+	Address string `json:"address"`
+}
+
+// CheckTxKeyResponse ...
+type CheckTxKeyResponse struct {
+	Confirmations uint64 `json:"confirmations"`
+	InPool        bool   `json:"in_pool"`
+	Received      uint64 `json:"received"`
+}
+
+func (c *client) callCheckTxKey(txID, txKey, address string) (*CheckTxKeyResponse, error) {
+	const method = "check_tx_key"
+
+	req := &checkTxKeyRequest{
+		TxID:    txID,
+		TxKey:   txKey,
+		Address: address,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *CheckTxKeyResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type getTransfersRequest struct {
+	In           bool `json:"in"`
+	Pool         bool `json:"pool"`
+	AccountIndex uint `json:"account_index"`
+}
+
+// Transfer is a transfer to or from the wallet.
+type Transfer struct {
+	TxID          string `json:"txid"`
+	Address       string `json:"address"`
+	Amount        uint64 `json:"amount"`
+	Confirmations uint64 `json:"confirmations"`
+	Height        uint64 `json:"height"`
+	UnlockTime    uint64 `json:"unlock_time"`
+}
+
+// GetTransfersResponse ...
+type GetTransfersResponse struct {
+	In   []*Transfer `json:"in"`
+	Pool []*Transfer `json:"pool"`
+}
+
+func (c *client) callGetTransfers(idx uint) (*GetTransfersResponse, error) {
+	const method = "get_transfers"
+
+	req := &getTransfersRequest{
+		In:           true,
+		Pool:         true,
+		AccountIndex: idx,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *GetTransfersResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
 // GetAddressRequest ...
 type GetAddressRequest struct {
 	AccountIndex uint `json:"account_index"`
@@ -232,7 +351,7 @@ func (c *client) callGetAddress(idx uint) (*GetAddressResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -257,7 +376,7 @@ type GetAccountsResponse struct {
 func (c *client) callGetAccounts() (*GetAccountsResponse, error) {
 	const method = "get_accounts"
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return nil, err
 	}
@@ -292,7 +411,7 @@ func (c *client) callOpenWallet(filename, password string) error {
 		return err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return err
 	}
@@ -324,7 +443,7 @@ func (c *client) callCreateWallet(filename, password string) error {
 		return err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return err
 	}
@@ -343,7 +462,7 @@ type getHeightResponse struct {
 func (c *client) callGetHeight() (uint, error) {
 	const method = "get_height"
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return 0, err
 	}
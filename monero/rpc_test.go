@@ -22,6 +22,6 @@ func TestCallGenerateFromKeys(t *testing.T) {
 
 	c := NewClient(tests.CreateWalletRPCService(t))
 	err = c.callGenerateFromKeys(kp.SpendKey(), kp.ViewKey(), kp.Address(common.Mainnet),
-		fmt.Sprintf("test-wallet-%d", r), "")
+		fmt.Sprintf("test-wallet-%d", r), "", 0)
 	require.NoError(t, err)
 }
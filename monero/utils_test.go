@@ -1,6 +1,7 @@
 package monero
 
 import (
+	"context"
 	"sync"
 	"testing"
 
@@ -26,7 +27,7 @@ func TestWaitForBlocks(t *testing.T) {
 		wg.Done()
 	}()
 
-	_, err = WaitForBlocks(c, 1)
+	_, err = NewBlockWaiter(c, nil).WaitForConfirmations(context.Background(), 1)
 	require.NoError(t, err)
 	wg.Wait()
 }
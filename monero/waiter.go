@@ -0,0 +1,137 @@
+package monero
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	blockSleepDuration  = time.Second * 10
+	defaultStallTimeout = time.Minute * 20 // 10x the monero target block time
+)
+
+// heightClient is the subset of Client needed to wait for blocks.
+type heightClient interface {
+	GetHeight() (uint, error)
+	Refresh() error
+}
+
+// WaitConfig configures a BlockWaiter. All fields are optional.
+type WaitConfig struct {
+	// OnProgress is called whenever the chain height changes while waiting.
+	OnProgress func(height, target uint)
+	// OnStall is called whenever the chain height hasn't advanced for StallTimeout.
+	OnStall func(height uint, stalledFor time.Duration)
+	// StallTimeout is how long the height may stay the same before the node is considered stalled.
+	// Defaults to 20 minutes.
+	StallTimeout time.Duration
+	// PollInterval is how often the height is checked. Defaults to 10 seconds.
+	PollInterval time.Duration
+}
+
+// BlockWaiter waits for the monero chain to reach a given height, reporting progress
+// and alerting if the node's height stops advancing.
+type BlockWaiter struct {
+	client       heightClient
+	onProgress   func(height, target uint)
+	onStall      func(height uint, stalledFor time.Duration)
+	stallTimeout time.Duration
+	pollInterval time.Duration
+}
+
+// NewBlockWaiter returns a new *BlockWaiter.
+func NewBlockWaiter(client heightClient, cfg *WaitConfig) *BlockWaiter {
+	if cfg == nil {
+		cfg = &WaitConfig{}
+	}
+
+	w := &BlockWaiter{
+		client:       client,
+		onProgress:   cfg.OnProgress,
+		onStall:      cfg.OnStall,
+		stallTimeout: cfg.StallTimeout,
+		pollInterval: cfg.PollInterval,
+	}
+
+	if w.stallTimeout == 0 {
+		w.stallTimeout = defaultStallTimeout
+	}
+
+	if w.pollInterval == 0 {
+		w.pollInterval = blockSleepDuration
+	}
+
+	return w
+}
+
+// Height refreshes the client and returns the current chain height.
+func (w *BlockWaiter) Height() (uint, error) {
+	if err := w.client.Refresh(); err != nil {
+		return 0, err
+	}
+
+	height, err := w.client.GetHeight()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get height: %w", err)
+	}
+
+	return height, nil
+}
+
+// WaitForConfirmations waits for `count` new blocks on top of the current height.
+// It returns the height of the chain.
+func (w *BlockWaiter) WaitForConfirmations(ctx context.Context, count uint) (uint, error) {
+	height, err := w.Height()
+	if err != nil {
+		return 0, err
+	}
+
+	return w.WaitForHeight(ctx, height+count)
+}
+
+// WaitForHeight waits until the chain reaches the target height, or the context is cancelled.
+// It returns the height of the chain.
+func (w *BlockWaiter) WaitForHeight(ctx context.Context, target uint) (uint, error) {
+	var (
+		lastHeight  uint
+		lastChange  = time.Now()
+		lastAlerted time.Time
+	)
+
+	for {
+		height, err := w.Height()
+		if err != nil {
+			log.Warnf("failed to get monero height: %s", err)
+		} else {
+			if height != lastHeight {
+				lastHeight = height
+				lastChange = time.Now()
+				if w.onProgress != nil {
+					w.onProgress(height, target)
+				}
+			}
+
+			if height >= target {
+				return height, nil
+			}
+
+			log.Infof("waiting for monero block height %d, current height=%d", target, height)
+		}
+
+		if stalledFor := time.Since(lastChange); stalledFor >= w.stallTimeout &&
+			time.Since(lastAlerted) >= w.stallTimeout {
+			lastAlerted = time.Now()
+			log.Warnf("monero node appears stalled: height %d has not advanced for %s", lastHeight, stalledFor)
+			if w.onStall != nil {
+				w.onStall(lastHeight, stalledFor)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return lastHeight, ctx.Err()
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package monero
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockHeightClient struct {
+	sync.Mutex
+	heights []uint // heights returned by successive calls; the last one repeats
+}
+
+func (c *mockHeightClient) Refresh() error {
+	return nil
+}
+
+func (c *mockHeightClient) GetHeight() (uint, error) {
+	c.Lock()
+	defer c.Unlock()
+	h := c.heights[0]
+	if len(c.heights) > 1 {
+		c.heights = c.heights[1:]
+	}
+	return h, nil
+}
+
+func TestBlockWaiter_WaitForConfirmations(t *testing.T) {
+	c := &mockHeightClient{heights: []uint{10, 10, 11, 11, 12, 13}}
+
+	var progress []uint
+	w := NewBlockWaiter(c, &WaitConfig{
+		OnProgress: func(height, target uint) {
+			require.Equal(t, uint(12), target)
+			progress = append(progress, height)
+		},
+		PollInterval: time.Millisecond,
+	})
+
+	height, err := w.WaitForConfirmations(context.Background(), 2)
+	require.NoError(t, err)
+	require.Equal(t, uint(12), height)
+	require.Equal(t, []uint{10, 11, 12}, progress)
+}
+
+func TestBlockWaiter_Stalled(t *testing.T) {
+	c := &mockHeightClient{heights: []uint{10}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var stalledAt uint
+	w := NewBlockWaiter(c, &WaitConfig{
+		OnStall: func(height uint, _ time.Duration) {
+			stalledAt = height
+			cancel()
+		},
+		StallTimeout: time.Millisecond * 20,
+		PollInterval: time.Millisecond,
+	})
+
+	_, err := w.WaitForHeight(ctx, 11)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, uint(10), stalledAt)
+}
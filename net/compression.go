@@ -0,0 +1,26 @@
+package net
+
+import (
+	"github.com/noot/atomic-swap/net/message"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+)
+
+// messages smaller than this aren't worth compressing
+const minCompressSize = 256
+
+// compressMessage compresses the encoded message if it's going to a peer that can decompress it
+// and compression makes it smaller. Swap messages are large enough to be worth compressing, as
+// they embed hex-encoded proofs in JSON.
+func compressMessage(s libp2pnetwork.Stream, encMsg []byte) []byte {
+	if ns, ok := s.(*negotiatedStream); !ok || !ns.compress || len(encMsg) < minCompressSize {
+		return encMsg
+	}
+
+	compressed := message.Compress(encMsg)
+	if len(compressed) >= len(encMsg) {
+		return encMsg
+	}
+
+	return compressed
+}
@@ -0,0 +1,83 @@
+package net
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressMessage(t *testing.T) {
+	encMsg, err := (&SendKeysMessage{DLEqProof: strings.Repeat("ab", 400)}).Encode()
+	require.NoError(t, err)
+
+	// only streams whose peer can decompress get compressed messages
+	require.Equal(t, encMsg, compressMessage(nil, encMsg))
+
+	require.Equal(t, encMsg, compressMessage(&negotiatedStream{envelope: true}, encMsg))
+
+	compressed := compressMessage(&negotiatedStream{compress: true}, encMsg)
+	require.Less(t, len(compressed), len(encMsg))
+	require.Equal(t, byte(message.CompressedType), compressed[0])
+
+	msg, err := message.DecodeMessage(compressed)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("ab", 400), msg.(*SendKeysMessage).DLEqProof)
+
+	small, err := (&SendKeysMessage{}).Encode()
+	require.NoError(t, err)
+	require.Equal(t, small, compressMessage(&negotiatedStream{compress: true}, small))
+}
+
+func TestHost_Initiate_Compression(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{DLEqProof: strings.Repeat("ab", 400)}, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+	require.True(t, ha.swaps[testID].stream.(*negotiatedStream).compress)
+	require.True(t, hb.swaps[testID].stream.(*negotiatedStream).compress)
+}
+
+func TestHost_Initiate_NoCompression(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	hb.hello = defaultHello()
+	hb.hello.Compression = nil
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{DLEqProof: strings.Repeat("ab", 400)}, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+	require.NotNil(t, hb.swaps[testID])
+	require.NotNil(t, ha.swaps[testID])
+	require.False(t, ha.swaps[testID].stream.(*negotiatedStream).compress)
+	require.False(t, hb.swaps[testID].stream.(*negotiatedStream).compress)
+}
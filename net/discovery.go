@@ -3,6 +3,7 @@ package net
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/noot/atomic-swap/common/types"
@@ -18,23 +19,101 @@ import (
 const (
 	initialAdvertisementTimeout = time.Millisecond
 	tryAdvertiseTimeout         = time.Second * 30
-	defaultAdvertiseTTL         = time.Minute * 5
 	defaultMaxPeers             = 50 // TODO: make this configurable
+
+	// DefaultAdvertiseInterval is how often we advertise ourselves in the DHT by default
+	DefaultAdvertiseInterval = time.Minute * 5
+	// DefaultDHTRefreshInterval is how often the DHT's routing table is refreshed by default
+	DefaultDHTRefreshInterval = time.Minute * 10
+)
+
+// DHTMode is whether we serve DHT records to other peers, or only query the DHT.
+type DHTMode string
+
+const (
+	// DHTModeAuto makes us a DHT server once we're found to be publicly reachable, and a client
+	// otherwise. It's the default.
+	DHTModeAuto DHTMode = "auto"
+	// DHTModeClient makes us only query the DHT, which suits nodes that aren't publicly reachable
+	// or have little bandwidth
+	DHTModeClient DHTMode = "client"
+	// DHTModeServer makes us always serve DHT records, which suits publicly reachable nodes such
+	// as bootnodes
+	DHTModeServer DHTMode = "server"
 )
 
+// NewDHTMode returns the DHT mode with the given name, or DHTModeAuto if it's empty.
+func NewDHTMode(mode string) (DHTMode, error) {
+	switch m := DHTMode(mode); m {
+	case "":
+		return DHTModeAuto, nil
+	case DHTModeAuto, DHTModeClient, DHTModeServer:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w: %q", errInvalidDHTMode, mode)
+	}
+}
+
+func (m DHTMode) kadMode() kaddht.ModeOpt {
+	switch m {
+	case DHTModeClient:
+		return kaddht.ModeClient
+	case DHTModeServer:
+		return kaddht.ModeServer
+	default:
+		return kaddht.ModeAutoServer
+	}
+}
+
+// DHTStatus reports the state of our DHT, so that operators can tell whether we can discover
+// other peers' offers and advertise our own.
+type DHTStatus struct {
+	Mode DHTMode
+	// LANRoutingTableSize and WANRoutingTableSize are the numbers of peers in the routing tables
+	// of our local network's DHT and the public DHT
+	LANRoutingTableSize int
+	WANRoutingTableSize int
+	// LastAdvertised is when we last advertised ourselves in the DHT; it's zero if we haven't
+	LastAdvertised time.Time
+}
+
 type discovery struct {
-	ctx         context.Context
-	dht         *dual.DHT
-	h           libp2phost.Host
-	rd          *libp2pdiscovery.RoutingDiscovery
-	provides    []types.ProvidesCoin
+	ctx  context.Context
+	dht  *dual.DHT
+	h    libp2phost.Host
+	rd   *libp2pdiscovery.RoutingDiscovery
+	mode DHTMode
+	// returns our current offers, which determine the coins we advertise that we provide
+	offers      func() []*types.Offer
 	advertiseCh chan struct{}
+	// how often we advertise ourselves once we've done so successfully
+	advertiseInterval time.Duration
+
+	lastAdvertisedMu sync.Mutex
+	lastAdvertised   time.Time
 }
 
-func newDiscovery(ctx context.Context, h libp2phost.Host, bnsFunc func() []peer.AddrInfo) (*discovery, error) {
+func newDiscovery(ctx context.Context, h libp2phost.Host, bnsFunc func() []peer.AddrInfo,
+	offers func() []*types.Offer, cfg *Config) (*discovery, error) {
+	mode, err := NewDHTMode(string(cfg.DHTMode))
+	if err != nil {
+		return nil, err
+	}
+
+	refreshInterval := cfg.DHTRefreshInterval
+	if refreshInterval == 0 {
+		refreshInterval = DefaultDHTRefreshInterval
+	}
+
+	advertiseInterval := cfg.AdvertiseInterval
+	if advertiseInterval == 0 {
+		advertiseInterval = DefaultAdvertiseInterval
+	}
+
 	dhtOpts := []dual.Option{
 		dual.DHTOption(kaddht.BootstrapPeersFunc(bnsFunc)),
-		dual.DHTOption(kaddht.Mode(kaddht.ModeAutoServer)),
+		dual.DHTOption(kaddht.Mode(mode.kadMode())),
+		dual.DHTOption(kaddht.RoutingTableRefreshPeriod(refreshInterval)),
 	}
 
 	dht, err := dual.New(ctx, h, dhtOpts...)
@@ -45,14 +124,30 @@ func newDiscovery(ctx context.Context, h libp2phost.Host, bnsFunc func() []peer.
 	rd := libp2pdiscovery.NewRoutingDiscovery(dht)
 
 	return &discovery{
-		ctx:         ctx,
-		dht:         dht,
-		h:           h,
-		rd:          rd,
-		advertiseCh: make(chan struct{}),
+		ctx:               ctx,
+		dht:               dht,
+		h:                 h,
+		rd:                rd,
+		mode:              mode,
+		offers:            offers,
+		advertiseCh:       make(chan struct{}),
+		advertiseInterval: advertiseInterval,
 	}, nil
 }
 
+// status returns the state of our DHT.
+func (d *discovery) status() *DHTStatus {
+	d.lastAdvertisedMu.Lock()
+	defer d.lastAdvertisedMu.Unlock()
+
+	return &DHTStatus{
+		Mode:                d.mode,
+		LANRoutingTableSize: d.dht.LAN.RoutingTable().Size(),
+		WANRoutingTableSize: d.dht.WAN.RoutingTable().Size(),
+		LastAdvertised:      d.lastAdvertised,
+	}
+}
+
 func (d *discovery) start() error {
 	err := d.dht.Bootstrap(d.ctx)
 	if err != nil {
@@ -82,8 +177,9 @@ func (d *discovery) advertise() {
 			return
 		}
 
-		for _, provides := range d.provides {
-			ttl, err = d.rd.Advertise(d.ctx, string(provides))
+		provides, nextExpiry := advertisedCoins(d.offers(), time.Now())
+		for _, coin := range provides {
+			ttl, err = d.rd.Advertise(d.ctx, string(coin))
 			if err != nil {
 				log.Debugf("failed to advertise in the DHT: err=%s", err)
 				ttl = tryAdvertiseTimeout
@@ -98,14 +194,21 @@ func (d *discovery) advertise() {
 			return
 		}
 
-		ttl = defaultAdvertiseTTL
+		ttl = d.advertiseInterval
+		d.lastAdvertisedMu.Lock()
+		d.lastAdvertised = time.Now()
+		d.lastAdvertisedMu.Unlock()
+
+		// check the offers again once the next one expires, so that we stop advertising coins
+		// we no longer have offers for
+		if until := time.Until(nextExpiry); !nextExpiry.IsZero() && until < ttl {
+			ttl = until
+		}
 	}
 
 	for {
 		select {
 		case <-d.advertiseCh:
-			// TODO: check current offers, as this may change
-			d.provides = []types.ProvidesCoin{types.ProvidesXMR}
 			doAdvertise()
 		case <-time.After(ttl):
 			doAdvertise()
@@ -115,6 +218,35 @@ func (d *discovery) advertise() {
 	}
 }
 
+// advertisedCoins returns the coins provided by the offers that haven't expired at the given time,
+// and when the first of them expires, which is zero if none of them do.
+func advertisedCoins(offers []*types.Offer, now time.Time) ([]types.ProvidesCoin, time.Time) {
+	var (
+		provides   []types.ProvidesCoin
+		nextExpiry time.Time
+		seen       = make(map[types.ProvidesCoin]struct{})
+	)
+
+	for _, o := range offers {
+		if o.Expired(now) {
+			continue
+		}
+
+		if o.ExpiresAt != nil && (nextExpiry.IsZero() || o.ExpiresAt.Before(nextExpiry)) {
+			nextExpiry = *o.ExpiresAt
+		}
+
+		if _, has := seen[o.Provides]; has {
+			continue
+		}
+
+		seen[o.Provides] = struct{}{}
+		provides = append(provides, o.Provides)
+	}
+
+	return provides, nextExpiry
+}
+
 func (d *discovery) discover(provides types.ProvidesCoin,
 	searchTime time.Duration) ([]peer.AddrInfo, error) {
 	log.Debugf("attempting to find DHT peers that provide [%s] for %vs...",
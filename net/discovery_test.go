@@ -6,6 +6,7 @@ import (
 
 	"github.com/noot/atomic-swap/common/types"
 
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,11 +34,89 @@ func TestHost_Discover(t *testing.T) {
 	err = hc.h.Connect(ha.ctx, hb.addrInfo())
 	require.NoError(t, err)
 
+	// only the coins we have offers for are advertised
+	ha.handler.(*mockHandler).offers = []*types.Offer{{Provides: types.ProvidesXMR}}
 	ha.Advertise()
-	time.Sleep(initialAdvertisementTimeout)
 
-	peers, err := hc.Discover(types.ProvidesXMR, time.Second)
+	// the advertisement reaches the DHT asynchronously, so it may take a few tries to find
+	var peers []peer.AddrInfo
+	require.Eventually(t, func() bool {
+		peers, err = hc.Discover(types.ProvidesXMR, time.Second)
+		return err == nil && len(peers) != 0
+	}, time.Second*10, time.Millisecond*100)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(peers))
 	require.Equal(t, ha.h.ID(), peers[0].ID)
 }
+
+func TestAdvertisedCoins(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	soon := now.Add(time.Minute)
+	later := now.Add(time.Hour)
+
+	provides, nextExpiry := advertisedCoins(nil, now)
+	require.Empty(t, provides)
+	require.True(t, nextExpiry.IsZero())
+
+	// expired offers aren't advertised
+	provides, nextExpiry = advertisedCoins([]*types.Offer{
+		{Provides: types.ProvidesXMR, ExpiresAt: &expired},
+	}, now)
+	require.Empty(t, provides)
+	require.True(t, nextExpiry.IsZero())
+
+	provides, nextExpiry = advertisedCoins([]*types.Offer{
+		{Provides: types.ProvidesXMR, ExpiresAt: &expired},
+		{Provides: types.ProvidesXMR, ExpiresAt: &later},
+		{Provides: types.ProvidesXMR, ExpiresAt: &soon},
+		{Provides: types.ProvidesXMR},
+	}, now)
+	require.Equal(t, []types.ProvidesCoin{types.ProvidesXMR}, provides)
+	require.Equal(t, soon, nextExpiry)
+}
+
+func TestNewDHTMode(t *testing.T) {
+	mode, err := NewDHTMode("")
+	require.NoError(t, err)
+	require.Equal(t, DHTModeAuto, mode)
+
+	for _, m := range []DHTMode{DHTModeAuto, DHTModeClient, DHTModeServer} {
+		mode, err = NewDHTMode(string(m))
+		require.NoError(t, err)
+		require.Equal(t, m, mode)
+	}
+
+	_, err = NewDHTMode("bootnode")
+	require.ErrorIs(t, err, errInvalidDHTMode)
+}
+
+func TestHost_DHTStatus(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	ha.handler.(*mockHandler).offers = []*types.Offer{{Provides: types.ProvidesXMR}}
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	status := ha.DHTStatus()
+	require.Equal(t, DHTModeAuto, status.Mode)
+	require.Zero(t, status.LANRoutingTableSize)
+	require.True(t, status.LastAdvertised.IsZero())
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	// hb joins our local network's routing table, which lets us advertise ourselves
+	ha.Advertise()
+	require.Eventually(t, func() bool {
+		status = ha.DHTStatus()
+		return status.LANRoutingTableSize == 1 && !status.LastAdvertised.IsZero()
+	}, time.Second*10, time.Millisecond*100)
+}
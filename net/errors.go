@@ -11,4 +11,23 @@ var (
 	errNoOngoingSwap         = errors.New("no swap currently happening")
 	errSwapAlreadyInProgress = errors.New("already have ongoing swap")
 	errInvalidBufferLength   = errors.New("buffer has length 0")
+	errQuoteRejected         = errors.New("peer did not quote the offer")
+	errOfferNotSigned        = errors.New("offer is not signed")
+	errInvalidOfferSignature = errors.New("invalid offer signature")
+	errOfferMakerMismatch    = errors.New("offer was not made by the peer it was received from")
+	errIncompatiblePeer      = errors.New("peer is incompatible")
+	errUnsupportedProxyAddr  = errors.New("address can't be dialed through the proxy")
+	errInvalidOnionAddress   = errors.New("invalid onion address")
+	errOnionRequiresProxy    = errors.New("an onion address requires a SOCKS5 proxy")
+	errHolePunchFailed       = errors.New("no direct connection was made")
+	errInvalidSessionKey     = errors.New("invalid session key")
+	errNoSessionKey          = errors.New("peer advertised session keys but didn't send one")
+	errInvalidSignedMessage  = errors.New("swap message isn't signed by the peer's session key")
+	errMDNSRequiresDirect    = errors.New("mDNS can't be used with a SOCKS5 proxy")
+	errInvalidDHTMode        = errors.New("invalid DHT mode, expected auto, client or server")
+	errOrderbookDisabled     = errors.New("the orderbook crawler isn't enabled")
+	errNoPrivateOffer        = errors.New("peer has no private offer for the token")
+	errInvalidIPAddress      = errors.New("invalid IP address")
+	errPeerNotBanned         = errors.New("peer is not banned")
+	errIPNotBanned           = errors.New("IP address is not banned")
 )
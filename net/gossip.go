@@ -0,0 +1,277 @@
+package net
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net/message"
+
+	libp2phost "github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+const (
+	offersTopicID = "/offers/0"
+
+	// how often makers publish their offers, so that takers who join later learn about them
+	gossipRepublishInterval = time.Minute
+	// how long a maker's offers are kept after we last heard from it
+	gossipOffersTTL = gossipRepublishInterval * 3
+	// most offers a maker may publish at once
+	maxGossipedOffers  = 64
+	maxGossipedMsgSize = 1 << 16
+)
+
+// PeerOffers are the offers a maker has published on the offers topic.
+type PeerOffers struct {
+	Peer   peer.AddrInfo
+	Offers []*types.Offer
+	// Latency is the peer's latency, if we've measured it
+	Latency time.Duration
+}
+
+// marketEntry is the latest set of offers a maker published.
+type marketEntry struct {
+	offers   []*types.Offer
+	received time.Time
+}
+
+// gossip publishes our offers on a gossipsub topic, and builds a view of the market from the
+// offers other makers publish there, so that takers don't need to query every maker.
+//
+// Each message is an encoded QueryResponse listing all of the maker's public offers, so it both
+// updates offers and removes those no longer listed; a maker with no offers left publishes an
+// empty list. Messages are signed by the maker's libp2p key, so a maker's offers can't be
+// replaced by anyone else.
+type gossip struct {
+	ctx   context.Context
+	h     libp2phost.Host
+	topic *pubsub.Topic
+	sub   *pubsub.Subscription
+	// returns our offers that anyone may take
+	offers     func() []*types.Offer
+	publishCh  chan struct{}
+	reputation *reputation
+	feed       *offerFeed
+
+	mu     sync.RWMutex
+	market map[peer.ID]*marketEntry
+	// whether our last published message listed any offers
+	listed bool
+}
+
+func newGossip(ctx context.Context, h libp2phost.Host, topicName string,
+	offers func() []*types.Offer, rep *reputation, feed *offerFeed) (*gossip, error) {
+	ps, err := pubsub.NewGossipSub(ctx, h, pubsub.WithMaxMessageSize(maxGossipedMsgSize))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &gossip{
+		ctx:        ctx,
+		h:          h,
+		offers:     offers,
+		publishCh:  make(chan struct{}, 1),
+		reputation: rep,
+		feed:       feed,
+		market:     make(map[peer.ID]*marketEntry),
+	}
+
+	if err = ps.RegisterTopicValidator(topicName, g.validate); err != nil {
+		return nil, err
+	}
+
+	g.topic, err = ps.Join(topicName)
+	if err != nil {
+		return nil, err
+	}
+
+	g.sub, err = g.topic.Subscribe()
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (g *gossip) start() {
+	go g.receive()
+	go g.republish()
+}
+
+// validate rejects messages that aren't valid offer lists, so they aren't propagated, and messages
+// from banned makers. The decoded offers are passed on to the subscription.
+func (g *gossip) validate(_ context.Context, _ peer.ID, msg *pubsub.Message) bool {
+	from := msg.GetFrom()
+	if g.reputation.isBanned(from) {
+		return false
+	}
+
+	decoded, err := message.DecodeMessage(msg.Data)
+	if err != nil {
+		g.reputation.recordInvalidMessage(from)
+		return false
+	}
+
+	resp, ok := decoded.(*message.QueryResponse)
+	if !ok || len(resp.Offers) > maxGossipedOffers {
+		g.reputation.recordInvalidMessage(from)
+		return false
+	}
+
+	if err = verifyOffersFrom(from, resp.Offers); err != nil {
+		log.Debugf("rejecting offers published by %s: %s", from, err)
+		g.reputation.recordInvalidMessage(from)
+		return false
+	}
+
+	msg.ValidatorData = resp
+	return true
+}
+
+// publish publishes our offers as soon as possible, eg. after they've changed.
+func (g *gossip) publish() {
+	select {
+	case g.publishCh <- struct{}{}:
+	default:
+	}
+}
+
+func (g *gossip) republish() {
+	timer := time.NewTimer(time.Millisecond)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-g.ctx.Done():
+			return
+		case <-timer.C:
+			timer.Reset(gossipRepublishInterval)
+		case <-g.publishCh:
+		}
+
+		if err := g.publishOffers(); err != nil {
+			log.Debugf("failed to publish offers: %s", err)
+		}
+
+		g.pruneMarket(time.Now())
+	}
+}
+
+// publishOffers publishes our current offers. Nothing is published if we have no offers and
+// haven't published any, so that takers stay quiet.
+func (g *gossip) publishOffers() error {
+	offers := g.offers()
+	if len(offers) > maxGossipedOffers {
+		offers = offers[:maxGossipedOffers]
+	}
+
+	g.mu.Lock()
+	if len(offers) == 0 && !g.listed {
+		g.mu.Unlock()
+		return nil
+	}
+	g.listed = len(offers) != 0
+	g.mu.Unlock()
+
+	bz, err := (&message.QueryResponse{Offers: offers}).Encode()
+	if err != nil {
+		return err
+	}
+
+	return g.topic.Publish(g.ctx, bz)
+}
+
+func (g *gossip) receive() {
+	for {
+		msg, err := g.sub.Next(g.ctx)
+		if err != nil {
+			return
+		}
+
+		from := msg.GetFrom()
+		if from == g.h.ID() {
+			continue
+		}
+
+		resp, ok := msg.ValidatorData.(*message.QueryResponse)
+		if !ok {
+			continue
+		}
+
+		now := time.Now()
+		g.update(from, resp.Offers, now)
+		g.feed.publish(from, resp.Offers, OfferSourceGossip, now)
+	}
+}
+
+// update replaces the maker's offers with those it just published.
+func (g *gossip) update(from peer.ID, offers []*types.Offer, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(offers) == 0 {
+		delete(g.market, from)
+		return
+	}
+
+	log.Debugf("received %d offers from peer %s", len(offers), from)
+	g.market[from] = &marketEntry{
+		offers:   offers,
+		received: now,
+	}
+}
+
+// pruneMarket forgets the offers of makers we haven't heard from for a while.
+func (g *gossip) pruneMarket(now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for id, entry := range g.market {
+		if now.Sub(entry.received) > gossipOffersTTL {
+			delete(g.market, id)
+		}
+	}
+}
+
+// marketOffers returns the unexpired offers that each maker has published, sorted by peer ID.
+func (g *gossip) marketOffers(now time.Time) []*PeerOffers {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	var market []*PeerOffers
+	for id, entry := range g.market {
+		if now.Sub(entry.received) > gossipOffersTTL || g.reputation.isBanned(id) {
+			continue
+		}
+
+		po := &PeerOffers{
+			Peer:    g.h.Peerstore().PeerInfo(id),
+			Latency: g.h.Peerstore().LatencyEWMA(id),
+		}
+
+		for _, o := range entry.offers {
+			if !o.Expired(now) {
+				po.Offers = append(po.Offers, o)
+			}
+		}
+
+		if len(po.Offers) != 0 {
+			market = append(market, po)
+		}
+	}
+
+	sort.Slice(market, func(i, j int) bool {
+		return market[i].Peer.ID < market[j].Peer.ID
+	})
+	return market
+}
+
+func (g *gossip) stop() {
+	g.sub.Cancel()
+	_ = g.topic.Close()
+}
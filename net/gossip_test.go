@@ -0,0 +1,86 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_MarketOffers(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+	offer := &types.Offer{ID: types.Hash{1}, Provides: types.ProvidesXMR, MaximumAmount: 1}
+	private := &types.Offer{ID: types.Hash{2}, Provides: types.ProvidesXMR, MaximumAmount: 1}
+	ha.handler = &mockHandler{
+		offers: []*types.Offer{offer, private},
+		takers: map[string]*types.TakerFilter{
+			private.GetID().String(): {Allow: []string{hb.h.ID().String()}},
+		},
+	}
+
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	require.NoError(t, hb.h.Connect(hb.ctx, ha.addrInfo()))
+
+	// private offers aren't published
+	require.Eventually(t, func() bool {
+		ha.Advertise()
+		return len(hb.MarketOffers()) == 1
+	}, 30*time.Second, 500*time.Millisecond)
+
+	market := hb.MarketOffers()
+	require.Equal(t, ha.h.ID(), market[0].Peer.ID)
+	require.Len(t, market[0].Offers, 1)
+	require.Equal(t, offer.ID, market[0].Offers[0].ID)
+	require.Equal(t, ha.h.ID().String(), market[0].Offers[0].Maker)
+	require.Empty(t, ha.MarketOffers())
+
+	// once the maker has no offers, they're removed
+	ha.handler.(*mockHandler).offers = []*types.Offer{}
+	ha.Advertise()
+	require.Eventually(t, func() bool {
+		return len(hb.MarketOffers()) == 0
+	}, 30*time.Second, 100*time.Millisecond)
+}
+
+func TestGossip_MarketOffers(t *testing.T) {
+	h := newHost(t, defaultPort)
+	defer func() {
+		_ = h.Stop()
+	}()
+
+	g := h.gossip
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	offer := &types.Offer{ID: types.Hash{1}}
+	peerA, peerB := peer.ID("a"), peer.ID("b")
+
+	g.update(peerA, []*types.Offer{offer, {ID: types.Hash{2}, ExpiresAt: &expired}}, now)
+	g.update(peerB, []*types.Offer{{ID: types.Hash{3}, ExpiresAt: &expired}}, now)
+
+	// expired offers aren't returned, nor makers without any other offers
+	market := g.marketOffers(now)
+	require.Len(t, market, 1)
+	require.Equal(t, peerA, market[0].Peer.ID)
+	require.Equal(t, []*types.Offer{offer}, market[0].Offers)
+
+	// makers we haven't heard from for a while are forgotten
+	later := now.Add(gossipOffersTTL + time.Second)
+	require.Empty(t, g.marketOffers(later))
+	g.pruneMarket(later)
+	require.Empty(t, g.market)
+
+	// an empty update removes the maker's offers
+	g.update(peerA, []*types.Offer{offer}, now)
+	g.update(peerA, nil, now)
+	require.Empty(t, g.market)
+}
@@ -0,0 +1,194 @@
+package net
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+)
+
+// defaultHello returns the capabilities advertised by a host that isn't configured with any: the
+// current message schema, ether, any contract timeout, and snappy compression.
+func defaultHello() *message.Hello {
+	return &message.Hello{
+		SchemaVersion:    message.SchemaVersion,
+		MinSchemaVersion: message.MinSchemaVersion,
+		EthAssets:        []ethcommon.Address{{}},
+		Compression:      []string{message.Snappy},
+		EnvelopeVersion:  message.EnvelopeVersion,
+		Encodings:        []string{message.CBOR},
+		Ping:             true,
+	}
+}
+
+// negotiatedStream is a swap stream, along with the ways of encoding messages that both we and
+// the peer support: envelopes, CBOR payloads within them, and compression, and whether both of
+// us answer pings.
+type negotiatedStream struct {
+	libp2pnetwork.Stream
+	envelope bool
+	cbor     bool
+	compress bool
+	ping     bool
+}
+
+// negotiate returns the swap stream, wrapped so that our messages are encoded in the ways that both
+// we and the peer that sent the given Hello support.
+func (h *host) negotiate(stream libp2pnetwork.Stream, theirs *message.Hello) libp2pnetwork.Stream {
+	ns := &negotiatedStream{
+		Stream:   stream,
+		envelope: h.hello.EnvelopeVersion > 0 && theirs.EnvelopeVersion > 0,
+		cbor:     message.SupportsCBOR(h.hello.Encodings) && message.SupportsCBOR(theirs.Encodings),
+		compress: message.SupportsCompression(h.hello.Compression) && message.SupportsCompression(theirs.Compression),
+		ping:     h.hello.Ping && theirs.Ping,
+	}
+
+	// CBOR payloads are only sent in envelopes, whose header gives the payload's encoding
+	ns.cbor = ns.cbor && ns.envelope
+
+	if !ns.envelope && !ns.compress && !ns.ping {
+		return stream
+	}
+
+	log.Debugf("swap messages to peer %s: envelope=%v cbor=%v compression=%v ping=%v",
+		stream.Conn().RemotePeer(), ns.envelope, ns.cbor, ns.compress, ns.ping)
+	return ns
+}
+
+// encodeMessage encodes the message in an envelope if it's going to a peer that can decode one,
+// with a CBOR payload if the peer can decode that too. Otherwise, it's encoded as its type
+// followed by its JSON payload, which all nodes can decode.
+func encodeMessage(s libp2pnetwork.Stream, msg Message) ([]byte, error) {
+	ns, ok := s.(*negotiatedStream)
+	if ok && ns.cbor {
+		return message.CBOREnvelope(msg)
+	}
+
+	if ok && ns.envelope {
+		return message.Envelope(msg)
+	}
+
+	return msg.Encode()
+}
+
+// sendHello opens the handshake on a swap stream we opened: it sends our Hello, and checks that
+// the Hello the peer replies with is compatible with ours and with the swap's contract timeout.
+// It returns the stream to send the swap's messages on, which encodes them in envelopes and
+// compresses them if both of us support it, and the peer's Hello.
+func (h *host) sendHello(stream libp2pnetwork.Stream, s SwapState) (libp2pnetwork.Stream, *message.Hello, error) {
+	if err := h.writeToStream(stream, h.hello); err != nil {
+		return nil, nil, err
+	}
+
+	_ = stream.SetReadDeadline(time.Now().Add(protocolTimeout))
+	defer func() {
+		_ = stream.SetReadDeadline(time.Time{})
+	}()
+
+	buf := make([]byte, 1<<12)
+	n, err := readStream(stream, buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: peer didn't reply to handshake, it may not support it: %s",
+			errIncompatiblePeer, err)
+	}
+
+	msg, err := message.DecodeMessage(buf[:n])
+	if err != nil {
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return nil, nil, err
+	}
+
+	theirs, ok := msg.(*message.Hello)
+	if !ok {
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return nil, nil, fmt.Errorf("expected Hello message, got %s", msg.Type())
+	}
+
+	if err = checkHello(h.hello, theirs); err != nil {
+		return nil, nil, err
+	}
+
+	if r, ok := s.(TimeoutReporter); ok {
+		if err = checkTimeout(theirs, r.ContractTimeout()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return h.negotiate(stream, theirs), theirs, nil
+}
+
+// replyHello answers the handshake on a swap stream the peer opened, and checks that the peer's
+// Hello is compatible with ours. We reply even if it isn't, so that the peer learns why. Like
+// sendHello, it returns the stream to send the swap's messages on, along with the challenge the
+// peer's proof of work must be over, if we require one.
+func (h *host) replyHello(stream libp2pnetwork.Stream, theirs *message.Hello) (libp2pnetwork.Stream, []byte, error) {
+	ours := h.hello
+	var challenge []byte
+	if h.hello.InitiateDifficulty != 0 {
+		var err error
+		challenge, err = newInitiateChallenge()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		withChallenge := *h.hello
+		withChallenge.InitiateChallenge = challenge
+		ours = &withChallenge
+	}
+
+	if err := h.writeToStream(stream, ours); err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkHello(h.hello, theirs); err != nil {
+		return nil, nil, err
+	}
+
+	return h.negotiate(stream, theirs), challenge, nil
+}
+
+// checkHello checks that we can swap with a peer that sent the given Hello.
+func checkHello(ours, theirs *message.Hello) error {
+	if theirs.SchemaVersion < ours.MinSchemaVersion || ours.SchemaVersion < theirs.MinSchemaVersion {
+		return fmt.Errorf("%w: peer supports message schema versions %d to %d, we support %d to %d",
+			errIncompatiblePeer, theirs.MinSchemaVersion, theirs.SchemaVersion, ours.MinSchemaVersion, ours.SchemaVersion)
+	}
+
+	if !haveCommonAsset(ours.EthAssets, theirs.EthAssets) {
+		return fmt.Errorf("%w: peer supports assets %v, we support %v", errIncompatiblePeer, theirs.EthAssets, ours.EthAssets)
+	}
+
+	if (ours.MaxTimeout != 0 && theirs.MinTimeout > ours.MaxTimeout) ||
+		(theirs.MaxTimeout != 0 && ours.MinTimeout > theirs.MaxTimeout) {
+		return fmt.Errorf("%w: peer accepts contract timeouts of %ds to %ds, we accept %ds to %ds",
+			errIncompatiblePeer, theirs.MinTimeout, theirs.MaxTimeout, ours.MinTimeout, ours.MaxTimeout)
+	}
+
+	return nil
+}
+
+// checkTimeout checks that the peer that sent the Hello accepts the given contract timeout.
+func checkTimeout(theirs *message.Hello, timeout time.Duration) error {
+	secs := uint64(timeout / time.Second)
+	if secs < theirs.MinTimeout || (theirs.MaxTimeout != 0 && secs > theirs.MaxTimeout) {
+		return fmt.Errorf("%w: peer accepts contract timeouts of %ds to %ds, the swap's is %ds",
+			errIncompatiblePeer, theirs.MinTimeout, theirs.MaxTimeout, secs)
+	}
+
+	return nil
+}
+
+func haveCommonAsset(a, b []ethcommon.Address) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,182 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHello(t *testing.T) {
+	ours := defaultHello()
+	ours.MinTimeout, ours.MaxTimeout = 60, 3600
+	require.NoError(t, checkHello(ours, defaultHello()))
+
+	newer := defaultHello()
+	newer.SchemaVersion, newer.MinSchemaVersion = message.SchemaVersion+2, message.SchemaVersion+1
+	require.ErrorIs(t, checkHello(ours, newer), errIncompatiblePeer)
+	require.ErrorIs(t, checkHello(newer, ours), errIncompatiblePeer)
+
+	token := defaultHello()
+	token.EthAssets = []ethcommon.Address{{1}}
+	require.ErrorIs(t, checkHello(ours, token), errIncompatiblePeer)
+
+	slow := defaultHello()
+	slow.MinTimeout = 7200
+	require.ErrorIs(t, checkHello(ours, slow), errIncompatiblePeer)
+	require.ErrorIs(t, checkHello(slow, ours), errIncompatiblePeer)
+	slow.MinTimeout = 3600
+	require.NoError(t, checkHello(ours, slow))
+}
+
+func TestCheckTimeout(t *testing.T) {
+	theirs := defaultHello()
+	require.NoError(t, checkTimeout(theirs, time.Hour))
+
+	theirs.MinTimeout, theirs.MaxTimeout = 60, 3600
+	require.NoError(t, checkTimeout(theirs, time.Minute))
+	require.NoError(t, checkTimeout(theirs, time.Hour))
+	require.ErrorIs(t, checkTimeout(theirs, time.Second*59), errIncompatiblePeer)
+	require.ErrorIs(t, checkTimeout(theirs, time.Hour+time.Second), errIncompatiblePeer)
+}
+
+func TestHost_Initiate_Incompatible(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	hb.hello = defaultHello()
+	hb.hello.MinSchemaVersion = message.SchemaVersion + 1
+	hb.hello.SchemaVersion = message.SchemaVersion + 1
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{}, new(mockSwapState))
+	require.ErrorIs(t, err, errIncompatiblePeer)
+	time.Sleep(time.Millisecond * 500)
+	require.Nil(t, ha.swaps[testID])
+	require.Nil(t, hb.swaps[testID])
+}
+
+func TestEncodeMessage(t *testing.T) {
+	msg := &message.NotifyETHLocked{Address: "0xabcd"}
+	legacy, err := msg.Encode()
+	require.NoError(t, err)
+
+	encMsg, err := encodeMessage(nil, msg)
+	require.NoError(t, err)
+	require.Equal(t, legacy, encMsg)
+
+	encMsg, err = encodeMessage(&negotiatedStream{compress: true}, msg)
+	require.NoError(t, err)
+	require.Equal(t, legacy, encMsg)
+
+	encMsg, err = encodeMessage(&negotiatedStream{envelope: true}, msg)
+	require.NoError(t, err)
+	require.NotEqual(t, legacy, encMsg)
+
+	decoded, err := message.DecodeMessage(encMsg)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	cborMsg, err := encodeMessage(&negotiatedStream{envelope: true, cbor: true}, msg)
+	require.NoError(t, err)
+	require.NotEqual(t, encMsg, cborMsg)
+
+	decoded, err = message.DecodeMessage(cborMsg)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+}
+
+func TestHost_Initiate_Envelope(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{}, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+	require.NotNil(t, hb.swaps[testID])
+	require.True(t, ha.swaps[testID].stream.(*negotiatedStream).envelope)
+	require.True(t, hb.swaps[testID].stream.(*negotiatedStream).envelope)
+	require.True(t, ha.swaps[testID].stream.(*negotiatedStream).cbor)
+	require.True(t, hb.swaps[testID].stream.(*negotiatedStream).cbor)
+}
+
+func TestHost_Initiate_NoEnvelope(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	// a peer that predates envelopes
+	hb := newHost(t, defaultPort+1)
+	hb.hello = defaultHello()
+	hb.hello.EnvelopeVersion = 0
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{}, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+	require.NotNil(t, hb.swaps[testID])
+	require.False(t, ha.swaps[testID].stream.(*negotiatedStream).envelope)
+	require.False(t, hb.swaps[testID].stream.(*negotiatedStream).envelope)
+	require.False(t, ha.swaps[testID].stream.(*negotiatedStream).cbor)
+	require.False(t, hb.swaps[testID].stream.(*negotiatedStream).cbor)
+}
+
+func TestHost_Initiate_NoCBOR(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	hb.hello = defaultHello()
+	hb.hello.Encodings = nil
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{}, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+	require.NotNil(t, hb.swaps[testID])
+	require.True(t, ha.swaps[testID].stream.(*negotiatedStream).envelope)
+	require.False(t, ha.swaps[testID].stream.(*negotiatedStream).cbor)
+	require.False(t, hb.swaps[testID].stream.(*negotiatedStream).cbor)
+}
@@ -0,0 +1,210 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	holePunchID = "/holepunch/0"
+	// how long a hole punch may take, from opening the stream until the direct connection is made
+	holePunchTimeout = time.Second * 30
+	// most addresses a peer may ask us to dial
+	maxHolePunchAddrs = 16
+)
+
+// isRelayed returns whether the connection goes through a circuit relay.
+func isRelayed(conn libp2pnetwork.Conn) bool {
+	_, err := conn.RemoteMultiaddr().ValueForProtocol(ma.P_CIRCUIT)
+	return err == nil
+}
+
+// hasDirectConn returns whether we're connected to the peer other than through a relay.
+func (h *host) hasDirectConn(who peer.ID) bool {
+	for _, conn := range h.h.Network().ConnsToPeer(who) {
+		if !isRelayed(conn) {
+			return true
+		}
+	}
+	return false
+}
+
+// directAddrs returns our advertised addresses that don't go through a relay.
+func (h *host) directAddrs() []string {
+	var addrs []string
+	for _, addr := range h.h.Addrs() {
+		if _, err := addr.ValueForProtocol(ma.P_CIRCUIT); err != nil {
+			addrs = append(addrs, addr.String())
+		}
+	}
+
+	if len(addrs) > maxHolePunchAddrs {
+		addrs = addrs[:maxHolePunchAddrs]
+	}
+	return addrs
+}
+
+// holePunchConnected starts a hole punch when a peer connects to us through a relay, as we're
+// probably not publicly reachable, so that our swap messages don't stay relayed.
+func (h *host) holePunchConnected(_ libp2pnetwork.Network, conn libp2pnetwork.Conn) {
+	if conn.Stat().Direction != libp2pnetwork.DirInbound || !isRelayed(conn) {
+		return
+	}
+
+	go func() {
+		who := conn.RemotePeer()
+		if err := h.holePunch(who); err != nil {
+			log.Debugf("failed to connect directly to peer %s: %s", who, err)
+		}
+	}()
+}
+
+// holePunch connects directly to a peer that's connected to us through a relay, like libp2p's
+// direct connection upgrade through relay (DCUtR). Over the relayed connection, we tell each
+// other our direct addresses, and measure the round trip time. We then tell the peer to dial us,
+// which it does about half a round trip later. In the meantime, we dial it briefly ourselves,
+// so that our NAT or firewall lets its connection in.
+func (h *host) holePunch(who peer.ID) error {
+	if h.hasDirectConn(who) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(h.ctx, holePunchTimeout)
+	defer cancel()
+
+	stream, err := h.h.NewStream(ctx, who, protocol.ID(h.protocolID+holePunchID))
+	if err != nil {
+		return fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	start := time.Now()
+	if err = h.writeToStream(stream, &message.HolePunchConnect{Addrs: h.directAddrs()}); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 4096)
+	addrs, err := h.readHolePunchConnect(stream, buf)
+	if err != nil {
+		return err
+	}
+	rtt := time.Since(start)
+
+	if err = h.writeToStream(stream, &message.HolePunchSync{}); err != nil {
+		return err
+	}
+
+	// our dial is cancelled before the peer's connection arrives, so that it's accepted by our
+	// listener instead of being taken as a simultaneous open, which our transports can't handle
+	h.h.Peerstore().AddAddrs(who, addrs, peerstore.TempAddrTTL)
+	punchCtx, punchCancel := context.WithTimeout(libp2pnetwork.WithForceDirectDial(ctx, "hole-punching"), rtt/2)
+	_, _ = h.h.Network().DialPeer(punchCtx, who)
+	punchCancel()
+
+	// the peer closes the stream once it's dialed us
+	_, _ = readStream(stream, buf)
+	if !h.hasDirectConn(who) {
+		return errHolePunchFailed
+	}
+
+	log.Infof("connected directly to peer %s", who)
+	return nil
+}
+
+// handleHolePunchStream handles a hole punch started by a peer that we're connected to through
+// a relay. We tell it our direct addresses, and dial it once it tells us to.
+func (h *host) handleHolePunchStream(stream libp2pnetwork.Stream) {
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	who := stream.Conn().RemotePeer()
+	if !isRelayed(stream.Conn()) {
+		return
+	}
+
+	buf := make([]byte, 4096)
+	addrs, err := h.readHolePunchConnect(stream, buf)
+	if err != nil {
+		log.Debugf("failed to read HolePunchConnect from peer %s: %s", who, err)
+		return
+	}
+
+	if err = h.writeToStream(stream, &message.HolePunchConnect{Addrs: h.directAddrs()}); err != nil {
+		log.Debugf("failed to send HolePunchConnect to peer %s: %s", who, err)
+		return
+	}
+
+	msg, err := h.readProtocolMessage(stream, buf)
+	if err != nil {
+		return
+	}
+
+	if _, ok := msg.(*message.HolePunchSync); !ok {
+		log.Debugf("peer sent %s on hole punch stream, expected HolePunchSync", msg.Type())
+		h.reputation.recordInvalidMessage(who)
+		return
+	}
+
+	h.h.Peerstore().AddAddrs(who, addrs, peerstore.TempAddrTTL)
+	ctx, cancel := context.WithTimeout(libp2pnetwork.WithForceDirectDial(h.ctx, "hole-punching"), holePunchTimeout)
+	defer cancel()
+
+	if _, err = h.h.Network().DialPeer(ctx, who); err != nil {
+		log.Debugf("failed to connect directly to peer %s: %s", who, err)
+		return
+	}
+
+	log.Infof("connected directly to peer %s", who)
+}
+
+// readHolePunchConnect reads the peer's direct addresses from the stream.
+func (h *host) readHolePunchConnect(stream libp2pnetwork.Stream, buf []byte) ([]ma.Multiaddr, error) {
+	msg, err := h.readProtocolMessage(stream, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	who := stream.Conn().RemotePeer()
+	connect, ok := msg.(*message.HolePunchConnect)
+	if !ok {
+		h.reputation.recordInvalidMessage(who)
+		return nil, fmt.Errorf("expected HolePunchConnect message, got %s", msg.Type())
+	}
+
+	if len(connect.Addrs) > maxHolePunchAddrs {
+		h.reputation.recordInvalidMessage(who)
+		return nil, fmt.Errorf("peer sent %d addresses, more than the maximum of %d",
+			len(connect.Addrs), maxHolePunchAddrs)
+	}
+
+	addrs := make([]ma.Multiaddr, 0, len(connect.Addrs))
+	for _, a := range connect.Addrs {
+		addr, err := ma.NewMultiaddr(a)
+		if err != nil {
+			h.reputation.recordInvalidMessage(who)
+			return nil, err
+		}
+
+		// we only want to dial the peer directly
+		if _, err := addr.ValueForProtocol(ma.P_CIRCUIT); err == nil {
+			continue
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
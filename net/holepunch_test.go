@@ -0,0 +1,74 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_HolePunch(t *testing.T) {
+	relay, err := NewHost(&Config{
+		Ctx:          context.Background(),
+		Environment:  common.Development,
+		ChainID:      common.GanacheChainID,
+		Port:         defaultPort + 2,
+		KeyFile:      path.Join(t.TempDir(), "relay.key"),
+		Handler:      &mockHandler{},
+		RelayService: true,
+	})
+	require.NoError(t, err)
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+
+	for _, h := range []*host{relay, ha, hb} {
+		require.NoError(t, h.Start())
+	}
+	defer func() {
+		for _, h := range []*host{relay, ha, hb} {
+			_ = h.Stop()
+		}
+	}()
+
+	require.NoError(t, hb.h.Connect(hb.ctx, relay.addrInfo()))
+	circuitAddr, err := ma.NewMultiaddr(fmt.Sprintf("%s/p2p/%s/p2p-circuit", relay.h.Addrs()[0], relay.h.ID()))
+	require.NoError(t, err)
+
+	// ha only knows hb's relayed address, so hb starts a hole punch once ha connects to it
+	_, err = ha.Query(peer.AddrInfo{
+		ID:    hb.h.ID(),
+		Addrs: []ma.Multiaddr{circuitAddr},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return ha.hasDirectConn(hb.h.ID()) && hb.hasDirectConn(ha.h.ID())
+	}, time.Second*10, time.Millisecond*100)
+}
+
+func TestHost_HolePunch_Direct(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+
+	for _, h := range []*host{ha, hb} {
+		require.NoError(t, h.Start())
+	}
+	defer func() {
+		for _, h := range []*host{ha, hb} {
+			_ = h.Stop()
+		}
+	}()
+
+	require.NoError(t, ha.h.Connect(ha.ctx, hb.addrInfo()))
+	require.True(t, ha.hasDirectConn(hb.h.ID()))
+
+	// there's nothing to do if we're already connected directly
+	require.NoError(t, ha.holePunch(hb.h.ID()))
+}
@@ -3,18 +3,24 @@ package net
 import (
 	"context"
 	"fmt"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net/message"
 
 	"github.com/libp2p/go-libp2p"
 	libp2phost "github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/metrics"
 	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
 	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
 
 	"github.com/chyeh/pubip"
 	logging "github.com/ipfs/go-log"
@@ -36,6 +42,15 @@ type Host interface {
 
 	Discover(provides types.ProvidesCoin, searchTime time.Duration) ([]peer.AddrInfo, error)
 	Query(who peer.AddrInfo) (*QueryResponse, error)
+	MarketOffers() []*PeerOffers
+	SubscribeOffers() (<-chan *SeenOffer, func())
+	RecordSwapOutcome(who peer.ID, status types.Status)
+	PeerScore(who peer.ID) *PeerScore
+	PeerScores() []*PeerScore
+	PeerStats() []*PeerStats
+	Orderbook() (*Orderbook, error)
+	QueryPrivateOffer(who peer.AddrInfo, token string) (*types.Offer, error)
+	RequestQuote(who peer.AddrInfo, req *QuoteRequest) (*Quote, error)
 	Initiate(who peer.AddrInfo, msg *SendKeysMessage, s common.SwapStateNet) error
 	MessageSender
 }
@@ -43,6 +58,7 @@ type Host interface {
 type swap struct {
 	swapState SwapState
 	stream    libp2pnetwork.Stream
+	session   *session
 }
 
 type host struct {
@@ -53,7 +69,35 @@ type host struct {
 	h         libp2phost.Host
 	bootnodes []peer.AddrInfo
 	discovery *discovery
+	gossip    *gossip
+	// sends the offers we learn about to subscribers
+	offerFeed *offerFeed
 	handler   Handler
+	// finds peers on the local network, if enabled
+	mdnsEnabled bool
+	mdns        mdns.Service
+	// scores peers and bans those that misbehave
+	reputation *reputation
+	// limits the streams each peer may have open with us
+	streamLimiter *streamLimiter
+	// counts the bytes we exchange with each peer
+	bandwidth *metrics.BandwidthCounter
+	// crawls the offers on the network; nil if not enabled
+	orderbook *orderbook
+	// restricts who may take any of our offers
+	takerFilter *types.TakerFilter
+	journal     Journal
+	hello       *message.Hello
+	// how often we ping the counterparties of our swaps
+	pingInterval time.Duration
+	// whether we try to connect directly to peers that connect to us through a relay; we don't
+	// when connecting through a proxy, which would reveal our IP address
+	holePunching bool
+
+	// peers we've been connected to, which are persisted across restarts
+	peerStore    PeerStore
+	savedPeersMu sync.Mutex
+	savedPeers   map[peer.ID]*SavedPeer
 
 	// swap instance info
 	swapMu sync.Mutex
@@ -72,6 +116,51 @@ type Config struct {
 	KeyFile     string
 	Bootnodes   []string
 	Handler     Handler
+	// TakerFilter, if set, restricts who may take any of our offers
+	TakerFilter *types.TakerFilter
+	// Journal, if set, records swaps' messages before they're sent or handled
+	Journal Journal
+	// Hello, if set, is the capabilities we advertise when a swap stream opens; by default, it's
+	// the current message schema, ether, and any contract timeout
+	Hello *message.Hello
+	// ProxyAddress, if set, is the host:port of a SOCKS5 proxy, such as Tor's, that all of our
+	// connections are made through. We then only listen on localhost, and don't look up or
+	// advertise our IP address.
+	ProxyAddress string
+	// OnionAddress, if set, is the address of a Tor onion service that forwards to our libp2p
+	// port, which is advertised instead of our IP addresses. It requires ProxyAddress.
+	OnionAddress string
+	// Relays, if set, are the circuit relays we may be dialed through when we aren't publicly
+	// reachable. By default, the bootnodes are tried as relays.
+	Relays []string
+	// RelayService, if set, makes us relay connections for other peers
+	RelayService bool
+	// MaxStreamsPerPeer and MaxStreamsPerIP limit how many of our protocol's streams a peer, and
+	// all of the peers at an IP address, may have open with us at once; peers that keep going
+	// over the limits are banned for a while. If not set, DefaultMaxStreamsPerPeer and
+	// DefaultMaxStreamsPerIP are used.
+	MaxStreamsPerPeer int
+	MaxStreamsPerIP   int
+	// NoPortMapping, if set, stops us from asking the router to forward our listening ports to us
+	// by UPnP or NAT-PMP
+	NoPortMapping bool
+	// PeerStore, if set, persists the peers we've been connected to, which we reconnect to when
+	// starting, along with the bootnodes
+	PeerStore PeerStore
+	// MDNS, if set, makes us find and connect to other nodes on the local network by mDNS. It
+	// can't be used with ProxyAddress, as it reveals us to the local network.
+	MDNS bool
+	// DHTMode is whether we serve DHT records to other peers; by default, DHTModeAuto
+	DHTMode DHTMode
+	// DHTRefreshInterval is how often the DHT's routing table is refreshed, by looking up peers
+	// and reconnecting to the bootnodes if it's empty; by default, DefaultDHTRefreshInterval
+	DHTRefreshInterval time.Duration
+	// AdvertiseInterval is how often we advertise ourselves and the coins we provide in the DHT;
+	// by default, DefaultAdvertiseInterval
+	AdvertiseInterval time.Duration
+	// OrderbookInterval, if set, makes us keep an orderbook of the offers on the network, by
+	// discovering and querying makers this often
+	OrderbookInterval time.Duration
 }
 
 // NewHost returns a new host
@@ -80,6 +169,10 @@ func NewHost(cfg *Config) (*host, error) {
 		cfg.KeyFile = defaultKeyFile
 	}
 
+	if err := ValidateTakerFilter(cfg.TakerFilter); err != nil {
+		return nil, err
+	}
+
 	key, err := loadKey(cfg.KeyFile)
 	if err != nil {
 		log.Debugf("failed to load libp2p key, generating key %s...", cfg.KeyFile)
@@ -89,80 +182,167 @@ func NewHost(cfg *Config) (*host, error) {
 		}
 	}
 
-	addr, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/0.0.0.0/tcp/%d", cfg.Port))
+	// set libp2p host options
+	var opts []libp2p.Option
+	if cfg.ProxyAddress != "" {
+		opts, err = proxyOptions(cfg)
+	} else {
+		opts, err = directOptions(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// format bootnodes
+	bns, err := stringsToAddrInfos(cfg.Bootnodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format bootnodes: %w", err)
+	}
+
+	relayOpts, err := relayOptions(cfg, bns)
 	if err != nil {
 		return nil, err
 	}
 
-	var externalAddr ma.Multiaddr
+	opts = append(opts, relayOpts...)
+	opts = append(opts, libp2p.Identity(key))
+
+	hello := cfg.Hello
+	if hello == nil {
+		hello = defaultHello()
+	}
+
+	rep := newReputation()
+	opts = append(opts, libp2p.ConnectionGater(rep))
+
+	bandwidth := metrics.NewBandwidthCounter()
+	opts = append(opts, libp2p.BandwidthReporter(bandwidth))
+
+	// create libp2p host instance
+	h, err := libp2p.New(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ourCtx, cancel := context.WithCancel(cfg.Ctx)
+	hst := &host{
+		ctx:           ourCtx,
+		cancel:        cancel,
+		protocolID:    fmt.Sprintf("%s/%s/%d", protocolID, cfg.Environment, cfg.ChainID),
+		h:             h,
+		handler:       cfg.Handler,
+		takerFilter:   cfg.TakerFilter,
+		journal:       cfg.Journal,
+		hello:         hello,
+		pingInterval:  defaultPingInterval,
+		bootnodes:     bns,
+		queryBuf:      make([]byte, 1024*16),
+		swaps:         make(map[types.Hash]*swap),
+		reputation:    rep,
+		holePunching:  cfg.ProxyAddress == "",
+		mdnsEnabled:   cfg.MDNS,
+		peerStore:     cfg.PeerStore,
+		streamLimiter: newStreamLimiter(cfg.MaxStreamsPerPeer, cfg.MaxStreamsPerIP, rep),
+		bandwidth:     bandwidth,
+		savedPeers:    make(map[peer.ID]*SavedPeer),
+		offerFeed:     newOfferFeed(),
+	}
+
+	rep.onBan = func(who peer.ID) {
+		_ = h.Network().ClosePeer(who)
+	}
+	rep.onBanIP = func(ip string) {
+		for _, conn := range h.Network().Conns() {
+			if addr, err := manet.ToIP(conn.RemoteMultiaddr()); err == nil && addr.String() == ip {
+				_ = conn.Close()
+			}
+		}
+	}
+
+	hst.discovery, err = newDiscovery(ourCtx, h, hst.getBootnodes, hst.getOffers, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hst.gossip, err = newGossip(ourCtx, h, hst.protocolID+offersTopicID, hst.getPublicOffers, rep,
+		hst.offerFeed)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.OrderbookInterval != 0 {
+		hst.orderbook = newOrderbook(ourCtx, cfg.OrderbookInterval, hst.Discover, hst.Query, rep)
+	}
+
+	return hst, nil
+}
+
+// directOptions returns the libp2p options for a host that connects to peers directly. It listens
+// on all interfaces, and advertises its public IP address.
+func directOptions(cfg *Config) ([]libp2p.Option, error) {
+	if cfg.OnionAddress != "" {
+		return nil, errOnionRequiresProxy
+	}
+
+	listenAddrs, err := hostAddrs("0.0.0.0", cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	var externalAddrs []ma.Multiaddr
 	ip, err := pubip.Get()
 	if err != nil {
 		log.Warnf("failed to get public IP error: %v", err)
 	} else {
 		log.Debugf("got public IP address %s", ip)
-		externalAddr, err = ma.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", ip, cfg.Port))
+		externalAddrs, err = hostAddrs(ip.String(), cfg.Port)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	// set libp2p host options
+	if !quicSupported {
+		log.Warnf("QUIC isn't supported by this build, only listening on TCP")
+	}
+
 	opts := []libp2p.Option{
-		libp2p.ListenAddrs(addr),
-		libp2p.DisableRelay(),
-		libp2p.Identity(key),
-		libp2p.NATPortMap(),
+		libp2p.ListenAddrs(listenAddrs...),
+		quicTransports(),
 		libp2p.AddrsFactory(func(as []ma.Multiaddr) []ma.Multiaddr {
+			// only advertize non-local addrs (if not in dev mode)
 			if cfg.Environment == common.Development {
 				return as
 			}
 
-			// only advertize non-local addrs (if not in dev mode)
-			addrs := []ma.Multiaddr{}
-			for _, addr := range as {
-				if !privateIPs.AddrBlocked(addr) {
-					addrs = append(addrs, addr)
-				}
-			}
-
-			if externalAddr == nil {
-				return addrs
-			}
-
-			return append(addrs, externalAddr)
+			return advertisedAddrs(as, externalAddrs)
 		}),
 	}
 
-	// format bootnodes
-	bns, err := stringsToAddrInfos(cfg.Bootnodes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to format bootnodes: %w", err)
+	if !cfg.NoPortMapping {
+		opts = append(opts, libp2p.NATPortMap())
 	}
 
-	// create libp2p host instance
-	h, err := libp2p.New(context.Background(), opts...)
-	if err != nil {
-		return nil, err
-	}
+	return opts, nil
+}
 
-	ourCtx, cancel := context.WithCancel(cfg.Ctx)
-	hst := &host{
-		ctx:        ourCtx,
-		cancel:     cancel,
-		protocolID: fmt.Sprintf("%s/%s/%d", protocolID, cfg.Environment, cfg.ChainID),
-		h:          h,
-		handler:    cfg.Handler,
-		bootnodes:  bns,
-		queryBuf:   make([]byte, 1024*5),
-		swaps:      make(map[types.Hash]*swap),
+// hostAddrs returns the addresses to listen on, or advertise, for the given IPv4 address and port:
+// a TCP address and, if supported, a QUIC address on the same UDP port.
+func hostAddrs(ip string, port uint16) ([]ma.Multiaddr, error) {
+	formats := []string{"/ip4/%s/tcp/%d"}
+	if quicSupported {
+		formats = append(formats, "/ip4/%s/udp/%d/quic")
 	}
 
-	hst.discovery, err = newDiscovery(ourCtx, h, hst.getBootnodes)
-	if err != nil {
-		return nil, err
+	addrs := make([]ma.Multiaddr, len(formats))
+	for i, format := range formats {
+		addr, err := ma.NewMultiaddr(fmt.Sprintf(format, ip, port))
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = addr
 	}
 
-	return hst, nil
+	return addrs, nil
 }
 
 func (h *host) SetHandler(handler Handler) {
@@ -174,37 +354,76 @@ func (h *host) Start() error {
 		return errNilHandler
 	}
 
-	h.h.SetStreamHandler(protocol.ID(h.protocolID+queryID), h.handleQueryStream)
-	h.h.SetStreamHandler(protocol.ID(h.protocolID+swapID), h.handleProtocolStream)
+	h.h.SetStreamHandler(protocol.ID(h.protocolID+queryID), h.limitStreams(h.handleQueryStream))
+	h.h.SetStreamHandler(protocol.ID(h.protocolID+swapID), h.limitStreams(h.handleProtocolStream))
+	h.h.SetStreamHandler(protocol.ID(h.protocolID+rfqID), h.limitStreams(h.handleRFQStream))
+	h.h.SetStreamHandler(protocol.ID(h.protocolID+privateOfferID), h.limitStreams(h.handlePrivateOfferStream))
 
 	h.h.Network().SetConnHandler(h.handleConn)
+	if h.holePunching {
+		h.h.SetStreamHandler(protocol.ID(h.protocolID+holePunchID), h.limitStreams(h.handleHolePunchStream))
+		h.h.Network().Notify(&libp2pnetwork.NotifyBundle{
+			ConnectedF: h.holePunchConnected,
+		})
+	}
 	for _, addr := range h.multiaddrs() {
 		log.Info("Started listening: address=", addr)
 	}
+	h.logAddressUpdates()
+
+	if err := h.loadSavedPeers(time.Now()); err != nil {
+		log.Warnf("failed to load saved peers: %s", err)
+	}
 
 	if err := h.bootstrap(); err != nil {
 		return err
 	}
 
 	go h.logPeers()
+	go h.persistPeers()
+	h.gossip.start()
+	if h.orderbook != nil {
+		h.orderbook.start()
+	}
+	if h.mdnsEnabled {
+		h.startMDNS()
+	}
 
 	return h.discovery.start()
 }
 
+// logPeers periodically logs how many peers we're connected to, and warns if our DHT routing
+// tables are still empty after we've had time to bootstrap.
 func (h *host) logPeers() {
-	for {
+	for i := 0; ; i++ {
 		if h.ctx.Err() != nil {
 			return
 		}
 
-		log.Debugf("peer count: %d", len(h.h.Network().Peers()))
+		status := h.discovery.status()
+		log.Debugf("peer count: %d, DHT routing table sizes: LAN=%d WAN=%d",
+			len(h.h.Network().Peers()), status.LANRoutingTableSize, status.WANRoutingTableSize)
+		if i > 0 && status.LANRoutingTableSize == 0 && status.WANRoutingTableSize == 0 {
+			log.Warnf("DHT routing table is empty, so offers can't be discovered or advertised")
+		}
 		time.Sleep(time.Minute)
 	}
 }
 
 // close closes host services and the libp2p host (host services first)
 func (h *host) Stop() error {
+	if err := h.savePeers(time.Now()); err != nil {
+		log.Warnf("failed to save peers: %s", err)
+	}
+
 	h.cancel()
+	h.gossip.stop()
+
+	if h.mdns != nil {
+		if err := h.mdns.Close(); err != nil {
+			log.Warnf("failed to stop mDNS: %s", err)
+		}
+	}
 
 	if err := h.discovery.stop(); err != nil {
 		return err
@@ -219,8 +438,14 @@ func (h *host) Stop() error {
 	return nil
 }
 
+// DHTStatus returns the state of our DHT.
+func (h *host) DHTStatus() *DHTStatus {
+	return h.discovery.status()
+}
+
 func (h *host) Advertise() {
 	h.discovery.advertiseCh <- struct{}{}
+	h.gossip.publish()
 }
 
 func (h *host) Addresses() []string {
@@ -233,8 +458,103 @@ func (h *host) Addresses() []string {
 
 // Discover searches the DHT for peers that advertise that they provide the given coin.
 // It searches for up to `searchTime` duration of time.
+// Banned peers are left out.
 func (h *host) Discover(provides types.ProvidesCoin, searchTime time.Duration) ([]peer.AddrInfo, error) {
-	return h.discovery.discover(provides, searchTime)
+	peers, err := h.discovery.discover(provides, searchTime)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]peer.AddrInfo, 0, len(peers))
+	for _, p := range peers {
+		if !h.reputation.isBanned(p.ID) {
+			allowed = append(allowed, p)
+		}
+	}
+	return allowed, nil
+}
+
+// MarketOffers returns the offers that makers have published on the offers topic, which are kept
+// up to date without querying the makers.
+func (h *host) MarketOffers() []*PeerOffers {
+	return h.gossip.marketOffers(time.Now())
+}
+
+// Orderbook returns the offers of the makers found by the orderbook crawler, which must be enabled.
+func (h *host) Orderbook() (*Orderbook, error) {
+	if h.orderbook == nil {
+		return nil, errOrderbookDisabled
+	}
+
+	return h.orderbook.list(time.Now()), nil
+}
+
+// RecordSwapOutcome updates the peer's reputation score with the outcome of a completed swap with
+// it. Peers whose score falls too low are banned.
+func (h *host) RecordSwapOutcome(who peer.ID, status types.Status) {
+	h.reputation.recordSwapOutcome(who, status)
+}
+
+// PeerScore returns the peer's reputation score.
+func (h *host) PeerScore(who peer.ID) *PeerScore {
+	return h.reputation.score(who)
+}
+
+// PeerScores returns the reputation scores of all the peers we've swapped with or received invalid
+// messages from.
+func (h *host) PeerScores() []*PeerScore {
+	return h.reputation.scores()
+}
+
+// BanPeer bans the peer for the given duration, or until it's unbanned if the duration is 0,
+// disconnecting from it. Banned peers can't connect to us, and we don't connect to them.
+func (h *host) BanPeer(who peer.ID, duration time.Duration) {
+	h.reputation.ban(who, banEnd(duration))
+}
+
+// UnbanPeer lifts the peer's ban.
+func (h *host) UnbanPeer(who peer.ID) error {
+	if !h.reputation.unban(who) {
+		return errPeerNotBanned
+	}
+
+	return nil
+}
+
+// BanIP bans the IP address for the given duration, or until it's unbanned if the duration is 0,
+// closing our connections with it. We don't accept connections from banned IP addresses, or
+// dial them.
+func (h *host) BanIP(ip string, duration time.Duration) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("%w: %s", errInvalidIPAddress, ip)
+	}
+
+	h.reputation.banIP(parsed.String(), banEnd(duration))
+	return nil
+}
+
+// UnbanIP lifts the IP address's ban.
+func (h *host) UnbanIP(ip string) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("%w: %s", errInvalidIPAddress, ip)
+	}
+
+	if !h.reputation.unbanIP(parsed.String()) {
+		return errIPNotBanned
+	}
+
+	return nil
+}
+
+// banEnd returns when a ban of the given duration ends, which is zero if it doesn't.
+func banEnd(duration time.Duration) time.Time {
+	if duration == 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(duration)
 }
 
 // SendSwapMessage sends a message to the peer who we're currently doing a swap with.
@@ -247,17 +567,44 @@ func (h *host) SendSwapMessage(msg Message, id types.Hash) error {
 		return errNoOngoingSwap
 	}
 
-	return h.writeToStream(swap.stream, msg)
+	if err := h.recordSent(id, msg); err != nil {
+		return err
+	}
+
+	return h.writeSwapMessage(swap.stream, swap.session, msg)
 }
 
 func (h *host) getBootnodes() []peer.AddrInfo {
-	addrs := h.bootnodes
+	addrs := append(h.savedPeerAddrs(), h.bootnodes...)
 	for _, p := range h.h.Network().Peers() {
 		addrs = append(addrs, h.h.Peerstore().PeerInfo(p))
 	}
 	return addrs
 }
 
+// getOffers returns the handler's current offers, or none if it isn't set yet. Private offers
+// aren't included, since they're only given to peers that have their token.
+func (h *host) getOffers() []*types.Offer {
+	if h.handler == nil {
+		return nil
+	}
+
+	offers := h.handler.GetOffers()
+	listed := make([]*types.Offer, 0, len(offers))
+	for _, o := range offers {
+		if h.offerToken(o.GetID().String()) == "" {
+			listed = append(listed, o)
+		}
+	}
+	return listed
+}
+
+// getPublicOffers returns our current offers that anyone may take, signed, which are the ones we
+// publish on the offers topic.
+func (h *host) getPublicOffers() []*types.Offer {
+	return h.signOffers(h.offersFor("", h.getOffers()))
+}
+
 // multiaddrs returns the multiaddresses of the host
 func (h *host) multiaddrs() (multiaddrs []ma.Multiaddr) {
 	addrs := h.h.Addrs()
@@ -279,11 +626,12 @@ func (h *host) addrInfo() peer.AddrInfo {
 }
 
 func (h *host) writeToStream(s libp2pnetwork.Stream, msg Message) error {
-	encMsg, err := msg.Encode()
+	encMsg, err := encodeMessage(s, msg)
 	if err != nil {
 		return err
 	}
 
+	encMsg = compressMessage(s, encMsg)
 	msgLen := uint64(len(encMsg))
 	lenBytes := uint64ToLEB128(msgLen)
 	encMsg = append(lenBytes, encMsg...)
@@ -350,18 +698,31 @@ func readStream(stream libp2pnetwork.Stream, buf []byte) (int, error) {
 }
 
 // bootstrap connects the host to the configured bootnodes
+// bootstrap connects to the bootnodes and the peers we were connected to before we last stopped.
+// It fails if there are bootnodes but we couldn't connect to any of these peers.
 func (h *host) bootstrap() error {
-	failed := 0
-	for _, addrInfo := range h.bootnodes {
-		log.Debugf("bootstrapping to peer: peer=%s", addrInfo.ID)
-		err := h.h.Connect(h.ctx, addrInfo)
-		if err != nil {
-			log.Debugf("failed to bootstrap to peer: err=%s", err)
-			failed++
-		}
+	peers := append(h.savedPeerAddrs(), h.bootnodes...)
+
+	var (
+		wg        sync.WaitGroup
+		connected int32
+	)
+	for _, addrInfo := range peers {
+		wg.Add(1)
+		go func(addrInfo peer.AddrInfo) {
+			defer wg.Done()
+			log.Debugf("bootstrapping to peer: peer=%s", addrInfo.ID)
+			err := h.h.Connect(h.ctx, addrInfo)
+			if err != nil {
+				log.Debugf("failed to bootstrap to peer: err=%s", err)
+				return
+			}
+			atomic.AddInt32(&connected, 1)
+		}(addrInfo)
 	}
+	wg.Wait()
 
-	if failed == len(h.bootnodes) && len(h.bootnodes) != 0 {
+	if connected == 0 && len(h.bootnodes) != 0 {
 		return errFailedToBootstrap
 	}
 
@@ -2,6 +2,8 @@ package net
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -24,20 +26,53 @@ var defaultPort uint16 = 5001
 var testID = types.Hash{99}
 
 type mockHandler struct {
-	id types.Hash
+	id     types.Hash
+	offers []*types.Offer
+	takers map[string]*types.TakerFilter
+	tokens map[string]string
+	// key, if set, is used to sign the session keys of the swaps started with the handler
+	key *ecdsa.PrivateKey
 }
 
 func (h *mockHandler) GetOffers() []*types.Offer {
+	if h.offers != nil {
+		return h.offers
+	}
 	return []*types.Offer{}
 }
 
 func (h *mockHandler) HandleInitiateMessage(msg *SendKeysMessage) (s SwapState, resp Message, err error) {
+	if h.key != nil {
+		return &signingSwapState{mockSwapState{h.id}, h.key},
+			&SendKeysMessage{Secp256k1PublicKey: secp256k1PublicKey(h.key)}, nil
+	}
 	if (h.id != types.Hash{}) {
 		return &mockSwapState{h.id}, &SendKeysMessage{}, nil
 	}
 	return &mockSwapState{}, &SendKeysMessage{}, nil
 }
 
+func (h *mockHandler) HandleQuoteRequest(req *QuoteRequest) (*Quote, error) {
+	if req.OfferID != testID.String() {
+		return nil, errors.New("no offer with given ID")
+	}
+
+	return &Quote{
+		ID:             types.Hash{1},
+		OfferID:        req.OfferID,
+		ProvidedAmount: req.ProvidedAmount,
+		ExchangeRate:   0.1,
+	}, nil
+}
+
+func (h *mockHandler) GetTakerFilter(offerID string) *types.TakerFilter {
+	return h.takers[offerID]
+}
+
+func (h *mockHandler) GetOfferToken(offerID string) string {
+	return h.tokens[offerID]
+}
+
 type mockSwapState struct {
 	id types.Hash
 }
@@ -81,3 +116,17 @@ func TestNewHost(t *testing.T) {
 	err = h.Stop()
 	require.NoError(t, err)
 }
+
+func TestHostAddrs(t *testing.T) {
+	addrs, err := hostAddrs("1.2.3.4", 9900)
+	require.NoError(t, err)
+	require.Equal(t, "/ip4/1.2.3.4/tcp/9900", addrs[0].String())
+
+	if !quicSupported {
+		require.Len(t, addrs, 1)
+		return
+	}
+
+	require.Len(t, addrs, 2)
+	require.Equal(t, "/ip4/1.2.3.4/udp/9900/quic", addrs[1].String())
+}
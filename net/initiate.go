@@ -46,7 +46,36 @@ func (h *host) Initiate(who peer.AddrInfo, msg *SendKeysMessage, s common.SwapSt
 		"opened protocol stream, peer=", who.ID,
 	)
 
-	if err := h.writeToStream(stream, msg); err != nil {
+	swapStream, theirs, err := h.sendHello(stream, s)
+	if err != nil {
+		log.Warnf("handshake with peer %s failed: %s", who.ID, err)
+		_ = stream.Close()
+		return err
+	}
+	stream = swapStream
+
+	if err = h.proveInitiateWork(theirs, msg); err != nil {
+		_ = stream.Close()
+		return err
+	}
+
+	sess, err := newSession()
+	if err != nil {
+		_ = stream.Close()
+		return err
+	}
+	sess.requirePeerKey = theirs.Version.HasFeature(common.FeatureSessionKeys)
+	if err = sess.signSessionKey(s, msg); err != nil {
+		_ = stream.Close()
+		return err
+	}
+
+	if err = h.recordSent(id, msg); err != nil {
+		_ = stream.Close()
+		return err
+	}
+
+	if err := h.writeSwapMessage(stream, sess, msg); err != nil {
 		log.Warnf("failed to send initial SendKeysMessage to peer: err=%s", err)
 		return err
 	}
@@ -54,9 +83,11 @@ func (h *host) Initiate(who peer.AddrInfo, msg *SendKeysMessage, s common.SwapSt
 	h.swaps[id] = &swap{
 		swapState: s,
 		stream:    stream,
+		session:   sess,
 	}
 
-	go h.handleProtocolStreamInner(stream, s)
+	recordPeer(s, who.ID)
+	go h.handleProtocolStreamInner(stream, s, sess)
 	return nil
 }
 
@@ -69,28 +100,75 @@ func (h *host) handleProtocolStream(stream libp2pnetwork.Stream) {
 
 	// TODO: don't allocate this twice
 	msgBytes := make([]byte, 1<<17)
-	tot, err := readStream(stream, msgBytes[:])
-	if err != nil {
-		log.Debug("peer closed stream with us, protocol exited")
-		_ = stream.Close()
-		return
-	}
-
-	// decode message based on message type
-	msg, err := message.DecodeMessage(msgBytes[:tot])
+	msg, err := h.readProtocolMessage(stream, msgBytes)
 	if err != nil {
-		log.Debug("failed to decode message from peer, id=", stream.ID(), " protocol=", stream.Protocol(), " err=", err)
 		_ = stream.Close()
 		return
 	}
 
-	log.Debug(
-		"received message from peer, peer=", stream.Conn().RemotePeer(), " type=", msg.Type(),
+	// peers that predate the handshake send their SendKeysMessage straight away
+	var (
+		challenge      []byte
+		requirePeerKey bool
 	)
+	if hello, ok := msg.(*message.Hello); ok {
+		var swapStream libp2pnetwork.Stream
+		swapStream, challenge, err = h.replyHello(stream, hello)
+		if err != nil {
+			log.Infof("handshake with peer %s failed: %s", stream.Conn().RemotePeer(), err)
+			_ = stream.Close()
+			return
+		}
+		stream = swapStream
+		requirePeerKey = hello.Version.HasFeature(common.FeatureSessionKeys)
+
+		msg, err = h.readProtocolMessage(stream, msgBytes)
+		if err != nil {
+			_ = stream.Close()
+			return
+		}
+	}
 
 	im, ok := msg.(*SendKeysMessage)
 	if !ok {
 		log.Warnf("failed to handle protocol message: message was not SendKeysMessage")
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		_ = stream.Close()
+		return
+	}
+
+	// private offers must not be taken by anyone else, so this is checked before the swap starts
+	if !h.mayTake(stream.Conn().RemotePeer(), im.OfferID) {
+		log.Infof("peer %s may not take offer %s, closing stream", stream.Conn().RemotePeer(), im.OfferID)
+		_ = stream.Close()
+		return
+	}
+
+	if !h.validOfferToken(im.OfferID, im.OfferToken) {
+		log.Infof("peer %s doesn't have the token for private offer %s, closing stream",
+			stream.Conn().RemotePeer(), im.OfferID)
+		_ = stream.Close()
+		return
+	}
+
+	// this is checked before the handler commits any of our funds to the swap
+	if !h.checkInitiateWork(challenge, im) {
+		log.Infof("peer %s didn't do the work required to initiate a swap, closing stream", stream.Conn().RemotePeer())
+		_ = stream.Close()
+		return
+	}
+
+	sess, err := newSession()
+	if err != nil {
+		log.Errorf("failed to create swap session: err=%s", err)
+		_ = stream.Close()
+		return
+	}
+	sess.requirePeerKey = requirePeerKey
+
+	if err = sess.setPeerKey(im); err != nil {
+		log.Warnf("failed to handle protocol message: err=%s", err)
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
 		_ = stream.Close()
 		return
 	}
@@ -103,7 +181,26 @@ func (h *host) handleProtocolStream(stream libp2pnetwork.Stream) {
 		return
 	}
 
-	if err := h.writeToStream(stream, resp); err != nil {
+	if keys, ok := resp.(*SendKeysMessage); ok {
+		if err = sess.signSessionKey(s, keys); err != nil {
+			log.Errorf("failed to sign session key: err=%s", err)
+			_ = s.Exit()
+			_ = stream.Close()
+			return
+		}
+	}
+
+	if err = h.recordReceived(s.ID(), im); err == nil {
+		err = h.recordSent(s.ID(), resp)
+	}
+	if err != nil {
+		log.Errorf("failed to record swap messages in journal: err=%s", err)
+		_ = s.Exit()
+		_ = stream.Close()
+		return
+	}
+
+	if err := h.writeSwapMessage(stream, sess, resp); err != nil {
 		log.Warnf("failed to send response to peer: err=%s", err)
 		_ = s.Exit()
 		_ = stream.Close()
@@ -114,15 +211,48 @@ func (h *host) handleProtocolStream(stream libp2pnetwork.Stream) {
 	h.swaps[s.ID()] = &swap{
 		swapState: s,
 		stream:    stream,
+		session:   sess,
 	}
 	h.swapMu.Unlock()
 
-	h.handleProtocolStreamInner(stream, s)
+	recordPeer(s, stream.Conn().RemotePeer())
+	h.handleProtocolStreamInner(stream, s, sess)
+}
+
+// readProtocolMessage reads and decodes the next message on a swap stream that's being opened.
+func (h *host) readProtocolMessage(stream libp2pnetwork.Stream, buf []byte) (Message, error) {
+	tot, err := readStream(stream, buf)
+	if err != nil {
+		log.Debug("peer closed stream with us, protocol exited")
+		return nil, err
+	}
+
+	// decode message based on message type
+	msg, err := message.DecodeMessage(buf[:tot])
+	if err != nil {
+		log.Debug("failed to decode message from peer, id=", stream.ID(), " protocol=", stream.Protocol(), " err=", err)
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return nil, err
+	}
+
+	log.Debug(
+		"received message from peer, peer=", stream.Conn().RemotePeer(), " type=", msg.Type(),
+	)
+	return msg, nil
+}
+
+// recordPeer records the swap's counterparty, if the swap state records it.
+func recordPeer(s SwapState, who peer.ID) {
+	if r, ok := s.(PeerRecorder); ok {
+		r.SetPeerID(who.String())
+	}
 }
 
 // handleProtocolStreamInner is called to handle a protocol stream, in both ingoing and outgoing cases.
-func (h *host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapState) {
+func (h *host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapState, sess *session) {
+	done := make(chan struct{})
 	defer func() {
+		close(done)
 		log.Debugf("closing stream: peer=%s protocol=%s", stream.Conn().RemotePeer(), stream.Protocol())
 		_ = stream.Close()
 
@@ -135,6 +265,52 @@ func (h *host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapStat
 		h.swapMu.Unlock()
 	}()
 
+	live := newLiveness()
+	msgs := make(chan Message)
+	go h.readSwapMessages(stream, sess, live, msgs, done)
+
+	if ns, ok := stream.(*negotiatedStream); ok && ns.ping {
+		go h.keepAlive(stream, s, sess, live, done)
+	}
+
+	for msg := range msgs {
+		if err := h.recordReceived(s.ID(), msg); err != nil {
+			log.Errorf("failed to record swap message in journal: err=%s", err)
+			return
+		}
+
+		resp, complete, err := s.HandleProtocolMessage(msg)
+		if err != nil {
+			log.Warnf("failed to handle protocol message: err=%s", err)
+			return
+		}
+
+		if resp != nil {
+			if err = h.recordSent(s.ID(), resp); err != nil {
+				log.Errorf("failed to record swap message in journal: err=%s", err)
+				return
+			}
+
+			if err := h.writeSwapMessage(stream, sess, resp); err != nil {
+				log.Warnf("failed to send response to peer: err=%s", err)
+				return
+			}
+		}
+
+		if complete {
+			log.Debug("protocol complete!")
+			return
+		}
+	}
+}
+
+// readSwapMessages reads the messages on a swap stream until it's closed, or done is closed. It
+// answers pings itself, and passes the swap's messages to msgs, which it closes when it returns,
+// so that the counterparty's pings are answered while one of its messages is being handled.
+func (h *host) readSwapMessages(stream libp2pnetwork.Stream, sess *session, live *liveness,
+	msgs chan<- Message, done <-chan struct{}) {
+	defer close(msgs)
+
 	msgBytes := make([]byte, 1<<17)
 
 	for {
@@ -148,30 +324,43 @@ func (h *host) handleProtocolStreamInner(stream libp2pnetwork.Stream, s SwapStat
 		msg, err := message.DecodeMessage(msgBytes[:tot])
 		if err != nil {
 			log.Debug("failed to decode message from peer, id=", stream.ID(), " protocol=", stream.Protocol(), " err=", err)
+			h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
 			continue
 		}
 
+		msg, err = sess.open(msg)
+		if err == nil {
+			if keys, ok := msg.(*SendKeysMessage); ok {
+				err = sess.setPeerKey(keys)
+			}
+		}
+		if err != nil {
+			log.Warnf("rejecting message from peer %s: %s", stream.Conn().RemotePeer(), err)
+			h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+			continue
+		}
+
+		live.seen()
 		log.Debug(
 			"received message from peer, peer=", stream.Conn().RemotePeer(), " type=", msg.Type(),
 		)
 
-		resp, done, err := s.HandleProtocolMessage(msg)
-		if err != nil {
-			log.Warnf("failed to handle protocol message: err=%s", err)
-			return
-		}
-
-		if resp == nil {
+		switch m := msg.(type) {
+		case *message.Ping:
+			if err = h.writeSwapMessage(stream, sess, &message.Pong{Nonce: m.Nonce}); err != nil {
+				log.Debugf("failed to answer ping from peer %s: %s", stream.Conn().RemotePeer(), err)
+			}
+			continue
+		case *message.Pong:
+			if rtt, ok := live.ponged(m.Nonce); ok {
+				h.recordLatency(stream.Conn().RemotePeer(), rtt)
+			}
 			continue
 		}
 
-		if err := h.writeToStream(stream, resp); err != nil {
-			log.Warnf("failed to send response to peer: err=%s", err)
-			return
-		}
-
-		if done {
-			log.Debug("protocol complete!")
+		select {
+		case msgs <- msg:
+		case <-done:
 			return
 		}
 	}
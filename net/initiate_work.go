@@ -0,0 +1,101 @@
+package net
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/noot/atomic-swap/net/message"
+)
+
+const (
+	// MaxInitiateDifficulty is the most leading zero bits we'll find a proof of work for to
+	// initiate a swap, which takes a few seconds; makers requiring more can't be taken from
+	MaxInitiateDifficulty = 24
+
+	initiateChallengeSize = 16
+)
+
+// newInitiateChallenge returns a random challenge for a taker's proof of work.
+func newInitiateChallenge() ([]byte, error) {
+	challenge := make([]byte, initiateChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, fmt.Errorf("failed to generate initiate challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+// initiateWorkBits returns the number of leading zero bits of the hash of the challenge and nonce.
+func initiateWorkBits(challenge []byte, nonce uint64) int {
+	var nb [8]byte
+	binary.BigEndian.PutUint64(nb[:], nonce)
+	hash := sha256.Sum256(append(append([]byte{}, challenge...), nb[:]...))
+
+	zeros := 0
+	for _, b := range hash {
+		zeros += bits.LeadingZeros8(b)
+		if b != 0 {
+			break
+		}
+	}
+	return zeros
+}
+
+// solveInitiateWork finds a nonce whose hash with the challenge has at least `difficulty` leading
+// zero bits.
+func solveInitiateWork(ctx context.Context, challenge []byte, difficulty uint8) (uint64, error) {
+	for nonce := uint64(0); ; nonce++ {
+		// checking the context every hash would slow the search down noticeably
+		if nonce%(1<<16) == 0 && ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+
+		if initiateWorkBits(challenge, nonce) >= int(difficulty) {
+			return nonce, nil
+		}
+	}
+}
+
+// proveInitiateWork sets the proof of work on the SendKeysMessage that the maker that sent the
+// Hello requires, if any.
+func (h *host) proveInitiateWork(theirs *message.Hello, msg *SendKeysMessage) error {
+	if theirs.InitiateDifficulty == 0 {
+		return nil
+	}
+
+	if theirs.InitiateDifficulty > MaxInitiateDifficulty {
+		return fmt.Errorf("%w: peer requires %d bits of work to initiate a swap, we do at most %d",
+			errIncompatiblePeer, theirs.InitiateDifficulty, MaxInitiateDifficulty)
+	}
+
+	if len(theirs.InitiateChallenge) == 0 {
+		return fmt.Errorf("%w: peer requires work to initiate a swap, but sent no challenge", errIncompatiblePeer)
+	}
+
+	nonce, err := solveInitiateWork(h.ctx, theirs.InitiateChallenge, theirs.InitiateDifficulty)
+	if err != nil {
+		return err
+	}
+
+	msg.InitiateNonce = nonce
+	return nil
+}
+
+// checkInitiateWork returns whether the SendKeysMessage carries the proof of work over the given
+// challenge that we require, if any. A nil challenge means the peer skipped the handshake, so it
+// can't have done the work.
+func (h *host) checkInitiateWork(challenge []byte, msg *SendKeysMessage) bool {
+	if h.hello.InitiateDifficulty == 0 {
+		return true
+	}
+
+	if challenge == nil {
+		return false
+	}
+
+	return initiateWorkBits(challenge, msg.InitiateNonce) >= int(h.hello.InitiateDifficulty)
+}
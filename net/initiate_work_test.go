@@ -0,0 +1,90 @@
+package net
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveInitiateWork(t *testing.T) {
+	challenge, err := newInitiateChallenge()
+	require.NoError(t, err)
+
+	nonce, err := solveInitiateWork(context.Background(), challenge, 12)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, initiateWorkBits(challenge, nonce), 12)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = solveInitiateWork(ctx, challenge, 64)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHost_CheckInitiateWork(t *testing.T) {
+	h := newHost(t, defaultPort)
+	defer func() {
+		_ = h.Stop()
+	}()
+
+	challenge, err := newInitiateChallenge()
+	require.NoError(t, err)
+
+	// no work is required by default
+	require.True(t, h.checkInitiateWork(nil, &SendKeysMessage{}))
+
+	h.hello = defaultHello()
+	h.hello.InitiateDifficulty = 12
+	msg := &SendKeysMessage{}
+	require.NoError(t, h.proveInitiateWork(&message.Hello{InitiateDifficulty: 12, InitiateChallenge: challenge}, msg))
+	require.True(t, h.checkInitiateWork(challenge, msg))
+
+	// the work is only good for the challenge it was done over
+	other, err := newInitiateChallenge()
+	require.NoError(t, err)
+	msg.InitiateNonce = 0
+	for initiateWorkBits(other, msg.InitiateNonce) >= 12 {
+		msg.InitiateNonce++
+	}
+	require.False(t, h.checkInitiateWork(other, msg))
+
+	// peers that skip the handshake can't have done it
+	require.False(t, h.checkInitiateWork(nil, msg))
+
+	tooHard := &message.Hello{InitiateDifficulty: MaxInitiateDifficulty + 1, InitiateChallenge: challenge}
+	err = h.proveInitiateWork(tooHard, msg)
+	require.ErrorIs(t, err, errIncompatiblePeer)
+	err = h.proveInitiateWork(&message.Hello{InitiateDifficulty: 12}, msg)
+	require.ErrorIs(t, err, errIncompatiblePeer)
+}
+
+func TestHost_Initiate_Work(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	hb.hello = defaultHello()
+	hb.hello.InitiateDifficulty = 12
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	msg := &SendKeysMessage{}
+	err = ha.Initiate(hb.addrInfo(), msg, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+
+	hb.swapMu.Lock()
+	defer hb.swapMu.Unlock()
+	require.NotNil(t, hb.swaps[testID])
+}
@@ -0,0 +1,23 @@
+package net
+
+import (
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// recordSent records the message in the journal, if there is one, before it's sent.
+func (h *host) recordSent(id types.Hash, msg Message) error {
+	if h.journal == nil {
+		return nil
+	}
+
+	return h.journal.MessageSent(id, msg)
+}
+
+// recordReceived records the message in the journal, if there is one, before it's handled.
+func (h *host) recordReceived(id types.Hash, msg Message) error {
+	if h.journal == nil {
+		return nil
+	}
+
+	return h.journal.MessageReceived(id, msg)
+}
@@ -0,0 +1,110 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+)
+
+const (
+	defaultPingInterval = time.Second * 5
+	// defaultLivenessTimeout is how long the counterparty may go without being heard from, if
+	// the swap state doesn't say
+	defaultLivenessTimeout = time.Minute
+)
+
+// liveness records when we last heard from the counterparty on a swap stream, and when we sent our
+// latest ping, to time the round trip.
+type liveness struct {
+	mu        sync.Mutex
+	lastSeen  time.Time
+	pingNonce uint64
+	pingSent  time.Time
+}
+
+func newLiveness() *liveness {
+	return &liveness{lastSeen: time.Now()}
+}
+
+func (l *liveness) seen() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lastSeen = time.Now()
+}
+
+func (l *liveness) since() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return time.Since(l.lastSeen)
+}
+
+func (l *liveness) pinged(nonce uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pingNonce = nonce
+	l.pingSent = time.Now()
+}
+
+// ponged returns the round-trip time of the ping answered by a Pong with the given nonce, if it's
+// our latest ping.
+func (l *liveness) ponged(nonce uint64) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if nonce == 0 || nonce != l.pingNonce {
+		return 0, false
+	}
+
+	l.pingNonce = 0
+	return time.Since(l.pingSent), true
+}
+
+// livenessTimeout returns how long the swap's counterparty may go without being heard from at the
+// swap's current stage.
+func livenessTimeout(s SwapState) time.Duration {
+	if r, ok := s.(LivenessReporter); ok {
+		if timeout := r.LivenessTimeout(); timeout != 0 {
+			return timeout
+		}
+	}
+
+	return defaultLivenessTimeout
+}
+
+// keepAlive pings the swap's counterparty until done is closed, once the session keys have been
+// exchanged. If the counterparty isn't heard from within the liveness timeout of the swap's
+// current stage, the stream is reset, which exits the swap, so that it's recovered with as much
+// time as possible before t0 and t1, rather than when its next message is due.
+func (h *host) keepAlive(stream libp2pnetwork.Stream, s SwapState, sess *session, live *liveness,
+	done <-chan struct{}) {
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	var nonce uint64
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		if !sess.established() {
+			continue
+		}
+
+		if since, timeout := live.since(), livenessTimeout(s); since > timeout {
+			log.Warnf("swap %s counterparty %s hasn't been heard from for %s, closing stream",
+				s.ID(), stream.Conn().RemotePeer(), since.Round(time.Second))
+			_ = stream.Reset()
+			return
+		}
+
+		nonce++
+		live.pinged(nonce)
+		if err := h.writeSwapMessage(stream, sess, &message.Ping{Nonce: nonce}); err != nil {
+			log.Debugf("failed to ping swap %s counterparty: %s", s.ID(), err)
+		}
+	}
+}
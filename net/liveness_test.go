@@ -0,0 +1,123 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLivenessSwapState signs its session key, as the pings that keep a swap open only start once
+// the session is established.
+type mockLivenessSwapState struct {
+	signingSwapState
+	timeout time.Duration
+}
+
+func (s *mockLivenessSwapState) LivenessTimeout() time.Duration {
+	return s.timeout
+}
+
+func TestLivenessTimeout(t *testing.T) {
+	require.Equal(t, defaultLivenessTimeout, livenessTimeout(new(mockSwapState)))
+	require.Equal(t, defaultLivenessTimeout, livenessTimeout(new(mockLivenessSwapState)))
+	require.Equal(t, time.Second, livenessTimeout(&mockLivenessSwapState{timeout: time.Second}))
+}
+
+func newLivenessHosts(t *testing.T) (*host, *host) {
+	ha := newHost(t, defaultPort)
+	ha.pingInterval = time.Millisecond * 50
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	hb.pingInterval = time.Millisecond * 50
+	err = hb.Start()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	})
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	makerKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	hb.handler.(*mockHandler).key = makerKey
+
+	takerKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	s := &mockLivenessSwapState{
+		signingSwapState: signingSwapState{key: takerKey},
+		timeout:          time.Millisecond * 300,
+	}
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{Secp256k1PublicKey: secp256k1PublicKey(takerKey)}, s)
+	require.NoError(t, err)
+
+	// pings only start once both sides have each other's session keys
+	require.Eventually(t, func() bool {
+		return sessionEstablished(ha) && sessionEstablished(hb)
+	}, time.Second*10, time.Millisecond*50)
+	return ha, hb
+}
+
+func sessionEstablished(h *host) bool {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+	return h.swaps[testID] != nil && h.swaps[testID].session.established()
+}
+
+func TestHost_Liveness(t *testing.T) {
+	ha, hb := newLivenessHosts(t)
+
+	ha.swapMu.Lock()
+	require.True(t, ha.swaps[testID].stream.(*negotiatedStream).ping)
+	ha.swapMu.Unlock()
+
+	// the counterparty answers our pings
+	require.Eventually(t, func() bool {
+		return ha.h.Peerstore().LatencyEWMA(hb.h.ID()) != 0
+	}, time.Second*5, time.Millisecond*50)
+
+	// which keeps the swap open for longer than the liveness timeout, while no swap messages are
+	// sent
+	require.Never(t, func() bool {
+		return !sessionEstablished(ha) || !sessionEstablished(hb)
+	}, time.Second, time.Millisecond*50)
+}
+
+func TestHost_Liveness_Timeout(t *testing.T) {
+	ha, hb := newLivenessHosts(t)
+
+	// the counterparty hangs, and stops answering pings
+	hb.swapMu.Lock()
+	sess := hb.swaps[testID].session
+	hb.swapMu.Unlock()
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		ha.swapMu.Lock()
+		defer ha.swapMu.Unlock()
+		return ha.swaps[testID] == nil
+	}, time.Second*5, time.Millisecond*50)
+}
+
+func TestLiveness_Ponged(t *testing.T) {
+	live := newLiveness()
+	_, ok := live.ponged(1)
+	require.False(t, ok)
+
+	live.pinged(1)
+	live.pinged(2)
+	_, ok = live.ponged(1)
+	require.False(t, ok)
+	_, ok = live.ponged(2)
+	require.True(t, ok)
+
+	// each ping is only timed once
+	_, ok = live.ponged(2)
+	require.False(t, ok)
+}
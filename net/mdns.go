@@ -0,0 +1,41 @@
+package net
+
+import (
+	"context"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p/p2p/discovery/mdns"
+)
+
+const (
+	// mdnsServiceName is the DNS-SD service we advertise and browse for on the local network
+	mdnsServiceName = "_atomic-swap._udp"
+	// how long we try to connect to a peer found by mDNS
+	mdnsConnectTimeout = time.Second * 10
+)
+
+// startMDNS advertises us on the local network by mDNS, and connects to the other nodes that do,
+// so that nodes on the same network find each other without bootnodes or the DHT.
+func (h *host) startMDNS() {
+	h.mdns = mdns.NewMdnsService(h.h, mdnsServiceName)
+	h.mdns.RegisterNotifee(h)
+}
+
+// HandlePeerFound connects to a peer found on the local network by mDNS.
+func (h *host) HandlePeerFound(p peer.AddrInfo) {
+	if p.ID == h.h.ID() || h.h.Network().Connectedness(p.ID) == libp2pnetwork.Connected {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(h.ctx, mdnsConnectTimeout)
+	defer cancel()
+
+	if err := h.h.Connect(ctx, p); err != nil {
+		log.Debugf("failed to connect to peer %s found by mDNS: %s", p.ID, err)
+		return
+	}
+
+	log.Infof("connected to peer %s found on the local network", p.ID)
+}
@@ -0,0 +1,78 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/stretchr/testify/require"
+)
+
+func newMDNSHost(t *testing.T, port uint16) *host {
+	h, err := NewHost(&Config{
+		Ctx:         context.Background(),
+		Environment: common.Development,
+		ChainID:     common.GanacheChainID,
+		Port:        port,
+		KeyFile:     path.Join(t.TempDir(), fmt.Sprintf("node-%d.key", port)),
+		Handler:     &mockHandler{},
+		MDNS:        true,
+	})
+	require.NoError(t, err)
+	return h
+}
+
+func TestHost_MDNS(t *testing.T) {
+	ha := newMDNSHost(t, defaultPort)
+	hb := newMDNSHost(t, defaultPort+1)
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	// the hosts find each other without bootnodes
+	require.Eventually(t, func() bool {
+		return ha.h.Network().Connectedness(hb.h.ID()) == libp2pnetwork.Connected
+	}, time.Second*30, time.Millisecond*100)
+}
+
+func TestNewHost_MDNSWithProxy(t *testing.T) {
+	_, err := NewHost(&Config{
+		Ctx:          context.Background(),
+		Environment:  common.Development,
+		ChainID:      common.GanacheChainID,
+		Port:         defaultPort,
+		KeyFile:      path.Join(t.TempDir(), "node.key"),
+		Handler:      &mockHandler{},
+		ProxyAddress: "127.0.0.1:9050",
+		MDNS:         true,
+	})
+	require.ErrorIs(t, err, errMDNSRequiresDirect)
+}
+
+func TestHost_HandlePeerFound(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	// we don't connect to ourselves
+	ha.HandlePeerFound(ha.addrInfo())
+	require.Empty(t, ha.h.Network().Peers())
+
+	ha.HandlePeerFound(hb.addrInfo())
+	require.Equal(t, libp2pnetwork.Connected, ha.h.Network().Connectedness(hb.h.ID()))
+}
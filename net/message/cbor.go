@@ -0,0 +1,56 @@
+package message
+
+import (
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR is the name of the CBOR payload encoding, as advertised in Hello.Encodings.
+const CBOR = "cbor"
+
+// payload encodings, as given in an envelope's header
+const (
+	encodingJSON byte = iota
+	encodingCBOR
+)
+
+var (
+	// cborEncMode encodes with the core deterministic encoding of RFC 8949, so that a message has
+	// exactly one encoding. big.Ints are encoded as integers, or bignums if they don't fit in one.
+	cborEncMode = mustEncMode(func() cbor.EncOptions {
+		opts := cbor.CoreDetEncOptions()
+		opts.Time = cbor.TimeRFC3339Nano
+		return opts
+	}())
+
+	// cborDecMode rejects duplicate map keys, which would make a message ambiguous, and, as with
+	// JSON, ignores fields that we don't know of.
+	cborDecMode = mustDecMode(cbor.DecOptions{
+		DupMapKey: cbor.DupMapKeyEnforcedAPF,
+	})
+)
+
+func mustEncMode(opts cbor.EncOptions) cbor.EncMode {
+	em, err := opts.EncMode()
+	if err != nil {
+		panic(err)
+	}
+	return em
+}
+
+func mustDecMode(opts cbor.DecOptions) cbor.DecMode {
+	dm, err := opts.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return dm
+}
+
+// SupportsCBOR returns whether the given payload encodings include CBOR.
+func SupportsCBOR(encodings []string) bool {
+	for _, e := range encodings {
+		if e == CBOR {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+// Snappy is the name of the snappy compression algorithm, as advertised in Hello.Compression.
+const Snappy = "snappy"
+
+// maxDecompressedSize is the largest message we decompress, so that a peer can't make us allocate
+// too much memory with a small message.
+const maxDecompressedSize = 1 << 20
+
+var errNestedCompression = errors.New("compressed message contains a compressed message")
+
+// Compress compresses an encoded message with snappy, and returns it as a Compressed message.
+func Compress(encoded []byte) []byte {
+	return append([]byte{byte(CompressedType)}, snappy.Encode(nil, encoded)...)
+}
+
+// decompress returns the encoded message within a Compressed message, without its type byte.
+func decompress(b []byte) ([]byte, error) {
+	n, err := snappy.DecodedLen(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed message would be %d bytes, more than the maximum of %d",
+			n, maxDecompressedSize)
+	}
+
+	encoded, err := snappy.Decode(nil, b)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encoded) > 0 && Type(encoded[0]) == CompressedType {
+		return nil, errNestedCompression
+	}
+
+	return encoded, nil
+}
+
+// SupportsCompression returns whether the given compression algorithms include snappy, the only
+// one we support.
+func SupportsCompression(algorithms []string) bool {
+	for _, a := range algorithms {
+		if a == Snappy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+)
+
+// envelopeMarker is the first byte of a message in an envelope. It isn't a message type, so that
+// messages in envelopes can be told apart from messages sent by nodes that predate envelopes,
+// which start with their type.
+const envelopeMarker = 0xff
+
+// EnvelopeVersion is the newest envelope version we can decode. It's increased when the envelope
+// changes in a way that older nodes can't decode; fields added to the end of the header don't
+// need a new version, as older nodes skip them.
+const EnvelopeVersion = 1
+
+var (
+	errInvalidEnvelope      = errors.New("invalid message envelope")
+	errCompressedInEnvelope = errors.New("message envelope contains a compressed message")
+	errUnsupportedEnvelope  = errors.New("unsupported message envelope version")
+	errUnknownEncoding      = errors.New("message envelope has an unknown payload encoding")
+)
+
+// Envelope encodes the message in an envelope, which is the envelope marker followed by the
+// envelope's version, the length of its header, the header, and the message's payload. The
+// header is the message's type, optionally followed by the payload's encoding, which is JSON if
+// it's omitted; later versions may append optional fields to it.
+func Envelope(msg Message) ([]byte, error) {
+	encoded, err := msg.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return envelope([]byte{byte(msg.Type())}, encoded[1:]), nil
+}
+
+// CBOREnvelope encodes the message in an envelope, like Envelope, but with its payload encoded
+// with deterministic CBOR rather than JSON, which is smaller, and encodes each message one way.
+func CBOREnvelope(msg Message) ([]byte, error) {
+	payload, err := cborEncMode.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return envelope([]byte{byte(msg.Type()), encodingCBOR}, payload), nil
+}
+
+func envelope(header, payload []byte) []byte {
+	b := make([]byte, 0, 3+len(header)+len(payload))
+	b = append(b, envelopeMarker, EnvelopeVersion, byte(len(header)))
+	b = append(b, header...)
+	return append(b, payload...)
+}
+
+// openEnvelope returns the type, payload encoding and payload of the message in an envelope,
+// without its marker. Header fields that we don't know of are skipped.
+func openEnvelope(b []byte) (Type, byte, []byte, error) {
+	if len(b) < 2 {
+		return 0, 0, nil, errInvalidEnvelope
+	}
+
+	version, headerLen := b[0], int(b[1])
+	if version == 0 || version > EnvelopeVersion {
+		return 0, 0, nil, fmt.Errorf("%w: %d, we support up to %d", errUnsupportedEnvelope, version, EnvelopeVersion)
+	}
+
+	if headerLen == 0 || len(b) < 2+headerLen {
+		return 0, 0, nil, errInvalidEnvelope
+	}
+
+	// compression is applied to envelopes, not within them, so that decompression can't recurse
+	header := b[2 : 2+headerLen]
+	t := Type(header[0])
+	if t == CompressedType {
+		return 0, 0, nil, errCompressedInEnvelope
+	}
+
+	encoding := encodingJSON
+	if len(header) > 1 {
+		encoding = header[1]
+	}
+
+	if encoding != encodingJSON && encoding != encodingCBOR {
+		return 0, 0, nil, fmt.Errorf("%w: %d", errUnknownEncoding, encoding)
+	}
+
+	return t, encoding, b[2+headerLen:], nil
+}
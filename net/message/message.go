@@ -1,14 +1,17 @@
 package message
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/noot/atomic-swap/common/types"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
 // Type represents the type of a network message
@@ -23,8 +26,26 @@ const (
 	NotifyClaimedType
 	NotifyRefundType
 	NilType
+	NotifyAbortType
+	QuoteRequestType
+	QuoteType
+	HelloType
+	HolePunchConnectType
+	HolePunchSyncType
+	CompressedType
+	SignedType
+	PingType
+	PongType
+	PrivateOfferRequestType
 )
 
+// SchemaVersion is the version of the swap protocol's message schema. It's increased when a
+// change is made to the messages that older nodes can't handle.
+const SchemaVersion = 1
+
+// MinSchemaVersion is the oldest message schema version we can swap with.
+const MinSchemaVersion = 1
+
 func (t Type) String() string {
 	switch t {
 	case QueryResponseType:
@@ -41,6 +62,28 @@ func (t Type) String() string {
 		return "NotifyClaimed"
 	case NotifyRefundType:
 		return "NotifyRefund"
+	case NotifyAbortType:
+		return "NotifyAbort"
+	case QuoteRequestType:
+		return "QuoteRequest"
+	case QuoteType:
+		return "Quote"
+	case HelloType:
+		return "Hello"
+	case HolePunchConnectType:
+		return "HolePunchConnect"
+	case HolePunchSyncType:
+		return "HolePunchSync"
+	case CompressedType:
+		return "Compressed"
+	case SignedType:
+		return "Signed"
+	case PingType:
+		return "Ping"
+	case PongType:
+		return "Pong"
+	case PrivateOfferRequestType:
+		return "PrivateOfferRequest"
 	default:
 		return "unknown"
 	}
@@ -53,52 +96,138 @@ type Message interface {
 	Type() Type
 }
 
-// DecodeMessage decodes the given bytes into a Message
+// DecodeMessage decodes the given bytes into a Message. The bytes are either a message in an
+// envelope, or the message's type followed by its payload, as sent by nodes that predate envelopes.
 func DecodeMessage(b []byte) (Message, error) {
 	if len(b) == 0 {
 		return nil, errors.New("invalid message bytes")
 	}
 
-	switch Type(b[0]) {
+	if b[0] == envelopeMarker {
+		t, encoding, payload, err := openEnvelope(b[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		if encoding == encodingCBOR {
+			return decodePayload(t, payload, cborDecMode.Unmarshal)
+		}
+		return decodePayload(t, payload, json.Unmarshal)
+	}
+
+	return decodePayload(Type(b[0]), b[1:], json.Unmarshal)
+}
+
+// decodePayload decodes the payload of a message of the given type with the given unmarshal
+// function. Fields the payload has that the message doesn't are ignored, so that newer nodes can
+// add optional fields to messages.
+func decodePayload(t Type, payload []byte, unmarshal func([]byte, interface{}) error) (Message, error) {
+	switch t {
 	case QueryResponseType:
 		var m *QueryResponse
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
 	case SendKeysType:
 		var m *SendKeysMessage
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
 	case NotifyETHLockedType:
 		var m *NotifyETHLocked
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
 	case NotifyXMRLockType:
 		var m *NotifyXMRLock
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
 	case NotifyReadyType:
 		var m *NotifyReady
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
 	case NotifyClaimedType:
 		var m *NotifyClaimed
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
 	case NotifyRefundType:
 		var m *NotifyRefund
-		if err := json.Unmarshal(b[1:], &m); err != nil {
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case NotifyAbortType:
+		var m *NotifyAbort
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case QuoteRequestType:
+		var m *QuoteRequest
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case QuoteType:
+		var m *Quote
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case HelloType:
+		var m *Hello
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case HolePunchConnectType:
+		var m *HolePunchConnect
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case HolePunchSyncType:
+		var m *HolePunchSync
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case CompressedType:
+		encoded, err := decompress(payload)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeMessage(encoded)
+	case SignedType:
+		var m *Signed
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case PingType:
+		var m *Ping
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case PongType:
+		var m *Pong
+		if err := unmarshal(payload, &m); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case PrivateOfferRequestType:
+		var m *PrivateOfferRequest
+		if err := unmarshal(payload, &m); err != nil {
 			return nil, err
 		}
 		return m, nil
@@ -119,6 +248,53 @@ func (m *QueryResponse) String() string {
 	)
 }
 
+// UnmarshalJSON decodes the response's offers one at a time, skipping any that can't be decoded, so
+// that an offer made with an incompatible schema by a newer node doesn't hide the maker's other
+// offers. Offers made by older nodes decode with version 0 and none of the newer terms.
+func (m *QueryResponse) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Offers []json.RawMessage
+	}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	m.Offers = make([]*types.Offer, 0, len(raw.Offers))
+	for _, bz := range raw.Offers {
+		var o *types.Offer
+		if err := json.Unmarshal(bz, &o); err != nil || o == nil {
+			continue
+		}
+
+		m.Offers = append(m.Offers, o)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the response's offers with their IDs as arrays of 32 bytes, as nodes that
+// predate hex-encoded hashes expect; newer nodes decode either.
+func (m *QueryResponse) MarshalJSON() ([]byte, error) {
+	type legacyIDOffer struct {
+		*types.Offer
+		ID [32]byte
+	}
+
+	offers := make([]*legacyIDOffer, 0, len(m.Offers))
+	for _, o := range m.Offers {
+		if o == nil {
+			continue
+		}
+
+		offers = append(offers, &legacyIDOffer{Offer: o, ID: o.ID})
+	}
+
+	return json.Marshal(struct {
+		Offers []*legacyIDOffer
+	}{offers})
+}
+
 // Encode ...
 func (m *QueryResponse) Encode() ([]byte, error) {
 	b, err := json.Marshal(m)
@@ -134,6 +310,187 @@ func (m *QueryResponse) Type() Type {
 	return QueryResponseType
 }
 
+// QuoteRequest is sent by a taker to ask a maker for a firm quote on taking one of its offers.
+type QuoteRequest struct {
+	OfferID        string
+	ProvidedAmount float64
+}
+
+// String ...
+func (m *QuoteRequest) String() string {
+	return fmt.Sprintf("QuoteRequest OfferID=%s ProvidedAmount=%v",
+		m.OfferID,
+		m.ProvidedAmount,
+	)
+}
+
+// Encode ...
+func (m *QuoteRequest) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(QuoteRequestType)}, b...), nil
+}
+
+// Type ...
+func (m *QuoteRequest) Type() Type {
+	return QuoteRequestType
+}
+
+// Quote is a maker's response to a QuoteRequest. The maker honours its exchange rate for a swap
+// initiated with the quote's ID and the same offer and amount until ExpiresAt.
+type Quote struct {
+	ID             types.Hash
+	OfferID        string
+	ProvidedAmount float64
+	ExchangeRate   types.ExchangeRate
+	ExpiresAt      time.Time
+}
+
+// String ...
+func (m *Quote) String() string {
+	return fmt.Sprintf("Quote ID=%s OfferID=%s ProvidedAmount=%v ExchangeRate=%v ExpiresAt=%s",
+		m.ID,
+		m.OfferID,
+		m.ProvidedAmount,
+		m.ExchangeRate,
+		m.ExpiresAt,
+	)
+}
+
+// Encode ...
+func (m *Quote) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(QuoteType)}, b...), nil
+}
+
+// Type ...
+func (m *Quote) Type() Type {
+	return QuoteType
+}
+
+// Hello is exchanged when a swap stream opens, before any other message, so that peers that
+// can't swap with each other find out before any funds are locked.
+type Hello struct {
+	// SchemaVersion and MinSchemaVersion are the newest and oldest message schema versions the
+	// sender supports
+	SchemaVersion    uint64
+	MinSchemaVersion uint64
+	// EthAssets are the assets the sender can swap for XMR; the zero address is ether
+	EthAssets []ethcommon.Address
+	// MinTimeout and MaxTimeout are the range of contract timeouts, in seconds, that the sender
+	// accepts; a MaxTimeout of 0 means there's no maximum
+	MinTimeout uint64
+	MaxTimeout uint64
+	Version    *types.VersionInfo
+	// Compression are the compression algorithms the sender can decompress messages with; once
+	// both peers have sent a Hello, either may compress its messages with an algorithm both support
+	Compression []string `json:",omitempty"`
+	// EnvelopeVersion is the newest message envelope version the sender can decode; 0 means it
+	// only decodes messages that aren't in an envelope
+	EnvelopeVersion uint64 `json:",omitempty"`
+	// Encodings are the payload encodings the sender can decode in envelopes besides JSON; once
+	// both peers have sent a Hello, either may encode its messages with one both support
+	Encodings []string `json:",omitempty"`
+	// Ping is whether the sender answers Pings on the swap stream; if both peers do, each pings
+	// the other to find out quickly if it's gone
+	Ping bool `json:",omitempty"`
+	// InitiateDifficulty is how many leading zero bits the maker requires of the hash of
+	// InitiateChallenge and the taker's InitiateNonce before it handles a SendKeysMessage
+	// initiating a swap, so that starting swaps costs takers some work; 0 means none is required
+	InitiateDifficulty uint8 `json:",omitempty"`
+	// InitiateChallenge is a random challenge the maker sends with its Hello, fresh for each swap
+	// stream, so that proofs of work can't be reused
+	InitiateChallenge []byte `json:",omitempty"`
+}
+
+// String ...
+func (m *Hello) String() string {
+	return fmt.Sprintf("Hello SchemaVersion=%d MinSchemaVersion=%d EthAssets=%v MinTimeout=%d MaxTimeout=%d Version=%s Compression=%v EnvelopeVersion=%d Encodings=%v Ping=%v InitiateDifficulty=%d", //nolint:lll
+		m.SchemaVersion,
+		m.MinSchemaVersion,
+		m.EthAssets,
+		m.MinTimeout,
+		m.MaxTimeout,
+		m.Version,
+		m.Compression,
+		m.EnvelopeVersion,
+		m.Encodings,
+		m.Ping,
+		m.InitiateDifficulty,
+	)
+}
+
+// Encode ...
+func (m *Hello) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(HelloType)}, b...), nil
+}
+
+// Type ...
+func (m *Hello) Type() Type {
+	return HelloType
+}
+
+// HolePunchConnect is exchanged over a relayed connection by peers that want to connect to each
+// other directly, to tell each other the addresses to dial.
+type HolePunchConnect struct {
+	Addrs []string
+}
+
+// String ...
+func (m *HolePunchConnect) String() string {
+	return fmt.Sprintf("HolePunchConnect Addrs=%v", m.Addrs)
+}
+
+// Encode ...
+func (m *HolePunchConnect) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(HolePunchConnectType)}, b...), nil
+}
+
+// Type ...
+func (m *HolePunchConnect) Type() Type {
+	return HolePunchConnectType
+}
+
+// HolePunchSync is sent by the peer that started a hole punch to tell the other peer to dial it.
+type HolePunchSync struct{}
+
+// String ...
+func (m *HolePunchSync) String() string {
+	return "HolePunchSync"
+}
+
+// Encode ...
+func (m *HolePunchSync) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(HolePunchSyncType)}, b...), nil
+}
+
+// Type ...
+func (m *HolePunchSync) Type() Type {
+	return HolePunchSyncType
+}
+
 // The below messages are swap protocol messages, exchanged after the swap has been agreed
 // upon by both sides.
 
@@ -141,25 +498,47 @@ func (m *QueryResponse) Type() Type {
 type SendKeysMessage struct {
 	OfferID            string
 	ProvidedAmount     float64
+	SpeedTier          string
 	PublicSpendKey     string
 	PublicViewKey      string
 	PrivateViewKey     string
 	DLEqProof          string
 	Secp256k1PublicKey string
 	EthAddress         string
+	// Version is the sender's version info; it's nil if the sender predates the version handshake
+	Version *types.VersionInfo
+	// RemainderOfferID is set by the maker when the offer was only partially taken; it's the ID of
+	// the offer listing what's left of it
+	RemainderOfferID string
+	// QuoteID is set by the taker when taking the offer at a rate the maker quoted
+	QuoteID string
+	// SessionKey is the hex-encoded public key that the sender signs the swap's later messages
+	// with; it's empty if the sender predates session keys
+	SessionKey string `json:",omitempty"`
+	// SessionKeySignature is the hex-encoded signature of SessionKey's SessionKeyDigest by the
+	// key in Secp256k1PublicKey, which the DLEq proof binds to the sender's swap keys
+	SessionKeySignature string `json:",omitempty"`
+	// OfferToken is set by the taker when taking a private offer; it's the token the maker gave
+	// out for the offer
+	OfferToken string `json:",omitempty"`
+	// InitiateNonce is the taker's proof of work over the maker's InitiateChallenge, if the maker's
+	// Hello requires one; see Hello
+	InitiateNonce uint64 `json:",omitempty"`
 }
 
 // String ...
 func (m *SendKeysMessage) String() string {
-	return fmt.Sprintf("SendKeysMessage OfferID=%s ProvidedAmount=%v PublicSpendKey=%s PublicViewKey=%s PrivateViewKey=%s DLEqProof=%s Secp256k1PublicKey=%s EthAddress=%s", //nolint:lll
+	return fmt.Sprintf("SendKeysMessage OfferID=%s ProvidedAmount=%v SpeedTier=%s PublicSpendKey=%s PublicViewKey=%s PrivateViewKey=%s DLEqProof=%s Secp256k1PublicKey=%s EthAddress=%s Version=%s", //nolint:lll
 		m.OfferID,
 		m.ProvidedAmount,
+		m.SpeedTier,
 		m.PublicSpendKey,
 		m.PublicViewKey,
 		m.PrivateViewKey,
 		m.DLEqProof,
 		m.Secp256k1PublicKey,
 		m.EthAddress,
+		m.Version,
 	)
 }
 
@@ -225,13 +604,24 @@ func (m *NotifyETHLocked) Type() Type {
 }
 
 // NotifyXMRLock is sent by XMRMaker to XMRTaker after locking his XMR.
+// TxHash and TxKey prove the lock transaction's output to Address; they're empty if the
+// sender doesn't support FeatureXMRLockProof.
 type NotifyXMRLock struct {
 	Address string
+	TxHash  string
+	TxKey   string
+	// Height is the sender's monero chain height when it broadcast the lock transaction, so the
+	// lock is mined at or above it; it's 0 if the sender predates it
+	Height uint64 `json:",omitempty"`
 }
 
 // String ...
 func (m *NotifyXMRLock) String() string {
-	return "NotifyXMRLock"
+	return fmt.Sprintf("NotifyXMRLock Address=%s TxHash=%s Height=%d",
+		m.Address,
+		m.TxHash,
+		m.Height,
+	)
 }
 
 // Encode ...
@@ -321,3 +711,183 @@ func (m *NotifyRefund) Encode() ([]byte, error) {
 func (m *NotifyRefund) Type() Type {
 	return NotifyRefundType
 }
+
+// NotifyAbort is sent by either party when it cancels the swap before locking its funds.
+type NotifyAbort struct {
+	Reason string
+}
+
+// String ...
+func (m *NotifyAbort) String() string {
+	return fmt.Sprintf("NotifyAbort %s", m.Reason)
+}
+
+// Encode ...
+func (m *NotifyAbort) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(NotifyAbortType)}, b...), nil
+}
+
+// Type ...
+func (m *NotifyAbort) Type() Type {
+	return NotifyAbortType
+}
+
+// signedPrefix is prepended to the bytes a Signed message's signature is over, so that it can't be
+// taken for a signature over anything else
+const signedPrefix = "atomic-swap signed message:"
+
+var errNestedSigned = errors.New("signed message contains a signed message")
+
+// Signed wraps a swap message sent after the SendKeysMessages, signed with the sender's session
+// key, so that it can't be spoofed by anyone else.
+type Signed struct {
+	// Nonce is the number of signed messages the sender sent on the swap before this one
+	Nonce     uint64
+	Message   []byte
+	Signature []byte
+}
+
+// String ...
+func (m *Signed) String() string {
+	return fmt.Sprintf("Signed Nonce=%d Message=%x Signature=%x",
+		m.Nonce,
+		m.Message,
+		m.Signature,
+	)
+}
+
+// Encode ...
+func (m *Signed) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(SignedType)}, b...), nil
+}
+
+// sessionKeyPrefix is prepended to the session key that's signed in a SendKeysMessage, so that the
+// signature can't be mistaken for one over anything else.
+const sessionKeyPrefix = "atomic-swap session key:"
+
+// SessionKeyDigest returns the digest of a SendKeysMessage's session key that the sender signs
+// with its secp256k1 key.
+func SessionKeyDigest(sessionKey []byte) []byte {
+	return ethcrypto.Keccak256(append([]byte(sessionKeyPrefix), sessionKey...))
+}
+
+// Type ...
+func (m *Signed) Type() Type {
+	return SignedType
+}
+
+// SigningBytes returns the bytes the sender signs. They include the recipient's session key and
+// the nonce, so that the message can't be replayed on another swap, or again on this one.
+func (m *Signed) SigningBytes(recipientKey []byte) []byte {
+	var nonce [8]byte
+	binary.BigEndian.PutUint64(nonce[:], m.Nonce)
+
+	b := make([]byte, 0, len(signedPrefix)+len(recipientKey)+len(nonce)+len(m.Message))
+	b = append(b, signedPrefix...)
+	b = append(b, recipientKey...)
+	b = append(b, nonce[:]...)
+	return append(b, m.Message...)
+}
+
+// Decode decodes the signed message.
+func (m *Signed) Decode() (Message, error) {
+	msg, err := DecodeMessage(m.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	if msg.Type() == SignedType {
+		return nil, errNestedSigned
+	}
+
+	return msg, nil
+}
+
+// Ping is sent periodically on a swap stream to check that the counterparty is still there. It's
+// answered with a Pong with the same nonce.
+type Ping struct {
+	Nonce uint64
+}
+
+// String ...
+func (m *Ping) String() string {
+	return fmt.Sprintf("Ping Nonce=%d", m.Nonce)
+}
+
+// Encode ...
+func (m *Ping) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(PingType)}, b...), nil
+}
+
+// Type ...
+func (m *Ping) Type() Type {
+	return PingType
+}
+
+// Pong answers a Ping.
+type Pong struct {
+	Nonce uint64
+}
+
+// String ...
+func (m *Pong) String() string {
+	return fmt.Sprintf("Pong Nonce=%d", m.Nonce)
+}
+
+// Encode ...
+func (m *Pong) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(PongType)}, b...), nil
+}
+
+// Type ...
+func (m *Pong) Type() Type {
+	return PongType
+}
+
+// PrivateOfferRequest is sent by a taker to ask a maker for the private offer that the maker
+// gave out the token for. The maker answers with a QueryResponse listing the offer, or no offers
+// if the token isn't for any of them.
+type PrivateOfferRequest struct {
+	Token string
+}
+
+// String ...
+func (m *PrivateOfferRequest) String() string {
+	// the token lets anyone take the offer, so it isn't logged
+	return "PrivateOfferRequest"
+}
+
+// Encode ...
+func (m *PrivateOfferRequest) Encode() ([]byte, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte(PrivateOfferRequestType)}, b...), nil
+}
+
+// Type ...
+func (m *PrivateOfferRequest) Type() Type {
+	return PrivateOfferRequestType
+}
@@ -0,0 +1,248 @@
+package message
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeMessage_QueryResponse(t *testing.T) {
+	offer := &types.Offer{
+		Provides:           types.ProvidesXMR,
+		MinimumAmount:      1,
+		MaximumAmount:      2,
+		ExchangeRate:       0.05,
+		Version:            types.CurrentOfferVersion,
+		MinimumTakerAmount: 0.06,
+	}
+	offer.GetID()
+
+	bz, err := (&QueryResponse{Offers: []*types.Offer{offer}}).Encode()
+	require.NoError(t, err)
+
+	msg, err := DecodeMessage(bz)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Offer{offer}, msg.(*QueryResponse).Offers)
+}
+
+func TestQueryResponse_LegacyOfferIDs(t *testing.T) {
+	offer := &types.Offer{ID: types.Hash{1, 2}, Provides: types.ProvidesXMR, MaximumAmount: 1}
+	bz, err := (&QueryResponse{Offers: []*types.Offer{offer}}).Encode()
+	require.NoError(t, err)
+
+	// nodes that predate hex-encoded hashes decode offer IDs as arrays of bytes
+	var legacy struct {
+		Offers []struct {
+			ID       [32]byte
+			Provides types.ProvidesCoin
+		}
+	}
+	require.NoError(t, json.Unmarshal(bz[1:], &legacy))
+	require.Len(t, legacy.Offers, 1)
+	require.Equal(t, [32]byte(offer.ID), legacy.Offers[0].ID)
+	require.Equal(t, types.ProvidesXMR, legacy.Offers[0].Provides)
+}
+
+func TestDecodeMessage_QueryResponse_Compatibility(t *testing.T) {
+	// an offer from a node that predates offer versions, one that can't be decoded, and one from
+	// a newer node with terms we don't know about
+	bz := append([]byte{byte(QueryResponseType)}, []byte(`{"Offers":[
+		{"ID":"0x0100000000000000000000000000000000000000000000000000000000000000","Provides":"XMR",
+			"MinimumAmount":1,"MaximumAmount":2,"ExchangeRate":0.05,"SpeedTiers":null},
+		{"ID":"0x0200000000000000000000000000000000000000000000000000000000000000","MinimumAmount":"one"},
+		null,
+		{"ID":"0x0300000000000000000000000000000000000000000000000000000000000000","Provides":"XMR",
+			"MinimumAmount":1,"MaximumAmount":2,"ExchangeRate":0.05,"Version":2,"NewTerm":true}
+	]}`)...)
+
+	msg, err := DecodeMessage(bz)
+	require.NoError(t, err)
+
+	offers := msg.(*QueryResponse).Offers
+	require.Len(t, offers, 2)
+	require.Equal(t, types.Hash{1}, offers[0].ID)
+	require.Equal(t, uint64(0), offers[0].Version)
+	require.NoError(t, offers[0].CheckTake(0.01))
+	require.Equal(t, types.Hash{3}, offers[1].ID)
+	require.Equal(t, uint64(2), offers[1].Version)
+}
+
+func TestDecodeMessage_Compressed(t *testing.T) {
+	msg := &SendKeysMessage{
+		DLEqProof: strings.Repeat("0123456789abcdef", 64),
+		Version:   &types.VersionInfo{},
+	}
+	bz, err := msg.Encode()
+	require.NoError(t, err)
+
+	compressed := Compress(bz)
+	require.Equal(t, byte(CompressedType), compressed[0])
+	require.Less(t, len(compressed), len(bz))
+
+	decoded, err := DecodeMessage(compressed)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	// a compressed message can't contain another one
+	_, err = DecodeMessage(Compress(compressed))
+	require.ErrorIs(t, err, errNestedCompression)
+
+	// nor be too large once decompressed
+	_, err = DecodeMessage(Compress(make([]byte, maxDecompressedSize+1)))
+	require.Error(t, err)
+
+	_, err = DecodeMessage([]byte{byte(CompressedType), 0xff, 0xff})
+	require.Error(t, err)
+}
+
+func TestSupportsCompression(t *testing.T) {
+	require.True(t, SupportsCompression([]string{"zstd", Snappy}))
+	require.False(t, SupportsCompression([]string{"zstd"}))
+	require.False(t, SupportsCompression(nil))
+}
+
+func TestEnvelope(t *testing.T) {
+	msg := &NotifyETHLocked{Address: "0xabcd"}
+	bz, err := Envelope(msg)
+	require.NoError(t, err)
+	require.Equal(t, []byte{envelopeMarker, EnvelopeVersion, 1, byte(NotifyETHLockedType)}, bz[:4])
+
+	decoded, err := DecodeMessage(bz)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	// messages from nodes that predate envelopes still decode
+	legacy, err := msg.Encode()
+	require.NoError(t, err)
+	decoded, err = DecodeMessage(legacy)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	// as do envelopes compressed as a whole
+	decoded, err = DecodeMessage(Compress(bz))
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+}
+
+func TestEnvelope_Compatibility(t *testing.T) {
+	// a newer node's envelope, with a header field and a message field we don't know about
+	bz := []byte{envelopeMarker, EnvelopeVersion, 3, byte(NotifyETHLockedType), encodingJSON, 0x34}
+	bz = append(bz, []byte(`{"Address":"0xabcd","NewField":true}`)...)
+
+	decoded, err := DecodeMessage(bz)
+	require.NoError(t, err)
+	require.Equal(t, &NotifyETHLocked{Address: "0xabcd"}, decoded)
+}
+
+func TestEnvelope_Invalid(t *testing.T) {
+	_, err := DecodeMessage([]byte{envelopeMarker, EnvelopeVersion + 1, 1, byte(NotifyETHLockedType)})
+	require.ErrorIs(t, err, errUnsupportedEnvelope)
+
+	_, err = DecodeMessage([]byte{envelopeMarker, 0, 1, byte(NotifyETHLockedType)})
+	require.ErrorIs(t, err, errUnsupportedEnvelope)
+
+	for _, bz := range [][]byte{
+		{envelopeMarker},
+		{envelopeMarker, EnvelopeVersion},
+		{envelopeMarker, EnvelopeVersion, 0},
+		{envelopeMarker, EnvelopeVersion, 2, byte(NotifyETHLockedType)},
+	} {
+		_, err = DecodeMessage(bz)
+		require.ErrorIs(t, err, errInvalidEnvelope)
+	}
+
+	// compression wraps envelopes, not the other way round
+	_, err = DecodeMessage([]byte{envelopeMarker, EnvelopeVersion, 1, byte(CompressedType)})
+	require.ErrorIs(t, err, errCompressedInEnvelope)
+}
+
+func TestCBOREnvelope(t *testing.T) {
+	msg := &NotifyETHLocked{
+		Address:        "0xabcd",
+		TxHash:         "0x1234",
+		ContractSwapID: [32]byte{1, 2, 3},
+		ContractSwap: &ContractSwap{
+			Owner:    ethcommon.Address{1},
+			Timeout0: big.NewInt(1000),
+			Timeout1: big.NewInt(2000),
+			Value:    new(big.Int).Lsh(big.NewInt(1), 100),
+			Nonce:    big.NewInt(0),
+		},
+	}
+
+	bz, err := CBOREnvelope(msg)
+	require.NoError(t, err)
+	require.Equal(t, []byte{envelopeMarker, EnvelopeVersion, 2, byte(NotifyETHLockedType), encodingCBOR}, bz[:5])
+
+	decoded, err := DecodeMessage(bz)
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+
+	// the encoding is deterministic, and smaller than JSON
+	again, err := CBOREnvelope(msg)
+	require.NoError(t, err)
+	require.Equal(t, bz, again)
+
+	jsonBz, err := Envelope(msg)
+	require.NoError(t, err)
+	require.Less(t, len(bz), len(jsonBz))
+
+	signed := &Signed{Nonce: 1, Message: bz, Signature: []byte{4, 5, 6}}
+	bz, err = CBOREnvelope(signed)
+	require.NoError(t, err)
+	decoded, err = DecodeMessage(bz)
+	require.NoError(t, err)
+	require.Equal(t, signed, decoded)
+	decoded, err = decoded.(*Signed).Decode()
+	require.NoError(t, err)
+	require.Equal(t, msg, decoded)
+}
+
+func TestCBOREnvelope_Compatibility(t *testing.T) {
+	// a newer node's message, with a field we don't know about
+	payload, err := cbor.Marshal(map[string]interface{}{"Address": "0xabcd", "NewField": true})
+	require.NoError(t, err)
+
+	bz := envelope([]byte{byte(NotifyETHLockedType), encodingCBOR}, payload)
+	decoded, err := DecodeMessage(bz)
+	require.NoError(t, err)
+	require.Equal(t, &NotifyETHLocked{Address: "0xabcd"}, decoded)
+}
+
+func TestCBOREnvelope_Invalid(t *testing.T) {
+	// a field can't be given twice
+	payload := []byte{0xa2, 0x67}
+	payload = append(payload, "Address"...)
+	payload = append(payload, 0x61, 'a', 0x67)
+	payload = append(payload, "Address"...)
+	payload = append(payload, 0x61, 'b')
+	_, err := DecodeMessage(envelope([]byte{byte(NotifyETHLockedType), encodingCBOR}, payload))
+	var dupErr *cbor.DupMapKeyError
+	require.ErrorAs(t, err, &dupErr)
+
+	_, err = DecodeMessage(envelope([]byte{byte(NotifyETHLockedType), encodingCBOR + 1}, payload))
+	require.ErrorIs(t, err, errUnknownEncoding)
+}
+
+func TestSupportsCBOR(t *testing.T) {
+	require.True(t, SupportsCBOR([]string{"other", CBOR}))
+	require.False(t, SupportsCBOR([]string{"other"}))
+	require.False(t, SupportsCBOR(nil))
+}
+
+func TestDecodeMessage_Ping(t *testing.T) {
+	for _, msg := range []Message{&Ping{Nonce: 7}, &Pong{Nonce: 7}, &PrivateOfferRequest{Token: "abc"}} {
+		bz, err := CBOREnvelope(msg)
+		require.NoError(t, err)
+		decoded, err := DecodeMessage(bz)
+		require.NoError(t, err)
+		require.Equal(t, msg, decoded)
+	}
+}
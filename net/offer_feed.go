@@ -0,0 +1,126 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// how long an offer is remembered after we last saw it; it's only sent to subscribers again
+	// if it's seen after that
+	seenOfferTTL = gossipOffersTTL
+	// how many offers a subscriber can fall behind by before offers are dropped for it
+	offerSubscriberBufferSize = 256
+)
+
+// OfferSource is how we learned about an offer.
+type OfferSource string
+
+// The ways we learn about offers.
+const (
+	// OfferSourceQuery is an offer returned when we queried a maker
+	OfferSourceQuery OfferSource = "query"
+	// OfferSourceGossip is an offer a maker published on the offers topic
+	OfferSourceGossip OfferSource = "gossip"
+)
+
+// SeenOffer is an offer made by another peer that we've just learned about.
+type SeenOffer struct {
+	Peer   peer.ID
+	Offer  *types.Offer
+	Source OfferSource
+	Time   time.Time
+}
+
+// offerFeed sends the offers we learn about from other peers to its subscribers. Offers we've seen
+// recently aren't sent again, so makers republishing their offers don't flood subscribers.
+type offerFeed struct {
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[uint64]chan *SeenOffer
+	// when we last saw each offer, by ID
+	seen map[types.Hash]time.Time
+}
+
+func newOfferFeed() *offerFeed {
+	return &offerFeed{
+		subscribers: make(map[uint64]chan *SeenOffer),
+		seen:        make(map[types.Hash]time.Time),
+	}
+}
+
+// publish sends the offers that we haven't seen recently, and that haven't expired, to the
+// subscribers.
+func (f *offerFeed) publish(from peer.ID, offers []*types.Offer, source OfferSource, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for id, last := range f.seen {
+		if now.Sub(last) > seenOfferTTL {
+			delete(f.seen, id)
+		}
+	}
+
+	for _, o := range offers {
+		if o.ExpiresAt != nil && !now.Before(*o.ExpiresAt) {
+			continue
+		}
+
+		id := o.GetID()
+		_, has := f.seen[id]
+		f.seen[id] = now
+		if has {
+			continue
+		}
+
+		seen := &SeenOffer{
+			Peer:   from,
+			Offer:  o,
+			Source: source,
+			Time:   now,
+		}
+
+		for _, ch := range f.subscribers {
+			select {
+			case ch <- seen:
+			default:
+				log.Debugf("dropped offer %s for a slow subscriber", id)
+			}
+		}
+	}
+}
+
+// subscribe returns a channel that receives the offers we learn about from now on, and a function
+// that unsubscribes, after which the channel is closed.
+func (f *offerFeed) subscribe() (<-chan *SeenOffer, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := f.nextID
+	f.nextID++
+	ch := make(chan *SeenOffer, offerSubscriberBufferSize)
+	f.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			delete(f.subscribers, id)
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// SubscribeOffers returns a channel that receives the offers we learn about from other peers, by
+// querying them or from the offers topic, and a function that unsubscribes. Offers we've seen in
+// the last few minutes aren't sent again.
+func (h *host) SubscribeOffers() (<-chan *SeenOffer, func()) {
+	return h.offerFeed.subscribe()
+}
@@ -0,0 +1,71 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfferFeed(t *testing.T) {
+	f := newOfferFeed()
+	ch, unsubscribe := f.subscribe()
+
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	offer := &types.Offer{ID: types.Hash{1}}
+	peerA := peer.ID("a")
+
+	f.publish(peerA, []*types.Offer{offer, {ID: types.Hash{2}, ExpiresAt: &expired}}, OfferSourceQuery, now)
+	seen := <-ch
+	require.Equal(t, peerA, seen.Peer)
+	require.Equal(t, offer, seen.Offer)
+	require.Equal(t, OfferSourceQuery, seen.Source)
+
+	// offers seen recently aren't sent again, and expired offers aren't sent
+	f.publish(peerA, []*types.Offer{offer}, OfferSourceGossip, now.Add(time.Minute))
+	require.Empty(t, ch)
+
+	// offers are sent again once they're forgotten
+	later := now.Add(time.Minute + seenOfferTTL + time.Second)
+	f.publish(peerA, []*types.Offer{offer}, OfferSourceGossip, later)
+	seen = <-ch
+	require.Equal(t, OfferSourceGossip, seen.Source)
+
+	unsubscribe()
+	_, ok := <-ch
+	require.False(t, ok)
+	f.publish(peerA, []*types.Offer{{ID: types.Hash{3}}}, OfferSourceGossip, later)
+}
+
+func TestHost_SubscribeOffers(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+	offer := &types.Offer{ID: types.Hash{1}, Provides: types.ProvidesXMR, MaximumAmount: 1}
+	ha.handler = &mockHandler{offers: []*types.Offer{offer}}
+
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	ch, unsubscribe := hb.SubscribeOffers()
+	defer unsubscribe()
+
+	_, err := hb.Query(ha.addrInfo())
+	require.NoError(t, err)
+
+	select {
+	case seen := <-ch:
+		require.Equal(t, ha.h.ID(), seen.Peer)
+		require.Equal(t, offer.ID, seen.Offer.ID)
+		require.Equal(t, OfferSourceQuery, seen.Source)
+	case <-time.After(time.Second * 5):
+		t.Fatal("offer wasn't received")
+	}
+}
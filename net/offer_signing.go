@@ -0,0 +1,103 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// signOffers returns copies of the offers signed by our identity key, so that takers can check
+// they were made by us however they received them.
+func (h *host) signOffers(offers []*types.Offer) []*types.Offer {
+	key := h.h.Peerstore().PrivKey(h.h.ID())
+	signed := make([]*types.Offer, 0, len(offers))
+	for _, o := range offers {
+		s, err := signOffer(key, h.h.ID(), o)
+		if err != nil {
+			log.Warnf("failed to sign offer %s: %s", o.ID, err)
+			continue
+		}
+
+		signed = append(signed, s)
+	}
+
+	return signed
+}
+
+func signOffer(key crypto.PrivKey, id peer.ID, o *types.Offer) (*types.Offer, error) {
+	signed := *o
+	signed.Maker = id.String()
+
+	var err error
+	signed.SignedPayload, err = signed.EncodeForSigning()
+	if err != nil {
+		return nil, err
+	}
+
+	signed.Signature, err = key.Sign(signed.SigningBytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return &signed, nil
+}
+
+// VerifyOffer checks that the offer is signed by its maker's identity key, and returns the maker.
+// The offer's terms are replaced by the ones its maker signed, in case they were changed after.
+func VerifyOffer(o *types.Offer) (peer.ID, error) {
+	if len(o.Signature) == 0 || len(o.SignedPayload) == 0 {
+		return "", errOfferNotSigned
+	}
+
+	var signed types.Offer
+	if err := json.Unmarshal(o.SignedPayload, &signed); err != nil {
+		return "", fmt.Errorf("invalid signed offer: %w", err)
+	}
+
+	maker, err := peer.Decode(signed.Maker)
+	if err != nil {
+		return "", fmt.Errorf("invalid offer maker %q: %w", signed.Maker, err)
+	}
+
+	pub, err := maker.ExtractPublicKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to get public key of offer maker: %w", err)
+	}
+
+	ok, err := pub.Verify(o.SigningBytes(), o.Signature)
+	if err != nil || !ok {
+		return "", errInvalidOfferSignature
+	}
+
+	signed.SignedPayload = o.SignedPayload
+	signed.Signature = o.Signature
+	*o = signed
+	return maker, nil
+}
+
+// verifyOffersFrom checks that each signed offer was signed by the peer we received it from.
+// Nodes that predate offer signing send unsigned offers; since we received them from their maker,
+// they're accepted as they are, but any maker they claim is cleared.
+func verifyOffersFrom(from peer.ID, offers []*types.Offer) error {
+	for _, o := range offers {
+		if len(o.Signature) == 0 {
+			o.Maker = ""
+			continue
+		}
+
+		maker, err := VerifyOffer(o)
+		if err != nil {
+			return fmt.Errorf("offer %s: %w", o.ID, err)
+		}
+
+		if maker != from {
+			return fmt.Errorf("offer %s: %w", o.ID, errOfferMakerMismatch)
+		}
+	}
+
+	return nil
+}
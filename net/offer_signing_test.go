@@ -0,0 +1,93 @@
+package net
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMaker(t *testing.T, seed int64) (peer.ID, *types.Offer, func(*types.Offer) *types.Offer) {
+	key, err := generateKey(seed, "")
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+
+	offer := &types.Offer{ID: types.Hash{1}, Provides: types.ProvidesXMR, MaximumAmount: 1, ExchangeRate: 0.1}
+	sign := func(o *types.Offer) *types.Offer {
+		signed, err := signOffer(key, id, o)
+		require.NoError(t, err)
+		return signed
+	}
+	return id, offer, sign
+}
+
+func TestVerifyOffer(t *testing.T) {
+	id, offer, sign := newTestMaker(t, 1)
+
+	_, err := VerifyOffer(offer)
+	require.ErrorIs(t, err, errOfferNotSigned)
+
+	signed := sign(offer)
+	require.Empty(t, offer.Signature)
+	maker, err := VerifyOffer(signed)
+	require.NoError(t, err)
+	require.Equal(t, id, maker)
+
+	// the signed terms replace any that were changed after signing
+	tampered := *signed
+	tampered.ExchangeRate = 0.2
+	_, err = VerifyOffer(&tampered)
+	require.NoError(t, err)
+	require.Equal(t, offer.ExchangeRate, tampered.ExchangeRate)
+
+	// changing the signed terms invalidates the signature
+	tampered = *signed
+	tampered.SignedPayload = bytes.Replace(signed.SignedPayload, []byte(`"ExchangeRate":0.1`),
+		[]byte(`"ExchangeRate":0.2`), 1)
+	_, err = VerifyOffer(&tampered)
+	require.ErrorIs(t, err, errInvalidOfferSignature)
+
+	// so does claiming it was made by someone else
+	otherID, _, _ := newTestMaker(t, 2)
+	tampered = *signed
+	tampered.SignedPayload = bytes.Replace(signed.SignedPayload, []byte(id.String()), []byte(otherID.String()), 1)
+	_, err = VerifyOffer(&tampered)
+	require.ErrorIs(t, err, errInvalidOfferSignature)
+}
+
+func TestVerifyOffer_UnknownFields(t *testing.T) {
+	key, err := generateKey(1, "")
+	require.NoError(t, err)
+	id, err := peer.IDFromPrivateKey(key)
+	require.NoError(t, err)
+
+	// an offer from a newer node, with a field we don't know of
+	payload := []byte(`{"Provides":"XMR","MaximumAmount":1,"ExchangeRate":0.1,"Maker":"` + id.String() + `","NewTerm":true}`)
+	o := &types.Offer{SignedPayload: payload}
+	o.Signature, err = key.Sign(o.SigningBytes())
+	require.NoError(t, err)
+
+	maker, err := VerifyOffer(o)
+	require.NoError(t, err)
+	require.Equal(t, id, maker)
+	require.Equal(t, types.ExchangeRate(0.1), o.ExchangeRate)
+}
+
+func TestVerifyOffersFrom(t *testing.T) {
+	id, offer, sign := newTestMaker(t, 1)
+	otherID, _, _ := newTestMaker(t, 2)
+	offers := []*types.Offer{sign(offer)}
+
+	require.NoError(t, verifyOffersFrom(id, offers))
+	require.ErrorIs(t, verifyOffersFrom(otherID, offers), errOfferMakerMismatch)
+
+	// offers from nodes that predate signing are accepted, without the maker they claim
+	unsigned := *offer
+	unsigned.Maker = otherID.String()
+	require.NoError(t, verifyOffersFrom(id, []*types.Offer{&unsigned}))
+	require.Empty(t, unsigned.Maker)
+}
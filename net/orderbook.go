@@ -0,0 +1,199 @@
+package net
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// DefaultOrderbookInterval is how often the orderbook crawler discovers and queries makers
+	// by default
+	DefaultOrderbookInterval = time.Minute * 2
+
+	orderbookSearchTime = time.Second * 12
+	// how many crawls in a row a maker can't be queried in before its offers are dropped
+	orderbookMaxMissedCrawls = 3
+	// most makers queried at once
+	maxOrderbookQueries = 8
+)
+
+// OrderbookEntry is a maker's offers, as of when we last queried it.
+type OrderbookEntry struct {
+	Peer   peer.AddrInfo
+	Offers []*types.Offer
+	// UpdatedAt is when the maker last answered our query
+	UpdatedAt time.Time
+}
+
+// Orderbook is the offers of the makers found by the orderbook crawler.
+type Orderbook struct {
+	Makers []*OrderbookEntry
+	// LastCrawl is when the last crawl finished; it's zero if none has yet
+	LastCrawl time.Time
+}
+
+// orderbook periodically discovers makers and queries their offers, so that the offers on the
+// network can be listed without waiting for a search. Unlike the offers makers publish on the
+// offers topic, it includes makers that aren't subscribed to the topic.
+type orderbook struct {
+	ctx        context.Context
+	interval   time.Duration
+	discover   func(provides types.ProvidesCoin, searchTime time.Duration) ([]peer.AddrInfo, error)
+	query      func(who peer.AddrInfo) (*QueryResponse, error)
+	reputation *reputation
+
+	mu        sync.RWMutex
+	entries   map[peer.ID]*OrderbookEntry
+	lastCrawl time.Time
+}
+
+func newOrderbook(ctx context.Context, interval time.Duration,
+	discover func(types.ProvidesCoin, time.Duration) ([]peer.AddrInfo, error),
+	query func(peer.AddrInfo) (*QueryResponse, error), rep *reputation) *orderbook {
+	return &orderbook{
+		ctx:        ctx,
+		interval:   interval,
+		discover:   discover,
+		query:      query,
+		reputation: rep,
+		entries:    make(map[peer.ID]*OrderbookEntry),
+	}
+}
+
+func (ob *orderbook) start() {
+	go ob.run()
+}
+
+func (ob *orderbook) run() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ob.ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		ob.crawl()
+		timer.Reset(ob.interval)
+	}
+}
+
+// crawl discovers the makers providing XMR and queries each of them for its offers.
+func (ob *orderbook) crawl() {
+	peers, err := ob.discover(types.ProvidesXMR, orderbookSearchTime)
+	if err != nil {
+		log.Debugf("failed to discover makers for the orderbook: %s", err)
+		return
+	}
+
+	log.Debugf("crawling %d makers for the orderbook", len(peers))
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, maxOrderbookQueries)
+	)
+
+	for _, who := range peers {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(who peer.AddrInfo) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			ob.queryMaker(who)
+		}(who)
+	}
+
+	wg.Wait()
+
+	now := time.Now()
+	ob.prune(now)
+	ob.mu.Lock()
+	ob.lastCrawl = now
+	ob.mu.Unlock()
+}
+
+func (ob *orderbook) queryMaker(who peer.AddrInfo) {
+	resp, err := ob.query(who)
+	if err != nil {
+		log.Debugf("failed to query maker %s for the orderbook: %s", who.ID, err)
+		return
+	}
+
+	ob.update(who, resp.Offers, time.Now())
+}
+
+// update replaces the maker's offers with those it just listed.
+func (ob *orderbook) update(who peer.AddrInfo, offers []*types.Offer, now time.Time) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	if len(offers) == 0 {
+		delete(ob.entries, who.ID)
+		return
+	}
+
+	ob.entries[who.ID] = &OrderbookEntry{
+		Peer:      who,
+		Offers:    offers,
+		UpdatedAt: now,
+	}
+}
+
+// prune forgets the offers of makers we haven't been able to query for a few crawls.
+func (ob *orderbook) prune(now time.Time) {
+	ob.mu.Lock()
+	defer ob.mu.Unlock()
+
+	for id, entry := range ob.entries {
+		if now.Sub(entry.UpdatedAt) > ob.interval*orderbookMaxMissedCrawls {
+			delete(ob.entries, id)
+		}
+	}
+}
+
+// list returns the unexpired offers of each maker in the orderbook, sorted by peer ID.
+func (ob *orderbook) list(now time.Time) *Orderbook {
+	ob.mu.RLock()
+	defer ob.mu.RUnlock()
+
+	var entries []*OrderbookEntry
+	for id, entry := range ob.entries {
+		if ob.reputation.isBanned(id) {
+			continue
+		}
+
+		listed := &OrderbookEntry{
+			Peer:      entry.Peer,
+			UpdatedAt: entry.UpdatedAt,
+		}
+
+		for _, o := range entry.Offers {
+			if !o.Expired(now) {
+				listed.Offers = append(listed.Offers, o)
+			}
+		}
+
+		if len(listed.Offers) != 0 {
+			entries = append(entries, listed)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Peer.ID < entries[j].Peer.ID
+	})
+	return &Orderbook{
+		Makers:    entries,
+		LastCrawl: ob.lastCrawl,
+	}
+}
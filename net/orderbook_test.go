@@ -0,0 +1,98 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderbook_Crawl(t *testing.T) {
+	peerA, peerB, peerC := peer.AddrInfo{ID: "a"}, peer.AddrInfo{ID: "b"}, peer.AddrInfo{ID: "c"}
+	offerA := &types.Offer{ID: types.Hash{1}}
+	offerB := &types.Offer{ID: types.Hash{2}}
+	queryable := map[peer.ID]bool{peerA.ID: true, peerB.ID: true}
+
+	discover := func(types.ProvidesCoin, time.Duration) ([]peer.AddrInfo, error) {
+		return []peer.AddrInfo{peerA, peerB, peerC}, nil
+	}
+	query := func(who peer.AddrInfo) (*QueryResponse, error) {
+		if !queryable[who.ID] {
+			return nil, errors.New("unreachable")
+		}
+
+		switch who.ID {
+		case peerA.ID:
+			return &QueryResponse{Offers: []*types.Offer{offerA}}, nil
+		case peerB.ID:
+			return &QueryResponse{Offers: []*types.Offer{offerB}}, nil
+		}
+		return &QueryResponse{}, nil
+	}
+
+	rep := newReputation()
+	ob := newOrderbook(context.Background(), time.Minute, discover, query, rep)
+	require.True(t, ob.list(time.Now()).LastCrawl.IsZero())
+
+	// makers that can't be queried aren't listed
+	ob.crawl()
+	book := ob.list(time.Now())
+	require.False(t, book.LastCrawl.IsZero())
+	require.Len(t, book.Makers, 2)
+	require.Equal(t, peerA.ID, book.Makers[0].Peer.ID)
+	require.Equal(t, []*types.Offer{offerA}, book.Makers[0].Offers)
+	require.Equal(t, peerB.ID, book.Makers[1].Peer.ID)
+
+	// a maker that can't be queried keeps its offers until it's been missed for a few crawls
+	queryable[peerB.ID] = false
+	ob.crawl()
+	book = ob.list(time.Now())
+	require.Len(t, book.Makers, 2)
+	require.True(t, book.Makers[1].UpdatedAt.Before(book.Makers[0].UpdatedAt))
+
+	ob.prune(time.Now().Add(ob.interval*orderbookMaxMissedCrawls + time.Second))
+	require.Empty(t, ob.entries)
+
+	// banned makers aren't listed
+	ob.crawl()
+	rep.tempBan(peerA.ID, time.Now().Add(time.Minute))
+	require.Empty(t, ob.list(time.Now()).Makers)
+}
+
+func TestOrderbook_Update(t *testing.T) {
+	ob := newOrderbook(context.Background(), time.Minute, nil, nil, newReputation())
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	offer := &types.Offer{ID: types.Hash{1}}
+	peerA, peerB := peer.AddrInfo{ID: "a"}, peer.AddrInfo{ID: "b"}
+
+	ob.update(peerA, []*types.Offer{offer, {ID: types.Hash{2}, ExpiresAt: &expired}}, now)
+	ob.update(peerB, []*types.Offer{{ID: types.Hash{3}, ExpiresAt: &expired}}, now)
+
+	// expired offers aren't listed, nor makers without any other offers
+	book := ob.list(now)
+	require.Len(t, book.Makers, 1)
+	require.Equal(t, peerA.ID, book.Makers[0].Peer.ID)
+	require.Equal(t, []*types.Offer{offer}, book.Makers[0].Offers)
+	require.Equal(t, now, book.Makers[0].UpdatedAt)
+
+	// a maker that lists no offers is removed
+	ob.update(peerA, nil, now)
+	ob.update(peerB, nil, now)
+	require.Empty(t, ob.entries)
+}
+
+func TestHost_Orderbook(t *testing.T) {
+	h := newHost(t, defaultPort)
+	defer func() {
+		_ = h.Stop()
+	}()
+
+	_, err := h.Orderbook()
+	require.ErrorIs(t, err, errOrderbookDisabled)
+}
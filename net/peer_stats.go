@@ -0,0 +1,120 @@
+package net
+
+import (
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/metrics"
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// ConnectedPeer is a peer we're connected to, and how we're connected to it.
+type ConnectedPeer struct {
+	Peer peer.ID
+	// Addrs are the peer's addresses that we're connected to it at
+	Addrs []ma.Multiaddr
+	// Protocols are the protocols the peer supports
+	Protocols []string
+	// Direction and ConnectedAt are of our oldest connection with the peer
+	Direction   libp2pnetwork.Direction
+	ConnectedAt time.Time
+	Score       *PeerScore
+}
+
+// PeerStats are the bandwidth we've used with a peer, and its latency.
+type PeerStats struct {
+	Peer      peer.ID
+	Connected bool
+	// BytesIn and BytesOut are the total bytes received from and sent to the peer, and RateIn and
+	// RateOut the current rates, in bytes per second
+	BytesIn  int64
+	BytesOut int64
+	RateIn   float64
+	RateOut  float64
+	// Latency is the peer's round-trip latency, averaged over the swap pings and queries we've
+	// timed; it's 0 if we haven't timed any
+	Latency time.Duration
+}
+
+// PeerCount returns how many peers we're connected to.
+func (h *host) PeerCount() int {
+	return len(h.h.Network().Peers())
+}
+
+// ConnectedPeers returns the peers we're connected to, sorted by peer ID.
+func (h *host) ConnectedPeers() []*ConnectedPeer {
+	var peers []*ConnectedPeer
+	for _, who := range h.h.Network().Peers() {
+		conns := h.h.Network().ConnsToPeer(who)
+		if len(conns) == 0 {
+			// the peer disconnected since we listed the peers
+			continue
+		}
+
+		// the protocols are only known once the peer has been identified
+		protocols, err := h.h.Peerstore().GetProtocols(who)
+		if err != nil {
+			log.Debugf("failed to get protocols of peer %s: %s", who, err)
+		}
+
+		p := &ConnectedPeer{
+			Peer:      who,
+			Protocols: protocols,
+			Score:     h.reputation.score(who),
+		}
+
+		for _, conn := range conns {
+			p.Addrs = append(p.Addrs, conn.RemoteMultiaddr())
+			stat := conn.Stat()
+			if p.ConnectedAt.IsZero() || stat.Opened.Before(p.ConnectedAt) {
+				p.Direction = stat.Direction
+				p.ConnectedAt = stat.Opened
+			}
+		}
+
+		sort.Strings(p.Protocols)
+		peers = append(peers, p)
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].Peer < peers[j].Peer
+	})
+	return peers
+}
+
+// PeerStats returns the stats of the peers we're connected to or have exchanged data with, sorted
+// by peer ID.
+func (h *host) PeerStats() []*PeerStats {
+	byPeer := h.bandwidth.GetBandwidthByPeer()
+	for _, who := range h.h.Network().Peers() {
+		if _, has := byPeer[who]; !has {
+			byPeer[who] = metrics.Stats{}
+		}
+	}
+
+	stats := make([]*PeerStats, 0, len(byPeer))
+	for who, bw := range byPeer {
+		stats = append(stats, &PeerStats{
+			Peer:      who,
+			Connected: h.h.Network().Connectedness(who) == libp2pnetwork.Connected,
+			BytesIn:   bw.TotalIn,
+			BytesOut:  bw.TotalOut,
+			RateIn:    bw.RateIn,
+			RateOut:   bw.RateOut,
+			Latency:   h.h.Peerstore().LatencyEWMA(who),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Peer < stats[j].Peer
+	})
+	return stats
+}
+
+// recordLatency records a round trip to the peer, which is averaged into its latency. The latency
+// is also used to rank the offers makers publish.
+func (h *host) recordLatency(who peer.ID, rtt time.Duration) {
+	h.h.Peerstore().RecordLatency(who, rtt)
+}
@@ -0,0 +1,76 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_PeerStats(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	_, err = ha.Query(hb.addrInfo())
+	require.NoError(t, err)
+	require.NotZero(t, ha.h.Peerstore().LatencyEWMA(hb.h.ID()))
+
+	require.Eventually(t, func() bool {
+		for _, stats := range ha.PeerStats() {
+			if stats.Peer == hb.h.ID() {
+				return stats.Connected && stats.BytesIn > 0 && stats.BytesOut > 0 && stats.Latency > 0
+			}
+		}
+		return false
+	}, time.Second*5, time.Millisecond*100)
+}
+
+func TestHost_ConnectedPeers(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+	ha.reputation.recordInvalidMessage(hb.h.ID())
+
+	var peers []*ConnectedPeer
+	require.Eventually(t, func() bool {
+		// the peers are identified after connecting
+		peers = ha.ConnectedPeers()
+		return len(peers) == 1 && len(peers[0].Protocols) > 0
+	}, time.Second*5, time.Millisecond*100)
+
+	p := peers[0]
+	require.Equal(t, hb.h.ID(), p.Peer)
+	require.NotEmpty(t, p.Addrs)
+	require.Equal(t, libp2pnetwork.DirOutbound, p.Direction)
+	require.False(t, p.ConnectedAt.IsZero())
+	require.Equal(t, int64(invalidMessageScore), p.Score.Score)
+
+	require.Eventually(t, func() bool {
+		peers := hb.ConnectedPeers()
+		return len(peers) == 1 && peers[0].Direction == libp2pnetwork.DirInbound
+	}, time.Second*5, time.Millisecond*100)
+}
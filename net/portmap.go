@@ -0,0 +1,73 @@
+package net
+
+import (
+	"github.com/libp2p/go-libp2p-core/event"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// advertisedAddrs returns the addresses to advertise out of the host's addresses, which include
+// the external addresses of any ports mapped on our router by UPnP or NAT-PMP. Private addresses
+// are left out, and our public IP address is added with our listening port, in case the port was
+// forwarded by hand.
+func advertisedAddrs(as, externalAddrs []ma.Multiaddr) []ma.Multiaddr {
+	addrs := make([]ma.Multiaddr, 0, len(as)+len(externalAddrs))
+	for _, addr := range as {
+		if !privateIPs.AddrBlocked(addr) {
+			addrs = append(addrs, addr)
+		}
+	}
+
+	for _, ext := range externalAddrs {
+		if !containsAddr(addrs, ext) {
+			addrs = append(addrs, ext)
+		}
+	}
+
+	return addrs
+}
+
+func containsAddr(addrs []ma.Multiaddr, addr ma.Multiaddr) bool {
+	for _, a := range addrs {
+		if a.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// logAddressUpdates logs the addresses we advertise as they change, such as when a port mapping
+// is made, so that it's clear whether peers can connect to us.
+func (h *host) logAddressUpdates() {
+	sub, err := h.h.EventBus().Subscribe(new(event.EvtLocalAddressesUpdated))
+	if err != nil {
+		log.Warnf("failed to subscribe to address updates: %s", err)
+		return
+	}
+
+	go func() {
+		defer func() {
+			_ = sub.Close()
+		}()
+
+		for {
+			select {
+			case <-h.ctx.Done():
+				return
+			case e, ok := <-sub.Out():
+				if !ok {
+					return
+				}
+
+				evt := e.(event.EvtLocalAddressesUpdated)
+				for _, addr := range evt.Current {
+					if addr.Action == event.Added {
+						log.Infof("advertising address %s", addr.Address)
+					}
+				}
+				for _, addr := range evt.Removed {
+					log.Infof("no longer advertising address %s", addr.Address)
+				}
+			}
+		}
+	}()
+}
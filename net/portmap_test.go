@@ -0,0 +1,31 @@
+package net
+
+import (
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvertisedAddrs(t *testing.T) {
+	as := []ma.Multiaddr{
+		ma.StringCast("/ip4/10.0.0.5/tcp/9900"),
+		ma.StringCast("/ip4/192.168.1.10/tcp/9900"),
+		// mapped on the router to a different port
+		ma.StringCast("/ip4/1.2.3.4/tcp/41000"),
+		ma.StringCast("/ip4/1.2.3.4/tcp/9900"),
+	}
+	external := []ma.Multiaddr{
+		ma.StringCast("/ip4/1.2.3.4/tcp/9900"),
+		ma.StringCast("/ip4/1.2.3.4/udp/9900/quic"),
+	}
+
+	addrs := advertisedAddrs(as, external)
+	require.Equal(t, []ma.Multiaddr{
+		ma.StringCast("/ip4/1.2.3.4/tcp/41000"),
+		ma.StringCast("/ip4/1.2.3.4/tcp/9900"),
+		ma.StringCast("/ip4/1.2.3.4/udp/9900/quic"),
+	}, addrs)
+
+	require.Equal(t, external, advertisedAddrs(nil, external))
+}
@@ -0,0 +1,146 @@
+package net
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net/message"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+const (
+	privateOfferID      = "/private-offer/0"
+	privateOfferTimeout = time.Second * 5
+)
+
+// offerToken returns the token needed to take the offer, or "" if it's public.
+func (h *host) offerToken(offerID string) string {
+	if h.handler == nil {
+		return ""
+	}
+
+	return h.handler.GetOfferToken(offerID)
+}
+
+// validOfferToken returns whether the token lets the offer with the given ID be taken. Any token
+// is valid for public offers.
+func (h *host) validOfferToken(offerID, token string) bool {
+	want := h.offerToken(offerID)
+	if want == "" {
+		return true
+	}
+
+	return subtle.ConstantTimeCompare([]byte(want), []byte(token)) == 1
+}
+
+// privateOffer returns our private offer that the token is for, or nil if it isn't for any.
+func (h *host) privateOffer(token string) *types.Offer {
+	if h.handler == nil || token == "" {
+		return nil
+	}
+
+	for _, o := range h.handler.GetOffers() {
+		id := o.GetID().String()
+		if h.offerToken(id) != "" && h.validOfferToken(id, token) {
+			return o
+		}
+	}
+
+	return nil
+}
+
+func (h *host) handlePrivateOfferStream(stream libp2pnetwork.Stream) {
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	buf := make([]byte, 1024)
+	n, err := readStream(stream, buf)
+	if err != nil {
+		log.Debugf("failed to read PrivateOfferRequest from peer: err=%s", err)
+		return
+	}
+
+	msg, err := message.DecodeMessage(buf[:n])
+	if err != nil {
+		log.Debugf("failed to decode PrivateOfferRequest from peer: err=%s", err)
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return
+	}
+
+	req, ok := msg.(*message.PrivateOfferRequest)
+	if !ok {
+		log.Debugf("peer sent %s on private offer stream, expected PrivateOfferRequest", msg.Type())
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return
+	}
+
+	// peers that don't have the token for any of our offers see no offers, so they can't tell
+	// whether we have any private offers at all
+	resp := &QueryResponse{Offers: []*types.Offer{}}
+	if o := h.privateOffer(req.Token); o != nil && h.mayTake(stream.Conn().RemotePeer(), o.GetID().String()) {
+		resp.Offers = h.signOffers([]*types.Offer{o})
+	}
+
+	if err := h.writeToStream(stream, resp); err != nil {
+		log.Warnf("failed to send QueryResponse message to peer: err=%s", err)
+	}
+}
+
+// QueryPrivateOffer asks the peer for the private offer that it gave us the token for, which can
+// then be taken by initiating a swap with the same token.
+func (h *host) QueryPrivateOffer(who peer.AddrInfo, token string) (*types.Offer, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, privateOfferTimeout)
+	defer cancel()
+
+	if err := h.h.Connect(ctx, who); err != nil {
+		return nil, err
+	}
+
+	stream, err := h.h.NewStream(ctx, who.ID, protocol.ID(h.protocolID+privateOfferID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	if err = h.writeToStream(stream, &message.PrivateOfferRequest{Token: token}); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1024*5)
+	n, err := readStream(stream, buf)
+	if err != nil {
+		return nil, fmt.Errorf("read stream error: %w", err)
+	}
+
+	if n == 0 {
+		return nil, fmt.Errorf("received empty message")
+	}
+
+	var resp *QueryResponse
+	if err = json.Unmarshal(buf[1:n], &resp); err != nil {
+		h.reputation.recordInvalidMessage(who.ID)
+		return nil, err
+	}
+
+	if err = verifyOffersFrom(who.ID, resp.Offers); err != nil {
+		h.reputation.recordInvalidMessage(who.ID)
+		return nil, err
+	}
+
+	if len(resp.Offers) == 0 {
+		return nil, errNoPrivateOffer
+	}
+
+	return resp.Offers[0], nil
+}
@@ -0,0 +1,76 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_QueryPrivateOffer(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	public := &types.Offer{ID: types.Hash{1}, Provides: types.ProvidesXMR}
+	private := &types.Offer{ID: types.Hash{2}, Provides: types.ProvidesXMR}
+	handler := hb.handler.(*mockHandler)
+	handler.offers = []*types.Offer{public, private}
+	handler.tokens = map[string]string{private.GetID().String(): "secret"}
+
+	// the private offer isn't listed
+	resp, err := ha.Query(hb.addrInfo())
+	require.NoError(t, err)
+	require.Len(t, resp.Offers, 1)
+	require.Equal(t, public.GetID(), resp.Offers[0].GetID())
+	require.Len(t, hb.getPublicOffers(), 1)
+
+	offer, err := ha.QueryPrivateOffer(hb.addrInfo(), "secret")
+	require.NoError(t, err)
+	require.Equal(t, private.GetID(), offer.GetID())
+
+	_, err = ha.QueryPrivateOffer(hb.addrInfo(), "wrong")
+	require.ErrorIs(t, err, errNoPrivateOffer)
+	_, err = ha.QueryPrivateOffer(hb.addrInfo(), "")
+	require.ErrorIs(t, err, errNoPrivateOffer)
+}
+
+func TestHost_Initiate_PrivateOfferWrongToken(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	handler := hb.handler.(*mockHandler)
+	handler.tokens = map[string]string{testID.String(): "secret"}
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{OfferID: testID.String(), OfferToken: "wrong"},
+		new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+
+	// the swap never started on the maker's side
+	hb.swapMu.Lock()
+	defer hb.swapMu.Unlock()
+	require.Nil(t, hb.swaps[testID])
+}
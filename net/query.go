@@ -18,7 +18,7 @@ const (
 
 func (h *host) handleQueryStream(stream libp2pnetwork.Stream) {
 	resp := &QueryResponse{
-		Offers: h.handler.GetOffers(),
+		Offers: h.signOffers(h.offersFor(stream.Conn().RemotePeer(), h.getOffers())),
 	}
 
 	if err := h.writeToStream(stream, resp); err != nil {
@@ -36,6 +36,7 @@ func (h *host) Query(who peer.AddrInfo) (*QueryResponse, error) {
 		return nil, err
 	}
 
+	start := time.Now()
 	stream, err := h.h.NewStream(ctx, who.ID, protocol.ID(h.protocolID+queryID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
@@ -49,7 +50,14 @@ func (h *host) Query(who peer.AddrInfo) (*QueryResponse, error) {
 		_ = stream.Close()
 	}()
 
-	return h.receiveQueryResponse(stream)
+	resp, err := h.receiveQueryResponse(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	h.recordLatency(who.ID, time.Since(start))
+	h.offerFeed.publish(who.ID, resp.Offers, OfferSourceQuery, time.Now())
+	return resp, nil
 }
 
 func (h *host) receiveQueryResponse(stream libp2pnetwork.Stream) (*QueryResponse, error) {
@@ -69,6 +77,12 @@ func (h *host) receiveQueryResponse(stream libp2pnetwork.Stream) (*QueryResponse
 
 	var resp *QueryResponse
 	if err := json.Unmarshal(buf[1:n], &resp); err != nil {
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return nil, err
+	}
+
+	if err := verifyOffersFrom(stream.Conn().RemotePeer(), resp.Offers); err != nil {
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
 		return nil, err
 	}
 
@@ -0,0 +1,22 @@
+//go:build !go1.18
+// +build !go1.18
+
+package net
+
+import (
+	"github.com/libp2p/go-libp2p"
+	quic "github.com/libp2p/go-libp2p-quic-transport"
+)
+
+// quicSupported is whether the host can listen on and dial QUIC addresses. The QUIC
+// implementation used by our libp2p version only builds with Go 1.17 and earlier.
+const quicSupported = true
+
+// quicTransports returns the option enabling QUIC as well as the default TCP and websocket
+// transports, which would otherwise be replaced.
+func quicTransports() libp2p.Option {
+	return libp2p.ChainOptions(
+		libp2p.DefaultTransports,
+		libp2p.Transport(quic.NewTransport),
+	)
+}
@@ -0,0 +1,17 @@
+//go:build go1.18
+// +build go1.18
+
+package net
+
+import (
+	"github.com/libp2p/go-libp2p"
+)
+
+// quicSupported is whether the host can listen on and dial QUIC addresses. The QUIC
+// implementation used by our libp2p version only builds with Go 1.17 and earlier.
+const quicSupported = false
+
+// quicTransports returns the default transports, as QUIC isn't supported.
+func quicTransports() libp2p.Option {
+	return libp2p.DefaultTransports
+}
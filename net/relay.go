@@ -0,0 +1,46 @@
+package net
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p"
+	circuit "github.com/libp2p/go-libp2p-circuit"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// relayOptions returns the libp2p options for circuit relays. We can always dial, and be dialed,
+// through a relay. If there are any candidate relays, AutoRelay connects to some of them when we
+// aren't publicly reachable, and advertises our addresses through them so that peers can still
+// query us and take our offers. The candidates are the configured relays, or else the bootnodes.
+//
+// If the relay service is enabled, we relay connections for other peers instead.
+func relayOptions(cfg *Config, bootnodes []peer.AddrInfo) ([]libp2p.Option, error) {
+	if cfg.RelayService {
+		log.Info("relaying connections for other peers")
+		return []libp2p.Option{
+			libp2p.EnableRelay(circuit.OptHop),
+		}, nil
+	}
+
+	relays := bootnodes
+	if len(cfg.Relays) != 0 {
+		var err error
+		relays, err = stringsToAddrInfos(cfg.Relays)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format relays: %w", err)
+		}
+	}
+
+	opts := []libp2p.Option{
+		libp2p.EnableRelay(),
+	}
+
+	if len(relays) != 0 {
+		opts = append(opts,
+			libp2p.EnableAutoRelay(),
+			libp2p.StaticRelays(relays),
+		)
+	}
+
+	return opts, nil
+}
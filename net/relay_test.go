@@ -0,0 +1,80 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_Relay(t *testing.T) {
+	relay, err := NewHost(&Config{
+		Ctx:          context.Background(),
+		Environment:  common.Development,
+		ChainID:      common.GanacheChainID,
+		Port:         defaultPort + 2,
+		KeyFile:      path.Join(t.TempDir(), "relay.key"),
+		Handler:      &mockHandler{},
+		RelayService: true,
+	})
+	require.NoError(t, err)
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+
+	for _, h := range []*host{relay, ha, hb} {
+		require.NoError(t, h.Start())
+	}
+	defer func() {
+		for _, h := range []*host{relay, ha, hb} {
+			_ = h.Stop()
+		}
+	}()
+
+	// hb is only reachable through the relay it's connected to
+	require.NoError(t, hb.h.Connect(hb.ctx, relay.addrInfo()))
+	circuitAddr, err := ma.NewMultiaddr(fmt.Sprintf("%s/p2p/%s/p2p-circuit", relay.h.Addrs()[0], relay.h.ID()))
+	require.NoError(t, err)
+
+	resp, err := ha.Query(peer.AddrInfo{
+		ID:    hb.h.ID(),
+		Addrs: []ma.Multiaddr{circuitAddr},
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.Offers)
+
+	// the query was relayed, though hb may since have connected to ha directly
+	relayed := false
+	for _, conn := range ha.h.Network().ConnsToPeer(hb.h.ID()) {
+		relayed = relayed || isRelayed(conn)
+	}
+	require.True(t, relayed)
+}
+
+func TestRelayOptions(t *testing.T) {
+	opts, err := relayOptions(&Config{Relays: []string{"notanaddress"}}, nil)
+	require.Error(t, err)
+	require.Nil(t, opts)
+
+	// only the relay client, as there are no candidate relays
+	opts, err = relayOptions(&Config{}, nil)
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+
+	bootnodes, err := stringsToAddrInfos([]string{
+		"/ip4/127.0.0.1/tcp/9934/p2p/12D3KooWC547RfLcveQi1vBxACjnT6Uv15V11ortDTuxRWuhubGv",
+	})
+	require.NoError(t, err)
+	opts, err = relayOptions(&Config{}, bootnodes)
+	require.NoError(t, err)
+	require.Len(t, opts, 3)
+
+	opts, err = relayOptions(&Config{RelayService: true}, bootnodes)
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+}
@@ -0,0 +1,336 @@
+package net
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/connmgr"
+	"github.com/libp2p/go-libp2p-core/control"
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// how each outcome changes a peer's reputation score
+const (
+	completedSwapScore  = 2
+	abortedSwapScore    = -1
+	refundedSwapScore   = -3
+	invalidMessageScore = -5
+)
+
+const (
+	// peers with a score below this are tried after peers with better scores
+	deprioritizeScore = 0
+	// peers whose score falls to this are banned
+	banScore = -20
+)
+
+var _ connmgr.ConnectionGater = &reputation{}
+
+// PeerScore is what we know about how a peer has behaved.
+type PeerScore struct {
+	Peer            peer.ID
+	CompletedSwaps  uint64
+	AbortedSwaps    uint64
+	RefundedSwaps   uint64
+	InvalidMessages uint64
+	Score           int64
+	Banned          bool
+	// BannedUntil is when the peer's temporary ban ends, if it has one
+	BannedUntil time.Time
+}
+
+// Deprioritized returns whether the peer's offers should be tried after those of other peers.
+func (s *PeerScore) Deprioritized() bool {
+	return s.isBanned(time.Now()) || s.Score < deprioritizeScore
+}
+
+func (s *PeerScore) isBanned(now time.Time) bool {
+	return s.Banned || now.Before(s.BannedUntil)
+}
+
+// reputation scores peers by the outcomes of our swaps with them and the invalid messages they
+// send us, and bans peers whose score falls too low. Banned peers can't connect to us, and we
+// don't connect to them.
+type reputation struct {
+	mu    sync.RWMutex
+	peers map[peer.ID]*PeerScore
+	// when the ban of each banned IP address ends; it's zero if the ban doesn't end
+	bannedIPs map[string]time.Time
+	// called when a peer or IP address is banned, to disconnect from it
+	onBan   func(peer.ID)
+	onBanIP func(ip string)
+}
+
+func newReputation() *reputation {
+	return &reputation{
+		peers:     make(map[peer.ID]*PeerScore),
+		bannedIPs: make(map[string]time.Time),
+	}
+}
+
+// recordSwapOutcome updates the peer's score with the outcome of a completed swap.
+func (r *reputation) recordSwapOutcome(who peer.ID, status types.Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	score := r.getOrCreate(who)
+	switch status {
+	case types.CompletedSuccess:
+		score.CompletedSwaps++
+		score.Score += completedSwapScore
+	case types.CompletedAbort:
+		score.AbortedSwaps++
+		score.Score += abortedSwapScore
+	case types.CompletedRefund:
+		score.RefundedSwaps++
+		score.Score += refundedSwapScore
+	default:
+		return
+	}
+
+	r.checkBan(score)
+}
+
+// recordInvalidMessage lowers the peer's score for sending us a message we couldn't handle.
+func (r *reputation) recordInvalidMessage(who peer.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	score := r.getOrCreate(who)
+	score.InvalidMessages++
+	score.Score += invalidMessageScore
+	r.checkBan(score)
+}
+
+func (r *reputation) getOrCreate(who peer.ID) *PeerScore {
+	score, has := r.peers[who]
+	if !has {
+		score = &PeerScore{Peer: who}
+		r.peers[who] = score
+	}
+	return score
+}
+
+// checkBan bans the peer if its score has fallen too low. It must be called with the lock held.
+func (r *reputation) checkBan(score *PeerScore) {
+	if score.Banned || score.Score > banScore {
+		return
+	}
+
+	log.Infof("banning peer %s with reputation score %d", score.Peer, score.Score)
+	score.Banned = true
+	if r.onBan != nil {
+		go r.onBan(score.Peer)
+	}
+}
+
+// tempBan bans the peer until the given time, unless it's already banned for longer.
+func (r *reputation) tempBan(who peer.ID, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	score := r.getOrCreate(who)
+	if score.Banned || !until.After(score.BannedUntil) {
+		return
+	}
+
+	log.Infof("banning peer %s until %s", who, until.Format(time.RFC3339))
+	score.BannedUntil = until
+	if r.onBan != nil {
+		go r.onBan(who)
+	}
+}
+
+// ban bans the peer until the given time, or until it's unbanned if the time is zero. Unlike
+// tempBan, it replaces any ban the peer already has.
+func (r *reputation) ban(who peer.ID, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	score := r.getOrCreate(who)
+	score.Banned = until.IsZero()
+	score.BannedUntil = until
+	if !score.isBanned(time.Now()) {
+		return
+	}
+
+	log.Infof("banning peer %s %s", who, banDescription(until))
+	if r.onBan != nil {
+		go r.onBan(who)
+	}
+}
+
+// unban lifts the peer's ban, returning false if it isn't banned. The peer's score is kept, so a
+// peer that was banned for its score is banned again if the score falls any further.
+func (r *reputation) unban(who peer.ID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	score, has := r.peers[who]
+	if !has || !score.isBanned(time.Now()) {
+		return false
+	}
+
+	log.Infof("unbanning peer %s", who)
+	score.Banned = false
+	score.BannedUntil = time.Time{}
+	return true
+}
+
+// tempBanIP stops peers connecting to us from the IP address until the given time, unless it's
+// already banned for longer.
+func (r *reputation) tempBanIP(ip string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if end, has := r.bannedIPs[ip]; has && (end.IsZero() || !until.After(end)) {
+		return
+	}
+
+	r.setIPBan(ip, until)
+}
+
+// banIP stops us connecting to or from the IP address until the given time, or until it's unbanned
+// if the time is zero. Unlike tempBanIP, it replaces any ban the IP address already has.
+func (r *reputation) banIP(ip string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !until.IsZero() && !time.Now().Before(until) {
+		delete(r.bannedIPs, ip)
+		return
+	}
+
+	r.setIPBan(ip, until)
+	if r.onBanIP != nil {
+		go r.onBanIP(ip)
+	}
+}
+
+// setIPBan bans the IP address until the given time. It must be called with the lock held.
+func (r *reputation) setIPBan(ip string, until time.Time) {
+	// forget bans that have ended, so that the map doesn't grow forever
+	now := time.Now()
+	for banned, end := range r.bannedIPs {
+		if !end.IsZero() && !now.Before(end) {
+			delete(r.bannedIPs, banned)
+		}
+	}
+
+	log.Infof("banning IP address %s %s", ip, banDescription(until))
+	r.bannedIPs[ip] = until
+}
+
+// unbanIP lifts the IP address's ban, returning false if it isn't banned.
+func (r *reputation) unbanIP(ip string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isIPBannedLocked(ip) {
+		return false
+	}
+
+	log.Infof("unbanning IP address %s", ip)
+	delete(r.bannedIPs, ip)
+	return true
+}
+
+func (r *reputation) isIPBanned(ip string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.isIPBannedLocked(ip)
+}
+
+func (r *reputation) isIPBannedLocked(ip string) bool {
+	until, has := r.bannedIPs[ip]
+	return has && (until.IsZero() || time.Now().Before(until))
+}
+
+// isAddrBanned returns whether the address is at a banned IP address.
+func (r *reputation) isAddrBanned(addr ma.Multiaddr) bool {
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+
+	return r.isIPBanned(ip.String())
+}
+
+func banDescription(until time.Time) string {
+	if until.IsZero() {
+		return "until it's unbanned"
+	}
+
+	return "until " + until.Format(time.RFC3339)
+}
+
+// score returns the peer's score, which is empty if we know nothing about the peer.
+func (r *reputation) score(who peer.ID) *PeerScore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	score, has := r.peers[who]
+	if !has {
+		return &PeerScore{Peer: who}
+	}
+
+	s := *score
+	return &s
+}
+
+// scores returns the scores of all the peers we know about, sorted by peer ID.
+func (r *reputation) scores() []*PeerScore {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := make([]*PeerScore, 0, len(r.peers))
+	for _, score := range r.peers {
+		s := *score
+		scores = append(scores, &s)
+	}
+
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].Peer < scores[j].Peer
+	})
+	return scores
+}
+
+func (r *reputation) isBanned(who peer.ID) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	score, has := r.peers[who]
+	return has && score.isBanned(time.Now())
+}
+
+// InterceptPeerDial prevents us from dialing banned peers.
+func (r *reputation) InterceptPeerDial(p peer.ID) bool {
+	return !r.isBanned(p)
+}
+
+// InterceptAddrDial prevents us from dialing banned peers, and banned IP addresses.
+func (r *reputation) InterceptAddrDial(p peer.ID, addr ma.Multiaddr) bool {
+	return !r.isBanned(p) && !r.isAddrBanned(addr)
+}
+
+// InterceptAccept rejects inbound connections from banned IP addresses. The peer isn't known
+// until the connection is secured.
+func (r *reputation) InterceptAccept(addrs libp2pnetwork.ConnMultiaddrs) bool {
+	return !r.isAddrBanned(addrs.RemoteMultiaddr())
+}
+
+// InterceptSecured rejects connections with banned peers.
+func (r *reputation) InterceptSecured(_ libp2pnetwork.Direction, p peer.ID, _ libp2pnetwork.ConnMultiaddrs) bool {
+	return !r.isBanned(p)
+}
+
+// InterceptUpgraded allows all connections that were secured.
+func (r *reputation) InterceptUpgraded(_ libp2pnetwork.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
@@ -0,0 +1,219 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReputation_Score(t *testing.T) {
+	r := newReputation()
+	peerA := peer.ID("a")
+
+	r.recordSwapOutcome(peerA, types.CompletedSuccess)
+	r.recordSwapOutcome(peerA, types.CompletedAbort)
+	r.recordSwapOutcome(peerA, types.XMRLocked)
+	score := r.score(peerA)
+	require.Equal(t, uint64(1), score.CompletedSwaps)
+	require.Equal(t, uint64(1), score.AbortedSwaps)
+	require.Equal(t, int64(completedSwapScore+abortedSwapScore), score.Score)
+	require.False(t, score.Deprioritized())
+
+	r.recordSwapOutcome(peerA, types.CompletedRefund)
+	require.True(t, r.score(peerA).Deprioritized())
+	require.False(t, r.isBanned(peerA))
+
+	// unknown peers have an empty score
+	require.Equal(t, &PeerScore{Peer: peer.ID("b")}, r.score(peer.ID("b")))
+	require.Len(t, r.scores(), 1)
+}
+
+func TestReputation_Ban(t *testing.T) {
+	r := newReputation()
+	peerA := peer.ID("a")
+	banned := make(chan peer.ID, 1)
+	r.onBan = func(who peer.ID) {
+		banned <- who
+	}
+
+	for i := 0; i < banScore/invalidMessageScore; i++ {
+		require.True(t, r.InterceptPeerDial(peerA))
+		r.recordInvalidMessage(peerA)
+	}
+
+	require.Equal(t, peerA, <-banned)
+	require.True(t, r.score(peerA).Banned)
+	require.False(t, r.InterceptPeerDial(peerA))
+	require.False(t, r.InterceptSecured(0, peerA, nil))
+}
+
+func TestHost_BannedPeer(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+	ha.handler = &mockHandler{}
+	hb.handler = &mockHandler{}
+
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	require.NoError(t, hb.h.Connect(hb.ctx, ha.addrInfo()))
+
+	for i := 0; i < banScore/invalidMessageScore; i++ {
+		ha.reputation.recordInvalidMessage(hb.h.ID())
+	}
+	require.True(t, ha.PeerScore(hb.h.ID()).Banned)
+
+	// neither side can connect once the peer is banned
+	require.Eventually(t, func() bool {
+		return len(ha.h.Network().ConnsToPeer(hb.h.ID())) == 0
+	}, 5*time.Second, 100*time.Millisecond)
+	require.Error(t, ha.h.Connect(ha.ctx, hb.addrInfo()))
+	require.Error(t, hb.h.Connect(hb.ctx, ha.addrInfo()))
+}
+
+func TestHost_BanIP(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+	ha.handler = &mockHandler{}
+	hb.handler = &mockHandler{}
+
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	require.NoError(t, hb.h.Connect(hb.ctx, ha.addrInfo()))
+	conns := ha.h.Network().ConnsToPeer(hb.h.ID())
+	require.NotEmpty(t, conns)
+	ip, err := manet.ToIP(conns[0].RemoteMultiaddr())
+	require.NoError(t, err)
+
+	require.Error(t, ha.BanIP("localhost", 0))
+	require.NoError(t, ha.BanIP(ip.String(), 0))
+
+	// our connections at the banned IP address are closed
+	require.Eventually(t, func() bool {
+		for _, conn := range ha.h.Network().ConnsToPeer(hb.h.ID()) {
+			if connIP, err := manet.ToIP(conn.RemoteMultiaddr()); err == nil && connIP.Equal(ip) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 100*time.Millisecond)
+
+	require.NoError(t, ha.UnbanIP(ip.String()))
+	require.Equal(t, errIPNotBanned, ha.UnbanIP(ip.String()))
+}
+
+func TestReputation_TempBan(t *testing.T) {
+	r := newReputation()
+	peerA := peer.ID("a")
+	banned := make(chan peer.ID, 1)
+	r.onBan = func(who peer.ID) {
+		banned <- who
+	}
+
+	r.tempBan(peerA, time.Now().Add(time.Hour))
+	require.Equal(t, peerA, <-banned)
+	require.True(t, r.isBanned(peerA))
+	require.True(t, r.score(peerA).Deprioritized())
+	require.False(t, r.score(peerA).Banned)
+	require.False(t, r.InterceptPeerDial(peerA))
+
+	// an earlier end doesn't shorten the ban
+	r.tempBan(peerA, time.Now())
+	require.True(t, r.isBanned(peerA))
+
+	peerB := peer.ID("b")
+	r.tempBan(peerB, time.Now().Add(-time.Second))
+	require.False(t, r.isBanned(peerB))
+}
+
+func TestReputation_TempBanIP(t *testing.T) {
+	r := newReputation()
+	r.tempBanIP("1.2.3.4", time.Now().Add(time.Hour))
+	r.tempBanIP("1.2.3.5", time.Now().Add(-time.Second))
+
+	banned, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/9900")
+	require.NoError(t, err)
+	notBanned, err := ma.NewMultiaddr("/ip4/1.2.3.5/tcp/9900")
+	require.NoError(t, err)
+
+	require.False(t, r.InterceptAccept(&mockConnAddrs{remote: banned}))
+	require.True(t, r.InterceptAccept(&mockConnAddrs{remote: notBanned}))
+}
+
+func TestReputation_ManualBan(t *testing.T) {
+	r := newReputation()
+	peerA := peer.ID("a")
+	banned := make(chan peer.ID, 1)
+	r.onBan = func(who peer.ID) {
+		banned <- who
+	}
+
+	r.ban(peerA, time.Time{})
+	require.Equal(t, peerA, <-banned)
+	require.True(t, r.score(peerA).Banned)
+	require.False(t, r.InterceptPeerDial(peerA))
+
+	// a manual ban replaces the peer's ban, even with an earlier end
+	r.ban(peerA, time.Now().Add(time.Hour))
+	<-banned
+	require.False(t, r.score(peerA).Banned)
+	require.True(t, r.isBanned(peerA))
+
+	require.True(t, r.unban(peerA))
+	require.False(t, r.isBanned(peerA))
+	require.True(t, r.InterceptPeerDial(peerA))
+	require.False(t, r.unban(peerA))
+	require.False(t, r.unban(peer.ID("b")))
+}
+
+func TestReputation_ManualBanIP(t *testing.T) {
+	r := newReputation()
+	bannedIPs := make(chan string, 1)
+	r.onBanIP = func(ip string) {
+		bannedIPs <- ip
+	}
+
+	addr, err := ma.NewMultiaddr("/ip4/1.2.3.4/tcp/9900")
+	require.NoError(t, err)
+
+	r.banIP("1.2.3.4", time.Time{})
+	require.Equal(t, "1.2.3.4", <-bannedIPs)
+	require.False(t, r.InterceptAccept(&mockConnAddrs{remote: addr}))
+	require.False(t, r.InterceptAddrDial(peer.ID("a"), addr))
+
+	// temporary bans don't shorten the ban
+	r.tempBanIP("1.2.3.4", time.Now().Add(time.Second))
+	require.True(t, r.isIPBanned("1.2.3.4"))
+
+	require.True(t, r.unbanIP("1.2.3.4"))
+	require.True(t, r.InterceptAccept(&mockConnAddrs{remote: addr}))
+	require.True(t, r.InterceptAddrDial(peer.ID("a"), addr))
+	require.False(t, r.unbanIP("1.2.3.4"))
+}
+
+type mockConnAddrs struct {
+	remote ma.Multiaddr
+}
+
+func (m *mockConnAddrs) LocalMultiaddr() ma.Multiaddr {
+	return nil
+}
+
+func (m *mockConnAddrs) RemoteMultiaddr() ma.Multiaddr {
+	return m.remote
+}
@@ -0,0 +1,104 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+const (
+	rfqID      = "/rfq/0"
+	rfqTimeout = time.Second * 5
+)
+
+func (h *host) handleRFQStream(stream libp2pnetwork.Stream) {
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	buf := make([]byte, 1024)
+	n, err := readStream(stream, buf)
+	if err != nil {
+		log.Debugf("failed to read QuoteRequest from peer: err=%s", err)
+		return
+	}
+
+	msg, err := message.DecodeMessage(buf[:n])
+	if err != nil {
+		log.Debugf("failed to decode QuoteRequest from peer: err=%s", err)
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return
+	}
+
+	req, ok := msg.(*QuoteRequest)
+	if !ok {
+		log.Debugf("peer sent %s on RFQ stream, expected QuoteRequest", msg.Type())
+		h.reputation.recordInvalidMessage(stream.Conn().RemotePeer())
+		return
+	}
+
+	if !h.mayTake(stream.Conn().RemotePeer(), req.OfferID) {
+		log.Infof("not quoting offer %s for peer %s: peer may not take it", req.OfferID, stream.Conn().RemotePeer())
+		return
+	}
+
+	quote, err := h.handler.HandleQuoteRequest(req)
+	if err != nil {
+		// the stream is closed without a quote, which the peer sees as the request being rejected
+		log.Infof("not quoting offer %s for peer %s: %s", req.OfferID, stream.Conn().RemotePeer(), err)
+		return
+	}
+
+	if err := h.writeToStream(stream, quote); err != nil {
+		log.Warnf("failed to send Quote message to peer: err=%s", err)
+	}
+}
+
+// RequestQuote asks the peer for a firm quote on taking one of its offers.
+func (h *host) RequestQuote(who peer.AddrInfo, req *QuoteRequest) (*Quote, error) {
+	ctx, cancel := context.WithTimeout(h.ctx, rfqTimeout)
+	defer cancel()
+
+	if err := h.h.Connect(ctx, who); err != nil {
+		return nil, err
+	}
+
+	stream, err := h.h.NewStream(ctx, who.ID, protocol.ID(h.protocolID+rfqID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stream with peer: err=%w", err)
+	}
+
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	if err = h.writeToStream(stream, req); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 1024)
+	n, err := readStream(stream, buf)
+	if err != nil || n == 0 {
+		return nil, errQuoteRejected
+	}
+
+	msg, err := message.DecodeMessage(buf[:n])
+	if err != nil {
+		h.reputation.recordInvalidMessage(who.ID)
+		return nil, err
+	}
+
+	quote, ok := msg.(*Quote)
+	if !ok {
+		h.reputation.recordInvalidMessage(who.ID)
+		return nil, fmt.Errorf("expected Quote message, got %s", msg.Type())
+	}
+
+	return quote, nil
+}
@@ -0,0 +1,42 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHost_RequestQuote(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	quote, err := ha.RequestQuote(hb.addrInfo(), &QuoteRequest{
+		OfferID:        testID.String(),
+		ProvidedAmount: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, types.Hash{1}, quote.ID)
+	require.Equal(t, testID.String(), quote.OfferID)
+	require.Equal(t, float64(1), quote.ProvidedAmount)
+	require.Equal(t, types.ExchangeRate(0.1), quote.ExchangeRate)
+
+	_, err = ha.RequestQuote(hb.addrInfo(), &QuoteRequest{
+		OfferID:        types.Hash{2}.String(),
+		ProvidedAmount: 1,
+	})
+	require.ErrorIs(t, err, errQuoteRejected)
+}
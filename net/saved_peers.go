@@ -0,0 +1,145 @@
+package net
+
+import (
+	"sort"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+)
+
+const (
+	// how often the peers we're connected to are saved
+	savePeersInterval = time.Minute * 5
+	// how long a peer is kept after we were last connected to it
+	savedPeerTTL = time.Hour * 24 * 7
+	// most peers that are kept
+	maxSavedPeers = 100
+)
+
+// SavedPeer is a peer we've been connected to, saved so that we can reconnect to it after a
+// restart.
+type SavedPeer struct {
+	Peer     peer.AddrInfo
+	LastSeen time.Time
+}
+
+// PeerStore persists the peers we've been connected to.
+type PeerStore interface {
+	// SavePeers replaces the saved peers with the given peers
+	SavePeers(peers []*SavedPeer) error
+	LoadPeers() ([]*SavedPeer, error)
+}
+
+// loadSavedPeers loads the peers saved before we last stopped, so that we can reconnect to them
+// as well as to the bootnodes.
+func (h *host) loadSavedPeers(now time.Time) error {
+	if h.peerStore == nil {
+		return nil
+	}
+
+	peers, err := h.peerStore.LoadPeers()
+	if err != nil {
+		return err
+	}
+
+	h.savedPeersMu.Lock()
+	defer h.savedPeersMu.Unlock()
+
+	for _, p := range peers {
+		if p.Peer.ID == h.h.ID() || now.Sub(p.LastSeen) > savedPeerTTL {
+			continue
+		}
+
+		h.savedPeers[p.Peer.ID] = p
+		h.h.Peerstore().AddAddrs(p.Peer.ID, p.Peer.Addrs, peerstore.RecentlyConnectedAddrTTL)
+	}
+
+	log.Debugf("loaded %d saved peers", len(h.savedPeers))
+	return nil
+}
+
+// savedPeerAddrs returns the saved peers that aren't bootnodes.
+func (h *host) savedPeerAddrs() []peer.AddrInfo {
+	bootnodes := make(map[peer.ID]struct{}, len(h.bootnodes))
+	for _, bn := range h.bootnodes {
+		bootnodes[bn.ID] = struct{}{}
+	}
+
+	h.savedPeersMu.Lock()
+	defer h.savedPeersMu.Unlock()
+
+	addrs := make([]peer.AddrInfo, 0, len(h.savedPeers))
+	for id, p := range h.savedPeers {
+		if _, is := bootnodes[id]; !is {
+			addrs = append(addrs, p.Peer)
+		}
+	}
+	return addrs
+}
+
+// savePeers saves the peers we're connected to, along with those saved earlier that we've been
+// connected to recently. The most recently seen peers are kept.
+func (h *host) savePeers(now time.Time) error {
+	if h.peerStore == nil {
+		return nil
+	}
+
+	h.savedPeersMu.Lock()
+	defer h.savedPeersMu.Unlock()
+
+	for _, id := range h.h.Network().Peers() {
+		if h.reputation.isBanned(id) {
+			continue
+		}
+
+		info := h.h.Peerstore().PeerInfo(id)
+		if len(info.Addrs) == 0 {
+			continue
+		}
+
+		h.savedPeers[id] = &SavedPeer{
+			Peer:     info,
+			LastSeen: now,
+		}
+	}
+
+	peers := make([]*SavedPeer, 0, len(h.savedPeers))
+	for id, p := range h.savedPeers {
+		if now.Sub(p.LastSeen) > savedPeerTTL || h.reputation.isBanned(id) {
+			delete(h.savedPeers, id)
+			continue
+		}
+		peers = append(peers, p)
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		return peers[i].LastSeen.After(peers[j].LastSeen)
+	})
+
+	if len(peers) > maxSavedPeers {
+		for _, p := range peers[maxSavedPeers:] {
+			delete(h.savedPeers, p.Peer.ID)
+		}
+		peers = peers[:maxSavedPeers]
+	}
+
+	return h.peerStore.SavePeers(peers)
+}
+
+// persistPeers periodically saves the peers we're connected to.
+func (h *host) persistPeers() {
+	ticker := time.NewTicker(savePeersInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := h.savePeers(time.Now()); err != nil {
+				log.Warnf("failed to save peers: %s", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package net
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPeerStore struct {
+	peers []*SavedPeer
+}
+
+func (s *mockPeerStore) SavePeers(peers []*SavedPeer) error {
+	s.peers = peers
+	return nil
+}
+
+func (s *mockPeerStore) LoadPeers() ([]*SavedPeer, error) {
+	return s.peers, nil
+}
+
+func newHostWithPeerStore(t *testing.T, port uint16, keyFile string, store PeerStore) *host {
+	h, err := NewHost(&Config{
+		Ctx:         context.Background(),
+		Environment: common.Development,
+		ChainID:     common.GanacheChainID,
+		Port:        port,
+		KeyFile:     keyFile,
+		Handler:     &mockHandler{},
+		PeerStore:   store,
+	})
+	require.NoError(t, err)
+	return h
+}
+
+func TestHost_SavedPeers(t *testing.T) {
+	store := &mockPeerStore{}
+	keyFile := path.Join(t.TempDir(), "node.key")
+	ha := newHostWithPeerStore(t, defaultPort, keyFile, store)
+	hb := newHost(t, defaultPort+1)
+
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+	defer func() {
+		_ = hb.Stop()
+	}()
+
+	require.NoError(t, ha.h.Connect(ha.ctx, hb.addrInfo()))
+	require.NoError(t, ha.Stop())
+	require.Len(t, store.peers, 1)
+	require.Equal(t, hb.h.ID(), store.peers[0].Peer.ID)
+
+	// after restarting, ha reconnects to hb without any bootnodes
+	ha = newHostWithPeerStore(t, defaultPort, keyFile, store)
+	require.NoError(t, ha.Start())
+	defer func() {
+		_ = ha.Stop()
+	}()
+	require.NotEmpty(t, ha.h.Network().ConnsToPeer(hb.h.ID()))
+}
+
+func TestHost_SavePeers_Expiry(t *testing.T) {
+	now := time.Now()
+	stale := &SavedPeer{
+		Peer:     peer.AddrInfo{ID: "stale"},
+		LastSeen: now.Add(-savedPeerTTL - time.Minute),
+	}
+	recent := &SavedPeer{
+		Peer:     peer.AddrInfo{ID: "recent"},
+		LastSeen: now.Add(-time.Hour),
+	}
+
+	store := &mockPeerStore{peers: []*SavedPeer{stale, recent}}
+	h := newHostWithPeerStore(t, defaultPort, path.Join(t.TempDir(), "node.key"), store)
+	defer func() {
+		_ = h.Stop()
+	}()
+	require.NoError(t, h.loadSavedPeers(now))
+	require.Len(t, h.savedPeers, 1)
+
+	require.NoError(t, h.savePeers(now))
+	require.Equal(t, []*SavedPeer{recent}, store.peers)
+}
@@ -0,0 +1,223 @@
+package net
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+)
+
+// session authenticates a swap's messages. Each peer sends a fresh session key in its
+// SendKeysMessage, and signs all of its later messages on the swap with it, so that a hijacked or
+// spoofed stream can't inject messages such as a fake contract address or ready signal. The
+// session key is itself signed with the secp256k1 key in the SendKeysMessage, which the DLEq proof
+// binds to the sender's swap keys, so that it can't be replaced without replacing those too. Peers
+// that predate session keys don't send one, and their messages aren't signed.
+type session struct {
+	key       crypto.PrivKey
+	publicKey []byte
+
+	// requirePeerKey is set if the peer's Hello advertised session keys, in which case its
+	// SendKeysMessage must carry one
+	requirePeerKey bool
+
+	// mu is held while a message is signed and written, so that messages are written in the
+	// order of their nonces
+	mu       sync.Mutex
+	peerKey  crypto.PubKey
+	sent     uint64
+	received uint64
+}
+
+func newSession() (*session, error) {
+	key, pub, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	pubBytes, err := crypto.MarshalPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session{
+		key:       key,
+		publicKey: pubBytes,
+	}, nil
+}
+
+// sessionKey returns our session key, encoded for a SendKeysMessage.
+func (s *session) sessionKey() string {
+	return hex.EncodeToString(s.publicKey)
+}
+
+// established returns whether the peer has sent us its session key, after which all of the
+// swap's messages are signed.
+func (s *session) established() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peerKey != nil
+}
+
+// signSessionKey sets our session key in the SendKeysMessage, signed by the swap state. Swap
+// states that can't sign it don't send one.
+func (s *session) signSessionKey(swapState SwapState, msg *SendKeysMessage) error {
+	signer, ok := swapState.(SessionKeySigner)
+	if !ok {
+		return nil
+	}
+
+	sig, err := signer.SignSessionKey(s.publicKey)
+	if err != nil {
+		return err
+	}
+
+	msg.SessionKey = s.sessionKey()
+	msg.SessionKeySignature = hex.EncodeToString(sig)
+	return nil
+}
+
+// setPeerKey sets the session key the peer sent in its SendKeysMessage, if it sent one, after
+// checking that it's signed by the message's secp256k1 key.
+func (s *session) setPeerKey(msg *SendKeysMessage) error {
+	if msg.SessionKey == "" {
+		if s.requirePeerKey {
+			return errNoSessionKey
+		}
+		return nil
+	}
+
+	b, err := hex.DecodeString(msg.SessionKey)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidSessionKey, err)
+	}
+
+	if err = verifySessionKey(msg, b); err != nil {
+		return err
+	}
+
+	key, err := crypto.UnmarshalPublicKey(b)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidSessionKey, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peerKey = key
+	return nil
+}
+
+// verifySessionKey checks that the session key is signed by the SendKeysMessage's secp256k1 key.
+// The swap checks that key against the DLEq proof when it handles the message.
+func verifySessionKey(msg *SendKeysMessage, key []byte) error {
+	pub, err := hex.DecodeString(msg.Secp256k1PublicKey)
+	if err != nil || len(pub) != 64 {
+		return fmt.Errorf("%w: invalid secp256k1 public key", errInvalidSessionKey)
+	}
+
+	sig, err := hex.DecodeString(msg.SessionKeySignature)
+	if err != nil || len(sig) != 65 {
+		return fmt.Errorf("%w: missing or malformed signature", errInvalidSessionKey)
+	}
+
+	// the public key is sent as x || y, without the uncompressed point prefix
+	pub = append([]byte{4}, pub...)
+	if !ethcrypto.VerifySignature(pub, message.SessionKeyDigest(key), sig[:64]) {
+		return fmt.Errorf("%w: invalid signature", errInvalidSessionKey)
+	}
+
+	return nil
+}
+
+// writeSwapMessage writes the swap message to the stream, signed if the peer sent us its session
+// key.
+func (h *host) writeSwapMessage(stream libp2pnetwork.Stream, s *session, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	signed, err := s.sign(stream, msg)
+	if err != nil {
+		return err
+	}
+
+	return h.writeToStream(stream, signed)
+}
+
+// sign returns the message signed with our session key, if the peer sent us its session key. The
+// message is encoded as it would be on the stream. SendKeysMessages aren't signed, as they carry
+// the session keys. It must be called with the lock held.
+func (s *session) sign(stream libp2pnetwork.Stream, msg Message) (Message, error) {
+	if s.peerKey == nil || msg.Type() == message.SendKeysType {
+		return msg, nil
+	}
+
+	encoded, err := encodeMessage(stream, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	peerKey, err := crypto.MarshalPublicKey(s.peerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := &message.Signed{
+		Nonce:   s.sent,
+		Message: encoded,
+	}
+
+	signed.Signature, err = s.key.Sign(signed.SigningBytes(peerKey))
+	if err != nil {
+		return nil, err
+	}
+
+	s.sent++
+	return signed, nil
+}
+
+// open returns the swap message that was received, checking that it's signed with the peer's
+// session key if the peer sent us one. The peer's SendKeysMessage sets its session key.
+func (s *session) open(msg Message) (Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	signed, ok := msg.(*message.Signed)
+	if !ok {
+		if s.peerKey != nil {
+			return nil, fmt.Errorf("%w: got unsigned %s", errInvalidSignedMessage, msg.Type())
+		}
+
+		return msg, nil
+	}
+
+	if s.peerKey == nil {
+		return nil, fmt.Errorf("%w: peer hasn't sent a session key", errInvalidSignedMessage)
+	}
+
+	if signed.Nonce != s.received {
+		return nil, fmt.Errorf("%w: expected nonce %d, got %d", errInvalidSignedMessage, s.received, signed.Nonce)
+	}
+
+	valid, err := s.peerKey.Verify(signed.SigningBytes(s.publicKey), signed.Signature)
+	if err != nil || !valid {
+		return nil, fmt.Errorf("%w: invalid signature", errInvalidSignedMessage)
+	}
+
+	inner, err := signed.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	if inner.Type() == message.SendKeysType {
+		return nil, fmt.Errorf("%w: SendKeysMessage can't be signed", errInvalidSignedMessage)
+	}
+
+	s.received++
+	return inner, nil
+}
@@ -0,0 +1,252 @@
+package net
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// signingSwapState signs its session key with a secp256k1 key, as the protocol's swap states do
+// with the key of their DLEq proof.
+type signingSwapState struct {
+	mockSwapState
+	key *ecdsa.PrivateKey
+}
+
+func (s *signingSwapState) SignSessionKey(key []byte) ([]byte, error) {
+	return ethcrypto.Sign(message.SessionKeyDigest(key), s.key)
+}
+
+// secp256k1PublicKey returns the public key encoded as in a SendKeysMessage.
+func secp256k1PublicKey(key *ecdsa.PrivateKey) string {
+	return hex.EncodeToString(ethcrypto.FromECDSAPub(&key.PublicKey)[1:])
+}
+
+// sendKeys returns a SendKeysMessage carrying the session's key, signed by a new secp256k1 key.
+func sendKeys(t *testing.T, s *session) *SendKeysMessage {
+	key, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	msg := &SendKeysMessage{Secp256k1PublicKey: secp256k1PublicKey(key)}
+	require.NoError(t, s.signSessionKey(&signingSwapState{key: key}, msg))
+	return msg
+}
+
+func newSessions(t *testing.T) (*session, *session) {
+	a, err := newSession()
+	require.NoError(t, err)
+	b, err := newSession()
+	require.NoError(t, err)
+
+	require.NoError(t, a.setPeerKey(sendKeys(t, b)))
+	require.NoError(t, b.setPeerKey(sendKeys(t, a)))
+	return a, b
+}
+
+// transmit signs the message with one session and opens it with the other, encoding and decoding
+// it as if it were sent over a stream.
+func transmit(t *testing.T, from, to *session, msg Message) (Message, error) {
+	from.mu.Lock()
+	signed, err := from.sign(nil, msg)
+	from.mu.Unlock()
+	require.NoError(t, err)
+
+	encoded, err := signed.Encode()
+	require.NoError(t, err)
+	decoded, err := message.DecodeMessage(encoded)
+	require.NoError(t, err)
+	return to.open(decoded)
+}
+
+func TestSession(t *testing.T) {
+	a, b := newSessions(t)
+
+	msg := &message.NotifyETHLocked{Address: "0xabcd"}
+	received, err := transmit(t, a, b, msg)
+	require.NoError(t, err)
+	require.Equal(t, msg, received)
+
+	received, err = transmit(t, b, a, &message.NotifyReady{})
+	require.NoError(t, err)
+	require.Equal(t, &message.NotifyReady{}, received)
+
+	// the SendKeysMessages carry the session keys, so they aren't signed
+	a.mu.Lock()
+	keys, err := a.sign(nil, &SendKeysMessage{})
+	a.mu.Unlock()
+	require.NoError(t, err)
+	require.Equal(t, &SendKeysMessage{}, keys)
+
+	// once the peer's session key is known, unsigned messages are rejected
+	_, err = b.open(&message.NotifyReady{})
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+	_, err = b.open(&SendKeysMessage{})
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+}
+
+func TestSession_Replay(t *testing.T) {
+	a, b := newSessions(t)
+
+	a.mu.Lock()
+	signed, err := a.sign(nil, &message.NotifyReady{})
+	a.mu.Unlock()
+	require.NoError(t, err)
+
+	_, err = b.open(signed)
+	require.NoError(t, err)
+	_, err = b.open(signed)
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+
+	// nor can it be replayed on another swap with the same peer
+	_, c := newSessions(t)
+	require.NoError(t, c.setPeerKey(sendKeys(t, a)))
+	signed.(*message.Signed).Nonce = 0
+	_, err = c.open(signed)
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+}
+
+func TestSession_Spoofed(t *testing.T) {
+	a, b := newSessions(t)
+	mallory, _ := newSessions(t)
+	require.NoError(t, mallory.setPeerKey(sendKeys(t, b)))
+
+	_, err := transmit(t, mallory, b, &message.NotifyETHLocked{Address: "0xbad"})
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+
+	a.mu.Lock()
+	signed, err := a.sign(nil, &message.NotifyETHLocked{Address: "0xabcd"})
+	a.mu.Unlock()
+	require.NoError(t, err)
+
+	tampered, err := (&message.NotifyETHLocked{Address: "0xbad"}).Encode()
+	require.NoError(t, err)
+	signed.(*message.Signed).Message = tampered
+	_, err = b.open(signed)
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+}
+
+func TestSession_NoPeerKey(t *testing.T) {
+	a, err := newSession()
+	require.NoError(t, err)
+
+	// peers that predate session keys send unsigned messages
+	require.NoError(t, a.setPeerKey(&SendKeysMessage{}))
+	msg, err := a.open(&message.NotifyReady{})
+	require.NoError(t, err)
+	require.Equal(t, &message.NotifyReady{}, msg)
+
+	a.mu.Lock()
+	signed, err := a.sign(nil, &message.NotifyReady{})
+	a.mu.Unlock()
+	require.NoError(t, err)
+	require.Equal(t, &message.NotifyReady{}, signed)
+
+	_, err = a.open(&message.Signed{})
+	require.ErrorIs(t, err, errInvalidSignedMessage)
+
+	require.ErrorIs(t, a.setPeerKey(&SendKeysMessage{SessionKey: "zz"}), errInvalidSessionKey)
+	require.ErrorIs(t, a.setPeerKey(&SendKeysMessage{SessionKey: "abcd"}), errInvalidSessionKey)
+}
+
+func TestSession_PeerKeySignature(t *testing.T) {
+	a, err := newSession()
+	require.NoError(t, err)
+	b, err := newSession()
+	require.NoError(t, err)
+
+	// the session key must be signed by the message's secp256k1 key
+	msg := sendKeys(t, b)
+	unsigned := &SendKeysMessage{
+		Secp256k1PublicKey: msg.Secp256k1PublicKey,
+		SessionKey:         msg.SessionKey,
+	}
+	require.ErrorIs(t, a.setPeerKey(unsigned), errInvalidSessionKey)
+
+	// a session key can't be swapped in under someone else's signature
+	mallory, err := newSession()
+	require.NoError(t, err)
+	replaced := *msg
+	replaced.SessionKey = mallory.sessionKey()
+	require.ErrorIs(t, a.setPeerKey(&replaced), errInvalidSessionKey)
+
+	other := sendKeys(t, b)
+	resigned := *msg
+	resigned.SessionKeySignature = other.SessionKeySignature
+	require.ErrorIs(t, a.setPeerKey(&resigned), errInvalidSessionKey)
+
+	require.NoError(t, a.setPeerKey(msg))
+	require.True(t, a.established())
+}
+
+func TestSession_PeerKeyRequired(t *testing.T) {
+	a, err := newSession()
+	require.NoError(t, err)
+
+	// a peer that advertised session keys may not leave them out
+	a.requirePeerKey = true
+	require.ErrorIs(t, a.setPeerKey(&SendKeysMessage{}), errNoSessionKey)
+	require.False(t, a.established())
+}
+
+type recordingSwapState struct {
+	signingSwapState
+	received chan Message
+}
+
+func (s *recordingSwapState) HandleProtocolMessage(msg Message) (resp Message, done bool, err error) {
+	s.received <- msg
+	return nil, false, nil
+}
+
+func TestHost_SendSwapMessage_Signed(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	makerKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	hb.handler.(*mockHandler).key = makerKey
+
+	takerKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+	s := &recordingSwapState{
+		signingSwapState: signingSwapState{key: takerKey},
+		received:         make(chan Message, 2),
+	}
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{Secp256k1PublicKey: secp256k1PublicKey(takerKey)}, s)
+	require.NoError(t, err)
+
+	// the peer's SendKeysMessage reply carries its session key
+	keys := <-s.received
+	require.NotEmpty(t, keys.(*SendKeysMessage).SessionKey)
+	require.Eventually(t, func() bool {
+		hb.swapMu.Lock()
+		defer hb.swapMu.Unlock()
+		return hb.swaps[testID] != nil
+	}, time.Second*5, time.Millisecond*10)
+
+	// a message that isn't signed by the swap's session key is dropped
+	err = hb.writeToStream(hb.swaps[testID].stream, &message.NotifyETHLocked{Address: "0xbad"})
+	require.NoError(t, err)
+
+	err = hb.SendSwapMessage(&message.NotifyETHLocked{Address: "0xabcd"}, testID)
+	require.NoError(t, err)
+	require.Equal(t, &message.NotifyETHLocked{Address: "0xabcd"}, <-s.received)
+}
@@ -0,0 +1,162 @@
+package net
+
+import (
+	"sync"
+	"time"
+
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	// DefaultMaxStreamsPerPeer is the default number of our protocol's streams, such as query, quote
+	// and swap streams, that a peer may have open with us at once
+	DefaultMaxStreamsPerPeer = 4
+	// DefaultMaxStreamsPerIP is the default number of those streams that the peers at one IP
+	// address may have open with us at once
+	DefaultMaxStreamsPerIP = 16
+
+	// how many streams over the limits a peer may open within streamViolationWindow before it's
+	// banned for streamBanDuration
+	maxStreamViolations   = 8
+	streamViolationWindow = time.Minute
+	streamBanDuration     = time.Minute * 30
+	// how many peers and IP addresses' violations are tracked before old ones are pruned
+	maxTrackedViolators = 1024
+)
+
+// streamLimiter limits how many of our protocol's streams each peer, and all of the peers at an IP
+// address, may have open with us at once, so that a peer can't exhaust our resources or hold up
+// all of our offers. Peers or IP addresses that keep going over the limits are banned for a while.
+type streamLimiter struct {
+	maxPerPeer int
+	maxPerIP   int
+	reputation *reputation
+
+	mu         sync.Mutex
+	perPeer    map[peer.ID]int
+	perIP      map[string]int
+	violations map[string][]time.Time
+}
+
+func newStreamLimiter(maxPerPeer, maxPerIP int, rep *reputation) *streamLimiter {
+	if maxPerPeer <= 0 {
+		maxPerPeer = DefaultMaxStreamsPerPeer
+	}
+	if maxPerIP <= 0 {
+		maxPerIP = DefaultMaxStreamsPerIP
+	}
+
+	return &streamLimiter{
+		maxPerPeer: maxPerPeer,
+		maxPerIP:   maxPerIP,
+		reputation: rep,
+		perPeer:    make(map[peer.ID]int),
+		perIP:      make(map[string]int),
+		violations: make(map[string][]time.Time),
+	}
+}
+
+// acquire counts a stream opened by the peer, at the given IP address, if it's within the limits.
+// The IP address is empty if it's unknown, eg. for relayed connections, in which case only the
+// peer's limit applies.
+func (l *streamLimiter) acquire(who peer.ID, ip string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perPeer[who] >= l.maxPerPeer {
+		log.Debugf("peer %s has too many streams open", who)
+		if l.violated(who.String(), now) {
+			l.reputation.tempBan(who, now.Add(streamBanDuration))
+		}
+		return false
+	}
+
+	if ip != "" && l.perIP[ip] >= l.maxPerIP {
+		log.Debugf("IP address %s has too many streams open", ip)
+		if l.violated(ip, now) {
+			l.reputation.tempBanIP(ip, now.Add(streamBanDuration))
+			l.reputation.tempBan(who, now.Add(streamBanDuration))
+		}
+		return false
+	}
+
+	l.perPeer[who]++
+	if ip != "" {
+		l.perIP[ip]++
+	}
+	return true
+}
+
+// release uncounts a stream counted by acquire.
+func (l *streamLimiter) release(who peer.ID, ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.perPeer[who]--
+	if l.perPeer[who] <= 0 {
+		delete(l.perPeer, who)
+	}
+
+	if ip == "" {
+		return
+	}
+
+	l.perIP[ip]--
+	if l.perIP[ip] <= 0 {
+		delete(l.perIP, ip)
+	}
+}
+
+// violated records that the peer or IP address went over its limit, returning whether it's done
+// so too often. It must be called with the lock held.
+func (l *streamLimiter) violated(key string, now time.Time) bool {
+	// forget violations that are too old to count, so that the map doesn't grow forever
+	if len(l.violations) > maxTrackedViolators {
+		for k, times := range l.violations {
+			if now.Sub(times[len(times)-1]) >= streamViolationWindow {
+				delete(l.violations, k)
+			}
+		}
+	}
+
+	recent := l.violations[key][:0]
+	for _, t := range l.violations[key] {
+		if now.Sub(t) < streamViolationWindow {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+
+	if len(recent) < maxStreamViolations {
+		l.violations[key] = recent
+		return false
+	}
+
+	delete(l.violations, key)
+	return true
+}
+
+// limitStreams wraps a stream handler so that streams over the limits are reset without being
+// handled.
+func (h *host) limitStreams(handler libp2pnetwork.StreamHandler) libp2pnetwork.StreamHandler {
+	return func(stream libp2pnetwork.Stream) {
+		who := stream.Conn().RemotePeer()
+
+		var ip string
+		if !isRelayed(stream.Conn()) {
+			if addr, err := manet.ToIP(stream.Conn().RemoteMultiaddr()); err == nil {
+				ip = addr.String()
+			}
+		}
+
+		if !h.streamLimiter.acquire(who, ip, time.Now()) {
+			_ = stream.Reset()
+			return
+		}
+		defer h.streamLimiter.release(who, ip)
+
+		handler(stream)
+	}
+}
@@ -0,0 +1,111 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamLimiter(t *testing.T) {
+	l := newStreamLimiter(2, 3, newReputation())
+	peerA, peerB := peer.ID("a"), peer.ID("b")
+	now := time.Now()
+
+	require.True(t, l.acquire(peerA, "1.2.3.4", now))
+	require.True(t, l.acquire(peerA, "1.2.3.4", now))
+	require.False(t, l.acquire(peerA, "1.2.3.4", now))
+
+	// the IP address's limit applies to all of its peers
+	require.True(t, l.acquire(peerB, "1.2.3.4", now))
+	require.False(t, l.acquire(peerB, "1.2.3.4", now))
+
+	// relayed streams have no IP address
+	require.True(t, l.acquire(peerB, "", now))
+
+	l.release(peerA, "1.2.3.4")
+	require.True(t, l.acquire(peerA, "1.2.3.4", now))
+
+	l.release(peerB, "")
+	l.release(peerB, "1.2.3.4")
+	require.Equal(t, 0, l.perPeer[peerB])
+	require.Equal(t, 2, l.perIP["1.2.3.4"])
+	require.False(t, l.reputation.isBanned(peerA))
+}
+
+func TestStreamLimiter_Ban(t *testing.T) {
+	l := newStreamLimiter(1, 0, newReputation())
+	peerA := peer.ID("a")
+	now := time.Now()
+
+	require.True(t, l.acquire(peerA, "", now))
+
+	// old violations don't count
+	for i := 0; i < maxStreamViolations-1; i++ {
+		require.False(t, l.acquire(peerA, "", now.Add(-streamViolationWindow)))
+	}
+	require.False(t, l.acquire(peerA, "", now))
+	require.False(t, l.reputation.isBanned(peerA))
+
+	for i := 0; i < maxStreamViolations-2; i++ {
+		require.False(t, l.acquire(peerA, "", now))
+	}
+	require.False(t, l.reputation.isBanned(peerA))
+
+	require.False(t, l.acquire(peerA, "", now))
+	require.True(t, l.reputation.isBanned(peerA))
+	require.Equal(t, now.Add(streamBanDuration), l.reputation.score(peerA).BannedUntil)
+}
+
+func TestStreamLimiter_BanIP(t *testing.T) {
+	l := newStreamLimiter(0, 1, newReputation())
+	peerA := peer.ID("a")
+	now := time.Now()
+
+	require.True(t, l.acquire(peer.ID("b"), "1.2.3.4", now))
+	for i := 0; i < maxStreamViolations; i++ {
+		require.False(t, l.acquire(peerA, "1.2.3.4", now))
+	}
+
+	require.True(t, l.reputation.isBanned(peerA))
+	require.True(t, l.reputation.isIPBanned("1.2.3.4"))
+	require.False(t, l.reputation.isIPBanned("1.2.3.5"))
+}
+
+func TestHost_LimitStreams(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	hb := newHost(t, defaultPort+1)
+	hb.streamLimiter = newStreamLimiter(1, 0, hb.reputation)
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	require.NoError(t, ha.h.Connect(ha.ctx, hb.addrInfo()))
+
+	// a swap stream that's held open uses up the peer's limit; we send the length of a message
+	// that never arrives, so that the peer's handler waits for it
+	stream, err := ha.h.NewStream(ha.ctx, hb.h.ID(), protocol.ID(ha.protocolID+swapID))
+	require.NoError(t, err)
+	_, err = stream.Write([]byte{8})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		hb.streamLimiter.mu.Lock()
+		defer hb.streamLimiter.mu.Unlock()
+		return hb.streamLimiter.perPeer[ha.h.ID()] == 1
+	}, time.Second*5, time.Millisecond*10)
+
+	_, err = ha.Query(hb.addrInfo())
+	require.Error(t, err)
+
+	_ = stream.Close()
+	require.Eventually(t, func() bool {
+		_, err = ha.Query(hb.addrInfo())
+		return err == nil
+	}, time.Second*5, time.Millisecond*100)
+}
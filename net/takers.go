@@ -0,0 +1,49 @@
+package net
+
+import (
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ValidateTakerFilter checks that the filter's peer IDs are valid, since a mistyped ID would
+// otherwise leave an allowlisted offer untakeable.
+func ValidateTakerFilter(filter *types.TakerFilter) error {
+	if filter == nil {
+		return nil
+	}
+
+	for _, ids := range [][]string{filter.Allow, filter.Deny} {
+		for _, id := range ids {
+			if _, err := peer.Decode(id); err != nil {
+				return fmt.Errorf("invalid taker peer ID %q: %w", id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mayTake returns whether the peer may take the offer with the given ID, according to both our
+// global taker filter and the offer's own.
+func (h *host) mayTake(who peer.ID, offerID string) bool {
+	if !h.takerFilter.Permits(who.String()) {
+		return false
+	}
+
+	return h.handler.GetTakerFilter(offerID).Permits(who.String())
+}
+
+// offersFor returns the offers that the peer may take, so that peers don't see private offers
+// they couldn't take anyway.
+func (h *host) offersFor(who peer.ID, offers []*types.Offer) []*types.Offer {
+	permitted := make([]*types.Offer, 0, len(offers))
+	for _, o := range offers {
+		if h.mayTake(who, o.GetID().String()) {
+			permitted = append(permitted, o)
+		}
+	}
+	return permitted
+}
@@ -0,0 +1,86 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTakerFilter(t *testing.T) {
+	h := newHost(t, defaultPort)
+	defer func() {
+		_ = h.Stop()
+	}()
+
+	require.NoError(t, ValidateTakerFilter(nil))
+	require.NoError(t, ValidateTakerFilter(&types.TakerFilter{Allow: []string{h.h.ID().String()}}))
+	require.Error(t, ValidateTakerFilter(&types.TakerFilter{Deny: []string{"notapeerid"}}))
+}
+
+func TestHost_Query_PrivateOffer(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	public := &types.Offer{ID: types.Hash{1}, Provides: types.ProvidesXMR}
+	private := &types.Offer{ID: types.Hash{2}, Provides: types.ProvidesXMR}
+	handler := hb.handler.(*mockHandler)
+	handler.offers = []*types.Offer{public, private}
+	handler.takers = map[string]*types.TakerFilter{
+		private.GetID().String(): {Allow: []string{"someone-else"}},
+	}
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	resp, err := ha.Query(hb.addrInfo())
+	require.NoError(t, err)
+	require.Len(t, resp.Offers, 1)
+	require.Equal(t, public.GetID(), resp.Offers[0].GetID())
+
+	// once we're allowed to take the private offer, we can see it too
+	handler.takers[private.GetID().String()].Allow = append(handler.takers[private.GetID().String()].Allow,
+		ha.h.ID().String())
+	resp, err = ha.Query(hb.addrInfo())
+	require.NoError(t, err)
+	require.Len(t, resp.Offers, 2)
+}
+
+func TestHost_Initiate_TakerDenied(t *testing.T) {
+	ha := newHost(t, defaultPort)
+	err := ha.Start()
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+	err = hb.Start()
+	require.NoError(t, err)
+
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	hb.takerFilter = &types.TakerFilter{Deny: []string{ha.h.ID().String()}}
+
+	err = ha.h.Connect(ha.ctx, hb.addrInfo())
+	require.NoError(t, err)
+
+	err = ha.Initiate(hb.addrInfo(), &SendKeysMessage{OfferID: testID.String()}, new(mockSwapState))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 500)
+
+	// the swap never started on the maker's side
+	hb.swapMu.Lock()
+	defer hb.swapMu.Unlock()
+	require.Nil(t, hb.swaps[testID])
+}
@@ -0,0 +1,195 @@
+package net
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/transport"
+	tptu "github.com/libp2p/go-libp2p-transport-upgrader"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"golang.org/x/net/proxy"
+)
+
+var _ transport.Transport = &socksTransport{}
+
+// socksTransport is a TCP transport that makes all of its outgoing connections through a SOCKS5
+// proxy, such as Tor's, so that peers don't learn our IP address. It can also dial onion
+// addresses. It listens directly, so that Tor can forward an onion service's connections to it.
+type socksTransport struct {
+	upgrader *tptu.Upgrader
+	dialer   proxy.ContextDialer
+}
+
+func newSocksTransport(upgrader *tptu.Upgrader, proxyAddr string) (*socksTransport, error) {
+	d, err := proxy.SOCKS5("tcp", proxyAddr, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("SOCKS5 dialer %T can't dial with a context", d)
+	}
+
+	return &socksTransport{
+		upgrader: upgrader,
+		dialer:   dialer,
+	}, nil
+}
+
+// CanDial returns whether the address is a TCP or onion address.
+func (t *socksTransport) CanDial(addr ma.Multiaddr) bool {
+	_, err := proxyTarget(addr)
+	return err == nil
+}
+
+// Dial connects to the peer through the proxy. Host names are resolved by the proxy, so that
+// they don't leak through our DNS resolver.
+func (t *socksTransport) Dial(ctx context.Context, raddr ma.Multiaddr, p peer.ID) (transport.CapableConn, error) {
+	target, err := proxyTarget(raddr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := t.dialer.DialContext(ctx, "tcp", target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s through proxy: %w", raddr, err)
+	}
+
+	laddr, err := manet.FromNetAddr(conn.LocalAddr())
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return t.upgrader.UpgradeOutbound(ctx, t, &proxiedConn{
+		Conn:  conn,
+		laddr: laddr,
+		raddr: raddr,
+	}, p)
+}
+
+// Listen listens for TCP connections on the given address.
+func (t *socksTransport) Listen(laddr ma.Multiaddr) (transport.Listener, error) {
+	l, err := manet.Listen(laddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.upgrader.UpgradeListener(t, l), nil
+}
+
+// Protocols returns the protocols the transport dials and listens on.
+func (t *socksTransport) Protocols() []int {
+	return []int{ma.P_TCP, ma.P_ONION3}
+}
+
+// Proxy returns true, so that the transport is used for onion addresses.
+func (t *socksTransport) Proxy() bool {
+	return true
+}
+
+func (t *socksTransport) String() string {
+	return "SOCKS5"
+}
+
+// proxiedConn is a connection made through the proxy, whose remote address is the address we
+// asked the proxy to connect to.
+type proxiedConn struct {
+	net.Conn
+	laddr ma.Multiaddr
+	raddr ma.Multiaddr
+}
+
+func (c *proxiedConn) LocalMultiaddr() ma.Multiaddr {
+	return c.laddr
+}
+
+func (c *proxiedConn) RemoteMultiaddr() ma.Multiaddr {
+	return c.raddr
+}
+
+// proxyTarget returns the host and port to ask the proxy to connect to, for an IP, DNS or onion
+// address with a TCP port.
+func proxyTarget(addr ma.Multiaddr) (string, error) {
+	var host, port string
+	ma.ForEach(addr, func(c ma.Component) bool {
+		switch c.Protocol().Code {
+		case ma.P_IP4, ma.P_IP6, ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+			host = c.Value()
+		case ma.P_TCP:
+			port = c.Value()
+		case ma.P_ONION3:
+			// the value is the onion service's address and port, eg. "vww6...wdid:1234"
+			parts := strings.SplitN(c.Value(), ":", 2)
+			if len(parts) == 2 {
+				host, port = parts[0]+".onion", parts[1]
+			}
+		}
+		return true
+	})
+
+	if host == "" || port == "" {
+		return "", fmt.Errorf("%w: %s", errUnsupportedProxyAddr, addr)
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// onionMultiaddr returns the multiaddress of the onion service with the given address, eg.
+// "vww6...wdid.onion" or "vww6...wdid.onion:9900". If it has no port, the given port is used.
+func onionMultiaddr(onion string, port uint16) (ma.Multiaddr, error) {
+	addr, portStr := onion, strconv.Itoa(int(port))
+	if i := strings.LastIndex(onion, ":"); i != -1 {
+		addr, portStr = onion[:i], onion[i+1:]
+	}
+
+	addr = strings.TrimSuffix(addr, ".onion")
+	maddr, err := ma.NewMultiaddr(fmt.Sprintf("/onion3/%s:%s", addr, portStr))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %s", errInvalidOnionAddress, onion, err)
+	}
+
+	return maddr, nil
+}
+
+// proxyOptions returns the libp2p options for a host that makes all of its connections through
+// the configured SOCKS5 proxy. It listens only on localhost, where Tor forwards the connections
+// to our onion service, and advertises only the onion service's address, if there is one.
+func proxyOptions(cfg *Config) ([]libp2p.Option, error) {
+	if cfg.MDNS {
+		return nil, errMDNSRequiresDirect
+	}
+
+	addr, err := ma.NewMultiaddr(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d", cfg.Port))
+	if err != nil {
+		return nil, err
+	}
+
+	var advertised []ma.Multiaddr
+	if cfg.OnionAddress != "" {
+		onion, err := onionMultiaddr(cfg.OnionAddress, cfg.Port)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("advertising onion address %s", onion)
+		advertised = append(advertised, onion)
+	}
+
+	return []libp2p.Option{
+		libp2p.ListenAddrs(addr),
+		libp2p.Transport(func(u *tptu.Upgrader) (*socksTransport, error) {
+			return newSocksTransport(u, cfg.ProxyAddress)
+		}),
+		libp2p.AddrsFactory(func([]ma.Multiaddr) []ma.Multiaddr {
+			return advertised
+		}),
+	}, nil
+}
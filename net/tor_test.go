@@ -0,0 +1,178 @@
+package net
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+const testOnion = "vww6ybal4bd7szmgncyruucpgfkqahzddi37ktceo3ah7ngmcopnpyyd"
+
+func TestProxyTarget(t *testing.T) {
+	for addr, target := range map[string]string{
+		"/ip4/1.2.3.4/tcp/9900": "1.2.3.4:9900",
+		"/ip6/::1/tcp/9900/p2p/12D3KooWHLUrLnJtUbaGzTSi6azZavKhNgUZTtSiUZ9Uy12v1eZ7": "[::1]:9900",
+		"/dns4/example.com/tcp/9900":     "example.com:9900",
+		"/onion3/" + testOnion + ":9900": testOnion + ".onion:9900",
+	} {
+		maddr, err := ma.NewMultiaddr(addr)
+		require.NoError(t, err)
+		got, err := proxyTarget(maddr)
+		require.NoError(t, err)
+		require.Equal(t, target, got)
+	}
+
+	maddr, err := ma.NewMultiaddr("/ip4/1.2.3.4/udp/9900")
+	require.NoError(t, err)
+	_, err = proxyTarget(maddr)
+	require.ErrorIs(t, err, errUnsupportedProxyAddr)
+}
+
+func TestOnionMultiaddr(t *testing.T) {
+	maddr, err := onionMultiaddr(testOnion+".onion", 9900)
+	require.NoError(t, err)
+	require.Equal(t, "/onion3/"+testOnion+":9900", maddr.String())
+
+	maddr, err = onionMultiaddr(testOnion+".onion:80", 9900)
+	require.NoError(t, err)
+	require.Equal(t, "/onion3/"+testOnion+":80", maddr.String())
+
+	_, err = onionMultiaddr("notanonion.onion", 9900)
+	require.ErrorIs(t, err, errInvalidOnionAddress)
+}
+
+// runSOCKS5Proxy runs a minimal SOCKS5 proxy that supports unauthenticated CONNECT requests, and
+// returns its address and the number of connections it has proxied.
+func runSOCKS5Proxy(t *testing.T) (string, *int32) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	var proxied int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				if err := proxySOCKS5(conn); err == nil {
+					atomic.AddInt32(&proxied, 1)
+				}
+			}()
+		}
+	}()
+
+	return l.Addr().String(), &proxied
+}
+
+func proxySOCKS5(conn net.Conn) error {
+	// greeting: version, number of methods, methods; we accept no authentication
+	buf := make([]byte, 262)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return err
+	}
+
+	// request: version, command, reserved, address type, address, port
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return err
+	}
+
+	var host string
+	switch buf[3] {
+	case 1:
+		if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+			return err
+		}
+		host = net.IP(buf[:4]).String()
+	case 3:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return err
+		}
+		n := buf[0]
+		if _, err := io.ReadFull(conn, buf[:n]); err != nil {
+			return err
+		}
+		host = string(buf[:n])
+	default:
+		return fmt.Errorf("unsupported address type %d", buf[3])
+	}
+
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return err
+	}
+	port := binary.BigEndian.Uint16(buf[:2])
+
+	target, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))))
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return err
+	}
+
+	if _, err = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		return err
+	}
+
+	go func() {
+		_, _ = io.Copy(target, conn)
+		_ = target.Close()
+	}()
+	go func() {
+		_, _ = io.Copy(conn, target)
+		_ = conn.Close()
+	}()
+	return nil
+}
+
+func TestHost_Proxy(t *testing.T) {
+	proxyAddr, proxied := runSOCKS5Proxy(t)
+
+	ha, err := NewHost(&Config{
+		Ctx:          context.Background(),
+		Environment:  common.Development,
+		ChainID:      common.GanacheChainID,
+		Port:         defaultPort,
+		KeyFile:      path.Join(t.TempDir(), "node.key"),
+		Handler:      &mockHandler{},
+		ProxyAddress: proxyAddr,
+		OnionAddress: testOnion + ".onion",
+	})
+	require.NoError(t, err)
+	hb := newHost(t, defaultPort+1)
+
+	require.NoError(t, ha.Start())
+	require.NoError(t, hb.Start())
+	defer func() {
+		_ = ha.Stop()
+		_ = hb.Stop()
+	}()
+
+	// only the onion address is advertised
+	require.Len(t, ha.multiaddrs(), 1)
+	require.Contains(t, ha.multiaddrs()[0].String(), "/onion3/"+testOnion+":5001")
+
+	resp, err := ha.Query(hb.addrInfo())
+	require.NoError(t, err)
+	require.Empty(t, resp.Offers)
+	require.NotZero(t, atomic.LoadInt32(proxied))
+}
@@ -1,6 +1,8 @@
 package net
 
 import (
+	"time"
+
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/net/message"
@@ -14,6 +16,8 @@ type (
 	Message         = message.Message
 	QueryResponse   = message.QueryResponse
 	SendKeysMessage = message.SendKeysMessage
+	QuoteRequest    = message.QuoteRequest
+	Quote           = message.Quote
 )
 
 // MessageSender is implemented by a Host
@@ -21,9 +25,43 @@ type MessageSender interface {
 	SendSwapMessage(Message, types.Hash) error
 }
 
+// Journal records each swap's protocol messages before they're sent or handled.
+type Journal interface {
+	MessageSent(id types.Hash, msg Message) error
+	MessageReceived(id types.Hash, msg Message) error
+}
+
+// PeerRecorder is implemented by swap states that record the peer ID of their counterparty.
+type PeerRecorder interface {
+	SetPeerID(id string)
+}
+
+// TimeoutReporter is implemented by swap states that know the contract timeout they'll use, so
+// that it can be checked against the timeouts the counterparty accepts before the swap begins.
+type TimeoutReporter interface {
+	ContractTimeout() time.Duration
+}
+
+// LivenessReporter is implemented by swap states that know how long the counterparty may go
+// without being heard from, at the swap's current stage, before it's considered gone.
+type LivenessReporter interface {
+	LivenessTimeout() time.Duration
+}
+
+// SessionKeySigner is implemented by swap states that sign the session key sent in their
+// SendKeysMessage with the secp256k1 key of their DLEq proof; see session.
+type SessionKeySigner interface {
+	SignSessionKey(key []byte) ([]byte, error)
+}
+
 // Handler handles swap initiation messages.
 // It is implemented by *xmrmaker.xmrmaker
 type Handler interface {
 	GetOffers() []*types.Offer
 	HandleInitiateMessage(msg *SendKeysMessage) (s SwapState, resp Message, err error)
+	HandleQuoteRequest(req *QuoteRequest) (*Quote, error)
+	// GetTakerFilter returns the filter restricting who may take the offer, or nil if anyone may
+	GetTakerFilter(offerID string) *types.TakerFilter
+	// GetOfferToken returns the token needed to take the offer, or "" if it's public
+	GetOfferToken(offerID string) string
 }
@@ -0,0 +1,66 @@
+package protocol
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// Kinds of alert.
+const (
+	// AlertClaimFailing is sent when claiming a swap's ether keeps failing as t1 approaches, after
+	// which the counterparty can refund it.
+	AlertClaimFailing = "claimFailing"
+
+	// AlertPayoutFailed is sent when claimed ether couldn't be forwarded to the payout address.
+	AlertPayoutFailed = "payoutFailed"
+)
+
+var (
+	// URL that alerts are POSTed to, if set
+	alertWebhookMu sync.RWMutex
+	alertWebhook   string
+)
+
+// SetAlertWebhook sets a URL that alerts are POSTed to (as JSON), so that the operator can be told
+// about swaps needing their attention.
+func SetAlertWebhook(url string) {
+	alertWebhookMu.Lock()
+	defer alertWebhookMu.Unlock()
+	alertWebhook = url
+}
+
+// Alert is a problem with a swap that needs the operator's attention.
+type Alert struct {
+	SwapID  types.Hash `json:"swapID"`
+	Kind    string     `json:"kind"`
+	Message string     `json:"message"`
+	// Deadline is when it'll be too late to act, if there is one
+	Deadline time.Time `json:"deadline,omitempty"`
+}
+
+// SendAlert logs the alert and, if an alert webhook is set, POSTs it there in the background.
+func SendAlert(alert *Alert) {
+	log.Errorf("ALERT for swap %s: %s (deadline %s)", alert.SwapID, alert.Message, alert.Deadline)
+
+	alertWebhookMu.RLock()
+	url := alertWebhook
+	alertWebhookMu.RUnlock()
+	if url == "" {
+		return
+	}
+
+	bz, err := json.Marshal(alert)
+	if err != nil {
+		log.Warnf("failed to encode alert for swap %s: %s", alert.SwapID, err)
+		return
+	}
+
+	go func() {
+		if err := postWebhook(url, bz); err != nil {
+			log.Warnf("failed to deliver alert for swap %s: %s", alert.SwapID, err)
+		}
+	}()
+}
@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendAlert(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bz, _ := ioutil.ReadAll(r.Body)
+		received <- bz
+	}))
+	defer server.Close()
+
+	SetAlertWebhook(server.URL)
+	defer SetAlertWebhook("")
+
+	alert := &Alert{
+		SwapID:   types.Hash{1},
+		Kind:     AlertClaimFailing,
+		Message:  "failed to claim",
+		Deadline: time.Now().Add(time.Hour).Round(0),
+	}
+	SendAlert(alert)
+
+	select {
+	case bz := <-received:
+		var delivered *Alert
+		require.NoError(t, json.Unmarshal(bz, &delivered))
+		require.Equal(t, alert.SwapID, delivered.SwapID)
+		require.Equal(t, AlertClaimFailing, delivered.Kind)
+		require.True(t, alert.Deadline.Equal(delivered.Deadline))
+	case <-time.After(time.Second * 5):
+		t.Fatal("alert wasn't delivered to the webhook")
+	}
+}
@@ -3,6 +3,7 @@ package backend
 import (
 	"context"
 	"crypto/ecdsa"
+	"fmt"
 	"math/big"
 	"sync"
 	"time"
@@ -11,11 +12,12 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/crypto/hdwallet"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/db"
 	"github.com/noot/atomic-swap/monero"
 	"github.com/noot/atomic-swap/net"
 	"github.com/noot/atomic-swap/protocol/swap"
@@ -25,12 +27,6 @@ import (
 	logging "github.com/ipfs/go-log"
 )
 
-const (
-	// in total, we will wait up to 1 hour for a transaction to be included
-	maxRetries           = 360
-	receiptSleepDuration = time.Second * 10
-)
-
 var (
 	log                    = logging.Logger("protocol/backend")
 	defaultTimeoutDuration = time.Hour * 24
@@ -46,8 +42,12 @@ type Backend interface {
 
 	// ethclient methods
 	BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error)
 	CodeAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) ([]byte, error)
 	FilterLogs(ctx context.Context, q eth.FilterQuery) ([]ethtypes.Log, error)
+	SyncProgress(ctx context.Context) (*eth.SyncProgress, error)
+	TransactionByHash(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Transaction, bool, error)
 	TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
 
 	// helpers
@@ -57,9 +57,12 @@ type Backend interface {
 	// getters
 	Ctx() context.Context
 	Env() common.Environment
+	HasDaemonClient() bool
 	ChainID() *big.Int
 	CallOpts() *bind.CallOpts
 	TxOpts() (*bind.TransactOpts, error)
+	GasPrice(ctx context.Context) (*big.Int, error)
+	GasConfig() txsender.GasConfig
 	SwapManager() swap.Manager
 	EthAddress() ethcommon.Address
 	Contract() *swapfactory.SwapFactory
@@ -77,6 +80,13 @@ type Backend interface {
 	SetBaseXMRDepositAddress(mcrypto.Address)
 	SetContract(*swapfactory.SwapFactory)
 	SetContractAddress(ethcommon.Address)
+
+	// HD wallet swap accounts
+	SwapEthAddress(id types.Hash) (ethcommon.Address, *uint32, error)
+	RegisterEthKeyIndex(index uint32) (ethcommon.Address, error)
+	HasEthAddress(addr ethcommon.Address) bool
+	TransferETH(from, to ethcommon.Address, amount *big.Int) (ethcommon.Hash, error)
+	SweepSwapAccount(addr ethcommon.Address) (ethcommon.Hash, error)
 }
 
 type backend struct {
@@ -94,13 +104,16 @@ type backend struct {
 	xmrDepositAddrs    map[types.Hash]mcrypto.Address
 
 	// ethereum endpoint and variables
-	ethClient  *ethclient.Client
+	ethClient  EthClient
 	ethPrivKey *ecdsa.PrivateKey
 	callOpts   *bind.CallOpts
 	ethAddress ethcommon.Address
 	chainID    *big.Int
-	gasPrice   *big.Int
-	gasLimit   uint64
+	// gas settings of new transactions; the senders have their own copies
+	gasMu sync.RWMutex
+	gas   txsender.GasConfig
+	// number of confirmations required before a transaction is considered final
+	confirmations uint64
 	txsender.Sender
 
 	// swap contract
@@ -110,6 +123,17 @@ type backend struct {
 
 	// network interface
 	net.MessageSender
+
+	// checked before signing every transaction, if set
+	fence func() error
+
+	// if set, each swap uses its own account derived from the wallet
+	hdWallet  *hdwallet.Wallet
+	broadcast *txsender.BroadcastConfig
+	hdMu      sync.Mutex
+	hdDB      db.Database
+	hdIndexes map[ethcommon.Address]uint32
+	hdSenders map[ethcommon.Address]txsender.Sender
 }
 
 // Config is the config for the Backend
@@ -118,19 +142,40 @@ type Config struct {
 	MoneroWalletEndpoint string
 	MoneroDaemonEndpoint string // only needed for development
 
-	EthereumClient     *ethclient.Client
+	EthereumClient     EthClient
 	EthereumPrivateKey *ecdsa.PrivateKey
 	Environment        common.Environment
 	ChainID            *big.Int
 	GasPrice           *big.Int
 	GasLimit           uint64
 
+	// Confirmations is the number of confirmations required before a transaction is
+	// considered final. If unset, transactions are considered final once included.
+	Confirmations uint64
+
 	SwapContract        *swapfactory.SwapFactory
 	SwapContractAddress ethcommon.Address
 
 	SwapManager swap.Manager
 
 	Net net.MessageSender
+
+	// EthereumHDWallet, if set, is used to derive a fresh ethereum account for each swap, so that
+	// swaps can't be linked to each other on-chain. If EthereumPrivateKey isn't set, the wallet's
+	// first account is used as the base account, which funds the swap accounts.
+	EthereumHDWallet *hdwallet.Wallet
+
+	// Database is where the HD wallet account index of each swap is stored. It's required for new
+	// swaps to be started with an HD wallet.
+	Database db.Database
+
+	// Broadcast, if set, selects how each swap contract method's transactions are broadcast.
+	Broadcast *txsender.BroadcastConfig
+
+	// Fence, if set, is called before every transaction is signed. If it returns an error, the
+	// transaction isn't signed. It's used to stop a daemon that's no longer active from signing
+	// transactions when running in active/standby mode.
+	Fence func() error
 }
 
 // NewBackend returns a new Backend
@@ -145,6 +190,20 @@ func NewBackend(cfg *Config) (Backend, error) {
 		defaultTimeoutDuration = time.Hour
 	}
 
+	if cfg.EthereumHDWallet != nil {
+		if cfg.Broadcast.UsesExternalSigner() {
+			return nil, errHDWalletWithExternalSigner
+		}
+
+		if cfg.EthereumPrivateKey == nil {
+			pk, err := cfg.EthereumHDWallet.PrivateKey(0)
+			if err != nil {
+				return nil, err
+			}
+			cfg.EthereumPrivateKey = pk
+		}
+	}
+
 	var (
 		addr   ethcommon.Address
 		sender txsender.Sender
@@ -155,12 +214,24 @@ func NewBackend(cfg *Config) (Backend, error) {
 			return nil, err
 		}
 
+		if cfg.Fence != nil {
+			txOpts.Signer = fencedSigner(txOpts.Signer, cfg.Fence)
+		}
+
 		addr = common.EthereumPrivateKeyToAddress(cfg.EthereumPrivateKey)
-		sender = txsender.NewSenderWithPrivateKey(cfg.Ctx, cfg.EthereumClient, cfg.SwapContract, txOpts)
+		sender, err = newPrivateKeySender(cfg, txOpts)
+		if err != nil {
+			return nil, err
+		}
 	} else {
+		if cfg.Broadcast != nil && !cfg.Broadcast.OnlyExternal() {
+			return nil, errBroadcastRequiresPrivateKey
+		}
+
 		log.Debugf("instantiated backend with external sender")
 		var err error
-		sender, err = txsender.NewExternalSender(cfg.Ctx, cfg.Environment, cfg.EthereumClient, cfg.SwapContractAddress)
+		sender, err = txsender.NewExternalSender(cfg.Ctx, cfg.Environment, cfg.EthereumClient,
+			cfg.SwapContractAddress, cfg.Confirmations)
 		if err != nil {
 			return nil, err
 		}
@@ -179,6 +250,12 @@ func NewBackend(cfg *Config) (Backend, error) {
 		return nil, errNilSwapContractOrAddress
 	}
 
+	gas := txsender.GasConfig{
+		GasPrice: cfg.GasPrice,
+		GasLimit: cfg.GasLimit,
+	}
+	sender.SetGasConfig(&gas)
+
 	return &backend{
 		ctx:          cfg.Ctx,
 		env:          cfg.Environment,
@@ -193,17 +270,56 @@ func NewBackend(cfg *Config) (Backend, error) {
 		Sender:          sender,
 		ethAddress:      addr,
 		chainID:         cfg.ChainID,
-		gasPrice:        cfg.GasPrice,
-		gasLimit:        cfg.GasLimit,
+		gas:             gas,
+		confirmations:   cfg.Confirmations,
 		contract:        cfg.SwapContract,
 		contractAddr:    cfg.SwapContractAddress,
 		swapManager:     cfg.SwapManager,
 		swapTimeout:     defaultTimeoutDuration,
 		MessageSender:   cfg.Net,
 		xmrDepositAddrs: make(map[types.Hash]mcrypto.Address),
+		fence:           cfg.Fence,
+		hdWallet:        cfg.EthereumHDWallet,
+		hdDB:            cfg.Database,
+		broadcast:       cfg.Broadcast,
+		hdIndexes:       make(map[ethcommon.Address]uint32),
+		hdSenders:       make(map[ethcommon.Address]txsender.Sender),
 	}, nil
 }
 
+// newPrivateKeySender returns a sender that signs with the configured private key and broadcasts
+// using the configured strategies.
+func newPrivateKeySender(cfg *Config, txOpts *bind.TransactOpts) (txsender.Sender, error) {
+	if cfg.Broadcast == nil {
+		return txsender.NewSenderWithPrivateKey(cfg.Ctx, cfg.EthereumClient, cfg.SwapContract, txOpts,
+			cfg.Confirmations), nil
+	}
+
+	var external *txsender.ExternalSender
+	if cfg.Broadcast.UsesExternalSigner() {
+		var err error
+		external, err = txsender.NewExternalSender(cfg.Ctx, cfg.Environment, cfg.EthereumClient,
+			cfg.SwapContractAddress, cfg.Confirmations)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return txsender.NewSenderWithBroadcastConfig(cfg.Ctx, cfg.EthereumClient, cfg.SwapContract, txOpts,
+		cfg.Confirmations, cfg.Broadcast, external)
+}
+
+// fencedSigner returns a signer that only signs transactions if the fence check passes.
+func fencedSigner(signer bind.SignerFn, fence func() error) bind.SignerFn {
+	return func(addr ethcommon.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+		if err := fence(); err != nil {
+			return nil, fmt.Errorf("%w: %s", errFenced, err)
+		}
+
+		return signer(addr, tx)
+	}
+}
+
 func (b *backend) CallOpts() *bind.CallOpts {
 	return b.callOpts
 }
@@ -228,21 +344,34 @@ func (b *backend) Env() common.Environment {
 	return b.env
 }
 
+// HasDaemonClient returns whether the backend has a monerod client; it only has one in the
+// development environment.
+func (b *backend) HasDaemonClient() bool {
+	return b.DaemonClient != nil
+}
+
 func (b *backend) EthAddress() ethcommon.Address {
 	return b.ethAddress
 }
 
-func (b *backend) EthClient() *ethclient.Client {
+func (b *backend) EthClient() EthClient {
 	return b.ethClient
 }
 
+// externalSenderGetter is implemented by senders that send some methods through an external signer
+type externalSenderGetter interface {
+	ExternalSender() *txsender.ExternalSender
+}
+
 func (b *backend) ExternalSender() *txsender.ExternalSender {
-	s, ok := b.Sender.(*txsender.ExternalSender)
-	if !ok {
+	switch s := b.Sender.(type) {
+	case *txsender.ExternalSender:
+		return s
+	case externalSenderGetter:
+		return s.ExternalSender()
+	default:
 		return nil
 	}
-
-	return s
 }
 
 func (b *backend) Net() net.MessageSender {
@@ -257,9 +386,46 @@ func (b *backend) SwapTimeout() time.Duration {
 	return b.swapTimeout
 }
 
-// SetGasPrice sets the ethereum gas price for the instance to use (in wei).
+// SetGasPrice sets the ethereum gas price for the instance to use (in wei). New transactions are
+// sent as legacy transactions, with the gas price instead of fee caps.
 func (b *backend) SetGasPrice(gasPrice uint64) {
-	b.gasPrice = big.NewInt(0).SetUint64(gasPrice)
+	cfg := b.GasConfig()
+	cfg.GasPrice = new(big.Int).SetUint64(gasPrice)
+	cfg.GasFeeCap, cfg.GasTipCap = nil, nil
+	b.SetGasConfig(&cfg)
+}
+
+// GasPrice returns the gas price set with SetGasPrice, or the gas price suggested by the
+// ethereum node if it isn't set.
+func (b *backend) GasPrice(ctx context.Context) (*big.Int, error) {
+	if price := b.GasConfig().GasPrice; price != nil {
+		return price, nil
+	}
+
+	return b.ethClient.SuggestGasPrice(ctx)
+}
+
+// GasConfig returns the gas settings of new transactions.
+func (b *backend) GasConfig() txsender.GasConfig {
+	b.gasMu.RLock()
+	defer b.gasMu.RUnlock()
+	return b.gas
+}
+
+// SetGasConfig sets the gas settings of the transactions sent from now on, by any of our
+// accounts. The config must be valid.
+func (b *backend) SetGasConfig(cfg *txsender.GasConfig) {
+	b.gasMu.Lock()
+	b.gas = *cfg
+	b.gasMu.Unlock()
+
+	b.Sender.SetGasConfig(cfg)
+
+	b.hdMu.Lock()
+	defer b.hdMu.Unlock()
+	for _, s := range b.hdSenders {
+		s.SetGasConfig(cfg)
+	}
 }
 
 // SetSwapTimeout sets the duration between the swap being initiated on-chain and the timeout t0,
@@ -272,6 +438,14 @@ func (b *backend) BalanceAt(ctx context.Context, account ethcommon.Address, bloc
 	return b.ethClient.BalanceAt(ctx, account, blockNumber)
 }
 
+func (b *backend) BlockNumber(ctx context.Context) (uint64, error) {
+	return b.ethClient.BlockNumber(ctx)
+}
+
+func (b *backend) CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return b.ethClient.CallContract(ctx, call, blockNumber)
+}
+
 func (b *backend) CodeAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) ([]byte, error) {
 	return b.ethClient.CodeAt(ctx, account, blockNumber)
 }
@@ -280,18 +454,35 @@ func (b *backend) FilterLogs(ctx context.Context, q eth.FilterQuery) ([]ethtypes
 	return b.ethClient.FilterLogs(ctx, q)
 }
 
+func (b *backend) SyncProgress(ctx context.Context) (*eth.SyncProgress, error) {
+	return b.ethClient.SyncProgress(ctx)
+}
+
+func (b *backend) TransactionByHash(ctx context.Context,
+	txHash ethcommon.Hash) (*ethtypes.Transaction, bool, error) {
+	return b.ethClient.TransactionByHash(ctx, txHash)
+}
+
 func (b *backend) TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
 	return b.ethClient.TransactionReceipt(ctx, txHash)
 }
 
 func (b *backend) TxOpts() (*bind.TransactOpts, error) {
+	if b.ethPrivKey == nil {
+		return nil, errNoEthereumPrivateKey
+	}
+
 	txOpts, err := bind.NewKeyedTransactorWithChainID(b.ethPrivKey, b.chainID)
 	if err != nil {
 		return nil, err
 	}
 
-	txOpts.GasPrice = b.gasPrice
-	txOpts.GasLimit = b.gasLimit
+	if b.fence != nil {
+		txOpts.Signer = fencedSigner(txOpts.Signer, b.fence)
+	}
+
+	gas := b.GasConfig()
+	gas.Apply(txOpts)
 	return txOpts, nil
 }
 
@@ -315,26 +506,26 @@ func (b *backend) XMRDepositAddress(id *types.Hash) (mcrypto.Address, error) {
 	return addr, nil
 }
 
-// WaitForReceipt waits for the receipt for the given transaction to be available and returns it.
+// WaitForReceipt waits for the receipt for the given transaction to be available and to have
+// the backend's required number of confirmations, and returns it.
 func (b *backend) WaitForReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
-	for i := 0; i < maxRetries; i++ {
-		receipt, err := b.ethClient.TransactionReceipt(ctx, txHash)
-		if err != nil {
-			log.Infof("waiting for transaction to be included in chain: txHash=%s", txHash)
-			time.Sleep(receiptSleepDuration)
-			continue
-		}
+	log.Infof("waiting for transaction to be included in chain: txHash=%s confirmations=%d",
+		txHash,
+		b.confirmations,
+	)
 
-		log.Infof("transaction %s included in chain, block hash=%s, block number=%d, gas used=%d",
-			txHash,
-			receipt.BlockHash,
-			receipt.BlockNumber,
-			receipt.CumulativeGasUsed,
-		)
-		return receipt, nil
+	receipt, err := txsender.WaitForReceipt(ctx, b.ethClient, txHash, b.confirmations)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, errReceiptTimeOut
+	log.Infof("transaction %s included in chain, block hash=%s, block number=%d, gas used=%d",
+		txHash,
+		receipt.BlockHash,
+		receipt.BlockNumber,
+		receipt.CumulativeGasUsed,
+	)
+	return receipt, nil
 }
 
 func (b *backend) NewSwapFactory(addr ethcommon.Address) (*swapfactory.SwapFactory, error) {
@@ -342,7 +533,9 @@ func (b *backend) NewSwapFactory(addr ethcommon.Address) (*swapfactory.SwapFacto
 }
 
 func (b *backend) SetEthAddress(addr ethcommon.Address) {
-	if b.ExternalSender() == nil {
+	// when some methods are sent by an external signer alongside a private key, the signer
+	// must use the private key's account, so the address isn't changed
+	if b.ExternalSender() == nil || b.ethPrivKey != nil {
 		return
 	}
 
@@ -365,6 +558,12 @@ func (b *backend) SetXMRDepositAddress(addr mcrypto.Address, id types.Hash) {
 func (b *backend) SetContract(contract *swapfactory.SwapFactory) {
 	b.contract = contract
 	b.Sender.SetContract(contract)
+
+	b.hdMu.Lock()
+	defer b.hdMu.Unlock()
+	for _, s := range b.hdSenders {
+		s.SetContract(contract)
+	}
 }
 
 func (b *backend) SetContractAddress(addr ethcommon.Address) {
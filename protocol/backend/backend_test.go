@@ -3,12 +3,14 @@ package backend
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/tests"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
@@ -58,3 +60,37 @@ func TestWaitForReceipt(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, tx.Hash(), receipt.TxHash)
 }
+
+func TestFencedSigner(t *testing.T) {
+	privKey, err := ethcrypto.GenerateKey()
+	require.NoError(t, err)
+
+	txOpts, err := bind.NewKeyedTransactorWithChainID(privKey, big.NewInt(1))
+	require.NoError(t, err)
+
+	var fenceErr error
+	signer := fencedSigner(txOpts.Signer, func() error {
+		return fenceErr
+	})
+
+	to := ethcommon.Address{}
+	tx := ethtypes.NewTx(&ethtypes.LegacyTx{
+		To:       &to,
+		Value:    big.NewInt(1),
+		Gas:      21000,
+		GasPrice: big.NewInt(1),
+	})
+
+	_, err = signer(txOpts.From, tx)
+	require.NoError(t, err)
+
+	fenceErr = errors.New("lease lost")
+	_, err = signer(txOpts.From, tx)
+	require.ErrorIs(t, err, errFenced)
+}
+
+func TestTxOpts_NoPrivateKey(t *testing.T) {
+	b := &backend{chainID: big.NewInt(1)}
+	_, err := b.TxOpts()
+	require.Equal(t, errNoEthereumPrivateKey, err)
+}
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// defaultLogChunkSize is the maximum number of blocks queried by a single eth_getLogs call when backfilling.
+// Many nodes and providers reject log queries over larger ranges.
+const defaultLogChunkSize = 2000
+
+type logFilterer interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	FilterLogs(ctx context.Context, q eth.FilterQuery) ([]ethtypes.Log, error)
+}
+
+// FilterLogsFrom returns the logs matching the query from the given block up to the chain head.
+// The range is queried in chunks of at most defaultLogChunkSize blocks; if a chunk is rejected,
+// for example because it has too many results, it's retried in halves.
+// The query's FromBlock and ToBlock are ignored.
+func FilterLogsFrom(ctx context.Context, ec logFilterer, q eth.FilterQuery, fromBlock uint64) ([]ethtypes.Log, error) {
+	return filterLogsInChunks(ctx, ec, q, fromBlock, defaultLogChunkSize)
+}
+
+func filterLogsInChunks(ctx context.Context, ec logFilterer, q eth.FilterQuery, fromBlock,
+	chunkSize uint64) ([]ethtypes.Log, error) {
+	head, err := ec.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromBlock == 0 {
+		log.Warnf("swap creation block is unknown, filtering logs from genesis; this may take a while")
+	}
+
+	var logs []ethtypes.Log
+	for start := fromBlock; start <= head; {
+		end := start + chunkSize - 1
+		if end > head {
+			end = head
+		}
+
+		q.FromBlock = new(big.Int).SetUint64(start)
+		q.ToBlock = new(big.Int).SetUint64(end)
+		chunk, err := ec.FilterLogs(ctx, q)
+		if err != nil {
+			if chunkSize == 1 || ctx.Err() != nil {
+				return nil, err
+			}
+
+			chunkSize /= 2
+			log.Debugf("failed to filter logs in blocks %d-%d, retrying with %d blocks: %s",
+				start, end, chunkSize, err)
+			continue
+		}
+
+		logs = append(logs, chunk...)
+		start = end + 1
+	}
+
+	return logs, nil
+}
@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogFilterer has one log per block, and rejects queries over more than maxRange blocks.
+type mockLogFilterer struct {
+	head     uint64
+	maxRange uint64
+	queries  [][2]uint64
+}
+
+func (f *mockLogFilterer) BlockNumber(_ context.Context) (uint64, error) {
+	return f.head, nil
+}
+
+func (f *mockLogFilterer) FilterLogs(_ context.Context, q eth.FilterQuery) ([]ethtypes.Log, error) {
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	f.queries = append(f.queries, [2]uint64{from, to})
+	if to-from+1 > f.maxRange {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+
+	var logs []ethtypes.Log
+	for i := from; i <= to; i++ {
+		logs = append(logs, ethtypes.Log{BlockNumber: i})
+	}
+	return logs, nil
+}
+
+func TestFilterLogsInChunks(t *testing.T) {
+	f := &mockLogFilterer{head: 25, maxRange: 10}
+	logs, err := filterLogsInChunks(context.Background(), f, eth.FilterQuery{}, 6, 10)
+	require.NoError(t, err)
+	require.Equal(t, 20, len(logs))
+	require.Equal(t, uint64(6), logs[0].BlockNumber)
+	require.Equal(t, uint64(25), logs[19].BlockNumber)
+	require.Equal(t, [][2]uint64{{6, 15}, {16, 25}}, f.queries)
+}
+
+func TestFilterLogsInChunks_RangeRejected(t *testing.T) {
+	f := &mockLogFilterer{head: 20, maxRange: 4}
+	logs, err := filterLogsInChunks(context.Background(), f, eth.FilterQuery{}, 10, 10)
+	require.NoError(t, err)
+	require.Equal(t, 11, len(logs))
+
+	// the rejected range is retried in halves until it's accepted
+	require.Equal(t, [][2]uint64{{10, 19}, {10, 14}, {10, 11}, {12, 13}, {14, 15}, {16, 17}, {18, 19}, {20, 20}},
+		f.queries)
+}
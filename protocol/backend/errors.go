@@ -5,8 +5,19 @@ import (
 )
 
 var (
-	errMustProvideDaemonEndpoint = errors.New("environment is development, must provide monero daemon endpoint")
-	errNilSwapContractOrAddress  = errors.New("must provide swap contract and address")
-	errReceiptTimeOut            = errors.New("failed to get receipt, timed out")
-	errNoXMRDepositAddress       = errors.New("no xmr deposit address for given id")
+	errMustProvideDaemonEndpoint   = errors.New("environment is development, must provide monero daemon endpoint")
+	errNilSwapContractOrAddress    = errors.New("must provide swap contract and address")
+	errNoXMRDepositAddress         = errors.New("no xmr deposit address for given id")
+	errNoEthereumEndpoints         = errors.New("unable to connect to any ethereum endpoint")
+	errFenced                      = errors.New("refusing to sign transaction, daemon is not active")
+	errBroadcastRequiresPrivateKey = errors.New("broadcast strategies other than external require an ethereum private key") //nolint:lll
+	errNoHDWallet                  = errors.New("backend has no HD wallet")
+	errUnknownSwapAccount          = errors.New("no key for swap account")
+	errNoHDDatabase                = errors.New("swap accounts can't be allocated without a database")
+	errSwapAccountsExhausted       = errors.New("all of the HD wallet's swap account indexes are used")
+	errFundingFailed               = errors.New("transaction funding swap account failed")
+	errTransferFailed              = errors.New("ether transfer failed")
+	errCantTransfer                = errors.New("account's sender can't send transfers")
+	errHDWalletWithExternalSigner  = errors.New("HD wallet swap accounts can't be used with the external signer")
+	errNoEthereumPrivateKey        = errors.New("no ethereum private key, transactions must be signed by the external signer") //nolint:lll
 )
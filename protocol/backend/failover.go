@@ -0,0 +1,450 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	healthCheckInterval = time.Second * 30
+	healthCheckTimeout  = time.Second * 10
+
+	// an endpoint that is more than this many blocks behind the best endpoint is considered unhealthy
+	maxBlocksBehind = 3
+
+	// maximum backoff between attempts to re-establish a subscription
+	resubscribeBackoffMax = time.Second * 30
+
+	// an endpoint that fails to dial isn't dialed again until the backoff has passed
+	dialTimeout   = time.Second * 5
+	redialBackoff = time.Second * 30
+)
+
+// EthClient is the interface to an ethereum node used by the backend.
+// It's implemented by both *ethclient.Client and the failover client.
+type EthClient interface {
+	bind.ContractBackend
+	BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+	SyncProgress(ctx context.Context) (*eth.SyncProgress, error)
+	TransactionByHash(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
+}
+
+// failoverClient is an EthClient that is connected to multiple ethereum endpoints. Calls are
+// made to the current endpoint; if it can't be reached, the call is retried on the other
+// endpoints and the first one that succeeds becomes the current endpoint. Endpoints are also
+// periodically health-checked, so that we move off an endpoint that is down or lagging.
+type failoverClient struct {
+	ctx       context.Context
+	endpoints []string
+	dial      func(ctx context.Context, endpoint string) (*ethclient.Client, error)
+	now       func() time.Time
+
+	sync.RWMutex
+	current    int
+	clients    []*ethclient.Client
+	dialFailed []time.Time // when dialing each endpoint last failed
+}
+
+// NewFailoverClient returns an EthClient that fails over between the given endpoints,
+// in order of preference. At least one endpoint must be reachable.
+func NewFailoverClient(ctx context.Context, endpoints []string) (EthClient, error) {
+	if len(endpoints) == 0 {
+		return nil, errNoEthereumEndpoints
+	}
+
+	c := &failoverClient{
+		ctx:        ctx,
+		endpoints:  endpoints,
+		dial:       ethclient.DialContext,
+		now:        time.Now,
+		clients:    make([]*ethclient.Client, len(endpoints)),
+		dialFailed: make([]time.Time, len(endpoints)),
+	}
+
+	connected := 0
+	for i := range endpoints {
+		if c.redial(i) != nil {
+			connected++
+		}
+	}
+
+	if connected == 0 {
+		return nil, errNoEthereumEndpoints
+	}
+
+	c.checkHealth()
+	go c.healthCheckLoop()
+	return c, nil
+}
+
+// currentIndex returns the index of the current endpoint.
+func (c *failoverClient) currentIndex() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.current
+}
+
+func (c *failoverClient) setCurrent(i int) {
+	c.Lock()
+	defer c.Unlock()
+	if c.current == i {
+		return
+	}
+
+	log.Infof("switching ethereum endpoint from %s to %s", c.endpoints[c.current], c.endpoints[i])
+	c.current = i
+}
+
+// redial returns the given endpoint's client, dialing the endpoint if it isn't connected.
+// It returns nil if the dial fails, or if the last dial failed less than redialBackoff ago.
+func (c *failoverClient) redial(i int) *ethclient.Client {
+	c.RLock()
+	ec, failed := c.clients[i], c.dialFailed[i]
+	c.RUnlock()
+	if ec != nil {
+		return ec
+	}
+
+	if !failed.IsZero() && c.now().Before(failed.Add(redialBackoff)) {
+		return nil
+	}
+
+	// dial without holding the lock, so that calls to the other endpoints aren't blocked
+	ctx, cancel := context.WithTimeout(c.ctx, dialTimeout)
+	defer cancel()
+	ec, err := c.dial(ctx, c.endpoints[i])
+
+	c.Lock()
+	defer c.Unlock()
+	if err != nil {
+		log.Warnf("failed to dial ethereum endpoint %s: %s", c.endpoints[i], err)
+		c.dialFailed[i] = c.now()
+		return nil
+	}
+
+	// another call may have dialed the endpoint in the meantime
+	if c.clients[i] != nil {
+		ec.Close()
+		return c.clients[i]
+	}
+
+	c.clients[i] = ec
+	c.dialFailed[i] = time.Time{}
+	return ec
+}
+
+// call calls fn with the current endpoint's client. If the endpoint can't be reached,
+// fn is retried with the other endpoints in order, and the first to succeed becomes current.
+func (c *failoverClient) call(fn func(*ethclient.Client) error) error {
+	start := c.currentIndex()
+
+	var err error
+	for i := 0; i < len(c.clients); i++ {
+		idx := (start + i) % len(c.clients)
+		ec := c.redial(idx)
+		if ec == nil {
+			continue
+		}
+
+		err = fn(ec)
+		if err == nil || !isConnectionError(err) {
+			c.setCurrent(idx)
+			return err
+		}
+
+		log.Warnf("ethereum endpoint %s failed: %s", c.endpoints[idx], err)
+	}
+
+	if err == nil {
+		return errNoEthereumEndpoints
+	}
+	return err
+}
+
+func (c *failoverClient) healthCheckLoop() {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.Lock()
+			for _, ec := range c.clients {
+				if ec != nil {
+					ec.Close()
+				}
+			}
+			c.Unlock()
+			return
+		case <-ticker.C:
+			c.checkHealth()
+		}
+	}
+}
+
+// checkHealth queries the block number of each endpoint. If the current endpoint is unreachable
+// or lagging behind, it switches to the most preferred healthy endpoint.
+func (c *failoverClient) checkHealth() {
+	heights := make([]uint64, len(c.clients))
+	healthy := make([]bool, len(c.clients))
+	var best uint64
+
+	for i := range c.clients {
+		ec := c.redial(i)
+		if ec == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(c.ctx, healthCheckTimeout)
+		height, err := ec.BlockNumber(ctx)
+		cancel()
+		if err != nil {
+			log.Debugf("ethereum endpoint %s failed health check: %s", c.endpoints[i], err)
+			continue
+		}
+
+		heights[i] = height
+		healthy[i] = true
+		if height > best {
+			best = height
+		}
+	}
+
+	isHealthy := func(i int) bool {
+		return healthy[i] && heights[i]+maxBlocksBehind >= best
+	}
+
+	current := c.currentIndex()
+	if isHealthy(current) {
+		return
+	}
+
+	for i := range c.clients {
+		if isHealthy(i) {
+			c.setCurrent(i)
+			return
+		}
+	}
+
+	log.Warnf("no healthy ethereum endpoints")
+}
+
+// isConnectionError returns true if the error is due to the endpoint being unreachable,
+// as opposed to an error returned by the node itself (eg. a reverted call).
+func isConnectionError(err error) bool {
+	var (
+		netErr  net.Error
+		httpErr rpc.HTTPError
+	)
+
+	switch {
+	case errors.As(err, &netErr),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, rpc.ErrClientQuit):
+		return true
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
+	default:
+		return false
+	}
+}
+
+func (c *failoverClient) BalanceAt(ctx context.Context, account ethcommon.Address,
+	blockNumber *big.Int) (balance *big.Int, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		balance, err = ec.BalanceAt(ctx, account, blockNumber)
+		return err
+	})
+	return balance, err
+}
+
+func (c *failoverClient) BlockNumber(ctx context.Context) (height uint64, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		height, err = ec.BlockNumber(ctx)
+		return err
+	})
+	return height, err
+}
+
+func (c *failoverClient) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		chainID, err = ec.ChainID(ctx)
+		return err
+	})
+	return chainID, err
+}
+
+func (c *failoverClient) CodeAt(ctx context.Context, account ethcommon.Address,
+	blockNumber *big.Int) (code []byte, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		code, err = ec.CodeAt(ctx, account, blockNumber)
+		return err
+	})
+	return code, err
+}
+
+func (c *failoverClient) CallContract(ctx context.Context, call eth.CallMsg,
+	blockNumber *big.Int) (res []byte, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		res, err = ec.CallContract(ctx, call, blockNumber)
+		return err
+	})
+	return res, err
+}
+
+func (c *failoverClient) HeaderByNumber(ctx context.Context, number *big.Int) (header *ethtypes.Header, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		header, err = ec.HeaderByNumber(ctx, number)
+		return err
+	})
+	return header, err
+}
+
+func (c *failoverClient) PendingCodeAt(ctx context.Context, account ethcommon.Address) (code []byte, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		code, err = ec.PendingCodeAt(ctx, account)
+		return err
+	})
+	return code, err
+}
+
+func (c *failoverClient) PendingNonceAt(ctx context.Context, account ethcommon.Address) (nonce uint64, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		nonce, err = ec.PendingNonceAt(ctx, account)
+		return err
+	})
+	return nonce, err
+}
+
+func (c *failoverClient) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		price, err = ec.SuggestGasPrice(ctx)
+		return err
+	})
+	return price, err
+}
+
+func (c *failoverClient) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		tip, err = ec.SuggestGasTipCap(ctx)
+		return err
+	})
+	return tip, err
+}
+
+func (c *failoverClient) EstimateGas(ctx context.Context, call eth.CallMsg) (gas uint64, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		gas, err = ec.EstimateGas(ctx, call)
+		return err
+	})
+	return gas, err
+}
+
+func (c *failoverClient) SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error {
+	return c.call(func(ec *ethclient.Client) error {
+		return ec.SendTransaction(ctx, tx)
+	})
+}
+
+func (c *failoverClient) FilterLogs(ctx context.Context, q eth.FilterQuery) (logs []ethtypes.Log, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		logs, err = ec.FilterLogs(ctx, q)
+		return err
+	})
+	return logs, err
+}
+
+func (c *failoverClient) SyncProgress(ctx context.Context) (progress *eth.SyncProgress, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		progress, err = ec.SyncProgress(ctx)
+		return err
+	})
+	return progress, err
+}
+
+func (c *failoverClient) TransactionByHash(ctx context.Context,
+	txHash ethcommon.Hash) (tx *ethtypes.Transaction, isPending bool, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		tx, isPending, err = ec.TransactionByHash(ctx, txHash)
+		return err
+	})
+	return tx, isPending, err
+}
+
+func (c *failoverClient) TransactionReceipt(ctx context.Context,
+	txHash ethcommon.Hash) (receipt *ethtypes.Receipt, err error) {
+	err = c.call(func(ec *ethclient.Client) error {
+		receipt, err = ec.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
+
+// SubscribeFilterLogs subscribes to logs on the current endpoint. If the subscription fails,
+// eg. because the endpoint went down, it's re-established on the next available endpoint.
+// Logs emitted while re-subscribing are not replayed; callers that can't miss logs should
+// also query them with FilterLogs.
+func (c *failoverClient) SubscribeFilterLogs(ctx context.Context, q eth.FilterQuery,
+	ch chan<- ethtypes.Log) (eth.Subscription, error) {
+	subscribe := func(ctx context.Context) (eth.Subscription, error) {
+		var sub eth.Subscription
+		err := c.call(func(ec *ethclient.Client) error {
+			var err error
+			sub, err = ec.SubscribeFilterLogs(ctx, q, ch)
+			return err
+		})
+		return sub, err
+	}
+
+	// make sure the initial subscription succeeds before returning
+	sub, err := subscribe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	first := true
+	return event.ResubscribeErr(resubscribeBackoffMax, func(ctx context.Context, err error) (event.Subscription, error) {
+		if first {
+			first = false
+			return sub, nil
+		}
+
+		log.Warnf("log subscription failed, re-subscribing: %s", err)
+		c.markUnreachable(c.currentIndex())
+		return subscribe(ctx)
+	}), nil
+}
+
+// markUnreachable moves off the given endpoint if it's the current one.
+func (c *failoverClient) markUnreachable(i int) {
+	if len(c.clients) < 2 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.current == i {
+		c.current = (i + 1) % len(c.clients)
+		log.Infof("switching ethereum endpoint from %s to %s", c.endpoints[i], c.endpoints[c.current])
+	}
+}
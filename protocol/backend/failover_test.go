@@ -0,0 +1,183 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockEthEndpoint returns a JSON-RPC server that responds to eth_blockNumber with the given height.
+// If height is 0, the server responds with an HTTP 500 error, as if the node is down.
+func newMockEthEndpoint(t *testing.T, height uint64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if height == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method != "eth_blockNumber" {
+			_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"unsupported"}}`, req.ID)
+			return
+		}
+
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x%x"}`, req.ID, height)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFailoverClient_EndpointDown(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	down := newMockEthEndpoint(t, 0)
+	up := newMockEthEndpoint(t, 16)
+
+	ec, err := NewFailoverClient(ctx, []string{down.URL, up.URL})
+	require.NoError(t, err)
+	c := ec.(*failoverClient)
+	require.Equal(t, 1, c.currentIndex())
+
+	// the call should still succeed if we're pointed at the endpoint that's down
+	c.setCurrent(0)
+	height, err := ec.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(16), height)
+	require.Equal(t, 1, c.currentIndex())
+}
+
+func TestFailoverClient_EndpointLagging(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lagging := newMockEthEndpoint(t, 10)
+	synced := newMockEthEndpoint(t, 100)
+
+	ec, err := NewFailoverClient(ctx, []string{lagging.URL, synced.URL})
+	require.NoError(t, err)
+	require.Equal(t, 1, ec.(*failoverClient).currentIndex())
+}
+
+func TestFailoverClient_PrefersFirstHealthy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := newMockEthEndpoint(t, 99)
+	second := newMockEthEndpoint(t, 100)
+
+	ec, err := NewFailoverClient(ctx, []string{first.URL, second.URL})
+	require.NoError(t, err)
+	require.Equal(t, 0, ec.(*failoverClient).currentIndex())
+}
+
+func TestFailoverClient_NodeErrorDoesNotFailOver(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := newMockEthEndpoint(t, 100)
+	second := newMockEthEndpoint(t, 100)
+
+	ec, err := NewFailoverClient(ctx, []string{first.URL, second.URL})
+	require.NoError(t, err)
+
+	_, err = ec.SuggestGasPrice(ctx)
+	require.Error(t, err)
+	require.False(t, isConnectionError(err))
+	require.Equal(t, 0, ec.(*failoverClient).currentIndex())
+}
+
+func TestFailoverClient_RedialBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// nothing is listening on the websocket endpoint, so dialing it fails
+	up := newMockEthEndpoint(t, 16)
+	ec, err := NewFailoverClient(ctx, []string{"ws://127.0.0.1:1", up.URL})
+	require.NoError(t, err)
+	c := ec.(*failoverClient)
+
+	now := time.Now()
+	dials := 0
+	c.now = func() time.Time {
+		return now
+	}
+	c.dial = func(ctx context.Context, endpoint string) (*ethclient.Client, error) {
+		dials++
+		return ethclient.DialContext(ctx, endpoint)
+	}
+
+	// the dead endpoint isn't re-dialed on every call
+	c.setCurrent(0)
+	_, err = ec.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, dials)
+
+	// but it's re-dialed once the backoff has passed
+	now = now.Add(redialBackoff)
+	c.setCurrent(0)
+	_, err = ec.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, dials)
+
+	c.setCurrent(0)
+	_, err = ec.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, dials)
+}
+
+func TestFailoverClient_RedialDoesNotBlock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	up := newMockEthEndpoint(t, 16)
+	ec, err := NewFailoverClient(ctx, []string{up.URL, up.URL})
+	require.NoError(t, err)
+	c := ec.(*failoverClient)
+
+	dialing := make(chan struct{})
+	release := make(chan struct{})
+	c.dial = func(ctx context.Context, endpoint string) (*ethclient.Client, error) {
+		close(dialing)
+		<-release
+		return ethclient.DialContext(ctx, endpoint)
+	}
+
+	c.Lock()
+	c.clients[1].Close()
+	c.clients[1] = nil
+	c.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		require.NotNil(t, c.redial(1))
+	}()
+	<-dialing
+
+	// calls to the other endpoint go through while the dial is in progress
+	height, err := ec.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(16), height)
+
+	close(release)
+	<-done
+}
+
+func TestNewFailoverClient_NoEndpoints(t *testing.T) {
+	_, err := NewFailoverClient(context.Background(), nil)
+	require.Equal(t, errNoEthereumEndpoints, err)
+}
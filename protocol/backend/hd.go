@@ -0,0 +1,386 @@
+package backend
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/protocol/txsender"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// hardenedKeyStart is the first hardened BIP-32 index; swap accounts use non-hardened indexes
+	hardenedKeyStart = 1 << 31
+
+	// gas budgeted for each swap contract call when funding a swap account
+	swapCallGasBudget = 200000
+	transferGas       = 21000
+
+	// swap account transactions may be sent well after the account is funded, so the gas
+	// budget is priced at a multiple of the current gas price
+	gasPriceHeadroom = 2
+)
+
+var (
+	// hdBucket holds the HD wallet account index of each swap, keyed by swap ID, and the next
+	// index to be allocated
+	hdBucket     = []byte("hdwallet")
+	nextIndexKey = []byte("next")
+)
+
+// swapKeyIndex returns the index of the HD wallet account used for the swap with the given ID,
+// allocating the next unused index the first time it's called for the swap. Indexes are
+// allocated from a stored counter, so no two swaps share an account, even across restarts.
+// Index 0 is the base account, which funds the swap accounts.
+func (b *backend) swapKeyIndex(id types.Hash) (uint32, error) {
+	if b.hdDB == nil {
+		return 0, errNoHDDatabase
+	}
+
+	b.hdMu.Lock()
+	defer b.hdMu.Unlock()
+
+	v, err := b.hdDB.Get(hdBucket, id[:])
+	if err == nil {
+		return binary.BigEndian.Uint32(v), nil
+	}
+	if !errors.Is(err, db.ErrNotFound) {
+		return 0, err
+	}
+
+	index := uint32(1)
+	v, err = b.hdDB.Get(hdBucket, nextIndexKey)
+	switch {
+	case err == nil:
+		index = binary.BigEndian.Uint32(v)
+	case !errors.Is(err, db.ErrNotFound):
+		return 0, err
+	}
+
+	if index >= hardenedKeyStart {
+		return 0, errSwapAccountsExhausted
+	}
+
+	// the counter is bumped first, so that a crash can only skip an index, never reuse one
+	var next, value [4]byte
+	binary.BigEndian.PutUint32(next[:], index+1)
+	if err = b.hdDB.Put(hdBucket, nextIndexKey, next[:]); err != nil {
+		return 0, err
+	}
+
+	binary.BigEndian.PutUint32(value[:], index)
+	if err = b.hdDB.Put(hdBucket, id[:], value[:]); err != nil {
+		return 0, err
+	}
+
+	return index, nil
+}
+
+// SwapEthAddress returns the ethereum address used for the swap with the given ID. If the backend
+// has an HD wallet, it's a fresh address derived for the swap and its account index is also
+// returned, so it can be recorded for recovery. Otherwise, it's the backend's address.
+func (b *backend) SwapEthAddress(id types.Hash) (ethcommon.Address, *uint32, error) {
+	if b.hdWallet == nil {
+		return b.ethAddress, nil, nil
+	}
+
+	index, err := b.swapKeyIndex(id)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	addr, err := b.RegisterEthKeyIndex(index)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	return addr, &index, nil
+}
+
+// RegisterEthKeyIndex derives the HD wallet account with the given index, so that transactions
+// for swaps using its address can be signed. It's used when recovering swaps.
+func (b *backend) RegisterEthKeyIndex(index uint32) (ethcommon.Address, error) {
+	if b.hdWallet == nil {
+		return ethcommon.Address{}, errNoHDWallet
+	}
+
+	addr, err := b.hdWallet.Address(index)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	b.hdMu.Lock()
+	defer b.hdMu.Unlock()
+	b.hdIndexes[addr] = index
+	return addr, nil
+}
+
+// HasEthAddress returns true if the backend can sign transactions for the given address.
+func (b *backend) HasEthAddress(addr ethcommon.Address) bool {
+	if addr == b.ethAddress {
+		return true
+	}
+
+	b.hdMu.Lock()
+	defer b.hdMu.Unlock()
+	_, has := b.hdIndexes[addr]
+	return has
+}
+
+// senderFor returns the sender that signs with the given address's key.
+func (b *backend) senderFor(addr ethcommon.Address) (txsender.Sender, error) {
+	if b.hdWallet == nil || addr == b.ethAddress {
+		return b.Sender, nil
+	}
+
+	b.hdMu.Lock()
+	defer b.hdMu.Unlock()
+
+	if s, has := b.hdSenders[addr]; has {
+		return s, nil
+	}
+
+	txOpts, err := b.swapAccountTxOpts(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var s txsender.Sender
+	if b.broadcast != nil {
+		s, err = txsender.NewSenderWithBroadcastConfig(b.ctx, b.ethClient, b.contract, txOpts, b.confirmations,
+			b.broadcast, nil)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		s = txsender.NewSenderWithPrivateKey(b.ctx, b.ethClient, b.contract, txOpts, b.confirmations)
+	}
+
+	gas := b.GasConfig()
+	s.SetGasConfig(&gas)
+	b.hdSenders[addr] = s
+	return s, nil
+}
+
+// swapAccountTxOpts returns transactor options that sign with the given swap account's key. It
+// must be called with hdMu held.
+func (b *backend) swapAccountTxOpts(addr ethcommon.Address) (*bind.TransactOpts, error) {
+	index, has := b.hdIndexes[addr]
+	if !has {
+		return nil, fmt.Errorf("%w: %s", errUnknownSwapAccount, addr)
+	}
+
+	pk, err := b.hdWallet.PrivateKey(index)
+	if err != nil {
+		return nil, err
+	}
+
+	txOpts, err := bind.NewKeyedTransactorWithChainID(pk, b.chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.fence != nil {
+		txOpts.Signer = fencedSigner(txOpts.Signer, b.fence)
+	}
+
+	return txOpts, nil
+}
+
+// fundSwapAccount tops up the given swap account from the base account, so that it holds at
+// least the given value plus enough gas for the given number of swap contract calls.
+func (b *backend) fundSwapAccount(addr ethcommon.Address, value *big.Int, calls int64) error {
+	gasPrice, err := b.GasPrice(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	need := new(big.Int).Mul(gasPrice, big.NewInt(swapCallGasBudget*calls*gasPriceHeadroom))
+	if value != nil {
+		need.Add(need, value)
+	}
+
+	balance, err := b.ethClient.BalanceAt(b.ctx, addr, nil)
+	if err != nil {
+		return err
+	}
+
+	if balance.Cmp(need) >= 0 {
+		return nil
+	}
+
+	amount := new(big.Int).Sub(need, balance)
+	tx, err := b.sendTransfer(b.ethAddress, addr, amount, gasPrice)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("funding swap account %s with %s ETH: txHash=%s", addr, common.EtherAmount(*amount).AsEther(),
+		tx.Hash())
+
+	receipt, err := txsender.WaitForReceipt(b.ctx, b.ethClient, tx.Hash(), b.confirmations)
+	if err != nil {
+		return err
+	}
+
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return fmt.Errorf("%w: txHash=%s", errFundingFailed, tx.Hash())
+	}
+
+	return nil
+}
+
+// NewSwap locks the swap's ether from the swap's account, which is funded from the base account
+// first if the backend has an HD wallet.
+func (b *backend) NewSwap(id types.Hash, _pubKeyClaim [32]byte, _pubKeyRefund [32]byte,
+	_claimer ethcommon.Address, _timeoutDuration *big.Int, _nonce *big.Int,
+	value *big.Int) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	addr, _, err := b.SwapEthAddress(id)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	if addr != b.ethAddress {
+		// new_swap, set_ready and refund may all be sent from the swap account
+		if err = b.fundSwapAccount(addr, value, 3); err != nil {
+			return ethcommon.Hash{}, nil, fmt.Errorf("failed to fund swap account %s: %w", addr, err)
+		}
+	}
+
+	s, err := b.senderFor(addr)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	return s.NewSwap(id, _pubKeyClaim, _pubKeyRefund, _claimer, _timeoutDuration, _nonce, value)
+}
+
+// SetReady sends set_ready from the swap's owner account.
+func (b *backend) SetReady(id types.Hash,
+	_swap swapfactory.SwapFactorySwap) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	s, err := b.senderFor(_swap.Owner)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	return s.SetReady(id, _swap)
+}
+
+// Claim sends claim from the swap's claimer account, funding it with gas first if it's an HD
+// wallet swap account.
+func (b *backend) Claim(id types.Hash, _swap swapfactory.SwapFactorySwap,
+	_s [32]byte, opts ...txsender.SendOption) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	s, err := b.senderFor(_swap.Claimer)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	if b.hdWallet != nil && _swap.Claimer != b.ethAddress {
+		if err = b.fundSwapAccount(_swap.Claimer, nil, 1); err != nil {
+			return ethcommon.Hash{}, nil, fmt.Errorf("failed to fund swap account %s: %w", _swap.Claimer, err)
+		}
+	}
+
+	return s.Claim(id, _swap, _s, opts...)
+}
+
+// Refund sends refund from the swap's owner account.
+func (b *backend) Refund(id types.Hash, _swap swapfactory.SwapFactorySwap,
+	_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	s, err := b.senderFor(_swap.Owner)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	return s.Refund(id, _swap, _s)
+}
+
+// sendTransfer sends a transfer from one of our accounts through the account's sender, so that it
+// doesn't take the nonce of a contract call the sender is sending at the same time.
+func (b *backend) sendTransfer(from, to ethcommon.Address, amount, gasPrice *big.Int) (*ethtypes.Transaction, error) {
+	if from == b.ethAddress && b.ethPrivKey == nil {
+		return nil, errNoEthereumPrivateKey
+	}
+
+	s, err := b.senderFor(from)
+	if err != nil {
+		return nil, err
+	}
+
+	t, ok := s.(txsender.Transferer)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errCantTransfer, from)
+	}
+
+	return t.Transfer(to, amount, gasPrice)
+}
+
+// TransferETH sends the given amount of ether from one of our accounts, either the base account or
+// a swap account, to the given address, and waits for the transfer to be included.
+func (b *backend) TransferETH(from, to ethcommon.Address, amount *big.Int) (ethcommon.Hash, error) {
+	gasPrice, err := b.GasPrice(b.ctx)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	return b.transferETH(from, to, amount, gasPrice)
+}
+
+// SweepSwapAccount sends what's left in the given swap account back to the base account, once the
+// swap that used it is over, and waits for the transfer to be included. It does nothing if the
+// address isn't an HD wallet swap account, or if what's left doesn't cover the transfer's gas.
+func (b *backend) SweepSwapAccount(addr ethcommon.Address) (ethcommon.Hash, error) {
+	if b.hdWallet == nil || addr == b.ethAddress || !b.HasEthAddress(addr) {
+		return ethcommon.Hash{}, nil
+	}
+
+	gasPrice, err := b.GasPrice(b.ctx)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	balance, err := b.ethClient.BalanceAt(b.ctx, addr, nil)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	amount := new(big.Int).Sub(balance, new(big.Int).Mul(gasPrice, big.NewInt(transferGas)))
+	if amount.Sign() <= 0 {
+		return ethcommon.Hash{}, nil
+	}
+
+	log.Infof("sweeping %s ETH from swap account %s back to %s", common.EtherAmount(*amount).AsEther(),
+		addr, b.ethAddress)
+	return b.transferETH(addr, b.ethAddress, amount, gasPrice)
+}
+
+func (b *backend) transferETH(from, to ethcommon.Address, amount, gasPrice *big.Int) (ethcommon.Hash, error) {
+	if from != b.ethAddress && b.hdWallet == nil {
+		return ethcommon.Hash{}, fmt.Errorf("%w: %s", errUnknownSwapAccount, from)
+	}
+
+	tx, err := b.sendTransfer(from, to, amount, gasPrice)
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	receipt, err := txsender.WaitForReceipt(b.ctx, b.ethClient, tx.Hash(), b.confirmations)
+	if err != nil {
+		return tx.Hash(), err
+	}
+
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return tx.Hash(), fmt.Errorf("%w: txHash=%s", errTransferFailed, tx.Hash())
+	}
+
+	return tx.Hash(), nil
+}
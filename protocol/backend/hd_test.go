@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/crypto/hdwallet"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/protocol/txsender"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func newTestHDBackend(t *testing.T) *backend {
+	w, err := hdwallet.NewWallet(testMnemonic)
+	require.NoError(t, err)
+
+	base, err := w.Address(0)
+	require.NoError(t, err)
+
+	return &backend{
+		ethAddress: base,
+		hdWallet:   w,
+		hdDB:       db.NewMemoryDatabase(),
+		hdIndexes:  make(map[ethcommon.Address]uint32),
+		hdSenders:  make(map[ethcommon.Address]txsender.Sender),
+	}
+}
+
+func TestSwapKeyIndex(t *testing.T) {
+	b := newTestHDBackend(t)
+
+	// indexes are allocated in turn, skipping the base account's
+	index, err := b.swapKeyIndex(types.Hash{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), index)
+	index, err = b.swapKeyIndex(types.Hash{3, 2, 1})
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), index)
+
+	index, err = b.swapKeyIndex(types.Hash{1, 2, 3})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), index)
+
+	// they're stored, so a restarted backend doesn't reuse them
+	restarted := newTestHDBackend(t)
+	restarted.hdDB = b.hdDB
+	index, err = restarted.swapKeyIndex(types.Hash{4})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), index)
+	index, err = restarted.swapKeyIndex(types.Hash{3, 2, 1})
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), index)
+}
+
+func TestSwapKeyIndex_NoDatabase(t *testing.T) {
+	b := newTestHDBackend(t)
+	b.hdDB = nil
+	_, _, err := b.SwapEthAddress(types.Hash{1})
+	require.Equal(t, errNoHDDatabase, err)
+}
+
+func TestSweepSwapAccount_NotSwapAccount(t *testing.T) {
+	b := newTestHDBackend(t)
+
+	// neither the base account nor unknown addresses are swept
+	txHash, err := b.SweepSwapAccount(b.ethAddress)
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.Hash{}, txHash)
+	txHash, err = b.SweepSwapAccount(ethcommon.Address{9})
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.Hash{}, txHash)
+}
+
+func TestSwapEthAddress(t *testing.T) {
+	b := newTestHDBackend(t)
+	id := types.Hash{1, 2, 3}
+
+	addr, index, err := b.SwapEthAddress(id)
+	require.NoError(t, err)
+	require.NotNil(t, index)
+	require.Equal(t, uint32(1), *index)
+	require.NotEqual(t, b.ethAddress, addr)
+	require.True(t, b.HasEthAddress(addr))
+
+	expected, err := b.hdWallet.Address(*index)
+	require.NoError(t, err)
+	require.Equal(t, expected, addr)
+}
+
+func TestSwapEthAddress_NoHDWallet(t *testing.T) {
+	b := &backend{ethAddress: ethcommon.Address{1}}
+	addr, index, err := b.SwapEthAddress(types.Hash{1})
+	require.NoError(t, err)
+	require.Nil(t, index)
+	require.Equal(t, b.ethAddress, addr)
+
+	_, err = b.RegisterEthKeyIndex(1)
+	require.Equal(t, errNoHDWallet, err)
+}
+
+func TestRegisterEthKeyIndex(t *testing.T) {
+	b := newTestHDBackend(t)
+
+	// the well-known address of the test mnemonic at index 1
+	addr := ethcommon.HexToAddress("0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0")
+	require.False(t, b.HasEthAddress(addr))
+
+	_, err := b.senderFor(addr)
+	require.True(t, errors.Is(err, errUnknownSwapAccount))
+
+	registered, err := b.RegisterEthKeyIndex(1)
+	require.NoError(t, err)
+	require.Equal(t, addr, registered)
+	require.True(t, b.HasEthAddress(addr))
+}
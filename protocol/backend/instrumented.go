@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/noot/atomic-swap/metrics"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// instrumentedClient is an EthClient that records how long each call to the ethereum node takes,
+// labelled with the JSON-RPC method it makes.
+type instrumentedClient struct {
+	ec EthClient
+}
+
+// NewInstrumentedClient returns an EthClient that makes its calls with the given client, and
+// records how long they take in the daemon's metrics.
+func NewInstrumentedClient(ec EthClient) EthClient {
+	return &instrumentedClient{
+		ec: ec,
+	}
+}
+
+func observeEthCall(method string, start time.Time, err error) {
+	metrics.ObserveRPC(metrics.Ethereum, method, start, err)
+}
+
+func (c *instrumentedClient) BalanceAt(ctx context.Context, account ethcommon.Address,
+	blockNumber *big.Int) (balance *big.Int, err error) {
+	start := time.Now()
+	balance, err = c.ec.BalanceAt(ctx, account, blockNumber)
+	observeEthCall("eth_getBalance", start, err)
+	return balance, err
+}
+
+func (c *instrumentedClient) BlockNumber(ctx context.Context) (height uint64, err error) {
+	start := time.Now()
+	height, err = c.ec.BlockNumber(ctx)
+	observeEthCall("eth_blockNumber", start, err)
+	return height, err
+}
+
+func (c *instrumentedClient) ChainID(ctx context.Context) (chainID *big.Int, err error) {
+	start := time.Now()
+	chainID, err = c.ec.ChainID(ctx)
+	observeEthCall("eth_chainId", start, err)
+	return chainID, err
+}
+
+func (c *instrumentedClient) CodeAt(ctx context.Context, account ethcommon.Address,
+	blockNumber *big.Int) (code []byte, err error) {
+	start := time.Now()
+	code, err = c.ec.CodeAt(ctx, account, blockNumber)
+	observeEthCall("eth_getCode", start, err)
+	return code, err
+}
+
+func (c *instrumentedClient) CallContract(ctx context.Context, call eth.CallMsg,
+	blockNumber *big.Int) (res []byte, err error) {
+	start := time.Now()
+	res, err = c.ec.CallContract(ctx, call, blockNumber)
+	observeEthCall("eth_call", start, err)
+	return res, err
+}
+
+func (c *instrumentedClient) HeaderByNumber(ctx context.Context, number *big.Int) (header *ethtypes.Header, err error) {
+	start := time.Now()
+	header, err = c.ec.HeaderByNumber(ctx, number)
+	observeEthCall("eth_getBlockByNumber", start, err)
+	return header, err
+}
+
+func (c *instrumentedClient) PendingCodeAt(ctx context.Context, account ethcommon.Address) (code []byte, err error) {
+	start := time.Now()
+	code, err = c.ec.PendingCodeAt(ctx, account)
+	observeEthCall("eth_getCode", start, err)
+	return code, err
+}
+
+func (c *instrumentedClient) PendingNonceAt(ctx context.Context, account ethcommon.Address) (nonce uint64, err error) {
+	start := time.Now()
+	nonce, err = c.ec.PendingNonceAt(ctx, account)
+	observeEthCall("eth_getTransactionCount", start, err)
+	return nonce, err
+}
+
+func (c *instrumentedClient) SuggestGasPrice(ctx context.Context) (price *big.Int, err error) {
+	start := time.Now()
+	price, err = c.ec.SuggestGasPrice(ctx)
+	observeEthCall("eth_gasPrice", start, err)
+	return price, err
+}
+
+func (c *instrumentedClient) SuggestGasTipCap(ctx context.Context) (tip *big.Int, err error) {
+	start := time.Now()
+	tip, err = c.ec.SuggestGasTipCap(ctx)
+	observeEthCall("eth_maxPriorityFeePerGas", start, err)
+	return tip, err
+}
+
+func (c *instrumentedClient) EstimateGas(ctx context.Context, call eth.CallMsg) (gas uint64, err error) {
+	start := time.Now()
+	gas, err = c.ec.EstimateGas(ctx, call)
+	observeEthCall("eth_estimateGas", start, err)
+	return gas, err
+}
+
+func (c *instrumentedClient) SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error {
+	start := time.Now()
+	err := c.ec.SendTransaction(ctx, tx)
+	observeEthCall("eth_sendRawTransaction", start, err)
+	return err
+}
+
+func (c *instrumentedClient) FilterLogs(ctx context.Context, q eth.FilterQuery) (logs []ethtypes.Log, err error) {
+	start := time.Now()
+	logs, err = c.ec.FilterLogs(ctx, q)
+	observeEthCall("eth_getLogs", start, err)
+	return logs, err
+}
+
+func (c *instrumentedClient) SyncProgress(ctx context.Context) (progress *eth.SyncProgress, err error) {
+	start := time.Now()
+	progress, err = c.ec.SyncProgress(ctx)
+	observeEthCall("eth_syncing", start, err)
+	return progress, err
+}
+
+func (c *instrumentedClient) TransactionByHash(ctx context.Context,
+	txHash ethcommon.Hash) (tx *ethtypes.Transaction, isPending bool, err error) {
+	start := time.Now()
+	tx, isPending, err = c.ec.TransactionByHash(ctx, txHash)
+	observeEthCall("eth_getTransactionByHash", start, err)
+	return tx, isPending, err
+}
+
+func (c *instrumentedClient) TransactionReceipt(ctx context.Context,
+	txHash ethcommon.Hash) (receipt *ethtypes.Receipt, err error) {
+	start := time.Now()
+	receipt, err = c.ec.TransactionReceipt(ctx, txHash)
+	observeEthCall("eth_getTransactionReceipt", start, err)
+	return receipt, err
+}
+
+func (c *instrumentedClient) SubscribeFilterLogs(ctx context.Context, q eth.FilterQuery,
+	ch chan<- ethtypes.Log) (sub eth.Subscription, err error) {
+	start := time.Now()
+	sub, err = c.ec.SubscribeFilterLogs(ctx, q, ch)
+	observeEthCall("eth_subscribe", start, err)
+	return sub, err
+}
@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+const httpTimeout = time.Second * 30
+
+type agentTarget struct {
+	url    *url.URL
+	client *http.Client
+}
+
+// NewAgentTarget returns a Target that writes files to a remote backup agent by
+// sending a PUT request to <url>/<name> for each file.
+func NewAgentTarget(u *url.URL) Target {
+	return &agentTarget{
+		url: u,
+		client: &http.Client{
+			Timeout: httpTimeout,
+		},
+	}
+}
+
+func (t *agentTarget) Write(name string, data []byte) error {
+	u := *t.url
+	u.Path = path.Join(u.Path, path.Base(name))
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return doRequest(t.client, req)
+}
+
+func (t *agentTarget) String() string {
+	return t.url.Redacted()
+}
+
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	if err = resp.Body.Close(); err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("backup request failed with status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package backup provides secondary backup targets for swap secret files. Files are encrypted
+// before being written, so the backup location doesn't need to be trusted.
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var (
+	log = logging.Logger("backup")
+
+	errNoPassword       = errors.New("must provide a password to encrypt backups with")
+	errInvalidS3URI     = errors.New("invalid s3 URI; must be of the form s3://bucket/prefix")
+	errNoS3Credentials  = errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to use an s3 backup target")
+	errInvalidDirectory = errors.New("backup directory must be an absolute path")
+
+	errInvalidSFTPURI    = errors.New("invalid sftp URI; must be of the form sftp://user@host[:port]/dir")
+	errNoSFTPCredentials = errors.New("SFTP_PRIVATE_KEY_FILE or SFTP_PASSWORD must be set to use an sftp backup target")
+)
+
+// Target is a location that backup files can be written to.
+type Target interface {
+	// Write writes the file with the given name, overwriting it if it already exists.
+	Write(name string, data []byte) error
+	String() string
+}
+
+// Backup encrypts files and synchronously writes them to a backup Target.
+type Backup struct {
+	target   Target
+	password []byte
+}
+
+// NewBackup returns a new *Backup that encrypts files with the given password
+// and writes them to the given target.
+func NewBackup(target Target, password []byte) (*Backup, error) {
+	if len(password) == 0 {
+		return nil, errNoPassword
+	}
+
+	return &Backup{
+		target:   target,
+		password: password,
+	}, nil
+}
+
+// NewTarget parses a target URI and returns the corresponding Target. The URI can be one of:
+// - an absolute directory path, eg. /mnt/backup/swaps
+// - an S3-compatible bucket, eg. s3://bucket/prefix; credentials and endpoint are read from the environment
+// - an SFTP server, eg. sftp://user@host/backups; credentials are read from the environment
+// - a remote backup agent, eg. https://backup.example.com/swaps; files are PUT to <uri>/<name>
+func NewTarget(uri string) (Target, error) {
+	switch {
+	case strings.HasPrefix(uri, "s3://"):
+		return newS3TargetFromURI(uri)
+	case strings.HasPrefix(uri, "sftp://"):
+		return newSFTPTargetFromURI(uri)
+	case strings.HasPrefix(uri, "http://"), strings.HasPrefix(uri, "https://"):
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAgentTarget(u), nil
+	case strings.HasPrefix(uri, "/"):
+		return NewDirTarget(uri), nil
+	default:
+		return nil, errInvalidDirectory
+	}
+}
+
+// Write encrypts the given data and writes it to the backup target.
+func (b *Backup) Write(name string, data []byte) error {
+	enc, err := Encrypt(b.password, data)
+	if err != nil {
+		return err
+	}
+
+	if err = b.target.Write(name+".enc", enc); err != nil {
+		return fmt.Errorf("failed to write %s to backup %s: %w", name, b.target, err)
+	}
+
+	log.Debugf("wrote %s to backup %s", name, b.target)
+	return nil
+}
+
+// String returns the backup target as a string.
+func (b *Backup) String() string {
+	return b.target.String()
+}
@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+)
+
+type dirTarget struct {
+	dir string
+}
+
+// NewDirTarget returns a Target that writes files to the given directory, which should be
+// on a different disk than the daemon's basepath.
+func NewDirTarget(dir string) Target {
+	return &dirTarget{
+		dir: dir,
+	}
+}
+
+func (t *dirTarget) Write(name string, data []byte) error {
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that a crash can't leave a partially written backup
+	path := filepath.Join(t.dir, filepath.Base(name))
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(filepath.Clean(tmp), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err = file.Write(data); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err = file.Sync(); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (t *dirTarget) String() string {
+	return t.dir
+}
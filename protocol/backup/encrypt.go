@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	saltLength = 16
+
+	// scrypt parameters; backups are written several times per swap, so the cost is kept
+	// lower than for long-lived keystores
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var errCiphertextTooShort = errors.New("ciphertext too short")
+
+// Encrypt encrypts the given plaintext with a key derived from the password.
+// The output is salt || nonce || ciphertext.
+func Encrypt(password, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append(salt, nonce...) //nolint:gocritic
+	return aead.Seal(out, nonce, plaintext, nil), nil
+}
+
+// Decrypt decrypts data encrypted with Encrypt.
+func Decrypt(password, data []byte) ([]byte, error) {
+	if len(data) < saltLength {
+		return nil, errCiphertextTooShort
+	}
+
+	aead, err := newAEAD(password, data[:saltLength])
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[saltLength:]
+	if len(data) < aead.NonceSize() {
+		return nil, errCiphertextTooShort
+	}
+
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+func newAEAD(password, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(password, salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
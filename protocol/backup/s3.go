@@ -0,0 +1,223 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region  = "us-east-1"
+	s3DateFormat     = "20060102"
+	s3DateTimeFormat = "20060102T150405Z"
+)
+
+type s3Target struct {
+	endpoint     *url.URL
+	bucket       string
+	prefix       string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	client       *http.Client
+	now          func() time.Time
+}
+
+// newS3TargetFromURI returns a Target that writes files to an S3-compatible bucket given a URI
+// of the form s3://bucket/prefix. Credentials are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and (optionally) AWS_SESSION_TOKEN; the region from AWS_REGION, and the endpoint from
+// AWS_ENDPOINT_URL, which defaults to AWS S3.
+func newS3TargetFromURI(uri string) (Target, error) {
+	bucketAndPrefix := strings.TrimPrefix(uri, "s3://")
+	bucket, prefix := bucketAndPrefix, ""
+	if i := strings.Index(bucketAndPrefix, "/"); i >= 0 {
+		bucket, prefix = bucketAndPrefix[:i], strings.Trim(bucketAndPrefix[i+1:], "/")
+	}
+
+	if bucket == "" {
+		return nil, errInvalidS3URI
+	}
+
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, errNoS3Credentials
+	}
+
+	return &s3Target{
+		endpoint:     u,
+		bucket:       bucket,
+		prefix:       prefix,
+		region:       region,
+		accessKey:    accessKey,
+		secretKey:    secretKey,
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		client: &http.Client{
+			Timeout: httpTimeout,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Write uploads the file using a path-style PutObject request signed with AWS Signature Version 4.
+func (t *s3Target) Write(name string, data []byte) error {
+	key := path.Base(name)
+	if t.prefix != "" {
+		key = t.prefix + "/" + key
+	}
+
+	canonicalURI := "/" + uriEncode(t.bucket) + "/" + uriEncodePath(key)
+	u := *t.endpoint
+	u.RawPath = strings.TrimSuffix(u.Path, "/") + canonicalURI
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + t.bucket + "/" + key
+
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	t.sign(req, data)
+	return doRequest(t.client, req)
+}
+
+func (t *s3Target) sign(req *http.Request, payload []byte) {
+	now := t.now().UTC()
+	amzDate := now.Format(s3DateTimeFormat)
+	date := now.Format(s3DateFormat)
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if t.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", t.sessionToken)
+	}
+
+	signedHeaders, headers := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncodePath(req.URL.Path),
+		"", // no query string
+		headers,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, t.region)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+t.secretKey), []byte(date))
+	key = hmacSHA256(key, []byte(t.region))
+	key = hmacSHA256(key, []byte("s3"))
+	key = hmacSHA256(key, []byte("aws4_request"))
+	signature := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.accessKey,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+// canonicalHeaders returns the names of the request's headers that are signed, which are its host,
+// its date, if it's set, and its x-amz-* headers, and the headers in their canonical form, as
+// specified by AWS Signature Version 4.
+func canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name != "date" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%s:%s\n", name, headers[name])
+	}
+	return strings.Join(names, ";"), sb.String()
+}
+
+func (t *s3Target) String() string {
+	return fmt.Sprintf("s3://%s/%s", t.bucket, t.prefix)
+}
+
+func sha256Hex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+// uriEncode encodes a string as specified by AWS Signature Version 4: every byte
+// except the unreserved characters A-Z, a-z, 0-9, '-', '.', '_' and '~' is percent-encoded.
+func uriEncode(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '.' || c == '_' || c == '~' {
+			sb.WriteByte(c)
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+// uriEncodePath encodes each segment of the given path with uriEncode.
+func uriEncodePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = uriEncode(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,157 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultSFTPPort      = "22"
+	posixRenameExtension = "posix-rename@openssh.com"
+)
+
+type sftpTarget struct {
+	addr   string
+	dir    string
+	config *ssh.ClientConfig
+}
+
+// newSFTPTargetFromURI returns a Target that writes files over SFTP given a URI of the form
+// sftp://user@host[:port]/dir. The private key is read from the file at SFTP_PRIVATE_KEY_FILE and/or
+// the password from SFTP_PASSWORD, and the server's host key is checked against SFTP_KNOWN_HOSTS,
+// which defaults to ~/.ssh/known_hosts.
+func newSFTPTargetFromURI(uri string) (Target, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Hostname() == "" || u.User == nil || u.User.Username() == "" || !strings.HasPrefix(u.Path, "/") {
+		return nil, errInvalidSFTPURI
+	}
+
+	var auth []ssh.AuthMethod
+	if keyFile := os.Getenv("SFTP_PRIVATE_KEY_FILE"); keyFile != "" {
+		key, err := os.ReadFile(filepath.Clean(keyFile)) //nolint:govet
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	if password := os.Getenv("SFTP_PASSWORD"); password != "" {
+		auth = append(auth, ssh.Password(password))
+	}
+
+	if len(auth) == 0 {
+		return nil, errNoSFTPCredentials
+	}
+
+	knownHostsFile := os.Getenv("SFTP_KNOWN_HOSTS")
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(os.Getenv("HOME"), ".ssh", "known_hosts")
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultSFTPPort
+	}
+
+	return &sftpTarget{
+		addr: net.JoinHostPort(u.Hostname(), port),
+		dir:  path.Clean(u.Path),
+		config: &ssh.ClientConfig{
+			User:            u.User.Username(),
+			Auth:            auth,
+			HostKeyCallback: hostKeyCallback,
+			Timeout:         httpTimeout,
+		},
+	}, nil
+}
+
+// Write connects to the server and writes the file over SFTP. Backups are written rarely, so a new
+// connection is made for each one rather than keeping one alive.
+func (t *sftpTarget) Write(name string, data []byte) error {
+	conn, err := ssh.Dial("tcp", t.addr, t.config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close() //nolint:errcheck
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		return err
+	}
+	defer client.Close() //nolint:errcheck
+
+	return writeFile(client, t.dir, path.Base(name), data)
+}
+
+func (t *sftpTarget) String() string {
+	return fmt.Sprintf("sftp://%s@%s%s", t.config.User, t.addr, t.dir)
+}
+
+// writeFile writes the file to a temporary file in the directory first, and then renames it, so that
+// a failed write can't leave a partially written backup.
+func writeFile(client *sftp.Client, dir, name string, data []byte) error {
+	// the directory usually exists already, in which case this fails
+	if err := client.Mkdir(dir); err == nil {
+		if err = client.Chmod(dir, 0700); err != nil {
+			return err
+		}
+	}
+
+	target := path.Join(dir, name)
+	tmp := target + ".tmp"
+	f, err := client.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+
+	if err = f.Chmod(0600); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if _, has := client.HasExtension(posixRenameExtension); has {
+		return client.PosixRename(tmp, target)
+	}
+
+	// version 3 renames fail if the target exists, so it has to be removed first
+	err = client.Remove(target)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return client.Rename(tmp, target)
+}
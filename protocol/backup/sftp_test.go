@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+)
+
+// pipeConn joins the ends of two pipes into the connection the SFTP server is served over.
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// newTestSFTPClient returns a client of an SFTP server that serves the local filesystem.
+func newTestSFTPClient(t *testing.T) *sftp.Client {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	server, err := sftp.NewServer(&pipeConn{serverR, serverW})
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve()
+	}()
+
+	client, err := sftp.NewClientPipe(clientR, clientW)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		// the client waits for the server's end of the connection to close
+		_ = server.Close()
+		_ = client.Close()
+	})
+	return client
+}
+
+func TestWriteFile_SFTP(t *testing.T) {
+	for _, posixRename := range []bool{true, false} {
+		if !posixRename {
+			// servers that predate the posix-rename extension don't advertise it
+			require.NoError(t, sftp.SetSFTPExtensions())
+			t.Cleanup(func() {
+				_ = sftp.SetSFTPExtensions("hardlink@openssh.com", posixRenameExtension, "statvfs@openssh.com")
+			})
+		}
+
+		client := newTestSFTPClient(t)
+		_, has := client.HasExtension(posixRenameExtension)
+		require.Equal(t, posixRename, has)
+
+		// larger than a single write, and written twice so that the first copy is replaced
+		dir := filepath.Join(t.TempDir(), "backups")
+		data := make([]byte, 32768*2+1)
+		data[len(data)-1] = 1
+		require.NoError(t, writeFile(client, dir, "info.enc", []byte("old")))
+		require.NoError(t, writeFile(client, dir, "info.enc", data))
+
+		written, err := os.ReadFile(filepath.Join(dir, "info.enc"))
+		require.NoError(t, err)
+		require.Equal(t, data, written)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		info, err := os.Stat(dir)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0700), info.Mode().Perm())
+		info, err = entries[0].Info()
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func TestNewTarget_SFTP(t *testing.T) {
+	_, err := NewTarget("sftp://host/backups")
+	require.Equal(t, errInvalidSFTPURI, err)
+
+	t.Setenv("SFTP_PRIVATE_KEY_FILE", "")
+	t.Setenv("SFTP_PASSWORD", "")
+	_, err = NewTarget("sftp://user@host/backups")
+	require.Equal(t, errNoSFTPCredentials, err)
+}
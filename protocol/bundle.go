@@ -0,0 +1,65 @@
+package protocol
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/protocol/backup"
+)
+
+const (
+	// RecoveryBundleSuffix is the file extension of exported recovery bundles.
+	RecoveryBundleSuffix = ".bundle"
+
+	recoveryBundleVersion = 1
+)
+
+// RecoveryBundle is everything needed to recover a single swap's funds: its private keys, the
+// contract swap and its ID, and the contract address, as stored in its info file.
+type RecoveryBundle struct {
+	Version     int               `json:"version"`
+	Environment string            `json:"environment"`
+	Info        *InfoFileContents `json:"info"`
+}
+
+// ExportRecoveryBundle returns the recovery bundle of the swap with the given info, encrypted with
+// a key derived from the passphrase.
+func ExportRecoveryBundle(env common.Environment, info *InfoFileContents, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errNoBundlePassphrase
+	}
+
+	bz, err := json.Marshal(&RecoveryBundle{
+		Version:     recoveryBundleVersion,
+		Environment: env.String(),
+		Info:        info,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.Encrypt(passphrase, bz)
+}
+
+// ImportRecoveryBundle decrypts the given recovery bundle with the passphrase it was exported with.
+func ImportRecoveryBundle(data, passphrase []byte) (*RecoveryBundle, error) {
+	bz, err := backup.Decrypt(passphrase, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundle *RecoveryBundle
+	if err = json.Unmarshal(bz, &bundle); err != nil {
+		return nil, err
+	}
+
+	if bundle.Version != recoveryBundleVersion {
+		return nil, errUnsupportedBundleVersion
+	}
+
+	if bundle.Info == nil {
+		return nil, errEmptyBundle
+	}
+
+	return bundle, nil
+}
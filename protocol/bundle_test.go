@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryBundle(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	info := &InfoFileContents{
+		ContractAddress: "0xabcd",
+		ContractSwapID:  [32]byte{9},
+		ContractSwap: swapfactory.SwapFactorySwap{
+			Timeout0: big.NewInt(1),
+			Timeout1: big.NewInt(2),
+			Value:    big.NewInt(3),
+			Nonce:    big.NewInt(4),
+		},
+		PrivateKeyInfo: kp.Info(common.Development),
+		SwapID:         types.Hash{1},
+	}
+
+	bundle, err := ExportRecoveryBundle(common.Development, info, []byte("passphrase"))
+	require.NoError(t, err)
+
+	imported, err := ImportRecoveryBundle(bundle, []byte("passphrase"))
+	require.NoError(t, err)
+	require.Equal(t, common.Development.String(), imported.Environment)
+	require.Equal(t, info, imported.Info)
+
+	_, err = ImportRecoveryBundle(bundle, []byte("wrong"))
+	require.Error(t, err)
+
+	_, err = ExportRecoveryBundle(common.Development, info, nil)
+	require.ErrorIs(t, err, errNoBundlePassphrase)
+}
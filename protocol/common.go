@@ -6,6 +6,9 @@ import (
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
 	"github.com/noot/atomic-swap/crypto/secp256k1"
 	"github.com/noot/atomic-swap/dleq"
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
 // KeysAndProof contains a DLEq proof, a secp256k1 public key,
@@ -76,3 +79,15 @@ func VerifyKeysAndProof(proofStr, secp256k1PubString string) (*secp256k1.PublicK
 
 	return secp256k1Pub, nil
 }
+
+// SignSessionKey signs the network session key sent in our SendKeysMessage with the secp256k1 key
+// that the DLEq proof binds to our swap keys, given as the secret scalar (see the swap states'
+// getSecret).
+func SignSessionKey(secret [32]byte, sessionKey []byte) ([]byte, error) {
+	sk, err := ethcrypto.ToECDSA(secret[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return ethcrypto.Sign(message.SessionKeyDigest(sessionKey), sk)
+}
@@ -6,4 +6,9 @@ import (
 
 var (
 	errInvalidSecp256k1Key = errors.New("secp256k1 public key resulting from proof verification does not match key sent")
+
+	// recovery bundle errors
+	errNoBundlePassphrase       = errors.New("must provide a passphrase to encrypt the recovery bundle with")
+	errUnsupportedBundleVersion = errors.New("unsupported recovery bundle version")
+	errEmptyBundle              = errors.New("recovery bundle does not contain any swap info")
 )
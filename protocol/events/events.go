@@ -0,0 +1,138 @@
+// Package events provides a bus that swap lifecycle events are published to, so that features
+// that react to swaps, such as RPC subscriptions, don't need to hook into the swap states.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("events")
+
+// Type is the kind of an Event.
+type Type string
+
+// Kinds of event.
+const (
+	// OfferMade is published when we make an offer.
+	OfferMade Type = "offerMade"
+	// OfferTaken is published when a swap taking an offer begins, whether we made or took it.
+	OfferTaken Type = "offerTaken"
+	// ETHLocked is published when the ether of a swap is locked in the contract.
+	ETHLocked Type = "ethLocked"
+	// XMRLocked is published when the monero of a swap is locked.
+	XMRLocked Type = "xmrLocked"
+	// Ready is published when the swap contract is set to ready, so the ether can be claimed.
+	Ready Type = "ready"
+	// Claimed is published when a swap completes successfully.
+	Claimed Type = "claimed"
+	// Refunded is published when a swap is refunded.
+	Refunded Type = "refunded"
+	// Aborted is published when a swap aborts before any funds are locked.
+	Aborted Type = "aborted"
+	// NeedsRecovery is published when a swap interrupted by a restart is left to be recovered by hand.
+	NeedsRecovery Type = "needsRecovery"
+	// StatusChanged is published when a swap moves to any other status.
+	StatusChanged Type = "statusChanged"
+)
+
+// subscriberBufferSize is how many events a subscriber can fall behind by before events are
+// dropped for it, so a slow subscriber never blocks a swap.
+const subscriberBufferSize = 64
+
+// Event is something that happened to a swap or offer.
+type Event struct {
+	Type Type `json:"type"`
+	// SwapID is the ID of the swap, which is the ID of the offer it takes
+	SwapID types.Hash   `json:"swapID"`
+	Status types.Status `json:"status"`
+	Time   time.Time    `json:"time"`
+	// Offer is set for offer events
+	Offer *types.Offer `json:"offer,omitempty"`
+	// PeerID is the libp2p peer ID of the swap's counterparty, if it's known
+	PeerID string `json:"peerID,omitempty"`
+}
+
+// TypeForStatus returns the kind of event published when a swap moves to the given status.
+func TypeForStatus(s types.Status) Type {
+	switch s {
+	case types.ETHLocked:
+		return ETHLocked
+	case types.XMRLocked:
+		return XMRLocked
+	case types.ContractReady:
+		return Ready
+	case types.CompletedSuccess:
+		return Claimed
+	case types.CompletedRefund:
+		return Refunded
+	case types.CompletedAbort:
+		return Aborted
+	default:
+		return StatusChanged
+	}
+}
+
+// Bus delivers published events to its subscribers. A nil *Bus drops everything published to it.
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[uint64]chan *Event
+}
+
+// NewBus returns a new Bus.
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[uint64]chan *Event),
+	}
+}
+
+// Publish sends the event to every subscriber. Subscribers that have fallen too far behind miss
+// the event rather than blocking the publisher. If the event's time isn't set, it's set to now.
+func (b *Bus) Publish(e *Event) {
+	if b == nil {
+		return
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+			log.Warnf("dropped %s event for swap %s for a slow subscriber", e.Type, e.SwapID)
+		}
+	}
+}
+
+// Subscribe returns a channel that receives every event published from now on, and a function
+// that unsubscribes, after which the channel is closed.
+func (b *Bus) Subscribe() (<-chan *Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan *Event, subscriberBufferSize)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, id)
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	b := NewBus()
+	ch1, unsubscribe1 := b.Subscribe()
+	ch2, unsubscribe2 := b.Subscribe()
+	defer unsubscribe2()
+
+	b.Publish(&Event{Type: OfferMade, SwapID: types.Hash{1}})
+	for _, ch := range []<-chan *Event{ch1, ch2} {
+		e := <-ch
+		require.Equal(t, OfferMade, e.Type)
+		require.Equal(t, types.Hash{1}, e.SwapID)
+		require.False(t, e.Time.IsZero())
+	}
+
+	// unsubscribed channels are closed, and don't receive any more events
+	unsubscribe1()
+	unsubscribe1()
+	_, ok := <-ch1
+	require.False(t, ok)
+
+	b.Publish(&Event{Type: Claimed})
+	require.Equal(t, Claimed, (<-ch2).Type)
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	b := NewBus()
+	ch, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	// publishing never blocks, so events are dropped once the subscriber's buffer is full
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		b.Publish(&Event{Type: StatusChanged})
+	}
+	require.Len(t, ch, subscriberBufferSize)
+}
+
+func TestBus_Nil(t *testing.T) {
+	var b *Bus
+	b.Publish(&Event{Type: OfferMade})
+}
+
+func TestTypeForStatus(t *testing.T) {
+	require.Equal(t, ETHLocked, TypeForStatus(types.ETHLocked))
+	require.Equal(t, XMRLocked, TypeForStatus(types.XMRLocked))
+	require.Equal(t, Ready, TypeForStatus(types.ContractReady))
+	require.Equal(t, Claimed, TypeForStatus(types.CompletedSuccess))
+	require.Equal(t, Refunded, TypeForStatus(types.CompletedRefund))
+	require.Equal(t, Aborted, TypeForStatus(types.CompletedAbort))
+	require.Equal(t, StatusChanged, TypeForStatus(types.KeysExchanged))
+}
@@ -0,0 +1,144 @@
+// Package export writes the swap manager's history out as CSV or JSON, for accounting and tax
+// purposes.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+)
+
+// Formats that history can be exported in.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+var errInvalidFormat = errors.New("export format must be csv or json")
+
+// Record is an exported swap. Amounts are in ETH and XMR.
+type Record struct {
+	ID             types.Hash         `json:"id"`
+	Status         string             `json:"status"`
+	StartTime      time.Time          `json:"startTime"`
+	EndTime        *time.Time         `json:"endTime,omitempty"`
+	Provided       types.ProvidesCoin `json:"provided"`
+	ProvidedAmount float64            `json:"providedAmount"`
+	Received       types.ProvidesCoin `json:"received"`
+	ReceivedAmount float64            `json:"receivedAmount"`
+	ExchangeRate   types.ExchangeRate `json:"exchangeRate"`
+	// GasUsed and Fees are the totals for the ethereum transactions we sent for the swap
+	GasUsed  uint64   `json:"gasUsed"`
+	Fees     float64  `json:"fees"`
+	TxHashes []string `json:"txHashes"`
+	PeerID   string   `json:"peerID,omitempty"`
+}
+
+var csvHeader = []string{
+	"id", "status", "startTime", "endTime", "provided", "providedAmount", "received", "receivedAmount",
+	"exchangeRate", "gasUsed", "fees", "txHashes", "peerID",
+}
+
+// NewRecord returns the exported record of the swap.
+func NewRecord(info *swap.Info) *Record {
+	r := &Record{
+		ID:             info.ID(),
+		Status:         info.Status().String(),
+		StartTime:      info.StartTime(),
+		Provided:       info.Provides(),
+		ProvidedAmount: info.ProvidedAmount(),
+		Received:       types.ProvidesXMR,
+		ReceivedAmount: info.ReceivedAmount(),
+		ExchangeRate:   info.ExchangeRate(),
+		TxHashes:       []string{},
+		PeerID:         info.PeerID(),
+	}
+
+	if r.Provided == types.ProvidesXMR {
+		r.Received = types.ProvidesETH
+	}
+
+	if end := info.EndTime(); !end.IsZero() {
+		r.EndTime = &end
+	}
+
+	gasUsed, fees := info.TxCosts()
+	r.GasUsed = gasUsed
+	if fees != nil {
+		r.Fees = common.EtherAmount(*fees).AsEther()
+	}
+
+	for _, h := range info.TxHashes() {
+		r.TxHashes = append(r.TxHashes, h.Hex())
+	}
+
+	return r
+}
+
+// Write writes the swaps to w in the given format. CSV has a header row, then a row per swap, with
+// times in RFC 3339 format and transaction hashes separated by spaces. JSON is an array of
+// Records.
+func Write(w io.Writer, format string, swaps []*swap.Info) error {
+	records := make([]*Record, len(swaps))
+	for i, info := range swaps {
+		records[i] = NewRecord(info)
+	}
+
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, records)
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(records)
+	default:
+		return errInvalidFormat
+	}
+}
+
+func writeCSV(w io.Writer, records []*Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		var end string
+		if r.EndTime != nil {
+			end = r.EndTime.Format(time.RFC3339)
+		}
+
+		row := []string{
+			r.ID.String(),
+			r.Status,
+			r.StartTime.Format(time.RFC3339),
+			end,
+			string(r.Provided),
+			formatFloat(r.ProvidedAmount),
+			string(r.Received),
+			formatFloat(r.ReceivedAmount),
+			formatFloat(float64(r.ExchangeRate)),
+			strconv.FormatUint(r.GasUsed, 10),
+			formatFloat(r.Fees),
+			strings.Join(r.TxHashes, " "),
+			r.PeerID,
+		}
+
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
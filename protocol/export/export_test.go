@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSwaps() []*swap.Info {
+	ethSwap := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 0.5, 10, 0.05, types.ExpectingKeys, nil)
+	ethSwap.AddTxHash(ethcommon.Hash{1})
+	ethSwap.AddTxHash(ethcommon.Hash{2})
+	ethSwap.AddTxCost(21000, common.EtherToWei(0.001).BigInt())
+	ethSwap.SetStatus(types.CompletedSuccess)
+
+	xmrSwap := swap.NewInfo(types.Hash{2}, types.ProvidesXMR, 2, 0.1, 0.05, types.ExpectingKeys, nil)
+	xmrSwap.SetPeerID("12D3KooWtest")
+	xmrSwap.SetStatus(types.CompletedAbort)
+	return []*swap.Info{ethSwap, xmrSwap}
+}
+
+func TestWrite_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCSV, newTestSwaps()))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Equal(t, csvHeader, rows[0])
+
+	eth := rows[1]
+	require.Equal(t, types.Hash{1}.String(), eth[0])
+	require.Equal(t, "Success", eth[1])
+	require.NotEmpty(t, eth[3])
+	require.Equal(t, []string{"ETH", "0.5", "XMR", "10", "0.05", "21000", "0.001"}, eth[4:11])
+	require.Equal(t, ethcommon.Hash{1}.Hex()+" "+ethcommon.Hash{2}.Hex(), eth[11])
+
+	xmr := rows[2]
+	require.Equal(t, []string{"XMR", "2", "ETH", "0.1"}, xmr[4:8])
+	require.Equal(t, "", xmr[11])
+	require.Equal(t, "12D3KooWtest", xmr[12])
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, newTestSwaps()))
+
+	var records []*Record
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &records))
+	require.Len(t, records, 2)
+	require.Equal(t, types.Hash{1}, records[0].ID)
+	require.Equal(t, types.ProvidesXMR, records[0].Received)
+	require.Equal(t, uint64(21000), records[0].GasUsed)
+	require.InDelta(t, 0.001, records[0].Fees, 1e-12)
+	require.Len(t, records[0].TxHashes, 2)
+	require.NotNil(t, records[0].EndTime)
+	require.Equal(t, types.ProvidesETH, records[1].Received)
+	require.Empty(t, records[1].TxHashes)
+}
+
+func TestWrite_InvalidFormat(t *testing.T) {
+	var buf bytes.Buffer
+	require.Equal(t, errInvalidFormat, Write(&buf, "xml", nil))
+}
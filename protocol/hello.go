@@ -0,0 +1,45 @@
+package protocol
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// swapTimeoutRanges are the shortest and longest contract timeouts we accept in each environment.
+// Shorter timeouts risk a party not being able to claim or refund in time; longer ones lock funds
+// for too long if the swap fails.
+var swapTimeoutRanges = map[common.Environment][2]time.Duration{
+	common.Mainnet:     {time.Hour, time.Hour * 24 * 7},
+	common.Stagenet:    {time.Minute * 10, time.Hour * 24 * 7},
+	common.Development: {time.Second, time.Hour * 24 * 7},
+}
+
+// SwapTimeoutRange returns the shortest and longest contract timeouts we accept.
+func SwapTimeoutRange(env common.Environment) (time.Duration, time.Duration) {
+	r := swapTimeoutRanges[env]
+	return r[0], r[1]
+}
+
+// NewHello returns the capabilities we advertise when a swap stream opens.
+func NewHello(env common.Environment, chainID *big.Int) *message.Hello {
+	minTimeout, maxTimeout := SwapTimeoutRange(env)
+	return &message.Hello{
+		SchemaVersion:    message.SchemaVersion,
+		MinSchemaVersion: message.MinSchemaVersion,
+		// only ether is supported so far
+		EthAssets:  []ethcommon.Address{{}},
+		MinTimeout: uint64(minTimeout / time.Second),
+		MaxTimeout: uint64(maxTimeout / time.Second),
+		Version:    NewVersionInfo(env, chainID),
+		// snappy compresses the hex-encoded proofs in our messages well, and quickly
+		Compression:     []string{message.Snappy},
+		EnvelopeVersion: message.EnvelopeVersion,
+		Encodings:       []string{message.CBOR},
+		Ping:            true,
+	}
+}
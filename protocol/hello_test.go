@@ -0,0 +1,27 @@
+package protocol
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/net/message"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHello(t *testing.T) {
+	h := NewHello(common.Mainnet, big.NewInt(1))
+	require.Equal(t, uint64(message.SchemaVersion), h.SchemaVersion)
+	require.Equal(t, uint64(3600), h.MinTimeout)
+	require.Equal(t, uint64(3600*24*7), h.MaxTimeout)
+	require.Len(t, h.EthAssets, 1)
+	require.True(t, h.Version.HasFeature(common.FeatureCapabilityHandshake))
+	require.True(t, message.SupportsCompression(h.Compression))
+
+	for _, env := range []common.Environment{common.Mainnet, common.Stagenet, common.Development} {
+		min, max := SwapTimeoutRange(env)
+		require.NotZero(t, min)
+		require.Less(t, min, max)
+	}
+}
@@ -0,0 +1,212 @@
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	recoveryInstructionsSuffix = ".instructions"
+	recoveryWebhookTimeout     = time.Second * 30
+)
+
+var (
+	// URL that recovery instructions are POSTed to when they're written, if set
+	recoveryWebhookMu sync.RWMutex
+	recoveryWebhook   string
+)
+
+// SetRecoveryWebhook sets a URL that recovery instructions are POSTed to (as JSON) when ether is
+// locked, so they can be delivered elsewhere, eg. to an email relay.
+func SetRecoveryWebhook(url string) {
+	recoveryWebhookMu.Lock()
+	defer recoveryWebhookMu.Unlock()
+	recoveryWebhook = url
+}
+
+// RecoveryInstructions describe how to get back the ether locked in a swap without the daemon
+// that locked it. They're written when the ether is locked, and don't contain any secrets; those
+// stay in the info file they point to.
+type RecoveryInstructions struct {
+	SwapID            types.Hash                  `json:"swapID"`
+	Environment       string                      `json:"environment"`
+	ChainID           int64                       `json:"chainID"`
+	ContractAddress   ethcommon.Address           `json:"contractAddress"`
+	ContractSwapID    ethcommon.Hash              `json:"contractSwapID"`
+	ContractSwap      swapfactory.SwapFactorySwap `json:"contractSwap"`
+	ContractSwapBlock uint64                      `json:"contractSwapBlock"`
+	InfoFile          string                      `json:"infoFile"`
+	EthereumKeyIndex  *uint32                     `json:"ethereumKeyIndex,omitempty"`
+
+	// Timeout0 and Timeout1 are the swap's deadlines, from the contract swap
+	Timeout0 time.Time `json:"timeout0"`
+	Timeout1 time.Time `json:"timeout1"`
+
+	Steps    []string `json:"steps"`
+	Commands []string `json:"commands"`
+}
+
+// NewRecoveryInstructions returns the recovery instructions for the ether locked in the given swap.
+func NewRecoveryInstructions(env common.Environment, chainID int64, infofile string, swapID types.Hash,
+	contractAddr ethcommon.Address, contractSwapID [32]byte, swap swapfactory.SwapFactorySwap,
+	swapBlock uint64, keyIndex *uint32) *RecoveryInstructions {
+	r := &RecoveryInstructions{
+		SwapID:            swapID,
+		Environment:       env.String(),
+		ChainID:           chainID,
+		ContractAddress:   contractAddr,
+		ContractSwapID:    contractSwapID,
+		ContractSwap:      swap,
+		ContractSwapBlock: swapBlock,
+		InfoFile:          infofile,
+		EthereumKeyIndex:  keyIndex,
+		Timeout0:          time.Unix(swap.Timeout0.Int64(), 0).UTC(),
+		Timeout1:          time.Unix(swap.Timeout1.Int64(), 0).UTC(),
+	}
+
+	ethKey := "--ethereum-privkey=<file containing the private key of " + swap.Owner.String() + ">"
+	if keyIndex != nil {
+		ethKey = "--ethereum-hd-wallet=<file containing the HD wallet seed>"
+	}
+
+	r.Steps = []string{
+		fmt.Sprintf("%v ether was locked in swap %s on contract %s (chain ID %d) by %s.",
+			common.EtherAmount(*swap.Value).AsEther(), r.ContractSwapID, contractAddr, chainID, swap.Owner),
+		fmt.Sprintf("The secret needed to refund it is in the info file %s. Keep it safe; without it the ether can "+
+			"only be claimed by the counterparty.", infofile),
+		fmt.Sprintf("Until %s, the ether can be refunded unless the counterparty's monero was seen and the swap "+
+			"was set ready.", r.Timeout0.Format(time.RFC3339)),
+		fmt.Sprintf("From %s until %s, the counterparty can claim the ether. If they do, their secret is "+
+			"published on-chain and the command below sweeps the monero they locked to a wallet you control.",
+			r.Timeout0.Format(time.RFC3339), r.Timeout1.Format(time.RFC3339)),
+		fmt.Sprintf("After %s, the ether can always be refunded if it hasn't been claimed. Run the command below "+
+			"from a machine with the info file; it refunds or sweeps, whichever is possible.",
+			r.Timeout1.Format(time.RFC3339)),
+	}
+
+	r.Commands = []string{
+		fmt.Sprintf("swaprecover --env=%s --xmrtaker --infofile=%s --ethereum-endpoint=<ethereum endpoint> "+
+			"--ethereum-chain-id=%d %s --monero-endpoint=<monero-wallet-rpc endpoint>",
+			env, infofile, chainID, ethKey),
+		fmt.Sprintf("swapcli recover --env=%s --instructions=%s --ethereum-endpoint=<ethereum endpoint> "+
+			"--ethereum-chain-id=%d %s --monero-endpoint=<monero-wallet-rpc endpoint>",
+			env, RecoveryInstructionsFilepath(infofile), chainID, ethKey),
+	}
+
+	return r
+}
+
+// RecoveryInstructionsFilepath returns the path the recovery instructions for the swap with the
+// given info file are written to.
+func RecoveryInstructionsFilepath(infofile string) string {
+	return infofile + recoveryInstructionsSuffix + ".json"
+}
+
+// WriteRecoveryInstructions writes the given instructions next to the swap's info file, as JSON
+// and as text, and delivers them to the recovery webhook, if one is set. Delivery happens in the
+// background; failures are logged.
+func WriteRecoveryInstructions(infofile string, r *RecoveryInstructions) error {
+	bz, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err = writeFileAtomic(RecoveryInstructionsFilepath(infofile), bz); err != nil {
+		return err
+	}
+
+	txtPath := filepath.Clean(infofile + recoveryInstructionsSuffix + ".txt")
+	if err = writeFileAtomic(txtPath, []byte(r.String())); err != nil {
+		return err
+	}
+
+	recoveryWebhookMu.RLock()
+	url := recoveryWebhook
+	recoveryWebhookMu.RUnlock()
+	if url != "" {
+		go func() {
+			if err := postWebhook(url, bz); err != nil {
+				log.Warnf("failed to deliver recovery instructions for swap %s: %s", r.SwapID, err)
+			}
+		}()
+	}
+
+	return nil
+}
+
+// ReadRecoveryInstructions reads the JSON recovery instructions at the given path.
+func ReadRecoveryInstructions(path string) (*RecoveryInstructions, error) {
+	bz, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var r *RecoveryInstructions
+	if err = json.Unmarshal(bz, &r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// String returns the instructions as text.
+func (r *RecoveryInstructions) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Recovery instructions for swap %s\n\n", r.SwapID)
+	for i, step := range r.Steps {
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, step)
+	}
+
+	sb.WriteString("\nCommands:\n")
+	for _, cmd := range r.Commands {
+		fmt.Fprintf(&sb, "  %s\n", cmd)
+	}
+
+	fmt.Fprintf(&sb, "\nContract swap (%s, created in block %d):\n", r.ContractSwapID, r.ContractSwapBlock)
+	swap, err := json.MarshalIndent(r.ContractSwap, "  ", "\t")
+	if err == nil {
+		fmt.Fprintf(&sb, "  %s\n", swap)
+	}
+
+	return sb.String()
+}
+
+// postWebhook POSTs the JSON-encoded body to the webhook at the given URL.
+func postWebhook(url string, bz []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), recoveryWebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bz))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRecoveryInstructions(infofile string, keyIndex *uint32) *RecoveryInstructions {
+	swap := swapfactory.SwapFactorySwap{
+		Owner:    ethcommon.Address{1},
+		Claimer:  ethcommon.Address{2},
+		Timeout0: big.NewInt(1650000000),
+		Timeout1: big.NewInt(1650003600),
+		Value:    common.EtherToWei(0.5).BigInt(),
+		Nonce:    big.NewInt(1),
+	}
+
+	return NewRecoveryInstructions(common.Stagenet, 5, infofile, types.Hash{9}, ethcommon.Address{3},
+		[32]byte{4}, swap, 100, keyIndex)
+}
+
+func TestNewRecoveryInstructions(t *testing.T) {
+	r := newTestRecoveryInstructions("/tmp/swap", nil)
+	require.Equal(t, time.Unix(1650000000, 0).UTC(), r.Timeout0)
+	require.Equal(t, time.Unix(1650003600, 0).UTC(), r.Timeout1)
+	require.Len(t, r.Commands, 2)
+	require.True(t, strings.HasPrefix(r.Commands[0], "swaprecover --env=stagenet --xmrtaker --infofile=/tmp/swap"))
+	require.Contains(t, r.Commands[0], "--ethereum-chain-id=5")
+	require.Contains(t, r.Commands[0], "--ethereum-privkey")
+	require.True(t, strings.HasPrefix(r.Commands[1], "swapcli recover --env=stagenet --instructions=/tmp/swap"))
+	require.Contains(t, r.Steps[0], "0.5 ether")
+
+	index := uint32(7)
+	r = newTestRecoveryInstructions("/tmp/swap", &index)
+	require.Contains(t, r.Commands[0], "--ethereum-hd-wallet")
+}
+
+func TestWriteRecoveryInstructions(t *testing.T) {
+	received := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bz, _ := ioutil.ReadAll(r.Body)
+		received <- bz
+	}))
+	defer server.Close()
+
+	SetRecoveryWebhook(server.URL)
+	defer SetRecoveryWebhook("")
+
+	infofile := path.Join(t.TempDir(), "swap")
+	r := newTestRecoveryInstructions(infofile, nil)
+	require.NoError(t, WriteRecoveryInstructions(infofile, r))
+
+	bz, err := os.ReadFile(RecoveryInstructionsFilepath(infofile))
+	require.NoError(t, err)
+	var written *RecoveryInstructions
+	require.NoError(t, json.Unmarshal(bz, &written))
+	require.Equal(t, r.ContractSwapID, written.ContractSwapID)
+	require.Equal(t, r.Timeout1, written.Timeout1)
+	require.Equal(t, r.Commands, written.Commands)
+
+	read, err := ReadRecoveryInstructions(RecoveryInstructionsFilepath(infofile))
+	require.NoError(t, err)
+	require.Equal(t, written, read)
+
+	txt, err := os.ReadFile(infofile + ".instructions.txt")
+	require.NoError(t, err)
+	require.Contains(t, string(txt), r.Commands[0])
+
+	select {
+	case delivered := <-received:
+		require.Equal(t, bz, delivered)
+	case <-time.After(time.Second * 5):
+		t.Fatal("recovery instructions weren't delivered to the webhook")
+	}
+}
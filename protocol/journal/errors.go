@@ -0,0 +1,9 @@
+package journal
+
+import (
+	"errors"
+)
+
+var (
+	errInvalidKey = errors.New("invalid journal entry key")
+)
@@ -0,0 +1,216 @@
+// Package journal implements the write-ahead journal of swap protocol transitions. Every message
+// sent or received and every transaction submitted or confirmed is recorded before it's acted on,
+// so that after a crash, each swap's position can be reconstructed from the journal and the swap
+// finished.
+package journal
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Kind is the kind of transition a journal entry records.
+type Kind string
+
+// Kinds of journal entries.
+const (
+	KindMessageSent     Kind = "messageSent"
+	KindMessageReceived Kind = "messageReceived"
+	KindTxSubmitted     Kind = "txSubmitted"
+	KindTxConfirmed     Kind = "txConfirmed"
+	KindStatus          Kind = "status"
+)
+
+// Entry is a transition of a swap recorded in the journal.
+type Entry struct {
+	Seq     uint64          `json:"seq"`
+	Time    time.Time       `json:"time"`
+	Kind    Kind            `json:"kind"`
+	Message string          `json:"message,omitempty"`
+	TxHash  *ethcommon.Hash `json:"txHash,omitempty"`
+	Status  *types.Status   `json:"status,omitempty"`
+}
+
+// Journal is the append-only journal of every swap's transitions, stored in the database.
+type Journal struct {
+	db db.Database
+
+	mu sync.Mutex
+	// sequence number of each swap's next entry
+	next map[types.Hash]uint64
+	// last status recorded for each swap, so that unchanged statuses aren't recorded again
+	statuses map[types.Hash]types.Status
+}
+
+// NewJournal returns the journal stored in the given database.
+func NewJournal(d db.Database) (*Journal, error) {
+	j := &Journal{
+		db:       d,
+		next:     make(map[types.Hash]uint64),
+		statuses: make(map[types.Hash]types.Status),
+	}
+
+	err := j.iterate(func(id types.Hash, e *Entry) {
+		j.next[id] = e.Seq + 1
+		if e.Status != nil {
+			j.statuses[id] = *e.Status
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// entryKey returns the key of a swap's entry; the keys of a swap's entries sort in sequence order.
+func entryKey(id types.Hash, seq uint64) []byte {
+	key := make([]byte, len(id)+8)
+	copy(key, id[:])
+	binary.BigEndian.PutUint64(key[len(id):], seq)
+	return key
+}
+
+func (j *Journal) iterate(fn func(id types.Hash, e *Entry)) error {
+	return j.db.Iterate(db.JournalBucket, func(key, value []byte) error {
+		if len(key) != len(types.Hash{})+8 {
+			return errInvalidKey
+		}
+
+		var e *Entry
+		if err := json.Unmarshal(value, &e); err != nil {
+			return err
+		}
+
+		var id types.Hash
+		copy(id[:], key)
+		fn(id, e)
+		return nil
+	})
+}
+
+func (j *Journal) append(id types.Hash, e *Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e.Seq = j.next[id]
+	e.Time = time.Now()
+	bz, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err = j.db.Put(db.JournalBucket, entryKey(id, e.Seq), bz); err != nil {
+		return err
+	}
+
+	j.next[id] = e.Seq + 1
+	return nil
+}
+
+// MessageSent records that the message is about to be sent to the swap's counterparty.
+func (j *Journal) MessageSent(id types.Hash, msg message.Message) error {
+	return j.append(id, &Entry{
+		Kind:    KindMessageSent,
+		Message: msg.Type().String(),
+	})
+}
+
+// MessageReceived records that the message was received from the swap's counterparty, before
+// it's handled.
+func (j *Journal) MessageReceived(id types.Hash, msg message.Message) error {
+	return j.append(id, &Entry{
+		Kind:    KindMessageReceived,
+		Message: msg.Type().String(),
+	})
+}
+
+// TxSubmitted records that the transaction is about to be submitted for the swap.
+func (j *Journal) TxSubmitted(id types.Hash, txHash ethcommon.Hash) error {
+	return j.append(id, &Entry{
+		Kind:   KindTxSubmitted,
+		TxHash: &txHash,
+	})
+}
+
+// TxConfirmed records that the swap's transaction was confirmed.
+func (j *Journal) TxConfirmed(id types.Hash, txHash ethcommon.Hash) error {
+	return j.append(id, &Entry{
+		Kind:   KindTxConfirmed,
+		TxHash: &txHash,
+	})
+}
+
+// StatusChanged records the swap's status, if it's changed since it was last recorded.
+func (j *Journal) StatusChanged(id types.Hash, status types.Status) error {
+	j.mu.Lock()
+	last, has := j.statuses[id]
+	j.mu.Unlock()
+	if has && last == status {
+		return nil
+	}
+
+	if err := j.append(id, &Entry{
+		Kind:   KindStatus,
+		Status: &status,
+	}); err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	j.statuses[id] = status
+	j.mu.Unlock()
+	return nil
+}
+
+// Entries returns the swap's entries, in the order they were recorded.
+func (j *Journal) Entries(id types.Hash) ([]*Entry, error) {
+	var entries []*Entry
+	err := j.iterate(func(entryID types.Hash, e *Entry) {
+		if entryID == id {
+			entries = append(entries, e)
+		}
+	})
+	return entries, err
+}
+
+// Replay replays the journal, and returns the position each swap in it had reached, ordered by
+// when the swaps began.
+func (j *Journal) Replay() ([]*Position, error) {
+	positions := make(map[types.Hash]*Position)
+	err := j.iterate(func(id types.Hash, e *Entry) {
+		p, has := positions[id]
+		if !has {
+			p = &Position{
+				ID:      id,
+				Status:  types.ExpectingKeys,
+				started: e.Time,
+			}
+			positions[id] = p
+		}
+
+		p.apply(e)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replayed := make([]*Position, 0, len(positions))
+	for _, p := range positions {
+		replayed = append(replayed, p)
+	}
+
+	sort.Slice(replayed, func(i, k int) bool {
+		return replayed[i].started.Before(replayed[k].started)
+	})
+	return replayed, nil
+}
@@ -0,0 +1,94 @@
+package journal
+
+import (
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournal_Replay(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	j, err := NewJournal(d)
+	require.NoError(t, err)
+
+	// swap 1 locked ether, swap 2 only exchanged keys, and swap 3 completed
+	id1, id2, id3 := types.Hash{1}, types.Hash{2}, types.Hash{3}
+	require.NoError(t, j.MessageSent(id1, &message.SendKeysMessage{}))
+	require.NoError(t, j.MessageReceived(id1, &message.SendKeysMessage{}))
+	require.NoError(t, j.StatusChanged(id1, types.KeysExchanged))
+	require.NoError(t, j.TxSubmitted(id1, ethcommon.Hash{1}))
+	require.NoError(t, j.TxConfirmed(id1, ethcommon.Hash{1}))
+	require.NoError(t, j.TxSubmitted(id1, ethcommon.Hash{2}))
+
+	require.NoError(t, j.MessageReceived(id2, &message.SendKeysMessage{}))
+	require.NoError(t, j.StatusChanged(id2, types.KeysExchanged))
+
+	require.NoError(t, j.StatusChanged(id3, types.ExpectingKeys))
+	require.NoError(t, j.StatusChanged(id3, types.CompletedSuccess))
+
+	// the daemon restarts
+	j, err = NewJournal(d)
+	require.NoError(t, err)
+	positions, err := j.Replay()
+	require.NoError(t, err)
+	require.Len(t, positions, 3)
+
+	p := positions[0]
+	require.Equal(t, id1, p.ID)
+	require.True(t, p.Ongoing())
+	require.True(t, p.FundsMayBeLocked())
+	require.Equal(t, types.KeysExchanged, p.Status)
+	require.Equal(t, "SendKeysMessage", p.LastSent)
+	require.Equal(t, "SendKeysMessage", p.LastReceived)
+	require.Equal(t, []ethcommon.Hash{{2}}, p.Pending())
+
+	p = positions[1]
+	require.Equal(t, id2, p.ID)
+	require.True(t, p.Ongoing())
+	require.False(t, p.FundsMayBeLocked())
+
+	p = positions[2]
+	require.Equal(t, id3, p.ID)
+	require.False(t, p.Ongoing())
+
+	// entries carry on from where they left off
+	require.NoError(t, j.MessageReceived(id2, &message.NotifyETHLocked{}))
+	entries, err := j.Entries(id2)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, uint64(2), entries[2].Seq)
+	require.Equal(t, KindMessageReceived, entries[2].Kind)
+
+	positions, err = j.Replay()
+	require.NoError(t, err)
+	require.True(t, positions[1].FundsMayBeLocked())
+}
+
+func TestJournal_StatusChanged_Unchanged(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	j, err := NewJournal(d)
+	require.NoError(t, err)
+
+	id := types.Hash{1}
+	require.NoError(t, j.StatusChanged(id, types.ExpectingKeys))
+	require.NoError(t, j.StatusChanged(id, types.ExpectingKeys))
+
+	j, err = NewJournal(d)
+	require.NoError(t, err)
+	require.NoError(t, j.StatusChanged(id, types.ExpectingKeys))
+	require.NoError(t, j.StatusChanged(id, types.KeysExchanged))
+
+	entries, err := j.Entries(id)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, types.KeysExchanged, *entries[1].Status)
+}
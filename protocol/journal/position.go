@@ -0,0 +1,78 @@
+package journal
+
+import (
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net/message"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Position is how far a swap had got, reconstructed from its journal entries.
+type Position struct {
+	ID     types.Hash
+	Status types.Status
+	// types of the last messages sent to and received from the counterparty, if any
+	LastSent, LastReceived string
+	// transactions submitted for the swap, and those of them that were confirmed
+	Submitted, Confirmed []ethcommon.Hash
+
+	started time.Time
+	// whether any message was exchanged after the keys, ie. whether either party may have locked funds
+	pastKeyExchange bool
+}
+
+func (p *Position) apply(e *Entry) {
+	switch e.Kind {
+	case KindMessageSent:
+		p.LastSent = e.Message
+	case KindMessageReceived:
+		p.LastReceived = e.Message
+	case KindTxSubmitted:
+		p.Submitted = append(p.Submitted, *e.TxHash)
+	case KindTxConfirmed:
+		p.Confirmed = append(p.Confirmed, *e.TxHash)
+	case KindStatus:
+		p.Status = *e.Status
+	}
+
+	if e.Message != "" && e.Message != message.SendKeysType.String() {
+		p.pastKeyExchange = true
+	}
+}
+
+// Ongoing returns whether the swap hadn't completed.
+func (p *Position) Ongoing() bool {
+	return p.Status.IsOngoing()
+}
+
+// Pending returns the transactions that were submitted but not seen to be confirmed.
+func (p *Position) Pending() []ethcommon.Hash {
+	confirmed := make(map[ethcommon.Hash]struct{}, len(p.Confirmed))
+	for _, txHash := range p.Confirmed {
+		confirmed[txHash] = struct{}{}
+	}
+
+	var pending []ethcommon.Hash
+	for _, txHash := range p.Submitted {
+		if _, has := confirmed[txHash]; !has {
+			pending = append(pending, txHash)
+		}
+	}
+
+	return pending
+}
+
+// FundsMayBeLocked returns whether either party may have locked funds in the swap. If not, the
+// swap can simply be aborted.
+func (p *Position) FundsMayBeLocked() bool {
+	switch {
+	case len(p.Submitted) != 0, p.pastKeyExchange:
+		return true
+	case p.Status == types.ExpectingKeys, p.Status == types.KeysExchanged:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,25 @@
+package protocol
+
+import (
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// livenessTimeouts are how long the counterparty may go without being heard from at each stage of
+// a swap before it's considered gone. They're shortest once funds are locked but the contract isn't
+// ready, as noticing then leaves the most time to recover the swap before t0.
+var livenessTimeouts = map[types.Status]time.Duration{
+	types.ExpectingKeys: time.Minute * 2,
+	types.KeysExchanged: time.Minute * 2,
+	types.ETHLocked:     time.Second * 30,
+	types.XMRLocked:     time.Second * 30,
+	types.ContractReady: time.Minute,
+	types.ClaimFailing:  time.Minute,
+}
+
+// LivenessTimeout returns how long the counterparty may go without being heard from at the given
+// stage of a swap. It returns 0 if the stage has no timeout of its own.
+func LivenessTimeout(status types.Status) time.Duration {
+	return livenessTimeouts[status]
+}
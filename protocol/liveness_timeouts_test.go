@@ -0,0 +1,19 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivenessTimeout(t *testing.T) {
+	for _, status := range []types.Status{types.ExpectingKeys, types.ETHLocked, types.XMRLocked, types.ContractReady} {
+		require.NotZero(t, LivenessTimeout(status))
+	}
+
+	// we notice soonest when funds are locked but the contract isn't ready
+	require.Less(t, LivenessTimeout(types.ETHLocked), LivenessTimeout(types.ExpectingKeys))
+	require.Zero(t, LivenessTimeout(types.CompletedSuccess))
+}
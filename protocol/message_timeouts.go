@@ -0,0 +1,32 @@
+package protocol
+
+import (
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/net/message"
+)
+
+// messageTimeouts are how long a swap waits for each message from the counterparty before giving up on it.
+// Messages expected after both parties have locked funds aren't included, as the swap contract's
+// timeouts already cover them.
+var messageTimeouts = map[common.Environment]map[message.Type]time.Duration{
+	common.Mainnet: {
+		message.NotifyETHLockedType: time.Minute * 30,
+		message.NotifyXMRLockType:   time.Minute * 30,
+	},
+	common.Stagenet: {
+		message.NotifyETHLockedType: time.Minute * 15,
+		message.NotifyXMRLockType:   time.Minute * 15,
+	},
+	common.Development: {
+		message.NotifyETHLockedType: time.Minute * 2,
+		message.NotifyXMRLockType:   time.Minute * 5,
+	},
+}
+
+// MessageTimeout returns how long to wait for a message of the given type from the counterparty
+// before aborting or recovering the swap. It returns 0 if there's no deadline for the message.
+func MessageTimeout(env common.Environment, t message.Type) time.Duration {
+	return messageTimeouts[env][t]
+}
@@ -0,0 +1,26 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/net/message"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageTimeout(t *testing.T) {
+	for _, env := range []common.Environment{common.Mainnet, common.Stagenet, common.Development} {
+		require.NotZero(t, MessageTimeout(env, message.NotifyETHLockedType))
+		require.NotZero(t, MessageTimeout(env, message.NotifyXMRLockType))
+
+		// covered by the swap contract's timeouts
+		require.Zero(t, MessageTimeout(env, message.NotifyReadyType))
+		require.Zero(t, MessageTimeout(env, message.NotifyClaimedType))
+	}
+
+	require.Less(t,
+		MessageTimeout(common.Development, message.NotifyETHLockedType),
+		MessageTimeout(common.Mainnet, message.NotifyETHLockedType),
+	)
+}
@@ -0,0 +1,109 @@
+package oracle
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Mainnet addresses of the Chainlink feeds used by default.
+var (
+	DefaultChainlinkXMRUSDFeed = ethcommon.HexToAddress("0xFA66458Cce7Dd15D8650015c4fce4D278271618F")
+	DefaultChainlinkETHUSDFeed = ethcommon.HexToAddress("0x5f4eC3Df9cbd43714FE2740f5E3616155c5b8419")
+)
+
+// feeds are updated at least daily; a round older than this means the feed has stopped updating
+const maxRoundAge = time.Hour * 25
+
+// the parts of Chainlink's AggregatorV3Interface that we use
+const aggregatorABI = `[
+	{"inputs":[],"name":"decimals","outputs":[{"internalType":"uint8","name":"","type":"uint8"}],
+		"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"latestRoundData","outputs":[
+		{"internalType":"uint80","name":"roundId","type":"uint80"},
+		{"internalType":"int256","name":"answer","type":"int256"},
+		{"internalType":"uint256","name":"startedAt","type":"uint256"},
+		{"internalType":"uint256","name":"updatedAt","type":"uint256"},
+		{"internalType":"uint80","name":"answeredInRound","type":"uint80"}],
+		"stateMutability":"view","type":"function"}
+]`
+
+// ContractCaller is the ethereum client used to read Chainlink feeds.
+type ContractCaller = bind.ContractCaller
+
+type chainlinkSource struct {
+	xmrFeed, ethFeed *bind.BoundContract
+}
+
+// NewChainlinkSource returns a Source that derives XMR's price in ETH from Chainlink's XMR/USD
+// and ETH/USD feeds at the given addresses.
+func NewChainlinkSource(caller ContractCaller, xmrUSDFeed, ethUSDFeed ethcommon.Address) (Source, error) {
+	if caller == nil || xmrUSDFeed == (ethcommon.Address{}) || ethUSDFeed == (ethcommon.Address{}) {
+		return nil, errNoChainlinkFeed
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		return nil, err
+	}
+
+	return &chainlinkSource{
+		xmrFeed: bind.NewBoundContract(xmrUSDFeed, parsed, caller, nil, nil),
+		ethFeed: bind.NewBoundContract(ethUSDFeed, parsed, caller, nil, nil),
+	}, nil
+}
+
+func (s *chainlinkSource) Rate(ctx context.Context) (types.ExchangeRate, error) {
+	xmrUSD, err := latestPrice(ctx, s.xmrFeed)
+	if err != nil {
+		return 0, err
+	}
+
+	ethUSD, err := latestPrice(ctx, s.ethFeed)
+	if err != nil {
+		return 0, err
+	}
+
+	rate, _ := new(big.Float).Quo(xmrUSD, ethUSD).Float64()
+	return types.ExchangeRate(rate), nil
+}
+
+// latestPrice returns the feed's latest answer, checking that it's positive and recent.
+func latestPrice(ctx context.Context, feed *bind.BoundContract) (*big.Float, error) {
+	opts := &bind.CallOpts{Context: ctx}
+
+	var out []interface{}
+	if err := feed.Call(opts, &out, "decimals"); err != nil {
+		return nil, err
+	}
+	decimals := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	out = nil
+	if err := feed.Call(opts, &out, "latestRoundData"); err != nil {
+		return nil, err
+	}
+	answer := *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	updatedAt := *abi.ConvertType(out[3], new(*big.Int)).(**big.Int)
+
+	if answer.Sign() <= 0 {
+		return nil, errInvalidPrice
+	}
+
+	if time.Since(time.Unix(updatedAt.Int64(), 0)) > maxRoundAge {
+		return nil, errStalePrice
+	}
+
+	scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	return new(big.Float).Quo(new(big.Float).SetInt(answer), scale), nil
+}
+
+func (s *chainlinkSource) String() string {
+	return Chainlink
+}
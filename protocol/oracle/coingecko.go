@@ -0,0 +1,70 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// DefaultCoinGeckoURL is the CoinGecko API endpoint that XMR's price in ETH is fetched from.
+const DefaultCoinGeckoURL = "https://api.coingecko.com/api/v3/simple/price?ids=monero&vs_currencies=eth"
+
+const httpTimeout = time.Second * 30
+
+type coinGeckoSource struct {
+	url    string
+	client *http.Client
+}
+
+// NewCoinGeckoSource returns a Source that fetches XMR's price in ETH from the CoinGecko API at
+// the given URL.
+func NewCoinGeckoSource(url string) Source {
+	return &coinGeckoSource{
+		url: url,
+		client: &http.Client{
+			Timeout: httpTimeout,
+		},
+	}
+}
+
+func (s *coinGeckoSource) Rate(ctx context.Context) (types.ExchangeRate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("coingecko request failed with status %s", resp.Status)
+	}
+
+	// eg. {"monero":{"eth":0.0712}}
+	var prices map[string]map[string]float64
+	if err = json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return 0, err
+	}
+
+	price, has := prices["monero"]["eth"]
+	if !has {
+		return 0, errNoPrice
+	}
+
+	if price <= 0 {
+		return 0, errInvalidPrice
+	}
+
+	return types.ExchangeRate(price), nil
+}
+
+func (s *coinGeckoSource) String() string {
+	return CoinGecko
+}
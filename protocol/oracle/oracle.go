@@ -0,0 +1,52 @@
+// Package oracle provides sources of the XMR-ETH index price, which makers can peg their offers'
+// exchange rates to.
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/noot/atomic-swap/common/types"
+)
+
+var (
+	errUnknownSource   = errors.New("unknown price oracle; must be one of coingecko or chainlink")
+	errNoPrice         = errors.New("price oracle returned no price")
+	errInvalidPrice    = errors.New("price oracle returned a non-positive price")
+	errStalePrice      = errors.New("price oracle's latest price is stale")
+	errNoChainlinkFeed = errors.New("chainlink feeds must be set to use the chainlink price oracle")
+)
+
+// Source returns the current index exchange rate of XMR in ETH, in the same form as an offer's
+// exchange rate; ie. 0.1 means 10 XMR = 1 ETH.
+type Source interface {
+	Rate(ctx context.Context) (types.ExchangeRate, error)
+	String() string
+}
+
+// Names of the supported sources, as passed to NewSource.
+const (
+	CoinGecko = "coingecko"
+	Chainlink = "chainlink"
+)
+
+// NewSource returns the named Source. The Chainlink source reads its feeds using the given
+// ethereum client, which must be connected to mainnet unless other feeds are configured.
+func NewSource(name string, caller ContractCaller) (Source, error) {
+	switch strings.ToLower(name) {
+	case CoinGecko:
+		return NewCoinGeckoSource(DefaultCoinGeckoURL), nil
+	case Chainlink:
+		return NewChainlinkSource(caller, DefaultChainlinkXMRUSDFeed, DefaultChainlinkETHUSDFeed)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownSource, name)
+	}
+}
+
+// PeggedRate returns the exchange rate of an offer pegged to the given index rate with the given
+// spread; eg. a spread of 0.01 is 1% above the index.
+func PeggedRate(index types.ExchangeRate, spread float64) types.ExchangeRate {
+	return types.ExchangeRate(float64(index) * (1 + spread))
+}
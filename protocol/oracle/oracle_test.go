@@ -0,0 +1,105 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeggedRate(t *testing.T) {
+	require.InDelta(t, 0.101, float64(PeggedRate(0.1, 0.01)), 1e-9)
+	require.InDelta(t, 0.095, float64(PeggedRate(0.1, -0.05)), 1e-9)
+	require.Equal(t, types.ExchangeRate(0.1), PeggedRate(0.1, 0))
+}
+
+func TestNewSource_Unknown(t *testing.T) {
+	_, err := NewSource("kraken", nil)
+	require.ErrorIs(t, err, errUnknownSource)
+}
+
+func TestCoinGeckoSource(t *testing.T) {
+	body := `{"monero":{"eth":0.0712}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	s := NewCoinGeckoSource(server.URL)
+	rate, err := s.Rate(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, types.ExchangeRate(0.0712), rate)
+
+	body = `{}`
+	_, err = s.Rate(context.Background())
+	require.ErrorIs(t, err, errNoPrice)
+}
+
+// mockCaller answers calls to Chainlink feeds with the given prices, which have 8 decimals.
+type mockCaller struct {
+	prices    map[ethcommon.Address]int64
+	updatedAt time.Time
+}
+
+func (c *mockCaller) CodeAt(context.Context, ethcommon.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (c *mockCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(aggregatorABI))
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := parsed.MethodById(call.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "decimals":
+		return method.Outputs.Pack(uint8(8))
+	default:
+		answer := big.NewInt(c.prices[*call.To])
+		updatedAt := big.NewInt(c.updatedAt.Unix())
+		return method.Outputs.Pack(big.NewInt(1), answer, updatedAt, updatedAt, big.NewInt(1))
+	}
+}
+
+func TestChainlinkSource(t *testing.T) {
+	xmrFeed, ethFeed := ethcommon.Address{1}, ethcommon.Address{2}
+	caller := &mockCaller{
+		prices: map[ethcommon.Address]int64{
+			xmrFeed: 150_00000000,
+			ethFeed: 3000_00000000,
+		},
+		updatedAt: time.Now(),
+	}
+
+	s, err := NewChainlinkSource(caller, xmrFeed, ethFeed)
+	require.NoError(t, err)
+
+	rate, err := s.Rate(context.Background())
+	require.NoError(t, err)
+	require.InDelta(t, 0.05, float64(rate), 1e-9)
+
+	caller.updatedAt = time.Now().Add(-maxRoundAge * 2)
+	_, err = s.Rate(context.Background())
+	require.ErrorIs(t, err, errStalePrice)
+
+	caller.updatedAt = time.Now()
+	caller.prices[xmrFeed] = 0
+	_, err = s.Rate(context.Background())
+	require.ErrorIs(t, err, errInvalidPrice)
+}
@@ -0,0 +1,241 @@
+// Package preflight checks that the daemon's nodes and wallets are ready for a swap before it
+// begins, so that problems are found before any funds are locked rather than mid-swap.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// names of the checks
+const (
+	CheckEthereumSync = "ethereum-sync"
+	CheckMoneroWallet = "monero-wallet"
+	CheckMoneroDaemon = "monero-daemon"
+	CheckETHBalance   = "eth-balance"
+	CheckXMRBalance   = "xmr-balance"
+	CheckGasBudget    = "gas-budget"
+)
+
+const (
+	// gas budgeted for each swap contract call
+	callGasBudget = 200000
+
+	// the ETH provider may call new_swap, set_ready and refund; the XMR provider only calls claim
+	ethProviderCalls = 3
+	xmrProviderCalls = 1
+
+	// maximum number of blocks the monero wallet may be behind monerod
+	maxWalletLag = 10
+)
+
+var (
+	errPreflightFailed = errors.New("preflight checks failed")
+	errUnknownCoin     = errors.New("unknown coin; must be one of ETH or XMR")
+)
+
+// Backend is the subset of protocol/backend.Backend used by the checks.
+type Backend interface {
+	BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error)
+	EthAddress() ethcommon.Address
+	GasPrice(ctx context.Context) (*big.Int, error)
+	SyncProgress(ctx context.Context) (*eth.SyncProgress, error)
+	GetBalance(idx uint) (*monero.GetBalanceResponse, error)
+	GetHeight() (uint, error)
+	GetInfo() (*monero.GetInfoResponse, error)
+	HasDaemonClient() bool
+	LockClient()
+	UnlockClient()
+}
+
+// Check is the result of a single check.
+type Check struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Report is the result of running the checks for a swap.
+type Report struct {
+	Provides types.ProvidesCoin `json:"provides"`
+	Amount   float64            `json:"amount"`
+	Passed   bool               `json:"passed"`
+	Checks   []*Check           `json:"checks"`
+}
+
+// Err returns an error listing the failed checks, or nil if all of them passed.
+func (r *Report) Err() error {
+	if r.Passed {
+		return nil
+	}
+
+	var failed []string
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+	}
+
+	return fmt.Errorf("%w: %s", errPreflightFailed, strings.Join(failed, "; "))
+}
+
+func (r *Report) add(name string, passed bool, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, &Check{
+		Name:    name,
+		Passed:  passed,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Run checks that a swap providing the given amount of the given coin (in standard units) can
+// be completed: that the ethereum node is synced, monero-wallet-rpc and monerod are reachable
+// and synced, and that our balances cover the amount and the gas for our contract calls.
+func Run(ctx context.Context, b Backend, provides types.ProvidesCoin, amount float64) (*Report, error) {
+	if provides != types.ProvidesETH && provides != types.ProvidesXMR {
+		return nil, errUnknownCoin
+	}
+
+	r := &Report{
+		Provides: provides,
+		Amount:   amount,
+	}
+
+	checkEthereumSync(ctx, b, r)
+	checkMonero(b, r, provides, amount)
+	checkETHBalance(ctx, b, r, provides, amount)
+
+	r.Passed = true
+	for _, c := range r.Checks {
+		r.Passed = r.Passed && c.Passed
+	}
+
+	return r, nil
+}
+
+func checkEthereumSync(ctx context.Context, b Backend, r *Report) {
+	progress, err := b.SyncProgress(ctx)
+	switch {
+	case err != nil:
+		r.add(CheckEthereumSync, false, "failed to reach ethereum node: %s", err)
+	case progress != nil:
+		r.add(CheckEthereumSync, false, "ethereum node is syncing: block %d of %d",
+			progress.CurrentBlock, progress.HighestBlock)
+	default:
+		r.add(CheckEthereumSync, true, "ethereum node is synced")
+	}
+}
+
+func checkMonero(b Backend, r *Report, provides types.ProvidesCoin, amount float64) {
+	// we only talk to monerod directly in the development environment; otherwise the wallet
+	// check below is all we can do
+	var info *monero.GetInfoResponse
+	if b.HasDaemonClient() {
+		var err error
+		info, err = b.GetInfo()
+		switch {
+		case err != nil:
+			r.add(CheckMoneroDaemon, false, "failed to reach monerod: %s", err)
+		case info.TargetHeight > info.Height:
+			r.add(CheckMoneroDaemon, false, "monerod is syncing: height %d of %d", info.Height, info.TargetHeight)
+		default:
+			r.add(CheckMoneroDaemon, true, "monerod is synced at height %d", info.Height)
+		}
+	}
+
+	b.LockClient()
+	defer b.UnlockClient()
+
+	height, err := b.GetHeight()
+	switch {
+	case err != nil:
+		r.add(CheckMoneroWallet, false, "failed to reach monero-wallet-rpc: %s", err)
+		return
+	case info != nil && height+maxWalletLag < info.Height:
+		r.add(CheckMoneroWallet, false, "monero wallet is behind monerod: height %d of %d", height, info.Height)
+	default:
+		r.add(CheckMoneroWallet, true, "monero wallet is reachable at height %d", height)
+	}
+
+	if provides != types.ProvidesXMR {
+		return
+	}
+
+	balance, err := b.GetBalance(0)
+	if err != nil {
+		r.add(CheckXMRBalance, false, "failed to get monero balance: %s", err)
+		return
+	}
+
+	unlocked := common.MoneroAmount(balance.UnlockedBalance)
+	if unlocked < common.MoneroToPiconero(amount) {
+		r.add(CheckXMRBalance, false, "unlocked balance %v XMR is lower than %v XMR", unlocked.AsMonero(), amount)
+		return
+	}
+
+	r.add(CheckXMRBalance, true, "unlocked balance %v XMR covers %v XMR", unlocked.AsMonero(), amount)
+}
+
+func checkETHBalance(ctx context.Context, b Backend, r *Report, provides types.ProvidesCoin, amount float64) {
+	balance, err := b.BalanceAt(ctx, b.EthAddress(), nil)
+	if err != nil {
+		r.add(CheckETHBalance, false, "failed to get ether balance: %s", err)
+		return
+	}
+
+	// the ether we provide is locked first, so only what's left over pays for gas
+	remaining := new(big.Int).Set(balance)
+	calls := int64(xmrProviderCalls)
+	if provides == types.ProvidesETH {
+		calls = ethProviderCalls
+		value := common.EtherToWei(amount).BigInt()
+		if balance.Cmp(value) < 0 {
+			r.add(CheckETHBalance, false, "balance %v ETH is lower than %v ETH",
+				common.EtherAmount(*balance).AsEther(), amount)
+			return
+		}
+
+		r.add(CheckETHBalance, true, "balance %v ETH covers %v ETH", common.EtherAmount(*balance).AsEther(), amount)
+		remaining.Sub(remaining, value)
+	}
+
+	gasPrice, err := b.GasPrice(ctx)
+	if err != nil {
+		r.add(CheckGasBudget, false, "failed to get gas price: %s", err)
+		return
+	}
+
+	budget := common.EtherAmount(*new(big.Int).Mul(gasPrice, big.NewInt(callGasBudget*calls)))
+	if remaining.Cmp(budget.BigInt()) < 0 {
+		r.add(CheckGasBudget, false, "%v ETH left for gas is lower than the %v ETH needed for %d contract calls",
+			common.EtherAmount(*remaining).AsEther(), budget.AsEther(), calls)
+		return
+	}
+
+	r.add(CheckGasBudget, true, "%v ETH left for gas covers %d contract calls at %s wei",
+		common.EtherAmount(*remaining).AsEther(), calls, gasPrice)
+}
+
+// Checker runs the checks against a backend.
+type Checker struct {
+	backend Backend
+}
+
+// NewChecker returns a new *Checker.
+func NewChecker(b Backend) *Checker {
+	return &Checker{backend: b}
+}
+
+// Run runs the checks for a swap providing the given amount of the given coin.
+func (c *Checker) Run(ctx context.Context, provides types.ProvidesCoin, amount float64) (*Report, error) {
+	return Run(ctx, c.backend, provides, amount)
+}
@@ -0,0 +1,151 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBackend struct {
+	ethBalance   *big.Int
+	gasPrice     *big.Int
+	syncProgress *eth.SyncProgress
+	xmrBalance   float64
+	walletHeight uint
+	walletErr    error
+	info         *monero.GetInfoResponse
+	noDaemon     bool
+}
+
+func newMockBackend() *mockBackend {
+	return &mockBackend{
+		ethBalance:   common.EtherToWei(1).BigInt(),
+		gasPrice:     big.NewInt(1e9),
+		xmrBalance:   float64(common.MoneroToPiconero(10)),
+		walletHeight: 1000,
+		info:         &monero.GetInfoResponse{Height: 1001},
+	}
+}
+
+func (b *mockBackend) BalanceAt(_ context.Context, _ ethcommon.Address, _ *big.Int) (*big.Int, error) {
+	return b.ethBalance, nil
+}
+func (b *mockBackend) EthAddress() ethcommon.Address {
+	return ethcommon.Address{}
+}
+func (b *mockBackend) GasPrice(_ context.Context) (*big.Int, error) {
+	return b.gasPrice, nil
+}
+func (b *mockBackend) SyncProgress(_ context.Context) (*eth.SyncProgress, error) {
+	return b.syncProgress, nil
+}
+func (b *mockBackend) GetBalance(_ uint) (*monero.GetBalanceResponse, error) {
+	return &monero.GetBalanceResponse{UnlockedBalance: b.xmrBalance}, nil
+}
+func (b *mockBackend) GetHeight() (uint, error) {
+	return b.walletHeight, b.walletErr
+}
+func (b *mockBackend) GetInfo() (*monero.GetInfoResponse, error) {
+	return b.info, nil
+}
+func (b *mockBackend) HasDaemonClient() bool {
+	return !b.noDaemon
+}
+func (b *mockBackend) LockClient()   {}
+func (b *mockBackend) UnlockClient() {}
+
+func failedChecks(r *Report) []string {
+	var failed []string
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failed = append(failed, c.Name)
+		}
+	}
+	return failed
+}
+
+func TestRun_Passes(t *testing.T) {
+	r, err := Run(context.Background(), newMockBackend(), types.ProvidesETH, 0.5)
+	require.NoError(t, err)
+	require.True(t, r.Passed, "%v", failedChecks(r))
+	require.NoError(t, r.Err())
+	require.Len(t, r.Checks, 5)
+
+	r, err = Run(context.Background(), newMockBackend(), types.ProvidesXMR, 5)
+	require.NoError(t, err)
+	require.True(t, r.Passed, "%v", failedChecks(r))
+	require.Len(t, r.Checks, 5)
+}
+
+func TestRun_UnknownCoin(t *testing.T) {
+	_, err := Run(context.Background(), newMockBackend(), "BTC", 1)
+	require.Equal(t, errUnknownCoin, err)
+}
+
+func TestRun_NodesNotReady(t *testing.T) {
+	b := newMockBackend()
+	b.syncProgress = &eth.SyncProgress{CurrentBlock: 10, HighestBlock: 100}
+	b.info = &monero.GetInfoResponse{Height: 500, TargetHeight: 1000}
+
+	r, err := Run(context.Background(), b, types.ProvidesETH, 0.5)
+	require.NoError(t, err)
+	require.False(t, r.Passed)
+	require.Equal(t, []string{CheckEthereumSync, CheckMoneroDaemon}, failedChecks(r))
+	require.True(t, errors.Is(r.Err(), errPreflightFailed))
+
+	b = newMockBackend()
+	b.walletErr = errors.New("connection refused")
+	r, err = Run(context.Background(), b, types.ProvidesXMR, 5)
+	require.NoError(t, err)
+	require.Equal(t, []string{CheckMoneroWallet}, failedChecks(r))
+
+	b = newMockBackend()
+	b.walletHeight = 900
+	r, err = Run(context.Background(), b, types.ProvidesETH, 0.5)
+	require.NoError(t, err)
+	require.Equal(t, []string{CheckMoneroWallet}, failedChecks(r))
+}
+
+func TestRun_InsufficientBalances(t *testing.T) {
+	r, err := Run(context.Background(), newMockBackend(), types.ProvidesETH, 2)
+	require.NoError(t, err)
+	require.Equal(t, []string{CheckETHBalance}, failedChecks(r))
+
+	// enough to lock, but not to pay for gas afterwards
+	r, err = Run(context.Background(), newMockBackend(), types.ProvidesETH, 0.9999)
+	require.NoError(t, err)
+	require.Equal(t, []string{CheckGasBudget}, failedChecks(r))
+
+	r, err = Run(context.Background(), newMockBackend(), types.ProvidesXMR, 11)
+	require.NoError(t, err)
+	require.Equal(t, []string{CheckXMRBalance}, failedChecks(r))
+
+	b := newMockBackend()
+	b.ethBalance = big.NewInt(0)
+	r, err = Run(context.Background(), b, types.ProvidesXMR, 5)
+	require.NoError(t, err)
+	require.Equal(t, []string{CheckGasBudget}, failedChecks(r))
+}
+
+func TestRun_NoDaemonClient(t *testing.T) {
+	b := newMockBackend()
+	b.noDaemon = true
+	b.info = nil
+
+	r, err := Run(context.Background(), b, types.ProvidesXMR, 5)
+	require.NoError(t, err)
+	require.True(t, r.Passed, "%v", failedChecks(r))
+	require.Len(t, r.Checks, 4)
+	for _, c := range r.Checks {
+		require.NotEqual(t, CheckMoneroDaemon, c.Name)
+	}
+}
@@ -0,0 +1,41 @@
+package rebalance
+
+import (
+	"context"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/protocol/backend"
+)
+
+type backendBalances struct {
+	backend backend.Backend
+}
+
+// NewBackendBalances returns Balances that reads the backend's ethereum account balance and its
+// monero wallet's balance.
+func NewBackendBalances(b backend.Backend) Balances {
+	return &backendBalances{backend: b}
+}
+
+func (b *backendBalances) ETHBalance(ctx context.Context) (float64, error) {
+	balance, err := b.backend.BalanceAt(ctx, b.backend.EthAddress(), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return common.EtherAmount(*balance).AsEther(), nil
+}
+
+// XMRBalance returns the wallet's whole balance, including monero that's yet to unlock, since
+// monero swapped back to us is locked for a while after it arrives.
+func (b *backendBalances) XMRBalance() (float64, error) {
+	b.backend.LockClient()
+	defer b.backend.UnlockClient()
+
+	balance, err := b.backend.GetBalance(0)
+	if err != nil {
+		return 0, err
+	}
+
+	return common.MoneroAmount(balance.Balance).AsMonero(), nil
+}
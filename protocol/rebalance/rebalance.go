@@ -0,0 +1,185 @@
+// Package rebalance keeps a maker's inventory from drifting entirely into ether: once claims have
+// built up enough ether, it takes other makers' offers to swap some of it back to monero.
+package rebalance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	logging "github.com/ipfs/go-log"
+)
+
+// DefaultInterval is how often balances are checked, if not configured.
+const DefaultInterval = time.Minute * 10
+
+var (
+	log = logging.Logger("rebalance")
+
+	errInvalidRatios    = errors.New("XMR ratio bounds must satisfy 0 <= min <= max <= 1")
+	errInvalidThreshold = errors.New("rebalance threshold must be positive")
+)
+
+// Config configures when, and how much, ether is swapped back to monero.
+type Config struct {
+	// ThresholdETH is the ether balance that must be exceeded before any is swapped
+	ThresholdETH float64
+	// MinXMRRatio and MaxXMRRatio bound the fraction of our inventory's value that's held in
+	// monero. When it falls below MinXMRRatio, enough ether is swapped to bring it back to the
+	// middle of the bounds.
+	MinXMRRatio, MaxXMRRatio float64
+	// ReserveETH is ether that's never swapped, so there's always enough to pay for gas
+	ReserveETH float64
+	// MaxSpread is how far above the index rate we'll pay; eg. 0.02 is 2% above the index
+	MaxSpread float64
+	// Interval is how often balances are checked; defaults to DefaultInterval
+	Interval time.Duration
+}
+
+// Balances gives our current ether and monero balances.
+type Balances interface {
+	ETHBalance(ctx context.Context) (float64, error)
+	XMRBalance() (float64, error)
+}
+
+// RateFunc returns the index exchange rate that our monero is valued at.
+type RateFunc func(ctx context.Context) (types.ExchangeRate, error)
+
+// TakeFunc takes offers on the network to swap providesAmount of ether for monero, at no more
+// than maxRate, and returns the ID of the swap.
+type TakeFunc func(providesAmount float64, maxRate types.ExchangeRate) (types.Hash, error)
+
+// Rebalancer periodically swaps ether back to monero when our inventory drifts too far into ether.
+// Only one rebalancing swap is done at a time.
+type Rebalancer struct {
+	cfg      Config
+	balances Balances
+	rate     RateFunc
+	take     TakeFunc
+	sm       swap.Manager
+
+	mu sync.Mutex
+	// ID of the ongoing rebalancing swap, if there is one
+	pending *types.Hash
+}
+
+// NewRebalancer returns a new *Rebalancer, which looks up the status of its swaps in the given
+// swap.Manager.
+func NewRebalancer(cfg *Config, balances Balances, rate RateFunc, take TakeFunc,
+	sm swap.Manager) (*Rebalancer, error) {
+	if cfg.ThresholdETH <= 0 {
+		return nil, errInvalidThreshold
+	}
+
+	if cfg.MinXMRRatio < 0 || cfg.MinXMRRatio > cfg.MaxXMRRatio || cfg.MaxXMRRatio > 1 {
+		return nil, errInvalidRatios
+	}
+
+	r := &Rebalancer{
+		cfg:      *cfg,
+		balances: balances,
+		rate:     rate,
+		take:     take,
+		sm:       sm,
+	}
+	if r.cfg.Interval == 0 {
+		r.cfg.Interval = DefaultInterval
+	}
+
+	return r, nil
+}
+
+// Start checks our balances every interval, rebalancing if needed, until the context is cancelled.
+func (r *Rebalancer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if err := r.check(ctx); err != nil {
+				log.Warnf("failed to rebalance: %s", err)
+			}
+		}
+	}()
+}
+
+// check swaps ether back to monero if our balances call for it and no rebalancing swap is ongoing.
+func (r *Rebalancer) check(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pending != nil {
+		if r.sm.GetOngoingSwap(*r.pending) != nil {
+			return nil
+		}
+
+		r.pending = nil
+	}
+
+	ethBalance, err := r.balances.ETHBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	if ethBalance <= r.cfg.ThresholdETH {
+		return nil
+	}
+
+	xmrBalance, err := r.balances.XMRBalance()
+	if err != nil {
+		return err
+	}
+
+	index, err := r.rate(ctx)
+	if err != nil {
+		return err
+	}
+
+	amount := amountToSwap(&r.cfg, ethBalance, xmrBalance, index)
+	if amount <= 0 {
+		return nil
+	}
+
+	maxRate := types.ExchangeRate(float64(index) * (1 + r.cfg.MaxSpread))
+	log.Infof("rebalancing: swapping %v of our %v ETH for XMR at no more than %v", amount, ethBalance, maxRate)
+
+	id, err := r.take(amount, maxRate)
+	if err != nil {
+		return err
+	}
+
+	r.pending = &id
+	return nil
+}
+
+// amountToSwap returns how much ether to swap for monero to bring the fraction of our inventory
+// held in monero back to the middle of the configured bounds, or 0 if it's within them.
+func amountToSwap(cfg *Config, ethBalance, xmrBalance float64, index types.ExchangeRate) float64 {
+	xmrValue := float64(index) * xmrBalance
+	total := xmrValue + ethBalance
+	if total <= 0 || xmrValue/total >= cfg.MinXMRRatio {
+		return 0
+	}
+
+	target := (cfg.MinXMRRatio + cfg.MaxXMRRatio) / 2
+	amount := target*total - xmrValue
+	if spendable := ethBalance - cfg.ReserveETH; amount > spendable {
+		amount = spendable
+	}
+
+	if amount < 0 {
+		return 0
+	}
+
+	return amount
+}
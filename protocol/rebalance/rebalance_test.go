@@ -0,0 +1,94 @@
+package rebalance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockBalances struct {
+	eth, xmr float64
+}
+
+func (b *mockBalances) ETHBalance(context.Context) (float64, error) {
+	return b.eth, nil
+}
+
+func (b *mockBalances) XMRBalance() (float64, error) {
+	return b.xmr, nil
+}
+
+var testConfig = &Config{
+	ThresholdETH: 1,
+	MinXMRRatio:  0.4,
+	MaxXMRRatio:  0.6,
+	ReserveETH:   0.1,
+	MaxSpread:    0.02,
+}
+
+func TestAmountToSwap(t *testing.T) {
+	// 10 XMR at 0.1 is worth 1 ETH; with 9 ETH, 10% of our inventory is in XMR, so 4 ETH is swapped
+	// to bring it to 50%
+	require.InDelta(t, 4, amountToSwap(testConfig, 9, 10, 0.1), 1e-9)
+
+	// within the bounds
+	require.Zero(t, amountToSwap(testConfig, 5, 50, 0.1))
+
+	// no XMR at all, but the reserve is kept for gas
+	require.InDelta(t, 0.9, amountToSwap(&Config{MinXMRRatio: 1, MaxXMRRatio: 1, ReserveETH: 0.1}, 1, 0, 0.1),
+		1e-9)
+	require.Zero(t, amountToSwap(testConfig, 0.05, 0, 0.1))
+}
+
+func TestNewRebalancer_InvalidConfig(t *testing.T) {
+	_, err := NewRebalancer(&Config{MinXMRRatio: 0.4, MaxXMRRatio: 0.6}, nil, nil, nil, nil)
+	require.ErrorIs(t, err, errInvalidThreshold)
+
+	_, err = NewRebalancer(&Config{ThresholdETH: 1, MinXMRRatio: 0.6, MaxXMRRatio: 0.4}, nil, nil, nil, nil)
+	require.ErrorIs(t, err, errInvalidRatios)
+}
+
+func TestRebalancer_Check(t *testing.T) {
+	balances := &mockBalances{eth: 0.5, xmr: 10}
+	sm := swap.NewManager()
+	rate := func(context.Context) (types.ExchangeRate, error) {
+		return 0.1, nil
+	}
+
+	var (
+		takes   []float64
+		maxRate types.ExchangeRate
+	)
+	take := func(amount float64, rate types.ExchangeRate) (types.Hash, error) {
+		takes = append(takes, amount)
+		maxRate = rate
+		return types.Hash{byte(len(takes))}, nil
+	}
+
+	r, err := NewRebalancer(testConfig, balances, rate, take, sm)
+	require.NoError(t, err)
+
+	// below the threshold, nothing is swapped
+	require.NoError(t, r.check(context.Background()))
+	require.Empty(t, takes)
+
+	balances.eth = 9
+	require.NoError(t, r.check(context.Background()))
+	require.Len(t, takes, 1)
+	require.InDelta(t, 4, takes[0], 1e-9)
+	require.InDelta(t, 0.102, float64(maxRate), 1e-9)
+
+	// nothing more is swapped while the rebalancing swap is ongoing
+	info := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 4, 40, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(info))
+	require.NoError(t, r.check(context.Background()))
+	require.Len(t, takes, 1)
+
+	sm.CompleteOngoingSwap(types.Hash{1})
+	require.NoError(t, r.check(context.Background()))
+	require.Len(t, takes, 2)
+}
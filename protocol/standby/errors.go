@@ -0,0 +1,11 @@
+package standby
+
+import (
+	"errors"
+)
+
+var (
+	errLeaseHeld       = errors.New("lease is held by another daemon")
+	errLeaseLost       = errors.New("lease is no longer held by this daemon")
+	errInvalidLeaseTTL = errors.New("lease TTL must be greater than zero")
+)
@@ -0,0 +1,254 @@
+package standby
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/protocol/backup"
+)
+
+// leaseState is the contents of the lease file.
+type leaseState struct {
+	Holder  string
+	Epoch   uint64
+	Expires time.Time
+}
+
+// Lease is a lease on a file that's shared between an active daemon and its standby, eg. on
+// replicated or network storage. Only the daemon holding the lease may sign transactions.
+//
+// Every acquisition increments the lease's epoch, which acts as a fencing token. A daemon only gets
+// an epoch by creating the epoch's claim file next to the lease file, which is created exclusively,
+// so no two daemons can acquire the same epoch, even if they try at the same time. Once a standby
+// has claimed the next epoch, the old holder's Check fails, even if it comes back up after being
+// partitioned. A holder stops signing as soon as its own lease expires, and a standby only takes
+// over after an additional grace period of one TTL, so the two never sign at the same time as long
+// as their clocks are within one TTL of each other. The shared storage must support exclusive
+// file creation (O_EXCL), as local filesystems and NFSv3 and later do.
+type Lease struct {
+	path   string
+	holder string
+	ttl    time.Duration
+
+	sync.RWMutex
+	epoch   uint64
+	expires time.Time
+
+	// for testing
+	now func() time.Time
+}
+
+// NewLease returns a new *Lease backed by the file at the given path. The holder should
+// uniquely identify this daemon.
+func NewLease(path, holder string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, errInvalidLeaseTTL
+	}
+
+	return &Lease{
+		path:   path,
+		holder: holder,
+		ttl:    ttl,
+		now:    time.Now,
+	}, nil
+}
+
+// Epoch returns the epoch of the lease held by this daemon, or 0 if it was never acquired.
+func (l *Lease) Epoch() uint64 {
+	l.RLock()
+	defer l.RUnlock()
+	return l.epoch
+}
+
+// Acquire acquires the lease, incrementing its epoch. It fails with errLeaseHeld if another
+// daemon's lease hasn't expired yet, including the grace period.
+func (l *Lease) Acquire() error {
+	l.Lock()
+	defer l.Unlock()
+
+	state, err := l.read()
+	if err != nil {
+		return err
+	}
+
+	now := l.now()
+	if state.Holder != "" && state.Holder != l.holder && now.Before(state.Expires.Add(l.ttl)) {
+		return errLeaseHeld
+	}
+
+	next := &leaseState{
+		Holder:  l.holder,
+		Epoch:   state.Epoch + 1,
+		Expires: now.Add(l.ttl),
+	}
+
+	// if another daemon read the lease at the same time, only one of us can claim the epoch
+	if err = l.claim(next.Epoch); err != nil {
+		return err
+	}
+
+	l.epoch = next.Epoch
+	l.expires = next.Expires
+	return l.write(next)
+}
+
+// Renew extends the lease by one TTL. It fails with errLeaseLost if the lease was taken over. If
+// it's taken over after the check, this write is stale, but the next epoch's claim still fences
+// this daemon off.
+func (l *Lease) Renew() error {
+	l.Lock()
+	defer l.Unlock()
+
+	if err := l.check(); err != nil {
+		return err
+	}
+
+	next := &leaseState{
+		Holder:  l.holder,
+		Epoch:   l.epoch,
+		Expires: l.now().Add(l.ttl),
+	}
+
+	if err := l.write(next); err != nil {
+		return err
+	}
+
+	l.expires = next.Expires
+	return nil
+}
+
+// Check returns nil if this daemon currently holds the lease, and errLeaseLost otherwise.
+// It's checked before every transaction is signed.
+func (l *Lease) Check() error {
+	l.RLock()
+	defer l.RUnlock()
+	return l.check()
+}
+
+func (l *Lease) check() error {
+	if l.epoch == 0 || !l.now().Before(l.expires) {
+		return errLeaseLost
+	}
+
+	// a daemon taking over the lease claims the next epoch before writing the lease file
+	_, err := os.Stat(l.claimPath(l.epoch + 1))
+	if err == nil {
+		return errLeaseLost
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// KeepAlive renews the lease every third of its TTL until the context is cancelled.
+// If the lease is lost, onLost is called and KeepAlive returns.
+func (l *Lease) KeepAlive(ctx context.Context, onLost func()) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := l.Renew()
+		if err == nil {
+			continue
+		}
+
+		log.Warnf("failed to renew lease: %s", err)
+		if l.Check() != nil {
+			onLost()
+			return
+		}
+	}
+}
+
+// WaitAndAcquire polls the lease until it can be acquired, ie. until the active daemon
+// has stopped renewing it, or until the context is cancelled.
+func (l *Lease) WaitAndAcquire(ctx context.Context) error {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		err := l.Acquire()
+		if err == nil {
+			return nil
+		}
+
+		if err != errLeaseHeld {
+			log.Warnf("failed to acquire lease: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimPath returns the path of the given epoch's claim file.
+func (l *Lease) claimPath(epoch uint64) string {
+	return fmt.Sprintf("%s.epoch-%d", l.path, epoch)
+}
+
+// claim claims the given epoch by creating its claim file, which fails with errLeaseHeld if
+// another daemon has already claimed it. Claim files are never removed, so an epoch can't be
+// claimed twice.
+func (l *Lease) claim(epoch uint64) error {
+	f, err := os.OpenFile(filepath.Clean(l.claimPath(epoch)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return errLeaseHeld
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.WriteString(l.holder); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (l *Lease) read() (*leaseState, error) {
+	bz, err := os.ReadFile(filepath.Clean(l.path))
+	if os.IsNotExist(err) {
+		return &leaseState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &leaseState{}
+	if err = json.Unmarshal(bz, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (l *Lease) write(state *leaseState) error {
+	bz, err := json.MarshalIndent(state, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return backup.NewDirTarget(filepath.Dir(l.path)).Write(filepath.Base(l.path), bz)
+}
@@ -0,0 +1,157 @@
+package standby
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLease(t *testing.T, path, holder string, now *time.Time) *Lease {
+	l, err := NewLease(path, holder, time.Minute)
+	require.NoError(t, err)
+	l.now = func() time.Time {
+		return *now
+	}
+	return l
+}
+
+func TestLease_AcquireAndTakeOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.json")
+	now := time.Now()
+
+	active := newTestLease(t, path, "active", &now)
+	standby := newTestLease(t, path, "standby", &now)
+
+	require.NoError(t, active.Acquire())
+	require.Equal(t, uint64(1), active.Epoch())
+	require.NoError(t, active.Check())
+	require.Equal(t, errLeaseHeld, standby.Acquire())
+
+	// the active daemon stops signing as soon as its lease expires...
+	now = now.Add(time.Minute + time.Second)
+	require.Equal(t, errLeaseLost, active.Check())
+	require.Equal(t, errLeaseLost, active.Renew())
+
+	// ...but the standby only takes over after the grace period
+	require.Equal(t, errLeaseHeld, standby.Acquire())
+	now = now.Add(time.Minute)
+	require.NoError(t, standby.Acquire())
+	require.Equal(t, uint64(2), standby.Epoch())
+	require.NoError(t, standby.Check())
+
+	// the old active daemon is fenced off, even if its clock is behind
+	now = now.Add(-time.Minute * 2)
+	active.expires = now.Add(time.Minute)
+	require.Equal(t, errLeaseLost, active.Check())
+}
+
+func TestLease_ConcurrentAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.json")
+	now := time.Now()
+
+	a := newTestLease(t, path, "a", &now)
+	b := newTestLease(t, path, "b", &now)
+
+	// both daemons read the empty lease, but only one can claim epoch 1
+	require.NoError(t, a.claim(1))
+	require.Equal(t, errLeaseHeld, b.claim(1))
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		l := newTestLease(t, path, fmt.Sprintf("daemon-%d", i), &now)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = l.Acquire()
+		}(i)
+	}
+	wg.Wait()
+
+	var acquired int
+	for _, err := range errs {
+		if err == nil {
+			acquired++
+			continue
+		}
+		require.Equal(t, errLeaseHeld, err)
+	}
+	require.LessOrEqual(t, acquired, 1)
+}
+
+func TestLease_RenewAfterTakeOver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.json")
+	now := time.Now()
+
+	active := newTestLease(t, path, "active", &now)
+	require.NoError(t, active.Acquire())
+
+	// a standby claims the next epoch before the active daemon's renewal is written; the stale
+	// renewal doesn't stop the active daemon from being fenced off
+	standby := newTestLease(t, path, "standby", &now)
+	require.NoError(t, standby.claim(2))
+	require.Equal(t, errLeaseLost, active.Renew())
+	require.Equal(t, errLeaseLost, active.Check())
+}
+
+func TestLease_Renew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.json")
+	now := time.Now()
+
+	active := newTestLease(t, path, "active", &now)
+	standby := newTestLease(t, path, "standby", &now)
+
+	require.NoError(t, active.Acquire())
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second * 30)
+		require.NoError(t, active.Renew())
+		require.NoError(t, active.Check())
+		require.Equal(t, errLeaseHeld, standby.Acquire())
+	}
+
+	require.Equal(t, uint64(1), active.Epoch())
+}
+
+func TestLease_WaitAndAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease.json")
+
+	active, err := NewLease(path, "active", time.Millisecond*300)
+	require.NoError(t, err)
+	standby, err := NewLease(path, "standby", time.Millisecond*300)
+	require.NoError(t, err)
+
+	require.NoError(t, active.Acquire())
+
+	// the standby shouldn't take over while the lease is being renewed
+	ctx, cancel := context.WithCancel(context.Background())
+	lost := make(chan struct{})
+	go active.KeepAlive(ctx, func() {
+		close(lost)
+	})
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), time.Second)
+	defer waitCancel()
+	require.ErrorIs(t, standby.WaitAndAcquire(waitCtx), context.DeadlineExceeded)
+
+	// once the active daemon stops, the standby takes over
+	cancel()
+	require.NoError(t, standby.WaitAndAcquire(context.Background()))
+	require.Equal(t, uint64(2), standby.Epoch())
+	require.Error(t, active.Check())
+
+	select {
+	case <-lost:
+		t.Fatal("lease shouldn't be reported lost after KeepAlive was cancelled")
+	default:
+	}
+}
+
+func TestNewLease_InvalidTTL(t *testing.T) {
+	_, err := NewLease("lease.json", "active", 0)
+	require.Equal(t, errInvalidLeaseTTL, err)
+}
@@ -0,0 +1,123 @@
+// Package standby implements active/standby failover between two swapd instances run by the
+// same market maker. The active daemon holds a lease on a shared file and writes its encrypted
+// swap info files to a shared backup directory. The standby waits for the lease to expire, then
+// takes it over and finishes the active daemon's ongoing swaps from the backed up info files.
+package standby
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/backup"
+	recovery "github.com/noot/atomic-swap/recover"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("standby")
+
+// Config is the config for a Standby.
+type Config struct {
+	Backend  backend.Backend
+	Basepath string
+	Lease    *Lease
+
+	// BackupDir is the directory the active daemon writes its encrypted info files to
+	BackupDir      string
+	BackupPassword []byte
+}
+
+// Standby is a daemon waiting to take over from the active daemon.
+type Standby struct {
+	backend        backend.Backend
+	basepath       string
+	lease          *Lease
+	backupDir      string
+	backupPassword []byte
+}
+
+// NewStandby returns a new *Standby.
+func NewStandby(cfg *Config) *Standby {
+	return &Standby{
+		backend:        cfg.Backend,
+		basepath:       cfg.Basepath,
+		lease:          cfg.Lease,
+		backupDir:      cfg.BackupDir,
+		backupPassword: cfg.BackupPassword,
+	}
+}
+
+// Run blocks until the active daemon's lease expires, then acquires the lease and finishes
+// all the swaps in the backup directory. Once it returns, this daemon is the active daemon.
+func (s *Standby) Run(ctx context.Context) error {
+	log.Infof("running as standby, waiting for lease %s to expire", s.lease.path)
+	if err := s.lease.WaitAndAcquire(ctx); err != nil {
+		return err
+	}
+
+	log.Infof("acquired lease, promoted to active daemon: epoch=%d", s.lease.Epoch())
+	s.RecoverSwaps()
+	return nil
+}
+
+// RecoverSwaps claims, refunds or sweeps the funds of every swap in the backup directory.
+// Errors are logged, so that one failed swap doesn't prevent the others from being recovered.
+func (s *Standby) RecoverSwaps() {
+	infofiles, err := loadInfoFiles(s.backupDir, s.backupPassword)
+	if err != nil {
+		log.Errorf("failed to load info files from %s: %s", s.backupDir, err)
+		return
+	}
+
+	for name, infofile := range infofiles {
+		if err := s.recoverSwap(infofile); err != nil {
+			log.Warnf("failed to recover swap from %s: %s", name, err)
+		}
+	}
+}
+
+func (s *Standby) recoverSwap(infofile *pcommon.InfoFileContents) error {
+	_, err := recovery.RecoverSwap(s.backend, s.basepath, infofile)
+	return err
+}
+
+// loadInfoFiles decrypts and returns all the info files in the given backup directory,
+// keyed by file name.
+func loadInfoFiles(dir string, password []byte) (map[string]*pcommon.InfoFileContents, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	infofiles := make(map[string]*pcommon.InfoFileContents)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".enc") {
+			continue
+		}
+
+		ciphertext, err := os.ReadFile(filepath.Clean(filepath.Join(dir, name)))
+		if err != nil {
+			return nil, err
+		}
+
+		bz, err := backup.Decrypt(password, ciphertext)
+		if err != nil {
+			return nil, err
+		}
+
+		var infofile *pcommon.InfoFileContents
+		if err = json.Unmarshal(bz, &infofile); err != nil {
+			return nil, err
+		}
+
+		infofiles[name] = infofile
+	}
+
+	return infofiles, nil
+}
@@ -0,0 +1,40 @@
+package standby
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backup"
+)
+
+func TestLoadInfoFiles(t *testing.T) {
+	dir := t.TempDir()
+	password := []byte("password")
+
+	b, err := backup.NewBackup(backup.NewDirTarget(dir), password)
+	require.NoError(t, err)
+
+	infofile := &pcommon.InfoFileContents{
+		ContractAddress: "0xabcd",
+		ContractSwapID:  [32]byte{1},
+	}
+	bz, err := json.Marshal(infofile)
+	require.NoError(t, err)
+	require.NoError(t, b.Write("info-1.txt", bz))
+
+	// files that aren't encrypted backups are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lease.json"), []byte("{}"), 0600))
+
+	infofiles, err := loadInfoFiles(dir, password)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(infofiles))
+	require.Equal(t, infofile, infofiles["info-1.txt.enc"])
+
+	_, err = loadInfoFiles(dir, []byte("wrong"))
+	require.Error(t, err)
+}
@@ -0,0 +1,119 @@
+// Package stats aggregates the swap manager's history into statistics, so market makers can
+// monitor how their swaps are going.
+package stats
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+)
+
+// Stats are statistics about past swaps. Amounts are in ETH and XMR, and durations in seconds.
+type Stats struct {
+	// Swaps is the number of past swaps, and Ongoing the number of swaps currently in progress.
+	Swaps   int `json:"swaps"`
+	Ongoing int `json:"ongoing"`
+
+	// Succeeded, Refunded and Aborted are the numbers of past swaps that completed each way, and
+	// Interrupted the number that were interrupted by the daemon stopping.
+	Succeeded   int `json:"succeeded"`
+	Refunded    int `json:"refunded"`
+	Aborted     int `json:"aborted"`
+	Interrupted int `json:"interrupted"`
+
+	// SuccessRate, RefundRate and AbortRate are fractions of the completed swaps.
+	SuccessRate float64 `json:"successRate"`
+	RefundRate  float64 `json:"refundRate"`
+	AbortRate   float64 `json:"abortRate"`
+
+	// VolumeETH and VolumeXMR are the amounts of each coin exchanged in successful swaps.
+	VolumeETH float64 `json:"volumeETH"`
+	VolumeXMR float64 `json:"volumeXMR"`
+
+	// AverageDuration is the average time completed swaps took, and AverageStageDurations the
+	// average time swaps spent at each status before moving on to the next.
+	AverageDuration       float64            `json:"averageDuration"`
+	AverageStageDurations map[string]float64 `json:"averageStageDurations"`
+
+	// GasUsed and Fees are the totals for the ethereum transactions sent for the swaps.
+	GasUsed uint64  `json:"gasUsed"`
+	Fees    float64 `json:"fees"`
+}
+
+// Compute returns statistics about the past swaps selected by the filter, which may be nil to
+// select every past swap.
+func Compute(m swap.Manager, filter *swap.HistoryFilter) (*Stats, error) {
+	swaps, err := m.GetPastSwaps(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := computeStats(swaps)
+	stats.Ongoing = len(m.GetOngoingSwaps())
+	return stats, nil
+}
+
+func computeStats(swaps []*swap.Info) *Stats {
+	stats := &Stats{
+		Swaps:                 len(swaps),
+		AverageStageDurations: make(map[string]float64),
+	}
+
+	var (
+		totalDuration  time.Duration
+		stageDurations = make(map[types.Status]time.Duration)
+		stageCounts    = make(map[types.Status]int)
+		fees           = new(big.Int)
+	)
+
+	for _, info := range swaps {
+		switch info.Status() {
+		case types.CompletedSuccess:
+			stats.Succeeded++
+			if info.Provides() == types.ProvidesETH {
+				stats.VolumeETH += info.ProvidedAmount()
+				stats.VolumeXMR += info.ReceivedAmount()
+			} else {
+				stats.VolumeXMR += info.ProvidedAmount()
+				stats.VolumeETH += info.ReceivedAmount()
+			}
+		case types.CompletedRefund:
+			stats.Refunded++
+		case types.CompletedAbort:
+			stats.Aborted++
+		default:
+			stats.Interrupted++
+			continue
+		}
+
+		totalDuration += info.EndTime().Sub(info.StartTime())
+
+		times := info.StatusTimes()
+		for i := 0; i+1 < len(times); i++ {
+			stageDurations[times[i].Status] += times[i+1].Time.Sub(times[i].Time)
+			stageCounts[times[i].Status]++
+		}
+
+		gasUsed, fee := info.TxCosts()
+		stats.GasUsed += gasUsed
+		fees.Add(fees, fee)
+	}
+
+	completed := stats.Succeeded + stats.Refunded + stats.Aborted
+	if completed != 0 {
+		stats.SuccessRate = float64(stats.Succeeded) / float64(completed)
+		stats.RefundRate = float64(stats.Refunded) / float64(completed)
+		stats.AbortRate = float64(stats.Aborted) / float64(completed)
+		stats.AverageDuration = totalDuration.Seconds() / float64(completed)
+	}
+
+	for status, d := range stageDurations {
+		stats.AverageStageDurations[status.String()] = d.Seconds() / float64(stageCounts[status])
+	}
+
+	stats.Fees = common.EtherAmount(*fees).AsEther()
+	return stats
+}
@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	sm := swap.NewManager()
+
+	// a swap is only moved to the past swaps once it completes
+	complete := func(info *swap.Info, statuses ...types.Status) {
+		require.NoError(t, sm.AddSwap(info))
+		for _, s := range statuses {
+			time.Sleep(time.Millisecond)
+			info.SetStatus(s)
+		}
+		sm.CompleteOngoingSwap(info.ID())
+	}
+
+	ethSwap := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 0.5, 10, 0.05, types.ExpectingKeys, nil)
+	ethSwap.AddTxCost(21000, common.EtherToWei(0.001).BigInt())
+	complete(ethSwap, types.ETHLocked, types.ContractReady, types.CompletedSuccess)
+
+	xmrSwap := swap.NewInfo(types.Hash{2}, types.ProvidesXMR, 2, 0.1, 0.05, types.ExpectingKeys, nil)
+	xmrSwap.AddTxCost(50000, common.EtherToWei(0.002).BigInt())
+	complete(xmrSwap, types.XMRLocked, types.CompletedSuccess)
+
+	complete(swap.NewInfo(types.Hash{3}, types.ProvidesETH, 1, 20, 0.05, types.ExpectingKeys, nil),
+		types.ETHLocked, types.CompletedRefund)
+	complete(swap.NewInfo(types.Hash{4}, types.ProvidesXMR, 1, 0.05, 0.05, types.ExpectingKeys, nil),
+		types.CompletedAbort)
+
+	require.NoError(t, sm.AddSwap(swap.NewInfo(types.Hash{5}, types.ProvidesXMR, 1, 0.05, 0.05,
+		types.KeysExchanged, nil)))
+
+	stats, err := Compute(sm, nil)
+	require.NoError(t, err)
+	require.Equal(t, 4, stats.Swaps)
+	require.Equal(t, 1, stats.Ongoing)
+	require.Equal(t, 2, stats.Succeeded)
+	require.Equal(t, 1, stats.Refunded)
+	require.Equal(t, 1, stats.Aborted)
+	require.Equal(t, 0, stats.Interrupted)
+	require.Equal(t, 0.5, stats.SuccessRate)
+	require.Equal(t, 0.25, stats.RefundRate)
+	require.Equal(t, 0.25, stats.AbortRate)
+	require.InDelta(t, 0.6, stats.VolumeETH, 1e-12)
+	require.Equal(t, float64(12), stats.VolumeXMR)
+	require.Greater(t, stats.AverageDuration, float64(0))
+	require.Contains(t, stats.AverageStageDurations, types.ExpectingKeys.String())
+	require.Contains(t, stats.AverageStageDurations, types.ETHLocked.String())
+	require.NotContains(t, stats.AverageStageDurations, types.CompletedSuccess.String())
+	require.Equal(t, uint64(71000), stats.GasUsed)
+	require.InDelta(t, 0.003, stats.Fees, 1e-12)
+
+	// only the selected swaps are counted
+	stats, err = Compute(sm, &swap.HistoryFilter{Statuses: []types.Status{types.CompletedSuccess}})
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.Swaps)
+	require.Equal(t, float64(1), stats.SuccessRate)
+}
+
+func TestComputeStats_Empty(t *testing.T) {
+	stats := computeStats(nil)
+	require.Equal(t, 0, stats.Swaps)
+	require.Equal(t, float64(0), stats.SuccessRate)
+	require.Equal(t, float64(0), stats.AverageDuration)
+	require.Equal(t, uint64(0), stats.GasUsed)
+	require.Equal(t, float64(0), stats.Fees)
+	require.Empty(t, stats.AverageStageDurations)
+}
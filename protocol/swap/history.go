@@ -0,0 +1,301 @@
+package swap
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+)
+
+// ErrInvalidCursor is returned when a HistoryFilter's cursor wasn't returned by HistoryCursor.
+var ErrInvalidCursor = errors.New("invalid history cursor")
+
+// HistoryFilter selects past swaps. Its zero value selects every past swap.
+type HistoryFilter struct {
+	// From and To restrict the swaps to those started in [From, To); either may be zero
+	From time.Time
+	To   time.Time
+	// Statuses restricts the swaps to those with one of the statuses, if it's not empty
+	Statuses []Status
+	// PeerID restricts the swaps to those with the given counterparty, if it's set
+	PeerID string
+	// Provides restricts the swaps to those in which we provided the given coin, if it's set
+	Provides types.ProvidesCoin
+	// After restricts the swaps to those that come after the swap it's the HistoryCursor of, if
+	// it's set; with Limit, it pages through the history
+	After string
+	// Limit is the most swaps to select, if it's positive
+	Limit int
+}
+
+func (f *HistoryFilter) matches(info *Info) bool {
+	start := info.StartTime()
+	if (!f.From.IsZero() && start.Before(f.From)) || (!f.To.IsZero() && !start.Before(f.To)) {
+		return false
+	}
+
+	if f.PeerID != "" && info.PeerID() != f.PeerID {
+		return false
+	}
+
+	if f.Provides != "" && info.Provides() != f.Provides {
+		return false
+	}
+
+	if len(f.Statuses) == 0 {
+		return true
+	}
+
+	status := info.Status()
+	for _, s := range f.Statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HistoryCursor returns the cursor to set as a HistoryFilter's After, to select the swaps that
+// come after the given one.
+func HistoryCursor(info *Info) string {
+	return hex.EncodeToString([]byte(indexKey(info.StartTime(), info.id)))
+}
+
+// parseCursor returns the index key of the swap the cursor was made from, or "" if it's empty.
+func parseCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+
+	key, err := hex.DecodeString(cursor)
+	if err != nil || len(key) != len(timeKey(time.Time{}))+len(types.Hash{}) {
+		return "", ErrInvalidCursor
+	}
+
+	return string(key), nil
+}
+
+// GetPastSwaps returns the past swaps selected by the filter, in the order they were started. If
+// the swaps are persisted, they're selected using the database's indexes.
+func (m *manager) GetPastSwaps(filter *HistoryFilter) ([]*Info, error) {
+	if filter == nil {
+		filter = &HistoryFilter{}
+	}
+
+	after, err := parseCursor(filter.After)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.db == nil {
+		return m.filterPastSwaps(filter, after), nil
+	}
+
+	ids, err := m.queryIndexes(filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	m.RLock()
+	defer m.RUnlock()
+	swaps := make([]*Info, 0, len(ids))
+	for _, id := range ids {
+		if filter.Limit > 0 && len(swaps) == filter.Limit {
+			break
+		}
+
+		// the indexes also contain ongoing swaps
+		info, has := m.past[id]
+		if !has || !filter.matches(info) {
+			continue
+		}
+
+		swaps = append(swaps, info)
+	}
+
+	return swaps, nil
+}
+
+// filterPastSwaps returns the past swaps held in memory that are selected by the filter and come
+// after the given index key.
+func (m *manager) filterPastSwaps(filter *HistoryFilter, after string) []*Info {
+	m.RLock()
+	defer m.RUnlock()
+
+	var swaps []*Info
+	for _, info := range m.past {
+		if filter.matches(info) && indexKey(info.StartTime(), info.id) > after {
+			swaps = append(swaps, info)
+		}
+	}
+
+	sort.Slice(swaps, func(i, j int) bool {
+		return indexKey(swaps[i].StartTime(), swaps[i].id) < indexKey(swaps[j].StartTime(), swaps[j].id)
+	})
+
+	if filter.Limit > 0 && len(swaps) > filter.Limit {
+		swaps = swaps[:filter.Limit]
+	}
+	return swaps
+}
+
+// queryIndexes returns the IDs of the swaps in the most selective index for the filter that come
+// after the given index key, in the order they were started.
+func (m *manager) queryIndexes(filter *HistoryFilter, after string) ([]types.Hash, error) {
+	var start, limit []byte
+	if !filter.From.IsZero() {
+		start = timeKey(filter.From)
+	}
+	if !filter.To.IsZero() {
+		limit = timeKey(filter.To)
+	}
+
+	// the range's start is inclusive, so it starts just after the cursor's key
+	if after != "" && after >= string(start) {
+		start = append([]byte(after), 0)
+	}
+
+	var buckets [][]byte
+	switch {
+	case filter.PeerID != "":
+		buckets = [][]byte{peerBucket(filter.PeerID)}
+	case len(filter.Statuses) != 0:
+		for _, s := range filter.Statuses {
+			buckets = append(buckets, statusBucket(s))
+		}
+	default:
+		buckets = [][]byte{db.SwapsByTimeBucket}
+	}
+
+	var keys []string
+	for _, bucket := range buckets {
+		err := m.db.IterateRange(bucket, start, limit, func(key, _ []byte) error {
+			keys = append(keys, string(key))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// keys from several status indexes need merging
+	if len(buckets) > 1 {
+		sort.Strings(keys)
+	}
+
+	ids := make([]types.Hash, 0, len(keys))
+	for _, key := range keys {
+		var id types.Hash
+		copy(id[:], key[len(key)-len(id):])
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// getRecord returns the swap's record in the database, or nil if it isn't there.
+func (m *manager) getRecord(id types.Hash) (*swapRecord, error) {
+	bz, err := m.db.Get(db.SwapsBucket, id[:])
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r *swapRecord
+	if err = json.Unmarshal(bz, &r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// timeKey returns the index key prefix of swaps started at the given time, which sorts in time order.
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 8)
+	// swaps stored before start times were recorded have a zero start time, which sorts first
+	if !t.IsZero() && t.UnixNano() > 0 {
+		binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	}
+	return key
+}
+
+// indexKey returns the key of a swap within an index.
+func indexKey(start time.Time, id types.Hash) string {
+	return string(append(timeKey(start), id[:]...))
+}
+
+func statusBucket(s Status) []byte {
+	return append(append([]byte{}, db.SwapsByStatusBucket...), '/', byte(s))
+}
+
+func peerBucket(peerID string) []byte {
+	return append(append(append([]byte{}, db.SwapsByPeerBucket...), '/'), peerID...)
+}
+
+// updateIndexes updates the indexes of a swap whose record changed from prev, which is nil if the
+// swap is new. A swap's start time never changes, so neither does its key within the indexes.
+func updateIndexes(d db.Database, prev, r *swapRecord) error {
+	key := []byte(indexKey(r.StartTime, r.ID))
+	if prev == nil {
+		if err := d.Put(db.SwapsByTimeBucket, key, nil); err != nil {
+			return err
+		}
+	}
+
+	if prev == nil || prev.Status != r.Status {
+		if prev != nil {
+			if err := d.Delete(statusBucket(prev.Status), key); err != nil {
+				return err
+			}
+		}
+
+		if err := d.Put(statusBucket(r.Status), key, nil); err != nil {
+			return err
+		}
+	}
+
+	if r.PeerID == "" || (prev != nil && prev.PeerID == r.PeerID) {
+		return nil
+	}
+
+	if prev != nil && prev.PeerID != "" {
+		if err := d.Delete(peerBucket(prev.PeerID), key); err != nil {
+			return err
+		}
+	}
+
+	return d.Put(peerBucket(r.PeerID), key, nil)
+}
+
+// rebuildIndexes replaces the indexes with ones built from the given records.
+func rebuildIndexes(d db.Database, records []*swapRecord) error {
+	byTime := make(map[string][]byte, len(records))
+	byStatus := make(map[string][]byte, len(records))
+	byPeer := make(map[string][]byte)
+	for _, r := range records {
+		key := indexKey(r.StartTime, r.ID)
+		byTime[key] = nil
+		byStatus[string(append([]byte{byte(r.Status), '/'}, key...))] = nil
+		if r.PeerID != "" {
+			byPeer[r.PeerID+"/"+key] = nil
+		}
+	}
+
+	if err := d.ReplaceBucket(db.SwapsByTimeBucket, byTime); err != nil {
+		return err
+	}
+
+	if err := d.ReplaceBucket(db.SwapsByStatusBucket, byStatus); err != nil {
+		return err
+	}
+
+	return d.ReplaceBucket(db.SwapsByPeerBucket, byPeer)
+}
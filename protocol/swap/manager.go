@@ -1,11 +1,22 @@
 package swap
 
 import (
+	"encoding/json"
+	"math/big"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/protocol/events"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	logging "github.com/ipfs/go-log"
 )
 
+var log = logging.Logger("swap")
+
 type (
 	Status = types.Status //nolint:revive
 )
@@ -19,8 +30,46 @@ type Info struct {
 	exchangeRate   types.ExchangeRate
 	status         Status
 	statusCh       <-chan types.Status
+
+	mu sync.RWMutex
+	// progress of the monero chain towards the height we're waiting for, if any
+	moneroHeight       uint
+	moneroTargetHeight uint
+	// hashes of the ethereum transactions we've sent for this swap, in order
+	txHashes []ethcommon.Hash
+	// channels of the status subscribers, see Subscribe
+	subscribers []chan Status
+	// version info the counterparty sent when the swap began; nil if it didn't send any
+	peerVersion *types.VersionInfo
+	// libp2p peer ID of the counterparty, if known
+	peerID string
+	// when the swap was started, and when it completed; endTime is zero while it's ongoing
+	startTime time.Time
+	endTime   time.Time
+	// when the swap reached each of its statuses, in order
+	statusTimes []StatusTime
+	// gas used by the ethereum transactions sent for this swap, and the fees paid for them in wei
+	gasUsed uint64
+	fees    *big.Int
+
+	// legacy numeric ID of the swap, set by the Manager
+	index uint64
+	// called after the swap's persisted fields change, if set
+	onUpdate func(*Info)
+	// bus that the swap's status changes are published to, set by the Manager
+	events *events.Bus
+}
+
+// StatusTime is when a swap reached a status.
+type StatusTime struct {
+	Status Status    `json:"status"`
+	Time   time.Time `json:"time"`
 }
 
+// subscriberBufferSize is large enough to hold every status of a swap, so a slow subscriber never
+// blocks the swap.
+const subscriberBufferSize = 16
+
 // ID returns the swap ID.
 func (i *Info) ID() types.Hash {
 	if i == nil {
@@ -60,21 +109,234 @@ func (i *Info) Status() Status {
 		return 0
 	}
 
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	return i.status
 }
 
-// StatusCh returns the swap's status update channel.
+// StatusCh returns the swap's status update channel. It has a single reader; use Subscribe to
+// watch a swap from several places at once.
 func (i *Info) StatusCh() <-chan types.Status {
 	return i.statusCh
 }
 
-// SetStatus ...
+// SetStatus sets the swap's status and sends it to the swap's subscribers. Once the swap
+// completes, the subscribers' channels are closed.
 func (i *Info) SetStatus(s Status) {
 	if i == nil {
 		return
 	}
 
+	i.mu.Lock()
+	if i.status == s {
+		i.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
 	i.status = s
+	i.statusTimes = append(i.statusTimes, StatusTime{Status: s, Time: now})
+	if !s.IsOngoing() && i.endTime.IsZero() {
+		i.endTime = now
+	}
+	for _, ch := range i.subscribers {
+		select {
+		case ch <- s:
+		default:
+		}
+
+		if !s.IsOngoing() {
+			close(ch)
+		}
+	}
+
+	if !s.IsOngoing() {
+		i.subscribers = nil
+	}
+	bus := i.events
+	peerID := i.peerID
+	i.mu.Unlock()
+
+	i.updated()
+	bus.Publish(&events.Event{
+		Type:   events.TypeForStatus(s),
+		SwapID: i.id,
+		Status: s,
+		Time:   now,
+		PeerID: peerID,
+	})
+}
+
+// updated calls the swap's update hook, if it has one. It must be called without the lock held.
+func (i *Info) updated() {
+	i.mu.RLock()
+	onUpdate := i.onUpdate
+	i.mu.RUnlock()
+	if onUpdate != nil {
+		onUpdate(i)
+	}
+}
+
+// Subscribe returns a channel that receives the swap's current status, then each status update
+// until the swap completes, after which it's closed. Unlike StatusCh, any number of subscribers
+// can watch a swap.
+func (i *Info) Subscribe() <-chan Status {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	ch := make(chan Status, subscriberBufferSize)
+	ch <- i.status
+	if !i.status.IsOngoing() {
+		close(ch)
+		return ch
+	}
+
+	i.subscribers = append(i.subscribers, ch)
+	return ch
+}
+
+// MoneroProgress returns the last seen monero height and the height the swap is waiting for.
+// Both are zero if the swap isn't waiting for monero blocks.
+func (i *Info) MoneroProgress() (height, target uint) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.moneroHeight, i.moneroTargetHeight
+}
+
+// SetMoneroProgress sets the current monero height and the height the swap is waiting for.
+func (i *Info) SetMoneroProgress(height, target uint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.moneroHeight = height
+	i.moneroTargetHeight = target
+}
+
+// TxHashes returns the hashes of the ethereum transactions sent for this swap.
+func (i *Info) TxHashes() []ethcommon.Hash {
+	if i == nil {
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]ethcommon.Hash{}, i.txHashes...)
+}
+
+// AddTxHash records an ethereum transaction sent for this swap.
+func (i *Info) AddTxHash(txHash ethcommon.Hash) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	i.txHashes = append(i.txHashes, txHash)
+	i.mu.Unlock()
+
+	i.updated()
+}
+
+// PeerVersion returns the version info of the counterparty's daemon, or nil if it's unknown.
+func (i *Info) PeerVersion() *types.VersionInfo {
+	if i == nil {
+		return nil
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.peerVersion
+}
+
+// SetPeerVersion sets the version info of the counterparty's daemon.
+func (i *Info) SetPeerVersion(v *types.VersionInfo) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	i.peerVersion = v
+	i.mu.Unlock()
+
+	i.updated()
+}
+
+// PeerID returns the libp2p peer ID of the counterparty, or an empty string if it's unknown.
+func (i *Info) PeerID() string {
+	if i == nil {
+		return ""
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.peerID
+}
+
+// SetPeerID sets the libp2p peer ID of the counterparty.
+func (i *Info) SetPeerID(id string) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	i.peerID = id
+	i.mu.Unlock()
+
+	i.updated()
+}
+
+// StartTime returns when the swap was started.
+func (i *Info) StartTime() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.startTime
+}
+
+// EndTime returns when the swap completed, or the zero time if it hasn't.
+func (i *Info) EndTime() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.endTime
+}
+
+// StatusTimes returns when the swap reached each of its statuses, in order.
+func (i *Info) StatusTimes() []StatusTime {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]StatusTime{}, i.statusTimes...)
+}
+
+// TxCosts returns the gas used by the ethereum transactions sent for this swap, and the fees paid
+// for them in wei.
+func (i *Info) TxCosts() (uint64, *big.Int) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.gasUsed, addFees(i.fees, nil)
+}
+
+// AddTxCost records the gas used by an ethereum transaction sent for this swap, and the fee paid
+// for it in wei.
+func (i *Info) AddTxCost(gasUsed uint64, fee *big.Int) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	i.gasUsed += gasUsed
+	i.fees = addFees(i.fees, fee)
+	i.mu.Unlock()
+
+	i.updated()
+}
+
+// addFees returns a new sum of the given fees, either of which may be nil.
+func addFees(a, b *big.Int) *big.Int {
+	sum := new(big.Int)
+	if a != nil {
+		sum.Add(sum, a)
+	}
+	if b != nil {
+		sum.Add(sum, b)
+	}
+	return sum
 }
 
 // NewInfo ...
@@ -88,31 +350,207 @@ func NewInfo(id types.Hash, provides types.ProvidesCoin, providedAmount, receive
 		exchangeRate:   exchangeRate,
 		status:         status,
 		statusCh:       statusCh,
+		startTime:      time.Now(),
+		fees:           big.NewInt(0),
+	}
+
+	info.statusTimes = []StatusTime{{Status: status, Time: info.startTime}}
+	if !status.IsOngoing() {
+		info.endTime = info.startTime
+	}
+
+	return info
+}
+
+// swapRecord is a swap's *Info as it's stored in the database.
+type swapRecord struct {
+	ID             types.Hash         `json:"id"`
+	Index          uint64             `json:"index"`
+	Provides       types.ProvidesCoin `json:"provides"`
+	ProvidedAmount float64            `json:"providedAmount"`
+	ReceivedAmount float64            `json:"receivedAmount"`
+	ExchangeRate   types.ExchangeRate `json:"exchangeRate"`
+	Status         Status             `json:"status"`
+	TxHashes       []ethcommon.Hash   `json:"txHashes,omitempty"`
+	PeerVersion    *types.VersionInfo `json:"peerVersion,omitempty"`
+	PeerID         string             `json:"peerID,omitempty"`
+	StartTime      time.Time          `json:"startTime"`
+	EndTime        time.Time          `json:"endTime"`
+	StatusTimes    []StatusTime       `json:"statusTimes,omitempty"`
+	GasUsed        uint64             `json:"gasUsed,omitempty"`
+	Fees           *big.Int           `json:"fees,omitempty"`
+}
+
+func (i *Info) record() *swapRecord {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return &swapRecord{
+		ID:             i.id,
+		Index:          i.index,
+		Provides:       i.provides,
+		ProvidedAmount: i.providedAmount,
+		ReceivedAmount: i.receivedAmount,
+		ExchangeRate:   i.exchangeRate,
+		Status:         i.status,
+		TxHashes:       i.txHashes,
+		PeerVersion:    i.peerVersion,
+		PeerID:         i.peerID,
+		StartTime:      i.startTime,
+		EndTime:        i.endTime,
+		StatusTimes:    i.statusTimes,
+		GasUsed:        i.gasUsed,
+		Fees:           i.fees,
+	}
+}
+
+func (r *swapRecord) info() *Info {
+	info := NewInfo(r.ID, r.Provides, r.ProvidedAmount, r.ReceivedAmount, r.ExchangeRate, r.Status, nil)
+	info.index = r.Index
+	info.txHashes = r.TxHashes
+	info.peerVersion = r.PeerVersion
+	info.peerID = r.PeerID
+	info.startTime = r.StartTime
+	info.endTime = r.EndTime
+	// swaps stored by older versions didn't record their status times or costs
+	info.statusTimes = r.StatusTimes
+	info.gasUsed = r.GasUsed
+	if r.Fees != nil {
+		info.fees = r.Fees
 	}
 	return info
 }
 
+// StatusJournal records swaps' status transitions.
+type StatusJournal interface {
+	StatusChanged(id types.Hash, status Status) error
+}
+
 // Manager tracks current and past swaps.
 type Manager interface {
 	AddSwap(info *Info) error
 	GetPastIDs() []types.Hash
 	GetPastSwap(types.Hash) *Info
 	GetOngoingSwap(types.Hash) *Info
+	GetOngoingSwaps() []*Info
 	CompleteOngoingSwap(types.Hash)
+	GetIDByLegacyID(uint64) (types.Hash, bool)
+	GetPastSwaps(filter *HistoryFilter) ([]*Info, error)
+	Events() *events.Bus
 }
 
 type manager struct {
 	sync.RWMutex
 	ongoing map[types.Hash]*Info
 	past    map[types.Hash]*Info
+
+	// swap IDs in the order they were added; the index of a swap is its legacy numeric ID
+	legacyIDs []types.Hash
+
+	// database that swaps are persisted to; nil if they're only held in memory
+	db db.Database
+	// serializes writes to the database, so that each swap's indexes are updated from its last record
+	putMu sync.Mutex
+	// journal that status transitions are recorded in, if any
+	journal StatusJournal
+	// bus that the swaps' status changes are published to
+	events *events.Bus
 }
 
-// NewManager ...
+// NewManager returns a Manager that only holds swaps in memory.
 func NewManager() Manager {
 	return &manager{
 		ongoing: make(map[types.Hash]*Info),
 		past:    make(map[types.Hash]*Info),
+		events:  events.NewBus(),
+	}
+}
+
+// NewManagerWithDatabase returns a Manager that persists swaps to the given database, loading the
+// swaps already stored in it, and records their status transitions in the journal, if it's set.
+// Swaps that were ongoing when they were stored can't be resumed, so they're loaded as past swaps
+// with the status they'd reached.
+func NewManagerWithDatabase(d db.Database, j StatusJournal) (Manager, error) {
+	m := &manager{
+		ongoing: make(map[types.Hash]*Info),
+		past:    make(map[types.Hash]*Info),
+		db:      d,
+		journal: j,
+		events:  events.NewBus(),
 	}
+
+	var records []*swapRecord
+	err := d.Iterate(db.SwapsBucket, func(_, value []byte) error {
+		var r *swapRecord
+		if err := json.Unmarshal(value, &r); err != nil {
+			return err
+		}
+
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Index < records[j].Index
+	})
+
+	// the indexes are rebuilt in case they're missing (eg. if the swaps were stored by an older
+	// version) or out of date (eg. if the daemon crashed while updating them)
+	if err = rebuildIndexes(d, records); err != nil {
+		return nil, err
+	}
+
+	for _, r := range records {
+		info := r.info()
+		info.onUpdate = m.save
+		if info.status.IsOngoing() {
+			log.Warnf("swap %s was interrupted with status %s; its funds may need to be recovered", info.id, info.status)
+		}
+
+		m.past[info.id] = info
+		m.legacyIDs = append(m.legacyIDs, info.id)
+	}
+
+	return m, nil
+}
+
+// save writes the swap to the database.
+func (m *manager) save(info *Info) {
+	if err := m.put(info); err != nil {
+		log.Warnf("failed to save swap %s: %s", info.ID(), err)
+	}
+}
+
+func (m *manager) put(info *Info) error {
+	m.putMu.Lock()
+	defer m.putMu.Unlock()
+
+	r := info.record()
+	bz, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	prev, err := m.getRecord(r.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = m.db.Put(db.SwapsBucket, info.id[:], bz); err != nil {
+		return err
+	}
+
+	if err = updateIndexes(m.db, prev, r); err != nil {
+		return err
+	}
+
+	if m.journal == nil {
+		return nil
+	}
+
+	return m.journal.StatusChanged(r.ID, r.Status)
 }
 
 // AddSwap adds the given swap *Info to the Manager.
@@ -120,14 +558,61 @@ func (m *manager) AddSwap(info *Info) error {
 	m.Lock()
 	defer m.Unlock()
 
-	switch info.status.IsOngoing() {
+	existing, isOngoing := m.ongoing[info.id]
+	if !isOngoing {
+		existing = m.past[info.id]
+	}
+
+	var (
+		startTime   time.Time
+		statusTimes []StatusTime
+		gasUsed     uint64
+		fees        *big.Int
+	)
+	if existing != nil && existing != info {
+		startTime = existing.StartTime()
+		statusTimes = existing.StatusTimes()
+		gasUsed, fees = existing.TxCosts()
+	}
+
+	info.mu.Lock()
+	switch {
+	case existing == info:
+		// the same swap is being added again, eg. once it's completed
+	case existing != nil:
+		// the swap is being resumed, so it keeps its place in the history, and what it's done so far
+		info.index = existing.index
+		info.startTime = startTime
+		info.statusTimes = append(statusTimes, info.statusTimes...)
+		info.gasUsed += gasUsed
+		info.fees = addFees(info.fees, fees)
+	default:
+		info.index = uint64(len(m.legacyIDs))
+		m.legacyIDs = append(m.legacyIDs, info.id)
+	}
+	info.events = m.events
+	info.mu.Unlock()
+
+	switch info.Status().IsOngoing() {
 	case true:
 		m.ongoing[info.id] = info
 	default:
 		m.past[info.id] = info
 	}
 
-	return nil
+	if m.db == nil {
+		return nil
+	}
+
+	info.mu.Lock()
+	info.onUpdate = m.save
+	info.mu.Unlock()
+	return m.put(info)
+}
+
+// Events returns the bus that swap status changes are published to.
+func (m *manager) Events() *events.Bus {
+	return m.events
 }
 
 // GetPastIDs returns all past swap IDs.
@@ -157,6 +642,17 @@ func (m *manager) GetOngoingSwap(id types.Hash) *Info {
 	return m.ongoing[id]
 }
 
+// GetOngoingSwaps returns the *Info of every ongoing swap.
+func (m *manager) GetOngoingSwaps() []*Info {
+	m.RLock()
+	defer m.RUnlock()
+	swaps := make([]*Info, 0, len(m.ongoing))
+	for _, info := range m.ongoing {
+		swaps = append(swaps, info)
+	}
+	return swaps
+}
+
 // CompleteOngoingSwap marks the current ongoing swap as completed.
 func (m *manager) CompleteOngoingSwap(id types.Hash) {
 	m.Lock()
@@ -169,3 +665,16 @@ func (m *manager) CompleteOngoingSwap(id types.Hash) {
 	m.past[id] = s
 	delete(m.ongoing, id)
 }
+
+// GetIDByLegacyID returns the ID of the swap with the given legacy numeric ID, which is
+// the order in which the swap was added to the Manager.
+// Deprecated: numeric swap IDs are only supported for backwards compatibility.
+func (m *manager) GetIDByLegacyID(legacyID uint64) (types.Hash, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	if legacyID >= uint64(len(m.legacyIDs)) {
+		return types.Hash{}, false
+	}
+
+	return m.legacyIDs[legacyID], true
+}
@@ -1,10 +1,15 @@
 package swap
 
 import (
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/protocol/events"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,3 +55,346 @@ func TestManager_AddSwap_Past(t *testing.T) {
 	ids := m.GetPastIDs()
 	require.Equal(t, 2, len(ids))
 }
+
+func TestManager_GetIDByLegacyID(t *testing.T) {
+	m := NewManager()
+
+	for i := byte(0); i < 3; i++ {
+		err := m.AddSwap(NewInfo(types.Hash{i}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil))
+		require.NoError(t, err)
+	}
+
+	// re-adding a swap doesn't assign it a new legacy ID
+	m.CompleteOngoingSwap(types.Hash{1})
+	err := m.AddSwap(NewInfo(types.Hash{1}, types.ProvidesXMR, 1, 1, 0.1, types.CompletedSuccess, nil))
+	require.NoError(t, err)
+
+	for i := byte(0); i < 3; i++ {
+		id, ok := m.GetIDByLegacyID(uint64(i))
+		require.True(t, ok)
+		require.Equal(t, types.Hash{i}, id)
+	}
+
+	_, ok := m.GetIDByLegacyID(3)
+	require.False(t, ok)
+}
+
+func TestManager_GetOngoingSwaps(t *testing.T) {
+	m := NewManager()
+	require.Empty(t, m.GetOngoingSwaps())
+
+	for i := byte(0); i < 3; i++ {
+		err := m.AddSwap(NewInfo(types.Hash{i}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil))
+		require.NoError(t, err)
+	}
+
+	m.CompleteOngoingSwap(types.Hash{1})
+	swaps := m.GetOngoingSwaps()
+	require.Len(t, swaps, 2)
+	for _, info := range swaps {
+		require.NotEqual(t, types.Hash{1}, info.ID())
+	}
+}
+
+func TestInfo_TxHashes(t *testing.T) {
+	info := NewInfo(types.Hash{1}, types.ProvidesETH, 1, 1, 0.1, types.ExpectingKeys, nil)
+	info.AddTxHash(ethcommon.Hash{1})
+	info.AddTxHash(ethcommon.Hash{2})
+
+	hashes := info.TxHashes()
+	require.Equal(t, []ethcommon.Hash{{1}, {2}}, hashes)
+
+	// the returned slice is a copy
+	hashes[0] = ethcommon.Hash{3}
+	require.Equal(t, ethcommon.Hash{1}, info.TxHashes()[0])
+
+	var nilInfo *Info
+	nilInfo.AddTxHash(ethcommon.Hash{1})
+	require.Nil(t, nilInfo.TxHashes())
+}
+
+func TestInfo_TxCosts(t *testing.T) {
+	info := NewInfo(types.Hash{1}, types.ProvidesETH, 1, 1, 0.1, types.ExpectingKeys, nil)
+	info.AddTxCost(21000, big.NewInt(100))
+	info.AddTxCost(50000, big.NewInt(200))
+
+	gasUsed, fees := info.TxCosts()
+	require.Equal(t, uint64(71000), gasUsed)
+	require.Equal(t, big.NewInt(300), fees)
+
+	// the returned fees are a copy
+	fees.SetInt64(0)
+	_, fees = info.TxCosts()
+	require.Equal(t, big.NewInt(300), fees)
+}
+
+func TestInfo_Subscribe(t *testing.T) {
+	info := NewInfo(types.Hash{1}, types.ProvidesETH, 1, 1, 0.1, types.ExpectingKeys, nil)
+	first := info.Subscribe()
+	second := info.Subscribe()
+
+	info.SetStatus(types.ETHLocked)
+	info.SetStatus(types.ETHLocked) // unchanged statuses aren't sent again
+	info.SetStatus(types.CompletedSuccess)
+
+	// every subscriber gets every update, then its channel is closed
+	for _, ch := range []<-chan types.Status{first, second} {
+		var statuses []types.Status
+		for s := range ch {
+			statuses = append(statuses, s)
+		}
+		require.Equal(t, []types.Status{types.ExpectingKeys, types.ETHLocked, types.CompletedSuccess}, statuses)
+	}
+
+	// subscribing to a completed swap gets only its final status
+	var statuses []types.Status
+	for s := range info.Subscribe() {
+		statuses = append(statuses, s)
+	}
+	require.Equal(t, []types.Status{types.CompletedSuccess}, statuses)
+}
+
+func TestManager_Events(t *testing.T) {
+	m := NewManager()
+	eventCh, unsubscribe := m.Events().Subscribe()
+	defer unsubscribe()
+
+	info := NewInfo(types.Hash{1}, types.ProvidesETH, 1, 1, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, m.AddSwap(info))
+	info.SetStatus(types.ETHLocked)
+	info.SetPeerID("peerA")
+	info.SetStatus(types.CompletedRefund)
+
+	e := <-eventCh
+	require.Equal(t, events.ETHLocked, e.Type)
+	require.Equal(t, info.ID(), e.SwapID)
+	require.Equal(t, types.ETHLocked, e.Status)
+	require.Empty(t, e.PeerID)
+
+	e = <-eventCh
+	require.Equal(t, events.Refunded, e.Type)
+	require.Equal(t, types.CompletedRefund, e.Status)
+	require.Equal(t, info.EndTime(), e.Time)
+	require.Equal(t, "peerA", e.PeerID)
+}
+
+func TestInfo_PeerVersion(t *testing.T) {
+	info := NewInfo(types.Hash{1}, types.ProvidesETH, 1, 1, 0.1, types.ExpectingKeys, nil)
+	require.Nil(t, info.PeerVersion())
+	require.Equal(t, "unknown", info.PeerVersion().String())
+
+	v := &types.VersionInfo{Version: "0.1.0", Commit: "abc"}
+	info.SetPeerVersion(v)
+	require.Equal(t, v, info.PeerVersion())
+	require.Equal(t, "0.1.0 (commit abc)", info.PeerVersion().String())
+}
+
+func TestManager_Database(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	m, err := NewManagerWithDatabase(d, nil)
+	require.NoError(t, err)
+
+	ongoing := NewInfo(types.Hash{1}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, m.AddSwap(ongoing))
+	past := NewInfo(types.Hash{2}, types.ProvidesETH, 2, 20, 0.1, types.CompletedSuccess, nil)
+	require.NoError(t, m.AddSwap(past))
+
+	ongoing.SetStatus(types.XMRLocked)
+	ongoing.AddTxHash(ethcommon.Hash{3})
+	ongoing.AddTxCost(21000, big.NewInt(1e9))
+
+	// the swaps are loaded from the database, and the interrupted swap is no longer ongoing
+	m, err = NewManagerWithDatabase(d, nil)
+	require.NoError(t, err)
+	require.Empty(t, m.GetOngoingSwaps())
+
+	info := m.GetPastSwap(types.Hash{1})
+	require.NotNil(t, info)
+	require.Equal(t, types.XMRLocked, info.Status())
+	require.Equal(t, []ethcommon.Hash{{3}}, info.TxHashes())
+	gasUsed, fees := info.TxCosts()
+	require.Equal(t, uint64(21000), gasUsed)
+	require.Equal(t, big.NewInt(1e9), fees)
+	statusTimes := info.StatusTimes()
+	require.Len(t, statusTimes, 2)
+	require.Equal(t, types.XMRLocked, statusTimes[1].Status)
+
+	info = m.GetPastSwap(types.Hash{2})
+	require.NotNil(t, info)
+	require.Equal(t, types.CompletedSuccess, info.Status())
+	require.Equal(t, float64(20), info.ReceivedAmount())
+
+	id, ok := m.GetIDByLegacyID(1)
+	require.True(t, ok)
+	require.Equal(t, types.Hash{2}, id)
+
+	// updates to loaded swaps are persisted too
+	m.GetPastSwap(types.Hash{1}).SetStatus(types.CompletedRefund)
+	m, err = NewManagerWithDatabase(d, nil)
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedRefund, m.GetPastSwap(types.Hash{1}).Status())
+}
+
+type mockStatusJournal struct {
+	statuses []Status
+}
+
+func (j *mockStatusJournal) StatusChanged(_ types.Hash, status Status) error {
+	j.statuses = append(j.statuses, status)
+	return nil
+}
+
+func TestManager_Journal(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	j := new(mockStatusJournal)
+	m, err := NewManagerWithDatabase(d, j)
+	require.NoError(t, err)
+
+	info := NewInfo(types.Hash{1}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, m.AddSwap(info))
+	info.SetStatus(types.KeysExchanged)
+	info.SetStatus(types.CompletedAbort)
+	require.Equal(t, []Status{types.ExpectingKeys, types.KeysExchanged, types.CompletedAbort}, j.statuses)
+}
+
+func TestManager_GetPastSwaps(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	m, err := NewManagerWithDatabase(d, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	newSwap := func(id byte, status Status, peerID string) *Info {
+		info := NewInfo(types.Hash{id}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil)
+		info.startTime = start.Add(time.Duration(id) * time.Hour)
+		require.NoError(t, m.AddSwap(info))
+		info.SetPeerID(peerID)
+		info.SetStatus(status)
+		return info
+	}
+
+	newSwap(1, types.CompletedSuccess, "peerA")
+	newSwap(2, types.CompletedRefund, "peerB")
+	newSwap(3, types.CompletedAbort, "peerA")
+	newSwap(4, types.CompletedSuccess, "peerB")
+	// ongoing swaps aren't past swaps
+	newSwap(5, types.XMRLocked, "peerA")
+	for id := byte(1); id <= 4; id++ {
+		m.CompleteOngoingSwap(types.Hash{id})
+	}
+
+	ids := func(filter *HistoryFilter) []byte {
+		swaps, err := m.GetPastSwaps(filter)
+		require.NoError(t, err)
+		var ids []byte
+		for _, info := range swaps {
+			ids = append(ids, info.ID()[0])
+		}
+		return ids
+	}
+
+	check := func() {
+		require.Equal(t, []byte{1, 2, 3, 4}, ids(nil))
+		require.Equal(t, []byte{2, 3}, ids(&HistoryFilter{
+			From: start.Add(2 * time.Hour),
+			To:   start.Add(4 * time.Hour),
+		}))
+		require.Equal(t, []byte{1, 4}, ids(&HistoryFilter{Statuses: []Status{types.CompletedSuccess}}))
+		require.Equal(t, []byte{2, 3}, ids(&HistoryFilter{
+			Statuses: []Status{types.CompletedAbort, types.CompletedRefund},
+		}))
+		require.Equal(t, []byte{1, 3}, ids(&HistoryFilter{PeerID: "peerA"}))
+		require.Equal(t, []byte{3}, ids(&HistoryFilter{PeerID: "peerA", Statuses: []Status{types.CompletedAbort}}))
+		require.Equal(t, []byte{4}, ids(&HistoryFilter{PeerID: "peerB", From: start.Add(3 * time.Hour)}))
+	}
+	check()
+
+	// the history survives a restart, where the interrupted swap becomes a past swap
+	m, err = NewManagerWithDatabase(d, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{5}, ids(&HistoryFilter{Statuses: []Status{types.XMRLocked}}))
+	require.Equal(t, []byte{1, 3, 5}, ids(&HistoryFilter{PeerID: "peerA"}))
+
+	info := m.GetPastSwap(types.Hash{5})
+	require.Equal(t, "peerA", info.PeerID())
+	require.True(t, info.EndTime().IsZero())
+	info.SetStatus(types.CompletedRefund)
+	require.False(t, info.EndTime().IsZero())
+	require.Equal(t, []byte{2, 5}, ids(&HistoryFilter{Statuses: []Status{types.CompletedRefund}}))
+	require.Empty(t, ids(&HistoryFilter{Statuses: []Status{types.XMRLocked}}))
+}
+
+func TestManager_GetPastSwaps_Memory(t *testing.T) {
+	m := NewManager()
+	for id := byte(1); id <= 3; id++ {
+		info := NewInfo(types.Hash{id}, types.ProvidesXMR, 1, 1, 0.1, types.CompletedSuccess, nil)
+		info.startTime = time.Unix(int64(10-id), 0)
+		require.NoError(t, m.AddSwap(info))
+	}
+
+	swaps, err := m.GetPastSwaps(&HistoryFilter{To: time.Unix(9, 0)})
+	require.NoError(t, err)
+	require.Len(t, swaps, 2)
+	require.Equal(t, types.Hash{3}, swaps[0].ID())
+	require.Equal(t, types.Hash{2}, swaps[1].ID())
+}
+
+func TestManager_GetPastSwaps_Pages(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	withDB, err := NewManagerWithDatabase(d, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for _, m := range []Manager{withDB, NewManager()} {
+		for id := byte(1); id <= 5; id++ {
+			provides := types.ProvidesXMR
+			if id%2 == 0 {
+				provides = types.ProvidesETH
+			}
+
+			info := NewInfo(types.Hash{id}, provides, 1, 1, 0.1, types.ExpectingKeys, nil)
+			info.startTime = start.Add(time.Duration(id) * time.Hour)
+			require.NoError(t, m.AddSwap(info))
+			info.SetStatus(types.CompletedSuccess)
+			m.CompleteOngoingSwap(info.ID())
+		}
+
+		// pages through the swaps we provided XMR in, two at a time
+		filter := &HistoryFilter{Provides: types.ProvidesXMR, Limit: 2}
+		var pages [][]byte
+		for {
+			swaps, err := m.GetPastSwaps(filter)
+			require.NoError(t, err)
+			if len(swaps) == 0 {
+				break
+			}
+
+			var ids []byte
+			for _, info := range swaps {
+				ids = append(ids, info.ID()[0])
+			}
+			pages = append(pages, ids)
+			filter.After = HistoryCursor(swaps[len(swaps)-1])
+		}
+		require.Equal(t, [][]byte{{1, 3}, {5}}, pages)
+
+		// the cursor is combined with the other filters
+		swaps, err := m.GetPastSwaps(&HistoryFilter{
+			From:  start.Add(3 * time.Hour),
+			After: HistoryCursor(m.GetPastSwap(types.Hash{1})),
+		})
+		require.NoError(t, err)
+		require.Len(t, swaps, 3)
+		require.Equal(t, types.Hash{3}, swaps[0].ID())
+
+		_, err = m.GetPastSwaps(&HistoryFilter{After: "00"})
+		require.ErrorIs(t, err, ErrInvalidCursor)
+	}
+}
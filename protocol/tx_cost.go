@@ -0,0 +1,33 @@
+package protocol
+
+import (
+	"context"
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// TxGetter gets ethereum transactions and their receipts.
+type TxGetter interface {
+	TransactionByHash(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
+}
+
+// GetTxCost returns the gas used by the mined transaction with the given hash, and the fee paid
+// for it in wei. For dynamic fee transactions, the fee cap is used as the gas price, as the block's
+// base fee isn't known, so their fee is an upper bound.
+func GetTxCost(ctx context.Context, ec TxGetter, txHash ethcommon.Hash) (uint64, *big.Int, error) {
+	receipt, err := ec.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	tx, _, err := ec.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), tx.GasPrice())
+	return receipt.GasUsed, fee, nil
+}
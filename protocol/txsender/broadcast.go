@@ -0,0 +1,292 @@
+package txsender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Method is a swap contract method whose transactions can be broadcast with their own strategy.
+type Method string
+
+// swap contract methods that send transactions
+const (
+	MethodNewSwap  Method = "new_swap"
+	MethodSetReady Method = "set_ready"
+	MethodClaim    Method = "claim"
+	MethodRefund   Method = "refund"
+)
+
+var allMethods = []Method{MethodNewSwap, MethodSetReady, MethodClaim, MethodRefund}
+
+// broadcast strategies; relay and relayer strategies are followed by `:<url>`
+const (
+	StrategyDirect   = "direct"
+	StrategyRelay    = "relay"
+	StrategyRelayer  = "relayer"
+	StrategyExternal = "external"
+)
+
+const relayerTimeout = time.Second * 30
+
+var (
+	errUnknownMethod       = errors.New("unknown transaction method")
+	errUnknownStrategy     = errors.New("unknown broadcast strategy")
+	errStrategyRequiresURL = errors.New("broadcast strategy requires a url")
+	errNoExternalSender    = errors.New("external broadcast strategy requires an external sender")
+)
+
+// Broadcaster submits signed transactions to the network.
+type Broadcaster interface {
+	Broadcast(ctx context.Context, tx *ethtypes.Transaction) error
+}
+
+// directBroadcaster sends transactions through the daemon's own ethereum endpoint.
+type directBroadcaster struct {
+	ec ethClient
+}
+
+func (b *directBroadcaster) Broadcast(ctx context.Context, tx *ethtypes.Transaction) error {
+	return b.ec.SendTransaction(ctx, tx)
+}
+
+// relayBroadcaster sends transactions with eth_sendRawTransaction to a private relay endpoint,
+// such as a private mempool RPC, so they aren't visible in the public mempool before inclusion.
+type relayBroadcaster struct {
+	client *rpc.Client
+}
+
+func newRelayBroadcaster(url string) (*relayBroadcaster, error) {
+	client, err := rpc.DialHTTP(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return &relayBroadcaster{client: client}, nil
+}
+
+func (b *relayBroadcaster) Broadcast(ctx context.Context, tx *ethtypes.Transaction) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	return b.client.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
+}
+
+// relayerRequest is the body POSTed to a relayer service.
+type relayerRequest struct {
+	RawTransaction string `json:"rawTransaction"`
+}
+
+// relayerBroadcaster hands signed transactions to a relayer service over HTTP, which is
+// responsible for getting them included.
+type relayerBroadcaster struct {
+	url    string
+	client *http.Client
+}
+
+func newRelayerBroadcaster(url string) *relayerBroadcaster {
+	return &relayerBroadcaster{
+		url:    url,
+		client: &http.Client{Timeout: relayerTimeout},
+	}
+}
+
+func (b *relayerBroadcaster) Broadcast(ctx context.Context, tx *ethtypes.Transaction) error {
+	data, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(&relayerRequest{RawTransaction: hexutil.Encode(data)})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("relayer rejected transaction: status %d: %s", resp.StatusCode,
+			strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// BroadcastConfig selects the broadcast strategy for each swap contract method. Strategies are
+// one of `direct`, `relay:<url>`, `relayer:<url>` or `external`. Methods without a strategy
+// use Default, or `direct` if it's unset.
+type BroadcastConfig struct {
+	Default string            `json:"default"`
+	Methods map[Method]string `json:"methods"`
+}
+
+// LoadBroadcastConfig reads the broadcast config for the given chain ID from a JSON file mapping
+// chain IDs to configs, eg. `{"1": {"methods": {"claim": "relay:https://..."}}}`. If the file has
+// no entry for the chain, every method is broadcast directly.
+func LoadBroadcastConfig(path string, chainID int64) (*BroadcastConfig, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var chains map[string]*BroadcastConfig
+	if err = json.Unmarshal(data, &chains); err != nil {
+		return nil, fmt.Errorf("failed to parse broadcast config %s: %w", path, err)
+	}
+
+	cfg, has := chains[strconv.FormatInt(chainID, 10)]
+	if !has || cfg == nil {
+		log.Infof("no broadcast config for chain ID %d, broadcasting transactions directly", chainID)
+		return &BroadcastConfig{}, nil
+	}
+
+	if err = cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Strategy returns the strategy used to broadcast transactions of the given method.
+func (c *BroadcastConfig) Strategy(m Method) string {
+	if c == nil {
+		return StrategyDirect
+	}
+
+	if s, has := c.Methods[m]; has && s != "" {
+		return s
+	}
+
+	if c.Default != "" {
+		return c.Default
+	}
+
+	return StrategyDirect
+}
+
+// UsesExternalSigner returns true if any method's transactions are signed by an external signer.
+func (c *BroadcastConfig) UsesExternalSigner() bool {
+	for _, m := range allMethods {
+		if c.Strategy(m) == StrategyExternal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OnlyExternal returns true if every method's transactions are signed by an external signer.
+func (c *BroadcastConfig) OnlyExternal() bool {
+	for _, m := range allMethods {
+		if c.Strategy(m) != StrategyExternal {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *BroadcastConfig) validate() error {
+	for m := range c.Methods {
+		if !isMethod(m) {
+			return fmt.Errorf("%w: %s", errUnknownMethod, m)
+		}
+	}
+
+	if c.Default != "" {
+		if _, err := parseStrategy(c.Default); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range c.Methods {
+		if _, err := parseStrategy(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isMethod(m Method) bool {
+	for _, method := range allMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+type strategy struct {
+	name string
+	url  string
+}
+
+func parseStrategy(s string) (*strategy, error) {
+	name, url := s, ""
+	if i := strings.Index(s, ":"); i != -1 {
+		name, url = s[:i], s[i+1:]
+	}
+
+	switch name {
+	case StrategyDirect, StrategyExternal:
+		if url != "" {
+			return nil, fmt.Errorf("%w: %s", errUnknownStrategy, s)
+		}
+	case StrategyRelay, StrategyRelayer:
+		if url == "" {
+			return nil, fmt.Errorf("%w: %s", errStrategyRequiresURL, name)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnknownStrategy, s)
+	}
+
+	return &strategy{name: name, url: url}, nil
+}
+
+// newBroadcaster returns the broadcaster for the given strategy. It returns nil for the
+// external strategy, since those transactions are signed and sent by the external signer.
+func newBroadcaster(ec ethClient, s string) (Broadcaster, error) {
+	st, err := parseStrategy(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch st.name {
+	case StrategyRelay:
+		return newRelayBroadcaster(st.url)
+	case StrategyRelayer:
+		return newRelayerBroadcaster(st.url), nil
+	case StrategyExternal:
+		return nil, nil
+	default:
+		return &directBroadcaster{ec: ec}, nil
+	}
+}
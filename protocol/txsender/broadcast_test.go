@@ -0,0 +1,180 @@
+package txsender
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSignedTx(t *testing.T) *ethtypes.Transaction {
+	txOpts := newTestTxOpts(t)
+	to := ethcommon.Address{1}
+	tx, err := txOpts.Signer(txOpts.From, ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    1,
+		GasPrice: big.NewInt(1000),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(1),
+	}))
+	require.NoError(t, err)
+	return tx
+}
+
+func writeBroadcastConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "broadcast.json")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoadBroadcastConfig(t *testing.T) {
+	path := writeBroadcastConfig(t, `{
+		"1": {"default": "relayer:https://relayer.example", "methods": {"new_swap": "direct"}},
+		"3": {"methods": {"claim": "relay:https://relay.example", "refund": "external"}}
+	}`)
+
+	cfg, err := LoadBroadcastConfig(path, 1)
+	require.NoError(t, err)
+	require.Equal(t, StrategyDirect, cfg.Strategy(MethodNewSwap))
+	require.Equal(t, "relayer:https://relayer.example", cfg.Strategy(MethodClaim))
+	require.False(t, cfg.UsesExternalSigner())
+
+	cfg, err = LoadBroadcastConfig(path, 3)
+	require.NoError(t, err)
+	require.Equal(t, StrategyDirect, cfg.Strategy(MethodNewSwap))
+	require.Equal(t, "relay:https://relay.example", cfg.Strategy(MethodClaim))
+	require.True(t, cfg.UsesExternalSigner())
+	require.False(t, cfg.OnlyExternal())
+
+	// chains without an entry broadcast everything directly
+	cfg, err = LoadBroadcastConfig(path, 1337)
+	require.NoError(t, err)
+	for _, m := range allMethods {
+		require.Equal(t, StrategyDirect, cfg.Strategy(m))
+	}
+}
+
+func TestLoadBroadcastConfig_Invalid(t *testing.T) {
+	path := writeBroadcastConfig(t, `{"1": {"methods": {"withdraw": "direct"}}}`)
+	_, err := LoadBroadcastConfig(path, 1)
+	require.ErrorIs(t, err, errUnknownMethod)
+
+	path = writeBroadcastConfig(t, `{"1": {"methods": {"claim": "carrier-pigeon"}}}`)
+	_, err = LoadBroadcastConfig(path, 1)
+	require.ErrorIs(t, err, errUnknownStrategy)
+
+	path = writeBroadcastConfig(t, `{"1": {"default": "relay"}}`)
+	_, err = LoadBroadcastConfig(path, 1)
+	require.ErrorIs(t, err, errStrategyRequiresURL)
+}
+
+func TestRelayBroadcaster(t *testing.T) {
+	tx := newTestSignedTx(t)
+
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []string        `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "eth_sendRawTransaction", req.Method)
+		received = req.Params[0]
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":"` + tx.Hash().Hex() + `"}`))
+	}))
+	defer server.Close()
+
+	b, err := newBroadcaster(nil, "relay:"+server.URL)
+	require.NoError(t, err)
+	require.NoError(t, b.Broadcast(context.Background(), tx))
+
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(data), received)
+}
+
+func TestRelayerBroadcaster(t *testing.T) {
+	tx := newTestSignedTx(t)
+
+	var req relayerRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+	}))
+	defer server.Close()
+
+	b, err := newBroadcaster(nil, "relayer:"+server.URL)
+	require.NoError(t, err)
+	require.NoError(t, b.Broadcast(context.Background(), tx))
+
+	data, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, hexutil.Encode(data), req.RawTransaction)
+}
+
+func TestRelayerBroadcaster_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		http.Error(w, "nonce too low", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	b, err := newBroadcaster(nil, "relayer:"+server.URL)
+	require.NoError(t, err)
+	err = b.Broadcast(context.Background(), newTestSignedTx(t))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "nonce too low")
+}
+
+type recordingBroadcaster struct {
+	sync.Mutex
+	ec   *mockEthClient
+	sent []*ethtypes.Transaction
+}
+
+func (b *recordingBroadcaster) Broadcast(ctx context.Context, tx *ethtypes.Transaction) error {
+	b.Lock()
+	b.sent = append(b.sent, tx)
+	b.Unlock()
+	return b.ec.SendTransaction(ctx, tx)
+}
+
+func TestTxMonitor_ResubmitsWithBroadcaster(t *testing.T) {
+	tx := newTestSignedTx(t)
+
+	ec := new(mockEthClient)
+	b := &recordingBroadcaster{ec: ec}
+	m := newTxMonitor(ec, newTestTxOpts(t), 1).withBroadcaster(b)
+	m.stuckBlocks = 2
+	m.sleep = time.Millisecond
+
+	txHash, _, err := m.waitForReceipt(context.Background(), tx)
+	require.NoError(t, err)
+	require.Len(t, b.sent, 1)
+	require.Equal(t, b.sent[0].Hash(), txHash)
+}
+
+func TestNewSenderWithBroadcastConfig_ExternalRequiresSender(t *testing.T) {
+	cfg := &BroadcastConfig{
+		Methods: map[Method]string{MethodClaim: StrategyExternal},
+	}
+
+	_, err := NewSenderWithBroadcastConfig(context.Background(), new(mockEthClient), nil,
+		newTestTxOpts(t), 1, cfg, nil)
+	require.ErrorIs(t, err, errNoExternalSender)
+}
@@ -12,15 +12,17 @@ import (
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/swapfactory"
 
+	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 var (
 	errTransactionTimeout = errors.New("timed out waiting for transaction to be signed")
+	errNoSignerConnected  = errors.New("transaction must be signed by an external signer, but none connected")
 	errNoSwapWithID       = errors.New("no swap with given id")
+	errSignerSessionEnded = errors.New("signer session ended before transaction was signed")
 
 	transactionTimeout = time.Minute * 2 // amount of time user has to sign message
 )
@@ -37,23 +39,32 @@ type swapChs struct {
 	out chan *Transaction
 	// incoming tx hashes
 	in chan ethcommon.Hash
+	// closed when the swap is deleted from the sender
+	done chan struct{}
+	// number of signers currently connected for this swap
+	signers int
+	// address of the last signer to connect, which sends the swap's transactions
+	signer ethcommon.Address
 }
 
 // ExternalSender represents a transaction signer and sender that is external to the daemon (ie. a front-end)
 type ExternalSender struct {
 	ctx          context.Context
-	ec           *ethclient.Client
+	ec           ethClient
 	abi          *abi.ABI
 	contractAddr ethcommon.Address
 
+	// number of confirmations required before a transaction is considered final
+	confirmations uint64
+
 	sync.RWMutex
 
 	swaps map[types.Hash]*swapChs
 }
 
 // NewExternalSender returns a new ExternalSender
-func NewExternalSender(ctx context.Context, env common.Environment, ec *ethclient.Client,
-	contractAddr ethcommon.Address) (*ExternalSender, error) {
+func NewExternalSender(ctx context.Context, env common.Environment, ec ethClient,
+	contractAddr ethcommon.Address, confirmations uint64) (*ExternalSender, error) {
 	abi, err := swapfactory.SwapFactoryMetaData.GetAbi()
 	if err != nil {
 		return nil, err
@@ -65,17 +76,21 @@ func NewExternalSender(ctx context.Context, env common.Environment, ec *ethclien
 	}
 
 	return &ExternalSender{
-		ctx:          ctx,
-		ec:           ec,
-		abi:          abi,
-		contractAddr: contractAddr,
-		swaps:        make(map[types.Hash]*swapChs),
+		ctx:           ctx,
+		ec:            ec,
+		abi:           abi,
+		contractAddr:  contractAddr,
+		confirmations: confirmations,
+		swaps:         make(map[types.Hash]*swapChs),
 	}, nil
 }
 
 // SetContract ...
 func (s *ExternalSender) SetContract(_ *swapfactory.SwapFactory) {}
 
+// SetGasConfig does nothing, as the external signer chooses the fees of the transactions it sends.
+func (s *ExternalSender) SetGasConfig(_ *GasConfig) {}
+
 // SetContractAddress ...
 func (s *ExternalSender) SetContractAddress(addr ethcommon.Address) {
 	s.contractAddr = addr
@@ -93,6 +108,17 @@ func (s *ExternalSender) OngoingCh(id types.Hash) (<-chan *Transaction, error) {
 	return chs.out, nil
 }
 
+// DoneCh returns a channel that is closed once the swap w/ the given ID is deleted from the sender
+func (s *ExternalSender) DoneCh(id types.Hash) (<-chan struct{}, error) {
+	s.RLock()
+	defer s.RUnlock()
+	chs, has := s.swaps[id]
+	if !has {
+		return nil, errNoSwapWithID
+	}
+	return chs.done, nil
+}
+
 // IncomingCh returns the channel of incoming transaction hashes that have been signed and submitted
 func (s *ExternalSender) IncomingCh(id types.Hash) (chan<- ethcommon.Hash, error) {
 	s.RLock()
@@ -104,7 +130,9 @@ func (s *ExternalSender) IncomingCh(id types.Hash) (chan<- ethcommon.Hash, error
 	return chs.in, nil
 }
 
-// AddID initialises the sender with a swap w/ the given ID
+// AddID initialises the sender with a swap w/ the given ID.
+// The session is kept until DeleteID is called, so a signer may disconnect and reconnect
+// mid-swap; any transaction that has not yet been signed is re-sent to the reconnected signer.
 func (s *ExternalSender) AddID(id types.Hash) {
 	s.Lock()
 	defer s.Unlock()
@@ -114,15 +142,59 @@ func (s *ExternalSender) AddID(id types.Hash) {
 	}
 
 	s.swaps[id] = &swapChs{
-		out: make(chan *Transaction),
-		in:  make(chan ethcommon.Hash),
+		out:  make(chan *Transaction),
+		in:   make(chan ethcommon.Hash),
+		done: make(chan struct{}),
 	}
 }
 
+// SignerConnected records that a signer with the given address connected for the swap w/ the
+// given ID, which must have been added with AddID. The returned function must be called once the
+// signer disconnects.
+func (s *ExternalSender) SignerConnected(id types.Hash, addr ethcommon.Address) (disconnected func(), err error) {
+	s.Lock()
+	defer s.Unlock()
+	chs, has := s.swaps[id]
+	if !has {
+		return nil, errNoSwapWithID
+	}
+
+	chs.signers++
+	chs.signer = addr
+	return func() {
+		s.Lock()
+		defer s.Unlock()
+		chs.signers--
+	}, nil
+}
+
+func (s *ExternalSender) hasSigner(chs *swapChs) bool {
+	s.RLock()
+	defer s.RUnlock()
+	return chs.signers > 0
+}
+
+// signerAddress returns the address of the last signer to connect for the swap w/ the given ID.
+func (s *ExternalSender) signerAddress(id types.Hash) ethcommon.Address {
+	s.RLock()
+	defer s.RUnlock()
+	chs, has := s.swaps[id]
+	if !has {
+		return ethcommon.Address{}
+	}
+	return chs.signer
+}
+
 // DeleteID deletes the swap w/ the given ID from the sender
 func (s *ExternalSender) DeleteID(id types.Hash) {
 	s.Lock()
 	defer s.Unlock()
+	chs, has := s.swaps[id]
+	if !has {
+		return
+	}
+
+	close(chs.done)
 	delete(s.swaps, id)
 }
 
@@ -141,27 +213,13 @@ func (s *ExternalSender) NewSwap(id types.Hash, _pubKeyClaim [32]byte, _pubKeyRe
 		Value: fmt.Sprintf("%v", common.EtherAmount(*value).AsEther()),
 	}
 
-	s.RLock()
-	defer s.RUnlock()
-	chs, has := s.swaps[id]
-	if !has {
-		return ethcommon.Hash{}, nil, errNoSwapWithID
-	}
-
-	chs.out <- tx
-	var txHash ethcommon.Hash
-	select {
-	case <-time.After(transactionTimeout):
-		return ethcommon.Hash{}, nil, errTransactionTimeout
-	case txHash = <-chs.in:
-	}
-
-	receipt, err := waitForReceipt(s.ctx, s.ec, txHash)
+	txHash, receipt, err := s.send(id, tx)
 	if err != nil {
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return txHash, receipt, nil
+	// the swap is owned by whoever sent new_swap, so replay the call from the signer's address
+	return txHash, receipt, s.checkStatus(receipt, s.signerAddress(id), input, value)
 }
 
 // SetReady prompts the external sender to sign a set_ready transaction
@@ -172,18 +230,24 @@ func (s *ExternalSender) SetReady(id types.Hash,
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return s.sendAndReceive(id, input)
+	return s.sendAndReceive(id, input, _swap.Owner)
 }
 
-// Claim prompts the external sender to sign a claim transaction
+// Claim prompts the external sender to sign a claim transaction. The external signer chooses the
+// transaction's fee, so send options are ignored.
 func (s *ExternalSender) Claim(id types.Hash, _swap swapfactory.SwapFactorySwap,
-	_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	_s [32]byte, _ ...SendOption) (ethcommon.Hash, *ethtypes.Receipt, error) {
 	input, err := s.abi.Pack("claim", _swap, _s)
 	if err != nil {
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return s.sendAndReceive(id, input)
+	txHash, receipt, err := s.sendAndReceive(id, input, _swap.Claimer)
+	if err != nil {
+		return txHash, receipt, err
+	}
+
+	return txHash, receipt, checkSwapEvent(receipt, eventClaimed, _swap)
 }
 
 // Refund prompts the external sender to sign a refund transaction
@@ -194,35 +258,96 @@ func (s *ExternalSender) Refund(id types.Hash, _swap swapfactory.SwapFactorySwap
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return s.sendAndReceive(id, input)
+	txHash, receipt, err := s.sendAndReceive(id, input, _swap.Owner)
+	if err != nil {
+		return txHash, receipt, err
+	}
+
+	return txHash, receipt, checkSwapEvent(receipt, eventRefunded, _swap)
 }
 
-func (s *ExternalSender) sendAndReceive(id types.Hash,
-	input []byte) (ethcommon.Hash, *ethtypes.Receipt, error) {
+// sendAndReceive has the signer send a transaction calling the contract with the given input,
+// which is expected to be sent from the given address.
+func (s *ExternalSender) sendAndReceive(id types.Hash, input []byte,
+	from ethcommon.Address) (ethcommon.Hash, *ethtypes.Receipt, error) {
 	tx := &Transaction{
 		To:   s.contractAddr,
 		Data: fmt.Sprintf("0x%x", input),
 	}
 
+	txHash, receipt, err := s.send(id, tx)
+	if err != nil {
+		return ethcommon.Hash{}, nil, err
+	}
+
+	return txHash, receipt, s.checkStatus(receipt, from, input, nil)
+}
+
+// checkStatus returns an error with the revert reason if the transaction reverted.
+func (s *ExternalSender) checkStatus(receipt *ethtypes.Receipt, from ethcommon.Address, input []byte,
+	value *big.Int) error {
+	if receipt.Status != ethtypes.ReceiptStatusFailed {
+		return nil
+	}
+
+	to := s.contractAddr
+	msg := eth.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  input,
+	}
+	return revertError(s.ctx, s.ec, msg, receipt)
+}
+
+// send offers the transaction to the signer until a signed transaction hash is received.
+// If the signer disconnects after the transaction was delivered but before it was signed,
+// the transaction is delivered again once a signer reconnects.
+func (s *ExternalSender) send(id types.Hash, tx *Transaction) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	// swaps being recovered may not have a session yet; open one so a signer can connect to it
+	s.AddID(id)
+
 	s.RLock()
-	defer s.RUnlock()
-	chs, has := s.swaps[id]
-	if !has {
-		return ethcommon.Hash{}, nil, errNoSwapWithID
+	chs := s.swaps[id]
+	s.RUnlock()
+
+	if !s.hasSigner(chs) {
+		log.Warnf("transaction for swap %s must be signed by the external signer, but none is connected; "+
+			"waiting up to %s for a signer to call signer_subscribe with this swap ID",
+			id,
+			transactionTimeout,
+		)
 	}
 
-	chs.out <- tx
+	timeout := time.After(transactionTimeout)
 	var txHash ethcommon.Hash
-	select {
-	case <-time.After(transactionTimeout):
-		return ethcommon.Hash{}, nil, errTransactionTimeout
-	case txHash = <-chs.in:
+	for signed := false; !signed; {
+		select {
+		case <-s.ctx.Done():
+			return ethcommon.Hash{}, nil, s.ctx.Err()
+		case <-chs.done:
+			return ethcommon.Hash{}, nil, errSignerSessionEnded
+		case <-timeout:
+			if !s.hasSigner(chs) {
+				return ethcommon.Hash{}, nil, fmt.Errorf("%w: swap %s", errNoSignerConnected, id)
+			}
+			return ethcommon.Hash{}, nil, errTransactionTimeout
+		case chs.out <- tx:
+		case txHash = <-chs.in:
+			signed = true
+		}
+	}
+
+	// the signer submits the transaction itself, so it can only be recorded afterwards
+	if err := recordSubmitted(id, txHash); err != nil {
+		log.Warnf("failed to record transaction %s in journal: %s", txHash, err)
 	}
 
-	receipt, err := waitForReceipt(s.ctx, s.ec, txHash)
+	receipt, err := WaitForReceipt(s.ctx, s.ec, txHash, s.confirmations)
 	if err != nil {
 		return ethcommon.Hash{}, nil, err
 	}
 
+	recordConfirmed(id, txHash)
 	return txHash, receipt, nil
 }
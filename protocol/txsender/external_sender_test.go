@@ -0,0 +1,215 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// includedEthClient reports every transaction as included in block 1.
+type includedEthClient struct {
+	mockEthClient
+}
+
+func (c *includedEthClient) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	return &ethtypes.Receipt{
+		Status:      ethtypes.ReceiptStatusSuccessful,
+		TxHash:      txHash,
+		BlockNumber: big.NewInt(1),
+	}, nil
+}
+
+func newTestExternalSender(t *testing.T) *ExternalSender {
+	s, err := NewExternalSender(context.Background(), common.Development, new(includedEthClient),
+		ethcommon.Address{1}, 1)
+	require.NoError(t, err)
+	return s
+}
+
+func TestExternalSender_ReplayAfterReconnect(t *testing.T) {
+	s := newTestExternalSender(t)
+	id := types.Hash{1}
+	s.AddID(id)
+
+	type result struct {
+		txHash ethcommon.Hash
+		err    error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		txHash, _, err := s.sendAndReceive(id, []byte{1, 2, 3}, ethcommon.Address{})
+		resCh <- result{txHash, err}
+	}()
+
+	outCh, err := s.OngoingCh(id)
+	require.NoError(t, err)
+	inCh, err := s.IncomingCh(id)
+	require.NoError(t, err)
+
+	// the first signer receives the transaction, then disconnects without signing it
+	first := <-outCh
+	require.Equal(t, "0x010203", first.Data)
+
+	// re-subscribing the same ID keeps the session and its pending transaction
+	s.AddID(id)
+	second := <-outCh
+	require.Equal(t, first, second)
+
+	inCh <- ethcommon.Hash{9}
+	res := <-resCh
+	require.NoError(t, res.err)
+	require.Equal(t, ethcommon.Hash{9}, res.txHash)
+}
+
+func TestExternalSender_DeleteIDEndsSession(t *testing.T) {
+	s := newTestExternalSender(t)
+	id := types.Hash{1}
+	s.AddID(id)
+
+	doneCh, err := s.DoneCh(id)
+	require.NoError(t, err)
+	outCh, err := s.OngoingCh(id)
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := s.sendAndReceive(id, []byte{1}, ethcommon.Address{})
+		errCh <- err
+	}()
+
+	<-outCh
+	s.DeleteID(id)
+	<-doneCh
+	require.Equal(t, errSignerSessionEnded, <-errCh)
+
+	_, err = s.OngoingCh(id)
+	require.Equal(t, errNoSwapWithID, err)
+}
+
+func TestExternalSender_NoSignerConnected(t *testing.T) {
+	prev := transactionTimeout
+	transactionTimeout = time.Millisecond * 50
+	defer func() {
+		transactionTimeout = prev
+	}()
+
+	s := newTestExternalSender(t)
+	_, _, err := s.sendAndReceive(types.Hash{1}, []byte{1}, ethcommon.Address{})
+	require.ErrorIs(t, err, errNoSignerConnected)
+}
+
+func TestExternalSender_SignerConnectsForRecoveredSwap(t *testing.T) {
+	s := newTestExternalSender(t)
+	id := types.Hash{2}
+
+	// a swap being recovered sends a transaction before any signer has subscribed to it
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := s.sendAndReceive(id, []byte{1}, ethcommon.Address{})
+		errCh <- err
+	}()
+
+	// the session is opened when the transaction is sent, before which the signer is refused
+	var disconnected func()
+	require.Eventually(t, func() bool {
+		var err error
+		disconnected, err = s.SignerConnected(id, ethcommon.Address{})
+		return err == nil
+	}, time.Second*5, time.Millisecond*10)
+	defer disconnected()
+
+	outCh, err := s.OngoingCh(id)
+	require.NoError(t, err)
+	inCh, err := s.IncomingCh(id)
+	require.NoError(t, err)
+
+	<-outCh
+	inCh <- ethcommon.Hash{9}
+	require.NoError(t, <-errCh)
+}
+
+func TestExternalSender_SignerConnected_UnknownSwap(t *testing.T) {
+	s := newTestExternalSender(t)
+	id := types.Hash{3}
+
+	// signers can't open sessions for swaps that aren't ongoing
+	_, err := s.SignerConnected(id, ethcommon.Address{})
+	require.Equal(t, errNoSwapWithID, err)
+	require.Empty(t, s.swaps)
+
+	s.AddID(id)
+	disconnected, err := s.SignerConnected(id, ethcommon.Address{})
+	require.NoError(t, err)
+	require.True(t, s.hasSigner(s.swaps[id]))
+
+	// the swap exiting while its signer is connected ends the session
+	s.DeleteID(id)
+	disconnected()
+	_, err = s.SignerConnected(id, ethcommon.Address{})
+	require.Equal(t, errNoSwapWithID, err)
+}
+
+// revertedEthClient reports every transaction as reverted, and records the calls made to replay them.
+type revertedEthClient struct {
+	mockEthClient
+	calls []eth.CallMsg
+}
+
+func (c *revertedEthClient) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	return &ethtypes.Receipt{
+		Status:      ethtypes.ReceiptStatusFailed,
+		TxHash:      txHash,
+		BlockNumber: big.NewInt(1),
+	}, nil
+}
+
+func (c *revertedEthClient) CallContract(_ context.Context, msg eth.CallMsg, _ *big.Int) ([]byte, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.calls = append(c.calls, msg)
+	return nil, errors.New("execution reverted")
+}
+
+func TestExternalSender_NewSwap_RevertReplayedFromSigner(t *testing.T) {
+	ec := new(revertedEthClient)
+	s, err := NewExternalSender(context.Background(), common.Development, ec, ethcommon.Address{1}, 1)
+	require.NoError(t, err)
+
+	id := types.Hash{4}
+	signer := ethcommon.Address{5}
+	s.AddID(id)
+	disconnected, err := s.SignerConnected(id, signer)
+	require.NoError(t, err)
+	defer disconnected()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := s.NewSwap(id, [32]byte{}, [32]byte{}, ethcommon.Address{6}, big.NewInt(60),
+			big.NewInt(1), big.NewInt(100))
+		errCh <- err
+	}()
+
+	outCh, err := s.OngoingCh(id)
+	require.NoError(t, err)
+	inCh, err := s.IncomingCh(id)
+	require.NoError(t, err)
+
+	<-outCh
+	inCh <- ethcommon.Hash{9}
+	require.ErrorIs(t, <-errCh, errTxReverted)
+
+	// the call is replayed from the signer's address, as the swap's owner is the sender of new_swap
+	require.Len(t, ec.calls, 1)
+	require.Equal(t, signer, ec.calls[0].From)
+	require.Equal(t, big.NewInt(100), ec.calls[0].Value)
+}
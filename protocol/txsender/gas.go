@@ -0,0 +1,56 @@
+package txsender
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+var (
+	errGasPriceWithFeeCaps = errors.New("gas price can't be set along with the fee cap or priority fee")
+	errTipAboveFeeCap      = errors.New("priority fee can't be more than the fee cap")
+)
+
+// GasConfig is the gas settings of new transactions. Fields that aren't set are suggested by the
+// ethereum node, or estimated in the case of the gas limit.
+type GasConfig struct {
+	// GasPrice is the gas price of legacy transactions, in wei; if it's set, the fee caps must not be
+	GasPrice *big.Int `json:"gasPrice,omitempty"`
+	// GasFeeCap and GasTipCap are the fee cap (maxFeePerGas) and the priority fee
+	// (maxPriorityFeePerGas) of dynamic fee transactions, in wei
+	GasFeeCap *big.Int `json:"maxFeePerGas,omitempty"`
+	GasTipCap *big.Int `json:"maxPriorityFeePerGas,omitempty"`
+	// GasLimit is the gas limit of transactions
+	GasLimit uint64 `json:"gasLimit,omitempty"`
+}
+
+// Validate returns an error if transactions can't be sent with the config.
+func (c *GasConfig) Validate() error {
+	if c.GasPrice != nil && (c.GasFeeCap != nil || c.GasTipCap != nil) {
+		return errGasPriceWithFeeCaps
+	}
+
+	if c.GasFeeCap != nil && c.GasTipCap != nil && c.GasTipCap.Cmp(c.GasFeeCap) > 0 {
+		return errTipAboveFeeCap
+	}
+
+	return nil
+}
+
+// Apply sets the config's gas settings in the transaction options.
+func (c *GasConfig) Apply(opts *bind.TransactOpts) {
+	opts.GasPrice = copyBig(c.GasPrice)
+	opts.GasFeeCap = copyBig(c.GasFeeCap)
+	opts.GasTipCap = copyBig(c.GasTipCap)
+	opts.GasLimit = c.GasLimit
+}
+
+// copyBig copies the given integer, so that the copy can be changed, eg. to bump fees.
+func copyBig(x *big.Int) *big.Int {
+	if x == nil {
+		return nil
+	}
+
+	return new(big.Int).Set(x)
+}
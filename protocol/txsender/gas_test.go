@@ -0,0 +1,40 @@
+package txsender
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGasConfig_Validate(t *testing.T) {
+	require.NoError(t, new(GasConfig).Validate())
+	require.NoError(t, (&GasConfig{GasPrice: big.NewInt(10), GasLimit: 100000}).Validate())
+	require.NoError(t, (&GasConfig{GasFeeCap: big.NewInt(10), GasTipCap: big.NewInt(10)}).Validate())
+
+	cfg := &GasConfig{GasPrice: big.NewInt(10), GasTipCap: big.NewInt(1)}
+	require.Equal(t, errGasPriceWithFeeCaps, cfg.Validate())
+
+	cfg = &GasConfig{GasFeeCap: big.NewInt(10), GasTipCap: big.NewInt(11)}
+	require.Equal(t, errTipAboveFeeCap, cfg.Validate())
+}
+
+func TestGasConfig_Apply(t *testing.T) {
+	cfg := &GasConfig{
+		GasFeeCap: big.NewInt(100),
+		GasTipCap: big.NewInt(2),
+		GasLimit:  21000,
+	}
+
+	opts := &bind.TransactOpts{GasPrice: big.NewInt(50)}
+	cfg.Apply(opts)
+	require.Nil(t, opts.GasPrice)
+	require.Equal(t, big.NewInt(100), opts.GasFeeCap)
+	require.Equal(t, big.NewInt(2), opts.GasTipCap)
+	require.Equal(t, uint64(21000), opts.GasLimit)
+
+	// bumping the options' fees doesn't change the config
+	opts.GasFeeCap.SetInt64(200)
+	require.Equal(t, big.NewInt(100), cfg.GasFeeCap)
+}
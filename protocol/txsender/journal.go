@@ -0,0 +1,52 @@
+package txsender
+
+import (
+	"sync"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Journal records the transactions submitted for each swap.
+type Journal interface {
+	TxSubmitted(id types.Hash, txHash ethcommon.Hash) error
+	TxConfirmed(id types.Hash, txHash ethcommon.Hash) error
+}
+
+var (
+	journalMu sync.RWMutex
+	journal   Journal
+)
+
+// SetJournal sets the journal that every sender records swaps' transactions in.
+func SetJournal(j Journal) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	journal = j
+}
+
+// recordSubmitted records the transaction in the journal, if there is one, before it's submitted.
+func recordSubmitted(id types.Hash, txHash ethcommon.Hash) error {
+	journalMu.RLock()
+	defer journalMu.RUnlock()
+	if journal == nil {
+		return nil
+	}
+
+	return journal.TxSubmitted(id, txHash)
+}
+
+// recordConfirmed records that the transaction was confirmed in the journal, if there is one.
+// The transaction has already been acted on by then, so failures are only logged.
+func recordConfirmed(id types.Hash, txHash ethcommon.Hash) {
+	journalMu.RLock()
+	defer journalMu.RUnlock()
+	if journal == nil {
+		return
+	}
+
+	if err := journal.TxConfirmed(id, txHash); err != nil {
+		log.Warnf("failed to record confirmed transaction %s in journal: %s", txHash, err)
+	}
+}
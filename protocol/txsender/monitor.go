@@ -0,0 +1,190 @@
+package txsender
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// defaultStuckBlocks is the number of blocks after which a transaction that has
+	// not been included is considered stuck and is resubmitted with a higher fee
+	defaultStuckBlocks = 10
+
+	// replacement transactions must pay at least 10% more than the transaction they
+	// replace to be accepted by geth, so we bump by 25% to be safe
+	feeBumpPercent = 25
+)
+
+// ethClient is the subset of *ethclient.Client used by the tx monitor
+type ethClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error)
+	SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error
+}
+
+// txMonitor waits for submitted transactions to be included and confirmed. If a transaction
+// has no receipt after stuckBlocks blocks, it's re-signed with a bumped fee and the same nonce,
+// replacing the stuck transaction in the mempool.
+type txMonitor struct {
+	ec            ethClient
+	txOpts        *bind.TransactOpts
+	broadcaster   Broadcaster
+	confirmations uint64
+	stuckBlocks   uint64
+	maxRetries    int
+	sleep         time.Duration
+}
+
+func newTxMonitor(ec ethClient, txOpts *bind.TransactOpts, confirmations uint64) *txMonitor {
+	return &txMonitor{
+		ec:            ec,
+		txOpts:        txOpts,
+		broadcaster:   &directBroadcaster{ec: ec},
+		confirmations: confirmations,
+		stuckBlocks:   defaultStuckBlocks,
+		maxRetries:    maxRetries,
+		sleep:         receiptSleepDuration,
+	}
+}
+
+// withBroadcaster returns a copy of the monitor that resubmits stuck transactions with the
+// given broadcaster, so replacements take the same route as the original transaction.
+func (m *txMonitor) withBroadcaster(b Broadcaster) *txMonitor {
+	c := *m
+	c.broadcaster = b
+	return &c
+}
+
+// waitForReceipt waits for the given transaction, or any of its fee-bumped replacements,
+// to be included in the chain and confirmed. It returns the hash of the transaction that
+// was included along with its receipt.
+func (m *txMonitor) waitForReceipt(ctx context.Context,
+	tx *ethtypes.Transaction) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	// every transaction we've submitted with this nonce; any of them may end up being included
+	submitted := []ethcommon.Hash{tx.Hash()}
+	current := tx
+
+	startBlock, err := m.ec.BlockNumber(ctx)
+	if err != nil {
+		log.Warnf("failed to get block number: %s", err)
+	}
+
+	for i := 0; i < m.maxRetries; i++ {
+		head, err := m.ec.BlockNumber(ctx)
+		if err != nil {
+			log.Warnf("failed to get block number: %s", err)
+		}
+
+		included := false
+		for _, txHash := range submitted {
+			receipt, err := m.ec.TransactionReceipt(ctx, txHash)
+			if err != nil {
+				continue
+			}
+
+			confirmed, err := isConfirmed(ctx, m.ec, receipt, head, m.confirmations)
+			if err != nil {
+				log.Warnf("%s: txHash=%s", err, txHash)
+				continue
+			}
+
+			if confirmed {
+				return txHash, receipt, nil
+			}
+
+			included = true
+			break
+		}
+
+		switch {
+		case included, startBlock == 0, head == 0:
+			// the transaction is waiting for confirmations, don't replace it; if it gets
+			// reorged out, count the stuck blocks from now
+			if head != 0 {
+				startBlock = head
+			}
+		case head >= startBlock+m.stuckBlocks:
+			log.Infof("transaction stuck for %d blocks, resubmitting with bumped fee: txHash=%s nonce=%d",
+				head-startBlock,
+				current.Hash(),
+				current.Nonce(),
+			)
+
+			bumped, err := m.bumpFee(ctx, current)
+			if err != nil {
+				log.Warnf("failed to resubmit stuck transaction %s: %s", current.Hash(), err)
+			} else {
+				log.Infof("resubmitted transaction: txHash=%s", bumped.Hash())
+				current = bumped
+				submitted = append(submitted, bumped.Hash())
+			}
+
+			// wait another stuckBlocks blocks before bumping again
+			startBlock = head
+		}
+
+		select {
+		case <-ctx.Done():
+			return ethcommon.Hash{}, nil, ctx.Err()
+		case <-time.After(m.sleep):
+		}
+	}
+
+	return ethcommon.Hash{}, nil, errReceiptTimeOut
+}
+
+// bumpFee re-signs the given transaction with the same nonce and a higher fee and submits it.
+func (m *txMonitor) bumpFee(ctx context.Context, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+	var inner ethtypes.TxData
+	switch tx.Type() {
+	case ethtypes.LegacyTxType:
+		inner = &ethtypes.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: bumpFee(tx.GasPrice()),
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}
+	case ethtypes.DynamicFeeTxType:
+		inner = &ethtypes.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  bumpFee(tx.GasTipCap()),
+			GasFeeCap:  bumpFee(tx.GasFeeCap()),
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}
+	default:
+		return nil, errUnsupportedTxType
+	}
+
+	signed, err := m.txOpts.Signer(m.txOpts.From, ethtypes.NewTx(inner))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.broadcaster.Broadcast(ctx, signed); err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(100+feeBumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	// ensure we always increase the fee, even if it's tiny
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
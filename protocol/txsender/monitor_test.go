@@ -0,0 +1,115 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.New("not found")
+
+// mockEthClient only includes transactions submitted through SendTransaction, so the
+// original transaction is never included. Each call to BlockNumber advances the chain by one block.
+type mockEthClient struct {
+	sync.Mutex
+	height uint64
+	sent   []*ethtypes.Transaction
+}
+
+func (c *mockEthClient) BlockNumber(_ context.Context) (uint64, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.height++
+	return c.height, nil
+}
+
+func (c *mockEthClient) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	c.Lock()
+	defer c.Unlock()
+	for _, tx := range c.sent {
+		if tx.Hash() == txHash {
+			return &ethtypes.Receipt{TxHash: txHash}, nil
+		}
+	}
+	return nil, errNotFound
+}
+
+func (c *mockEthClient) SendTransaction(_ context.Context, tx *ethtypes.Transaction) error {
+	c.Lock()
+	defer c.Unlock()
+	c.sent = append(c.sent, tx)
+	return nil
+}
+
+func newTestTxOpts(t *testing.T) *bind.TransactOpts {
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	txOpts, err := bind.NewKeyedTransactorWithChainID(pk, big.NewInt(1337))
+	require.NoError(t, err)
+	return txOpts
+}
+
+func TestTxMonitor_BumpsStuckTransaction(t *testing.T) {
+	txOpts := newTestTxOpts(t)
+	to := ethcommon.Address{1}
+	tx, err := txOpts.Signer(txOpts.From, ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    7,
+		GasPrice: big.NewInt(1000),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(1),
+	}))
+	require.NoError(t, err)
+
+	ec := new(mockEthClient)
+	m := newTxMonitor(ec, txOpts, 1)
+	m.stuckBlocks = 2
+	m.sleep = time.Millisecond
+
+	txHash, receipt, err := m.waitForReceipt(context.Background(), tx)
+	require.NoError(t, err)
+	require.NotEqual(t, tx.Hash(), txHash)
+	require.Equal(t, txHash, receipt.TxHash)
+
+	require.Equal(t, 1, len(ec.sent))
+	bumped := ec.sent[0]
+	require.Equal(t, tx.Nonce(), bumped.Nonce())
+	require.Equal(t, tx.Value(), bumped.Value())
+	require.Equal(t, big.NewInt(1250), bumped.GasPrice())
+}
+
+func TestTxMonitor_DynamicFeeTx(t *testing.T) {
+	txOpts := newTestTxOpts(t)
+	to := ethcommon.Address{1}
+	tx, err := txOpts.Signer(txOpts.From, ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1337),
+		Nonce:     3,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(2000),
+		Gas:       21000,
+		To:        &to,
+	}))
+	require.NoError(t, err)
+
+	m := newTxMonitor(new(mockEthClient), txOpts, 1)
+	bumped, err := m.bumpFee(context.Background(), tx)
+	require.NoError(t, err)
+	require.Equal(t, tx.Nonce(), bumped.Nonce())
+	require.Equal(t, big.NewInt(125), bumped.GasTipCap())
+	require.Equal(t, big.NewInt(2500), bumped.GasFeeCap())
+}
+
+func TestBumpFee(t *testing.T) {
+	require.Equal(t, big.NewInt(1), bumpFee(big.NewInt(0)))
+	require.Equal(t, big.NewInt(2), bumpFee(big.NewInt(1)))
+	require.Equal(t, big.NewInt(125), bumpFee(big.NewInt(100)))
+}
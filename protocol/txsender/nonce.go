@@ -0,0 +1,77 @@
+package txsender
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+type pendingNonceGetter interface {
+	PendingNonceAt(ctx context.Context, account ethcommon.Address) (uint64, error)
+}
+
+// nonceTracker assigns nonces to the transactions sent from an account, so that transactions
+// sent concurrently for different swaps don't reuse a nonce. Transactions sent with a private
+// broadcaster aren't in the node's pending pool, so the node's pending nonce alone isn't enough.
+type nonceTracker struct {
+	ec   ethClient
+	from ethcommon.Address
+
+	mu   sync.Mutex
+	next *uint64
+}
+
+func newNonceTracker(ec ethClient, from ethcommon.Address) *nonceTracker {
+	return &nonceTracker{
+		ec:   ec,
+		from: from,
+	}
+}
+
+// nonce returns the nonce the next transaction should use. If it's nil, the nonce is left for
+// the contract binding to pick. It must be called with the lock held.
+func (t *nonceTracker) nonce(ctx context.Context) (*big.Int, error) {
+	getter, ok := t.ec.(pendingNonceGetter)
+	if !ok {
+		if t.next == nil {
+			return nil, nil
+		}
+		return new(big.Int).SetUint64(*t.next), nil
+	}
+
+	pending, err := getter.PendingNonceAt(ctx, t.from)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.next != nil && *t.next > pending {
+		pending = *t.next
+	}
+
+	return new(big.Int).SetUint64(pending), nil
+}
+
+// do builds and broadcasts a transaction with the given function, which is passed the nonce to
+// use. Calls are serialised, and a nonce is only handed out again if its transaction failed.
+func (t *nonceTracker) do(ctx context.Context,
+	fn func(nonce *big.Int) (*ethtypes.Transaction, error)) (*ethtypes.Transaction, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	nonce, err := t.nonce(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := fn(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	next := tx.Nonce() + 1
+	t.next = &next
+	return tx, nil
+}
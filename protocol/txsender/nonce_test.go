@@ -0,0 +1,127 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockNonceClient reports a fixed pending nonce, as if none of the sent transactions reached the node.
+type mockNonceClient struct {
+	mockEthClient
+	pending uint64
+}
+
+func (c *mockNonceClient) PendingNonceAt(_ context.Context, _ ethcommon.Address) (uint64, error) {
+	return c.pending, nil
+}
+
+func txWithNonce(nonce *big.Int) *ethtypes.Transaction {
+	return ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: nonce.Uint64()})
+}
+
+func TestNonceTracker_Concurrent(t *testing.T) {
+	tracker := newNonceTracker(&mockNonceClient{pending: 5}, ethcommon.Address{})
+
+	const num = 20
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces = make(map[uint64]struct{})
+	)
+
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tx, err := tracker.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+				return txWithNonce(nonce), nil
+			})
+			require.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			nonces[tx.Nonce()] = struct{}{}
+		}()
+	}
+
+	wg.Wait()
+	require.Len(t, nonces, num)
+	for n := uint64(5); n < 5+num; n++ {
+		require.Contains(t, nonces, n)
+	}
+}
+
+func TestNonceTracker_FailedTxReusesNonce(t *testing.T) {
+	ec := &mockNonceClient{pending: 3}
+	tracker := newNonceTracker(ec, ethcommon.Address{})
+
+	_, err := tracker.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		require.Equal(t, uint64(3), nonce.Uint64())
+		return nil, errors.New("failed to broadcast")
+	})
+	require.Error(t, err)
+
+	tx, err := tracker.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		return txWithNonce(nonce), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), tx.Nonce())
+
+	// once the node is ahead of us, its nonce is used
+	ec.pending = 10
+	tx, err = tracker.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		return txWithNonce(nonce), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), tx.Nonce())
+}
+
+func TestNonceTracker_NoPendingNonce(t *testing.T) {
+	tracker := newNonceTracker(&mockEthClient{}, ethcommon.Address{})
+
+	tx, err := tracker.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		require.Nil(t, nonce)
+		return txWithNonce(big.NewInt(7)), nil
+	})
+	require.NoError(t, err)
+
+	_, err = tracker.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		require.Equal(t, tx.Nonce()+1, nonce.Uint64())
+		return txWithNonce(nonce), nil
+	})
+	require.NoError(t, err)
+}
+
+func TestPrivateKeySender_TransferSharesNonces(t *testing.T) {
+	ec := &mockNonceClient{pending: 4}
+	s := NewSenderWithPrivateKey(context.Background(), ec, nil, newTestTxOpts(t), 0).(*privateKeySender)
+
+	// a contract call broadcast privately, which the node doesn't see
+	call, err := s.nonces.do(context.Background(), func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		return txWithNonce(nonce), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), call.Nonce())
+
+	tx, err := s.Transfer(ethcommon.Address{1}, big.NewInt(1), big.NewInt(1000))
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), tx.Nonce())
+	require.Equal(t, []*ethtypes.Transaction{tx}, ec.sent)
+
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(big.NewInt(1337)), tx)
+	require.NoError(t, err)
+	require.Equal(t, s.txOpts.From, sender)
+}
+
+func TestPrivateKeySender_TransferNoPendingNonce(t *testing.T) {
+	s := NewSenderWithPrivateKey(context.Background(), &mockEthClient{}, nil, newTestTxOpts(t), 0).(*privateKeySender)
+	_, err := s.Transfer(ethcommon.Address{1}, big.NewInt(1), big.NewInt(1000))
+	require.Equal(t, errNoPendingNonce, err)
+}
@@ -0,0 +1,75 @@
+package txsender
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// SendOption changes how a transaction is sent.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	feeBumps uint
+}
+
+// WithFeeBumps raises the transaction's gas price by feeBumpPercent the given number of times,
+// eg. when retrying a transaction that failed to be sent or included.
+func WithFeeBumps(n uint) SendOption {
+	return func(o *sendOptions) {
+		o.feeBumps = n
+	}
+}
+
+// gasPriceSuggester is implemented by ethereum clients that can suggest a gas price.
+type gasPriceSuggester interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// applySendOptions applies the send options to the transaction options.
+func (s *privateKeySender) applySendOptions(opts *bind.TransactOpts, sendOpts []SendOption) error {
+	var o sendOptions
+	for _, opt := range sendOpts {
+		opt(&o)
+	}
+
+	if o.feeBumps == 0 {
+		return nil
+	}
+
+	// dynamic fee transactions have their configured caps bumped, rather than a gas price
+	if opts.GasFeeCap != nil || opts.GasTipCap != nil {
+		for i := uint(0); i < o.feeBumps; i++ {
+			if opts.GasFeeCap != nil {
+				opts.GasFeeCap = bumpFee(opts.GasFeeCap)
+			}
+			if opts.GasTipCap != nil {
+				opts.GasTipCap = bumpFee(opts.GasTipCap)
+			}
+		}
+		return nil
+	}
+
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		suggester, ok := s.ec.(gasPriceSuggester)
+		if !ok {
+			log.Warnf("can't bump fee, as the ethereum client can't suggest a gas price")
+			return nil
+		}
+
+		var err error
+		gasPrice, err = suggester.SuggestGasPrice(s.ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := uint(0); i < o.feeBumps; i++ {
+		gasPrice = bumpFee(gasPrice)
+	}
+
+	opts.GasPrice = gasPrice
+	return nil
+}
@@ -0,0 +1,52 @@
+package txsender
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/stretchr/testify/require"
+)
+
+// suggestingEthClient is a mockEthClient that suggests a fixed gas price.
+type suggestingEthClient struct {
+	mockEthClient
+}
+
+func (c *suggestingEthClient) SuggestGasPrice(_ context.Context) (*big.Int, error) {
+	return big.NewInt(1000), nil
+}
+
+func TestApplySendOptions_FeeBumps(t *testing.T) {
+	s := &privateKeySender{
+		ctx: context.Background(),
+		ec:  new(suggestingEthClient),
+	}
+
+	// without fee bumps, the gas price is left to the contract binding
+	opts := new(bind.TransactOpts)
+	require.NoError(t, s.applySendOptions(opts, nil))
+	require.Nil(t, opts.GasPrice)
+
+	require.NoError(t, s.applySendOptions(opts, []SendOption{WithFeeBumps(2)}))
+	require.Equal(t, big.NewInt(1562), opts.GasPrice)
+
+	// a set gas price is bumped instead of the suggested one
+	opts = &bind.TransactOpts{GasPrice: big.NewInt(2000)}
+	require.NoError(t, s.applySendOptions(opts, []SendOption{WithFeeBumps(1)}))
+	require.Equal(t, big.NewInt(2500), opts.GasPrice)
+
+	// clients that can't suggest a gas price leave it unset
+	s.ec = new(mockEthClient)
+	opts = new(bind.TransactOpts)
+	require.NoError(t, s.applySendOptions(opts, []SendOption{WithFeeBumps(1)}))
+	require.Nil(t, opts.GasPrice)
+
+	// dynamic fee transactions have their caps bumped
+	opts = &bind.TransactOpts{GasFeeCap: big.NewInt(2000), GasTipCap: big.NewInt(100)}
+	require.NoError(t, s.applySendOptions(opts, []SendOption{WithFeeBumps(1)}))
+	require.Nil(t, opts.GasPrice)
+	require.Equal(t, big.NewInt(2500), opts.GasFeeCap)
+	require.Equal(t, big.NewInt(125), opts.GasTipCap)
+}
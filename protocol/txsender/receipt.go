@@ -0,0 +1,76 @@
+package txsender
+
+import (
+	"context"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// WaitForReceipt waits for the given transaction to be included in the chain and to have
+// at least the given number of confirmations (the block containing the transaction counts as one).
+// If the transaction is reorged out while waiting, it waits for it to be re-included.
+func WaitForReceipt(ctx context.Context, ec ethClient, txHash ethcommon.Hash,
+	confirmations uint64) (*ethtypes.Receipt, error) {
+	return waitForReceipt(ctx, ec, txHash, confirmations, maxRetries, receiptSleepDuration)
+}
+
+func waitForReceipt(ctx context.Context, ec ethClient, txHash ethcommon.Hash, confirmations uint64,
+	retries int, sleep time.Duration) (*ethtypes.Receipt, error) {
+	var included *ethtypes.Receipt
+
+	for i := 0; i < retries; i++ {
+		receipt, err := ec.TransactionReceipt(ctx, txHash)
+		if err != nil && included != nil {
+			log.Warnf("transaction %s was reorged out of block %s, waiting for it to be re-included",
+				txHash,
+				included.BlockHash,
+			)
+		}
+		included = receipt
+
+		if err == nil {
+			head, err := ec.BlockNumber(ctx)
+			if err == nil {
+				confirmed, err := isConfirmed(ctx, ec, receipt, head, confirmations)
+				if err != nil {
+					log.Warnf("%s: txHash=%s", err, txHash)
+				}
+
+				if confirmed {
+					return receipt, nil
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sleep):
+		}
+	}
+
+	return nil, errReceiptTimeOut
+}
+
+// isConfirmed returns whether the transaction with the given receipt has at least the given
+// number of confirmations at the given chain head. Once deep enough, the receipt is re-fetched
+// to make sure the transaction is still included in the same block; if not, errTxReorged is returned.
+func isConfirmed(ctx context.Context, ec ethClient, receipt *ethtypes.Receipt, head,
+	confirmations uint64) (bool, error) {
+	if confirmations <= 1 {
+		return true, nil
+	}
+
+	if receipt.BlockNumber == nil || head+1 < receipt.BlockNumber.Uint64()+confirmations {
+		return false, nil
+	}
+
+	latest, err := ec.TransactionReceipt(ctx, receipt.TxHash)
+	if err != nil || latest.BlockHash != receipt.BlockHash {
+		return false, errTxReorged
+	}
+
+	return true, nil
+}
@@ -0,0 +1,66 @@
+package txsender
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// reorgEthClient returns the transaction as included in block 1, then reorged out,
+// then re-included in block 3.
+type reorgEthClient struct {
+	mockEthClient
+	calls int
+}
+
+func (c *reorgEthClient) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	c.Lock()
+	defer c.Unlock()
+	c.calls++
+	switch c.calls {
+	case 2:
+		return nil, errNotFound
+	case 1:
+		return &ethtypes.Receipt{TxHash: txHash, BlockNumber: big.NewInt(1), BlockHash: ethcommon.Hash{1}}, nil
+	default:
+		return &ethtypes.Receipt{TxHash: txHash, BlockNumber: big.NewInt(3), BlockHash: ethcommon.Hash{3}}, nil
+	}
+}
+
+func TestWaitForReceipt_Confirmations(t *testing.T) {
+	txHash := ethcommon.Hash{9}
+	ec := new(reorgEthClient)
+
+	receipt, err := waitForReceipt(context.Background(), ec, txHash, 4, 100, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.Hash{3}, receipt.BlockHash)
+	require.GreaterOrEqual(t, ec.height, uint64(6))
+}
+
+func TestWaitForReceipt_Timeout(t *testing.T) {
+	ec := new(mockEthClient)
+	_, err := waitForReceipt(context.Background(), ec, ethcommon.Hash{9}, 1, 3, time.Millisecond)
+	require.Equal(t, errReceiptTimeOut, err)
+}
+
+func TestIsConfirmed(t *testing.T) {
+	ec := new(mockEthClient)
+	receipt := &ethtypes.Receipt{TxHash: ethcommon.Hash{9}, BlockNumber: big.NewInt(10)}
+
+	confirmed, err := isConfirmed(context.Background(), ec, receipt, 10, 1)
+	require.NoError(t, err)
+	require.True(t, confirmed)
+
+	confirmed, err = isConfirmed(context.Background(), ec, receipt, 11, 3)
+	require.NoError(t, err)
+	require.False(t, confirmed)
+
+	// deep enough, but the transaction is no longer in the chain
+	_, err = isConfirmed(context.Background(), ec, receipt, 12, 3)
+	require.Equal(t, errTxReorged, err)
+}
@@ -0,0 +1,105 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/noot/atomic-swap/swapfactory"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	eventClaimed  = "Claimed"
+	eventRefunded = "Refunded"
+
+	executionRevertedPrefix = "execution reverted"
+	unknownRevertReason     = "unknown reason"
+)
+
+var (
+	errTxReverted       = errors.New("transaction reverted")
+	errMissingSwapEvent = errors.New("transaction did not emit the expected event")
+)
+
+// contractCaller is implemented by ethereum clients that can execute calls, which is used to
+// replay reverted transactions to find out why they reverted.
+type contractCaller interface {
+	CallContract(ctx context.Context, msg eth.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// revertError returns an error containing the reason the transaction with the given receipt
+// reverted. The transaction is replayed as a call at its block to get the revert string; if the
+// client can't make calls, or the call doesn't revert, the reason is unknown.
+func revertError(ctx context.Context, ec ethClient, msg eth.CallMsg, receipt *ethtypes.Receipt) error {
+	reason := unknownRevertReason
+	if caller, ok := ec.(contractCaller); ok {
+		_, err := caller.CallContract(ctx, msg, receipt.BlockNumber)
+		if err != nil {
+			reason = decodeRevertReason(err)
+		}
+	}
+
+	return fmt.Errorf("%w: %s: txHash=%s", errTxReverted, reason, receipt.TxHash)
+}
+
+// isRevert returns true if the given error is from a call or gas estimation that reverted.
+func isRevert(err error) bool {
+	var dataErr rpc.DataError
+	return errors.As(err, &dataErr) || strings.Contains(err.Error(), executionRevertedPrefix)
+}
+
+// decodeRevertReason returns the Solidity revert string (eg. "too early to claim!") from the
+// error returned by a reverted call, falling back to the error message if there isn't one.
+func decodeRevertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			if reason, uerr := abi.UnpackRevert(ethcommon.FromHex(data)); uerr == nil {
+				return reason
+			}
+		}
+	}
+
+	msg := err.Error()
+	if i := strings.Index(msg, executionRevertedPrefix+": "); i != -1 {
+		return msg[i+len(executionRevertedPrefix)+2:]
+	}
+
+	return msg
+}
+
+// checkSwapEvent returns an error if the receipt doesn't contain the given event (Claimed or
+// Refunded) for the given swap.
+func checkSwapEvent(receipt *ethtypes.Receipt, event string, swap swapfactory.SwapFactorySwap) error {
+	swapABI, err := swapfactory.SwapFactoryMetaData.GetAbi()
+	if err != nil {
+		return err
+	}
+
+	id, err := swapfactory.SwapID(swap)
+	if err != nil {
+		return err
+	}
+
+	topic := swapABI.Events[event].ID
+	for _, l := range receipt.Logs {
+		if len(l.Topics) == 0 || l.Topics[0] != topic {
+			continue
+		}
+
+		matches, err := swapfactory.CheckIfLogIDMatches(*l, event, id)
+		if err == nil && matches {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: no %s event for swap %x: txHash=%s", errMissingSwapEvent, event, id, receipt.TxHash)
+}
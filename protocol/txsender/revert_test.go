@@ -0,0 +1,143 @@
+package txsender
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	eth "github.com/ethereum/go-ethereum"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// revertDataError is a JSON-RPC error carrying ABI-encoded revert data, as returned by geth.
+type revertDataError struct {
+	data string
+}
+
+func (e *revertDataError) Error() string          { return "execution reverted" }
+func (e *revertDataError) ErrorData() interface{} { return e.data }
+
+// encodeRevert returns the ABI encoding of Error(reason).
+func encodeRevert(reason string) string {
+	selector := crypto.Keccak256([]byte("Error(string)"))[:4]
+	data := make([]byte, 0, 4+32*3)
+	data = append(data, selector...)
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(32).Bytes(), 32)...)
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(int64(len(reason))).Bytes(), 32)...)
+	data = append(data, ethcommon.RightPadBytes([]byte(reason), 32)...)
+	return hexutil.Encode(data)
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	err := &revertDataError{data: encodeRevert("too early to claim!")}
+	require.True(t, isRevert(err))
+	require.Equal(t, "too early to claim!", decodeRevertReason(err))
+
+	// clients that don't return revert data include the reason in the message
+	err2 := errors.New("VM Exception while processing transaction: execution reverted: too late to claim!")
+	require.True(t, isRevert(err2))
+	require.Equal(t, "too late to claim!", decodeRevertReason(err2))
+
+	require.False(t, isRevert(errors.New("connection refused")))
+}
+
+// revertingEthClient reports every transaction as reverted, and reverts every call with the given reason.
+type revertingEthClient struct {
+	mockEthClient
+	reason string
+	calls  []eth.CallMsg
+}
+
+func (c *revertingEthClient) TransactionReceipt(_ context.Context, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
+	return &ethtypes.Receipt{
+		Status:      ethtypes.ReceiptStatusFailed,
+		TxHash:      txHash,
+		BlockNumber: big.NewInt(1),
+	}, nil
+}
+
+func (c *revertingEthClient) CallContract(_ context.Context, msg eth.CallMsg, _ *big.Int) ([]byte, error) {
+	c.calls = append(c.calls, msg)
+	return nil, &revertDataError{data: encodeRevert(c.reason)}
+}
+
+func newTestSwap() swapfactory.SwapFactorySwap {
+	return swapfactory.SwapFactorySwap{
+		Owner:        ethcommon.Address{1},
+		Claimer:      ethcommon.Address{2},
+		PubKeyClaim:  [32]byte{3},
+		PubKeyRefund: [32]byte{4},
+		Timeout0:     big.NewInt(5),
+		Timeout1:     big.NewInt(6),
+		Value:        big.NewInt(7),
+		Nonce:        big.NewInt(8),
+	}
+}
+
+func TestExternalSender_Claim_RevertReason(t *testing.T) {
+	ec := &revertingEthClient{reason: "too early to claim!"}
+	s, err := NewExternalSender(context.Background(), common.Development, ec, ethcommon.Address{9}, 1)
+	require.NoError(t, err)
+
+	id := types.Hash{1}
+	s.AddID(id)
+	outCh, err := s.OngoingCh(id)
+	require.NoError(t, err)
+	inCh, err := s.IncomingCh(id)
+	require.NoError(t, err)
+
+	go func() {
+		<-outCh
+		inCh <- ethcommon.Hash{0xaa}
+	}()
+
+	swap := newTestSwap()
+	_, _, err = s.Claim(id, swap, [32]byte{})
+	require.ErrorIs(t, err, errTxReverted)
+	require.Contains(t, err.Error(), "too early to claim!")
+
+	// the call is replayed from the claimer, since the contract checks the sender
+	require.Len(t, ec.calls, 1)
+	require.Equal(t, swap.Claimer, ec.calls[0].From)
+	require.Equal(t, ethcommon.Address{9}, *ec.calls[0].To)
+}
+
+func newSwapEventLog(t *testing.T, event string, id [32]byte) *ethtypes.Log {
+	swapABI, err := swapfactory.SwapFactoryMetaData.GetAbi()
+	require.NoError(t, err)
+
+	data, err := swapABI.Events[event].Inputs.Pack(id, [32]byte{1})
+	require.NoError(t, err)
+
+	return &ethtypes.Log{
+		Topics: []ethcommon.Hash{swapABI.Events[event].ID},
+		Data:   data,
+	}
+}
+
+func TestCheckSwapEvent(t *testing.T) {
+	swap := newTestSwap()
+	id, err := swapfactory.SwapID(swap)
+	require.NoError(t, err)
+
+	receipt := &ethtypes.Receipt{Logs: []*ethtypes.Log{newSwapEventLog(t, eventClaimed, id)}}
+	require.NoError(t, checkSwapEvent(receipt, eventClaimed, swap))
+
+	// a Claimed event doesn't satisfy a refund
+	err = checkSwapEvent(receipt, eventRefunded, swap)
+	require.ErrorIs(t, err, errMissingSwapEvent)
+
+	// nor does an event for a different swap
+	receipt = &ethtypes.Receipt{Logs: []*ethtypes.Log{newSwapEventLog(t, eventClaimed, [32]byte{1})}}
+	err = checkSwapEvent(receipt, eventClaimed, swap)
+	require.ErrorIs(t, err, errMissingSwapEvent)
+}
@@ -3,16 +3,20 @@ package txsender
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/swapfactory"
 
+	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+
+	logging "github.com/ipfs/go-log"
 )
 
 const (
@@ -21,122 +25,271 @@ const (
 )
 
 var (
-	errReceiptTimeOut = errors.New("failed to get receipt, timed out")
+	log = logging.Logger("txsender")
+
+	errReceiptTimeOut    = errors.New("failed to get receipt, timed out")
+	errUnsupportedTxType = errors.New("unsupported transaction type")
+	errTxReorged         = errors.New("transaction was reorged out of the chain")
+	errNoPendingNonce    = errors.New("ethereum client can't report the account's pending nonce")
 )
 
+// transferGas is the gas used by a plain ether transfer.
+const transferGas = 21000
+
 // Sender signs and submits transactions to the chain
 type Sender interface {
 	SetContract(*swapfactory.SwapFactory)
 	SetContractAddress(ethcommon.Address)
+	SetGasConfig(*GasConfig)
 	NewSwap(id types.Hash, _pubKeyClaim [32]byte, _pubKeyRefund [32]byte, _claimer ethcommon.Address,
 		_timeoutDuration *big.Int, _nonce *big.Int, amount *big.Int) (ethcommon.Hash, *ethtypes.Receipt, error)
 	SetReady(id types.Hash, _swap swapfactory.SwapFactorySwap) (ethcommon.Hash, *ethtypes.Receipt, error)
 	Claim(id types.Hash, _swap swapfactory.SwapFactorySwap,
-		_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error)
+		_s [32]byte, opts ...SendOption) (ethcommon.Hash, *ethtypes.Receipt, error)
 	Refund(id types.Hash, _swap swapfactory.SwapFactorySwap,
 		_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error)
 }
 
+// Transferer sends plain ether transfers from the sender's account. Their nonces are assigned along
+// with those of the sender's contract calls, so the two can be sent concurrently.
+type Transferer interface {
+	Transfer(to ethcommon.Address, amount, gasPrice *big.Int) (*ethtypes.Transaction, error)
+}
+
 type privateKeySender struct {
 	ctx      context.Context
-	ec       *ethclient.Client
+	ec       ethClient
 	contract *swapfactory.SwapFactory
 	txOpts   *bind.TransactOpts
+	monitor  *txMonitor
+	nonces   *nonceTracker
+
+	// gas settings of new transactions
+	gasMu sync.RWMutex
+	gas   GasConfig
+
+	// broadcasters is the broadcaster for each method; methods without one are sent by external
+	broadcasters map[Method]Broadcaster
+	external     *ExternalSender
 }
 
-// NewSenderWithPrivateKey returns a new *privateKeySender
-func NewSenderWithPrivateKey(ctx context.Context, ec *ethclient.Client, contract *swapfactory.SwapFactory,
-	txOpts *bind.TransactOpts) Sender {
+// NewSenderWithPrivateKey returns a new *privateKeySender.
+// Transactions are considered final once they have the given number of confirmations.
+func NewSenderWithPrivateKey(ctx context.Context, ec ethClient, contract *swapfactory.SwapFactory,
+	txOpts *bind.TransactOpts, confirmations uint64) Sender {
+	direct := &directBroadcaster{ec: ec}
+	broadcasters := make(map[Method]Broadcaster)
+	for _, m := range allMethods {
+		broadcasters[m] = direct
+	}
+
 	return &privateKeySender{
-		ctx:      ctx,
-		ec:       ec,
-		contract: contract,
-		txOpts:   txOpts,
+		ctx:          ctx,
+		ec:           ec,
+		contract:     contract,
+		txOpts:       txOpts,
+		monitor:      newTxMonitor(ec, txOpts, confirmations),
+		nonces:       newNonceTracker(ec, txOpts.From),
+		broadcasters: broadcasters,
 	}
 }
 
+// NewSenderWithBroadcastConfig returns a sender that signs transactions with the given key and
+// broadcasts each method's transactions with the strategy selected in cfg. Methods using the
+// external strategy are sent through the given external sender, which must then be non-nil.
+func NewSenderWithBroadcastConfig(ctx context.Context, ec ethClient, contract *swapfactory.SwapFactory,
+	txOpts *bind.TransactOpts, confirmations uint64, cfg *BroadcastConfig,
+	external *ExternalSender) (Sender, error) {
+	broadcasters := make(map[Method]Broadcaster)
+	for _, m := range allMethods {
+		strategy := cfg.Strategy(m)
+		b, err := newBroadcaster(ec, strategy)
+		if err != nil {
+			return nil, err
+		}
+
+		if b == nil && external == nil {
+			return nil, errNoExternalSender
+		}
+
+		if b != nil {
+			broadcasters[m] = b
+		}
+
+		log.Infof("broadcasting %s transactions with strategy %s", m, strategy)
+	}
+
+	return &privateKeySender{
+		ctx:          ctx,
+		ec:           ec,
+		contract:     contract,
+		txOpts:       txOpts,
+		monitor:      newTxMonitor(ec, txOpts, confirmations),
+		nonces:       newNonceTracker(ec, txOpts.From),
+		broadcasters: broadcasters,
+		external:     external,
+	}, nil
+}
+
+// ExternalSender returns the external sender used by methods with the external strategy, if any.
+func (s *privateKeySender) ExternalSender() *ExternalSender {
+	return s.external
+}
+
 func (s *privateKeySender) SetContract(contract *swapfactory.SwapFactory) {
 	s.contract = contract
 }
 
-func (s *privateKeySender) SetContractAddress(_ ethcommon.Address) {}
+func (s *privateKeySender) SetContractAddress(addr ethcommon.Address) {
+	if s.external != nil {
+		s.external.SetContractAddress(addr)
+	}
+}
 
-func (s *privateKeySender) NewSwap(_ types.Hash, _pubKeyClaim [32]byte, _pubKeyRefund [32]byte,
-	_claimer ethcommon.Address, _timeoutDuration *big.Int, _nonce *big.Int,
-	value *big.Int) (ethcommon.Hash, *ethtypes.Receipt, error) {
-	s.txOpts.Value = value
-	defer func() {
-		s.txOpts.Value = nil
-	}()
+// SetGasConfig sets the gas settings of the transactions sent from now on.
+func (s *privateKeySender) SetGasConfig(cfg *GasConfig) {
+	s.gasMu.Lock()
+	defer s.gasMu.Unlock()
+	s.gas = *cfg
+}
 
-	tx, err := s.contract.NewSwap(s.txOpts, _pubKeyClaim, _pubKeyRefund, _claimer, _timeoutDuration, _nonce)
-	if err != nil {
-		return ethcommon.Hash{}, nil, err
-	}
+func (s *privateKeySender) applyGasConfig(opts *bind.TransactOpts) {
+	s.gasMu.RLock()
+	defer s.gasMu.RUnlock()
+	s.gas.Apply(opts)
+}
 
-	receipt, err := waitForReceipt(s.ctx, s.ec, tx.Hash())
-	if err != nil {
+func (s *privateKeySender) isExternal(m Method) bool {
+	_, has := s.broadcasters[m]
+	return !has
+}
+
+// send builds and signs a transaction with the given contract call and value, broadcasts it with
+// the method's strategy and waits for it to be confirmed. It's safe to call concurrently; only
+// building and broadcasting are serialised, so that each transaction gets its own nonce.
+func (s *privateKeySender) send(m Method, id types.Hash, value *big.Int,
+	call func(*bind.TransactOpts) (*ethtypes.Transaction, error),
+	sendOpts ...SendOption) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	opts := *s.txOpts
+	opts.NoSend = true
+	opts.Value = value
+	s.applyGasConfig(&opts)
+
+	if err := s.applySendOptions(&opts, sendOpts); err != nil {
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return tx.Hash(), receipt, nil
-}
+	b := s.broadcasters[m]
+	tx, err := s.nonces.do(s.ctx, func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		opts.Nonce = nonce
+		tx, err := call(&opts)
+		if err != nil {
+			if isRevert(err) {
+				return nil, fmt.Errorf("%w: %s", errTxReverted, decodeRevertReason(err))
+			}
+			return nil, err
+		}
 
-func (s *privateKeySender) SetReady(_ types.Hash,
-	_swap swapfactory.SwapFactorySwap) (ethcommon.Hash, *ethtypes.Receipt, error) {
-	tx, err := s.contract.SetReady(s.txOpts, _swap)
+		if err = recordSubmitted(id, tx.Hash()); err != nil {
+			return nil, err
+		}
+
+		return tx, b.Broadcast(s.ctx, tx)
+	})
 	if err != nil {
 		return ethcommon.Hash{}, nil, err
 	}
 
-	receipt, err := waitForReceipt(s.ctx, s.ec, tx.Hash())
+	txHash, receipt, err := s.monitor.withBroadcaster(b).waitForReceipt(s.ctx, tx)
 	if err != nil {
 		return ethcommon.Hash{}, nil, err
 	}
 
-	return tx.Hash(), receipt, nil
+	recordConfirmed(id, txHash)
+
+	if receipt.Status == ethtypes.ReceiptStatusFailed {
+		msg := eth.CallMsg{
+			From:  opts.From,
+			To:    tx.To(),
+			Gas:   tx.Gas(),
+			Value: tx.Value(),
+			Data:  tx.Data(),
+		}
+		return txHash, receipt, revertError(s.ctx, s.ec, msg, receipt)
+	}
+
+	return txHash, receipt, nil
 }
 
-func (s *privateKeySender) Claim(_ types.Hash, _swap swapfactory.SwapFactorySwap,
-	_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error) {
-	tx, err := s.contract.Claim(s.txOpts, _swap, _s)
-	if err != nil {
-		return ethcommon.Hash{}, nil, err
+func (s *privateKeySender) NewSwap(id types.Hash, _pubKeyClaim [32]byte, _pubKeyRefund [32]byte,
+	_claimer ethcommon.Address, _timeoutDuration *big.Int, _nonce *big.Int,
+	value *big.Int) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	if s.isExternal(MethodNewSwap) {
+		return s.external.NewSwap(id, _pubKeyClaim, _pubKeyRefund, _claimer, _timeoutDuration, _nonce, value)
 	}
 
-	receipt, err := waitForReceipt(s.ctx, s.ec, tx.Hash())
-	if err != nil {
-		return ethcommon.Hash{}, nil, err
+	return s.send(MethodNewSwap, id, value, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return s.contract.NewSwap(opts, _pubKeyClaim, _pubKeyRefund, _claimer, _timeoutDuration, _nonce)
+	})
+}
+
+func (s *privateKeySender) SetReady(id types.Hash,
+	_swap swapfactory.SwapFactorySwap) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	if s.isExternal(MethodSetReady) {
+		return s.external.SetReady(id, _swap)
 	}
 
-	return tx.Hash(), receipt, nil
+	return s.send(MethodSetReady, id, nil, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return s.contract.SetReady(opts, _swap)
+	})
 }
 
-func (s *privateKeySender) Refund(_ types.Hash, _swap swapfactory.SwapFactorySwap,
-	_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error) {
-	tx, err := s.contract.Refund(s.txOpts, _swap, _s)
-	if err != nil {
-		return ethcommon.Hash{}, nil, err
+func (s *privateKeySender) Claim(id types.Hash, _swap swapfactory.SwapFactorySwap,
+	_s [32]byte, sendOpts ...SendOption) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	if s.isExternal(MethodClaim) {
+		return s.external.Claim(id, _swap, _s, sendOpts...)
 	}
 
-	receipt, err := waitForReceipt(s.ctx, s.ec, tx.Hash())
+	txHash, receipt, err := s.send(MethodClaim, id, nil, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return s.contract.Claim(opts, _swap, _s)
+	}, sendOpts...)
 	if err != nil {
-		return ethcommon.Hash{}, nil, err
+		return txHash, receipt, err
 	}
 
-	return tx.Hash(), receipt, nil
+	return txHash, receipt, checkSwapEvent(receipt, eventClaimed, _swap)
 }
 
-func waitForReceipt(ctx context.Context, ec *ethclient.Client, txHash ethcommon.Hash) (*ethtypes.Receipt, error) {
-	for i := 0; i < maxRetries; i++ {
-		receipt, err := ec.TransactionReceipt(ctx, txHash)
+// Transfer signs and broadcasts a transfer of the given amount of ether, without waiting for it to
+// be included.
+func (s *privateKeySender) Transfer(to ethcommon.Address, amount, gasPrice *big.Int) (*ethtypes.Transaction, error) {
+	return s.nonces.do(s.ctx, func(nonce *big.Int) (*ethtypes.Transaction, error) {
+		if nonce == nil {
+			return nil, errNoPendingNonce
+		}
+
+		tx, err := s.txOpts.Signer(s.txOpts.From,
+			ethtypes.NewTransaction(nonce.Uint64(), to, amount, transferGas, gasPrice, nil))
 		if err != nil {
-			time.Sleep(receiptSleepDuration)
-			continue
+			return nil, err
 		}
 
-		return receipt, nil
+		return tx, s.ec.SendTransaction(s.ctx, tx)
+	})
+}
+
+func (s *privateKeySender) Refund(id types.Hash, _swap swapfactory.SwapFactorySwap,
+	_s [32]byte) (ethcommon.Hash, *ethtypes.Receipt, error) {
+	if s.isExternal(MethodRefund) {
+		return s.external.Refund(id, _swap, _s)
+	}
+
+	txHash, receipt, err := s.send(MethodRefund, id, nil, func(opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+		return s.contract.Refund(opts, _swap, _s)
+	})
+	if err != nil {
+		return txHash, receipt, err
 	}
 
-	return nil, errReceiptTimeOut
+	return txHash, receipt, checkSwapEvent(receipt, eventRefunded, _swap)
 }
@@ -0,0 +1,197 @@
+// Package utilization reports how a daemon's capital is split between ongoing swaps, offers
+// and its hot wallets, and keeps a history of those reports so market makers can size their float.
+package utilization
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("utilization")
+
+const (
+	// DefaultInterval is the default time between samples
+	DefaultInterval = time.Minute * 5
+	// DefaultHistorySize is the default number of samples kept, one day at the default interval
+	DefaultHistorySize = 288
+)
+
+// Backend is the subset of protocol/backend.Backend used to read balances and swaps.
+type Backend interface {
+	BalanceAt(ctx context.Context, account ethcommon.Address, blockNumber *big.Int) (*big.Int, error)
+	EthAddress() ethcommon.Address
+	GetBalance(idx uint) (*monero.GetBalanceResponse, error)
+	LockClient()
+	UnlockClient()
+	SwapManager() swap.Manager
+}
+
+// OfferGetter returns the offers currently made by the daemon.
+type OfferGetter interface {
+	GetOffers() []*types.Offer
+}
+
+// Snapshot is the daemon's capital at a point in time. Amounts are in ETH and XMR.
+type Snapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+
+	// LockedETH and LockedXMR are provided by us in ongoing swaps, whether or not they've
+	// been locked on-chain yet.
+	LockedETH    float64 `json:"lockedETH"`
+	LockedXMR    float64 `json:"lockedXMR"`
+	OngoingSwaps int     `json:"ongoingSwaps"`
+
+	// IdleETH and IdleXMR are the balances of the daemon's hot wallets.
+	IdleETH float64 `json:"idleETH"`
+	IdleXMR float64 `json:"idleXMR"`
+
+	// ReservedXMR is the sum of the maximum amounts of our current offers. It's part of IdleXMR
+	// until an offer is taken.
+	ReservedXMR float64 `json:"reservedXMR"`
+	Offers      int     `json:"offers"`
+
+	// ETHUtilization and XMRUtilization are the fractions of each coin locked in swaps.
+	ETHUtilization float64 `json:"ethUtilization"`
+	XMRUtilization float64 `json:"xmrUtilization"`
+}
+
+// Config is the config for a Tracker.
+type Config struct {
+	Backend Backend
+	// Offers is optional; if it's nil, no capital is reported as reserved by offers
+	Offers      OfferGetter
+	Interval    time.Duration
+	HistorySize int
+}
+
+// Tracker periodically samples the daemon's capital utilization.
+type Tracker struct {
+	backend     Backend
+	offers      OfferGetter
+	interval    time.Duration
+	historySize int
+
+	mu      sync.RWMutex
+	history []*Snapshot
+}
+
+// NewTracker returns a new *Tracker.
+func NewTracker(cfg *Config) *Tracker {
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+
+	historySize := cfg.HistorySize
+	if historySize == 0 {
+		historySize = DefaultHistorySize
+	}
+
+	return &Tracker{
+		backend:     cfg.Backend,
+		offers:      cfg.Offers,
+		interval:    interval,
+		historySize: historySize,
+	}
+}
+
+// Start samples the daemon's capital every interval until the context is cancelled.
+func (t *Tracker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			t.sample(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (t *Tracker) sample(ctx context.Context) {
+	s, err := t.Snapshot(ctx)
+	if err != nil {
+		log.Warnf("failed to sample capital utilization: %s", err)
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.history = append(t.history, s)
+	if len(t.history) > t.historySize {
+		t.history = t.history[len(t.history)-t.historySize:]
+	}
+}
+
+// History returns the recorded snapshots, oldest first.
+func (t *Tracker) History() []*Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]*Snapshot{}, t.history...)
+}
+
+// Snapshot returns the daemon's current capital utilization.
+func (t *Tracker) Snapshot(ctx context.Context) (*Snapshot, error) {
+	s := &Snapshot{
+		Timestamp: time.Now(),
+	}
+
+	for _, info := range t.backend.SwapManager().GetOngoingSwaps() {
+		s.OngoingSwaps++
+		switch info.Provides() {
+		case types.ProvidesETH:
+			s.LockedETH += info.ProvidedAmount()
+		case types.ProvidesXMR:
+			s.LockedXMR += info.ProvidedAmount()
+		}
+	}
+
+	if t.offers != nil {
+		for _, o := range t.offers.GetOffers() {
+			s.Offers++
+			s.ReservedXMR += o.MaximumAmount
+		}
+	}
+
+	ethBalance, err := t.backend.BalanceAt(ctx, t.backend.EthAddress(), nil)
+	if err != nil {
+		return nil, err
+	}
+	s.IdleETH = common.EtherAmount(*ethBalance).AsEther()
+
+	t.backend.LockClient()
+	xmrBalance, err := t.backend.GetBalance(0)
+	t.backend.UnlockClient()
+	if err != nil {
+		return nil, err
+	}
+	s.IdleXMR = common.MoneroAmount(xmrBalance.Balance).AsMonero()
+
+	s.ETHUtilization = utilization(s.LockedETH, s.IdleETH)
+	s.XMRUtilization = utilization(s.LockedXMR, s.IdleXMR)
+	return s, nil
+}
+
+func utilization(locked, idle float64) float64 {
+	if locked+idle == 0 {
+		return 0
+	}
+
+	return locked / (locked + idle)
+}
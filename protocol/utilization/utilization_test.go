@@ -0,0 +1,129 @@
+package utilization
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBackend struct {
+	sm         swap.Manager
+	ethBalance *big.Int
+	xmrBalance float64
+	err        error
+}
+
+func (b *mockBackend) BalanceAt(_ context.Context, _ ethcommon.Address, _ *big.Int) (*big.Int, error) {
+	return b.ethBalance, b.err
+}
+func (b *mockBackend) EthAddress() ethcommon.Address {
+	return ethcommon.Address{}
+}
+func (b *mockBackend) GetBalance(_ uint) (*monero.GetBalanceResponse, error) {
+	return &monero.GetBalanceResponse{Balance: b.xmrBalance}, nil
+}
+func (b *mockBackend) LockClient()   {}
+func (b *mockBackend) UnlockClient() {}
+func (b *mockBackend) SwapManager() swap.Manager {
+	return b.sm
+}
+
+type mockOffers []*types.Offer
+
+func (o mockOffers) GetOffers() []*types.Offer {
+	return o
+}
+
+func newMockBackend(t *testing.T) *mockBackend {
+	sm := swap.NewManager()
+	swaps := []*swap.Info{
+		swap.NewInfo(types.Hash{1}, types.ProvidesXMR, 1, 0.05, 0.05, types.ExpectingKeys, nil),
+		swap.NewInfo(types.Hash{2}, types.ProvidesXMR, 2, 0.1, 0.05, types.XMRLocked, nil),
+		swap.NewInfo(types.Hash{3}, types.ProvidesETH, 0.5, 10, 0.05, types.ETHLocked, nil),
+		swap.NewInfo(types.Hash{4}, types.ProvidesXMR, 9, 0.45, 0.05, types.CompletedSuccess, nil),
+	}
+	for _, info := range swaps {
+		require.NoError(t, sm.AddSwap(info))
+	}
+
+	return &mockBackend{
+		sm:         sm,
+		ethBalance: common.EtherToWei(1.5).BigInt(),
+		xmrBalance: float64(common.MoneroToPiconero(9)),
+	}
+}
+
+func TestTracker_Snapshot(t *testing.T) {
+	tracker := NewTracker(&Config{
+		Backend: newMockBackend(t),
+		Offers: mockOffers{
+			{MaximumAmount: 2},
+			{MaximumAmount: 1.5},
+		},
+	})
+
+	s, err := tracker.Snapshot(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, s.OngoingSwaps)
+	require.Equal(t, float64(3), s.LockedXMR)
+	require.Equal(t, 0.5, s.LockedETH)
+	require.Equal(t, 1.5, s.IdleETH)
+	require.Equal(t, float64(9), s.IdleXMR)
+	require.Equal(t, 3.5, s.ReservedXMR)
+	require.Equal(t, 2, s.Offers)
+	require.Equal(t, 0.25, s.ETHUtilization)
+	require.Equal(t, 0.25, s.XMRUtilization)
+}
+
+func TestTracker_Snapshot_Empty(t *testing.T) {
+	tracker := NewTracker(&Config{
+		Backend: &mockBackend{
+			sm:         swap.NewManager(),
+			ethBalance: big.NewInt(0),
+		},
+	})
+
+	s, err := tracker.Snapshot(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, s.ETHUtilization)
+	require.Zero(t, s.XMRUtilization)
+}
+
+func TestTracker_History(t *testing.T) {
+	b := newMockBackend(t)
+	tracker := NewTracker(&Config{
+		Backend:     b,
+		Interval:    time.Millisecond,
+		HistorySize: 3,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracker.Start(ctx)
+
+	require.Eventually(t, func() bool {
+		return len(tracker.History()) == 3
+	}, time.Second, time.Millisecond)
+
+	// the history is capped at HistorySize, oldest first
+	time.Sleep(time.Millisecond * 10)
+	history := tracker.History()
+	require.Len(t, history, 3)
+	require.False(t, history[1].Timestamp.Before(history[0].Timestamp))
+
+	// failed samples aren't recorded
+	cancel()
+	tracker = NewTracker(&Config{Backend: &mockBackend{sm: swap.NewManager(), err: errors.New("dial")}})
+	tracker.sample(context.Background())
+	require.Empty(t, tracker.History())
+}
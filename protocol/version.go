@@ -0,0 +1,41 @@
+package protocol
+
+import (
+	"math/big"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/swapfactory"
+)
+
+// NewVersionInfo returns the version info of this daemon, configured for the given environment
+// and ethereum chain.
+func NewVersionInfo(env common.Environment, chainID *big.Int) *types.VersionInfo {
+	moneroNetwork := env.String()
+	if env == common.Development {
+		moneroNetwork = "regtest"
+	}
+
+	var ethChainID int64
+	if chainID != nil {
+		ethChainID = chainID.Int64()
+	}
+
+	return &types.VersionInfo{
+		Version:          common.Version,
+		Commit:           common.GitCommit,
+		ProtocolFeatures: append([]string{}, common.ProtocolFeatures...),
+		ContractVersions: []string{swapfactory.CodeHash().Hex()},
+		Chains: []*types.Chain{
+			{
+				Name:    "ethereum",
+				Network: env.String(),
+				ChainID: ethChainID,
+			},
+			{
+				Name:    "monero",
+				Network: moneroNetwork,
+			},
+		},
+	}
+}
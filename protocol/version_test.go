@@ -0,0 +1,27 @@
+package protocol
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVersionInfo(t *testing.T) {
+	v := NewVersionInfo(common.Development, big.NewInt(common.GanacheChainID))
+	require.Equal(t, common.Version, v.Version)
+	require.Equal(t, []string{swapfactory.CodeHash().Hex()}, v.ContractVersions)
+	require.True(t, v.HasFeature(common.FeatureVersionHandshake))
+	require.False(t, v.HasFeature("unknown-feature"))
+
+	require.Len(t, v.Chains, 2)
+	require.Equal(t, int64(common.GanacheChainID), v.Chains[0].ChainID)
+	require.Equal(t, "regtest", v.Chains[1].Network)
+
+	// the features are a copy, so callers can't change what's advertised
+	v.ProtocolFeatures[0] = "changed"
+	require.Equal(t, common.FeatureSpeedTiers, common.ProtocolFeatures[0])
+}
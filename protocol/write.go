@@ -5,145 +5,350 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/net/message"
+	"github.com/noot/atomic-swap/protocol/backup"
 	"github.com/noot/atomic-swap/swapfactory"
+
+	logging "github.com/ipfs/go-log"
 )
 
+var (
+	log = logging.Logger("protocol")
+
+	// secondary backup that info files are also written to, if set
+	backupMu        sync.RWMutex
+	secondaryBackup *backup.Backup
+
+	// serialises updates to info files, which are read, modified and rewritten
+	fileMu sync.Mutex
+)
+
+// SetSecondaryBackup sets a secondary location that swap info files are synchronously written
+// to (encrypted) in addition to the local info file.
+func SetSecondaryBackup(b *backup.Backup) {
+	backupMu.Lock()
+	defer backupMu.Unlock()
+	secondaryBackup = b
+}
+
+// ReadInfoFromDatabase returns the contents of the given info file stored in the database. The
+// Write functions store a copy of an info file's contents in the database they're given, so that
+// the daemon can find a swap's recovery info by the swap's ID.
+func ReadInfoFromDatabase(d db.Database, infofile string) (*InfoFileContents, error) {
+	bz, err := d.Get(db.InfoBucket, []byte(filepath.Clean(infofile)))
+	if err != nil {
+		return nil, err
+	}
+
+	var contents *InfoFileContents
+	if err = json.Unmarshal(bz, &contents); err != nil {
+		return nil, err
+	}
+
+	return contents, nil
+}
+
+// FindInfoInDatabase returns the info file contents stored in the database for the swap with the
+// given ID, or db.ErrNotFound if there aren't any.
+func FindInfoInDatabase(d db.Database, id types.Hash) (*InfoFileContents, error) {
+	var found *InfoFileContents
+	err := d.Iterate(db.InfoBucket, func(_, value []byte) error {
+		var contents *InfoFileContents
+		if err := json.Unmarshal(value, &contents); err != nil {
+			return err
+		}
+
+		if contents.SwapID == id {
+			found = contents
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if found == nil {
+		return nil, db.ErrNotFound
+	}
+
+	return found, nil
+}
+
 // InfoFileContents represents the contents of the swap info file used in case
 // of recovery.
 type InfoFileContents struct {
 	ContractAddress      string
 	ContractSwapID       [32]byte
 	ContractSwap         swapfactory.SwapFactorySwap
+	ContractSwapBlock    uint64  // block the swap was created in, zero if unknown
+	EthereumKeyIndex     *uint32 // HD wallet account index of the swap's ethereum address, if any
 	PrivateKeyInfo       *mcrypto.PrivateKeyInfo
 	SharedSwapPrivateKey *mcrypto.PrivateKeyInfo
+	SwapID               types.Hash        // zero if unknown
+	CounterpartyKeys     *CounterpartyKeys // nil until the counterparty's keys are received
+}
+
+// CounterpartyKeys are the keys and DLEq proof sent by the counterparty in its SendKeysMessage,
+// as hex-encoded strings.
+type CounterpartyKeys struct {
+	PublicSpendKey     string
+	PublicViewKey      string
+	PrivateViewKey     string
+	Secp256k1PublicKey string
+	DLEqProof          string
+	EthAddress         string
 }
 
 // WriteContractAddressToFile writes the contract address to the given file
-func WriteContractAddressToFile(infofile, addr string) error {
-	file, contents, err := setupFile(infofile)
+func WriteContractAddressToFile(d db.Database, infofile, addr string) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.ContractAddress = addr
+	})
 	if err != nil {
 		return err
 	}
 
-	contents.ContractAddress = addr
+	writeBackupOrWarn(infofile, bz)
+	return nil
+}
 
-	bz, err := json.MarshalIndent(contents, "", "\t")
+// WriteContractSwapToFile writes the given Swap contract struct to the given file
+func WriteContractSwapToFile(d db.Database, infofile string, swapID [32]byte,
+	swap swapfactory.SwapFactorySwap) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.ContractSwapID = swapID
+		c.ContractSwap = swap
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = file.Write(bz)
-	return err
+	writeBackupOrWarn(infofile, bz)
+	return nil
 }
 
-// WriteContractSwapToFile writes the given Swap contract struct to the given file
-func WriteContractSwapToFile(infofile string, swapID [32]byte, swap swapfactory.SwapFactorySwap) error {
-	file, contents, err := setupFile(infofile)
+// WriteContractSwapBlockToFile writes the number of the block the swap was created in to the given file
+func WriteContractSwapBlockToFile(d db.Database, infofile string, block uint64) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.ContractSwapBlock = block
+	})
 	if err != nil {
 		return err
 	}
 
-	contents.ContractSwapID = swapID
-	contents.ContractSwap = swap
+	writeBackupOrWarn(infofile, bz)
+	return nil
+}
 
-	bz, err := json.MarshalIndent(contents, "", "\t")
+// WriteEthereumKeyIndexToFile writes the HD wallet account index of the swap's address to the given file
+func WriteEthereumKeyIndexToFile(d db.Database, infofile string, index uint32) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.EthereumKeyIndex = &index
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = file.Write(bz)
-	return err
+	writeBackupOrWarn(infofile, bz)
+	return nil
 }
 
 // WriteKeysToFile writes the given private key pair to the given file
-func WriteKeysToFile(infofile string, keys *mcrypto.PrivateKeyPair, env common.Environment) error {
-	file, contents, err := setupFile(infofile)
+func WriteKeysToFile(d db.Database, infofile string, keys *mcrypto.PrivateKeyPair,
+	env common.Environment) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.PrivateKeyInfo = keys.Info(env)
+	})
 	if err != nil {
 		return err
 	}
 
-	contents.PrivateKeyInfo = keys.Info(env)
+	// the swap keys are written before any funds are locked, so if the backup fails,
+	// it's safe (and preferable) to abort the swap
+	return writeBackup(infofile, bz)
+}
 
-	bz, err := json.MarshalIndent(contents, "", "\t")
+// WriteSharedSwapKeyPairToFile writes the given private key pair to the given file
+func WriteSharedSwapKeyPairToFile(d db.Database, infofile string, keys *mcrypto.PrivateKeyPair,
+	env common.Environment) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.SharedSwapPrivateKey = keys.Info(env)
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = file.Write(bz)
-	return err
+	writeBackupOrWarn(infofile, bz)
+	return nil
 }
 
-// WriteSharedSwapKeyPairToFile writes the given private key pair to the given file
-func WriteSharedSwapKeyPairToFile(infofile string, keys *mcrypto.PrivateKeyPair, env common.Environment) error {
-	file, contents, err := setupFile(infofile)
+// WriteSwapIDToFile writes the swap's ID to the given file
+func WriteSwapIDToFile(d db.Database, infofile string, id types.Hash) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.SwapID = id
+	})
 	if err != nil {
 		return err
 	}
 
-	contents.SharedSwapPrivateKey = keys.Info(env)
+	writeBackupOrWarn(infofile, bz)
+	return nil
+}
 
-	bz, err := json.MarshalIndent(contents, "", "\t")
+// WriteCounterpartyKeysToFile writes the keys and DLEq proof in the counterparty's
+// SendKeysMessage to the given file
+func WriteCounterpartyKeysToFile(d db.Database, infofile string, msg *message.SendKeysMessage) error {
+	bz, err := updateFile(d, infofile, func(c *InfoFileContents) {
+		c.CounterpartyKeys = &CounterpartyKeys{
+			PublicSpendKey:     msg.PublicSpendKey,
+			PublicViewKey:      msg.PublicViewKey,
+			PrivateViewKey:     msg.PrivateViewKey,
+			Secp256k1PublicKey: msg.Secp256k1PublicKey,
+			DLEqProof:          msg.DLEqProof,
+			EthAddress:         msg.EthAddress,
+		}
+	})
 	if err != nil {
 		return err
 	}
 
-	_, err = file.Write(bz)
-	return err
+	writeBackupOrWarn(infofile, bz)
+	return nil
 }
 
-func setupFile(infofile string) (*os.File, *InfoFileContents, error) {
+// updateFile applies the given update to the contents of the info file, and writes them back
+// atomically, so that a crash mid-write leaves either the old or the new contents. If a database
+// is given, the new contents are stored in it too. It returns the new contents.
+func updateFile(d db.Database, infofile string, update func(*InfoFileContents)) ([]byte, error) {
+	fileMu.Lock()
+	defer fileMu.Unlock()
+
+	contents, err := readInfoFile(infofile)
+	if err != nil {
+		return nil, err
+	}
+
+	update(contents)
+
+	bz, err := json.MarshalIndent(contents, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = writeFileAtomic(infofile, bz); err != nil {
+		return nil, err
+	}
+
+	if d != nil {
+		if err = d.Put(db.InfoBucket, []byte(filepath.Clean(infofile)), bz); err != nil {
+			return nil, err
+		}
+	}
+
+	return bz, nil
+}
+
+// writeBackup writes the info file contents to the secondary backup, if there is one.
+func writeBackup(infofile string, bz []byte) error {
+	backupMu.RLock()
+	defer backupMu.RUnlock()
+	if secondaryBackup == nil {
+		return nil
+	}
+
+	return secondaryBackup.Write(filepath.Base(infofile), bz)
+}
+
+// writeBackupOrWarn writes the info file contents to the secondary backup, if there is one.
+// It's used once funds may already be locked, where failing the swap would be worse than
+// having an out-of-date backup.
+func writeBackupOrWarn(infofile string, bz []byte) {
+	if err := writeBackup(infofile, bz); err != nil {
+		log.Warnf("failed to write info file to secondary backup: %s", err)
+	}
+}
+
+// readInfoFile returns the current contents of the info file, or empty contents if it doesn't
+// exist yet.
+func readInfoFile(infofile string) (*InfoFileContents, error) {
 	exists, err := exists(infofile)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	var (
-		file     *os.File
-		contents *InfoFileContents
-	)
+	contents := &InfoFileContents{}
 	if !exists {
-		err = makeDir(filepath.Dir(infofile))
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to make directory %s: %w", filepath.Dir(infofile), err)
-		}
+		return contents, nil
+	}
 
-		file, err = os.Create(filepath.Clean(infofile))
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create file %s: %w", filepath.Clean(infofile), err)
-		}
-	} else {
-		file, err = os.OpenFile(filepath.Clean(infofile), os.O_RDWR, 0600)
-		if err != nil {
-			return nil, nil, err
-		}
+	bz, err := os.ReadFile(filepath.Clean(infofile))
+	if err != nil {
+		return nil, err
+	}
 
-		bz, err := os.ReadFile(filepath.Clean(infofile))
-		if err != nil {
-			return nil, nil, err
-		}
+	if err = json.Unmarshal(bz, contents); err != nil {
+		return nil, err
+	}
 
-		if err = json.Unmarshal(bz, &contents); err != nil {
-			return nil, nil, err
-		}
+	return contents, nil
+}
 
-		if err = file.Truncate(0); err != nil {
-			return nil, nil, err
-		}
+// writeFileAtomic replaces the file at the given path with the given contents. The contents are
+// written to a temporary file in the same directory, synced, and then renamed over the file.
+func writeFileAtomic(filename string, bz []byte) error {
+	filename = filepath.Clean(filename)
+	dir := filepath.Dir(filename)
+	if err := makeDir(dir); err != nil {
+		return fmt.Errorf("failed to make directory %s: %w", dir, err)
+	}
 
-		_, err = file.Seek(0, 0)
-		if err != nil {
-			return nil, nil, err
-		}
+	tmp, err := os.CreateTemp(dir, filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file in %s: %w", dir, err)
+	}
+	defer func() {
+		// no-op once the rename succeeded
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(bz); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), filename); err != nil {
+		return err
 	}
 
-	if contents == nil {
-		contents = &InfoFileContents{}
+	return syncDir(dir)
+}
+
+// syncDir syncs the given directory, so that a rename within it is durable.
+func syncDir(dir string) error {
+	d, err := os.Open(filepath.Clean(dir))
+	if err != nil {
+		return err
 	}
+	defer func() {
+		_ = d.Close()
+	}()
 
-	return file, contents, nil
+	return d.Sync()
 }
 
 func makeDir(dir string) error {
@@ -1,11 +1,17 @@
 package protocol
 
 import (
+	"encoding/json"
+	"os"
 	"path"
 	"testing"
 
 	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/net/message"
+	"github.com/noot/atomic-swap/protocol/backup"
 
 	"github.com/stretchr/testify/require"
 )
@@ -14,12 +20,110 @@ func TestWriteKeysToFile(t *testing.T) {
 	kp, err := mcrypto.GenerateKeys()
 	require.NoError(t, err)
 
-	err = WriteKeysToFile(path.Join(t.TempDir(), "test.keys"), kp, common.Development)
+	err = WriteKeysToFile(nil, path.Join(t.TempDir(), "test.keys"), kp, common.Development)
 	require.NoError(t, err)
 }
 
 func TestWriteContractAddrssToFile(t *testing.T) {
 	addr := "0xabcd"
-	err := WriteContractAddressToFile(path.Join(t.TempDir(), "test.keys"), addr)
+	err := WriteContractAddressToFile(nil, path.Join(t.TempDir(), "test.keys"), addr)
 	require.NoError(t, err)
 }
+
+func TestWriteKeysToFile_SecondaryBackup(t *testing.T) {
+	backupDir := t.TempDir()
+	b, err := backup.NewBackup(backup.NewDirTarget(backupDir), []byte("password"))
+	require.NoError(t, err)
+	SetSecondaryBackup(b)
+	defer SetSecondaryBackup(nil)
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	infofile := path.Join(t.TempDir(), "test.keys")
+	err = WriteKeysToFile(nil, infofile, kp, common.Development)
+	require.NoError(t, err)
+
+	local, err := os.ReadFile(infofile)
+	require.NoError(t, err)
+	enc, err := os.ReadFile(path.Join(backupDir, "test.keys.enc"))
+	require.NoError(t, err)
+	dec, err := backup.Decrypt([]byte("password"), enc)
+	require.NoError(t, err)
+	require.Equal(t, local, dec)
+}
+
+func TestWriteProtocolArtifactsToFile(t *testing.T) {
+	infofile := path.Join(t.TempDir(), "test.keys")
+	id := types.Hash{1, 2, 3}
+	msg := &message.SendKeysMessage{
+		PublicSpendKey:     "spend",
+		PrivateViewKey:     "view",
+		Secp256k1PublicKey: "secp256k1",
+		DLEqProof:          "proof",
+		EthAddress:         "0xabcd",
+	}
+
+	require.NoError(t, WriteSwapIDToFile(nil, infofile, id))
+	require.NoError(t, WriteContractAddressToFile(nil, infofile, "0x1234"))
+	require.NoError(t, WriteCounterpartyKeysToFile(nil, infofile, msg))
+
+	bz, err := os.ReadFile(infofile)
+	require.NoError(t, err)
+	var contents *InfoFileContents
+	require.NoError(t, json.Unmarshal(bz, &contents))
+
+	// each write keeps what was written before
+	require.Equal(t, id, contents.SwapID)
+	require.Equal(t, "0x1234", contents.ContractAddress)
+	require.Equal(t, &CounterpartyKeys{
+		PublicSpendKey:     "spend",
+		PrivateViewKey:     "view",
+		Secp256k1PublicKey: "secp256k1",
+		DLEqProof:          "proof",
+		EthAddress:         "0xabcd",
+	}, contents.CounterpartyKeys)
+}
+
+func TestWriteKeysToFile_Database(t *testing.T) {
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	infofile := path.Join(t.TempDir(), "test.keys")
+	require.NoError(t, WriteKeysToFile(d, infofile, kp, common.Development))
+	require.NoError(t, WriteContractAddressToFile(d, infofile, "0xabcd"))
+
+	// the contents are stored in the database as well as the file, which recovery tools and the
+	// RPC responses point to
+	contents, err := ReadInfoFromDatabase(d, infofile)
+	require.NoError(t, err)
+	require.Equal(t, kp.Info(common.Development), contents.PrivateKeyInfo)
+	require.Equal(t, "0xabcd", contents.ContractAddress)
+
+	bz, err := os.ReadFile(infofile)
+	require.NoError(t, err)
+	var fromFile *InfoFileContents
+	require.NoError(t, json.Unmarshal(bz, &fromFile))
+	require.Equal(t, contents, fromFile)
+}
+
+func TestWriteContractAddressToFile_Replaces(t *testing.T) {
+	dir := t.TempDir()
+	infofile := path.Join(dir, "test.keys")
+	require.NoError(t, WriteContractAddressToFile(nil, infofile, "0xabcdefabcdefabcdef"))
+	require.NoError(t, WriteContractAddressToFile(nil, infofile, "0x1"))
+
+	// the shorter contents replace the file whole, and no temporary files are left behind
+	bz, err := os.ReadFile(infofile)
+	require.NoError(t, err)
+	var contents *InfoFileContents
+	require.NoError(t, json.Unmarshal(bz, &contents))
+	require.Equal(t, "0x1", contents.ContractAddress)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
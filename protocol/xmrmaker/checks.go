@@ -3,74 +3,58 @@ package xmrmaker
 import (
 	"bytes"
 	"fmt"
+	"math/big"
+	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/crypto"
-
+	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/net/message"
+	"github.com/noot/atomic-swap/swapfactory"
 )
 
 // checkContractSwapID checks that the `Swap` type sent matches the swap ID when hashed
 func checkContractSwapID(msg *message.NotifyETHLocked) error {
-	uint256Ty, err := abi.NewType("uint256", "", nil)
+	hash, err := swapfactory.SwapID(swapfactory.SwapFactorySwap(*msg.ContractSwap))
 	if err != nil {
-		return fmt.Errorf("failed to create uint256 type: %w", err)
+		return fmt.Errorf("failed to pack arguments: %w", err)
 	}
 
-	bytes32Ty, err := abi.NewType("bytes32", "", nil)
-	if err != nil {
-		return fmt.Errorf("failed to create bytes32 type: %w", err)
+	if !bytes.Equal(hash[:], msg.ContractSwapID[:]) {
+		return errSwapIDMismatch
 	}
 
-	addressTy, err := abi.NewType("address", "", nil)
-	if err != nil {
-		return fmt.Errorf("failed to create address type: %w", err)
+	return nil
+}
+
+// checkTimeoutRange checks that the contract's timeout duration is within the range we accept.
+func checkTimeoutRange(swap *message.ContractSwap, minTimeout, maxTimeout time.Duration) error {
+	if swap.Timeout0 == nil || swap.Timeout1 == nil {
+		return errUnexpectedTimeout
 	}
 
-	arguments := abi.Arguments{
-		{
-			Type: addressTy,
-		},
-		{
-			Type: addressTy,
-		},
-		{
-			Type: bytes32Ty,
-		},
-		{
-			Type: bytes32Ty,
-		},
-		{
-			Type: uint256Ty,
-		},
-		{
-			Type: uint256Ty,
-		},
-		{
-			Type: uint256Ty,
-		},
-		{
-			Type: uint256Ty,
-		},
+	duration := new(big.Int).Sub(swap.Timeout1, swap.Timeout0)
+	if !duration.IsInt64() || duration.Int64() < int64(minTimeout/time.Second) ||
+		duration.Int64() > int64(maxTimeout/time.Second) {
+		return fmt.Errorf("%w: got %s seconds, expected %d to %d", errTimeoutOutOfRange, duration,
+			minTimeout/time.Second, maxTimeout/time.Second)
 	}
 
-	args, err := arguments.Pack(
-		msg.ContractSwap.Owner,
-		msg.ContractSwap.Claimer,
-		msg.ContractSwap.PubKeyClaim,
-		msg.ContractSwap.PubKeyRefund,
-		msg.ContractSwap.Timeout0,
-		msg.ContractSwap.Timeout1,
-		msg.ContractSwap.Value,
-		msg.ContractSwap.Nonce,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to pack arguments: %w", err)
+	return nil
+}
+
+// checkSpeedTierTimeout checks that the contract's timeout duration matches the negotiated speed tier.
+// The contract sets t0 = now + duration and t1 = now + 2 * duration.
+func checkSpeedTierTimeout(swap *message.ContractSwap, tier *types.SpeedTier) error {
+	if tier == nil {
+		return nil
 	}
 
-	hash := crypto.Keccak256Hash(args)
-	if !bytes.Equal(hash[:], msg.ContractSwapID[:]) {
-		return errSwapIDMismatch
+	if swap.Timeout0 == nil || swap.Timeout1 == nil {
+		return errUnexpectedTimeout
+	}
+
+	duration := new(big.Int).Sub(swap.Timeout1, swap.Timeout0)
+	if !duration.IsUint64() || duration.Uint64() != tier.Timeout {
+		return fmt.Errorf("%w: got %s seconds, expected %d", errUnexpectedTimeout, duration, tier.Timeout)
 	}
 
 	return nil
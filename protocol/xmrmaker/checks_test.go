@@ -0,0 +1,41 @@
+package xmrmaker
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net/message"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSpeedTierTimeout(t *testing.T) {
+	swap := &message.ContractSwap{
+		Timeout0: big.NewInt(1000 + 600),
+		Timeout1: big.NewInt(1000 + 1200),
+	}
+
+	require.NoError(t, checkSpeedTierTimeout(swap, nil))
+	require.NoError(t, checkSpeedTierTimeout(swap, &types.SpeedTier{Name: "fast", MoneroConfirmations: 5, Timeout: 600}))
+
+	err := checkSpeedTierTimeout(swap, &types.SpeedTier{Name: "cheap", MoneroConfirmations: 10, Timeout: 3600})
+	require.True(t, errors.Is(err, errUnexpectedTimeout))
+}
+
+func TestCheckTimeoutRange(t *testing.T) {
+	swap := &message.ContractSwap{
+		Timeout0: big.NewInt(1000 + 600),
+		Timeout1: big.NewInt(1000 + 1200),
+	}
+
+	require.NoError(t, checkTimeoutRange(swap, time.Minute, time.Hour))
+	require.NoError(t, checkTimeoutRange(swap, time.Minute*10, time.Minute*10))
+
+	err := checkTimeoutRange(swap, time.Hour, time.Hour*2)
+	require.True(t, errors.Is(err, errTimeoutOutOfRange))
+	err = checkTimeoutRange(swap, time.Second, time.Minute)
+	require.True(t, errors.Is(err, errTimeoutOutOfRange))
+}
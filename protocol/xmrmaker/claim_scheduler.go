@@ -0,0 +1,320 @@
+package xmrmaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net/message"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/txsender"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// claimSchedule configures when claims are attempted and how failed claims are retried.
+type claimSchedule struct {
+	// how often to check whether the contract was set to ready while waiting for t0
+	pollInterval time.Duration
+	// delay before retrying a failed claim, doubled after each failure up to maxBackoff
+	initialBackoff, maxBackoff time.Duration
+	// fraction of the claim window, between t0 and t1, that's left when failing claims are escalated
+	escalateFraction float64
+}
+
+var defaultClaimSchedule = claimSchedule{
+	pollInterval:     time.Second * 12,
+	initialBackoff:   time.Second * 5,
+	maxBackoff:       time.Minute * 5,
+	escalateFraction: 0.5,
+}
+
+// how often a request to claim now is retried while the claim scheduler is busy
+const claimRequestRetryInterval = time.Second
+
+// claimResult is the outcome of a claim attempt requested through the swap_claim endpoint.
+type claimResult struct {
+	txHash ethcommon.Hash
+	err    error
+}
+
+// claimScheduler claims a swap's ether once the contract is ready or t0 has passed, retrying
+// failed claims with backoff and bumped fees until t1. If claims keep failing as t1 approaches,
+// after which the counterparty can refund, it escalates once.
+type claimScheduler struct {
+	schedule claimSchedule
+	t0, t1   time.Time
+
+	// returns whether the counterparty has set the contract to ready
+	isReady func() bool
+	// closed when the counterparty notifies us that the contract is ready, so that it's claimed
+	// straight away rather than at the next poll; may be nil
+	ready <-chan struct{}
+	// claims the ether, with the transaction's fee bumped the given number of times
+	claim func(feeBumps uint) (ethcommon.Hash, error)
+	// called with the latest error when failing claims are escalated
+	escalate func(err error)
+	// requests to claim now, rather than at the next scheduled attempt; each is sent the result
+	requests <-chan chan<- claimResult
+}
+
+// run waits until the ether can be claimed and claims it, returning the claim transaction's hash.
+func (cs *claimScheduler) run(ctx context.Context) (ethcommon.Hash, error) {
+	// a request to claim now, answered once the claim is attempted
+	var req chan<- claimResult
+
+	for !cs.isReady() {
+		// claims are only accepted once the latest block's timestamp is past t0
+		untilT0 := time.Until(cs.t0.Add(time.Second))
+		if untilT0 <= 0 {
+			break
+		}
+
+		if req != nil {
+			req <- claimResult{err: checkClaimWindow(time.Now(), cs.t0, cs.t1, false)}
+			req = nil
+		}
+
+		wait := cs.schedule.pollInterval
+		if untilT0 < wait {
+			wait = untilT0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ethcommon.Hash{}, ctx.Err()
+		case <-time.After(wait):
+		case <-cs.ready:
+		case req = <-cs.requests:
+		}
+	}
+
+	backoff := cs.schedule.initialBackoff
+	escalated := false
+	window := cs.t1.Sub(cs.t0)
+
+	for attempt := uint(0); ; attempt++ {
+		if time.Now().After(cs.t1) {
+			// we've passed t1, our only option now is for XMRTaker to refund
+			// and we can regain control of the locked XMR.
+			if req != nil {
+				req <- claimResult{err: errPastClaimTime}
+			}
+			return ethcommon.Hash{}, errPastClaimTime
+		}
+
+		txHash, err := cs.claim(attempt)
+		if req != nil {
+			req <- claimResult{txHash: txHash, err: err}
+			req = nil
+		}
+
+		if err == nil {
+			return txHash, nil
+		}
+
+		if strings.Contains(err.Error(), revertSwapCompleted) || errors.Is(err, errNotWaitingToClaim) {
+			return ethcommon.Hash{}, err
+		}
+
+		untilT1 := time.Until(cs.t1)
+		log.Warnf("claim attempt %d failed, %s before t1: %s", attempt+1, untilT1.Round(time.Second), err)
+
+		if !escalated && float64(untilT1) < float64(window)*cs.schedule.escalateFraction {
+			escalated = true
+			cs.escalate(err)
+		}
+
+		wait := backoff
+		if untilT1 < wait {
+			wait = untilT1
+		}
+
+		select {
+		case <-ctx.Done():
+			return ethcommon.Hash{}, ctx.Err()
+		case <-time.After(wait):
+		case req = <-cs.requests:
+			log.Infof("attempting to claim now, as requested")
+		}
+
+		backoff *= 2
+		if backoff > cs.schedule.maxBackoff {
+			backoff = cs.schedule.maxBackoff
+		}
+	}
+}
+
+// tryClaim claims the swap's ether once the contract is ready or t0 has passed, retrying failed
+// claims until t1. It's used when nothing else can act on the swap meanwhile, as when exiting with
+// the swap's lock held, or recovering it.
+func (s *swapState) tryClaim() (ethcommon.Hash, error) {
+	return s.runClaimScheduler(func(feeBumps uint) (ethcommon.Hash, error) {
+		return s.claimFunds(txsender.WithFeeBumps(feeBumps))
+	})
+}
+
+func (s *swapState) runClaimScheduler(claim func(feeBumps uint) (ethcommon.Hash, error)) (ethcommon.Hash, error) {
+	cs := &claimScheduler{
+		schedule: defaultClaimSchedule,
+		t0:       s.t0,
+		t1:       s.t1,
+		isReady:  s.isContractReady,
+		ready:    s.readyCh,
+		claim:    claim,
+		escalate: s.escalateClaimFailure,
+		requests: s.claimRequests,
+	}
+
+	atomic.AddInt32(&s.claiming, 1)
+	defer atomic.AddInt32(&s.claiming, -1)
+	return cs.run(s.ctx)
+}
+
+// startClaiming starts claiming the swap's ether in the background, once the contract is ready or
+// t0 has passed, if it hasn't been started already. The swap's lock is only held for each claim
+// attempt, so that the swap can still handle messages and requests while it waits.
+func (s *swapState) startClaiming() {
+	if !atomic.CompareAndSwapInt32(&s.claimStarted, 0, 1) {
+		return
+	}
+
+	go func() {
+		txHash, err := s.runClaimScheduler(s.claimIfWaiting)
+		switch {
+		case err == nil:
+		case errors.Is(err, errNotWaitingToClaim), errors.Is(err, context.Canceled):
+			// the swap was claimed some other way, or ended
+			return
+		default:
+			log.Errorf("failed to claim: err=%s", err)
+			if err = s.Exit(); err != nil {
+				log.Errorf("exit failed: err=%s", err)
+			}
+			return
+		}
+
+		log.Infof("claimed ether! transaction hash=%s", txHash)
+		notifyClaimed := &message.NotifyClaimed{TxHash: txHash.String()}
+		if err := s.SendSwapMessage(notifyClaimed, s.ID()); err != nil {
+			log.Errorf("failed to send NotifyClaimed message: err=%s", err)
+		}
+	}()
+}
+
+// claimIfWaiting claims the swap's ether with the swap's lock held, if the swap is still waiting to
+// claim it.
+func (s *swapState) claimIfWaiting(feeBumps uint) (ethcommon.Hash, error) {
+	s.lockState()
+	defer s.unlockState()
+
+	if _, ok := s.nextExpectedMessage.(*message.NotifyReady); !ok || !s.info.Status().IsOngoing() {
+		return ethcommon.Hash{}, errNotWaitingToClaim
+	}
+
+	txHash, err := s.claimFunds(txsender.WithFeeBumps(feeBumps))
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	s.clearNextExpectedMessage(types.CompletedSuccess)
+	return txHash, nil
+}
+
+// checkClaimWindow returns an error if the swap contract doesn't allow us to claim at the given
+// time: we can claim once the swap is ready or t0 has passed, until t1.
+func checkClaimWindow(now, t0, t1 time.Time, ready bool) error {
+	if !now.Before(t1) {
+		return errPastClaimTime
+	}
+
+	if !ready && !now.After(t0) {
+		return fmt.Errorf("%w: it can be claimed from %s", errClaimNotAllowedYet, t0.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// claimNow claims the swap's ether immediately, if the swap contract allows it, then notifies the
+// counterparty. If the claim scheduler is running, the claim is attempted by it instead. Otherwise
+// it's claimed with the swap's lock held, and as the scheduler only claims with the lock held
+// once it's checked that the swap is still waiting to claim, the two can't both claim.
+func (s *swapState) claimNow() (ethcommon.Hash, error) {
+	result := make(chan claimResult, 1)
+	for atomic.LoadInt32(&s.claiming) > 0 {
+		select {
+		case <-s.ctx.Done():
+			return ethcommon.Hash{}, s.ctx.Err()
+		case s.claimRequests <- result:
+			select {
+			case <-s.ctx.Done():
+				return ethcommon.Hash{}, s.ctx.Err()
+			case res := <-result:
+				return res.txHash, res.err
+			}
+		case <-time.After(claimRequestRetryInterval):
+			// the scheduler may have stopped without taking the request
+		}
+	}
+
+	s.lockState()
+	defer s.unlockState()
+
+	if _, ok := s.nextExpectedMessage.(*message.NotifyReady); !ok || !s.info.Status().IsOngoing() {
+		return ethcommon.Hash{}, errNotWaitingToClaim
+	}
+
+	if err := checkClaimWindow(time.Now(), s.t0, s.t1, s.isContractReady()); err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	txHash, err := s.claimFunds()
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	log.Infof("claimed ether as requested! transaction hash=%s", txHash)
+	s.clearNextExpectedMessage(types.CompletedSuccess)
+
+	notifyClaimed := &message.NotifyClaimed{TxHash: txHash.String()}
+	if err := s.SendSwapMessage(notifyClaimed, s.ID()); err != nil {
+		log.Errorf("failed to send NotifyClaimed message: err=%s", err)
+	}
+
+	return txHash, nil
+}
+
+// isContractReady returns whether the counterparty has notified us that the contract is ready, or
+// has set it to ready without notifying us.
+func (s *swapState) isContractReady() bool {
+	select {
+	case <-s.readyCh:
+		return true
+	default:
+	}
+
+	stage, err := s.Contract().Swaps(s.CallOpts(), s.contractSwapID)
+	if err != nil {
+		log.Debugf("failed to get stage of swap %s: %s", s.ID(), err)
+		return false
+	}
+
+	return stage == swapfactory.StageReady
+}
+
+// escalateClaimFailure tells the operator that we keep failing to claim the swap's ether, which
+// can be refunded after t1.
+func (s *swapState) escalateClaimFailure(err error) {
+	s.info.SetStatus(types.ClaimFailing)
+	pcommon.SendAlert(&pcommon.Alert{
+		SwapID:   s.ID(),
+		Kind:     pcommon.AlertClaimFailing,
+		Message:  fmt.Sprintf("failed to claim ether; it can be refunded after t1: %s", err),
+		Deadline: s.t1,
+	})
+}
@@ -0,0 +1,263 @@
+package xmrmaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testClaimSchedule = claimSchedule{
+		pollInterval:     time.Millisecond * 10,
+		initialBackoff:   time.Millisecond * 10,
+		maxBackoff:       time.Millisecond * 40,
+		escalateFraction: 0.5,
+	}
+
+	errTestClaim = errors.New("claim failed")
+)
+
+func TestClaimScheduler_WaitsForReady(t *testing.T) {
+	ready := make(chan struct{})
+	var claimedAt time.Time
+	cs := &claimScheduler{
+		schedule: testClaimSchedule,
+		t0:       time.Now().Add(time.Hour),
+		t1:       time.Now().Add(time.Hour * 2),
+		isReady: func() bool {
+			select {
+			case <-ready:
+				return true
+			default:
+				return false
+			}
+		},
+		claim: func(feeBumps uint) (ethcommon.Hash, error) {
+			claimedAt = time.Now()
+			return ethcommon.Hash{1}, nil
+		},
+		escalate: func(error) {
+			t.Fatal("claim shouldn't have been escalated")
+		},
+	}
+
+	start := time.Now()
+	time.AfterFunc(time.Millisecond*50, func() {
+		close(ready)
+	})
+
+	txHash, err := cs.run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.Hash{1}, txHash)
+	require.True(t, claimedAt.Sub(start) >= time.Millisecond*50)
+}
+
+func TestClaimScheduler_RetriesWithFeeBumps(t *testing.T) {
+	var bumps []uint
+	cs := &claimScheduler{
+		schedule: testClaimSchedule,
+		t0:       time.Now().Add(-time.Hour),
+		t1:       time.Now().Add(time.Hour * 2),
+		isReady:  func() bool { return false },
+		claim: func(feeBumps uint) (ethcommon.Hash, error) {
+			bumps = append(bumps, feeBumps)
+			if len(bumps) < 3 {
+				return ethcommon.Hash{}, errTestClaim
+			}
+			return ethcommon.Hash{1}, nil
+		},
+		escalate: func(error) {
+			t.Fatal("claim shouldn't have been escalated")
+		},
+	}
+
+	txHash, err := cs.run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, ethcommon.Hash{1}, txHash)
+	require.Equal(t, []uint{0, 1, 2}, bumps)
+}
+
+func TestClaimScheduler_EscalatesBeforeT1(t *testing.T) {
+	var escalations int
+	cs := &claimScheduler{
+		schedule: testClaimSchedule,
+		// less than half of the claim window is left
+		t0:      time.Now().Add(-time.Millisecond * 300),
+		t1:      time.Now().Add(time.Millisecond * 200),
+		isReady: func() bool { return true },
+		claim: func(uint) (ethcommon.Hash, error) {
+			return ethcommon.Hash{}, errTestClaim
+		},
+		escalate: func(err error) {
+			require.ErrorIs(t, err, errTestClaim)
+			escalations++
+		},
+	}
+
+	_, err := cs.run(context.Background())
+	require.ErrorIs(t, err, errPastClaimTime)
+	require.Equal(t, 1, escalations)
+}
+
+func TestClaimScheduler_SwapCompleted(t *testing.T) {
+	var attempts int
+	cs := &claimScheduler{
+		schedule: testClaimSchedule,
+		t0:       time.Now().Add(-time.Hour),
+		t1:       time.Now().Add(time.Hour),
+		isReady:  func() bool { return true },
+		claim: func(uint) (ethcommon.Hash, error) {
+			attempts++
+			return ethcommon.Hash{}, errors.New("execution reverted: " + revertSwapCompleted)
+		},
+	}
+
+	// there's no point retrying a claim of a swap that's already completed
+	_, err := cs.run(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 1, attempts)
+}
+
+func TestClaimScheduler_NotWaitingToClaim(t *testing.T) {
+	var attempts int
+	cs := &claimScheduler{
+		schedule: testClaimSchedule,
+		t0:       time.Now().Add(-time.Hour),
+		t1:       time.Now().Add(time.Hour),
+		isReady:  func() bool { return true },
+		claim: func(uint) (ethcommon.Hash, error) {
+			attempts++
+			return ethcommon.Hash{}, errNotWaitingToClaim
+		},
+	}
+
+	// nor one that was claimed some other way, or has ended
+	_, err := cs.run(context.Background())
+	require.ErrorIs(t, err, errNotWaitingToClaim)
+	require.Equal(t, 1, attempts)
+}
+
+func TestClaimScheduler_ReadyNotified(t *testing.T) {
+	ready := make(chan struct{})
+	schedule := testClaimSchedule
+	schedule.pollInterval = time.Hour
+	cs := &claimScheduler{
+		schedule: schedule,
+		t0:       time.Now().Add(time.Hour),
+		t1:       time.Now().Add(time.Hour * 2),
+		isReady: func() bool {
+			select {
+			case <-ready:
+				return true
+			default:
+				return false
+			}
+		},
+		ready: ready,
+		claim: func(uint) (ethcommon.Hash, error) {
+			return ethcommon.Hash{1}, nil
+		},
+	}
+
+	// the notification wakes the scheduler, rather than it waiting for the next poll
+	time.AfterFunc(time.Millisecond*50, func() {
+		close(ready)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		txHash, err := cs.run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, ethcommon.Hash{1}, txHash)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("claim wasn't attempted once the contract was ready")
+	}
+}
+
+func TestClaimScheduler_ClaimRequested(t *testing.T) {
+	requests := make(chan chan<- claimResult)
+	var bumps []uint
+	schedule := testClaimSchedule
+	schedule.initialBackoff = time.Hour
+	cs := &claimScheduler{
+		schedule: schedule,
+		t0:       time.Now().Add(-time.Hour),
+		t1:       time.Now().Add(time.Hour * 2),
+		isReady:  func() bool { return false },
+		claim: func(feeBumps uint) (ethcommon.Hash, error) {
+			bumps = append(bumps, feeBumps)
+			if len(bumps) < 2 {
+				return ethcommon.Hash{}, errTestClaim
+			}
+			return ethcommon.Hash{1}, nil
+		},
+		requests: requests,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		txHash, err := cs.run(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, ethcommon.Hash{1}, txHash)
+	}()
+
+	// the claim is attempted now, rather than after the backoff
+	result := make(chan claimResult, 1)
+	requests <- result
+	res := <-result
+	require.NoError(t, res.err)
+	require.Equal(t, ethcommon.Hash{1}, res.txHash)
+	<-done
+	require.Equal(t, []uint{0, 1}, bumps)
+}
+
+func TestClaimScheduler_ClaimRequestedBeforeT0(t *testing.T) {
+	requests := make(chan chan<- claimResult)
+	cs := &claimScheduler{
+		schedule: testClaimSchedule,
+		t0:       time.Now().Add(time.Hour),
+		t1:       time.Now().Add(time.Hour * 2),
+		isReady:  func() bool { return false },
+		claim: func(uint) (ethcommon.Hash, error) {
+			t.Fatal("swap shouldn't have been claimed")
+			return ethcommon.Hash{}, nil
+		},
+		requests: requests,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := cs.run(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	}()
+
+	// the scheduler carries on waiting for t0
+	result := make(chan claimResult, 1)
+	requests <- result
+	require.ErrorIs(t, (<-result).err, errClaimNotAllowedYet)
+	cancel()
+	<-done
+}
+
+func TestCheckClaimWindow(t *testing.T) {
+	now := time.Now()
+	t0 := now.Add(time.Hour)
+	t1 := now.Add(time.Hour * 2)
+
+	require.ErrorIs(t, checkClaimWindow(now, t0, t1, false), errClaimNotAllowedYet)
+	require.NoError(t, checkClaimWindow(now, t0, t1, true))
+	require.NoError(t, checkClaimWindow(t0.Add(time.Second), t0, t1, false))
+	require.ErrorIs(t, checkClaimWindow(t1, t0, t1, true), errPastClaimTime)
+}
@@ -0,0 +1,56 @@
+package xmrmaker
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// gas budgeted for our claim call; preflight checks budget the same for each contract call
+const claimGasBudget = 200000
+
+// checkMinimum checks that the amount of XMR being swapped is at least our global minimum.
+func (b *Instance) checkMinimum(xmrAmount float64) error {
+	if xmrAmount < b.minSwapAmount {
+		return fmt.Errorf("%w: %v XMR is below %v XMR", errBelowMinSwapAmount, xmrAmount, b.minSwapAmount)
+	}
+
+	return nil
+}
+
+// checkClaimCost checks that the ether we'd receive covers the expected gas cost of claiming it,
+// so that we don't lock XMR in a swap that costs more to claim than it pays.
+func (b *Instance) checkClaimCost(ethAmount float64) error {
+	gasPrice, err := b.backend.GasPrice(b.backend.Ctx())
+	if err != nil {
+		return fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	cost := common.EtherAmount(*new(big.Int).Mul(gasPrice, big.NewInt(claimGasBudget)))
+	if common.EtherToWei(ethAmount).BigInt().Cmp(cost.BigInt()) <= 0 {
+		return fmt.Errorf("%w: %v ETH received, %v ETH to claim", errClaimCostExceedsProceeds, ethAmount,
+			cost.AsEther())
+	}
+
+	return nil
+}
+
+// checkNotDust checks that a swap of xmrAmount for ethAmount is worth doing.
+func (b *Instance) checkNotDust(xmrAmount, ethAmount float64) error {
+	if err := b.checkMinimum(xmrAmount); err != nil {
+		return err
+	}
+
+	return b.checkClaimCost(ethAmount)
+}
+
+// checkOfferNotDust checks that the smallest swap the offer allows is worth doing.
+func (b *Instance) checkOfferNotDust(o *types.Offer) error {
+	if o.MinimumAmount <= 0 {
+		return errOfferMinimumNotPositive
+	}
+
+	return b.checkNotDust(o.MinimumAmount, o.ExchangeRate.ToETH(o.MinimumAmount))
+}
@@ -0,0 +1,77 @@
+package xmrmaker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/net"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDustInstance(t *testing.T) *Instance {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	backend := NewMockBackend(ctrl)
+	backend.EXPECT().Ctx().Return(context.Background()).AnyTimes()
+	// claiming costs 200000 * 100 gwei = 0.02 ETH
+	backend.EXPECT().GasPrice(gomock.Any()).Return(big.NewInt(100e9), nil).AnyTimes()
+
+	return &Instance{
+		backend:       backend,
+		offerManager:  newOfferManager(t.TempDir()),
+		quotes:        newQuoteManager(0),
+		minSwapAmount: 0.5,
+	}
+}
+
+func TestInstance_CheckNotDust(t *testing.T) {
+	b := newTestDustInstance(t)
+
+	require.NoError(t, b.checkNotDust(1, 0.1))
+	require.ErrorIs(t, b.checkNotDust(0.4, 0.1), errBelowMinSwapAmount)
+	require.ErrorIs(t, b.checkNotDust(1, 0.02), errClaimCostExceedsProceeds)
+}
+
+func TestInstance_CheckOfferNotDust(t *testing.T) {
+	b := newTestDustInstance(t)
+
+	offer := newTestOffer()
+	require.NoError(t, b.checkOfferNotDust(offer))
+
+	offer.MinimumAmount = 0
+	require.ErrorIs(t, b.checkOfferNotDust(offer), errOfferMinimumNotPositive)
+
+	offer.MinimumAmount = 0.1
+	require.ErrorIs(t, b.checkOfferNotDust(offer), errBelowMinSwapAmount)
+
+	// 1 XMR at this rate is worth less than the gas to claim it
+	offer.MinimumAmount = 1
+	offer.ExchangeRate = 0.01
+	require.ErrorIs(t, b.checkOfferNotDust(offer), errClaimCostExceedsProceeds)
+}
+
+func TestInstance_HandleInitiateMessage_Dust(t *testing.T) {
+	b := newTestDustInstance(t)
+	offer := newTestOffer()
+	offer.MinimumAmount = 0.1
+	b.offerManager.putOffer(offer)
+
+	// 0.03 ETH buys 0.3 XMR, within the offer's range but below our global minimum
+	_, _, err := b.HandleInitiateMessage(&net.SendKeysMessage{
+		OfferID:        offer.GetID().String(),
+		ProvidedAmount: 0.03,
+	})
+	require.ErrorIs(t, err, errBelowMinSwapAmount)
+
+	// the offer wasn't taken
+	require.NotNil(t, b.offerManager.getOffer(offer.GetID()))
+
+	_, err = b.HandleQuoteRequest(&net.QuoteRequest{
+		OfferID:        offer.GetID().String(),
+		ProvidedAmount: 0.03,
+	})
+	require.ErrorIs(t, err, errBelowMinSwapAmount)
+}
@@ -11,6 +11,9 @@ var (
 	errMissingAddress        = errors.New("got empty contract address")
 	errNoRefundLogsFound     = errors.New("no refund logs found")
 	errPastClaimTime         = errors.New("past t1, can no longer claim")
+	errClaimNotAllowedYet    = errors.New("swap can't be claimed until it's set to ready or t0 passes")
+	errNotWaitingToClaim     = errors.New("swap isn't waiting to claim; our XMR must be locked first")
+	errNoOngoingSwap         = errors.New("no ongoing swap with given ID")
 	errNilSwapState          = errors.New("swap state is nil")
 	errNilMessage            = errors.New("message is nil")
 	errIncorrectMessageType  = errors.New("received unexpected message")
@@ -20,6 +23,9 @@ var (
 	errUnexpectedSwapID      = errors.New("unexpected swap ID was emitted by New log")
 	errInvalidSwapContract   = errors.New("given contract address does not contain correct code")
 	errSwapIDMismatch        = errors.New("hash of swap struct does not match swap ID")
+	errUnexpectedTimeout     = errors.New("contract timeout does not match the negotiated speed tier")
+	errTimeoutOutOfRange     = errors.New("contract timeout is outside the range we accept")
+	errLockTxReorged         = errors.New("transaction locking ETH was reorged out while waiting for confirmations")
 
 	// protocol initiation errors
 	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
@@ -27,5 +33,18 @@ var (
 	errNoOfferWithID             = errors.New("failed to find offer with given ID")
 	errAmountProvidedTooLow      = errors.New("amount provided by taker is too low for offer")
 	errAmountProvidedTooHigh     = errors.New("amount provided by taker is too high for offer")
+	errMakerRequiresPrivateKey   = errors.New("making offers requires an ethereum private key, not an external signer")
+	errOfferExpiryInPast         = errors.New("offer's expiry must be in the future")
 	errUnlockedBalanceTooLow     = errors.New("unlocked balance is less than maximum offer amount")
+	errNoPriceOracle             = errors.New("pegged offers require a price oracle to be configured")
+	errInvalidSpread             = errors.New("pegged offer's spread must be greater than -1")
+	errBelowMinSwapAmount        = errors.New("swap amount is below our minimum")
+	errClaimCostExceedsProceeds  = errors.New("ether received wouldn't cover the gas to claim it")
+	errOfferMinimumNotPositive   = errors.New("offer's minimum amount must be positive")
+
+	// quote errors
+	errNoQuoteWithID    = errors.New("failed to find quote with given ID")
+	errQuoteExpired     = errors.New("quote has expired")
+	errQuoteMismatch    = errors.New("quote is for a different offer or amount")
+	errInvalidQuoteRate = errors.New("quoted exchange rate must be positive")
 )
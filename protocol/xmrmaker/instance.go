@@ -1,12 +1,17 @@
 package xmrmaker
 
 import (
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
 	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/oracle"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	logging "github.com/ipfs/go-log"
 )
 
@@ -23,6 +28,19 @@ type Instance struct {
 	walletFile, walletPassword string
 
 	offerManager *offerManager
+	quotes       *quoteManager
+	pricer       Pricer
+	oracle       oracle.Source
+
+	// least XMR we'll swap
+	minSwapAmount float64
+	// where claimed ether is forwarded to, if set
+	payoutAddress ethcommon.Address
+	// how long offers are listed for; if 0, they don't expire
+	offerTTL time.Duration
+
+	// where swaps' info file contents are also stored, if set
+	database db.Database
 
 	swapMu     sync.Mutex
 	swapStates map[types.Hash]*swapState
@@ -33,6 +51,31 @@ type Config struct {
 	Backend                    backend.Backend
 	Basepath                   string
 	WalletFile, WalletPassword string
+
+	// Pricer, if set, prices the quotes we give takers; otherwise the offer's exchange rate is quoted
+	Pricer Pricer
+	// QuoteValidity is how long our quotes are honoured for; defaults to DefaultQuoteValidity
+	QuoteValidity time.Duration
+
+	// Oracle, if set, gives the index rate that pegged offers' exchange rates follow
+	Oracle oracle.Source
+	// RateRefreshInterval is how often pegged offers' rates are refreshed; defaults to
+	// DefaultRateRefreshInterval
+	RateRefreshInterval time.Duration
+
+	// MinSwapAmount is the least XMR we'll swap, whatever offers' minimums are
+	MinSwapAmount float64
+
+	// PayoutAddress, if set, is where claimed ether is forwarded to, so that it doesn't stay in
+	// accounts whose keys the daemon holds
+	PayoutAddress ethcommon.Address
+
+	// OfferTTL, if set, is how long offers are listed for before they expire
+	OfferTTL time.Duration
+
+	// Database, if set, is where listed offers are saved, otherwise they're saved in Basepath, and
+	// where swaps' info file contents are also stored
+	Database db.Database
 }
 
 // NewInstance returns a new *xmrmaker.Instance.
@@ -46,27 +89,93 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		log.Warn("monero wallet-file not set; must be set via RPC call personal_setMoneroWalletFile before making an offer")
 	}
 
-	return &Instance{
+	b := &Instance{
 		backend:        cfg.Backend,
 		basepath:       cfg.Basepath,
 		walletFile:     cfg.WalletFile,
 		walletPassword: cfg.WalletPassword,
 		offerManager:   newOfferManager(cfg.Basepath),
+		quotes:         newQuoteManager(cfg.QuoteValidity),
+		pricer:         cfg.Pricer,
+		oracle:         cfg.Oracle,
+		minSwapAmount:  cfg.MinSwapAmount,
+		payoutAddress:  cfg.PayoutAddress,
+		offerTTL:       cfg.OfferTTL,
+		database:       cfg.Database,
 		swapStates:     make(map[types.Hash]*swapState),
-	}, nil
+	}
+
+	if cfg.Oracle != nil {
+		interval := cfg.RateRefreshInterval
+		if interval == 0 {
+			interval = DefaultRateRefreshInterval
+		}
+
+		go b.refreshPeggedRates(cfg.Backend.Ctx(), interval)
+	}
+
+	// offers listed before a restart are listed again
+	b.offerManager.db = cfg.Database
+	loaded, err := b.offerManager.load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load saved offers: %w", err)
+	}
+	if loaded != 0 {
+		log.Infof("loaded %d saved offers", loaded)
+	}
+
+	go b.removeExpiredOffers(cfg.Backend.Ctx())
+	return b, nil
 }
 
 // SetMoneroWalletFile sets the Instance's current monero wallet file.
 func (b *Instance) SetMoneroWalletFile(file, password string) error {
+	b.backend.LockClient()
+	defer b.backend.UnlockClient()
+
 	_ = b.backend.CloseWallet()
-	return b.backend.OpenWallet(file, password)
+	if err := b.backend.OpenWallet(file, password); err != nil {
+		return err
+	}
+
+	b.walletFile, b.walletPassword = file, password
+	return nil
 }
 
-func (b *Instance) openWallet() error { //nolint
+// openWallet re-opens our own wallet, eg. after a swap has opened the wallet it reclaimed its monero to,
+// so that other ongoing swaps keep locking funds from it. It must be called with the client lock held.
+func (b *Instance) openWallet() error {
+	if b.walletFile == "" {
+		return nil
+	}
+
 	return b.backend.OpenWallet(b.walletFile, b.walletPassword)
 }
 
+// Claim is called by the RPC function swap_claim.
+// It claims the ongoing swap's ether now, if the swap contract allows it, rather than waiting for
+// the next scheduled claim attempt.
+func (b *Instance) Claim(id types.Hash) (ethcommon.Hash, error) {
+	s := b.getSwapState(id)
+	if s == nil {
+		return ethcommon.Hash{}, errNoOngoingSwap
+	}
+
+	return s.claimNow()
+}
+
 // GetOngoingSwapState ...
 func (b *Instance) GetOngoingSwapState(id types.Hash) common.SwapState {
+	s := b.getSwapState(id)
+	if s == nil {
+		return nil
+	}
+
+	return s
+}
+
+func (b *Instance) getSwapState(id types.Hash) *swapState {
+	b.swapMu.Lock()
+	defer b.swapMu.Unlock()
 	return b.swapStates[id]
 }
@@ -52,19 +52,10 @@ func (s *swapState) HandleProtocolMessage(msg net.Message) (net.Message, bool, e
 		log.Debug("contract ready, attempting to claim funds...")
 		close(s.readyCh)
 
-		// contract ready, let's claim our ether
-		txHash, err := s.claimFunds()
-		if err != nil {
-			return nil, true, fmt.Errorf("failed to redeem ether: %w", err)
-		}
-
-		log.Debug("funds claimed!!")
-		out := &message.NotifyClaimed{
-			TxHash: txHash.String(),
-		}
-
-		s.clearNextExpectedMessage(types.CompletedSuccess)
-		return out, true, nil
+		// the ether is claimed in the background, by the same scheduler that claims it after t0,
+		// which notifies the counterparty once it's claimed
+		s.startClaiming()
+		return nil, false, nil
 	case *message.NotifyRefund:
 		// generate monero wallet, regaining control over locked funds
 		addr, err := s.handleRefund(msg.TxHash)
@@ -75,6 +66,10 @@ func (s *swapState) HandleProtocolMessage(msg net.Message) (net.Message, bool, e
 		s.clearNextExpectedMessage(types.CompletedRefund)
 		log.Infof("regained control over monero account %s", addr)
 		return nil, true, nil
+	case *message.NotifyAbort:
+		// exiting aborts the swap, or reclaims our monero if we already locked it
+		log.Infof("counterparty aborted swap %s: %s", s.ID(), msg.Reason)
+		return nil, true, nil
 	default:
 		return nil, true, errUnexpectedMessageType
 	}
@@ -102,13 +97,59 @@ func (s *swapState) setNextExpectedMessage(msg net.Message) {
 	}
 
 	s.nextExpectedMessage = msg
+	s.watchMessageDeadline(msg)
+
 	// TODO: check stage is not unknown (ie. swap completed)
 	stage := pcommon.GetStatus(msg.Type())
+	if stage != types.UnknownStatus {
+		s.info.SetStatus(stage)
+	}
+
 	if s.statusCh != nil {
 		s.statusCh <- stage
 	}
 }
 
+// watchMessageDeadline gives up on the counterparty if it doesn't send msg in time.
+func (s *swapState) watchMessageDeadline(msg net.Message) {
+	timeout := pcommon.MessageTimeout(s.Env(), msg.Type())
+	if timeout == 0 {
+		return
+	}
+
+	log.Debugf("waiting up to %vs for %s", timeout.Seconds(), msg.Type())
+
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(timeout):
+			s.handleMessageTimeout(msg.Type())
+		}
+	}()
+}
+
+// handleMessageTimeout exits the swap if we're still waiting for a message of type t.
+func (s *swapState) handleMessageTimeout(t message.Type) {
+	s.lockState()
+	defer s.unlockState()
+
+	if s.nextExpectedMessage == nil || s.nextExpectedMessage.Type() != t {
+		return
+	}
+
+	log.Warnf("counterparty didn't send %s in time, exiting swap %s", t, s.ID())
+
+	switch s.nextExpectedMessage.(type) {
+	case *net.SendKeysMessage, *message.NotifyETHLocked:
+		s.notifyAbort(fmt.Sprintf("timed out waiting for %s", t))
+	}
+
+	if err := s.exit(); err != nil {
+		log.Errorf("failed to exit swap: err=%s", err)
+	}
+}
+
 func (s *swapState) checkMessageType(msg net.Message) error {
 	if msg == nil {
 		return errNilMessage
@@ -123,6 +164,11 @@ func (s *swapState) checkMessageType(msg net.Message) error {
 		return nil
 	}
 
+	// XMRTaker may abort anytime before locking their ETH.
+	if _, ok := msg.(*message.NotifyAbort); ok {
+		return nil
+	}
+
 	if msg.Type() != s.nextExpectedMessage.Type() {
 		return errIncorrectMessageType
 	}
@@ -146,13 +192,26 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) (net.Mes
 		return nil, err
 	}
 
+	if err := checkSpeedTierTimeout(msg.ContractSwap, s.speedTier); err != nil {
+		return nil, err
+	}
+
+	minTimeout, maxTimeout := pcommon.SwapTimeoutRange(s.Env())
+	if err := checkTimeoutRange(msg.ContractSwap, minTimeout, maxTimeout); err != nil {
+		return nil, err
+	}
+
 	s.contractSwapID = msg.ContractSwapID
 	s.contractSwap = convertContractSwap(msg.ContractSwap)
 
-	if err := pcommon.WriteContractSwapToFile(s.infoFile, s.contractSwapID, s.contractSwap); err != nil {
+	if err := pcommon.WriteContractSwapToFile(s.infoDB, s.infoFile, s.contractSwapID, s.contractSwap); err != nil {
 		return nil, err
 	}
 
+	if err := pcommon.WriteContractAddressToFile(s.infoDB, s.infoFile, msg.Address); err != nil {
+		return nil, fmt.Errorf("failed to write contract address to file: %w", err)
+	}
+
 	contractAddr := ethcommon.HexToAddress(msg.Address)
 	if err := checkContractCode(s.ctx, s, contractAddr); err != nil {
 		return nil, err
@@ -162,10 +221,6 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) (net.Mes
 		return nil, fmt.Errorf("failed to instantiate contract instance: %w", err)
 	}
 
-	if err := pcommon.WriteContractAddressToFile(s.infoFile, msg.Address); err != nil {
-		return nil, fmt.Errorf("failed to write contract address to file: %w", err)
-	}
-
 	if err := s.checkContract(ethcommon.HexToHash(msg.TxHash)); err != nil {
 		return nil, err
 	}
@@ -173,65 +228,29 @@ func (s *swapState) handleNotifyETHLocked(msg *message.NotifyETHLocked) (net.Mes
 	// TODO: check these (in checkContract)
 	s.setTimeouts(msg.ContractSwap.Timeout0, msg.ContractSwap.Timeout1)
 
-	addrAB, err := s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
+	addrAB, lockTx, err := s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to lock funds: %w", err)
 	}
 
 	out := &message.NotifyXMRLock{
 		Address: string(addrAB),
+		TxHash:  lockTx.TxHash,
+		TxKey:   lockTx.TxKey,
+		Height:  uint64(lockTx.height),
 	}
 
-	go func() {
-		until := time.Until(s.t0)
-		log.Debugf("time until t0: %vs", until.Seconds())
-
-		select {
-		case <-s.ctx.Done():
-			return
-		// TODO: Document why we add one second
-		case <-time.After(until + time.Second):
-			s.handleT0Expired()
-		case <-s.readyCh:
-			return
-		}
-	}()
+	// the ether is claimed once the contract is set to ready, or t0 passes
+	s.startClaiming()
 
 	s.setNextExpectedMessage(&message.NotifyReady{})
 	return out, nil
 }
 
-func (s *swapState) handleT0Expired() {
-	s.lockState()
-	defer s.unlockState()
-
-	if !s.info.Status().IsOngoing() {
-		// swap was already completed, just return
-		return
-	}
-
-	// we can now call Claim()
-	txHash, err := s.claimFunds()
-	if err != nil {
-		log.Errorf("failed to claim: err=%s", err)
-		// TODO: retry claim, depending on error
-		if err = s.exit(); err != nil {
-			log.Errorf("exit failed: err=%s", err)
-		}
-		return
-	}
-
-	log.Debug("funds claimed!")
-	s.clearNextExpectedMessage(types.CompletedSuccess)
-
-	// send *message.NotifyClaimed
-	notifyClaimed := &message.NotifyClaimed{TxHash: txHash.String()}
-	if err := s.SendSwapMessage(notifyClaimed, s.ID()); err != nil {
-		log.Errorf("failed to send NotifyClaimed message: err=%s", err)
-	}
-}
-
 func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) error {
+	s.info.SetPeerVersion(msg.Version)
+	log.Infof("counterparty for swap %s is running version %s", s.info.ID(), msg.Version)
+
 	if msg.PublicSpendKey == "" || msg.PublicViewKey == "" {
 		return errMissingKeys
 	}
@@ -247,6 +266,10 @@ func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) error {
 		return err
 	}
 
+	if err = pcommon.WriteCounterpartyKeysToFile(s.infoDB, s.infoFile, msg); err != nil {
+		return fmt.Errorf("failed to write counterparty keys to file: %w", err)
+	}
+
 	s.setXMRTakerPublicKeys(kp, secp256k1Pub)
 	s.setNextExpectedMessage(&message.NotifyETHLocked{})
 	return nil
@@ -64,6 +64,36 @@ func (mr *MockBackendMockRecorder) BalanceAt(arg0, arg1, arg2 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceAt", reflect.TypeOf((*MockBackend)(nil).BalanceAt), arg0, arg1, arg2)
 }
 
+// BlockNumber mocks base method.
+func (m *MockBackend) BlockNumber(arg0 context.Context) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockNumber", arg0)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockNumber indicates an expected call of BlockNumber.
+func (mr *MockBackendMockRecorder) BlockNumber(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockNumber", reflect.TypeOf((*MockBackend)(nil).BlockNumber), arg0)
+}
+
+// CallContract mocks base method.
+func (m *MockBackend) CallContract(arg0 context.Context, arg1 ethereum.CallMsg, arg2 *big.Int) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CallContract", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CallContract indicates an expected call of CallContract.
+func (mr *MockBackendMockRecorder) CallContract(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContract", reflect.TypeOf((*MockBackend)(nil).CallContract), arg0, arg1, arg2)
+}
+
 // CallOpts mocks base method.
 func (m *MockBackend) CallOpts() *bind.CallOpts {
 	m.ctrl.T.Helper()
@@ -93,9 +123,13 @@ func (mr *MockBackendMockRecorder) ChainID() *gomock.Call {
 }
 
 // Claim mocks base method.
-func (m *MockBackend) Claim(arg0 types0.Hash, arg1 swapfactory.SwapFactorySwap, arg2 [32]byte) (common.Hash, *types.Receipt, error) {
+func (m *MockBackend) Claim(arg0 types0.Hash, arg1 swapfactory.SwapFactorySwap, arg2 [32]byte, arg3 ...txsender.SendOption) (common.Hash, *types.Receipt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Claim", arg0, arg1, arg2)
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Claim", varargs...)
 	ret0, _ := ret[0].(common.Hash)
 	ret1, _ := ret[1].(*types.Receipt)
 	ret2, _ := ret[2].(error)
@@ -103,9 +137,10 @@ func (m *MockBackend) Claim(arg0 types0.Hash, arg1 swapfactory.SwapFactorySwap,
 }
 
 // Claim indicates an expected call of Claim.
-func (mr *MockBackendMockRecorder) Claim(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockBackendMockRecorder) Claim(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockBackend)(nil).Claim), arg0, arg1, arg2)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockBackend)(nil).Claim), varargs...)
 }
 
 // CloseWallet mocks base method.
@@ -250,6 +285,35 @@ func (mr *MockBackendMockRecorder) FilterLogs(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FilterLogs", reflect.TypeOf((*MockBackend)(nil).FilterLogs), arg0, arg1)
 }
 
+// GasConfig mocks base method.
+func (m *MockBackend) GasConfig() txsender.GasConfig {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GasConfig")
+	ret0, _ := ret[0].(txsender.GasConfig)
+	return ret0
+}
+
+// GasConfig indicates an expected call of GasConfig.
+func (mr *MockBackendMockRecorder) GasConfig() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasConfig", reflect.TypeOf((*MockBackend)(nil).GasConfig))
+}
+
+// GasPrice mocks base method.
+func (m *MockBackend) GasPrice(arg0 context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GasPrice", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GasPrice indicates an expected call of GasPrice.
+func (mr *MockBackendMockRecorder) GasPrice(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasPrice", reflect.TypeOf((*MockBackend)(nil).GasPrice), arg0)
+}
+
 // GenerateBlocks mocks base method.
 func (m *MockBackend) GenerateBlocks(arg0 string, arg1 uint) error {
 	m.ctrl.T.Helper()
@@ -279,17 +343,17 @@ func (mr *MockBackendMockRecorder) GenerateFromKeys(arg0, arg1, arg2, arg3 inter
 }
 
 // GenerateViewOnlyWalletFromKeys mocks base method.
-func (m *MockBackend) GenerateViewOnlyWalletFromKeys(arg0 *mcrypto.PrivateViewKey, arg1 mcrypto.Address, arg2, arg3 string) error {
+func (m *MockBackend) GenerateViewOnlyWalletFromKeys(arg0 *mcrypto.PrivateViewKey, arg1 mcrypto.Address, arg2 uint64, arg3, arg4 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GenerateViewOnlyWalletFromKeys", arg0, arg1, arg2, arg3)
+	ret := m.ctrl.Call(m, "GenerateViewOnlyWalletFromKeys", arg0, arg1, arg2, arg3, arg4)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // GenerateViewOnlyWalletFromKeys indicates an expected call of GenerateViewOnlyWalletFromKeys.
-func (mr *MockBackendMockRecorder) GenerateViewOnlyWalletFromKeys(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockBackendMockRecorder) GenerateViewOnlyWalletFromKeys(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateViewOnlyWalletFromKeys", reflect.TypeOf((*MockBackend)(nil).GenerateViewOnlyWalletFromKeys), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateViewOnlyWalletFromKeys", reflect.TypeOf((*MockBackend)(nil).GenerateViewOnlyWalletFromKeys), arg0, arg1, arg2, arg3, arg4)
 }
 
 // GetAccounts mocks base method.
@@ -337,6 +401,36 @@ func (mr *MockBackendMockRecorder) GetBalance(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockBackend)(nil).GetBalance), arg0)
 }
 
+// CheckTxKey mocks base method.
+func (m *MockBackend) CheckTxKey(arg0, arg1 string, arg2 mcrypto.Address) (*monero.CheckTxKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckTxKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*monero.CheckTxKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckTxKey indicates an expected call of CheckTxKey.
+func (mr *MockBackendMockRecorder) CheckTxKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckTxKey", reflect.TypeOf((*MockBackend)(nil).CheckTxKey), arg0, arg1, arg2)
+}
+
+// GetTransfers mocks base method.
+func (m *MockBackend) GetTransfers(arg0 uint) (*monero.GetTransfersResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransfers", arg0)
+	ret0, _ := ret[0].(*monero.GetTransfersResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransfers indicates an expected call of GetTransfers.
+func (mr *MockBackendMockRecorder) GetTransfers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransfers", reflect.TypeOf((*MockBackend)(nil).GetTransfers), arg0)
+}
+
 // GetHeight mocks base method.
 func (m *MockBackend) GetHeight() (uint, error) {
 	m.ctrl.T.Helper()
@@ -352,6 +446,49 @@ func (mr *MockBackendMockRecorder) GetHeight() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeight", reflect.TypeOf((*MockBackend)(nil).GetHeight))
 }
 
+// GetInfo mocks base method.
+func (m *MockBackend) GetInfo() (*monero.GetInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInfo")
+	ret0, _ := ret[0].(*monero.GetInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInfo indicates an expected call of GetInfo.
+func (mr *MockBackendMockRecorder) GetInfo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInfo", reflect.TypeOf((*MockBackend)(nil).GetInfo))
+}
+
+// HasDaemonClient mocks base method.
+func (m *MockBackend) HasDaemonClient() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDaemonClient")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasDaemonClient indicates an expected call of HasDaemonClient.
+func (mr *MockBackendMockRecorder) HasDaemonClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDaemonClient", reflect.TypeOf((*MockBackend)(nil).HasDaemonClient))
+}
+
+// HasEthAddress mocks base method.
+func (m *MockBackend) HasEthAddress(arg0 common.Address) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasEthAddress", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasEthAddress indicates an expected call of HasEthAddress.
+func (mr *MockBackendMockRecorder) HasEthAddress(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasEthAddress", reflect.TypeOf((*MockBackend)(nil).HasEthAddress), arg0)
+}
+
 // LockClient mocks base method.
 func (m *MockBackend) LockClient() {
 	m.ctrl.T.Helper()
@@ -453,6 +590,21 @@ func (mr *MockBackendMockRecorder) Refund(arg0, arg1, arg2 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockBackend)(nil).Refund), arg0, arg1, arg2)
 }
 
+// RegisterEthKeyIndex mocks base method.
+func (m *MockBackend) RegisterEthKeyIndex(arg0 uint32) (common.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterEthKeyIndex", arg0)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterEthKeyIndex indicates an expected call of RegisterEthKeyIndex.
+func (mr *MockBackendMockRecorder) RegisterEthKeyIndex(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterEthKeyIndex", reflect.TypeOf((*MockBackend)(nil).RegisterEthKeyIndex), arg0)
+}
+
 // SendSwapMessage mocks base method.
 func (m *MockBackend) SendSwapMessage(arg0 message.Message, arg1 types0.Hash) error {
 	m.ctrl.T.Helper()
@@ -515,6 +667,18 @@ func (mr *MockBackendMockRecorder) SetEthAddress(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEthAddress", reflect.TypeOf((*MockBackend)(nil).SetEthAddress), arg0)
 }
 
+// SetGasConfig mocks base method.
+func (m *MockBackend) SetGasConfig(arg0 *txsender.GasConfig) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetGasConfig", arg0)
+}
+
+// SetGasConfig indicates an expected call of SetGasConfig.
+func (mr *MockBackendMockRecorder) SetGasConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGasConfig", reflect.TypeOf((*MockBackend)(nil).SetGasConfig), arg0)
+}
+
 // SetGasPrice mocks base method.
 func (m *MockBackend) SetGasPrice(arg0 uint64) {
 	m.ctrl.T.Helper()
@@ -555,6 +719,20 @@ func (mr *MockBackendMockRecorder) SetSwapTimeout(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSwapTimeout", reflect.TypeOf((*MockBackend)(nil).SetSwapTimeout), arg0)
 }
 
+// SetTransferPriority mocks base method.
+func (m *MockBackend) SetTransferPriority(arg0 monero.TransferPriority) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTransferPriority", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTransferPriority indicates an expected call of SetTransferPriority.
+func (mr *MockBackendMockRecorder) SetTransferPriority(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTransferPriority", reflect.TypeOf((*MockBackend)(nil).SetTransferPriority), arg0)
+}
+
 // SetXMRDepositAddress mocks base method.
 func (m *MockBackend) SetXMRDepositAddress(arg0 mcrypto.Address, arg1 types0.Hash) {
 	m.ctrl.T.Helper()
@@ -567,6 +745,22 @@ func (mr *MockBackendMockRecorder) SetXMRDepositAddress(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetXMRDepositAddress", reflect.TypeOf((*MockBackend)(nil).SetXMRDepositAddress), arg0, arg1)
 }
 
+// SwapEthAddress mocks base method.
+func (m *MockBackend) SwapEthAddress(arg0 types0.Hash) (common.Address, *uint32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwapEthAddress", arg0)
+	ret0, _ := ret[0].(common.Address)
+	ret1, _ := ret[1].(*uint32)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SwapEthAddress indicates an expected call of SwapEthAddress.
+func (mr *MockBackendMockRecorder) SwapEthAddress(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwapEthAddress", reflect.TypeOf((*MockBackend)(nil).SwapEthAddress), arg0)
+}
+
 // SwapManager mocks base method.
 func (m *MockBackend) SwapManager() swap.Manager {
 	m.ctrl.T.Helper()
@@ -610,6 +804,52 @@ func (mr *MockBackendMockRecorder) SweepAll(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepAll", reflect.TypeOf((*MockBackend)(nil).SweepAll), arg0, arg1)
 }
 
+// SweepSwapAccount mocks base method.
+func (m *MockBackend) SweepSwapAccount(arg0 common.Address) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepSwapAccount", arg0)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepSwapAccount indicates an expected call of SweepSwapAccount.
+func (mr *MockBackendMockRecorder) SweepSwapAccount(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepSwapAccount", reflect.TypeOf((*MockBackend)(nil).SweepSwapAccount), arg0)
+}
+
+// SyncProgress mocks base method.
+func (m *MockBackend) SyncProgress(arg0 context.Context) (*ethereum.SyncProgress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncProgress", arg0)
+	ret0, _ := ret[0].(*ethereum.SyncProgress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncProgress indicates an expected call of SyncProgress.
+func (mr *MockBackendMockRecorder) SyncProgress(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncProgress", reflect.TypeOf((*MockBackend)(nil).SyncProgress), arg0)
+}
+
+// TransactionByHash mocks base method.
+func (m *MockBackend) TransactionByHash(arg0 context.Context, arg1 common.Hash) (*types.Transaction, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransactionByHash", arg0, arg1)
+	ret0, _ := ret[0].(*types.Transaction)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TransactionByHash indicates an expected call of TransactionByHash.
+func (mr *MockBackendMockRecorder) TransactionByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransactionByHash", reflect.TypeOf((*MockBackend)(nil).TransactionByHash), arg0, arg1)
+}
+
 // TransactionReceipt mocks base method.
 func (m *MockBackend) TransactionReceipt(arg0 context.Context, arg1 common.Hash) (*types.Receipt, error) {
 	m.ctrl.T.Helper()
@@ -640,6 +880,21 @@ func (mr *MockBackendMockRecorder) Transfer(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockBackend)(nil).Transfer), arg0, arg1, arg2)
 }
 
+// TransferETH mocks base method.
+func (m *MockBackend) TransferETH(arg0, arg1 common.Address, arg2 *big.Int) (common.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferETH", arg0, arg1, arg2)
+	ret0, _ := ret[0].(common.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransferETH indicates an expected call of TransferETH.
+func (mr *MockBackendMockRecorder) TransferETH(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferETH", reflect.TypeOf((*MockBackend)(nil).TransferETH), arg0, arg1, arg2)
+}
+
 // TxOpts mocks base method.
 func (m *MockBackend) TxOpts() (*bind.TransactOpts, error) {
 	m.ctrl.T.Helper()
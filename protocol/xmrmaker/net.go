@@ -1,10 +1,14 @@
 package xmrmaker
 
 import (
+	"sync/atomic"
+
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/net"
 	"github.com/noot/atomic-swap/net/message"
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/protocol/preflight"
 
 	"github.com/fatih/color" //nolint:misspell
 )
@@ -14,33 +18,70 @@ func (b *Instance) Provides() types.ProvidesCoin {
 	return types.ProvidesXMR
 }
 
+// reservedAmount returns the monero, in piconero, that ongoing swaps will lock but haven't yet;
+// it's still part of our unlocked balance. It must be called with swapMu held.
+func (b *Instance) reservedAmount() common.MoneroAmount {
+	var reserved common.MoneroAmount
+	for _, s := range b.swapStates {
+		if atomic.LoadInt32(&s.xmrTransferred) == 1 {
+			continue
+		}
+
+		switch s.info.Status() {
+		case types.ExpectingKeys, types.KeysExchanged, types.ETHLocked:
+			reserved += common.MoneroToPiconero(s.info.ProvidedAmount())
+		}
+	}
+
+	return reserved
+}
+
 func (b *Instance) initiate(offer *types.Offer, offerExtra *types.OfferExtra, providesAmount common.MoneroAmount,
-	desiredAmount common.EtherAmount) error {
+	desiredAmount common.EtherAmount, tier *types.SpeedTier) (*swapState, error) {
 	b.swapMu.Lock()
 	defer b.swapMu.Unlock()
 
 	if b.swapStates[offer.GetID()] != nil {
-		return errProtocolAlreadyInProgress
+		return nil, errProtocolAlreadyInProgress
 	}
 
+	b.backend.LockClient()
 	balance, err := b.backend.GetBalance(0)
+	b.backend.UnlockClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// check user's balance and that they actually have what they will provide, on top of what
+	// our other ongoing swaps are yet to lock; otherwise the swap would fail after the taker locked
+	// their ETH. initiations are serialised by swapMu, so checking and adding the swap can't race.
+	if balance.UnlockedBalance <= float64(providesAmount+b.reservedAmount()) {
+		return nil, errBalanceTooLow
+	}
+
+	report, err := preflight.Run(b.backend.Ctx(), b.backend, types.ProvidesXMR, providesAmount.AsMonero())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// check user's balance and that they actually have what they will provide
-	if balance.UnlockedBalance <= float64(providesAmount) {
-		return errBalanceTooLow
+	if err = report.Err(); err != nil {
+		return nil, err
 	}
 
 	s, err := newSwapState(b.backend, offer, b.offerManager, offerExtra.StatusCh,
-		offerExtra.InfoFile, providesAmount, desiredAmount)
+		offerExtra.InfoFile, b.database, providesAmount, desiredAmount)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	s.speedTier = tier
+	s.openWallet = b.openWallet
+	s.payoutAddress = b.payoutAddress
+
 	go func() {
 		<-s.done
+		b.swapMu.Lock()
+		defer b.swapMu.Unlock()
 		delete(b.swapStates, offer.GetID())
 	}()
 
@@ -51,7 +92,21 @@ func (b *Instance) initiate(offer *types.Offer, offerExtra *types.OfferExtra, pr
 		s.info.ProvidedAmount()),
 	)
 	b.swapStates[offer.GetID()] = s
-	return nil
+	return s, nil
+}
+
+// checkTakenAmount checks that the amount of XMR being taken from the offer is within its range,
+// which may be less than its maximum, and returns the speed tier the taker asked for.
+func checkTakenAmount(offer *types.Offer, amount float64, speedTier string) (*types.SpeedTier, error) {
+	if amount < offer.MinimumAmount {
+		return nil, errAmountProvidedTooLow
+	}
+
+	if amount > offer.MaximumAmount {
+		return nil, errAmountProvidedTooHigh
+	}
+
+	return offer.GetSpeedTier(speedTier)
 }
 
 // HandleInitiateMessage is called when we receive a network message from a peer that they wish to initiate a swap.
@@ -73,28 +128,47 @@ func (b *Instance) HandleInitiateMessage(msg *net.SendKeysMessage) (net.SwapStat
 		return nil, nil, errNoOfferWithID
 	}
 
-	providedAmount := offer.ExchangeRate.ToXMR(msg.ProvidedAmount)
-
-	if providedAmount < offer.MinimumAmount {
-		return nil, nil, errAmountProvidedTooLow
+	rate, err := b.currentRate(offer)
+	if err != nil {
+		b.offerManager.putOffer(offer)
+		return nil, nil, err
 	}
 
-	if providedAmount > offer.MaximumAmount {
-		return nil, nil, errAmountProvidedTooHigh
-	}
+	// a quote overrides the offer's exchange rate
+	if msg.QuoteID != "" {
+		var quote *net.Quote
+		quote, err = b.quotes.takeQuote(msg.QuoteID, id, msg.ProvidedAmount)
+		if err != nil {
+			b.offerManager.putOffer(offer)
+			return nil, nil, err
+		}
 
-	if err = b.initiate(offer, offerExtra, common.MoneroToPiconero(providedAmount), common.EtherToWei(msg.ProvidedAmount)); err != nil { //nolint:lll
-		return nil, nil, err
+		rate = quote.ExchangeRate
 	}
 
-	offerID, err := types.HexToHash(msg.OfferID)
+	providedAmount := rate.ToXMR(msg.ProvidedAmount)
+	var s *swapState
+	tier, err := checkTakenAmount(offer, providedAmount, msg.SpeedTier)
+	if err == nil {
+		err = offer.CheckTake(msg.ProvidedAmount)
+	}
+	if err == nil {
+		err = b.checkNotDust(providedAmount, msg.ProvidedAmount)
+	}
+	if err == nil {
+		s, err = b.initiate(offer, offerExtra, common.MoneroToPiconero(providedAmount), common.EtherToWei(msg.ProvidedAmount), tier) //nolint:lll
+	}
 	if err != nil {
+		// the offer wasn't taken, so it's still available
+		b.offerManager.putOffer(offer)
 		return nil, nil, err
 	}
 
-	s, has := b.swapStates[offerID]
-	if !has {
-		panic("did not store swap state in Instance map")
+	// the rest of the offer stays available to other takers
+	remainder := b.offerManager.putRemainder(offer, providedAmount)
+	if remainder != nil {
+		log.Infof("offer %s partially taken; remaining %v XMR listed as offer %s",
+			offer.GetID(), remainder.MaximumAmount, remainder.GetID())
 	}
 
 	if err = s.handleSendKeysMessage(msg); err != nil {
@@ -106,6 +180,17 @@ func (b *Instance) HandleInitiateMessage(msg *net.SendKeysMessage) (net.SwapStat
 		return nil, nil, err
 	}
 
+	if remainder != nil {
+		resp.RemainderOfferID = remainder.GetID().String()
+	}
+
+	b.backend.SwapManager().Events().Publish(&events.Event{
+		Type:   events.OfferTaken,
+		SwapID: offer.GetID(),
+		Status: s.info.Status(),
+		Offer:  offer,
+	})
+
 	defer func() {
 		s.setNextExpectedMessage(&message.NotifyETHLocked{})
 	}()
@@ -3,8 +3,10 @@ package xmrmaker
 import (
 	"testing"
 
+	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/net/message"
+	pswap "github.com/noot/atomic-swap/protocol/swap"
 
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +20,7 @@ func TestXMRMaker_HandleInitiateMessage(t *testing.T) {
 		MaximumAmount: 0.002,
 		ExchangeRate:  0.1,
 	}
-	_, err := b.MakeOffer(offer)
+	_, err := b.MakeOffer(offer, nil, nil, nil)
 	require.NoError(t, err)
 
 	msg, _ := newTestXMRTakerSendKeysMessage(t)
@@ -28,5 +30,52 @@ func TestXMRMaker_HandleInitiateMessage(t *testing.T) {
 	_, resp, err := b.HandleInitiateMessage(msg)
 	require.NoError(t, err)
 	require.Equal(t, message.SendKeysType, resp.Type())
-	require.NotNil(t, b.swapStates[offer.GetID()])
+	require.NotNil(t, b.getSwapState(offer.GetID()))
+}
+
+func TestXMRMaker_HandleInitiateMessage_SpeedTier(t *testing.T) {
+	b := newTestXMRMaker(t)
+
+	cheap := &types.SpeedTier{Name: "cheap", MoneroConfirmations: 10, Timeout: 3600}
+	offer := &types.Offer{
+		Provides:      types.ProvidesXMR,
+		MinimumAmount: 0.001,
+		MaximumAmount: 0.002,
+		ExchangeRate:  0.1,
+		SpeedTiers: []*types.SpeedTier{
+			{Name: "fast", MoneroConfirmations: 5, Timeout: 600},
+			cheap,
+		},
+	}
+	_, err := b.MakeOffer(offer, nil, nil, nil)
+	require.NoError(t, err)
+
+	msg, _ := newTestXMRTakerSendKeysMessage(t)
+	msg.OfferID = offer.GetID().String()
+	msg.ProvidedAmount = offer.MinimumAmount * float64(offer.ExchangeRate)
+	msg.SpeedTier = "cheap"
+
+	_, _, err = b.HandleInitiateMessage(msg)
+	require.NoError(t, err)
+	require.Equal(t, cheap, b.getSwapState(offer.GetID()).speedTier)
+}
+
+func TestInstance_ReservedAmount(t *testing.T) {
+	b := &Instance{swapStates: make(map[types.Hash]*swapState)}
+	add := func(id byte, amount float64, status types.Status) {
+		b.swapStates[types.Hash{id}] = &swapState{
+			info: pswap.NewInfo(types.Hash{id}, types.ProvidesXMR, amount, 1, 1, status, nil),
+		}
+	}
+
+	add(1, 1, types.ExpectingKeys)
+	add(2, 2, types.ETHLocked)
+	// these swaps have already locked their monero, so it's no longer part of our balance
+	add(3, 4, types.XMRLocked)
+	add(4, 8, types.ContractReady)
+	require.Equal(t, common.MoneroToPiconero(3), b.reservedAmount())
+
+	// the monero leaves our balance as soon as the lock transfer is sent, before the status changes
+	b.swapStates[types.Hash{2}].xmrTransferred = 1
+	require.Equal(t, common.MoneroToPiconero(1), b.reservedAmount())
 }
@@ -0,0 +1,238 @@
+package xmrmaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+)
+
+const (
+	offersFilename = "offers.json"
+
+	// how often listed offers are checked for expiry
+	offerExpiryCheckInterval = time.Minute
+)
+
+// storedOffer is a listed offer as it's saved to disk, along with what the maker knows about it.
+type storedOffer struct {
+	Offer     *types.Offer        `json:"offer"`
+	InfoFile  string              `json:"infoFile"`
+	Relist    *types.RelistPolicy `json:"relist,omitempty"`
+	Peg       *types.RatePeg      `json:"peg,omitempty"`
+	Takers    *types.TakerFilter  `json:"takers,omitempty"`
+	ExpiresAt *time.Time          `json:"expiresAt,omitempty"`
+	Token     string              `json:"token,omitempty"`
+}
+
+func (om *offerManager) offersFile() string {
+	return filepath.Join(om.basepath, offersFilename)
+}
+
+// saveLocked writes the listed offers to the database, or to disk if there isn't one, so they can
+// be listed again after a restart. It must be called with the lock held.
+func (om *offerManager) saveLocked() {
+	if om.db == nil && om.basepath == "" {
+		return
+	}
+
+	stored := make([]*storedOffer, 0, len(om.offers))
+	for id, oe := range om.offers {
+		so := &storedOffer{
+			Offer:    oe.offer,
+			InfoFile: oe.extra.InfoFile,
+			Relist:   om.policies[id],
+			Peg:      om.pegs[id],
+			Takers:   om.takers[id],
+			Token:    om.tokens[id],
+		}
+		if expiry, has := om.expiries[id]; has {
+			so.ExpiresAt = &expiry
+		}
+		stored = append(stored, so)
+	}
+
+	var err error
+	if om.db != nil {
+		err = om.saveToDatabase(stored)
+	} else {
+		err = om.saveToFile(stored)
+	}
+	if err != nil {
+		log.Warnf("failed to save offers: %s", err)
+	}
+}
+
+func (om *offerManager) saveToDatabase(stored []*storedOffer) error {
+	kvs := make(map[string][]byte, len(stored))
+	for _, so := range stored {
+		bz, err := json.Marshal(so)
+		if err != nil {
+			return err
+		}
+
+		kvs[so.Offer.GetID().String()] = bz
+	}
+
+	return om.db.ReplaceBucket(db.OffersBucket, kvs)
+}
+
+func (om *offerManager) saveToFile(stored []*storedOffer) error {
+	bz, err := json.MarshalIndent(stored, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// the file is replaced atomically, so a crash can't leave it half-written
+	tmp := om.offersFile() + ".tmp"
+	if err = os.WriteFile(tmp, bz, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, om.offersFile())
+}
+
+// readStored returns the saved offers. If there's a database, offers saved to disk by earlier
+// versions are read too, and the file is removed once they've been moved to the database.
+func (om *offerManager) readStored() ([]*storedOffer, error) {
+	var stored []*storedOffer
+	if om.db != nil {
+		err := om.db.Iterate(db.OffersBucket, func(_, value []byte) error {
+			var so *storedOffer
+			if err := json.Unmarshal(value, &so); err != nil {
+				return err
+			}
+
+			stored = append(stored, so)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if om.basepath == "" {
+		return stored, nil
+	}
+
+	bz, err := os.ReadFile(filepath.Clean(om.offersFile()))
+	if errors.Is(err, os.ErrNotExist) {
+		return stored, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fromFile []*storedOffer
+	if err = json.Unmarshal(bz, &fromFile); err != nil {
+		return nil, err
+	}
+
+	if om.db != nil {
+		defer func() {
+			if rmErr := os.Remove(om.offersFile()); rmErr != nil {
+				log.Warnf("failed to remove %s: %s", om.offersFile(), rmErr)
+			}
+		}()
+	}
+
+	return append(stored, fromFile...), nil
+}
+
+// load lists the saved offers, apart from those that have expired since, and returns how many
+// were listed.
+func (om *offerManager) load() (int, error) {
+	stored, err := om.readStored()
+	if err != nil {
+		return 0, err
+	}
+
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	now := time.Now()
+	loaded := 0
+	for _, so := range stored {
+		if so.Offer == nil || (so.ExpiresAt != nil && !now.Before(*so.ExpiresAt)) {
+			continue
+		}
+
+		// offers saved by older versions don't advertise their expiry
+		if so.Offer.ExpiresAt == nil && so.ExpiresAt != nil {
+			so.Offer.ExpiresAt = so.ExpiresAt
+		}
+
+		id := so.Offer.GetID()
+		om.putOfferLocked(so.Offer).InfoFile = so.InfoFile
+		if so.Relist != nil {
+			om.policies[id] = so.Relist
+		}
+		if so.Peg != nil {
+			om.pegs[id] = so.Peg
+		}
+		if so.Takers != nil {
+			om.takers[id] = so.Takers
+		}
+		if so.ExpiresAt != nil {
+			om.expiries[id] = *so.ExpiresAt
+		}
+		if so.Token != "" {
+			om.tokens[id] = so.Token
+		}
+		loaded++
+	}
+
+	om.saveLocked()
+	return loaded, nil
+}
+
+// expiredLocked returns whether the offer with the given ID has expired. It must be called with
+// the lock held.
+func (om *offerManager) expiredLocked(id types.Hash, now time.Time) bool {
+	expiry, has := om.expiries[id]
+	return has && !now.Before(expiry)
+}
+
+// removeExpired removes listed offers that have expired.
+func (om *offerManager) removeExpired() {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	now := time.Now()
+	removed := false
+	for id := range om.offers {
+		if !om.expiredLocked(id, now) {
+			continue
+		}
+
+		log.Infof("offer %s has expired, removing it", id)
+		delete(om.offers, id)
+		om.forgetOfferLocked(id)
+		removed = true
+	}
+
+	if removed {
+		om.saveLocked()
+	}
+}
+
+// removeExpiredOffers periodically removes expired offers until the context is cancelled.
+func (b *Instance) removeExpiredOffers(ctx context.Context) {
+	ticker := time.NewTicker(offerExpiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		b.offerManager.removeExpired()
+	}
+}
@@ -0,0 +1,122 @@
+package xmrmaker
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfferManager_SaveAndLoad(t *testing.T) {
+	basepath := t.TempDir()
+	om := newOfferManager(basepath)
+
+	offer := newTestOffer()
+	relist := &types.RelistPolicy{Cooldown: 60}
+	peg := &types.RatePeg{Spread: 0.01}
+	takers := &types.TakerFilter{Allow: []string{"a"}}
+	om.setRelistPolicy(offer.GetID(), relist)
+	om.setPeg(offer.GetID(), peg)
+	om.setTakerFilter(offer.GetID(), takers)
+	om.setToken(offer.GetID(), "secret")
+	extra := om.putOffer(offer)
+
+	// the daemon restarts
+	reloaded := newOfferManager(basepath)
+	loaded, err := reloaded.load()
+	require.NoError(t, err)
+	require.Equal(t, 1, loaded)
+
+	offers := reloaded.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, offer.GetID(), offers[0].GetID())
+	require.Equal(t, offer.MaximumAmount, offers[0].MaximumAmount)
+	require.Equal(t, extra.InfoFile, reloaded.offers[offer.GetID()].extra.InfoFile)
+	require.Equal(t, relist, reloaded.policies[offer.GetID()])
+	require.Equal(t, peg, reloaded.getPeg(offer.GetID()))
+	require.Equal(t, takers, reloaded.getTakerFilter(offer.GetID()))
+	require.Equal(t, "secret", reloaded.getToken(offer.GetID()))
+
+	// offers that are taken or cleared aren't loaded again
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	require.NotNil(t, taken)
+	loaded, err = newOfferManager(basepath).load()
+	require.NoError(t, err)
+	require.Zero(t, loaded)
+
+	om.putOffer(offer)
+	om.clearOffers()
+	loaded, err = newOfferManager(basepath).load()
+	require.NoError(t, err)
+	require.Zero(t, loaded)
+}
+
+func TestOfferManager_Expiry(t *testing.T) {
+	basepath := t.TempDir()
+	om := newOfferManager(basepath)
+
+	live, expired := newTestOffer(), newTestOffer()
+	expired.MaximumAmount = 5
+	om.setExpiry(live.GetID(), time.Now().Add(time.Hour))
+	om.setExpiry(expired.GetID(), time.Now().Add(-time.Second))
+	om.putOffer(live)
+	om.putOffer(expired)
+
+	offers := om.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, live.GetID(), offers[0].GetID())
+	require.Nil(t, om.getOffer(expired.GetID()))
+
+	// expired offers are neither loaded after a restart nor can be taken
+	reloaded := newOfferManager(basepath)
+	loaded, err := reloaded.load()
+	require.NoError(t, err)
+	require.Equal(t, 1, loaded)
+	require.WithinDuration(t, om.expiries[live.GetID()], reloaded.expiries[live.GetID()], 0)
+
+	// offers saved without their expiry advertise it once they're loaded
+	require.NotNil(t, reloaded.getOffer(live.GetID()).ExpiresAt)
+	require.WithinDuration(t, om.expiries[live.GetID()], *reloaded.getOffer(live.GetID()).ExpiresAt, 0)
+
+	taken, _ := om.getAndDeleteOffer(expired.GetID())
+	require.Nil(t, taken)
+
+	om.setExpiry(live.GetID(), time.Now().Add(-time.Second))
+	om.removeExpired()
+	require.Empty(t, om.offers)
+	require.Empty(t, om.expiries)
+}
+
+func TestOfferManager_Database(t *testing.T) {
+	basepath := t.TempDir()
+	d := db.NewMemoryDatabase()
+	defer d.Close() //nolint:errcheck
+
+	// an offer saved to disk by an earlier version is moved to the database
+	legacy := newTestOffer()
+	newOfferManager(basepath).putOffer(legacy)
+
+	om := newOfferManager(basepath)
+	om.db = d
+	loaded, err := om.load()
+	require.NoError(t, err)
+	require.Equal(t, 1, loaded)
+	_, err = os.Stat(om.offersFile())
+	require.True(t, os.IsNotExist(err))
+
+	offer := newTestOffer()
+	offer.MaximumAmount = 5
+	om.putOffer(offer)
+
+	reloaded := newOfferManager(basepath)
+	reloaded.db = d
+	loaded, err = reloaded.load()
+	require.NoError(t, err)
+	require.Equal(t, 2, loaded)
+	require.NotNil(t, reloaded.getOffer(legacy.GetID()))
+	require.NotNil(t, reloaded.getOffer(offer.GetID()))
+}
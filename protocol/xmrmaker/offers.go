@@ -1,9 +1,18 @@
 package xmrmaker
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
 	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/protocol/oracle"
 )
 
 type offerWithExtra struct {
@@ -12,18 +21,50 @@ type offerWithExtra struct {
 }
 
 type offerManager struct {
-	offers   map[types.Hash]*offerWithExtra
-	basepath string
+	mu     sync.Mutex
+	offers map[types.Hash]*offerWithExtra
+	// map of partially taken offer IDs -> IDs of the offers listing their remainders
+	remainders map[types.Hash]types.Hash
+	// map of offer IDs -> whether to list them again after a failed swap; offers without one are
+	// listed again straight away
+	policies map[types.Hash]*types.RelistPolicy
+	// map of pegged offers' IDs -> their pegs
+	pegs map[types.Hash]*types.RatePeg
+	// map of offer IDs -> filters restricting who may take them
+	takers map[types.Hash]*types.TakerFilter
+	// map of offer IDs -> when they expire; offers without one don't
+	expiries map[types.Hash]time.Time
+	// map of private offers' IDs -> the tokens a taker needs to take them
+	tokens map[types.Hash]string
+	// incremented when offers are cleared, so that offers waiting out a cooldown aren't listed again
+	generation uint64
+	basepath   string
+	// database the offers are saved to; if nil, they're saved to a file in basepath
+	db db.Database
 }
 
 func newOfferManager(basepath string) *offerManager {
 	return &offerManager{
-		offers:   make(map[types.Hash]*offerWithExtra),
-		basepath: basepath,
+		offers:     make(map[types.Hash]*offerWithExtra),
+		remainders: make(map[types.Hash]types.Hash),
+		policies:   make(map[types.Hash]*types.RelistPolicy),
+		pegs:       make(map[types.Hash]*types.RatePeg),
+		takers:     make(map[types.Hash]*types.TakerFilter),
+		expiries:   make(map[types.Hash]time.Time),
+		tokens:     make(map[types.Hash]string),
+		basepath:   basepath,
 	}
 }
 
 func (om *offerManager) putOffer(o *types.Offer) *types.OfferExtra {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	extra := om.putOfferLocked(o)
+	om.saveLocked()
+	return extra
+}
+
+func (om *offerManager) putOfferLocked(o *types.Offer) *types.OfferExtra {
 	offer, has := om.offers[o.GetID()]
 	if has {
 		return offer.extra
@@ -45,17 +86,349 @@ func (om *offerManager) putOffer(o *types.Offer) *types.OfferExtra {
 }
 
 func (om *offerManager) getAndDeleteOffer(id types.Hash) (*types.Offer, *types.OfferExtra) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
 	offer, has := om.offers[id]
 	if !has {
 		return nil, nil
 	}
 
 	delete(om.offers, id)
+	defer om.saveLocked()
+
+	if om.expiredLocked(id, time.Now()) {
+		om.forgetOfferLocked(id)
+		return nil, nil
+	}
+
 	return offer.offer, offer.extra
 }
 
-// MakeOffer makes a new swap offer.
-func (b *Instance) MakeOffer(o *types.Offer) (*types.OfferExtra, error) {
+func (om *offerManager) getOffer(id types.Hash) *types.Offer {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	offer, has := om.offers[id]
+	if !has || om.expiredLocked(id, time.Now()) {
+		return nil
+	}
+
+	return offer.offer
+}
+
+// putRemainder lists what's left of an offer after `taken` XMR of it was taken, as a new offer
+// whose maximum is reduced accordingly. It returns nil if what's left is below the offer's minimum,
+// or if the offer is private, since its token can only be used once.
+func (om *offerManager) putRemainder(o *types.Offer, taken float64) *types.Offer {
+	left := o.MaximumAmount - taken
+	if left < o.MinimumAmount || left <= 0 {
+		return nil
+	}
+
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	if _, private := om.tokens[o.GetID()]; private {
+		return nil
+	}
+
+	remainder := *o
+	remainder.ID = types.Hash{}
+	remainder.MaximumAmount = left
+	om.putOfferLocked(&remainder)
+	om.remainders[o.GetID()] = remainder.GetID()
+	if policy, has := om.policies[o.GetID()]; has {
+		om.policies[remainder.GetID()] = policy
+	}
+	if peg, has := om.pegs[o.GetID()]; has {
+		om.pegs[remainder.GetID()] = peg
+	}
+	if filter, has := om.takers[o.GetID()]; has {
+		om.takers[remainder.GetID()] = filter
+	}
+	if expiry, has := om.expiries[o.GetID()]; has {
+		om.expiries[remainder.GetID()] = expiry
+	}
+	om.saveLocked()
+	return &remainder
+}
+
+// setRelistPolicy sets whether the offer with the given ID is listed again after a failed swap.
+func (om *offerManager) setRelistPolicy(id types.Hash, policy *types.RelistPolicy) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.policies[id] = policy
+}
+
+// setPeg pegs the exchange rate of the offer with the given ID to the price oracle's index.
+func (om *offerManager) setPeg(id types.Hash, peg *types.RatePeg) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.pegs[id] = peg
+}
+
+// getPeg returns the offer's peg, or nil if it isn't pegged.
+func (om *offerManager) getPeg(id types.Hash) *types.RatePeg {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	return om.pegs[id]
+}
+
+// setTakerFilter restricts who may take the offer with the given ID.
+func (om *offerManager) setTakerFilter(id types.Hash, filter *types.TakerFilter) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.takers[id] = filter
+}
+
+// getTakerFilter returns the filter restricting who may take the offer, or nil if anyone may.
+func (om *offerManager) getTakerFilter(id types.Hash) *types.TakerFilter {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	return om.takers[id]
+}
+
+// setToken makes the offer with the given ID private: it isn't advertised, and can only be taken
+// by a taker with the token.
+func (om *offerManager) setToken(id types.Hash, token string) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.tokens[id] = token
+}
+
+// getToken returns the token needed to take the offer, or "" if it's public.
+func (om *offerManager) getToken(id types.Hash) string {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	return om.tokens[id]
+}
+
+// setExpiry sets when the offer with the given ID expires and is no longer listed.
+func (om *offerManager) setExpiry(id types.Hash, expiry time.Time) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.expiries[id] = expiry
+}
+
+// updatePeggedRates sets the exchange rates of listed pegged offers from the given index rate.
+func (om *offerManager) updatePeggedRates(index types.ExchangeRate) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	for id, oe := range om.offers {
+		peg, has := om.pegs[id]
+		if !has {
+			continue
+		}
+
+		// offers may be being sent to peers, so they're replaced rather than updated in place
+		updated := *oe.offer
+		updated.ExchangeRate = oracle.PeggedRate(index, peg.Spread)
+		oe.offer = &updated
+	}
+}
+
+// forgetOffer drops what we know about an offer that was taken successfully.
+func (om *offerManager) forgetOffer(id types.Hash) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.forgetOfferLocked(id)
+}
+
+func (om *offerManager) forgetOfferLocked(id types.Hash) {
+	delete(om.remainders, id)
+	delete(om.policies, id)
+	delete(om.pegs, id)
+	delete(om.takers, id)
+	delete(om.expiries, id)
+	delete(om.tokens, id)
+}
+
+// restoreOffer makes the `taken` XMR of an offer available again after its swap failed, according
+// to the offer's relist policy: one-shot offers are dropped, and others are listed again once their
+// cooldown, if any, has passed.
+func (om *offerManager) restoreOffer(o *types.Offer, taken float64) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	policy := om.policies[o.GetID()]
+	switch {
+	case policy != nil && policy.OneShot:
+		log.Infof("not listing one-shot offer %s again", o.GetID())
+		om.forgetOfferLocked(o.GetID())
+	case policy == nil || policy.Cooldown == 0:
+		om.relistLocked(o, taken)
+	default:
+		log.Infof("listing offer %s again in %s", o.GetID(), policy.CooldownDuration())
+		generation := om.generation
+		time.AfterFunc(policy.CooldownDuration(), func() {
+			om.mu.Lock()
+			defer om.mu.Unlock()
+			if om.generation != generation {
+				return
+			}
+
+			om.relistLocked(o, taken)
+		})
+	}
+}
+
+// relistLocked lists the `taken` XMR of an offer again. If the offer's remainder is still listed,
+// it's added back to it; otherwise the offer is listed again. Expired offers aren't listed again.
+// It must be called with the lock held.
+func (om *offerManager) relistLocked(o *types.Offer, taken float64) {
+	defer om.saveLocked()
+
+	if om.expiredLocked(o.GetID(), time.Now()) {
+		log.Infof("not listing expired offer %s again", o.GetID())
+		om.forgetOfferLocked(o.GetID())
+		return
+	}
+
+	remainderID, partial := om.remainders[o.GetID()]
+	delete(om.remainders, o.GetID())
+
+	if !partial {
+		om.putOfferLocked(o)
+		return
+	}
+
+	if rem, has := om.offers[remainderID]; has {
+		updated := *rem.offer
+		updated.MaximumAmount += taken
+		rem.offer = &updated
+		return
+	}
+
+	// the remainder was taken too, so only what this swap took is available again
+	restored := *o
+	restored.MaximumAmount = taken
+	om.putOfferLocked(&restored)
+}
+
+func (om *offerManager) getOffers() []*types.Offer {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	now := time.Now()
+	offers := make([]*types.Offer, 0, len(om.offers))
+	for id, o := range om.offers {
+		// expired offers are removed periodically, but shouldn't be advertised in the meantime
+		if om.expiredLocked(id, now) {
+			continue
+		}
+
+		offers = append(offers, o.offer)
+	}
+	return offers
+}
+
+func (om *offerManager) clearOffers() {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+	om.offers = make(map[types.Hash]*offerWithExtra)
+	om.remainders = make(map[types.Hash]types.Hash)
+	om.policies = make(map[types.Hash]*types.RelistPolicy)
+	om.pegs = make(map[types.Hash]*types.RatePeg)
+	om.takers = make(map[types.Hash]*types.TakerFilter)
+	om.expiries = make(map[types.Hash]time.Time)
+	om.tokens = make(map[types.Hash]string)
+	om.generation++
+	om.saveLocked()
+}
+
+// removeOffers removes the offers with the given IDs, so they're no longer listed. If any of them
+// isn't listed, none are removed.
+func (om *offerManager) removeOffers(ids []types.Hash) error {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	for _, id := range ids {
+		if _, has := om.offers[id]; !has {
+			return fmt.Errorf("%w: %s", errNoOfferWithID, id)
+		}
+	}
+
+	for _, id := range ids {
+		delete(om.offers, id)
+		om.forgetOfferLocked(id)
+	}
+
+	om.saveLocked()
+	return nil
+}
+
+// MakeOffer makes a new swap offer. The relist policy, if set, determines whether it's listed again
+// after a swap taking it fails; otherwise it's listed again straight away. If the offer is pegged,
+// its exchange rate is set from the price oracle's index and kept up to date. The taker filter, if
+// set, restricts who may take it.
+func (b *Instance) MakeOffer(o *types.Offer, relist *types.RelistPolicy, peg *types.RatePeg,
+	takers *types.TakerFilter) (*types.OfferExtra, error) {
+	return b.makeOffer(o, relist, peg, takers, "")
+}
+
+// MakePrivateOffer makes a new swap offer that isn't advertised, and returns the one-time token
+// that a taker needs to take it. The offer can be taken by whoever we give the token and our
+// address to; it isn't listed again if the swap fails, and what's left of it after a partial take
+// isn't listed.
+func (b *Instance) MakePrivateOffer(o *types.Offer, peg *types.RatePeg) (*types.OfferExtra, string, error) {
+	token, err := newOfferToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	extra, err := b.makeOffer(o, &types.RelistPolicy{OneShot: true}, peg, nil, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return extra, token, nil
+}
+
+// newOfferToken returns a random token for a private offer.
+func newOfferToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate offer token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func (b *Instance) makeOffer(o *types.Offer, relist *types.RelistPolicy, peg *types.RatePeg,
+	takers *types.TakerFilter, token string) (*types.OfferExtra, error) {
+	if b.backend.ExternalSender() != nil {
+		return nil, errMakerRequiresPrivateKey
+	}
+
+	o.Version = types.CurrentOfferVersion
+	if err := o.ValidateTerms(); err != nil {
+		return nil, err
+	}
+
+	if o.Expired(time.Now()) {
+		return nil, errOfferExpiryInPast
+	}
+
+	if err := o.ValidateSpeedTiers(); err != nil {
+		return nil, err
+	}
+
+	if peg != nil {
+		if err := b.setPeggedRate(o, peg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := b.checkOfferNotDust(o); err != nil {
+		return nil, err
+	}
+
+	b.swapMu.Lock()
+	reserved := b.reservedAmount()
+	b.swapMu.Unlock()
+
 	b.backend.LockClient()
 	defer b.backend.UnlockClient()
 
@@ -64,33 +437,79 @@ func (b *Instance) MakeOffer(o *types.Offer) (*types.OfferExtra, error) {
 		return nil, err
 	}
 
-	if common.MoneroAmount(balance.UnlockedBalance) < common.MoneroToPiconero(o.MaximumAmount) {
+	// monero that ongoing swaps are yet to lock can't back a new offer; it's compared by adding
+	// rather than subtracting, as the unlocked balance may be below what's reserved
+	if balance.UnlockedBalance < float64(reserved+common.MoneroToPiconero(o.MaximumAmount)) {
 		return nil, errUnlockedBalanceTooLow
 	}
 
+	if relist != nil {
+		b.offerManager.setRelistPolicy(o.GetID(), relist)
+	}
+
+	if peg != nil {
+		b.offerManager.setPeg(o.GetID(), peg)
+	}
+
+	if takers != nil {
+		b.offerManager.setTakerFilter(o.GetID(), takers)
+	}
+
+	if o.ExpiresAt == nil && b.offerTTL != 0 {
+		expiry := time.Now().Add(b.offerTTL)
+		o.ExpiresAt = &expiry
+	}
+
+	if o.ExpiresAt != nil {
+		b.offerManager.setExpiry(o.GetID(), *o.ExpiresAt)
+	}
+
+	if token != "" {
+		b.offerManager.setToken(o.GetID(), token)
+	}
+
 	extra := b.offerManager.putOffer(o)
 	log.Infof("created new offer: %v", o)
+	b.backend.SwapManager().Events().Publish(&events.Event{
+		Type:   events.OfferMade,
+		SwapID: o.GetID(),
+		Offer:  o,
+	})
 	return extra, nil
 }
 
 // GetOffers returns all current offers.
 func (b *Instance) GetOffers() []*types.Offer {
-	// lock entire instance, as if an offer is taken a swap will be deleted
-	b.swapMu.Lock()
-	defer b.swapMu.Unlock()
+	return b.offerManager.getOffers()
+}
 
-	offers := make([]*types.Offer, len(b.offerManager.offers))
-	i := 0
-	for _, o := range b.offerManager.offers {
-		offers[i] = o.offer
-		i++
+// GetTakerFilter returns the filter restricting who may take the offer with the given ID, or nil
+// if anyone may.
+func (b *Instance) GetTakerFilter(offerID string) *types.TakerFilter {
+	id, err := types.HexToHash(offerID)
+	if err != nil {
+		return nil
 	}
-	return offers
+
+	return b.offerManager.getTakerFilter(id)
 }
 
-// ClearOffers clears all offers.
-func (b *Instance) ClearOffers() {
-	b.swapMu.Lock()
-	defer b.swapMu.Unlock()
-	b.offerManager.offers = make(map[types.Hash]*offerWithExtra)
+// GetOfferToken returns the token needed to take the offer with the given ID, or "" if it's public.
+func (b *Instance) GetOfferToken(offerID string) string {
+	id, err := types.HexToHash(offerID)
+	if err != nil {
+		return ""
+	}
+
+	return b.offerManager.getToken(id)
+}
+
+// ClearOffers removes the offers with the given IDs, or all offers if none are given.
+func (b *Instance) ClearOffers(ids []types.Hash) error {
+	if len(ids) == 0 {
+		b.offerManager.clearOffers()
+		return nil
+	}
+
+	return b.offerManager.removeOffers(ids)
 }
@@ -0,0 +1,244 @@
+package xmrmaker
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+	pswap "github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOffer() *types.Offer {
+	return &types.Offer{
+		Provides:      types.ProvidesXMR,
+		MinimumAmount: 1,
+		MaximumAmount: 10,
+		ExchangeRate:  0.1,
+	}
+}
+
+func TestOfferManager_PartialFill(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.putOffer(offer)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	require.NotNil(t, taken)
+
+	remainder := om.putRemainder(taken, 4)
+	require.NotNil(t, remainder)
+	require.NotEqual(t, offer.GetID(), remainder.GetID())
+	require.Equal(t, float64(6), remainder.MaximumAmount)
+	require.Equal(t, offer.MinimumAmount, remainder.MinimumAmount)
+
+	offers := om.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, remainder.GetID(), offers[0].GetID())
+
+	// if the swap fails, what it took is added back to the remainder
+	om.restoreOffer(taken, 4)
+	offers = om.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, remainder.GetID(), offers[0].GetID())
+	require.Equal(t, float64(10), offers[0].MaximumAmount)
+}
+
+func TestOfferManager_PartialFill_RemainderTooSmall(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.putOffer(offer)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	require.Nil(t, om.putRemainder(taken, 9.5))
+	require.Empty(t, om.getOffers())
+
+	om.restoreOffer(taken, 9.5)
+	offers := om.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, offer.GetID(), offers[0].GetID())
+	require.Equal(t, float64(10), offers[0].MaximumAmount)
+}
+
+func TestOfferManager_RestoreOffer_RemainderTaken(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.putOffer(offer)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	remainder := om.putRemainder(taken, 4)
+	_, _ = om.getAndDeleteOffer(remainder.GetID())
+
+	om.restoreOffer(taken, 4)
+	offers := om.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, offer.GetID(), offers[0].GetID())
+	require.Equal(t, float64(4), offers[0].MaximumAmount)
+}
+
+func TestOfferManager_RestoreOffer_OneShot(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.setRelistPolicy(offer.GetID(), &types.RelistPolicy{OneShot: true})
+	om.putOffer(offer)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	remainder := om.putRemainder(taken, 4)
+
+	// only the remainder stays listed
+	om.restoreOffer(taken, 4)
+	offers := om.getOffers()
+	require.Len(t, offers, 1)
+	require.Equal(t, remainder.GetID(), offers[0].GetID())
+	require.Equal(t, float64(6), offers[0].MaximumAmount)
+
+	// the remainder inherits the policy
+	taken, _ = om.getAndDeleteOffer(remainder.GetID())
+	om.restoreOffer(taken, taken.MaximumAmount)
+	require.Empty(t, om.getOffers())
+}
+
+func TestOfferManager_RestoreOffer_Cooldown(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.setRelistPolicy(offer.GetID(), &types.RelistPolicy{Cooldown: 1})
+	om.putOffer(offer)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	om.restoreOffer(taken, taken.MaximumAmount)
+	require.Empty(t, om.getOffers())
+
+	require.Eventually(t, func() bool {
+		return len(om.getOffers()) == 1
+	}, 3*time.Second, 50*time.Millisecond)
+	require.Equal(t, offer.GetID(), om.getOffers()[0].GetID())
+}
+
+func TestOfferManager_RestoreOffer_CooldownCleared(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.setRelistPolicy(offer.GetID(), &types.RelistPolicy{Cooldown: 1})
+	om.putOffer(offer)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	om.restoreOffer(taken, taken.MaximumAmount)
+
+	// offers cleared during the cooldown stay cleared
+	om.clearOffers()
+	time.Sleep(1500 * time.Millisecond)
+	require.Empty(t, om.getOffers())
+}
+
+func TestOfferManager_RemoveOffers(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	other := newTestOffer()
+	other.MaximumAmount++
+	om.setRelistPolicy(offer.GetID(), &types.RelistPolicy{OneShot: true})
+	om.putOffer(offer)
+	om.putOffer(other)
+
+	// nothing is removed if any offer isn't listed
+	err := om.removeOffers([]types.Hash{offer.GetID(), {1}})
+	require.ErrorIs(t, err, errNoOfferWithID)
+	require.Len(t, om.getOffers(), 2)
+
+	err = om.removeOffers([]types.Hash{offer.GetID()})
+	require.NoError(t, err)
+	require.Equal(t, []*types.Offer{other}, om.getOffers())
+	require.Nil(t, om.policies[offer.GetID()])
+}
+
+func TestCheckTakenAmount(t *testing.T) {
+	offer := newTestOffer()
+
+	_, err := checkTakenAmount(offer, 5, "")
+	require.NoError(t, err)
+
+	_, err = checkTakenAmount(offer, 0.5, "")
+	require.Equal(t, errAmountProvidedTooLow, err)
+
+	_, err = checkTakenAmount(offer, 11, "")
+	require.Equal(t, errAmountProvidedTooHigh, err)
+}
+
+func TestOfferManager_TakerFilter(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.putOffer(offer)
+	filter := &types.TakerFilter{Allow: []string{"a"}}
+	om.setTakerFilter(offer.GetID(), filter)
+
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	remainder := om.putRemainder(taken, 4)
+	require.NotNil(t, remainder)
+
+	// the remainder of a private offer is private too
+	require.Equal(t, filter, om.getTakerFilter(remainder.GetID()))
+
+	om.forgetOffer(offer.GetID())
+	require.Nil(t, om.getTakerFilter(offer.GetID()))
+	require.Equal(t, filter, om.getTakerFilter(remainder.GetID()))
+}
+
+func TestOfferManager_Token(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	offer := newTestOffer()
+	om.putOffer(offer)
+	om.setToken(offer.GetID(), "secret")
+	require.Equal(t, "secret", om.getToken(offer.GetID()))
+
+	// the token can only be used once, so what's left of a private offer isn't listed
+	taken, _ := om.getAndDeleteOffer(offer.GetID())
+	require.Nil(t, om.putRemainder(taken, 4))
+	require.Empty(t, om.getOffers())
+
+	om.forgetOffer(offer.GetID())
+	require.Empty(t, om.getToken(offer.GetID()))
+}
+
+func TestNewOfferToken(t *testing.T) {
+	token, err := newOfferToken()
+	require.NoError(t, err)
+	require.Len(t, token, 64)
+
+	other, err := newOfferToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token, other)
+}
+
+func TestInstance_MakeOffer_BalanceBelowReserved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	backend := NewMockBackend(ctrl)
+
+	b := &Instance{
+		backend:      backend,
+		offerManager: newOfferManager(t.TempDir()),
+		swapStates:   make(map[types.Hash]*swapState),
+	}
+
+	// an ongoing swap is yet to lock more monero than the unlocked balance holds, eg. because
+	// some of it was spent from the wallet meanwhile
+	b.swapStates[types.Hash{1}] = &swapState{
+		info: pswap.NewInfo(types.Hash{1}, types.ProvidesXMR, 2, 1, 1, types.ETHLocked, nil),
+	}
+
+	backend.EXPECT().ExternalSender().Return(nil)
+	backend.EXPECT().Ctx().Return(context.Background()).AnyTimes()
+	backend.EXPECT().GasPrice(gomock.Any()).Return(big.NewInt(1), nil)
+	backend.EXPECT().LockClient()
+	backend.EXPECT().UnlockClient()
+	backend.EXPECT().GetBalance(uint(0)).Return(&monero.GetBalanceResponse{
+		UnlockedBalance: float64(common.MoneroToPiconero(1)),
+	}, nil)
+
+	_, err := b.makeOffer(newTestOffer(), nil, nil, nil, "")
+	require.Equal(t, errUnlockedBalanceTooLow, err)
+}
@@ -0,0 +1,55 @@
+package xmrmaker
+
+import (
+	"fmt"
+
+	"github.com/noot/atomic-swap/common"
+	pcommon "github.com/noot/atomic-swap/protocol"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// forwardPayout forwards the swap's claimed ether to our payout address, if one is set. The
+// contract only pays out to the claimer, which must sign the claim, so the ether is forwarded
+// once it's claimed. It's safe in the claiming account if forwarding fails, so failures are
+// alerted on rather than failing the swap.
+func (s *swapState) forwardPayout() {
+	from := s.contractSwap.Claimer
+	if (s.payoutAddress == ethcommon.Address{}) || s.payoutAddress == from {
+		return
+	}
+
+	amount := s.contractSwap.Value
+	txHash, err := s.TransferETH(from, s.payoutAddress, amount)
+	if err != nil {
+		pcommon.SendAlert(&pcommon.Alert{
+			SwapID: s.ID(),
+			Kind:   pcommon.AlertPayoutFailed,
+			Message: fmt.Sprintf("failed to forward %v ETH from %s to payout address %s: %s",
+				common.EtherAmount(*amount).AsEther(), from, s.payoutAddress, err),
+		})
+		return
+	}
+
+	log.Infof("forwarded %v ETH to payout address %s, tx hash=%s", common.EtherAmount(*amount).AsEther(),
+		s.payoutAddress, txHash)
+	s.addTx(txHash)
+}
+
+// sweepSwapAccount sends what's left in the swap's HD wallet account, if it used one, back to the
+// base account. As with forwarding, it's safe where it is if this fails.
+func (s *swapState) sweepSwapAccount() {
+	if !s.swapAccount {
+		return
+	}
+
+	txHash, err := s.SweepSwapAccount(s.contractSwap.Claimer)
+	if err != nil {
+		log.Warnf("failed to sweep swap account %s: %s", s.contractSwap.Claimer, err)
+		return
+	}
+
+	if (txHash != ethcommon.Hash{}) {
+		s.addTx(txHash)
+	}
+}
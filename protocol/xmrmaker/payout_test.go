@@ -0,0 +1,52 @@
+package xmrmaker
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+	pswap "github.com/noot/atomic-swap/protocol/swap"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwapState_ForwardPayout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	backend := NewMockBackend(ctrl)
+
+	claimer := ethcommon.Address{1}
+	payout := ethcommon.Address{2}
+	value := big.NewInt(1e18)
+
+	s := &swapState{
+		Backend: backend,
+		info:    pswap.NewInfo(types.Hash{1}, types.ProvidesXMR, 10, 1, 0.1, types.ExpectingKeys, nil),
+	}
+	s.contractSwap.Claimer = claimer
+	s.contractSwap.Value = value
+
+	// without a payout address, the ether stays where it was claimed to
+	s.forwardPayout()
+	require.Empty(t, s.info.TxHashes())
+
+	s.payoutAddress = payout
+	backend.EXPECT().TransferETH(claimer, payout, value).Return(ethcommon.Hash{3}, nil)
+	backend.EXPECT().TransactionReceipt(gomock.Any(), ethcommon.Hash{3}).Return(&ethtypes.Receipt{GasUsed: 21000}, nil)
+	backend.EXPECT().TransactionByHash(gomock.Any(), ethcommon.Hash{3}).
+		Return(ethtypes.NewTx(&ethtypes.LegacyTx{GasPrice: big.NewInt(100)}), false, nil)
+	s.forwardPayout()
+	require.Equal(t, []ethcommon.Hash{{3}}, s.info.TxHashes())
+	gasUsed, fees := s.info.TxCosts()
+	require.Equal(t, uint64(21000), gasUsed)
+	require.Equal(t, big.NewInt(2100000), fees)
+
+	// failures don't fail the swap; the ether is still in the claiming account
+	backend.EXPECT().TransferETH(claimer, payout, value).Return(ethcommon.Hash{}, errors.New("no gas"))
+	s.forwardPayout()
+	require.Len(t, s.info.TxHashes(), 1)
+}
@@ -0,0 +1,76 @@
+package xmrmaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/oracle"
+)
+
+// DefaultRateRefreshInterval is how often pegged offers' exchange rates are refreshed from the
+// price oracle if Config.RateRefreshInterval isn't set.
+const DefaultRateRefreshInterval = time.Minute
+
+// setPeggedRate sets the offer's exchange rate from the price oracle's current index.
+func (b *Instance) setPeggedRate(o *types.Offer, peg *types.RatePeg) error {
+	if b.oracle == nil {
+		return errNoPriceOracle
+	}
+
+	if peg.Spread <= -1 {
+		return errInvalidSpread
+	}
+
+	index, err := b.oracle.Rate(b.backend.Ctx())
+	if err != nil {
+		return err
+	}
+
+	o.ExchangeRate = oracle.PeggedRate(index, peg.Spread)
+	o.Spread = peg.Spread
+	return nil
+}
+
+// currentRate returns the exchange rate the offer is taken at. A pegged offer's advertised rate
+// may be out of date, so it's taken at the rate given by the oracle's current index.
+func (b *Instance) currentRate(offer *types.Offer) (types.ExchangeRate, error) {
+	peg := b.offerManager.getPeg(offer.GetID())
+	if peg == nil {
+		return offer.ExchangeRate, nil
+	}
+
+	if b.oracle == nil {
+		return 0, errNoPriceOracle
+	}
+
+	index, err := b.oracle.Rate(b.backend.Ctx())
+	if err != nil {
+		return 0, err
+	}
+
+	return oracle.PeggedRate(index, peg.Spread), nil
+}
+
+// refreshPeggedRates periodically updates the advertised exchange rates of pegged offers, until the
+// context is cancelled.
+func (b *Instance) refreshPeggedRates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		index, err := b.oracle.Rate(ctx)
+		if err != nil {
+			log.Warnf("failed to get index rate from %s oracle: %s", b.oracle, err)
+			continue
+		}
+
+		b.offerManager.updatePeggedRates(index)
+	}
+}
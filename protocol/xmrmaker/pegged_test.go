@@ -0,0 +1,79 @@
+package xmrmaker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOracle is an oracle.Source with a fixed index rate.
+type mockOracle struct {
+	rate types.ExchangeRate
+}
+
+func (o *mockOracle) Rate(context.Context) (types.ExchangeRate, error) {
+	return o.rate, nil
+}
+
+func (o *mockOracle) String() string {
+	return "mock"
+}
+
+func TestOfferManager_UpdatePeggedRates(t *testing.T) {
+	om := newOfferManager(t.TempDir())
+	fixed, pegged := newTestOffer(), newTestOffer()
+	om.putOffer(fixed)
+	om.setPeg(pegged.GetID(), &types.RatePeg{Spread: 0.1})
+	om.putOffer(pegged)
+
+	om.updatePeggedRates(0.2)
+	require.Equal(t, types.ExchangeRate(0.1), om.getOffer(fixed.GetID()).ExchangeRate)
+	require.InDelta(t, 0.22, float64(om.getOffer(pegged.GetID()).ExchangeRate), 1e-9)
+	// the offer that was listed isn't modified, as it may be in use
+	require.Equal(t, types.ExchangeRate(0.1), pegged.ExchangeRate)
+
+	// a pegged offer's remainder stays pegged
+	taken, _ := om.getAndDeleteOffer(pegged.GetID())
+	remainder := om.putRemainder(taken, 4)
+	om.updatePeggedRates(0.3)
+	require.InDelta(t, 0.33, float64(om.getOffer(remainder.GetID()).ExchangeRate), 1e-9)
+}
+
+func TestInstance_CurrentRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	backend := NewMockBackend(ctrl)
+	backend.EXPECT().Ctx().Return(context.Background()).AnyTimes()
+
+	b := &Instance{
+		backend:      backend,
+		offerManager: newOfferManager(t.TempDir()),
+		oracle:       &mockOracle{rate: 0.2},
+	}
+
+	offer := newTestOffer()
+	rate, err := b.currentRate(offer)
+	require.NoError(t, err)
+	require.Equal(t, offer.ExchangeRate, rate)
+
+	// takes of pegged offers are priced from the current index, not the advertised rate
+	peg := &types.RatePeg{Spread: -0.5}
+	require.NoError(t, b.setPeggedRate(offer, peg))
+	require.InDelta(t, 0.1, float64(offer.ExchangeRate), 1e-9)
+	b.offerManager.setPeg(offer.GetID(), peg)
+
+	b.oracle = &mockOracle{rate: 0.4}
+	rate, err = b.currentRate(offer)
+	require.NoError(t, err)
+	require.InDelta(t, 0.2, float64(rate), 1e-9)
+
+	require.ErrorIs(t, b.setPeggedRate(offer, &types.RatePeg{Spread: -1}), errInvalidSpread)
+
+	b.oracle = nil
+	_, err = b.currentRate(offer)
+	require.ErrorIs(t, err, errNoPriceOracle)
+}
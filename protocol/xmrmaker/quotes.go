@@ -0,0 +1,137 @@
+package xmrmaker
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net"
+)
+
+// DefaultQuoteValidity is how long a quote is honoured for if Config.QuoteValidity isn't set.
+const DefaultQuoteValidity = time.Second * 30
+
+// Pricer returns the exchange rate to quote for taking the offer with the given amount of ETH. It
+// lets a maker price its offers dynamically without republishing them.
+type Pricer func(offer *types.Offer, providedAmount float64) (types.ExchangeRate, error)
+
+type quoteManager struct {
+	mu       sync.Mutex
+	quotes   map[types.Hash]*net.Quote
+	validity time.Duration
+}
+
+func newQuoteManager(validity time.Duration) *quoteManager {
+	if validity == 0 {
+		validity = DefaultQuoteValidity
+	}
+
+	return &quoteManager{
+		quotes:   make(map[types.Hash]*net.Quote),
+		validity: validity,
+	}
+}
+
+func (qm *quoteManager) newQuote(offerID types.Hash, providedAmount float64,
+	rate types.ExchangeRate) (*net.Quote, error) {
+	var id types.Hash
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	quote := &net.Quote{
+		ID:             id,
+		OfferID:        offerID.String(),
+		ProvidedAmount: providedAmount,
+		ExchangeRate:   rate,
+		ExpiresAt:      time.Now().Add(qm.validity),
+	}
+
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	// drop expired quotes, so ones that are never used don't pile up
+	for qid, q := range qm.quotes {
+		if time.Now().After(q.ExpiresAt) {
+			delete(qm.quotes, qid)
+		}
+	}
+
+	qm.quotes[id] = quote
+	return quote, nil
+}
+
+// takeQuote removes and returns the quote with the given ID, checking that it's unexpired and
+// matches the swap it's used for.
+func (qm *quoteManager) takeQuote(id string, offerID types.Hash, providedAmount float64) (*net.Quote, error) {
+	quoteID, err := types.HexToHash(id)
+	if err != nil {
+		return nil, err
+	}
+
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+
+	quote, has := qm.quotes[quoteID]
+	if !has {
+		return nil, errNoQuoteWithID
+	}
+
+	delete(qm.quotes, quoteID)
+
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, errQuoteExpired
+	}
+
+	if quote.OfferID != offerID.String() || quote.ProvidedAmount != providedAmount {
+		return nil, errQuoteMismatch
+	}
+
+	return quote, nil
+}
+
+// HandleQuoteRequest is called when a taker asks for a firm quote on one of our offers. The offer's
+// exchange rate, or its current pegged rate, is quoted unless a Pricer is configured.
+func (b *Instance) HandleQuoteRequest(req *net.QuoteRequest) (*net.Quote, error) {
+	id, err := types.HexToHash(req.OfferID)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := b.offerManager.getOffer(id)
+	if offer == nil {
+		return nil, errNoOfferWithID
+	}
+
+	rate, err := b.currentRate(offer)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.pricer != nil {
+		rate, err = b.pricer(offer, req.ProvidedAmount)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rate <= 0 {
+		return nil, errInvalidQuoteRate
+	}
+
+	if _, err = checkTakenAmount(offer, rate.ToXMR(req.ProvidedAmount), ""); err != nil {
+		return nil, err
+	}
+
+	if err = offer.CheckTake(req.ProvidedAmount); err != nil {
+		return nil, err
+	}
+
+	// the claim cost is checked when the quote is taken, since gas prices may have changed by then
+	if err = b.checkMinimum(rate.ToXMR(req.ProvidedAmount)); err != nil {
+		return nil, err
+	}
+
+	return b.quotes.newQuote(id, req.ProvidedAmount, rate)
+}
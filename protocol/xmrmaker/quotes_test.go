@@ -0,0 +1,97 @@
+package xmrmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQuoteInstance(t *testing.T) (*Instance, *types.Offer) {
+	b := &Instance{
+		offerManager: newOfferManager(t.TempDir()),
+		quotes:       newQuoteManager(0),
+	}
+
+	offer := &types.Offer{
+		Provides:      types.ProvidesXMR,
+		MinimumAmount: 1,
+		MaximumAmount: 10,
+		ExchangeRate:  0.1,
+	}
+	b.offerManager.putOffer(offer)
+	return b, offer
+}
+
+func TestInstance_HandleQuoteRequest(t *testing.T) {
+	b, offer := newTestQuoteInstance(t)
+
+	quote, err := b.HandleQuoteRequest(&net.QuoteRequest{
+		OfferID:        offer.GetID().String(),
+		ProvidedAmount: 0.5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, offer.ExchangeRate, quote.ExchangeRate)
+	require.WithinDuration(t, time.Now().Add(DefaultQuoteValidity), quote.ExpiresAt, time.Second)
+
+	// 2 ETH would buy 20 XMR, above the offer's maximum
+	_, err = b.HandleQuoteRequest(&net.QuoteRequest{
+		OfferID:        offer.GetID().String(),
+		ProvidedAmount: 2,
+	})
+	require.ErrorIs(t, err, errAmountProvidedTooHigh)
+
+	_, err = b.HandleQuoteRequest(&net.QuoteRequest{
+		OfferID:        types.Hash{1}.String(),
+		ProvidedAmount: 0.5,
+	})
+	require.ErrorIs(t, err, errNoOfferWithID)
+}
+
+func TestInstance_HandleQuoteRequest_Pricer(t *testing.T) {
+	b, offer := newTestQuoteInstance(t)
+	b.pricer = func(o *types.Offer, providedAmount float64) (types.ExchangeRate, error) {
+		// bigger takes get a better rate
+		if providedAmount >= 0.5 {
+			return o.ExchangeRate * 0.9, nil
+		}
+		return o.ExchangeRate, nil
+	}
+
+	quote, err := b.HandleQuoteRequest(&net.QuoteRequest{
+		OfferID:        offer.GetID().String(),
+		ProvidedAmount: 0.5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, offer.ExchangeRate*0.9, quote.ExchangeRate)
+}
+
+func TestQuoteManager_TakeQuote(t *testing.T) {
+	qm := newQuoteManager(time.Millisecond * 100)
+	offerID := types.Hash{1}
+
+	quote, err := qm.newQuote(offerID, 1, 0.1)
+	require.NoError(t, err)
+
+	_, err = qm.takeQuote(quote.ID.String(), types.Hash{2}, 1)
+	require.ErrorIs(t, err, errQuoteMismatch)
+
+	// a quote can't be reused, even if using it failed
+	_, err = qm.takeQuote(quote.ID.String(), offerID, 1)
+	require.ErrorIs(t, err, errNoQuoteWithID)
+
+	quote, err = qm.newQuote(offerID, 1, 0.1)
+	require.NoError(t, err)
+	taken, err := qm.takeQuote(quote.ID.String(), offerID, 1)
+	require.NoError(t, err)
+	require.Equal(t, quote, taken)
+
+	quote, err = qm.newQuote(offerID, 1, 0.1)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 150)
+	_, err = qm.takeQuote(quote.ID.String(), offerID, 1)
+	require.ErrorIs(t, err, errQuoteExpired)
+}
@@ -19,9 +19,11 @@ type recoveryState struct {
 
 // NewRecoveryState returns a new *xmrmaker.recoveryState,
 // which has methods to either claim ether or reclaim monero from an initiated swap.
+// contractSwapBlock is the block the swap was created in, or zero if it's unknown.
 func NewRecoveryState(b backend.Backend, basePath string, secret *mcrypto.PrivateSpendKey,
 	contractAddr ethcommon.Address,
-	contractSwapID [32]byte, contractSwap swapfactory.SwapFactorySwap) (*recoveryState, error) {
+	contractSwapID [32]byte, contractSwap swapfactory.SwapFactorySwap,
+	contractSwapBlock uint64) (*recoveryState, error) {
 	kp, err := secret.AsPrivateKeyPair()
 	if err != nil {
 		return nil, err
@@ -34,15 +36,16 @@ func NewRecoveryState(b backend.Backend, basePath string, secret *mcrypto.Privat
 
 	ctx, cancel := context.WithCancel(b.Ctx())
 	s := &swapState{
-		ctx:            ctx,
-		cancel:         cancel,
-		Backend:        b,
-		privkeys:       kp,
-		pubkeys:        pubkp,
-		dleqProof:      dleq.NewProofWithSecret(sc),
-		contractSwapID: contractSwapID,
-		contractSwap:   contractSwap,
-		infoFile:       pcommon.GetSwapRecoveryFilepath(basePath),
+		ctx:               ctx,
+		cancel:            cancel,
+		Backend:           b,
+		privkeys:          kp,
+		pubkeys:           pubkp,
+		dleqProof:         dleq.NewProofWithSecret(sc),
+		contractSwapID:    contractSwapID,
+		contractSwap:      contractSwap,
+		contractSwapBlock: contractSwapBlock,
+		infoFile:          pcommon.GetSwapRecoveryFilepath(basePath),
 	}
 
 	if err := s.setContract(contractAddr); err != nil {
@@ -26,7 +26,7 @@ func newTestRecoveryState(t *testing.T) *recoveryState {
 
 	basePath := path.Join(t.TempDir(), "test-infofile")
 	rs, err := NewRecoveryState(inst.backend, basePath, s.privkeys.SpendKey(), s.ContractAddr(),
-		s.contractSwapID, s.contractSwap)
+		s.contractSwapID, s.contractSwap, s.contractSwapBlock)
 	require.NoError(t, err)
 
 	return rs
@@ -65,7 +65,7 @@ func TestClaimOrRecover_Recover(t *testing.T) {
 
 	// lock XMR
 	rs.ss.setXMRTakerPublicKeys(rs.ss.pubkeys, nil)
-	addrAB, err := rs.ss.lockFunds(1)
+	addrAB, _, err := rs.ss.lockFunds(1)
 	require.NoError(t, err)
 
 	// call refund w/ XMRTaker's spend key
@@ -8,6 +8,7 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	eth "github.com/ethereum/go-ethereum"
@@ -19,6 +20,7 @@ import (
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
 	"github.com/noot/atomic-swap/crypto/secp256k1"
+	"github.com/noot/atomic-swap/db"
 	"github.com/noot/atomic-swap/dleq"
 	"github.com/noot/atomic-swap/monero"
 	"github.com/noot/atomic-swap/net"
@@ -26,10 +28,15 @@ import (
 	pcommon "github.com/noot/atomic-swap/protocol"
 	"github.com/noot/atomic-swap/protocol/backend"
 	pswap "github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/protocol/txsender"
 	"github.com/noot/atomic-swap/swapfactory"
 )
 
-const revertSwapCompleted = "swap is already completed"
+const (
+	revertSwapCompleted = "swap is already completed"
+	// how often the chain head is checked while waiting for confirmations
+	confirmationsPollInterval = time.Second * 5
+)
 
 var (
 	// this is from the autogenerated swap.go
@@ -44,12 +51,17 @@ type swapState struct {
 	cancel   context.CancelFunc
 	stateMu  sync.Mutex
 	infoFile string
+	// database the info file's contents are also stored in; may be nil
+	infoDB db.Database
 
 	info         *pswap.Info
 	offer        *types.Offer
 	offerManager *offerManager
 	statusCh     chan types.Status
 
+	// settlement speed tier negotiated for this swap; nil if the offer has none
+	speedTier *types.SpeedTier
+
 	// our keys for this session
 	dleqProof    *dleq.Proof
 	secp256k1Pub *secp256k1.PublicKey
@@ -59,7 +71,9 @@ type swapState struct {
 	// swap contract and timeouts in it; set once contract is deployed
 	contractSwapID [32]byte
 	contractSwap   swapfactory.SwapFactorySwap
-	t0, t1         time.Time
+	// block the swap was created in, used as the starting point when searching for its logs
+	contractSwapBlock uint64
+	t0, t1            time.Time
 
 	// XMRTaker's keys for this session
 	xmrtakerPublicKeys         *mcrypto.PublicKeyPair
@@ -73,12 +87,32 @@ type swapState struct {
 	done    chan struct{}
 	exited  bool
 
+	// requests from swap_claim to claim now, taken by the claim scheduler while it's running
+	claimRequests chan chan<- claimResult
+	// the number of claim schedulers running; accessed atomically
+	claiming int32
+	// set to 1 once claiming is started in the background; accessed atomically
+	claimStarted int32
+	// set to 1 once the monero lock transfer has been sent, after which the monero is no longer
+	// part of our unlocked balance; accessed atomically
+	xmrTransferred int32
+
 	// address of reclaimed monero wallet, if the swap is refunded77
 	moneroReclaimAddress mcrypto.Address
+
+	// re-opens our own wallet after the reclaimed wallet has been opened; may be nil
+	openWallet func() error
+
+	// address claimed ether is forwarded to; if unset, it's left in the claiming account
+	payoutAddress ethcommon.Address
+
+	// set if the ether is claimed to an HD wallet swap account, which is swept back to the base
+	// account once the swap is claimed
+	swapAccount bool
 }
 
 func newSwapState(b backend.Backend, offer *types.Offer, om *offerManager, statusCh chan types.Status, infoFile string,
-	providesAmount common.MoneroAmount, desiredAmount common.EtherAmount) (*swapState, error) {
+	infoDB db.Database, providesAmount common.MoneroAmount, desiredAmount common.EtherAmount) (*swapState, error) {
 	exchangeRate := types.ExchangeRate(providesAmount.AsMonero() / desiredAmount.AsEther())
 	stage := types.ExpectingKeys
 	if statusCh == nil {
@@ -99,13 +133,19 @@ func newSwapState(b backend.Backend, offer *types.Offer, om *offerManager, statu
 		offer:               offer,
 		offerManager:        om,
 		infoFile:            infoFile,
+		infoDB:              infoDB,
 		nextExpectedMessage: &net.SendKeysMessage{},
 		readyCh:             make(chan struct{}),
+		claimRequests:       make(chan chan<- claimResult),
 		info:                info,
 		statusCh:            statusCh,
 		done:                make(chan struct{}),
 	}
 
+	if err := pcommon.WriteSwapIDToFile(s.infoDB, s.infoFile, offer.GetID()); err != nil {
+		return nil, fmt.Errorf("failed to write swap ID to file: %w", err)
+	}
+
 	return s, nil
 }
 
@@ -123,13 +163,28 @@ func (s *swapState) SendKeysMessage() (*net.SendKeysMessage, error) {
 		return nil, err
 	}
 
+	// the address the swap's ether is claimed to; if it's derived from an HD wallet, record its
+	// index so the claim can still be signed after a restart
+	addr, keyIndex, err := s.SwapEthAddress(s.ID())
+	if err != nil {
+		return nil, err
+	}
+
+	if keyIndex != nil {
+		if err = pcommon.WriteEthereumKeyIndexToFile(s.infoDB, s.infoFile, *keyIndex); err != nil {
+			return nil, err
+		}
+		s.swapAccount = true
+	}
+
 	return &net.SendKeysMessage{
 		ProvidedAmount:     s.info.ProvidedAmount(),
 		PublicSpendKey:     s.pubkeys.SpendKey().Hex(),
 		PrivateViewKey:     s.privkeys.ViewKey().Hex(),
 		DLEqProof:          hex.EncodeToString(s.dleqProof.Proof()),
 		Secp256k1PublicKey: s.secp256k1Pub.String(),
-		EthAddress:         s.EthAddress().String(),
+		EthAddress:         addr.String(),
+		Version:            pcommon.NewVersionInfo(s.Env(), s.ChainID()),
 	}, nil
 }
 
@@ -138,6 +193,23 @@ func (s *swapState) InfoFile() string {
 	return s.infoFile
 }
 
+// SetPeerID records the swap's counterparty in the swap's history.
+func (s *swapState) SetPeerID(id string) {
+	s.info.SetPeerID(id)
+}
+
+// LivenessTimeout returns how long the counterparty may go without being heard from at the
+// swap's current stage, before the network gives up on it.
+func (s *swapState) LivenessTimeout() time.Duration {
+	return pcommon.LivenessTimeout(s.info.Status())
+}
+
+// SignSessionKey signs the network session key for the swap with the secp256k1 key of our DLEq
+// proof, so that the counterparty can check it came from us.
+func (s *swapState) SignSessionKey(key []byte) ([]byte, error) {
+	return pcommon.SignSessionKey(s.getSecret(), key)
+}
+
 // ReceivedAmount returns the amount received, or expected to be received, at the end of the swap
 func (s *swapState) ReceivedAmount() float64 {
 	return s.info.ReceivedAmount()
@@ -161,6 +233,33 @@ func (s *swapState) Exit() error {
 	return s.exit()
 }
 
+// Cancel is called by the swap_cancel RPC endpoint. If we haven't locked our monero yet, the
+// counterparty is notified so that it aborts too, rather than waiting for the stream to close.
+// Otherwise, it's the same as Exit.
+func (s *swapState) Cancel() error {
+	if s == nil {
+		return errNilSwapState
+	}
+
+	s.lockState()
+	defer s.unlockState()
+
+	switch s.nextExpectedMessage.(type) {
+	case *net.SendKeysMessage, *message.NotifyETHLocked:
+		s.notifyAbort("swap cancelled by counterparty")
+	}
+
+	return s.exit()
+}
+
+// notifyAbort tells the counterparty that we're aborting the swap. It's best-effort, as the
+// counterparty aborts anyway once the stream closes.
+func (s *swapState) notifyAbort(reason string) {
+	if err := s.SendSwapMessage(&message.NotifyAbort{Reason: reason}, s.ID()); err != nil {
+		log.Warnf("failed to notify counterparty of abort: %s", err)
+	}
+}
+
 // exit is the same as Exit, but assumes the calling code block already holds the swapState lock.
 func (s *swapState) exit() error {
 	if s == nil {
@@ -182,7 +281,9 @@ func (s *swapState) exit() error {
 
 		if s.info.Status() != types.CompletedSuccess {
 			// re-add offer, as it wasn't taken successfully
-			s.offerManager.putOffer(s.offer)
+			s.offerManager.restoreOffer(s.offer, s.info.ProvidedAmount())
+		} else {
+			s.offerManager.forgetOffer(s.offer.GetID())
 		}
 
 		close(s.done)
@@ -270,23 +371,35 @@ func (s *swapState) reclaimMonero(skA *mcrypto.PrivateSpendKey) (mcrypto.Address
 	kpAB := mcrypto.NewPrivateKeyPair(skAB, vkAB)
 
 	// write keys to file in case something goes wrong
-	if err = pcommon.WriteSharedSwapKeyPairToFile(s.infoFile, kpAB, s.Env()); err != nil {
+	if err = pcommon.WriteSharedSwapKeyPairToFile(s.infoDB, s.infoFile, kpAB, s.Env()); err != nil {
 		return "", err
 	}
 
 	// TODO: check balance
 	s.LockClient()
 	defer s.UnlockClient()
-	return monero.CreateMoneroWallet("xmrmaker-swap-wallet", s.Env(), s, kpAB)
+	addr, err := monero.CreateMoneroWallet("xmrmaker-swap-wallet", s.Env(), s, kpAB)
+	if err != nil {
+		return "", err
+	}
+
+	// other ongoing swaps still lock their funds from our own wallet
+	if s.openWallet != nil {
+		if err = s.openWallet(); err != nil {
+			return "", err
+		}
+	}
+
+	return addr, nil
 }
 
 func (s *swapState) filterForRefund() (*mcrypto.PrivateSpendKey, error) {
 	const refundedEvent = "Refunded"
 
-	logs, err := s.FilterLogs(s.ctx, eth.FilterQuery{
+	logs, err := backend.FilterLogsFrom(s.ctx, s, eth.FilterQuery{
 		Addresses: []ethcommon.Address{s.ContractAddr()},
 		Topics:    [][]ethcommon.Hash{{refundedTopic}},
-	})
+	}, s.contractSwapBlock)
 	if err != nil {
 		return nil, fmt.Errorf("failed to filter logs: %w", err)
 	}
@@ -325,29 +438,6 @@ func (s *swapState) filterForRefund() (*mcrypto.PrivateSpendKey, error) {
 	return sa, nil
 }
 
-func (s *swapState) tryClaim() (ethcommon.Hash, error) {
-	untilT0 := time.Until(s.t0)
-	untilT1 := time.Until(s.t1)
-	stage, err := s.Contract().Swaps(s.CallOpts(), s.contractSwapID)
-	if err != nil {
-		return ethcommon.Hash{}, err
-	}
-
-	if untilT0 > 0 && stage != swapfactory.StageReady {
-		// we need to wait until t0 to claim
-		log.Infof("waiting until time %s to claim, time now=%s", s.t0, time.Now())
-		<-time.After(untilT0 + time.Second)
-	}
-
-	if untilT1 < 0 {
-		// we've passed t1, our only option now is for XMRTaker to refund
-		// and we can regain control of the locked XMR.
-		return ethcommon.Hash{}, errPastClaimTime
-	}
-
-	return s.claimFunds()
-}
-
 // generateKeys generates XMRMaker's spend and view keys (s_b, v_b)
 // It returns XMRMaker's public spend key and his private view key, so that XMRTaker can see
 // if the funds are locked.
@@ -370,13 +460,67 @@ func (s *swapState) generateAndSetKeys() error {
 	s.privkeys = keysAndProof.PrivateKeyPair
 	s.pubkeys = keysAndProof.PublicKeyPair
 
-	return pcommon.WriteKeysToFile(s.infoFile, s.privkeys, s.Env())
+	return pcommon.WriteKeysToFile(s.infoDB, s.infoFile, s.privkeys, s.Env())
 }
 
 func generateKeys() (*pcommon.KeysAndProof, error) {
 	return pcommon.GenerateKeysAndProof()
 }
 
+// waitForOfferConfirmations waits until the transaction with the given receipt has the number of
+// confirmations our offer requires, if it requires more than the backend waits for.
+func (s *swapState) waitForOfferConfirmations(receipt *ethtypes.Receipt) error {
+	if s.offer == nil || s.offer.RequiredConfirmations <= 1 {
+		return nil
+	}
+
+	// the block containing the transaction counts as one confirmation
+	target := receipt.BlockNumber.Uint64() + s.offer.RequiredConfirmations - 1
+	log.Infof("waiting for block %d for transaction %s to have %d confirmations", target, receipt.TxHash,
+		s.offer.RequiredConfirmations)
+
+	for {
+		head, err := s.BlockNumber(s.ctx)
+		if err != nil {
+			return err
+		}
+
+		if head >= target {
+			break
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(confirmationsPollInterval):
+		}
+	}
+
+	latest, err := s.TransactionReceipt(s.ctx, receipt.TxHash)
+	if err != nil {
+		return err
+	}
+
+	if latest.BlockHash != receipt.BlockHash {
+		return errLockTxReorged
+	}
+
+	return nil
+}
+
+// addTx records an ethereum transaction sent for the swap, along with its cost.
+func (s *swapState) addTx(txHash ethcommon.Hash) {
+	s.info.AddTxHash(txHash)
+
+	gasUsed, fee, err := pcommon.GetTxCost(s.ctx, s, txHash)
+	if err != nil {
+		log.Warnf("failed to get the cost of tx %s: %s", txHash, err)
+		return
+	}
+
+	s.info.AddTxCost(gasUsed, fee)
+}
+
 // getSecret secrets returns the current secret scalar used to unlock funds from the contract.
 func (s *swapState) getSecret() [32]byte {
 	secret := s.dleqProof.Secret()
@@ -429,6 +573,15 @@ func (s *swapState) checkContract(txHash ethcommon.Hash) error {
 		return err
 	}
 
+	if err = s.waitForOfferConfirmations(receipt); err != nil {
+		return err
+	}
+
+	s.contractSwapBlock = receipt.BlockNumber.Uint64()
+	if err := pcommon.WriteContractSwapBlockToFile(s.infoDB, s.infoFile, s.contractSwapBlock); err != nil {
+		return err
+	}
+
 	if !bytes.Equal(event.SwapID[:], s.contractSwapID[:]) {
 		return errUnexpectedSwapID
 	}
@@ -456,36 +609,58 @@ func (s *swapState) checkContract(txHash ethcommon.Hash) error {
 	return nil
 }
 
+// xmrLockTx is the transaction that locked our XMR.
+type xmrLockTx struct {
+	*monero.TransferResponse
+	// the wallet's chain height when the transaction was broadcast
+	height uint
+}
+
 // lockFunds locks XMRMaker's funds in the monero account specified by public key
 // (S_a + S_b), viewable with (V_a + V_b)
-// It accepts the amount to lock as the input
+// It accepts the amount to lock as the input, and returns the address and the lock transaction.
 // TODO: units
-func (s *swapState) lockFunds(amount common.MoneroAmount) (mcrypto.Address, error) {
+func (s *swapState) lockFunds(amount common.MoneroAmount) (mcrypto.Address, *xmrLockTx, error) {
 	kp := mcrypto.SumSpendAndViewKeys(s.xmrtakerPublicKeys, s.pubkeys)
 	log.Infof("going to lock XMR funds, amount(piconero)=%d", amount)
 
+	// the client is only locked while we use the wallet, not while we wait for confirmations, so
+	// that other swaps aren't held up
 	s.LockClient()
-	defer s.UnlockClient()
-
 	balance, err := s.GetBalance(0)
 	if err != nil {
-		return "", err
+		s.UnlockClient()
+		return "", nil, err
 	}
 
 	log.Debug("total XMR balance: ", balance.Balance)
 	log.Info("unlocked XMR balance: ", balance.UnlockedBalance)
 
+	// the lock transaction is mined at or above the height it's broadcast at, which the
+	// counterparty scans from
+	lockHeight, err := s.GetHeight()
+	if err != nil {
+		s.UnlockClient()
+		return "", nil, err
+	}
+
 	address := kp.Address(s.Env())
 	txResp, err := s.Transfer(address, 0, uint(amount))
 	if err != nil {
-		return "", err
+		s.UnlockClient()
+		return "", nil, err
 	}
 
-	log.Infof("locked XMR, txHash=%s fee=%d", txResp.TxHash, txResp.Fee)
+	// the status only changes once the transfer is confirmed, but the monero has already left our
+	// unlocked balance, so it mustn't be reserved for this swap any more
+	atomic.StoreInt32(&s.xmrTransferred, 1)
+
+	log.Infof("locked XMR, txHash=%s fee=%d height=%d", txResp.TxHash, txResp.Fee, lockHeight)
 
 	xmrmakerAddr, err := s.GetAddress(0)
+	s.UnlockClient()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	// if we're on a development --regtest node, generate some blocks
@@ -493,25 +668,31 @@ func (s *swapState) lockFunds(amount common.MoneroAmount) (mcrypto.Address, erro
 		_ = s.GenerateBlocks(xmrmakerAddr.Address, 2)
 	} else {
 		// otherwise, wait for new blocks
-		height, err := monero.WaitForBlocks(s, 1)
+		waiter := monero.NewBlockWaiter(s, &monero.WaitConfig{
+			OnProgress: s.info.SetMoneroProgress,
+		})
+		height, err := waiter.WaitForConfirmations(s.ctx, 1)
+		s.info.SetMoneroProgress(0, 0)
 		if err != nil {
-			return "", err
+			return "", nil, err
 		}
 
 		log.Infof("monero block height: %d", height)
 	}
 
+	s.LockClient()
+	defer s.UnlockClient()
 	if err := s.Refresh(); err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	log.Infof("successfully locked XMR funds: address=%s", address)
-	return address, nil
+	return address, &xmrLockTx{TransferResponse: txResp, height: lockHeight}, nil
 }
 
 // claimFunds redeems XMRMaker's ETH funds by calling Claim() on the contract
-func (s *swapState) claimFunds() (ethcommon.Hash, error) {
-	addr := s.EthAddress()
+func (s *swapState) claimFunds(opts ...txsender.SendOption) (ethcommon.Hash, error) {
+	addr := s.contractSwap.Claimer
 
 	balance, err := s.BalanceAt(s.ctx, addr, nil)
 	if err != nil {
@@ -522,12 +703,13 @@ func (s *swapState) claimFunds() (ethcommon.Hash, error) {
 
 	// call swap.Swap.Claim() w/ b.privkeys.sk, revealing XMRMaker's secret spend key
 	sc := s.getSecret()
-	txHash, _, err := s.Claim(s.ID(), s.contractSwap, sc)
+	txHash, _, err := s.Claim(s.ID(), s.contractSwap, sc, opts...)
 	if err != nil {
 		return ethcommon.Hash{}, err
 	}
 
 	log.Infof("sent claim tx, tx hash=%s", txHash)
+	s.addTx(txHash)
 
 	balance, err = s.BalanceAt(s.ctx, addr, nil)
 	if err != nil {
@@ -535,5 +717,7 @@ func (s *swapState) claimFunds() (ethcommon.Hash, error) {
 	}
 
 	log.Infof("balance after claim: %v ETH", common.EtherAmount(*balance).AsEther())
+	s.forwardPayout()
+	s.sweepSwapAccount()
 	return txHash, nil
 }
@@ -105,7 +105,7 @@ func newTestXMRMaker(t *testing.T) *Instance {
 func newTestInstance(t *testing.T) (*Instance, *swapState) {
 	xmrmaker := newTestXMRMaker(t)
 	infoFile := path.Join(t.TempDir(), "test.keys")
-	swapState, err := newSwapState(xmrmaker.backend, &types.Offer{}, xmrmaker.offerManager, nil, infoFile,
+	swapState, err := newSwapState(xmrmaker.backend, &types.Offer{}, xmrmaker.offerManager, nil, infoFile, nil,
 		common.MoneroAmount(33), desiredAmount)
 	require.NoError(t, err)
 	swapState.SetContract(xmrmaker.backend.Contract())
@@ -337,12 +337,24 @@ func TestSwapState_HandleProtocolMessage_NotifyReady(t *testing.T) {
 
 	msg := &message.NotifyReady{}
 
+	// the ether is claimed in the background, and the counterparty notified once it's claimed
 	resp, done, err := s.HandleProtocolMessage(msg)
 	require.NoError(t, err)
-	require.True(t, done)
-	require.NotNil(t, resp)
-	require.Equal(t, message.NotifyClaimedType, resp.Type())
-	require.Equal(t, types.CompletedSuccess, s.info.Status())
+	require.False(t, done)
+	require.Nil(t, resp)
+
+	for status := range s.statusCh {
+		if status == types.CompletedSuccess {
+			break
+		} else if !status.IsOngoing() {
+			t.Fatalf("got wrong exit status %s, expected CompletedSuccess", status)
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return s.Net().(*mockNet).msg != nil
+	}, time.Second*5, time.Millisecond*10)
+	require.Equal(t, message.NotifyClaimedType, s.Net().(*mockNet).msg.Type())
 }
 
 func TestSwapState_handleRefund(t *testing.T) {
@@ -362,7 +374,7 @@ func TestSwapState_handleRefund(t *testing.T) {
 	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), duration)
 
 	// lock XMR
-	addrAB, err := s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
+	addrAB, _, err := s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
 	require.NoError(t, err)
 
 	// call refund w/ XMRTaker's spend key
@@ -397,7 +409,7 @@ func TestSwapState_HandleProtocolMessage_NotifyRefund(t *testing.T) {
 	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), duration)
 
 	// lock XMR
-	_, err = s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
+	_, _, err = s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
 	require.NoError(t, err)
 
 	// call refund w/ XMRTaker's secret
@@ -439,7 +451,7 @@ func TestSwapState_Exit_Reclaim(t *testing.T) {
 	newSwap(t, s, [32]byte{}, refundKey, desiredAmount.BigInt(), duration)
 
 	// lock XMR
-	_, err = s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
+	_, _, err = s.lockFunds(common.MoneroToPiconero(s.info.ProvidedAmount()))
 	require.NoError(t, err)
 
 	// call refund w/ XMRTaker's secret
@@ -515,10 +527,48 @@ func TestSwapState_Exit_Refunded(t *testing.T) {
 		MaximumAmount: 0.2,
 		ExchangeRate:  0.1,
 	}
-	b.MakeOffer(s.offer)
+	b.MakeOffer(s.offer, nil, nil, nil)
 
 	s.info.SetStatus(types.CompletedRefund)
 	err := s.Exit()
 	require.NoError(t, err)
 	require.NotNil(t, b.offerManager.offers[s.offer.GetID()])
 }
+
+func TestSwapState_Cancel_NotifiesAbort(t *testing.T) {
+	_, s := newTestInstance(t)
+	s.nextExpectedMessage = &message.NotifyETHLocked{}
+	err := s.Cancel()
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedAbort, s.info.Status())
+	require.IsType(t, &message.NotifyAbort{}, s.Net().(*mockNet).msg)
+}
+
+func TestSwapState_HandleProtocolMessage_NotifyAbort(t *testing.T) {
+	_, s := newTestInstance(t)
+	s.nextExpectedMessage = &message.NotifyETHLocked{}
+
+	resp, done, err := s.HandleProtocolMessage(&message.NotifyAbort{Reason: "test"})
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Nil(t, resp)
+
+	err = s.Exit()
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedAbort, s.info.Status())
+	require.Nil(t, s.Net().(*mockNet).msg)
+}
+
+func TestSwapState_HandleMessageTimeout(t *testing.T) {
+	_, s := newTestInstance(t)
+	s.nextExpectedMessage = &message.NotifyETHLocked{}
+
+	// the counterparty already sent the message we timed out on
+	s.handleMessageTimeout(message.NotifyXMRLockType)
+	require.True(t, s.info.Status().IsOngoing())
+	require.Nil(t, s.Net().(*mockNet).msg)
+
+	s.handleMessageTimeout(message.NotifyETHLockedType)
+	require.Equal(t, types.CompletedAbort, s.info.Status())
+	require.IsType(t, &message.NotifyAbort{}, s.Net().(*mockNet).msg)
+}
@@ -12,6 +12,7 @@ var (
 	errMissingAddress          = errors.New("did not receive XMRMaker's address")
 	errNoClaimLogsFound        = errors.New("no Claimed logs found")
 	errCannotRefund            = errors.New("swap is not at a stage where it can refund")
+	errRefundNotAllowed        = errors.New("the swap contract doesn't allow refunding yet")
 	errNilMessage              = errors.New("message is nil")
 	errIncorrectMessageType    = errors.New("received unexpected message")
 	errNoLockedXMRAddress      = errors.New("got empty address for locked XMR")
@@ -20,10 +21,20 @@ var (
 	errCounterpartyKeysNotSet  = errors.New("counterparty's keys aren't set")
 	errSwapInstantiationNoLogs = errors.New("expected 1 log, got 0")
 	errSwapCompleted           = errors.New("swap has already completed")
+	errAccountAddressNotString = errors.New("account address is not a string")
+	errLockedXMRTooLow         = errors.New("locked XMR amount is less than expected")
+	errMissingXMRLockProof     = errors.New("counterparty didn't send a proof of the XMR lock")
+	errNoSweptAmounts          = errors.New("sweep all did not return any amounts")
+	errReadyNotManual          = errors.New("swap doesn't need confirmation to be set to ready")
+	errReadyPolicyNotMet       = errors.New("ready policy wasn't met before t0")
 
 	// inititation errors
 	errProtocolAlreadyInProgress = errors.New("protocol already in progress")
 	errBalanceTooLow             = errors.New("eth balance lower than amount to be provided")
 	errNoSwapContractSet         = errors.New("no swap contract found")
 	errMustProvideWalletAddress  = errors.New("must provide wallet address if transfer back is set")
+	errInvalidSweepAddress       = errors.New("invalid XMR sweep address")
+
+	// routing errors
+	errCannotFillRoute = errors.New("offers are insufficient to fill the order")
 )
@@ -9,6 +9,7 @@ import (
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/db"
 	"github.com/noot/atomic-swap/monero"
 	"github.com/noot/atomic-swap/protocol/backend"
 
@@ -31,11 +32,18 @@ type Instance struct {
 
 	walletFile, walletPassword string
 	transferBack               bool // transfer xmr back to original account
+	readyPolicy                ReadyPolicy
 
 	// non-nil if a swap is currently happening, nil otherwise
 	// map of offer IDs -> ongoing swaps
 	swapStates map[types.Hash]*swapState
 	swapMu     sync.Mutex // lock for above map
+
+	// refunds swaps whose ether is locked, independently of the swaps' states
+	refunds *refundScheduler
+
+	// where swaps' info file contents are also stored, if set
+	database db.Database
 }
 
 // Config contains the configuration values for a new XMRTaker instance.
@@ -44,6 +52,12 @@ type Config struct {
 	Basepath                               string
 	MoneroWalletFile, MoneroWalletPassword string
 	TransferBack                           bool
+	// if set, claimed XMR is swept to this address rather than to the wallet opened at startup
+	SweepAddress mcrypto.Address
+	// when to set swaps to ready once the maker's XMR is locked
+	ReadyPolicy ReadyPolicy
+	// Database, if set, is where swaps' info file contents are also stored
+	Database db.Database
 }
 
 // NewInstance returns a new instance of XMRTaker.
@@ -55,7 +69,14 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		err     error
 	)
 
-	if cfg.TransferBack {
+	switch {
+	case cfg.SweepAddress != "":
+		if err = mcrypto.ValidateAddress(string(cfg.SweepAddress)); err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidSweepAddress, err)
+		}
+		cfg.TransferBack = true
+		cfg.Backend.SetBaseXMRDepositAddress(cfg.SweepAddress)
+	case cfg.TransferBack:
 		address, err = getAddress(cfg.Backend, cfg.MoneroWalletFile, cfg.MoneroWalletPassword)
 		if err != nil {
 			return nil, err
@@ -69,7 +90,11 @@ func NewInstance(cfg *Config) (*Instance, error) {
 		basepath:       cfg.Basepath,
 		walletFile:     cfg.MoneroWalletFile,
 		walletPassword: cfg.MoneroWalletPassword,
+		transferBack:   cfg.TransferBack,
 		swapStates:     make(map[types.Hash]*swapState),
+		readyPolicy:    cfg.ReadyPolicy,
+		refunds:        newRefundScheduler(cfg.Backend.Ctx()),
+		database:       cfg.Database,
 	}, nil
 }
 
@@ -107,11 +132,8 @@ func getAddress(walletClient monero.Client, file, password string) (mcrypto.Addr
 // Refund is called by the RPC function swap_refund.
 // If it's possible to refund the ongoing swap, it does that, then notifies the counterparty.
 func (a *Instance) Refund(offerID types.Hash) (ethcommon.Hash, error) {
-	a.swapMu.Lock()
-	defer a.swapMu.Unlock()
-
-	s, has := a.swapStates[offerID]
-	if !has {
+	s := a.getSwapState(offerID)
+	if s == nil {
 		return ethcommon.Hash{}, errNoOngoingSwap
 	}
 
@@ -120,5 +142,16 @@ func (a *Instance) Refund(offerID types.Hash) (ethcommon.Hash, error) {
 
 // GetOngoingSwapState ...
 func (a *Instance) GetOngoingSwapState(offerID types.Hash) common.SwapState {
+	s := a.getSwapState(offerID)
+	if s == nil {
+		return nil
+	}
+
+	return s
+}
+
+func (a *Instance) getSwapState(offerID types.Hash) *swapState {
+	a.swapMu.Lock()
+	defer a.swapMu.Unlock()
 	return a.swapStates[offerID]
 }
@@ -1,6 +1,7 @@
 package xmrtaker
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/noot/atomic-swap/monero"
@@ -18,3 +19,10 @@ func TestGetAddress(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, addr, addr2)
 }
+
+func TestNewInstance_InvalidSweepAddress(t *testing.T) {
+	_, err := NewInstance(&Config{
+		SweepAddress: "notanaddress",
+	})
+	require.True(t, errors.Is(err, errInvalidSweepAddress))
+}
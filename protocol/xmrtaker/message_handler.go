@@ -4,10 +4,8 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
-	"github.com/noot/atomic-swap/monero"
 	"github.com/noot/atomic-swap/net"
 	"github.com/noot/atomic-swap/net/message"
 	pcommon "github.com/noot/atomic-swap/protocol"
@@ -52,6 +50,10 @@ func (s *swapState) HandleProtocolMessage(msg net.Message) (net.Message, bool, e
 
 		s.clearNextExpectedMessage(types.CompletedSuccess)
 		return nil, true, nil
+	case *message.NotifyAbort:
+		// exiting aborts the swap, or refunds if we already locked our ether
+		log.Infof("counterparty aborted swap %s: %s", s.ID(), msg.Reason)
+		return nil, true, nil
 	default:
 		return nil, false, errUnexpectedMessageType
 	}
@@ -75,14 +77,81 @@ func (s *swapState) setNextExpectedMessage(msg net.Message) {
 	}
 
 	s.nextExpectedMessage = msg
+	s.watchMessageDeadline(msg)
 
 	// TODO: check stage is not unknown (ie. swap completed)
 	stage := pcommon.GetStatus(msg.Type())
+	if stage != types.UnknownStatus {
+		s.info.SetStatus(stage)
+	}
+
 	if s.statusCh != nil {
 		s.statusCh <- stage
 	}
 }
 
+// watchMessageDeadline gives up on the counterparty if it doesn't send msg in time. As we need to
+// refund before t0 if XMRMaker never locks its XMR, the deadline for NotifyXMRLock is at most t0.
+func (s *swapState) watchMessageDeadline(msg net.Message) {
+	timeout := pcommon.MessageTimeout(s.Env(), msg.Type())
+	if _, ok := msg.(*message.NotifyXMRLock); ok {
+		if untilT0 := time.Until(s.t0) - refundT0Buffer; timeout == 0 || untilT0 < timeout {
+			timeout = untilT0
+		}
+	} else if timeout == 0 {
+		return
+	}
+
+	log.Debugf("waiting up to %vs for %s", timeout.Seconds(), msg.Type())
+
+	go func() {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(timeout):
+			s.handleMessageTimeout(msg.Type())
+		}
+	}()
+}
+
+// handleMessageTimeout exits the swap if we're still waiting for a message of type t.
+func (s *swapState) handleMessageTimeout(t message.Type) {
+	s.lockState()
+	defer s.unlockState()
+
+	if s.nextExpectedMessage == nil || s.nextExpectedMessage.Type() != t {
+		return
+	}
+
+	log.Warnf("counterparty didn't send %s in time, exiting swap %s", t, s.ID())
+
+	switch s.nextExpectedMessage.(type) {
+	case *net.SendKeysMessage:
+		s.notifyAbort(fmt.Sprintf("timed out waiting for %s", t))
+	case *message.NotifyXMRLock:
+		// XMRMaker hasn't locked yet, let's call refund
+		txHash, err := s.refund()
+		if err != nil {
+			log.Errorf("failed to refund: err=%s", err)
+			return
+		}
+
+		log.Infof("got our ETH back: tx hash=%s", txHash)
+		s.clearNextExpectedMessage(types.CompletedRefund)
+
+		// let XMRMaker know, so it can reclaim its XMR if it did lock it after all
+		if err := s.SendSwapMessage(&message.NotifyRefund{
+			TxHash: txHash.String(),
+		}, s.ID()); err != nil {
+			log.Errorf("failed to send refund message: err=%s", err)
+		}
+	}
+
+	if err := s.exit(); err != nil {
+		log.Errorf("failed to exit swap: err=%s", err)
+	}
+}
+
 func (s *swapState) checkMessageType(msg net.Message) error {
 	if msg == nil {
 		return errNilMessage
@@ -92,6 +161,11 @@ func (s *swapState) checkMessageType(msg net.Message) error {
 		return nil
 	}
 
+	// XMRMaker may abort anytime before locking their XMR.
+	if _, ok := msg.(*message.NotifyAbort); ok {
+		return nil
+	}
+
 	if msg.Type() != s.nextExpectedMessage.Type() {
 		return errIncorrectMessageType
 	}
@@ -100,6 +174,12 @@ func (s *swapState) checkMessageType(msg net.Message) error {
 }
 
 func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) (net.Message, error) {
+	s.info.SetPeerVersion(msg.Version)
+	log.Infof("counterparty for swap %s is running version %s", s.info.ID(), msg.Version)
+	if msg.RemainderOfferID != "" {
+		log.Infof("offer partially taken; the rest of it is listed as offer %s", msg.RemainderOfferID)
+	}
+
 	if msg.ProvidedAmount < s.info.ReceivedAmount() {
 		return nil, fmt.Errorf("receiving amount is not the same as expected: got %v, expected %v",
 			msg.ProvidedAmount,
@@ -107,6 +187,11 @@ func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) (net.Message
 		)
 	}
 
+	// the counterparty's amount is what we'll actually receive, so check it before locking our ether
+	if err := s.slippage.Check(s.info.ProvidedAmount(), msg.ProvidedAmount); err != nil {
+		return nil, err
+	}
+
 	if msg.PublicSpendKey == "" || msg.PrivateViewKey == "" {
 		return nil, errMissingKeys
 	}
@@ -135,6 +220,10 @@ func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) (net.Message
 		return nil, err
 	}
 
+	if err = pcommon.WriteCounterpartyKeysToFile(s.infoDB, s.infoFile, msg); err != nil {
+		return nil, fmt.Errorf("failed to write counterparty keys to file: %w", err)
+	}
+
 	log.Infof(color.New(color.Bold).Sprintf("receiving %v XMR for %v ETH", msg.ProvidedAmount, s.info.ProvidedAmount()))
 
 	s.setXMRMakerKeys(sk, vk, secp256k1Pub)
@@ -145,48 +234,6 @@ func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) (net.Message
 
 	log.Info("locked ether in swap contract, waiting for XMR to be locked")
 
-	// start goroutine to check that XMRMaker locks before t_0
-	go func() {
-		// TODO: this variable is so that we definitely refund before t0.
-		// this will vary based on environment (eg. development should be very small,
-		// a network with slower block times should be longer)
-		const timeoutBuffer = time.Second * 5
-		until := time.Until(s.t0)
-
-		log.Debugf("time until refund: %vs", until.Seconds())
-
-		select {
-		case <-s.ctx.Done():
-			return
-		case <-time.After(until - timeoutBuffer):
-			s.lockState()
-			defer s.unlockState()
-
-			if !s.info.Status().IsOngoing() {
-				return
-			}
-
-			// XMRMaker hasn't locked yet, let's call refund
-			txhash, err := s.refund()
-			if err != nil {
-				log.Errorf("failed to refund: err=%s", err)
-				return
-			}
-
-			log.Infof("got our ETH back: tx hash=%s", txhash)
-
-			// send NotifyRefund msg
-			if err := s.SendSwapMessage(&message.NotifyRefund{
-				TxHash: txhash.String(),
-			}, s.ID()); err != nil {
-				log.Errorf("failed to send refund message: err=%s", err)
-			}
-		case <-s.xmrLockedCh:
-			return
-		}
-
-	}()
-
 	s.setNextExpectedMessage(&message.NotifyXMRLock{})
 
 	out := &message.NotifyETHLocked{
@@ -200,113 +247,81 @@ func (s *swapState) handleSendKeysMessage(msg *net.SendKeysMessage) (net.Message
 }
 
 func (s *swapState) handleNotifyXMRLock(msg *message.NotifyXMRLock) (net.Message, error) {
+	notifiedAt := time.Now()
 	if msg.Address == "" {
 		return nil, errNoLockedXMRAddress
 	}
 
+	if err := s.verifyXMRLock(msg); err != nil {
+		return nil, err
+	}
+
+	if err := s.waitForReadyPolicy(notifiedAt); err != nil {
+		return nil, err
+	}
+
+	log.Info("XMR was locked successfully, setting contract to ready...")
+
+	if err := s.ready(); err != nil {
+		return nil, fmt.Errorf("failed to call Ready: %w", err)
+	}
+
+	s.setNextExpectedMessage(&message.NotifyClaimed{})
+	return &message.NotifyReady{}, nil
+}
+
+// verifyXMRLock checks that XMRMaker locked the XMR we expect in the swap's account.
+func (s *swapState) verifyXMRLock(msg *message.NotifyXMRLock) error {
 	// check that XMR was locked in expected account, and confirm amount
 	vk := mcrypto.SumPrivateViewKeys(s.xmrmakerPrivateViewKey, s.privkeys.ViewKey())
 	sk := mcrypto.SumPublicKeys(s.xmrmakerPublicSpendKey, s.pubkeys.SpendKey())
 	kp := mcrypto.NewPublicKeyPair(sk, vk.Public())
 
 	if msg.Address != string(kp.Address(s.Env())) {
-		return nil, fmt.Errorf("address received in message does not match expected address")
+		return fmt.Errorf("address received in message does not match expected address")
 	}
 
 	s.LockClient()
 	defer s.UnlockClient()
 
-	t := time.Now().Format("2006-01-02-15:04:05.999999999")
-	walletName := fmt.Sprintf("xmrtaker-viewonly-wallet-%s", t)
-	if err := s.GenerateViewOnlyWalletFromKeys(vk, kp.Address(s.Env()), walletName, ""); err != nil {
-		return nil, fmt.Errorf("failed to generate view-only wallet to verify locked XMR: %w", err)
-	}
-
-	log.Debugf("generated view-only wallet to check funds: %s", walletName)
-
-	if s.Env() != common.Development {
-		log.Infof("waiting for new blocks...")
-		// wait for 2 new blocks, otherwise balance might be 0
-		// TODO: check transaction hash
-		height, err := monero.WaitForBlocks(s.Backend, 2)
-		if err != nil {
-			return nil, err
-		}
-
-		log.Infof("monero block height: %d", height)
+	height, err := s.GetHeight()
+	if err != nil {
+		return fmt.Errorf("failed to get monero height: %w", err)
 	}
 
-	log.Debug("refreshing client...")
-
-	if err := s.Refresh(); err != nil {
-		return nil, fmt.Errorf("failed to refresh client: %w", err)
+	// if we know the height the lock was broadcast at, the view-only wallet only needs to scan
+	// the chain from there
+	lockHeight := plausibleLockHeight(msg.Height, uint64(height))
+	var restoreHeight uint64
+	if lockHeight > lockHeightTolerance {
+		restoreHeight = lockHeight - lockHeightTolerance
 	}
 
-	accounts, err := s.GetAccounts()
+	t := time.Now().Format("2006-01-02-15:04:05.999999999")
+	walletName := fmt.Sprintf("xmrtaker-viewonly-wallet-%s", t)
+	err = s.GenerateViewOnlyWalletFromKeys(vk, kp.Address(s.Env()), restoreHeight, walletName, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get accounts: %w", err)
+		return fmt.Errorf("failed to generate view-only wallet to verify locked XMR: %w", err)
 	}
 
-	var (
-		balance *monero.GetBalanceResponse
-	)
-
-	for i, acc := range accounts.SubaddressAccounts {
-		addr, ok := acc["base_address"].(string)
-		if !ok {
-			panic("address is not a string!")
-		}
-
-		if mcrypto.Address(addr) == kp.Address(s.Env()) {
-			balance, err = s.GetBalance(uint(i))
-			if err != nil {
-				return nil, fmt.Errorf("failed to get balance: %w", err)
-			}
+	log.Debugf("generated view-only wallet to check funds: %s restoreHeight=%d", walletName, restoreHeight)
 
-			break
-		}
+	if err := s.verifyXMRLockProof(msg, kp.Address(s.Env())); err != nil {
+		return err
 	}
 
-	if balance == nil {
-		return nil, fmt.Errorf("failed to find account with address %s", kp.Address(s.Env()))
+	lock, err := s.waitForXMRLock(kp.Address(s.Env()), lockHeight)
+	if err != nil {
+		return err
 	}
 
-	log.Debugf("checking locked wallet, address=%s balance=%v", kp.Address(s.Env()), balance.Balance)
-
-	// TODO: also check that the balance isn't unlocked only after an unreasonable amount of blocks
-	if balance.Balance < float64(s.receivedAmountInPiconero()) {
-		return nil, fmt.Errorf("locked XMR amount is less than expected: got %v, expected %v",
-			balance.Balance, float64(s.receivedAmountInPiconero()))
-	}
+	log.Infof("verified locked XMR: address=%s amount=%d txs=%v", kp.Address(s.Env()), lock.confirmed, lock.txIDs)
 
 	if err := s.CloseWallet(); err != nil {
-		return nil, fmt.Errorf("failed to close wallet: %w", err)
-	}
-
-	close(s.xmrLockedCh)
-	log.Info("XMR was locked successfully, setting contract to ready...")
-
-	if err := s.ready(); err != nil {
-		return nil, fmt.Errorf("failed to call Ready: %w", err)
+		return fmt.Errorf("failed to close wallet: %w", err)
 	}
 
-	go func() {
-		until := time.Until(s.t1)
-
-		select {
-		case <-s.ctx.Done():
-			return
-		// TODO: document why we add one second
-		case <-time.After(until + time.Second):
-			s.handleT1Expired()
-			return
-		case <-s.claimedCh:
-			return
-		}
-	}()
-
-	s.setNextExpectedMessage(&message.NotifyClaimed{})
-	return &message.NotifyReady{}, nil
+	return nil
 }
 
 func (s *swapState) handleT1Expired() {
@@ -4,6 +4,8 @@ import (
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/protocol/preflight"
 
 	"github.com/fatih/color" //nolint:misspell
 )
@@ -14,50 +16,106 @@ func (a *Instance) Provides() types.ProvidesCoin {
 }
 
 // InitiateProtocol is called when an RPC call is made from the user to initiate a swap.
-// The input units are ether that we will provide.
-func (a *Instance) InitiateProtocol(providesAmount float64, offer *types.Offer) (common.SwapState, error) {
+// The input units are ether that we will provide. The speed tier is the name of one of the
+// offer's speed tiers; if empty, the offer's first tier (if any) is used. If limits is non-nil,
+// the swap is aborted before our ether is locked if its terms are outside them.
+func (a *Instance) InitiateProtocol(providesAmount float64, offer *types.Offer,
+	speedTier string, limits *types.SlippageLimits) (common.SwapState, error) {
+	if err := offer.CheckTake(providesAmount); err != nil {
+		return nil, err
+	}
+
+	tier, err := offer.GetSpeedTier(speedTier)
+	if err != nil {
+		return nil, err
+	}
+
 	receivedAmount := offer.ExchangeRate.ToXMR(providesAmount)
-	err := a.initiate(common.EtherToWei(providesAmount), common.MoneroToPiconero(receivedAmount),
-		offer.ExchangeRate, offer.GetID())
+	if err = limits.Check(providesAmount, receivedAmount); err != nil {
+		return nil, err
+	}
+
+	s, err := a.initiate(common.EtherToWei(providesAmount), common.MoneroToPiconero(receivedAmount),
+		offer.ExchangeRate, offer.GetID(), tier, limits)
 	if err != nil {
 		return nil, err
 	}
 
-	return a.swapStates[offer.GetID()], nil
+	a.backend.SwapManager().Events().Publish(&events.Event{
+		Type:   events.OfferTaken,
+		SwapID: offer.GetID(),
+		Status: s.info.Status(),
+		Offer:  offer,
+	})
+	return s, nil
 }
 
+// initiate creates the state for a new swap. Swaps run concurrently, but initiations are
+// serialised, so each one sees the ether reserved by the others.
 func (a *Instance) initiate(providesAmount common.EtherAmount, receivedAmount common.MoneroAmount,
-	exchangeRate types.ExchangeRate, offerID types.Hash) error {
+	exchangeRate types.ExchangeRate, offerID types.Hash, tier *types.SpeedTier,
+	limits *types.SlippageLimits) (*swapState, error) {
 	a.swapMu.Lock()
 	defer a.swapMu.Unlock()
 
 	if a.swapStates[offerID] != nil {
-		return errProtocolAlreadyInProgress
+		return nil, errProtocolAlreadyInProgress
 	}
 
 	balance, err := a.backend.BalanceAt(a.backend.Ctx(), a.backend.EthAddress(), nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// check user's balance and that they actually have what they will provide, on top of what
+	// ongoing swaps will lock
+	needed := common.EtherToWei(a.reservedAmount() + providesAmount.AsEther())
+	if balance.Cmp(needed.BigInt()) <= 0 {
+		return nil, errBalanceTooLow
+	}
+
+	report, err := preflight.Run(a.backend.Ctx(), a.backend, types.ProvidesETH, needed.AsEther())
+	if err != nil {
+		return nil, err
 	}
 
-	// check user's balance and that they actually have what they will provide
-	if balance.Cmp(providesAmount.BigInt()) <= 0 {
-		return errBalanceTooLow
+	if err = report.Err(); err != nil {
+		return nil, err
 	}
 
-	s, err := newSwapState(a.backend, offerID, pcommon.GetSwapInfoFilepath(a.basepath), a.transferBack,
-		providesAmount, receivedAmount, exchangeRate)
+	s, err := newSwapState(a.backend, offerID, pcommon.GetSwapInfoFilepath(a.basepath), a.database, a.transferBack,
+		providesAmount, receivedAmount, exchangeRate, a.refunds)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	s.speedTier = tier
+	s.readyPolicy = a.readyPolicy
+	s.slippage = limits
+
 	go func() {
 		<-s.done
+		a.swapMu.Lock()
+		defer a.swapMu.Unlock()
 		delete(a.swapStates, offerID)
 	}()
 
 	log.Info(color.New(color.Bold).Sprintf("**initiated swap with ID=%s**", s.info.ID()))
 	log.Info(color.New(color.Bold).Sprint("DO NOT EXIT THIS PROCESS OR FUNDS MAY BE LOST!"))
 	a.swapStates[offerID] = s
-	return nil
+	return s, nil
+}
+
+// reservedAmount returns the ether, in standard units, that ongoing swaps will lock but haven't
+// yet; it's still part of our balance. It must be called with swapMu held.
+func (a *Instance) reservedAmount() float64 {
+	var reserved float64
+	for _, s := range a.swapStates {
+		switch s.info.Status() {
+		case types.ExpectingKeys, types.KeysExchanged:
+			reserved += s.info.ProvidedAmount()
+		}
+	}
+
+	return reserved
 }
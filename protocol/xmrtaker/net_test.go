@@ -2,10 +2,12 @@ package xmrtaker
 
 import (
 	"path"
+	"sync"
 	"testing"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
+	pswap "github.com/noot/atomic-swap/protocol/swap"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/stretchr/testify/require"
@@ -32,7 +34,91 @@ func TestXMRTaker_InitiateProtocol(t *testing.T) {
 	offer := &types.Offer{
 		ExchangeRate: 1,
 	}
-	s, err := a.InitiateProtocol(3.33, offer)
+	s, err := a.InitiateProtocol(3.33, offer, "", nil)
 	require.NoError(t, err)
 	require.Equal(t, a.swapStates[offer.GetID()], s)
 }
+
+func TestXMRTaker_InitiateProtocol_SpeedTier(t *testing.T) {
+	a := newTestXMRTaker(t)
+	fast := &types.SpeedTier{Name: "fast", MoneroConfirmations: 5, Timeout: 600}
+	offer := &types.Offer{
+		ExchangeRate: 1,
+		SpeedTiers:   []*types.SpeedTier{fast},
+	}
+
+	_, err := a.InitiateProtocol(3.33, offer, "cheap", nil)
+	require.Error(t, err)
+
+	s, err := a.InitiateProtocol(3.33, offer, "fast", nil)
+	require.NoError(t, err)
+	ss := s.(*swapState)
+	require.Equal(t, fast.TimeoutDuration(), ss.timeoutDuration())
+	require.Equal(t, uint64(5), ss.moneroConfirmations())
+
+	skm, err := ss.SendKeysMessage()
+	require.NoError(t, err)
+	require.Equal(t, "fast", skm.SpeedTier)
+}
+
+func TestXMRTaker_InitiateProtocol_Concurrent(t *testing.T) {
+	a := newTestXMRTaker(t)
+
+	const num = 4
+	states := make([]*swapState, num)
+	var wg sync.WaitGroup
+	for i := 0; i < num; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			offer := &types.Offer{
+				Provides:     types.ProvidesXMR,
+				ExchangeRate: types.ExchangeRate(i + 1),
+			}
+			s, err := a.InitiateProtocol(0.01, offer, "", nil)
+			require.NoError(t, err)
+			states[i] = s.(*swapState)
+		}(i)
+	}
+	wg.Wait()
+
+	infofiles := make(map[string]struct{})
+	keys := make(map[string]struct{})
+	for _, s := range states {
+		require.Equal(t, s, a.GetOngoingSwapState(s.info.ID()))
+		infofiles[s.InfoFile()] = struct{}{}
+		keys[s.pubkeys.SpendKey().Hex()] = struct{}{}
+	}
+	require.Len(t, infofiles, num)
+	require.Len(t, keys, num)
+}
+
+func TestXMRTaker_ReservedAmount(t *testing.T) {
+	a := &Instance{
+		swapStates: map[types.Hash]*swapState{
+			{1}: {info: pswap.NewInfo(types.Hash{1}, types.ProvidesETH, 1, 1, 1, types.ExpectingKeys, nil)},
+			{2}: {info: pswap.NewInfo(types.Hash{2}, types.ProvidesETH, 2, 2, 1, types.KeysExchanged, nil)},
+			{3}: {info: pswap.NewInfo(types.Hash{3}, types.ProvidesETH, 4, 4, 1, types.ETHLocked, nil)},
+		},
+	}
+
+	// ether that's already locked is no longer part of our balance
+	require.Equal(t, float64(3), a.reservedAmount())
+}
+
+func TestXMRTaker_InitiateProtocol_Slippage(t *testing.T) {
+	a := &Instance{
+		swapStates: make(map[types.Hash]*swapState),
+	}
+	offer := &types.Offer{
+		ExchangeRate: 0.1,
+	}
+
+	// the offer's rate is worse than the taker accepts, so the swap isn't started
+	_, err := a.InitiateProtocol(1, offer, "", &types.SlippageLimits{MaxExchangeRate: 0.05})
+	require.Error(t, err)
+
+	_, err = a.InitiateProtocol(1, offer, "", &types.SlippageLimits{MinReceivedXMR: 11})
+	require.Error(t, err)
+	require.Empty(t, a.swapStates)
+}
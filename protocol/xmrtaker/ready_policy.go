@@ -0,0 +1,72 @@
+package xmrtaker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// how long before t0 we give up waiting to set the contract to ready, and refund instead
+const readyDeadlineBuffer = time.Second * 5
+
+// ReadyPolicy decides when we set the contract to ready, after which XMRMaker can claim our
+// ether. Whatever the policy, the contract is only set to ready once XMRMaker's locked XMR is
+// verified, and never after t0; if the policy isn't met by then, the swap is refunded.
+type ReadyPolicy struct {
+	// MoneroConfirmations is the minimum number of confirmations of XMRMaker's locked XMR,
+	// whatever the swap's speed tier.
+	MoneroConfirmations uint64
+	// MinDelay is the minimum time between XMRMaker notifying us of its lock and setting ready.
+	MinDelay time.Duration
+	// Manual, if set, makes us wait for ConfirmReady to be called for the swap.
+	Manual bool
+}
+
+// ConfirmReady allows the swap with the given ID to be set to ready, if the ready policy
+// requires manual confirmation. It can be called before XMRMaker locks its XMR.
+func (a *Instance) ConfirmReady(offerID types.Hash) error {
+	s := a.getSwapState(offerID)
+	if s == nil {
+		return errNoOngoingSwap
+	}
+
+	if !s.readyPolicy.Manual {
+		return errReadyNotManual
+	}
+
+	s.readyConfirmedOnce.Do(func() {
+		close(s.readyConfirmedCh)
+	})
+	log.Infof("confirmed swap %s can be set to ready", offerID)
+	return nil
+}
+
+// waitForReadyPolicy waits until the ready policy allows the contract to be set to ready,
+// given that XMRMaker notified us of its lock at the given time.
+func (s *swapState) waitForReadyPolicy(notifiedAt time.Time) error {
+	ctx, cancel := context.WithDeadline(s.ctx, s.t0.Add(-readyDeadlineBuffer))
+	defer cancel()
+
+	if wait := time.Until(notifiedAt.Add(s.readyPolicy.MinDelay)); wait > 0 {
+		log.Infof("waiting %s before setting swap %s to ready", wait, s.ID())
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s", errReadyPolicyNotMet, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+
+	if !s.readyPolicy.Manual {
+		return nil
+	}
+
+	log.Infof("waiting for confirmation to set swap %s to ready", s.ID())
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %s", errReadyPolicyNotMet, ctx.Err())
+	case <-s.readyConfirmedCh:
+		return nil
+	}
+}
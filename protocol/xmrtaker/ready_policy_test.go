@@ -0,0 +1,69 @@
+package xmrtaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestReadySwapState(policy ReadyPolicy, untilT0 time.Duration) *swapState {
+	return &swapState{
+		ctx:              context.Background(),
+		info:             swap.NewInfo(types.Hash{}, types.ProvidesETH, 1, 1, 1, types.XMRLocked, nil),
+		t0:               time.Now().Add(untilT0),
+		readyPolicy:      policy,
+		readyConfirmedCh: make(chan struct{}),
+	}
+}
+
+func TestSwapState_WaitForReadyPolicy_MinDelay(t *testing.T) {
+	s := newTestReadySwapState(ReadyPolicy{MinDelay: time.Millisecond * 200}, time.Hour)
+
+	start := time.Now()
+	require.NoError(t, s.waitForReadyPolicy(start))
+	require.GreaterOrEqual(t, time.Since(start), time.Millisecond*200)
+
+	// the delay counts from when we were notified, so it may already have passed
+	start = time.Now()
+	require.NoError(t, s.waitForReadyPolicy(start.Add(-time.Second)))
+	require.Less(t, time.Since(start), time.Millisecond*200)
+}
+
+func TestSwapState_WaitForReadyPolicy_Manual(t *testing.T) {
+	s := newTestReadySwapState(ReadyPolicy{Manual: true}, time.Hour)
+
+	go func() {
+		time.Sleep(time.Millisecond * 100)
+		s.readyConfirmedOnce.Do(func() {
+			close(s.readyConfirmedCh)
+		})
+	}()
+
+	require.NoError(t, s.waitForReadyPolicy(time.Now()))
+}
+
+func TestSwapState_WaitForReadyPolicy_T0(t *testing.T) {
+	s := newTestReadySwapState(ReadyPolicy{Manual: true}, readyDeadlineBuffer+time.Millisecond*100)
+	err := s.waitForReadyPolicy(time.Now())
+	require.True(t, errors.Is(err, errReadyPolicyNotMet))
+}
+
+func TestInstance_ConfirmReady(t *testing.T) {
+	a := &Instance{swapStates: make(map[types.Hash]*swapState)}
+	require.Equal(t, errNoOngoingSwap, a.ConfirmReady(types.Hash{}))
+
+	s := newTestReadySwapState(ReadyPolicy{}, time.Hour)
+	a.swapStates[types.Hash{}] = s
+	require.Equal(t, errReadyNotManual, a.ConfirmReady(types.Hash{}))
+
+	s.readyPolicy.Manual = true
+	require.NoError(t, a.ConfirmReady(types.Hash{}))
+	require.NoError(t, a.ConfirmReady(types.Hash{}))
+	<-s.readyConfirmedCh
+}
@@ -25,8 +25,10 @@ type recoveryState struct {
 
 // NewRecoveryState returns a new *xmrmaker.recoveryState,
 // which has methods to either claim ether or reclaim monero from an initiated swap.
+// contractSwapBlock is the block the swap was created in, or zero if it's unknown.
 func NewRecoveryState(b backend.Backend, basePath string, secret *mcrypto.PrivateSpendKey,
-	contractSwapID [32]byte, contractSwap swapfactory.SwapFactorySwap) (*recoveryState, error) {
+	contractSwapID [32]byte, contractSwap swapfactory.SwapFactorySwap,
+	contractSwapBlock uint64) (*recoveryState, error) {
 	kp, err := secret.AsPrivateKeyPair()
 	if err != nil {
 		return nil, err
@@ -39,16 +41,16 @@ func NewRecoveryState(b backend.Backend, basePath string, secret *mcrypto.Privat
 
 	ctx, cancel := context.WithCancel(b.Ctx())
 	s := &swapState{
-		ctx:            ctx,
-		cancel:         cancel,
-		Backend:        b,
-		privkeys:       kp,
-		pubkeys:        pubkp,
-		dleqProof:      dleq.NewProofWithSecret(sc),
-		contractSwapID: contractSwapID,
-		contractSwap:   contractSwap,
-		infoFile:       pcommon.GetSwapRecoveryFilepath(basePath),
-		claimedCh:      make(chan struct{}),
+		ctx:               ctx,
+		cancel:            cancel,
+		Backend:           b,
+		privkeys:          kp,
+		pubkeys:           pubkp,
+		dleqProof:         dleq.NewProofWithSecret(sc),
+		contractSwapID:    contractSwapID,
+		contractSwap:      contractSwap,
+		contractSwapBlock: contractSwapBlock,
+		infoFile:          pcommon.GetSwapRecoveryFilepath(basePath),
 	}
 
 	rs := &recoveryState{
@@ -111,10 +113,10 @@ func (rs *recoveryState) ClaimOrRefund() (*RecoveryResult, error) {
 func (s *swapState) filterForClaim() (*mcrypto.PrivateSpendKey, error) {
 	const claimedEvent = "Claimed"
 
-	logs, err := s.FilterLogs(s.ctx, eth.FilterQuery{
+	logs, err := backend.FilterLogsFrom(s.ctx, s, eth.FilterQuery{
 		Addresses: []ethcommon.Address{s.ContractAddr()},
 		Topics:    [][]ethcommon.Hash{{claimedTopic}},
-	})
+	}, s.contractSwapBlock)
 	if err != nil {
 		return nil, fmt.Errorf("failed to filter logs: %w", err)
 	}
@@ -1,12 +1,21 @@
 package xmrtaker
 
 import (
+	"context"
+	"math/big"
 	"path"
 	"testing"
 	"time"
 
 	"github.com/noot/atomic-swap/common"
-
+	"github.com/noot/atomic-swap/protocol/backend"
+	pswap "github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/tests"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stretchr/testify/require"
 )
@@ -29,7 +38,8 @@ func newTestRecoveryState(t *testing.T) *recoveryState {
 	require.NoError(t, err)
 
 	basePath := path.Join(t.TempDir(), "test-infoFile")
-	rs, err := NewRecoveryState(s, basePath, s.privkeys.SpendKey(), s.contractSwapID, s.contractSwap)
+	rs, err := NewRecoveryState(s, basePath, s.privkeys.SpendKey(), s.contractSwapID, s.contractSwap,
+		s.contractSwapBlock)
 	require.NoError(t, err)
 	return rs
 }
@@ -96,3 +106,87 @@ func TestClaimOrRefund_Refund_afterT1(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, res.Refunded)
 }
+
+func TestClaimOrRefund_Refund_ExternalSigner(t *testing.T) {
+	// test case where the daemon has no ethereum private key, so the refund must be
+	// signed by the external signer.
+	rs := newTestRecoveryState(t)
+
+	ec, err := ethclient.Dial(common.DefaultEthEndpoint)
+	require.NoError(t, err)
+	defer ec.Close()
+
+	b, err := backend.NewBackend(&backend.Config{
+		Ctx:                  context.Background(),
+		MoneroWalletEndpoint: common.DefaultXMRTakerMoneroEndpoint,
+		MoneroDaemonEndpoint: common.DefaultMoneroDaemonEndpoint,
+		EthereumClient:       ec,
+		Environment:          common.Development,
+		ChainID:              big.NewInt(common.DevelopmentConfig.EthereumChainID),
+		SwapManager:          pswap.NewManager(),
+		SwapContract:         rs.ss.Contract(),
+		SwapContractAddress:  rs.ss.ContractAddr(),
+		Net:                  new(mockNet),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, b.ExternalSender())
+
+	_, err = b.TxOpts()
+	require.Error(t, err)
+
+	keylessRS, err := NewRecoveryState(b, path.Join(t.TempDir(), "test-infoFile"), rs.ss.privkeys.SpendKey(),
+		rs.ss.contractSwapID, rs.ss.contractSwap, rs.ss.contractSwapBlock)
+	require.NoError(t, err)
+
+	type result struct {
+		res *RecoveryResult
+		err error
+	}
+	resCh := make(chan result, 1)
+	go func() {
+		res, err := keylessRS.ClaimOrRefund()
+		resCh <- result{res, err}
+	}()
+
+	// act as the front-end signer for the recovered swap, which is identified by its on-chain ID
+	pk, err := ethcrypto.HexToECDSA(tests.GetTakerTestKey(t))
+	require.NoError(t, err)
+	from := common.EthereumPrivateKeyToAddress(pk)
+
+	es := b.ExternalSender()
+	var disconnected func()
+	require.Eventually(t, func() bool {
+		disconnected, err = es.SignerConnected(rs.ss.contractSwapID, from)
+		return err == nil
+	}, time.Second*10, time.Millisecond*10)
+	defer disconnected()
+
+	outCh, err := es.OngoingCh(rs.ss.contractSwapID)
+	require.NoError(t, err)
+	inCh, err := es.IncomingCh(rs.ss.contractSwapID)
+	require.NoError(t, err)
+
+	tx := <-outCh
+	nonce, err := ec.PendingNonceAt(context.Background(), from)
+	require.NoError(t, err)
+	gasPrice, err := ec.SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+
+	data, err := hexutil.Decode(tx.Data)
+	require.NoError(t, err)
+	signed, err := ethtypes.SignTx(ethtypes.NewTx(&ethtypes.LegacyTx{
+		Nonce:    nonce,
+		To:       &tx.To,
+		Gas:      200000,
+		GasPrice: gasPrice,
+		Data:     data,
+	}), ethtypes.LatestSignerForChainID(big.NewInt(common.DevelopmentConfig.EthereumChainID)), pk)
+	require.NoError(t, err)
+	require.NoError(t, ec.SendTransaction(context.Background(), signed))
+	inCh <- signed.Hash()
+
+	res := <-resCh
+	require.NoError(t, res.err)
+	require.True(t, res.res.Refunded)
+	require.Equal(t, signed.Hash(), res.res.TxHash)
+}
@@ -0,0 +1,136 @@
+package xmrtaker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const (
+	// how long after t1 the refund is sent, so that the chain's timestamp is past t1
+	refundT1Buffer = time.Second
+
+	// time between refund attempts once the swap has exited or t1 has passed
+	refundRetryInterval = time.Second * 30
+)
+
+// refundScheduler refunds the ether we locked in a swap if the counterparty never claims it:
+// right after t1, or before t0 if the counterparty aborts before locking their monero. It's
+// owned by the Instance rather than by the swap state, so the refund still happens after the
+// swap state has exited, eg. because the counterparty disconnected or a refund attempt failed.
+type refundScheduler struct {
+	ctx context.Context
+
+	mu        sync.Mutex
+	scheduled map[types.Hash]struct{}
+}
+
+func newRefundScheduler(ctx context.Context) *refundScheduler {
+	return &refundScheduler{
+		ctx:       ctx,
+		scheduled: make(map[types.Hash]struct{}),
+	}
+}
+
+// schedule arms the refund of the given swap, whose ether must already be locked.
+func (r *refundScheduler) schedule(s *swapState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, has := r.scheduled[s.ID()]; has {
+		return
+	}
+
+	r.scheduled[s.ID()] = struct{}{}
+	log.Debugf("scheduled refund of swap %s after %s", s.ID(), s.t1)
+	go r.run(s)
+}
+
+func (r *refundScheduler) isScheduled(id types.Hash) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, has := r.scheduled[id]
+	return has
+}
+
+func (r *refundScheduler) run(s *swapState) {
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.scheduled, s.ID())
+	}()
+
+	timer := time.NewTimer(time.Until(s.t1) + refundT1Buffer)
+	defer timer.Stop()
+
+	exited := s.done
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-exited:
+			// if the counterparty aborted before locking their monero, we can refund before t0
+			exited = nil
+		case <-timer.C:
+			if exited != nil && time.Now().After(s.t1) {
+				// the swap is still running, so let it refund and notify the counterparty
+				s.handleT1Expired()
+			}
+		}
+
+		if r.tryRefund(s) {
+			return
+		}
+
+		next := refundRetryInterval
+		if untilT1 := time.Until(s.t1) + refundT1Buffer; untilT1 > 0 && untilT1 < next {
+			next = untilT1
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// tryRefund refunds the swap if the contract allows it. It returns true once there's nothing
+// left to refund, either because we refunded or because the counterparty claimed.
+func (r *refundScheduler) tryRefund(s *swapState) bool {
+	stage, err := s.Contract().Swaps(&bind.CallOpts{Context: r.ctx}, s.contractSwapID)
+	if err != nil {
+		log.Warnf("failed to get stage of swap %s: %s", s.ID(), err)
+		return false
+	}
+
+	switch stage {
+	case swapfactory.StageInvalid, swapfactory.StageCompleted:
+		return true
+	}
+
+	// the contract allows refunds after t1, or before t0 if the swap isn't ready
+	now := time.Now()
+	if !now.After(s.t1) && !(stage == swapfactory.StagePending && now.Before(s.t0)) {
+		return false
+	}
+
+	s.lockState()
+	defer s.unlockState()
+
+	txHash, err := s.refund()
+	if err != nil {
+		log.Warnf("failed to refund swap %s, will retry: %s", s.ID(), err)
+		return false
+	}
+
+	log.Infof("refunded swap %s: txHash=%s", s.ID(), txHash)
+	return true
+}
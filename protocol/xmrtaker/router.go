@@ -0,0 +1,210 @@
+package xmrtaker
+
+import (
+	"crypto/rand"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// Statuses of a route as a whole.
+const (
+	RouteOngoing = "ongoing"
+	RouteSuccess = "success"
+	RoutePartial = "partial"
+	RouteFailed  = "failed"
+)
+
+// MakerOffer is an offer along with the maker that made it.
+type MakerOffer struct {
+	Maker peer.AddrInfo
+	Offer *types.Offer
+}
+
+// RouteLeg is the part of a route that's swapped with one maker.
+type RouteLeg struct {
+	MakerOffer
+	ProvidesAmount float64
+	// Err is set if the leg's swap couldn't be initiated
+	Err error
+}
+
+// Route is an order that's split across several makers' offers, each of which is swapped separately.
+type Route struct {
+	ID             types.Hash
+	ProvidesAmount float64
+	Legs           []*RouteLeg
+}
+
+// TakeFunc initiates the swap for one leg of a route.
+type TakeFunc func(leg *RouteLeg) error
+
+// Router satisfies orders that are too large for any one maker by taking several smaller offers
+// from different makers concurrently.
+type Router struct {
+	sm swap.Manager
+
+	mu     sync.Mutex
+	routes map[types.Hash]*Route
+}
+
+// NewRouter returns a new *Router that looks up the status of routes' swaps in the given swap.Manager.
+func NewRouter(sm swap.Manager) *Router {
+	return &Router{
+		sm:     sm,
+		routes: make(map[types.Hash]*Route),
+	}
+}
+
+// planRoute splits providesAmount of ETH across the offers, preferring the best exchange rates and
+// taking at most one offer from each maker.
+func planRoute(offers []*MakerOffer, providesAmount float64) ([]*RouteLeg, error) {
+	sorted := make([]*MakerOffer, len(offers))
+	copy(sorted, offers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Offer.ExchangeRate < sorted[j].Offer.ExchangeRate
+	})
+
+	var (
+		legs      []*RouteLeg
+		remaining = providesAmount
+		used      = make(map[peer.ID]struct{})
+	)
+
+	for _, mo := range sorted {
+		if remaining <= 0 {
+			break
+		}
+
+		if _, has := used[mo.Maker.ID]; has {
+			continue
+		}
+
+		rate := mo.Offer.ExchangeRate
+		if mo.Offer.Provides != types.ProvidesXMR || rate <= 0 {
+			continue
+		}
+
+		amount := remaining
+		if maxETH := rate.ToETH(mo.Offer.MaximumAmount); amount > maxETH {
+			amount = maxETH
+			// make sure rounding doesn't push the XMR amount over the offer's maximum
+			for rate.ToXMR(amount) > mo.Offer.MaximumAmount {
+				amount = math.Nextafter(amount, 0)
+			}
+		}
+
+		if amount < rate.ToETH(mo.Offer.MinimumAmount) || mo.Offer.CheckTake(amount) != nil {
+			continue
+		}
+
+		used[mo.Maker.ID] = struct{}{}
+		legs = append(legs, &RouteLeg{
+			MakerOffer:     *mo,
+			ProvidesAmount: amount,
+		})
+		remaining -= amount
+	}
+
+	if remaining > 0 {
+		return nil, errCannotFillRoute
+	}
+
+	return legs, nil
+}
+
+// Route splits providesAmount of ETH across the given offers and initiates each leg's swap
+// concurrently with take. Legs that fail to initiate are recorded in the route with their error.
+func (r *Router) Route(offers []*MakerOffer, providesAmount float64, take TakeFunc) (*Route, error) {
+	legs, err := planRoute(offers, providesAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &Route{
+		ProvidesAmount: providesAmount,
+		Legs:           legs,
+	}
+	if _, err = rand.Read(route.ID[:]); err != nil {
+		return nil, err
+	}
+
+	var wg sync.WaitGroup
+	for _, leg := range legs {
+		wg.Add(1)
+		go func(leg *RouteLeg) {
+			defer wg.Done()
+			leg.Err = take(leg)
+			if leg.Err != nil {
+				log.Warnf("failed to take offer %s for route %s: %s", leg.Offer.GetID(), route.ID, leg.Err)
+			}
+		}(leg)
+	}
+	wg.Wait()
+
+	r.mu.Lock()
+	r.routes[route.ID] = route
+	r.mu.Unlock()
+	return route, nil
+}
+
+// GetRoute returns the route with the given ID, or nil if there isn't one.
+func (r *Router) GetRoute(id types.Hash) *Route {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.routes[id]
+}
+
+// LegInfo returns the swap info for the leg, or nil if its swap wasn't initiated.
+func (r *Router) LegInfo(leg *RouteLeg) *swap.Info {
+	if leg.Err != nil {
+		return nil
+	}
+
+	id := leg.Offer.GetID()
+	if info := r.sm.GetOngoingSwap(id); info != nil {
+		return info
+	}
+
+	return r.sm.GetPastSwap(id)
+}
+
+// Status returns the status of the route as a whole, and the amount of XMR received by the legs
+// that completed successfully.
+func (r *Router) Status(route *Route) (string, float64) {
+	var (
+		ongoing, succeeded, failed bool
+		received                   float64
+	)
+
+	for _, leg := range route.Legs {
+		info := r.LegInfo(leg)
+		switch {
+		case info == nil:
+			failed = true
+		case info.Status().IsOngoing():
+			ongoing = true
+		case info.Status() == types.CompletedSuccess:
+			succeeded = true
+			received += info.ReceivedAmount()
+		default:
+			failed = true
+		}
+	}
+
+	switch {
+	case ongoing:
+		return RouteOngoing, received
+	case succeeded && failed:
+		return RoutePartial, received
+	case succeeded:
+		return RouteSuccess, received
+	default:
+		return RouteFailed, received
+	}
+}
@@ -0,0 +1,110 @@
+package xmrtaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMakerOffer(maker string, id byte, rate types.ExchangeRate, min, max float64) *MakerOffer {
+	return &MakerOffer{
+		Maker: peer.AddrInfo{ID: peer.ID(maker)},
+		Offer: &types.Offer{
+			ID:            types.Hash{id},
+			Provides:      types.ProvidesXMR,
+			MinimumAmount: min,
+			MaximumAmount: max,
+			ExchangeRate:  rate,
+		},
+	}
+}
+
+func TestPlanRoute(t *testing.T) {
+	offers := []*MakerOffer{
+		newTestMakerOffer("a", 1, 0.1, 1, 10),
+		newTestMakerOffer("b", 2, 0.09, 1, 5),
+		// maker b's second offer isn't used, as each leg is with a different maker
+		newTestMakerOffer("b", 3, 0.095, 1, 100),
+		newTestMakerOffer("c", 4, 0.2, 1, 100),
+	}
+
+	legs, err := planRoute(offers, 1.2)
+	require.NoError(t, err)
+	require.Len(t, legs, 2)
+	require.Equal(t, types.Hash{2}, legs[0].Offer.ID)
+	require.InDelta(t, 0.45, legs[0].ProvidesAmount, 1e-9)
+	require.Equal(t, types.Hash{1}, legs[1].Offer.ID)
+	require.InDelta(t, 0.75, legs[1].ProvidesAmount, 1e-9)
+	for _, leg := range legs {
+		require.LessOrEqual(t, leg.Offer.ExchangeRate.ToXMR(leg.ProvidesAmount), leg.Offer.MaximumAmount)
+	}
+
+	_, err = planRoute(offers, 100)
+	require.ErrorIs(t, err, errCannotFillRoute)
+}
+
+func TestPlanRoute_SkipsOffersBelowMinimum(t *testing.T) {
+	offers := []*MakerOffer{
+		newTestMakerOffer("a", 1, 0.1, 1, 10),
+		newTestMakerOffer("b", 2, 0.05, 10, 20),
+	}
+
+	// 0.3 ETH only buys 6 XMR from maker b, below its minimum
+	legs, err := planRoute(offers, 0.3)
+	require.NoError(t, err)
+	require.Len(t, legs, 1)
+	require.Equal(t, types.Hash{1}, legs[0].Offer.ID)
+}
+
+func TestRouter_Status(t *testing.T) {
+	sm := swap.NewManager()
+	r := NewRouter(sm)
+
+	offers := []*MakerOffer{
+		newTestMakerOffer("a", 1, 0.1, 1, 10),
+		newTestMakerOffer("b", 2, 0.1, 1, 10),
+	}
+
+	route, err := r.Route(offers, 2, func(leg *RouteLeg) error {
+		info := swap.NewInfo(leg.Offer.GetID(), types.ProvidesETH, leg.ProvidesAmount,
+			leg.Offer.ExchangeRate.ToXMR(leg.ProvidesAmount), leg.Offer.ExchangeRate, types.ETHLocked, nil)
+		return sm.AddSwap(info)
+	})
+	require.NoError(t, err)
+	require.Equal(t, route, r.GetRoute(route.ID))
+
+	status, received := r.Status(route)
+	require.Equal(t, RouteOngoing, status)
+	require.Zero(t, received)
+
+	sm.GetOngoingSwap(types.Hash{1}).SetStatus(types.CompletedSuccess)
+	sm.CompleteOngoingSwap(types.Hash{1})
+	sm.GetOngoingSwap(types.Hash{2}).SetStatus(types.CompletedRefund)
+	sm.CompleteOngoingSwap(types.Hash{2})
+
+	status, received = r.Status(route)
+	require.Equal(t, RoutePartial, status)
+	require.Equal(t, float64(10), received)
+}
+
+func TestRouter_Route_TakeFails(t *testing.T) {
+	r := NewRouter(swap.NewManager())
+
+	offers := []*MakerOffer{
+		newTestMakerOffer("a", 1, 0.1, 1, 10),
+	}
+
+	route, err := r.Route(offers, 1, func(leg *RouteLeg) error {
+		return errors.New("peer offline")
+	})
+	require.NoError(t, err)
+	require.Error(t, route.Legs[0].Err)
+
+	status, _ := r.Status(route)
+	require.Equal(t, RouteFailed, status)
+}
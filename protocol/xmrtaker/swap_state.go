@@ -14,6 +14,7 @@ import (
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
 	"github.com/noot/atomic-swap/crypto/secp256k1"
+	"github.com/noot/atomic-swap/db"
 	"github.com/noot/atomic-swap/dleq"
 	"github.com/noot/atomic-swap/monero"
 	"github.com/noot/atomic-swap/net"
@@ -27,7 +28,18 @@ import (
 	"github.com/fatih/color" //nolint:misspell
 )
 
-const revertSwapCompleted = "swap is already completed"
+const (
+	revertSwapCompleted = "swap is already completed"
+
+	// number of monero blocks to wait for after XMRMaker locks their XMR, if the offer
+	// doesn't have speed tiers
+	defaultMoneroConfirmations = 2
+
+	// TODO: this is so that we definitely refund before t0.
+	// this will vary based on environment (eg. development should be very small,
+	// a network with slower block times should be longer)
+	refundT0Buffer = time.Second * 5
+)
 
 // swapState is an instance of a swap. it holds the info needed for the swap,
 // and its current state.
@@ -39,10 +51,26 @@ type swapState struct {
 	stateMu      sync.Mutex
 	infoFile     string
 	transferBack bool
+	// database the info file's contents are also stored in; may be nil
+	infoDB db.Database
 
 	info     *pswap.Info
 	statusCh chan types.Status
 
+	// settlement speed tier negotiated for this swap; nil if the offer has none
+	speedTier *types.SpeedTier
+
+	// when to set the contract to ready once the maker's XMR is locked
+	readyPolicy        ReadyPolicy
+	readyConfirmedCh   chan struct{} // closed by ConfirmReady
+	readyConfirmedOnce sync.Once
+
+	// limits on the swap's terms, checked again when the counterparty sends its amount; may be nil
+	slippage *types.SlippageLimits
+
+	// refunds the swap if the counterparty doesn't claim; nil for swaps being recovered
+	refunds *refundScheduler
+
 	// our keys for this session
 	dleqProof    *dleq.Proof
 	secp256k1Pub *secp256k1.PublicKey
@@ -58,21 +86,25 @@ type swapState struct {
 	// swap contract and timeouts in it; set once contract is deployed
 	contractSwapID [32]byte
 	contractSwap   swapfactory.SwapFactorySwap
-	t0, t1         time.Time
+	// block the swap was created in, used as the starting point when searching for its logs
+	contractSwapBlock uint64
+	t0, t1            time.Time
 
 	// next expected network message
 	nextExpectedMessage net.Message
 
+	// set if the ether is locked from an HD wallet swap account, which is swept back to the base
+	// account once the swap is over
+	swapAccount bool
+
 	// channels
-	xmrLockedCh chan struct{}
-	claimedCh   chan struct{}
-	done        chan struct{}
-	exited      bool
+	done   chan struct{}
+	exited bool
 }
 
-func newSwapState(b backend.Backend, offerID types.Hash, infofile string, transferBack bool,
+func newSwapState(b backend.Backend, offerID types.Hash, infofile string, infoDB db.Database, transferBack bool,
 	providesAmount common.EtherAmount, receivedAmount common.MoneroAmount,
-	exchangeRate types.ExchangeRate) (*swapState, error) {
+	exchangeRate types.ExchangeRate, refunds *refundScheduler) (*swapState, error) {
 	if b.Contract() == nil {
 		return nil, errNoSwapContractSet
 	}
@@ -101,19 +133,29 @@ func newSwapState(b backend.Backend, offerID types.Hash, infofile string, transf
 		cancel:              cancel,
 		Backend:             b,
 		infoFile:            infofile,
+		infoDB:              infoDB,
 		transferBack:        transferBack,
 		nextExpectedMessage: &net.SendKeysMessage{},
-		xmrLockedCh:         make(chan struct{}),
-		claimedCh:           make(chan struct{}),
+		readyConfirmedCh:    make(chan struct{}),
 		done:                make(chan struct{}),
 		info:                info,
 		statusCh:            statusCh,
+		refunds:             refunds,
 	}
 
-	if err := pcommon.WriteContractAddressToFile(s.infoFile, b.ContractAddr().String()); err != nil {
+	if err := pcommon.WriteSwapIDToFile(s.infoDB, s.infoFile, offerID); err != nil {
+		return nil, fmt.Errorf("failed to write swap ID to file: %w", err)
+	}
+
+	if err := pcommon.WriteContractAddressToFile(s.infoDB, s.infoFile, b.ContractAddr().String()); err != nil {
 		return nil, fmt.Errorf("failed to write contract address to file: %w", err)
 	}
 
+	// open the signer's session, so the front-end can subscribe to the swap's transactions
+	if es := b.ExternalSender(); es != nil {
+		es.AddID(offerID)
+	}
+
 	go s.waitForSendKeysMessage()
 	return s, nil
 }
@@ -150,11 +192,18 @@ func (s *swapState) SendKeysMessage() (*net.SendKeysMessage, error) {
 		return nil, err
 	}
 
+	var speedTier string
+	if s.speedTier != nil {
+		speedTier = s.speedTier.Name
+	}
+
 	return &net.SendKeysMessage{
+		SpeedTier:          speedTier,
 		PublicSpendKey:     s.pubkeys.SpendKey().Hex(),
 		PublicViewKey:      s.pubkeys.ViewKey().Hex(),
 		DLEqProof:          hex.EncodeToString(s.dleqProof.Proof()),
 		Secp256k1PublicKey: s.secp256k1Pub.String(),
+		Version:            pcommon.NewVersionInfo(s.Env(), s.ChainID()),
 	}, nil
 }
 
@@ -163,6 +212,37 @@ func (s *swapState) InfoFile() string {
 	return s.infoFile
 }
 
+// SetPeerID records the swap's counterparty in the swap's history.
+func (s *swapState) SetPeerID(id string) {
+	s.info.SetPeerID(id)
+}
+
+// LivenessTimeout returns how long the counterparty may go without being heard from at the
+// swap's current stage, before the network gives up on it.
+func (s *swapState) LivenessTimeout() time.Duration {
+	return pcommon.LivenessTimeout(s.info.Status())
+}
+
+// sweepSwapAccount sends what's left in the swap's HD wallet account, which is the change from its
+// gas budget, or the refunded ether, back to the base account.
+func (s *swapState) sweepSwapAccount(addr ethcommon.Address) {
+	txHash, err := s.SweepSwapAccount(addr)
+	if err != nil {
+		log.Warnf("failed to sweep swap account %s: %s", addr, err)
+		return
+	}
+
+	if (txHash != ethcommon.Hash{}) {
+		log.Infof("swept swap account %s, tx hash=%s", addr, txHash)
+	}
+}
+
+// SignSessionKey signs the network session key for the swap with the secp256k1 key of our DLEq
+// proof, so that the counterparty can check it came from us.
+func (s *swapState) SignSessionKey(key []byte) ([]byte, error) {
+	return pcommon.SignSessionKey(s.getSecret(), key)
+}
+
 // ReceivedAmount returns the amount received, or expected to be received, at the end of the swap
 func (s *swapState) ReceivedAmount() float64 {
 	return s.info.ReceivedAmount()
@@ -178,6 +258,11 @@ func (s *swapState) receivedAmountInPiconero() common.MoneroAmount {
 
 // ID returns the ID of the swap
 func (s *swapState) ID() types.Hash {
+	if s.info == nil {
+		// swaps being recovered have no swap info, so they're identified by their on-chain swap ID
+		return s.contractSwapID
+	}
+
 	return s.info.ID()
 }
 
@@ -190,6 +275,28 @@ func (s *swapState) Exit() error {
 	return s.exit()
 }
 
+// Cancel is called by the swap_cancel RPC endpoint. If we haven't locked our ether yet, the
+// counterparty is notified so that it aborts too, rather than waiting for the stream to close.
+// Otherwise, it's the same as Exit.
+func (s *swapState) Cancel() error {
+	s.lockState()
+	defer s.unlockState()
+
+	if _, ok := s.nextExpectedMessage.(*net.SendKeysMessage); ok {
+		s.notifyAbort("swap cancelled by counterparty")
+	}
+
+	return s.exit()
+}
+
+// notifyAbort tells the counterparty that we're aborting the swap. It's best-effort, as the
+// counterparty aborts anyway once the stream closes.
+func (s *swapState) notifyAbort(reason string) {
+	if err := s.SendSwapMessage(&message.NotifyAbort{Reason: reason}, s.ID()); err != nil {
+		log.Warnf("failed to notify counterparty of abort: %s", err)
+	}
+}
+
 // exit is the same as Exit, but assumes the calling code block already holds the swapState lock.
 func (s *swapState) exit() error {
 	if s.exited {
@@ -201,8 +308,17 @@ func (s *swapState) exit() error {
 		// stop all running goroutines
 		s.cancel()
 		s.SwapManager().CompleteOngoingSwap(s.info.ID())
+		if es := s.ExternalSender(); es != nil {
+			es.DeleteID(s.info.ID())
+		}
 		close(s.done)
 
+		if s.swapAccount && s.info.Status() != types.CompletedAbort {
+			// the swap account is done with; sweeping it waits for the transfer, so it's done in
+			// the background
+			go s.sweepSwapAccount(s.contractSwap.Owner)
+		}
+
 		if s.info.Status() == types.CompletedSuccess {
 			str := color.New(color.Bold).Sprintf("**swap completed successfully: id=%s**", s.info.ID())
 			log.Info(str)
@@ -292,7 +408,11 @@ func (s *swapState) doRefund() (ethcommon.Hash, error) {
 	switch s.nextExpectedMessage.(type) {
 	case *message.NotifyXMRLock, *message.NotifyClaimed:
 		// the XMR has been locked, but the ETH hasn't been claimed.
-		// we can refund in this case.
+		// we can refund in this case, if the contract allows it now.
+		if err := s.checkRefundWindow(time.Now()); err != nil {
+			return ethcommon.Hash{}, err
+		}
+
 		txHash, err := s.tryRefund()
 		if err != nil {
 			s.clearNextExpectedMessage(types.CompletedAbort)
@@ -316,6 +436,29 @@ func (s *swapState) doRefund() (ethcommon.Hash, error) {
 	}
 }
 
+// checkRefundWindow returns an error if the swap contract doesn't allow us to refund at the given
+// time: we can refund before t0 if the swap isn't ready, and from t1.
+func (s *swapState) checkRefundWindow(now time.Time) error {
+	if s.Contract() == nil {
+		return errNoSwapContractSet
+	}
+
+	if !now.Before(s.t1) {
+		return nil
+	}
+
+	isReady, err := s.Contract().IsReady(s.CallOpts(), s.contractSwapID)
+	if err != nil {
+		return err
+	}
+
+	if now.Before(s.t0) && !isReady {
+		return nil
+	}
+
+	return fmt.Errorf("%w: it can be refunded from %s", errRefundNotAllowed, s.t1.UTC().Format(time.RFC3339))
+}
+
 func (s *swapState) tryRefund() (ethcommon.Hash, error) {
 	untilT0 := time.Until(s.t0)
 	untilT1 := time.Until(s.t1)
@@ -336,6 +479,36 @@ func (s *swapState) tryRefund() (ethcommon.Hash, error) {
 	return s.refund()
 }
 
+// ContractTimeout returns the contract timeout duration for this swap, so that the network can
+// check the counterparty accepts it before the swap begins.
+func (s *swapState) ContractTimeout() time.Duration {
+	return s.timeoutDuration()
+}
+
+// timeoutDuration returns the contract timeout duration for this swap.
+func (s *swapState) timeoutDuration() time.Duration {
+	if s.speedTier != nil {
+		return s.speedTier.TimeoutDuration()
+	}
+
+	return s.SwapTimeout()
+}
+
+// moneroConfirmations returns the number of confirmations XMRMaker's locked XMR needs
+// before we set the swap to ready.
+func (s *swapState) moneroConfirmations() uint64 {
+	confirmations := uint64(defaultMoneroConfirmations)
+	if s.speedTier != nil {
+		confirmations = s.speedTier.MoneroConfirmations
+	}
+
+	if s.readyPolicy.MoneroConfirmations > confirmations {
+		return s.readyPolicy.MoneroConfirmations
+	}
+
+	return confirmations
+}
+
 func (s *swapState) setTimeouts(t0, t1 *big.Int) {
 	s.t0 = time.Unix(t0.Int64(), 0)
 	s.t1 = time.Unix(t1.Int64(), 0)
@@ -356,7 +529,7 @@ func (s *swapState) generateAndSetKeys() error {
 	s.privkeys = keysAndProof.PrivateKeyPair
 	s.pubkeys = keysAndProof.PublicKeyPair
 
-	return pcommon.WriteKeysToFile(s.infoFile, s.privkeys, s.Env())
+	return pcommon.WriteKeysToFile(s.infoDB, s.infoFile, s.privkeys, s.Env())
 }
 
 // generateKeys generates XMRTaker's monero spend and view keys (S_b, V_b), a secp256k1 public key,
@@ -365,6 +538,19 @@ func generateKeys() (*pcommon.KeysAndProof, error) {
 	return pcommon.GenerateKeysAndProof()
 }
 
+// addTx records an ethereum transaction sent for the swap, along with its cost.
+func (s *swapState) addTx(txHash ethcommon.Hash) {
+	s.info.AddTxHash(txHash)
+
+	gasUsed, fee, err := pcommon.GetTxCost(s.ctx, s, txHash)
+	if err != nil {
+		log.Warnf("failed to get the cost of tx %s: %s", txHash, err)
+		return
+	}
+
+	s.info.AddTxCost(gasUsed, fee)
+}
+
 // getSecret secrets returns the current secret scalar used to unlock funds from the contract.
 func (s *swapState) getSecret() [32]byte {
 	secret := s.dleqProof.Secret()
@@ -395,14 +581,44 @@ func (s *swapState) lockETH(amount common.EtherAmount) (ethcommon.Hash, error) {
 	cmtXMRTaker := s.secp256k1Pub.Keccak256()
 	cmtXMRMaker := s.xmrmakerSecp256k1PublicKey.Keccak256()
 
+	// the swap's ether is locked from this address; if it's derived from an HD wallet, record
+	// its index first so the refund can still be signed after a restart
+	owner, keyIndex, err := s.SwapEthAddress(s.ID())
+	if err != nil {
+		return ethcommon.Hash{}, err
+	}
+
+	if keyIndex != nil {
+		if err = pcommon.WriteEthereumKeyIndexToFile(s.infoDB, s.infoFile, *keyIndex); err != nil {
+			return ethcommon.Hash{}, err
+		}
+		s.swapAccount = true
+	}
+
+	// the timeouts are only known once the swap is created, but the rest of the swap is written
+	// first, so that it can be found from the contract's logs if we crash before it's written again
 	nonce := generateNonce()
+	s.contractSwap = swapfactory.SwapFactorySwap{
+		Owner:        owner,
+		Claimer:      s.xmrmakerAddress,
+		PubKeyClaim:  cmtXMRMaker,
+		PubKeyRefund: cmtXMRTaker,
+		Value:        amount.BigInt(),
+		Nonce:        nonce,
+	}
+
+	if err = pcommon.WriteContractSwapToFile(s.infoDB, s.infoFile, [32]byte{}, s.contractSwap); err != nil {
+		return ethcommon.Hash{}, err
+	}
+
 	txHash, receipt, err := s.NewSwap(s.ID(), cmtXMRMaker, cmtXMRTaker,
-		s.xmrmakerAddress, big.NewInt(int64(s.SwapTimeout().Seconds())), nonce, amount.BigInt())
+		s.xmrmakerAddress, big.NewInt(int64(s.timeoutDuration().Seconds())), nonce, amount.BigInt())
 	if err != nil {
 		return ethcommon.Hash{}, fmt.Errorf("failed to instantiate swap on-chain: %w", err)
 	}
 
 	log.Debugf("instantiated swap on-chain: amount=%s txHash=%s", amount, txHash)
+	s.addTx(txHash)
 
 	if len(receipt.Logs) == 0 {
 		return ethcommon.Hash{}, errSwapInstantiationNoLogs
@@ -420,21 +636,29 @@ func (s *swapState) lockETH(amount common.EtherAmount) (ethcommon.Hash, error) {
 
 	s.setTimeouts(t0, t1)
 
-	s.contractSwap = swapfactory.SwapFactorySwap{
-		Owner:        s.EthAddress(),
-		Claimer:      s.xmrmakerAddress,
-		PubKeyClaim:  cmtXMRMaker,
-		PubKeyRefund: cmtXMRTaker,
-		Timeout0:     t0,
-		Timeout1:     t1,
-		Value:        amount.BigInt(),
-		Nonce:        nonce,
+	s.contractSwap.Timeout0 = t0
+	s.contractSwap.Timeout1 = t1
+
+	if err := pcommon.WriteContractSwapToFile(s.infoDB, s.infoFile, s.contractSwapID, s.contractSwap); err != nil {
+		return ethcommon.Hash{}, err
 	}
 
-	if err := pcommon.WriteContractSwapToFile(s.infoFile, s.contractSwapID, s.contractSwap); err != nil {
+	s.contractSwapBlock = receipt.BlockNumber.Uint64()
+	if err := pcommon.WriteContractSwapBlockToFile(s.infoDB, s.infoFile, s.contractSwapBlock); err != nil {
 		return ethcommon.Hash{}, err
 	}
 
+	// the ether is already locked, so failing to write the instructions shouldn't fail the swap
+	instructions := pcommon.NewRecoveryInstructions(s.Env(), s.ChainID().Int64(), s.infoFile, s.ID(),
+		s.ContractAddr(), s.contractSwapID, s.contractSwap, s.contractSwapBlock, keyIndex)
+	if err := pcommon.WriteRecoveryInstructions(s.infoFile, instructions); err != nil {
+		log.Warnf("failed to write recovery instructions: %s", err)
+	}
+
+	if s.refunds != nil {
+		s.refunds.schedule(s)
+	}
+
 	return txHash, nil
 }
 
@@ -442,7 +666,7 @@ func (s *swapState) lockETH(amount common.EtherAmount) (ethcommon.Hash, error) {
 // call Claim(). Ready() should only be called once XMRTaker sees XMRMaker lock his XMR.
 // If time t_0 has passed, there is no point of calling Ready().
 func (s *swapState) ready() error {
-	_, _, err := s.SetReady(s.ID(), s.contractSwap)
+	txHash, _, err := s.SetReady(s.ID(), s.contractSwap)
 	if err != nil {
 		if strings.Contains(err.Error(), revertSwapCompleted) && !s.info.Status().IsOngoing() {
 			return nil
@@ -451,6 +675,7 @@ func (s *swapState) ready() error {
 		return err
 	}
 
+	s.addTx(txHash)
 	return nil
 }
 
@@ -470,6 +695,7 @@ func (s *swapState) refund() (ethcommon.Hash, error) {
 		return ethcommon.Hash{}, err
 	}
 
+	s.addTx(txHash)
 	s.clearNextExpectedMessage(types.CompletedRefund)
 	return txHash, nil
 }
@@ -484,7 +710,7 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (mcrypto.Address,
 	kpAB := mcrypto.NewPrivateKeyPair(skAB, vkAB)
 
 	// write keys to file in case something goes wrong
-	if err := pcommon.WriteSharedSwapKeyPairToFile(s.infoFile, kpAB, s.Env()); err != nil {
+	if err := pcommon.WriteSharedSwapKeyPairToFile(s.infoDB, s.infoFile, kpAB, s.Env()); err != nil {
 		return "", err
 	}
 
@@ -507,36 +733,19 @@ func (s *swapState) claimMonero(skB *mcrypto.PrivateSpendKey) (mcrypto.Address,
 		return "", err
 	}
 
-	log.Infof("monero claimed in account %s; transferring to original account %s",
+	log.Infof("monero claimed in account %s; sweeping to %s",
 		addr, depositAddr)
 
 	err = mcrypto.ValidateAddress(string(depositAddr))
 	if err != nil {
-		log.Errorf("failed to transfer to original account, address %s is invalid", addr)
+		log.Errorf("failed to sweep claimed monero, address %s is invalid", depositAddr)
 		return addr, nil
 	}
 
-	err = s.waitUntilBalanceUnlocks(depositAddr)
-	if err != nil {
-		return "", fmt.Errorf("failed to wait for balance to unlock: %w", err)
-	}
-
-	res, err := s.SweepAll(depositAddr, 0)
-	if err != nil {
-		return "", fmt.Errorf("failed to send funds to original account: %w", err)
-	}
-
-	if len(res.AmountList) == 0 {
-		return "", fmt.Errorf("sweep all did not return any amounts")
+	if err = s.sweep(depositAddr); err != nil {
+		return "", err
 	}
 
-	amount := res.AmountList[0]
-	log.Infof("transferred %v XMR to %s",
-		common.MoneroAmount(amount).AsMonero(),
-		depositAddr,
-	)
-
-	close(s.claimedCh)
 	return addr, nil
 }
 
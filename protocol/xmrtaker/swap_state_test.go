@@ -108,8 +108,8 @@ func newXMRMakerBackend(t *testing.T) backend.Backend {
 
 func newTestInstance(t *testing.T) *swapState {
 	b := newBackend(t)
-	swapState, err := newSwapState(b, types.Hash{}, infofile, false,
-		common.NewEtherAmount(1), common.MoneroAmount(0), 1)
+	swapState, err := newSwapState(b, types.Hash{}, infofile, nil, false,
+		common.NewEtherAmount(1), common.MoneroAmount(0), 1, newRefundScheduler(b.Ctx()))
 	require.NoError(t, err)
 	return swapState
 }
@@ -151,6 +151,21 @@ func TestSwapState_HandleProtocolMessage_SendKeysMessage(t *testing.T) {
 	require.Equal(t, xmrmakerKeysAndProof.PrivateKeyPair.ViewKey().Hex(), s.xmrmakerPrivateViewKey.Hex())
 }
 
+func TestSwapState_HandleProtocolMessage_SendKeysMessage_Slippage(t *testing.T) {
+	s := newTestInstance(t)
+	defer s.cancel()
+
+	err := s.generateAndSetKeys()
+	require.NoError(t, err)
+
+	// the counterparty offers less monero than our limit, so no ether is locked
+	msg, _ := newTestXMRMakerSendKeysMessage(t)
+	s.slippage = &types.SlippageLimits{MinReceivedXMR: msg.ProvidedAmount + 1}
+	_, _, err = s.HandleProtocolMessage(msg)
+	require.Error(t, err)
+	require.Equal(t, [32]byte{}, s.contractSwapID)
+}
+
 // test the case where XMRTaker deploys and locks her eth, but XMRMaker never locks his monero.
 // XMRTaker should call refund before the timeout t0.
 func TestSwapState_HandleProtocolMessage_SendKeysMessage_Refund(t *testing.T) {
@@ -276,6 +291,36 @@ func TestSwapState_NotifyXMRLock_Refund(t *testing.T) {
 	require.Equal(t, uint64(0), balance.Uint64())
 }
 
+func TestSwapState_CheckRefundWindow(t *testing.T) {
+	s := newTestInstance(t)
+	defer s.cancel()
+	s.SetSwapTimeout(time.Minute)
+
+	err := s.generateAndSetKeys()
+	require.NoError(t, err)
+
+	xmrmakerKeysAndProof, err := generateKeys()
+	require.NoError(t, err)
+
+	s.setXMRMakerKeys(xmrmakerKeysAndProof.PublicKeyPair.SpendKey(), xmrmakerKeysAndProof.PrivateKeyPair.ViewKey(),
+		xmrmakerKeysAndProof.Secp256k1PublicKey)
+
+	_, err = s.lockETH(common.NewEtherAmount(1))
+	require.NoError(t, err)
+
+	// the swap isn't ready, so it can be refunded until t0, and again from t1
+	require.NoError(t, s.checkRefundWindow(time.Now()))
+	require.ErrorIs(t, s.checkRefundWindow(s.t0), errRefundNotAllowed)
+	require.NoError(t, s.checkRefundWindow(s.t1))
+
+	// a manual refund between t0 and t1 fails without ending the swap
+	s.nextExpectedMessage = &message.NotifyXMRLock{}
+	s.t0 = time.Now().Add(-time.Second)
+	_, err = s.doRefund()
+	require.ErrorIs(t, err, errRefundNotAllowed)
+	require.True(t, s.info.Status().IsOngoing())
+}
+
 func TestSwapState_NotifyClaimed(t *testing.T) {
 	s := newTestInstance(t)
 	defer s.cancel()
@@ -443,3 +488,11 @@ func TestExit_invalidNextMessageType(t *testing.T) {
 	info := s.SwapManager().GetPastSwap(s.info.ID())
 	require.Equal(t, types.CompletedAbort, info.Status())
 }
+
+func TestSwapState_Cancel_NotifiesAbort(t *testing.T) {
+	s := newTestInstance(t)
+	err := s.Cancel()
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedAbort, s.info.Status())
+	require.IsType(t, &message.NotifyAbort{}, s.Net().(*mockNet).msg)
+}
@@ -0,0 +1,76 @@
+package xmrtaker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+)
+
+const (
+	maxSweepAttempts      = 5
+	sweepRetryInterval    = time.Second * 30
+	devSweepRetryInterval = time.Second
+)
+
+// sweep transfers all the funds in the open swap wallet to the given address once they unlock,
+// retrying if the sweep fails. The network fee is paid out of the swept funds.
+func (s *swapState) sweep(to mcrypto.Address) error {
+	retryInterval := sweepRetryInterval
+	if s.Env() == common.Development {
+		retryInterval = devSweepRetryInterval
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxSweepAttempts; attempt++ {
+		if err = s.trySweep(to); err == nil {
+			return nil
+		}
+
+		log.Warnf("failed to sweep XMR to %s (attempt %d/%d): %s", to, attempt, maxSweepAttempts, err)
+		if attempt == maxSweepAttempts {
+			break
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+
+	return fmt.Errorf("failed to sweep XMR to %s after %d attempts: %w", to, maxSweepAttempts, err)
+}
+
+func (s *swapState) trySweep(to mcrypto.Address) error {
+	if err := s.waitUntilBalanceUnlocks(to); err != nil {
+		return fmt.Errorf("failed to wait for balance to unlock: %w", err)
+	}
+
+	res, err := s.SweepAll(to, 0)
+	if err != nil {
+		return err
+	}
+
+	if len(res.AmountList) == 0 {
+		return errNoSweptAmounts
+	}
+
+	var amount, fee uint
+	for i := range res.AmountList {
+		amount += res.AmountList[i]
+	}
+	for i := range res.FeeList {
+		fee += res.FeeList[i]
+	}
+
+	log.Infof("swept %v XMR to %s, fee=%v XMR txs=%v",
+		common.MoneroAmount(amount).AsMonero(),
+		to,
+		common.MoneroAmount(fee).AsMonero(),
+		res.TxHashList,
+	)
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package xmrtaker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/net/message"
+)
+
+const (
+	xmrLockPollInterval    = time.Second * 10
+	devXMRLockPollInterval = time.Second
+
+	// lockHeightTolerance is how many blocks XMRMaker's chain height may be ahead of ours, or
+	// behind, when it broadcasts the lock
+	lockHeightTolerance = 10
+)
+
+// xmrLock is what we found locked in the swap's monero account.
+type xmrLock struct {
+	// total amount, in piconero, of the incoming transfers with enough confirmations
+	confirmed uint64
+	// total amount, in piconero, of the incoming transfers without enough confirmations
+	pending uint64
+	// hashes of the transfers making up the confirmed amount
+	txIDs []string
+	// the height and target height of the least-confirmed transfer, for reporting progress
+	height, target uint
+}
+
+// checkXMRLock sums the incoming transfers in the given response. Transfers with a non-zero unlock
+// time are ignored, since we couldn't spend them after the swap.
+func checkXMRLock(transfers *monero.GetTransfersResponse, minConfirmations uint64) *xmrLock {
+	lock := &xmrLock{}
+
+	for _, tx := range transfers.In {
+		if tx.UnlockTime != 0 {
+			log.Warnf("ignoring transfer %s to swap account with unlock time %d", tx.TxID, tx.UnlockTime)
+			continue
+		}
+
+		if tx.Confirmations >= minConfirmations {
+			lock.confirmed += tx.Amount
+			lock.txIDs = append(lock.txIDs, tx.TxID)
+			continue
+		}
+
+		lock.pending += tx.Amount
+		target := uint(tx.Height + minConfirmations)
+		if lock.target == 0 || target > lock.target {
+			lock.height = uint(tx.Height + tx.Confirmations)
+			lock.target = target
+		}
+	}
+
+	for _, tx := range transfers.Pool {
+		if tx.UnlockTime == 0 {
+			lock.pending += tx.Amount
+		}
+	}
+
+	return lock
+}
+
+// verifyXMRLockProof checks, using the lock transaction's secret key sent by the maker, that the
+// transaction pays at least the amount we expect to the swap's address. It doesn't wait for the
+// transaction to be confirmed, so it only lets us abort early; waitForXMRLock must still be called.
+func (s *swapState) verifyXMRLockProof(msg *message.NotifyXMRLock, address mcrypto.Address) error {
+	if msg.TxHash == "" || msg.TxKey == "" {
+		if s.info.PeerVersion().HasFeature(common.FeatureXMRLockProof) {
+			return errMissingXMRLockProof
+		}
+
+		log.Warnf("counterparty didn't send a proof of the XMR lock; relying on the view key alone")
+		return nil
+	}
+
+	res, err := s.CheckTxKey(msg.TxHash, msg.TxKey, address)
+	if err != nil {
+		return fmt.Errorf("failed to check XMR lock proof: %w", err)
+	}
+
+	if err := checkXMRLockProof(res, uint64(s.receivedAmountInPiconero())); err != nil {
+		return err
+	}
+
+	log.Infof("verified XMR lock proof: txHash=%s received=%d inPool=%v confirmations=%d",
+		msg.TxHash, res.Received, res.InPool, res.Confirmations)
+	return nil
+}
+
+func checkXMRLockProof(res *monero.CheckTxKeyResponse, expected uint64) error {
+	if res.Received < expected {
+		return fmt.Errorf("%w: lock transaction sends %d, expected %d", errLockedXMRTooLow, res.Received, expected)
+	}
+
+	return nil
+}
+
+// plausibleLockHeight returns the height XMRMaker says it broadcast the lock at, or 0 if it didn't
+// say, or if the height is too far above our own chain height to be true. As the lock can't be
+// found below it, a height that's too high would make us miss the lock.
+func plausibleLockHeight(lockHeight, height uint64) uint64 {
+	if lockHeight > height+lockHeightTolerance {
+		log.Warnf("counterparty's XMR lock height %d is above our height %d; scanning from the start",
+			lockHeight, height)
+		return 0
+	}
+
+	return lockHeight
+}
+
+// findAccount returns the index of the wallet account with the given address.
+func findAccount(c monero.Client, address mcrypto.Address) (uint, error) {
+	accounts, err := c.GetAccounts()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	for i, acc := range accounts.SubaddressAccounts {
+		addr, ok := acc["base_address"].(string)
+		if !ok {
+			return 0, errAccountAddressNotString
+		}
+
+		if mcrypto.Address(addr) == address {
+			return uint(i), nil
+		}
+	}
+
+	return 0, fmt.Errorf("failed to find account with address %s", address)
+}
+
+// waitForXMRLock scans the view-only wallet of the swap's monero account, which must be open, until
+// transfers of at least the amount we expect to receive have the required number of confirmations.
+// Since the maker's NotifyXMRLock isn't trusted, this is what lets us set the swap to ready.
+// If the height the lock was broadcast at is known, it only starts scanning once the lock could
+// have enough confirmations. It gives up at t0, after which the contract can no longer be set to
+// ready.
+func (s *swapState) waitForXMRLock(address mcrypto.Address, lockHeight uint64) (*xmrLock, error) {
+	ctx, cancel := context.WithDeadline(s.ctx, s.t0)
+	defer cancel()
+
+	expected := uint64(s.receivedAmountInPiconero())
+	minConfirmations := s.moneroConfirmations()
+	pollInterval := xmrLockPollInterval
+	if s.Env() == common.Development {
+		// development nodes mine the lock immediately; requiring one confirmation means it's mined
+		minConfirmations = 1
+		pollInterval = devXMRLockPollInterval
+	}
+
+	defer s.info.SetMoneroProgress(0, 0)
+	log.Infof("waiting for %d piconero to be locked in %s with %d confirmations...",
+		expected, address, minConfirmations)
+
+	if lockHeight != 0 && s.Env() != common.Development {
+		waiter := monero.NewBlockWaiter(s, &monero.WaitConfig{
+			OnProgress: s.info.SetMoneroProgress,
+		})
+		if _, err := waiter.WaitForHeight(ctx, uint(lockHeight+minConfirmations)); err != nil {
+			return nil, fmt.Errorf("failed to confirm locked XMR: %w", err)
+		}
+	}
+
+	for {
+		lock, err := s.scanXMRLock(address, minConfirmations)
+		if err != nil {
+			log.Warnf("failed to scan for locked XMR: %s", err)
+		} else {
+			if lock.confirmed >= expected {
+				return lock, nil
+			}
+
+			if lock.target != 0 {
+				s.info.SetMoneroProgress(lock.height, lock.target)
+			}
+
+			log.Debugf("locked XMR: confirmed=%d pending=%d expected=%d", lock.confirmed, lock.pending, expected)
+		}
+
+		select {
+		case <-ctx.Done():
+			if lock != nil && lock.confirmed+lock.pending < expected {
+				return nil, fmt.Errorf("%w: got %d, expected %d",
+					errLockedXMRTooLow, lock.confirmed+lock.pending, expected)
+			}
+			return nil, fmt.Errorf("failed to confirm locked XMR: %w", ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (s *swapState) scanXMRLock(address mcrypto.Address, minConfirmations uint64) (*xmrLock, error) {
+	if err := s.Refresh(); err != nil {
+		return nil, fmt.Errorf("failed to refresh client: %w", err)
+	}
+
+	idx, err := findAccount(s, address)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.GetTransfers(idx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfers: %w", err)
+	}
+
+	return checkXMRLock(transfers, minConfirmations), nil
+}
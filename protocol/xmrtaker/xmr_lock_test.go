@@ -0,0 +1,79 @@
+package xmrtaker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/net/message"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckXMRLock(t *testing.T) {
+	transfers := &monero.GetTransfersResponse{
+		In: []*monero.Transfer{
+			{TxID: "a", Amount: 100, Confirmations: 5, Height: 10},
+			{TxID: "b", Amount: 50, Confirmations: 1, Height: 14},
+			{TxID: "c", Amount: 1000, Confirmations: 10, Height: 5, UnlockTime: 2000},
+		},
+		Pool: []*monero.Transfer{
+			{TxID: "d", Amount: 25},
+		},
+	}
+
+	lock := checkXMRLock(transfers, 2)
+	require.Equal(t, uint64(100), lock.confirmed)
+	require.Equal(t, uint64(75), lock.pending)
+	require.Equal(t, []string{"a"}, lock.txIDs)
+	require.Equal(t, uint(15), lock.height)
+	require.Equal(t, uint(16), lock.target)
+
+	lock = checkXMRLock(transfers, 1)
+	require.Equal(t, uint64(150), lock.confirmed)
+	require.Equal(t, uint64(25), lock.pending)
+	require.Equal(t, uint(0), lock.target)
+}
+
+func TestSwapState_MoneroConfirmations(t *testing.T) {
+	s := &swapState{}
+	require.Equal(t, uint64(defaultMoneroConfirmations), s.moneroConfirmations())
+
+	s.speedTier = &types.SpeedTier{MoneroConfirmations: 1}
+	require.Equal(t, uint64(1), s.moneroConfirmations())
+
+	// the configured minimum applies whatever the speed tier
+	s.readyPolicy.MoneroConfirmations = 10
+	require.Equal(t, uint64(10), s.moneroConfirmations())
+}
+
+func TestCheckXMRLockProof(t *testing.T) {
+	require.NoError(t, checkXMRLockProof(&monero.CheckTxKeyResponse{Received: 100, InPool: true}, 100))
+
+	err := checkXMRLockProof(&monero.CheckTxKeyResponse{Received: 99}, 100)
+	require.True(t, errors.Is(err, errLockedXMRTooLow))
+}
+
+func TestPlausibleLockHeight(t *testing.T) {
+	require.Equal(t, uint64(0), plausibleLockHeight(0, 1000))
+	require.Equal(t, uint64(990), plausibleLockHeight(990, 1000))
+	// the maker's node may be a little ahead of ours
+	require.Equal(t, uint64(1005), plausibleLockHeight(1005, 1000))
+	require.Equal(t, uint64(0), plausibleLockHeight(1000+lockHeightTolerance+1, 1000))
+}
+
+func TestSwapState_VerifyXMRLockProof_Missing(t *testing.T) {
+	s := &swapState{
+		info: swap.NewInfo(types.Hash{}, types.ProvidesETH, 1, 1, 1, types.ExpectingKeys, nil),
+	}
+
+	// peers that don't support lock proofs fall back to the view key
+	require.NoError(t, s.verifyXMRLockProof(&message.NotifyXMRLock{}, ""))
+
+	s.info.SetPeerVersion(&types.VersionInfo{ProtocolFeatures: []string{common.FeatureXMRLockProof}})
+	err := s.verifyXMRLockProof(&message.NotifyXMRLock{}, "")
+	require.Equal(t, errMissingXMRLockProof, err)
+}
@@ -0,0 +1,149 @@
+package recovery
+
+import (
+	"errors"
+
+	"github.com/noot/atomic-swap/common"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/crypto/secp256k1"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	ethsecp256k1 "github.com/ethereum/go-ethereum/crypto/secp256k1"
+)
+
+// ContractSwap is a swap found in the swap contract's New logs.
+type ContractSwap struct {
+	ID    [32]byte
+	Swap  swapfactory.SwapFactorySwap
+	Block uint64
+}
+
+// FindContractSwap finds the swap created with the given swap secret in the New logs of the swap
+// contract at the given address, starting at the given block. The secret's commitment is either the
+// swap's claim or refund key, and the rest of the swap is decoded from the transaction that created it.
+func FindContractSwap(b backend.Backend, contractAddr ethcommon.Address, sk *mcrypto.PrivateSpendKey,
+	fromBlock uint64) (*ContractSwap, error) {
+	contract, err := b.NewSwapFactory(contractAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := contract.FilterNew(&bind.FilterOpts{
+		Start:   fromBlock,
+		Context: b.Ctx(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close() //nolint:errcheck
+
+	commitment := secretCommitment(sk)
+	for iter.Next() {
+		ev := iter.Event
+		if ev.ClaimKey != commitment && ev.RefundKey != commitment {
+			continue
+		}
+
+		log.Infof("found swap %x in block %d", ev.SwapID, ev.Raw.BlockNumber)
+		swap, err := swapFromNewLog(b, ev)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ContractSwap{
+			ID:    ev.SwapID,
+			Swap:  swap,
+			Block: ev.Raw.BlockNumber,
+		}, nil
+	}
+
+	if err = iter.Error(); err != nil {
+		return nil, err
+	}
+
+	return nil, errContractSwapNotFound
+}
+
+// FillContractSwap fills in the contract swap of an info file that's missing it, eg. because only the
+// swap's keys survived, by finding it in the contract's New logs from the given block. If the info
+// file has no contract address, the backend's is used. If no swap is found, the info file is left as
+// it is, as no ether was locked.
+func FillContractSwap(b backend.Backend, infofile *pcommon.InfoFileContents, fromBlock uint64) error {
+	if infofile.SharedSwapPrivateKey != nil || (infofile.ContractSwapID != [32]byte{}) {
+		return nil
+	}
+
+	if infofile.PrivateKeyInfo == nil {
+		return errNoSwapKeys
+	}
+
+	sk, err := privateSpendKeyFromHex(infofile.PrivateKeyInfo.PrivateSpendKey)
+	if err != nil {
+		return err
+	}
+
+	contractAddr := b.ContractAddr()
+	if infofile.ContractAddress != "" {
+		contractAddr = ethcommon.HexToAddress(infofile.ContractAddress)
+	}
+
+	found, err := FindContractSwap(b, contractAddr, sk, fromBlock)
+	if errors.Is(err, errContractSwapNotFound) {
+		log.Warnf("no swap with the swap's keys was found in the logs of contract %s from block %d",
+			contractAddr, fromBlock)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	infofile.ContractAddress = contractAddr.Hex()
+	infofile.ContractSwapID = found.ID
+	infofile.ContractSwap = found.Swap
+	infofile.ContractSwapBlock = found.Block
+	return nil
+}
+
+// swapFromNewLog rebuilds the swap of a New event from the transaction that emitted it, checking that
+// the swap hashes to the event's swap ID.
+func swapFromNewLog(b backend.Backend, ev *swapfactory.SwapFactoryNew) (swapfactory.SwapFactorySwap, error) {
+	tx, _, err := b.TransactionByHash(b.Ctx(), ev.Raw.TxHash)
+	if err != nil {
+		return swapfactory.SwapFactorySwap{}, err
+	}
+
+	// the swap's owner is the transaction's sender
+	owner, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(b.ChainID()), tx)
+	if err != nil {
+		return swapfactory.SwapFactorySwap{}, err
+	}
+
+	swap, err := swapfactory.GetSwapFromNewSwapTx(tx, owner, ev.Timeout0, ev.Timeout1)
+	if err != nil {
+		return swapfactory.SwapFactorySwap{}, err
+	}
+
+	id, err := swapfactory.SwapID(swap)
+	if err != nil {
+		return swapfactory.SwapFactorySwap{}, err
+	}
+
+	if id != ev.SwapID {
+		return swapfactory.SwapFactorySwap{}, errContractSwapMismatch
+	}
+
+	return swap, nil
+}
+
+// secretCommitment returns the commitment to the swap secret that the contract stores as the swap's claim
+// or refund key, ie. the keccak256 hash of the secret's secp256k1 public key.
+func secretCommitment(sk *mcrypto.PrivateSpendKey) [32]byte {
+	// the secp256k1 scalar is the big-endian form of the little-endian ed25519 scalar
+	x, y := ethsecp256k1.S256().ScalarBaseMult(common.Reverse(sk.Bytes()))
+	return secp256k1.NewPublicKeyFromBigInt(x, y).Keccak256()
+}
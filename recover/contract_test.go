@@ -0,0 +1,17 @@
+package recovery
+
+import (
+	"testing"
+
+	pcommon "github.com/noot/atomic-swap/protocol"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretCommitment(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		keys, err := pcommon.GenerateKeysAndProof()
+		require.NoError(t, err)
+		require.Equal(t, keys.Secp256k1PublicKey.Keccak256(), secretCommitment(keys.PrivateKeyPair.SpendKey()))
+	}
+}
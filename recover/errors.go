@@ -0,0 +1,15 @@
+package recovery
+
+import (
+	"errors"
+)
+
+var (
+	errNotSwapParty     = errors.New("swap is not claimable or refundable by our ethereum address")
+	errNoPendingSwap    = errors.New("no pending swap with given ID")
+	errAlreadyResolving = errors.New("swap is already being recovered")
+
+	errNoSwapKeys           = errors.New("info file doesn't contain the swap's keys")
+	errContractSwapNotFound = errors.New("no swap with the swap's keys was found in the contract's logs")
+	errContractSwapMismatch = errors.New("swap decoded from its transaction doesn't match its ID")
+)
@@ -0,0 +1,263 @@
+package recovery
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+)
+
+// Class is what can be done to recover an abandoned swap's funds, given the state of its contract swap.
+type Class string
+
+// Classes of abandoned swaps.
+const (
+	// ClassAbortable swaps never locked any ether, so they can simply be aborted
+	ClassAbortable Class = "abortable"
+	// ClassClaimable swaps can be claimed by us now
+	ClassClaimable Class = "claimable"
+	// ClassRefundable swaps can be refunded by us now
+	ClassRefundable Class = "refundable"
+	// ClassWaiting swaps can't be claimed or refunded until a timeout passes or the counterparty acts
+	ClassWaiting Class = "waiting"
+	// ClassCompleted swaps were completed on-chain; recovering them sweeps any monero we're owed
+	ClassCompleted Class = "completed"
+)
+
+// PendingSwap is an abandoned swap that's waiting to be recovered.
+type PendingSwap struct {
+	ID types.Hash `json:"id"`
+	// Status is the swap's status when it was abandoned
+	Status   types.Status       `json:"status"`
+	Class    Class              `json:"class"`
+	Provides types.ProvidesCoin `json:"provides,omitempty"`
+	Timeout0 time.Time          `json:"timeout0,omitempty"`
+	Timeout1 time.Time          `json:"timeout1,omitempty"`
+	// Resolving is set while the swap is being recovered
+	Resolving bool `json:"resolving"`
+	// Error is the last error encountered classifying or recovering the swap, if any
+	Error string `json:"error,omitempty"`
+
+	added int
+}
+
+// Manager keeps track of the swaps that were abandoned when the daemon stopped, and recovers them.
+type Manager struct {
+	b        backend.Backend
+	db       db.Database
+	basepath string
+	// called with the status a swap ended with once it's recovered
+	onResolved func(id types.Hash, status types.Status)
+
+	// swaps are recovered one at a time, as they share the monero wallet
+	resolveMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[types.Hash]*PendingSwap
+}
+
+// NewManager returns a new *Manager that recovers swaps using the info stored in the database.
+func NewManager(b backend.Backend, d db.Database, basepath string,
+	onResolved func(id types.Hash, status types.Status)) *Manager {
+	return &Manager{
+		b:          b,
+		db:         d,
+		basepath:   basepath,
+		onResolved: onResolved,
+		pending:    make(map[types.Hash]*PendingSwap),
+	}
+}
+
+// Add classifies the abandoned swap with the given ID and status, and adds it to the pending swaps.
+func (m *Manager) Add(id types.Hash, status types.Status) *PendingSwap {
+	p := &PendingSwap{
+		ID:     id,
+		Status: status,
+	}
+	m.classify(p)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p.added = len(m.pending)
+	m.pending[id] = p
+	return p.copy()
+}
+
+// Pending returns the swaps waiting to be recovered, reclassified given the current state of their
+// contract swaps, in the order they were added.
+func (m *Manager) Pending() []*PendingSwap {
+	m.mu.Lock()
+	pending := make([]*PendingSwap, 0, len(m.pending))
+	for _, p := range m.pending {
+		pending = append(pending, p.copy())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].added < pending[j].added
+	})
+
+	for _, p := range pending {
+		if p.Resolving {
+			continue
+		}
+
+		m.classify(p)
+		m.mu.Lock()
+		if stored, has := m.pending[p.ID]; has && !stored.Resolving {
+			stored.Class, stored.Provides, stored.Error = p.Class, p.Provides, p.Error
+		}
+		m.mu.Unlock()
+	}
+
+	return pending
+}
+
+// Resolve starts recovering the given pending swap in the background. Once it's recovered, it's
+// no longer pending.
+func (m *Manager) Resolve(id types.Hash) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, has := m.pending[id]
+	if !has {
+		return errNoPendingSwap
+	}
+
+	if p.Resolving {
+		return errAlreadyResolving
+	}
+
+	p.Resolving = true
+	go m.resolve(id)
+	return nil
+}
+
+func (m *Manager) resolve(id types.Hash) {
+	status, err := m.recover(id)
+
+	m.mu.Lock()
+	if err != nil {
+		log.Errorf("failed to recover swap %s: %s", id, err)
+		m.pending[id].Resolving = false
+		m.pending[id].Error = err.Error()
+		m.mu.Unlock()
+		return
+	}
+
+	delete(m.pending, id)
+	m.mu.Unlock()
+
+	if status == types.UnknownStatus {
+		log.Warnf("recovered swap %s, but couldn't tell how it ended", id)
+		return
+	}
+
+	log.Infof("recovered swap %s: status=%s", id, status)
+	if m.onResolved != nil {
+		m.onResolved(id, status)
+	}
+}
+
+func (m *Manager) recover(id types.Hash) (types.Status, error) {
+	m.resolveMu.Lock()
+	defer m.resolveMu.Unlock()
+
+	infofile, err := pcommon.FindInfoInDatabase(m.db, id)
+	if err != nil {
+		return types.UnknownStatus, err
+	}
+
+	return RecoverSwap(m.b, m.basepath, infofile)
+}
+
+// classify sets the class of the pending swap from the state of its contract swap.
+func (m *Manager) classify(p *PendingSwap) {
+	infofile, err := pcommon.FindInfoInDatabase(m.db, p.ID)
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+
+	p.Error = ""
+	// the monero was already claimable; it just needs sweeping into a wallet
+	if infofile.SharedSwapPrivateKey != nil {
+		p.Class = ClassClaimable
+		return
+	}
+
+	if (infofile.ContractSwapID == [32]byte{}) || infofile.PrivateKeyInfo == nil {
+		p.Class = ClassAbortable
+		return
+	}
+
+	p.Timeout0 = time.Unix(infofile.ContractSwap.Timeout0.Int64(), 0).UTC()
+	p.Timeout1 = time.Unix(infofile.ContractSwap.Timeout1.Int64(), 0).UTC()
+
+	if infofile.EthereumKeyIndex != nil {
+		if _, err = m.b.RegisterEthKeyIndex(*infofile.EthereumKeyIndex); err != nil {
+			p.Error = err.Error()
+			return
+		}
+	}
+
+	switch {
+	case m.b.HasEthAddress(infofile.ContractSwap.Claimer):
+		p.Provides = types.ProvidesXMR
+	case m.b.HasEthAddress(infofile.ContractSwap.Owner):
+		p.Provides = types.ProvidesETH
+	default:
+		p.Error = errNotSwapParty.Error()
+		return
+	}
+
+	contract, err := m.b.NewSwapFactory(ethcommon.HexToAddress(infofile.ContractAddress))
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+
+	stage, err := contract.Swaps(m.b.CallOpts(), infofile.ContractSwapID)
+	if err != nil {
+		p.Error = err.Error()
+		return
+	}
+
+	p.Class = classify(stage, p.Provides, infofile.ContractSwap, time.Now())
+}
+
+func (p *PendingSwap) copy() *PendingSwap {
+	c := *p
+	return &c
+}
+
+// classify returns the class of a contract swap in the given stage at the given time, from the point of
+// view of the party providing the given coin.
+func classify(stage byte, provides types.ProvidesCoin, swap swapfactory.SwapFactorySwap, now time.Time) Class {
+	t0 := time.Unix(swap.Timeout0.Int64(), 0)
+	t1 := time.Unix(swap.Timeout1.Int64(), 0)
+
+	switch {
+	case stage == swapfactory.StageInvalid, stage == swapfactory.StageCompleted:
+		return ClassCompleted
+	case provides == types.ProvidesXMR:
+		// the claimer can claim once the swap is ready or t0 has passed, until t1
+		if now.Before(t1) && (stage == swapfactory.StageReady || !now.Before(t0)) {
+			return ClassClaimable
+		}
+	default:
+		// the owner can refund until t0 if the swap isn't ready, and after t1
+		if !now.Before(t1) || (stage == swapfactory.StagePending && now.Before(t0)) {
+			return ClassRefundable
+		}
+	}
+
+	return ClassWaiting
+}
@@ -0,0 +1,42 @@
+package recovery
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	now := time.Now()
+	swap := swapfactory.SwapFactorySwap{
+		Timeout0: big.NewInt(now.Add(time.Hour).Unix()),
+		Timeout1: big.NewInt(now.Add(time.Hour * 2).Unix()),
+	}
+	beforeT0, betweenTimeouts, afterT1 := now, now.Add(time.Hour*3/2), now.Add(time.Hour*3)
+
+	for _, tc := range []struct {
+		stage    byte
+		provides types.ProvidesCoin
+		now      time.Time
+		class    Class
+	}{
+		{swapfactory.StagePending, types.ProvidesXMR, beforeT0, ClassWaiting},
+		{swapfactory.StageReady, types.ProvidesXMR, beforeT0, ClassClaimable},
+		{swapfactory.StagePending, types.ProvidesXMR, betweenTimeouts, ClassClaimable},
+		{swapfactory.StageReady, types.ProvidesXMR, afterT1, ClassWaiting},
+		{swapfactory.StagePending, types.ProvidesETH, beforeT0, ClassRefundable},
+		{swapfactory.StageReady, types.ProvidesETH, beforeT0, ClassWaiting},
+		{swapfactory.StagePending, types.ProvidesETH, betweenTimeouts, ClassWaiting},
+		{swapfactory.StageReady, types.ProvidesETH, afterT1, ClassRefundable},
+		{swapfactory.StageCompleted, types.ProvidesETH, beforeT0, ClassCompleted},
+		{swapfactory.StageCompleted, types.ProvidesXMR, afterT1, ClassCompleted},
+	} {
+		require.Equal(t, tc.class, classify(tc.stage, tc.provides, swap, tc.now),
+			"stage=%d provides=%s", tc.stage, tc.provides)
+	}
+}
@@ -91,7 +91,7 @@ func (r *recoverer) WalletFromSharedSecret(pk *mcrypto.PrivateKeyInfo) (mcrypto.
 // RecoverFromXMRMakerSecretAndContract recovers funds by either claiming ether or reclaiming locked monero.
 func (r *recoverer) RecoverFromXMRMakerSecretAndContract(b backend.Backend, basePath string,
 	xmrmakerSecret, contractAddr string, swapID [32]byte,
-	swap swapfactory.SwapFactorySwap) (*xmrmaker.RecoveryResult, error) {
+	swap swapfactory.SwapFactorySwap, swapBlock uint64) (*xmrmaker.RecoveryResult, error) {
 	bs, err := hex.DecodeString(xmrmakerSecret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode XMRMaker's secret: %w", err)
@@ -103,7 +103,7 @@ func (r *recoverer) RecoverFromXMRMakerSecretAndContract(b backend.Backend, base
 	}
 
 	addr := ethcommon.HexToAddress(contractAddr)
-	rs, err := xmrmaker.NewRecoveryState(b, basePath, bk, addr, swapID, swap)
+	rs, err := xmrmaker.NewRecoveryState(b, basePath, bk, addr, swapID, swap, swapBlock)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +113,8 @@ func (r *recoverer) RecoverFromXMRMakerSecretAndContract(b backend.Backend, base
 
 // RecoverFromXMRTakerSecretAndContract recovers funds by either claiming locked monero or refunding ether.
 func (r *recoverer) RecoverFromXMRTakerSecretAndContract(b backend.Backend, basePath string,
-	xmrtakerSecret string, swapID [32]byte, swap swapfactory.SwapFactorySwap) (*xmrtaker.RecoveryResult, error) {
+	xmrtakerSecret string, swapID [32]byte, swap swapfactory.SwapFactorySwap,
+	swapBlock uint64) (*xmrtaker.RecoveryResult, error) {
 	as, err := hex.DecodeString(xmrtakerSecret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode XMRTaker's secret: %w", err)
@@ -124,7 +125,7 @@ func (r *recoverer) RecoverFromXMRTakerSecretAndContract(b backend.Backend, base
 		return nil, err
 	}
 
-	rs, err := xmrtaker.NewRecoveryState(b, basePath, ak, swapID, swap)
+	rs, err := xmrtaker.NewRecoveryState(b, basePath, ak, swapID, swap, swapBlock)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +152,7 @@ func TestRecoverer_RecoverFromXMRMakerSecretAndContract_Claim(t *testing.T) {
 	r := newRecoverer(t)
 	basePath := path.Join(t.TempDir(), "test-infofile")
 	res, err := r.RecoverFromXMRMakerSecretAndContract(b, basePath, keys.PrivateKeyPair.SpendKey().Hex(),
-		addr.String(), swapID, swap)
+		addr.String(), swapID, swap, 0)
 	require.NoError(t, err)
 	require.True(t, res.Claimed)
 }
@@ -168,7 +168,7 @@ func TestRecoverer_RecoverFromXMRMakerSecretAndContract_Claim_afterTimeout(t *te
 	r := newRecoverer(t)
 	basePath := path.Join(t.TempDir(), "test-infofile")
 	res, err := r.RecoverFromXMRMakerSecretAndContract(b, basePath, keys.PrivateKeyPair.SpendKey().Hex(),
-		addr.String(), swapID, swap)
+		addr.String(), swapID, swap, 0)
 	require.NoError(t, err)
 	require.True(t, res.Claimed)
 }
@@ -188,7 +188,7 @@ func TestRecoverer_RecoverFromXMRTakerSecretAndContract_Refund(t *testing.T) {
 	r := newRecoverer(t)
 	basePath := path.Join(t.TempDir(), "test-infofile")
 	res, err := r.RecoverFromXMRTakerSecretAndContract(b, basePath, keys.PrivateKeyPair.SpendKey().Hex(),
-		swapID, swap)
+		swapID, swap, 0)
 	require.NoError(t, err)
 	require.True(t, res.Refunded)
 }
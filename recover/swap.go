@@ -0,0 +1,138 @@
+package recovery
+
+import (
+	"encoding/hex"
+
+	"github.com/noot/atomic-swap/common/types"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/monero"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/backend"
+	"github.com/noot/atomic-swap/protocol/xmrmaker"
+	"github.com/noot/atomic-swap/protocol/xmrtaker"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("recovery")
+
+// RecoverSwap claims, refunds or sweeps the funds of the swap with the given info file, and returns
+// the status the swap ended with. It returns types.UnknownStatus if the outcome can't be told, eg.
+// because the swap was already completed on-chain.
+func RecoverSwap(b backend.Backend, basepath string, infofile *pcommon.InfoFileContents) (types.Status, error) {
+	// the monero was already claimable; we just need to sweep it into a wallet
+	if infofile.SharedSwapPrivateKey != nil {
+		addr, err := walletFromSharedSecret(b, infofile.SharedSwapPrivateKey)
+		if err != nil {
+			return types.UnknownStatus, err
+		}
+
+		log.Infof("restored wallet from swap secrets: address=%s", addr)
+		return types.UnknownStatus, nil
+	}
+
+	// no ether was locked yet, so there's nothing to recover
+	if (infofile.ContractSwapID == [32]byte{}) || infofile.PrivateKeyInfo == nil {
+		return types.CompletedAbort, nil
+	}
+
+	contractAddr := ethcommon.HexToAddress(infofile.ContractAddress)
+	contract, err := b.NewSwapFactory(contractAddr)
+	if err != nil {
+		return types.UnknownStatus, err
+	}
+
+	stage, err := contract.Swaps(b.CallOpts(), infofile.ContractSwapID)
+	if err != nil {
+		return types.UnknownStatus, err
+	}
+
+	if stage == swapfactory.StageInvalid {
+		return types.UnknownStatus, nil
+	}
+
+	sk, err := privateSpendKeyFromHex(infofile.PrivateKeyInfo.PrivateSpendKey)
+	if err != nil {
+		return types.UnknownStatus, err
+	}
+
+	// the swap may have used an account derived from the HD wallet rather than the base account
+	if infofile.EthereumKeyIndex != nil {
+		if _, err = b.RegisterEthKeyIndex(*infofile.EthereumKeyIndex); err != nil {
+			return types.UnknownStatus, err
+		}
+	}
+
+	switch {
+	case b.HasEthAddress(infofile.ContractSwap.Claimer):
+		rs, err := xmrmaker.NewRecoveryState(b, basepath, sk, contractAddr, //nolint:govet
+			infofile.ContractSwapID, infofile.ContractSwap, infofile.ContractSwapBlock)
+		if err != nil {
+			return types.UnknownStatus, err
+		}
+
+		res, err := rs.ClaimOrRecover()
+		if err != nil {
+			return types.UnknownStatus, err
+		}
+
+		log.Infof("recovered swap %x as xmrmaker: claimed=%v txHash=%s recovered=%v address=%s",
+			infofile.ContractSwapID, res.Claimed, res.TxHash, res.Recovered, res.MoneroAddress)
+		switch {
+		case res.Claimed:
+			return types.CompletedSuccess, nil
+		case res.Recovered:
+			return types.CompletedRefund, nil
+		}
+	case b.HasEthAddress(infofile.ContractSwap.Owner):
+		rs, err := xmrtaker.NewRecoveryState(b, basepath, sk, //nolint:govet
+			infofile.ContractSwapID, infofile.ContractSwap, infofile.ContractSwapBlock)
+		if err != nil {
+			return types.UnknownStatus, err
+		}
+
+		res, err := rs.ClaimOrRefund()
+		if err != nil {
+			return types.UnknownStatus, err
+		}
+
+		log.Infof("recovered swap %x as xmrtaker: claimed=%v address=%s refunded=%v txHash=%s",
+			infofile.ContractSwapID, res.Claimed, res.MoneroAddress, res.Refunded, res.TxHash)
+		switch {
+		case res.Claimed:
+			return types.CompletedSuccess, nil
+		case res.Refunded:
+			return types.CompletedRefund, nil
+		}
+	default:
+		return types.UnknownStatus, errNotSwapParty
+	}
+
+	return types.UnknownStatus, nil
+}
+
+func walletFromSharedSecret(b backend.Backend, pk *mcrypto.PrivateKeyInfo) (mcrypto.Address, error) {
+	sk, err := privateSpendKeyFromHex(pk.PrivateSpendKey)
+	if err != nil {
+		return "", err
+	}
+
+	vk, err := mcrypto.NewPrivateViewKeyFromHex(pk.PrivateViewKey)
+	if err != nil {
+		return "", err
+	}
+
+	kp := mcrypto.NewPrivateKeyPair(sk, vk)
+	return monero.CreateMoneroWallet("recovered-wallet", b.Env(), b, kp)
+}
+
+func privateSpendKeyFromHex(s string) (*mcrypto.PrivateSpendKey, error) {
+	bz, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcrypto.NewPrivateSpendKey(bz)
+}
@@ -0,0 +1,202 @@
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/noot/atomic-swap/db"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// AdminService handles RPC requests to administer the daemon's store and the peers it connects to.
+type AdminService struct {
+	sm  SwapManager
+	db  db.Database
+	net Net
+	// adminToken must be given to ban and unban peers, and to back up or restore the database,
+	// which are only written to and read from backupDir
+	adminToken string
+	backupDir  string
+}
+
+// NewAdminService ...
+func NewAdminService(sm SwapManager, d db.Database, n Net) *AdminService {
+	return &AdminService{
+		sm:  sm,
+		db:  d,
+		net: n,
+	}
+}
+
+// BackupDBRequest ...
+type BackupDBRequest struct {
+	// Name is the name of the directory in the backup directory to write the backup to; it mustn't
+	// already exist
+	Name string `json:"name"`
+}
+
+// BackupDBResponse ...
+type BackupDBResponse struct {
+	SchemaVersion uint64 `json:"schemaVersion"`
+}
+
+// BackupDB writes a snapshot of the database to a new directory in the backup directory. It's only
+// allowed while no swaps are ongoing, so the backup doesn't capture a swap part way through. As the
+// database holds swaps' private keys, it requires the admin token.
+func (s *AdminService) BackupDB(r *http.Request, req *BackupDBRequest, resp *BackupDBResponse) error {
+	path, err := s.checkBackupRequest(r, req.Name)
+	if err != nil {
+		return err
+	}
+
+	if err = s.db.Backup(path); err != nil {
+		return err
+	}
+
+	version, err := db.SchemaVersion(s.db)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("backed up database to %s", path)
+	resp.SchemaVersion = version
+	return nil
+}
+
+// RestoreDBRequest ...
+type RestoreDBRequest struct {
+	// Name is the name of the directory in the backup directory of a backup written by
+	// admin_backupDB
+	Name string `json:"name"`
+}
+
+// RestoreDBResponse ...
+type RestoreDBResponse struct {
+	SchemaVersion uint64 `json:"schemaVersion"`
+}
+
+// RestoreDB stages a backup written by BackupDB to replace the database when the daemon is next
+// started. Like BackupDB, it's only allowed while no swaps are ongoing, and requires the admin token.
+func (s *AdminService) RestoreDB(r *http.Request, req *RestoreDBRequest, resp *RestoreDBResponse) error {
+	path, err := s.checkBackupRequest(r, req.Name)
+	if err != nil {
+		return err
+	}
+
+	version, err := s.db.StageRestore(path)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("staged restore of database from %s; it'll be applied when swapd is restarted", path)
+	resp.SchemaVersion = version
+	return nil
+}
+
+// BanPeerRequest ...
+type BanPeerRequest struct {
+	// PeerID or IP is the peer or IP address to ban; exactly one must be given
+	PeerID string `json:"peerID,omitempty"`
+	IP     string `json:"ip,omitempty"`
+	// Duration is how long to ban for, in seconds; if it's 0, the ban lasts until it's lifted with
+	// admin_unbanPeer
+	Duration uint64 `json:"duration,omitempty"`
+}
+
+// BanPeer bans a peer or IP address, disconnecting from it straight away. Banned peers and IP
+// addresses can't connect to us, and we don't connect to them. It requires the admin token.
+func (s *AdminService) BanPeer(r *http.Request, req *BanPeerRequest, _ *interface{}) error {
+	if err := checkAdminToken(r, s.adminToken); err != nil {
+		return err
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	if req.IP != "" {
+		if req.PeerID != "" {
+			return errPeerIDOrIP
+		}
+
+		return s.net.BanIP(req.IP, duration)
+	}
+
+	who, err := decodePeerID(req.PeerID)
+	if err != nil {
+		return err
+	}
+
+	s.net.BanPeer(who, duration)
+	return nil
+}
+
+// UnbanPeerRequest ...
+type UnbanPeerRequest struct {
+	// PeerID or IP is the peer or IP address to unban; exactly one must be given
+	PeerID string `json:"peerID,omitempty"`
+	IP     string `json:"ip,omitempty"`
+}
+
+// UnbanPeer lifts the ban of a peer or IP address, whether it was banned with admin_banPeer or
+// for its behaviour. It requires the admin token.
+func (s *AdminService) UnbanPeer(r *http.Request, req *UnbanPeerRequest, _ *interface{}) error {
+	if err := checkAdminToken(r, s.adminToken); err != nil {
+		return err
+	}
+
+	if req.IP != "" {
+		if req.PeerID != "" {
+			return errPeerIDOrIP
+		}
+
+		return s.net.UnbanIP(req.IP)
+	}
+
+	who, err := decodePeerID(req.PeerID)
+	if err != nil {
+		return err
+	}
+
+	return s.net.UnbanPeer(who)
+}
+
+func decodePeerID(id string) (peer.ID, error) {
+	if id == "" {
+		return "", errPeerIDOrIP
+	}
+
+	who, err := peer.Decode(id)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidPeerID, err)
+	}
+
+	return who, nil
+}
+
+// checkBackupRequest checks that the database can be backed up or restored: the request has the
+// admin token, there's a database and a backup directory, the backup's name is a directory in it,
+// and no swaps are ongoing. It returns the backup's path.
+func (s *AdminService) checkBackupRequest(r *http.Request, name string) (string, error) {
+	if err := checkAdminToken(r, s.adminToken); err != nil {
+		return "", err
+	}
+
+	if s.db == nil {
+		return "", errNoSwapDatabase
+	}
+
+	if s.backupDir == "" {
+		return "", errNoBackupDir
+	}
+
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		return "", errInvalidBackupName
+	}
+
+	if n := len(s.sm.GetOngoingSwaps()); n != 0 {
+		return "", fmt.Errorf("%w: %d swaps are ongoing", errSwapsOngoing, n)
+	}
+
+	return filepath.Join(s.backupDir, name), nil
+}
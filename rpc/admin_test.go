@@ -0,0 +1,138 @@
+package rpc
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/db"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminService_BackupAndRestoreDB(t *testing.T) {
+	d, err := db.NewDatabase(filepath.Join(t.TempDir(), db.Filename))
+	require.NoError(t, err)
+	defer d.Close() //nolint:errcheck
+
+	s := NewAdminService(new(mockSwapManager), d, new(mockNet))
+	s.adminToken = "secret"
+	s.backupDir = t.TempDir()
+
+	r, err := http.NewRequest(http.MethodPost, "http://localhost:5001", nil)
+	require.NoError(t, err)
+	err = s.BackupDB(r, &BackupDBRequest{Name: "backup"}, new(BackupDBResponse))
+	require.Equal(t, errAdminTokenRequired, err)
+	r.Header.Set("Authorization", "Bearer secret")
+
+	for _, name := range []string{"", ".", "..", "../backup", "/tmp/backup"} {
+		err = s.BackupDB(r, &BackupDBRequest{Name: name}, new(BackupDBResponse))
+		require.Equal(t, errInvalidBackupName, err, name)
+	}
+
+	backupResp := new(BackupDBResponse)
+	require.NoError(t, s.BackupDB(r, &BackupDBRequest{Name: "backup"}, backupResp))
+	require.Equal(t, db.CurrentSchemaVersion(), backupResp.SchemaVersion)
+	require.DirExists(t, filepath.Join(s.backupDir, "backup"))
+
+	restoreResp := new(RestoreDBResponse)
+	require.NoError(t, s.RestoreDB(r, &RestoreDBRequest{Name: "backup"}, restoreResp))
+	require.Equal(t, db.CurrentSchemaVersion(), restoreResp.SchemaVersion)
+	err = s.RestoreDB(r, &RestoreDBRequest{Name: "../backup"}, new(RestoreDBResponse))
+	require.Equal(t, errInvalidBackupName, err)
+
+	s.backupDir = ""
+	err = s.BackupDB(r, &BackupDBRequest{Name: "backup"}, new(BackupDBResponse))
+	require.Equal(t, errNoBackupDir, err)
+
+	s = NewAdminService(new(mockSwapManager), nil, new(mockNet))
+	s.adminToken = "secret"
+	err = s.BackupDB(r, &BackupDBRequest{Name: "backup"}, new(BackupDBResponse))
+	require.Equal(t, errNoSwapDatabase, err)
+}
+
+// mockBanNet records the peers and IP addresses banned through it.
+type mockBanNet struct {
+	mockNet
+	peers map[peer.ID]time.Duration
+	ips   map[string]time.Duration
+}
+
+func (m *mockBanNet) BanPeer(who peer.ID, duration time.Duration) {
+	m.peers[who] = duration
+}
+
+func (m *mockBanNet) BanIP(ip string, duration time.Duration) error {
+	m.ips[ip] = duration
+	return nil
+}
+
+func TestAdminService_BanPeer(t *testing.T) {
+	n := &mockBanNet{
+		peers: make(map[peer.ID]time.Duration),
+		ips:   make(map[string]time.Duration),
+	}
+	s := NewAdminService(new(mockSwapManager), nil, n)
+	s.adminToken = "secret"
+
+	r, err := http.NewRequest(http.MethodPost, "http://localhost:5001", nil)
+	require.NoError(t, err)
+	r.Header.Set("Authorization", "Bearer secret")
+
+	const peerID = "12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2"
+	who, err := peer.Decode(peerID)
+	require.NoError(t, err)
+
+	require.NoError(t, s.BanPeer(r, &BanPeerRequest{PeerID: peerID, Duration: 60}, nil))
+	require.Equal(t, time.Minute, n.peers[who])
+	require.NoError(t, s.BanPeer(r, &BanPeerRequest{IP: "1.2.3.4"}, nil))
+	require.Equal(t, time.Duration(0), n.ips["1.2.3.4"])
+
+	err = s.BanPeer(r, &BanPeerRequest{PeerID: peerID, IP: "1.2.3.4"}, nil)
+	require.Equal(t, errPeerIDOrIP, err)
+	err = s.BanPeer(r, new(BanPeerRequest), nil)
+	require.Equal(t, errPeerIDOrIP, err)
+	err = s.BanPeer(r, &BanPeerRequest{PeerID: "not a peer"}, nil)
+	require.ErrorIs(t, err, errInvalidPeerID)
+
+	require.NoError(t, s.UnbanPeer(r, &UnbanPeerRequest{PeerID: peerID}, nil))
+	err = s.UnbanPeer(r, &UnbanPeerRequest{PeerID: peerID, IP: "1.2.3.4"}, nil)
+	require.Equal(t, errPeerIDOrIP, err)
+}
+
+func TestAdminService_BanPeer_RequiresAdminToken(t *testing.T) {
+	n := &mockBanNet{
+		peers: make(map[peer.ID]time.Duration),
+		ips:   make(map[string]time.Duration),
+	}
+	s := NewAdminService(new(mockSwapManager), nil, n)
+
+	const peerID = "12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2"
+	err := s.BanPeer(nil, &BanPeerRequest{PeerID: peerID}, nil)
+	require.Equal(t, errAdminTokenNotSet, err)
+	err = s.UnbanPeer(nil, &UnbanPeerRequest{PeerID: peerID}, nil)
+	require.Equal(t, errAdminTokenNotSet, err)
+
+	s.adminToken = "secret"
+	r, err := http.NewRequest(http.MethodPost, "http://localhost:5001", nil)
+	require.NoError(t, err)
+	for _, header := range []string{"", "Bearer wrong"} {
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+
+		err = s.BanPeer(r, &BanPeerRequest{PeerID: peerID}, nil)
+		require.Equal(t, errAdminTokenRequired, err, header)
+		err = s.BanPeer(r, &BanPeerRequest{IP: "1.2.3.4"}, nil)
+		require.Equal(t, errAdminTokenRequired, err, header)
+		err = s.UnbanPeer(r, &UnbanPeerRequest{PeerID: peerID}, nil)
+		require.Equal(t, errAdminTokenRequired, err, header)
+		err = s.UnbanPeer(r, &UnbanPeerRequest{IP: "1.2.3.4"}, nil)
+		require.Equal(t, errAdminTokenRequired, err, header)
+	}
+
+	require.Empty(t, n.peers)
+	require.Empty(t, n.ips)
+}
@@ -0,0 +1,59 @@
+package rpc
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AdminTokenFilename is the name of the file in the basepath that swapd writes its admin token to.
+const AdminTokenFilename = "rpc-admin-token"
+
+// NewAdminToken returns a new random admin token, and writes it to the given file, which only
+// the user running swapd may read, so that local clients can use it.
+func NewAdminToken(file string) (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	token := hex.EncodeToString(b[:])
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return "", err
+	}
+
+	// remove any token left by a previous run, as WriteFile doesn't change an existing file's mode
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if err := os.WriteFile(file, []byte(token), 0600); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// checkAdminToken checks that the request carries the admin token as a bearer token in its
+// Authorization header. Methods that reveal swap secrets or touch the database's files require
+// it; if no token is configured, they can't be called at all.
+func checkAdminToken(r *http.Request, token string) error {
+	if token == "" {
+		return errAdminTokenNotSet
+	}
+
+	if r == nil {
+		return errAdminTokenRequired
+	}
+
+	given := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		return errAdminTokenRequired
+	}
+
+	return nil
+}
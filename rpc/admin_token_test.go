@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdminToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "basepath", AdminTokenFilename)
+	token, err := NewAdminToken(file)
+	require.NoError(t, err)
+	require.Len(t, token, 64)
+
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	require.Equal(t, token, string(b))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// each run gets a new token
+	next, err := NewAdminToken(file)
+	require.NoError(t, err)
+	require.NotEqual(t, token, next)
+}
+
+func TestCheckAdminToken(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPost, "http://localhost:5001", nil)
+	require.NoError(t, err)
+
+	require.Equal(t, errAdminTokenNotSet, checkAdminToken(r, ""))
+	require.Equal(t, errAdminTokenRequired, checkAdminToken(r, "secret"))
+	require.Equal(t, errAdminTokenRequired, checkAdminToken(nil, "secret"))
+
+	r.Header.Set("Authorization", "Bearer wrong")
+	require.Equal(t, errAdminTokenRequired, checkAdminToken(r, "secret"))
+
+	r.Header.Set("Authorization", "Bearer secret")
+	require.NoError(t, checkAdminToken(r, "secret"))
+}
+
+func TestSwapService_ExportRecoveryBundle_RequiresAdminToken(t *testing.T) {
+	s := NewSwapService(new(mockSwapManager), nil, nil, new(mockNet), nil, nil, nil)
+	err := s.ExportRecoveryBundle(nil, &ExportRecoveryBundleRequest{}, new(ExportRecoveryBundleResponse))
+	require.Equal(t, errAdminTokenNotSet, err)
+
+	s.adminToken = "secret"
+	err = s.ExportRecoveryBundle(nil, &ExportRecoveryBundleRequest{}, new(ExportRecoveryBundleResponse))
+	require.Equal(t, errAdminTokenRequired, err)
+}
@@ -0,0 +1,28 @@
+package rpc
+
+import (
+	"net/http"
+
+	"github.com/noot/atomic-swap/common/types"
+	pcommon "github.com/noot/atomic-swap/protocol"
+)
+
+// DaemonService handles RPC requests about the daemon itself.
+type DaemonService struct {
+	pb ProtocolBackend
+}
+
+// NewDaemonService ...
+func NewDaemonService(pb ProtocolBackend) *DaemonService {
+	return &DaemonService{
+		pb: pb,
+	}
+}
+
+// Version returns the daemon's version, the protocol features and contract versions it
+// supports, and the chains it's configured for. The same info is sent to the counterparty
+// when a swap begins.
+func (s *DaemonService) Version(_ *http.Request, _ *interface{}, resp *types.VersionInfo) error {
+	*resp = *pcommon.NewVersionInfo(s.pb.Env(), s.pb.ChainID())
+	return nil
+}
@@ -8,14 +8,44 @@ var (
 	// net_ errors
 	errNoOfferWithID       = errors.New("peer does not have offer with given ID")
 	errFailedToGetSwapInfo = errors.New("failed to get swap info after initiating")
+	errNoMatchingOffers    = errors.New("no discovered offers match the request")
+	errInvalidQuote        = errors.New("peer's quote does not match the request")
+	errNoQuoteWithID       = errors.New("unable to find quote with given ID")
+	errQuoteExpired        = errors.New("quote has expired")
+	errQuoteMismatch       = errors.New("quote is for a different offer or amount")
+	errNoRouteWithID       = errors.New("unable to find route with given ID")
 
 	// swap_ errors
-	errNoSwapWithID  = errors.New("unable to find swap with given ID")
-	errNoOngoingSwap = errors.New("no current ongoing swap")
-	errCannotRefund  = errors.New("cannot refund if not the ETH provider")
+	errNoSwapWithID       = errors.New("unable to find swap with given ID")
+	errNoOngoingSwap      = errors.New("no current ongoing swap")
+	errCannotRefund       = errors.New("cannot refund if not the ETH provider")
+	errCannotRefundYet    = errors.New("swap pending recovery can't be refunded now")
+	errCannotClaim        = errors.New("cannot claim if not the XMR provider")
+	errCannotClaimYet     = errors.New("swap pending recovery can't be claimed now")
+	errCannotConfirmReady = errors.New("cannot confirm ready if not the ETH provider")
+	errInvalidSwapID      = errors.New("invalid swap ID; must be a hex-encoded 32-byte hash")
+	errNoDatabase         = errors.New("swap recovery info is not stored in a database")
+	errInvalidStatus      = errors.New("invalid swap status")
+	errInvalidLimit       = errors.New("limit must not be negative")
+
+	// personal_ errors
+	errNoUtilizationTracker = errors.New("capital utilization tracking is not enabled")
+	errNoPreflightChecker   = errors.New("preflight checks are not enabled")
+
+	// admin_ errors
+	errNoSwapDatabase     = errors.New("swaps are not stored in a database")
+	errNoBackupDir        = errors.New("swapd has no database backup directory")
+	errInvalidBackupName  = errors.New("backup name must be the name of a directory in the backup directory")
+	errSwapsOngoing       = errors.New("cannot back up or restore the database while swaps are ongoing")
+	errPeerIDOrIP         = errors.New("must provide exactly one of peerID or ip")
+	errInvalidPeerID      = errors.New("invalid peer ID")
+	errAdminTokenNotSet   = errors.New("this method is disabled, as swapd has no admin token")
+	errAdminTokenRequired = errors.New("method requires swapd's admin token as an Authorization bearer token")
 
 	// ws errors
-	errUnimplemented     = errors.New("unimplemented")
-	errInvalidMethod     = errors.New("invalid method")
-	errSignerNotRequired = errors.New("signer not required")
+	errUnimplemented        = errors.New("unimplemented")
+	errInvalidMethod        = errors.New("invalid method")
+	errSignerNotRequired    = errors.New("signer not required")
+	errRateLimited          = errors.New("too many requests; slow down")
+	errTooManySubscriptions = errors.New("too many subscriptions on this connection")
 )
@@ -0,0 +1,318 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/events"
+	"github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/rpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcService implements the gRPC API on top of the JSON-RPC services, so that both APIs behave
+// the same.
+type grpcService struct {
+	pb.UnimplementedSwapdServer
+
+	ctx context.Context
+	ns  *NetService
+	ss  *SwapService
+	sm  SwapManager
+}
+
+// newGRPCServer returns a gRPC server serving the Swapd service, over TLS if tlsConfig isn't nil.
+func newGRPCServer(ctx context.Context, ns *NetService, ss *SwapService, sm SwapManager,
+	tlsConfig *tls.Config) *grpc.Server {
+	var opts []grpc.ServerOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s := grpc.NewServer(opts...)
+	pb.RegisterSwapdServer(s, &grpcService{
+		ctx: ctx,
+		ns:  ns,
+		ss:  ss,
+		sm:  sm,
+	})
+	return s
+}
+
+// MakeOffer creates and advertises a new offer to swap our XMR for ETH.
+func (s *grpcService) MakeOffer(_ context.Context, req *pb.MakeOfferRequest) (*pb.MakeOfferResponse, error) {
+	resp := new(rpctypes.MakeOfferResponse)
+	err := s.ns.MakeOffer(nil, &rpctypes.MakeOfferRequest{
+		MinimumAmount: req.MinimumAmount,
+		MaximumAmount: req.MaximumAmount,
+		ExchangeRate:  types.ExchangeRate(req.ExchangeRate),
+		SpeedTiers:    speedTiersFromProto(req.SpeedTiers),
+		OfferTerms: &rpctypes.OfferTerms{
+			MinimumTakerAmount:    req.MinimumTakerAmount,
+			Spread:                req.Spread,
+			RequiredConfirmations: req.RequiredConfirmations,
+			TTL:                   req.Ttl,
+		},
+	}, resp)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &pb.MakeOfferResponse{
+		OfferId:  resp.ID,
+		InfoFile: resp.InfoFile,
+	}, nil
+}
+
+// GetOffers returns the offers we've made that are still available.
+func (s *grpcService) GetOffers(context.Context, *pb.GetOffersRequest) (*pb.GetOffersResponse, error) {
+	resp := new(GetOffersResponse)
+	if err := s.ss.GetOffers(nil, nil, resp); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &pb.GetOffersResponse{Offers: offersToProto(resp.Offers)}, nil
+}
+
+// ClearOffers removes the offers with the given IDs, or all offers if none are given.
+func (s *grpcService) ClearOffers(_ context.Context, req *pb.ClearOffersRequest) (*pb.ClearOffersResponse, error) {
+	err := s.ns.ClearOffers(nil, &rpctypes.ClearOffersRequest{OfferIDs: req.OfferIds}, nil)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return new(pb.ClearOffersResponse), nil
+}
+
+// QueryPeer returns the offers of the peer at the given multiaddress.
+func (s *grpcService) QueryPeer(_ context.Context, req *pb.QueryPeerRequest) (*pb.QueryPeerResponse, error) {
+	resp := new(rpctypes.QueryPeerResponse)
+	if err := s.ns.QueryPeer(nil, &rpctypes.QueryPeerRequest{Multiaddr: req.Multiaddr}, resp); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &pb.QueryPeerResponse{Offers: offersToProto(resp.Offers)}, nil
+}
+
+// TakeOffer starts a swap taking an offer made by the peer at the given multiaddress.
+func (s *grpcService) TakeOffer(_ context.Context, req *pb.TakeOfferRequest) (*pb.TakeOfferResponse, error) {
+	resp := new(rpctypes.TakeOfferResponse)
+	err := s.ns.TakeOffer(nil, &rpctypes.TakeOfferRequest{
+		Multiaddr:       req.Multiaddr,
+		OfferID:         req.OfferId,
+		ProvidesAmount:  req.ProvidesAmount,
+		SpeedTier:       req.SpeedTier,
+		MaxExchangeRate: types.ExchangeRate(req.MaxExchangeRate),
+		MinReceivedXMR:  req.MinReceivedXmr,
+		QuoteID:         req.QuoteId,
+	}, resp)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &pb.TakeOfferResponse{InfoFile: resp.InfoFile}, nil
+}
+
+// GetOngoingSwap returns the swap with the given ID, if it's ongoing.
+func (s *grpcService) GetOngoingSwap(_ context.Context, req *pb.SwapRequest) (*pb.Swap, error) {
+	id, err := parseSwapID(s.sm, req.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	info := s.sm.GetOngoingSwap(id)
+	if info == nil {
+		return nil, grpcError(errNoOngoingSwap)
+	}
+
+	return swapToProto(info), nil
+}
+
+// GetPastSwap returns the swap with the given ID, if it's completed.
+func (s *grpcService) GetPastSwap(_ context.Context, req *pb.SwapRequest) (*pb.Swap, error) {
+	id, err := parseSwapID(s.sm, req.Id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	info := s.sm.GetPastSwap(id)
+	if info == nil {
+		return nil, grpcError(errNoSwapWithID)
+	}
+
+	return swapToProto(info), nil
+}
+
+// CancelSwap cancels the ongoing swap with the given ID, refunding our funds if they're locked.
+func (s *grpcService) CancelSwap(_ context.Context, req *pb.SwapRequest) (*pb.CancelSwapResponse, error) {
+	resp := new(CancelResponse)
+	if err := s.ss.Cancel(nil, &CancelRequest{OfferID: req.Id}, resp); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &pb.CancelSwapResponse{Status: resp.Status.String()}, nil
+}
+
+// SubscribeSwapStatus sends the swap's status, then every status it moves to, until it completes.
+func (s *grpcService) SubscribeSwapStatus(req *pb.SwapRequest, stream pb.Swapd_SubscribeSwapStatusServer) error {
+	id, err := parseSwapID(s.sm, req.Id)
+	if err != nil {
+		return grpcError(err)
+	}
+
+	info := s.sm.GetOngoingSwap(id)
+	if info == nil {
+		info = s.sm.GetPastSwap(id)
+	}
+	if info == nil {
+		return grpcError(errNoSwapWithID)
+	}
+
+	statusCh := info.Subscribe()
+	for {
+		select {
+		case st, ok := <-statusCh:
+			if !ok {
+				return nil
+			}
+
+			err := stream.Send(&pb.SwapStatus{
+				Status:  st.String(),
+				Ongoing: st.IsOngoing(),
+			})
+			if err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		case <-s.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// SubscribeEvents sends every swap and offer event until the stream is cancelled.
+func (s *grpcService) SubscribeEvents(_ *pb.SubscribeEventsRequest, stream pb.Swapd_SubscribeEventsServer) error {
+	eventCh, unsubscribe := s.sm.Events().Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case e := <-eventCh:
+			evt := &pb.Event{
+				Type:   string(e.Type),
+				SwapId: e.SwapID.String(),
+				Time:   timestamppb.New(e.Time),
+				Offer:  offerToProto(e.Offer),
+			}
+			if e.Type != events.OfferMade {
+				evt.Status = e.Status.String()
+			}
+
+			if err := stream.Send(evt); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		case <-s.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// grpcError returns the error with the gRPC status code that best describes it.
+func grpcError(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, errNoSwapWithID), errors.Is(err, errNoOngoingSwap), errors.Is(err, errNoOfferWithID):
+		code = codes.NotFound
+	case errors.Is(err, errInvalidSwapID):
+		code = codes.InvalidArgument
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func speedTiersFromProto(tiers []*pb.SpeedTier) []*types.SpeedTier {
+	if len(tiers) == 0 {
+		return nil
+	}
+
+	res := make([]*types.SpeedTier, len(tiers))
+	for i, t := range tiers {
+		res[i] = &types.SpeedTier{
+			Name:                t.Name,
+			MoneroConfirmations: t.MoneroConfirmations,
+			Timeout:             t.Timeout,
+		}
+	}
+	return res
+}
+
+func offersToProto(offers []*types.Offer) []*pb.Offer {
+	res := make([]*pb.Offer, len(offers))
+	for i, o := range offers {
+		res[i] = offerToProto(o)
+	}
+	return res
+}
+
+func offerToProto(o *types.Offer) *pb.Offer {
+	if o == nil {
+		return nil
+	}
+
+	res := &pb.Offer{
+		Id:                    o.GetID().String(),
+		Provides:              string(o.Provides),
+		MinimumAmount:         o.MinimumAmount,
+		MaximumAmount:         o.MaximumAmount,
+		ExchangeRate:          float64(o.ExchangeRate),
+		MinimumTakerAmount:    o.MinimumTakerAmount,
+		Spread:                o.Spread,
+		RequiredConfirmations: o.RequiredConfirmations,
+		Maker:                 o.Maker,
+	}
+
+	for _, t := range o.SpeedTiers {
+		res.SpeedTiers = append(res.SpeedTiers, &pb.SpeedTier{
+			Name:                t.Name,
+			MoneroConfirmations: t.MoneroConfirmations,
+			Timeout:             t.Timeout,
+		})
+	}
+
+	if o.ExpiresAt != nil {
+		res.ExpiresAt = timestamppb.New(*o.ExpiresAt)
+	}
+
+	return res
+}
+
+func swapToProto(info *swap.Info) *pb.Swap {
+	res := &pb.Swap{
+		Id:             info.ID().String(),
+		Provided:       string(info.Provides()),
+		ProvidedAmount: info.ProvidedAmount(),
+		ReceivedAmount: info.ReceivedAmount(),
+		ExchangeRate:   float64(info.ExchangeRate()),
+		Status:         info.Status().String(),
+		TxHashes:       txHashStrings(info.TxHashes()),
+		PeerId:         info.PeerID(),
+		StartTime:      timestamppb.New(info.StartTime()),
+	}
+
+	if end := info.EndTime(); !end.IsZero() {
+		res.EndTime = timestamppb.New(end)
+	}
+
+	return res
+}
@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+	"github.com/noot/atomic-swap/rpc/pb"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient serves the gRPC API over an in-memory connection, and returns a client of it.
+func newTestGRPCClient(t *testing.T, sm SwapManager) pb.SwapdClient {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, sm)
+	ss := NewSwapService(sm, new(mockXMRTaker), nil, new(mockNet), nil, nil, nil)
+	s := newGRPCServer(ctx, ns, ss, sm, nil)
+	t.Cleanup(s.Stop)
+
+	ln := bufconn.Listen(1 << 20)
+	go func() {
+		_ = s.Serve(ln)
+	}()
+
+	conn, err := grpc.DialContext(ctx, "bufconn",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return ln.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return pb.NewSwapdClient(conn)
+}
+
+func TestGRPC_Swaps(t *testing.T) {
+	sm := swap.NewManager()
+	past := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 1, 10, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(past))
+	past.SetStatus(types.CompletedSuccess)
+	sm.CompleteOngoingSwap(past.ID())
+
+	ongoing := swap.NewInfo(types.Hash{2}, types.ProvidesXMR, 10, 1, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(ongoing))
+
+	c := newTestGRPCClient(t, sm)
+	ctx := context.Background()
+
+	resp, err := c.GetPastSwap(ctx, &pb.SwapRequest{Id: past.ID().String()})
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedSuccess.String(), resp.Status)
+	require.Equal(t, string(types.ProvidesETH), resp.Provided)
+	require.NotNil(t, resp.EndTime)
+
+	resp, err = c.GetOngoingSwap(ctx, &pb.SwapRequest{Id: ongoing.ID().String()})
+	require.NoError(t, err)
+	require.Equal(t, types.ExpectingKeys.String(), resp.Status)
+	require.Nil(t, resp.EndTime)
+
+	_, err = c.GetOngoingSwap(ctx, &pb.SwapRequest{Id: past.ID().String()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = c.GetPastSwap(ctx, &pb.SwapRequest{Id: "not an ID"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPC_SubscribeSwapStatus(t *testing.T) {
+	sm := swap.NewManager()
+	info := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 1, 10, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(info))
+
+	c := newTestGRPCClient(t, sm)
+	stream, err := c.SubscribeSwapStatus(context.Background(), &pb.SwapRequest{Id: info.ID().String()})
+	require.NoError(t, err)
+
+	// the current status is sent first
+	st, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, types.ExpectingKeys.String(), st.Status)
+	require.True(t, st.Ongoing)
+
+	info.SetStatus(types.ETHLocked)
+	info.SetStatus(types.CompletedSuccess)
+	sm.CompleteOngoingSwap(info.ID())
+
+	for _, expected := range []types.Status{types.ETHLocked, types.CompletedSuccess} {
+		st, err = stream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, expected.String(), st.Status)
+	}
+	require.False(t, st.Ongoing)
+
+	// the stream ends once the swap completes
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+
+	// completed swaps' final status is sent
+	stream, err = c.SubscribeSwapStatus(context.Background(), &pb.SwapRequest{Id: info.ID().String()})
+	require.NoError(t, err)
+	st, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, types.CompletedSuccess.String(), st.Status)
+}
+
+func TestGRPC_QueryPeer(t *testing.T) {
+	c := newTestGRPCClient(t, swap.NewManager())
+	resp, err := c.QueryPeer(context.Background(), &pb.QueryPeerRequest{Multiaddr: testMultiaddr})
+	require.NoError(t, err)
+	require.Len(t, resp.Offers, 1)
+}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/net"
+	"github.com/noot/atomic-swap/protocol/xmrtaker"
 
 	"github.com/libp2p/go-libp2p-core/peer"
 )
@@ -22,6 +24,20 @@ type Net interface {
 	Advertise()
 	Discover(provides types.ProvidesCoin, searchTime time.Duration) ([]peer.AddrInfo, error)
 	Query(who peer.AddrInfo) (*net.QueryResponse, error)
+	MarketOffers() []*net.PeerOffers
+	SubscribeOffers() (<-chan *net.SeenOffer, func())
+	Orderbook() (*net.Orderbook, error)
+	QueryPrivateOffer(who peer.AddrInfo, token string) (*types.Offer, error)
+	PeerScore(who peer.ID) *net.PeerScore
+	PeerScores() []*net.PeerScore
+	PeerStats() []*net.PeerStats
+	ConnectedPeers() []*net.ConnectedPeer
+	BanPeer(who peer.ID, duration time.Duration)
+	UnbanPeer(who peer.ID) error
+	BanIP(ip string, duration time.Duration) error
+	UnbanIP(ip string) error
+	DHTStatus() *net.DHTStatus
+	RequestQuote(who peer.AddrInfo, req *net.QuoteRequest) (*net.Quote, error)
 	Initiate(who peer.AddrInfo, msg *net.SendKeysMessage, s common.SwapStateNet) error
 	CloseProtocolStream(types.Hash)
 }
@@ -32,15 +48,24 @@ type NetService struct {
 	xmrtaker XMRTaker
 	xmrmaker XMRMaker
 	sm       SwapManager
+	selector *offerSelector
+	router   *xmrtaker.Router
+
+	// quotes we've received, by ID
+	quotesMu sync.Mutex
+	quotes   map[types.Hash]*net.Quote
 }
 
 // NewNetService ...
-func NewNetService(net Net, xmrtaker XMRTaker, xmrmaker XMRMaker, sm SwapManager) *NetService {
+func NewNetService(n Net, taker XMRTaker, maker XMRMaker, sm SwapManager) *NetService {
 	return &NetService{
-		net:      net,
-		xmrtaker: xmrtaker,
-		xmrmaker: xmrmaker,
+		net:      n,
+		xmrtaker: taker,
+		xmrmaker: maker,
 		sm:       sm,
+		selector: newOfferSelector(n, sm),
+		router:   xmrtaker.NewRouter(sm),
+		quotes:   make(map[types.Hash]*net.Quote),
 	}
 }
 
@@ -87,6 +112,141 @@ func addrInfoToStrings(addrInfo peer.AddrInfo) []string {
 	return strs
 }
 
+// GetMarketOffers returns the offers that makers have published on the offers topic, without
+// querying them.
+func (s *NetService) GetMarketOffers(_ *http.Request, _ *interface{}, resp *rpctypes.GetMarketOffersResponse) error {
+	resp.Peers = []*rpctypes.PeerOffers{}
+	for _, po := range s.net.MarketOffers() {
+		resp.Peers = append(resp.Peers, &rpctypes.PeerOffers{
+			PeerID:     po.Peer.ID.String(),
+			Multiaddrs: addrInfoToStrings(po.Peer),
+			Offers:     po.Offers,
+		})
+	}
+
+	return nil
+}
+
+// Orderbook returns the offers of the makers found by the orderbook crawler, and when each maker
+// was last queried.
+func (s *NetService) Orderbook(_ *http.Request, _ *interface{}, resp *rpctypes.OrderbookResponse) error {
+	ob, err := s.net.Orderbook()
+	if err != nil {
+		return err
+	}
+
+	resp.Peers = []*rpctypes.OrderbookEntry{}
+	for _, entry := range ob.Makers {
+		resp.Peers = append(resp.Peers, &rpctypes.OrderbookEntry{
+			PeerOffers: rpctypes.PeerOffers{
+				PeerID:     entry.Peer.ID.String(),
+				Multiaddrs: addrInfoToStrings(entry.Peer),
+				Offers:     entry.Offers,
+			},
+			UpdatedAt: entry.UpdatedAt,
+		})
+	}
+
+	if !ob.LastCrawl.IsZero() {
+		resp.LastCrawl = &ob.LastCrawl
+	}
+
+	return nil
+}
+
+// GetPeerScores returns the reputation scores of the peers we've swapped with or received
+// invalid messages from.
+func (s *NetService) GetPeerScores(_ *http.Request, _ *interface{}, resp *rpctypes.GetPeerScoresResponse) error {
+	resp.Peers = []*rpctypes.PeerScore{}
+	now := time.Now()
+	for _, score := range s.net.PeerScores() {
+		ps := &rpctypes.PeerScore{
+			PeerID:          score.Peer.String(),
+			CompletedSwaps:  score.CompletedSwaps,
+			AbortedSwaps:    score.AbortedSwaps,
+			RefundedSwaps:   score.RefundedSwaps,
+			InvalidMessages: score.InvalidMessages,
+			Score:           score.Score,
+			Deprioritized:   score.Deprioritized(),
+			Banned:          score.Banned,
+		}
+
+		if now.Before(score.BannedUntil) {
+			until := score.BannedUntil
+			ps.BannedUntil = &until
+		}
+
+		resp.Peers = append(resp.Peers, ps)
+	}
+
+	return nil
+}
+
+// PeerStats returns the bandwidth we've used with each peer we're connected to or have exchanged
+// data with, and its latency.
+func (s *NetService) PeerStats(_ *http.Request, _ *interface{}, resp *rpctypes.GetPeerStatsResponse) error {
+	resp.Peers = []*rpctypes.PeerStats{}
+	for _, stats := range s.net.PeerStats() {
+		resp.Peers = append(resp.Peers, &rpctypes.PeerStats{
+			PeerID:    stats.Peer.String(),
+			Connected: stats.Connected,
+			BytesIn:   stats.BytesIn,
+			BytesOut:  stats.BytesOut,
+			RateIn:    stats.RateIn,
+			RateOut:   stats.RateOut,
+			LatencyMs: float64(stats.Latency) / float64(time.Millisecond),
+		})
+	}
+
+	return nil
+}
+
+// Peers returns the peers we're currently connected to, with their addresses, the protocols they
+// support, how long we've been connected and their reputation scores.
+func (s *NetService) Peers(_ *http.Request, _ *interface{}, resp *rpctypes.GetPeersResponse) error {
+	resp.Peers = []*rpctypes.ConnectedPeer{}
+	peers := s.net.ConnectedPeers()
+	now := time.Now()
+	for _, p := range peers {
+		cp := &rpctypes.ConnectedPeer{
+			PeerID:        p.Peer.String(),
+			Multiaddrs:    make([]string, len(p.Addrs)),
+			Protocols:     p.Protocols,
+			Direction:     strings.ToLower(p.Direction.String()),
+			ConnectedAt:   p.ConnectedAt,
+			AgeSeconds:    now.Sub(p.ConnectedAt).Seconds(),
+			Score:         p.Score.Score,
+			Deprioritized: p.Score.Deprioritized(),
+		}
+
+		for i, addr := range p.Addrs {
+			cp.Multiaddrs[i] = addr.String()
+		}
+
+		if cp.Protocols == nil {
+			cp.Protocols = []string{}
+		}
+
+		resp.Peers = append(resp.Peers, cp)
+	}
+
+	return nil
+}
+
+// GetDHTStatus returns the state of our DHT, so that operators can tell whether offers can be
+// discovered and advertised.
+func (s *NetService) GetDHTStatus(_ *http.Request, _ *interface{}, resp *rpctypes.GetDHTStatusResponse) error {
+	status := s.net.DHTStatus()
+	resp.Mode = string(status.Mode)
+	resp.LANRoutingTableSize = status.LANRoutingTableSize
+	resp.WANRoutingTableSize = status.WANRoutingTableSize
+	if !status.LastAdvertised.IsZero() {
+		resp.LastAdvertised = &status.LastAdvertised
+	}
+
+	return nil
+}
+
 // QueryPeer queries a peer for the coins they provide, their maximum amounts, and desired exchange rate.
 func (s *NetService) QueryPeer(_ *http.Request, req *rpctypes.QueryPeerRequest,
 	resp *rpctypes.QueryPeerResponse) error {
@@ -107,7 +267,7 @@ func (s *NetService) QueryPeer(_ *http.Request, req *rpctypes.QueryPeerRequest,
 // TakeOffer initiates a swap with the given peer by taking an offer they've made.
 func (s *NetService) TakeOffer(_ *http.Request, req *rpctypes.TakeOfferRequest,
 	resp *rpctypes.TakeOfferResponse) error {
-	_, infofile, err := s.takeOffer(req.Multiaddr, req.OfferID, req.ProvidesAmount)
+	_, infofile, err := s.takeOffer(req)
 	if err != nil {
 		return err
 	}
@@ -116,9 +276,13 @@ func (s *NetService) TakeOffer(_ *http.Request, req *rpctypes.TakeOfferRequest,
 	return nil
 }
 
-func (s *NetService) takeOffer(multiaddr, offerID string,
-	providesAmount float64) (<-chan types.Status, string, error) {
-	who, err := net.StringToAddrInfo(multiaddr)
+func (s *NetService) takeOffer(req *rpctypes.TakeOfferRequest) (<-chan types.Status, string, error) {
+	id, err := parseOfferID(req.OfferID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	who, err := net.StringToAddrInfo(req.Multiaddr)
 	if err != nil {
 		return nil, "", err
 	}
@@ -133,7 +297,7 @@ func (s *NetService) takeOffer(multiaddr, offerID string,
 		offer *types.Offer
 	)
 	for _, maybeOffer := range queryResp.Offers {
-		if strings.Compare(maybeOffer.GetID().String(), offerID) == 0 {
+		if maybeOffer.GetID() == id {
 			found = true
 			offer = maybeOffer
 			break
@@ -144,7 +308,60 @@ func (s *NetService) takeOffer(multiaddr, offerID string,
 		return nil, "", errNoOfferWithID
 	}
 
-	swapState, err := s.xmrtaker.InitiateProtocol(providesAmount, offer)
+	if req.QuoteID != "" {
+		var quote *net.Quote
+		quote, err = s.takeQuote(req)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// the maker honours the quote's rate instead of the offer's
+		quoted := *offer
+		quoted.ExchangeRate = quote.ExchangeRate
+		offer = &quoted
+	}
+
+	return s.initiate(who, offer, req, "")
+}
+
+// TakePrivateOffer initiates a swap with the given peer by taking the private offer that it gave out
+// the token for.
+func (s *NetService) TakePrivateOffer(_ *http.Request, req *rpctypes.TakePrivateOfferRequest,
+	resp *rpctypes.TakePrivateOfferResponse) error {
+	who, err := net.StringToAddrInfo(req.Multiaddr)
+	if err != nil {
+		return err
+	}
+
+	offer, err := s.net.QueryPrivateOffer(who, req.Token)
+	if err != nil {
+		return err
+	}
+
+	takeReq := &rpctypes.TakeOfferRequest{
+		Multiaddr:       req.Multiaddr,
+		OfferID:         offer.GetID().String(),
+		ProvidesAmount:  req.ProvidesAmount,
+		SpeedTier:       req.SpeedTier,
+		MaxExchangeRate: req.MaxExchangeRate,
+		MinReceivedXMR:  req.MinReceivedXMR,
+	}
+
+	_, infofile, err := s.initiate(who, offer, takeReq, req.Token)
+	if err != nil {
+		return err
+	}
+
+	resp.OfferID = takeReq.OfferID
+	resp.InfoFile = infofile
+	return nil
+}
+
+// initiate takes the given offer from the peer. The token is only needed for private offers.
+func (s *NetService) initiate(who peer.AddrInfo, offer *types.Offer,
+	req *rpctypes.TakeOfferRequest, token string) (<-chan types.Status, string, error) {
+	id := offer.GetID()
+	swapState, err := s.xmrtaker.InitiateProtocol(req.ProvidesAmount, offer, req.SpeedTier, req.SlippageLimits())
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to initiate protocol: %w", err)
 	}
@@ -154,25 +371,229 @@ func (s *NetService) takeOffer(multiaddr, offerID string,
 		return nil, "", err
 	}
 
-	skm.OfferID = offerID
-	skm.ProvidedAmount = providesAmount
+	skm.OfferID = id.String()
+	skm.ProvidedAmount = req.ProvidesAmount
+	skm.QuoteID = req.QuoteID
+	skm.OfferToken = token
 
 	if err = s.net.Initiate(who, skm, swapState); err != nil {
 		_ = swapState.Exit()
 		return nil, "", err
 	}
 
-	id, err := offerIDStringToHash(offerID)
-	if err != nil {
-		return nil, "", err
-	}
+	s.selector.recordTake(who.ID, id)
 
 	info := s.sm.GetOngoingSwap(id)
 	if info == nil {
 		return nil, "", errFailedToGetSwapInfo
 	}
 
-	return info.StatusCh(), swapState.InfoFile(), nil
+	return info.Subscribe(), swapState.InfoFile(), nil
+}
+
+// TakeBestOffer discovers makers and takes the best offer that matches the request, ranking offers by
+// exchange rate, the maker's latency, and how our past swaps with the maker went. If taking the best
+// offer fails, the next best is tried.
+func (s *NetService) TakeBestOffer(_ *http.Request, req *rpctypes.TakeBestOfferRequest,
+	resp *rpctypes.TakeBestOfferResponse) error {
+	searchTime, err := time.ParseDuration(fmt.Sprintf("%ds", req.SearchTime))
+	if err != nil {
+		return err
+	}
+
+	if searchTime == 0 {
+		searchTime = defaultSearchTime
+	}
+
+	ranked, err := s.selector.rank(req, searchTime)
+	if err != nil {
+		return err
+	}
+
+	if len(ranked) == 0 {
+		return errNoMatchingOffers
+	}
+
+	for _, r := range ranked {
+		log.Infof("taking offer %s from peer %s: exchange rate=%v latency=%s success rate=%v",
+			r.offer.GetID(), r.who.ID, r.offer.ExchangeRate, r.latency, r.successRate)
+
+		takeReq := &rpctypes.TakeOfferRequest{
+			OfferID:         r.offer.GetID().String(),
+			ProvidesAmount:  req.ProvidesAmount,
+			SpeedTier:       req.SpeedTier,
+			MaxExchangeRate: req.MaxExchangeRate,
+			MinReceivedXMR:  req.MinReceivedXMR,
+		}
+
+		var infofile string
+		_, infofile, err = s.initiate(r.who, r.offer, takeReq, "")
+		if err != nil {
+			log.Warnf("failed to take offer %s: %s", r.offer.GetID(), err)
+			continue
+		}
+
+		if addrs := addrInfoToStrings(r.who); len(addrs) != 0 {
+			resp.Multiaddr = addrs[0]
+		}
+		resp.OfferID = takeReq.OfferID
+		resp.ExchangeRate = r.offer.ExchangeRate
+		resp.InfoFile = infofile
+		return nil
+	}
+
+	return err
+}
+
+// GetQuote asks the peer for a firm quote on taking one of its offers with the given amount. The quote
+// can then be used with net_takeOffer until it expires.
+func (s *NetService) GetQuote(_ *http.Request, req *rpctypes.GetQuoteRequest,
+	resp *rpctypes.GetQuoteResponse) error {
+	who, err := net.StringToAddrInfo(req.Multiaddr)
+	if err != nil {
+		return err
+	}
+
+	quote, err := s.net.RequestQuote(who, &net.QuoteRequest{
+		OfferID:        req.OfferID,
+		ProvidedAmount: req.ProvidesAmount,
+	})
+	if err != nil {
+		return err
+	}
+
+	if quote.OfferID != req.OfferID || quote.ProvidedAmount != req.ProvidesAmount || quote.ExchangeRate <= 0 {
+		return errInvalidQuote
+	}
+
+	s.quotesMu.Lock()
+	s.quotes[quote.ID] = quote
+	s.quotesMu.Unlock()
+
+	resp.ID = quote.ID.String()
+	resp.ExchangeRate = quote.ExchangeRate
+	resp.ReceivedAmount = quote.ExchangeRate.ToXMR(quote.ProvidedAmount)
+	resp.ExpiresAt = quote.ExpiresAt
+	return nil
+}
+
+// takeQuote removes and returns the quote the request uses, checking that it's unexpired and for
+// the request's offer and amount.
+func (s *NetService) takeQuote(req *rpctypes.TakeOfferRequest) (*net.Quote, error) {
+	id, err := types.HexToHash(req.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.quotesMu.Lock()
+	defer s.quotesMu.Unlock()
+
+	quote, has := s.quotes[id]
+	if !has {
+		return nil, errNoQuoteWithID
+	}
+
+	delete(s.quotes, id)
+
+	if time.Now().After(quote.ExpiresAt) {
+		return nil, errQuoteExpired
+	}
+
+	if quote.OfferID != req.OfferID || quote.ProvidedAmount != req.ProvidesAmount {
+		return nil, errQuoteMismatch
+	}
+
+	return quote, nil
+}
+
+// TakeRoute satisfies an order too large for any one maker by splitting it across several makers'
+// offers, preferring the best exchange rates. Each maker's swap runs separately; their statuses are
+// aggregated into one route, which can be checked with net_getRoute.
+func (s *NetService) TakeRoute(_ *http.Request, req *rpctypes.TakeRouteRequest, resp *rpctypes.RouteResponse) error {
+	searchTime, err := time.ParseDuration(fmt.Sprintf("%ds", req.SearchTime))
+	if err != nil {
+		return err
+	}
+
+	if searchTime == 0 {
+		searchTime = defaultSearchTime
+	}
+
+	discovered, err := s.selector.discoverOffers(searchTime)
+	if err != nil {
+		return err
+	}
+
+	var offers []*xmrtaker.MakerOffer
+	for _, o := range discovered {
+		if req.MaxExchangeRate != 0 && o.offer.ExchangeRate > req.MaxExchangeRate {
+			continue
+		}
+
+		offers = append(offers, &xmrtaker.MakerOffer{
+			Maker: o.who,
+			Offer: o.offer,
+		})
+	}
+
+	route, err := s.router.Route(offers, req.ProvidesAmount, func(leg *xmrtaker.RouteLeg) error {
+		return s.takeRouteLeg(leg, req.MaxExchangeRate)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.fillRouteResponse(route, resp)
+	return nil
+}
+
+func (s *NetService) takeRouteLeg(leg *xmrtaker.RouteLeg, maxExchangeRate types.ExchangeRate) error {
+	req := &rpctypes.TakeOfferRequest{
+		OfferID:         leg.Offer.GetID().String(),
+		ProvidesAmount:  leg.ProvidesAmount,
+		MaxExchangeRate: maxExchangeRate,
+	}
+
+	_, _, err := s.initiate(leg.Maker, leg.Offer, req, "")
+	return err
+}
+
+// GetRoute returns the status of a route taken with net_takeRoute, and of each of its legs.
+func (s *NetService) GetRoute(_ *http.Request, req *rpctypes.GetRouteRequest, resp *rpctypes.RouteResponse) error {
+	route := s.router.GetRoute(req.ID)
+	if route == nil {
+		return errNoRouteWithID
+	}
+
+	s.fillRouteResponse(route, resp)
+	return nil
+}
+
+func (s *NetService) fillRouteResponse(route *xmrtaker.Route, resp *rpctypes.RouteResponse) {
+	resp.ID = route.ID
+	resp.ProvidesAmount = route.ProvidesAmount
+	resp.Status, resp.ReceivedAmount = s.router.Status(route)
+	resp.Legs = make([]*rpctypes.RouteLeg, len(route.Legs))
+	for i, leg := range route.Legs {
+		l := &rpctypes.RouteLeg{
+			OfferID:        leg.Offer.GetID().String(),
+			ProvidesAmount: leg.ProvidesAmount,
+			ExchangeRate:   leg.Offer.ExchangeRate,
+		}
+
+		if addrs := addrInfoToStrings(leg.Maker); len(addrs) != 0 {
+			l.Multiaddr = addrs[0]
+		}
+
+		if leg.Err != nil {
+			l.Status = xmrtaker.RouteFailed
+			l.Error = leg.Err.Error()
+		} else if info := s.router.LegInfo(leg); info != nil {
+			l.Status = info.Status().String()
+		}
+
+		resp.Legs[i] = l
+	}
 }
 
 // TakeOfferSyncResponse ...
@@ -185,12 +606,12 @@ type TakeOfferSyncResponse struct {
 // It synchronously waits until the swap is completed before returning its status.
 func (s *NetService) TakeOfferSync(_ *http.Request, req *rpctypes.TakeOfferRequest,
 	resp *TakeOfferSyncResponse) error {
-	offerID, err := offerIDStringToHash(req.OfferID)
+	offerID, err := parseSwapID(s.sm, req.OfferID)
 	if err != nil {
 		return err
 	}
 
-	_, infofile, err := s.takeOffer(req.Multiaddr, req.OfferID, req.ProvidesAmount)
+	_, infofile, err := s.takeOffer(req)
 	if err != nil {
 		return err
 	}
@@ -228,18 +649,83 @@ func (s *NetService) MakeOffer(_ *http.Request, req *rpctypes.MakeOfferRequest,
 	return nil
 }
 
-func (s *NetService) makeOffer(req *rpctypes.MakeOfferRequest) (string, *types.OfferExtra, error) {
-	o := &types.Offer{
-		Provides:      types.ProvidesXMR,
+// MakePrivateOffer creates a new swap offer that isn't advertised. It returns the offer's token and
+// our addresses, which the maker gives to whoever it wants to take the offer.
+func (s *NetService) MakePrivateOffer(_ *http.Request, req *rpctypes.MakePrivateOfferRequest,
+	resp *rpctypes.MakePrivateOfferResponse) error {
+	o := newOffer(&rpctypes.MakeOfferRequest{
 		MinimumAmount: req.MinimumAmount,
 		MaximumAmount: req.MaximumAmount,
 		ExchangeRate:  req.ExchangeRate,
+		SpeedTiers:    req.SpeedTiers,
+		OfferTerms:    req.OfferTerms,
+	})
+
+	extra, token, err := s.xmrmaker.MakePrivateOffer(o, req.Peg)
+	if err != nil {
+		return err
 	}
 
-	offerExtra, err := s.xmrmaker.MakeOffer(o)
+	resp.ID = o.GetID().String()
+	resp.InfoFile = extra.InfoFile
+	resp.Token = token
+	resp.Addresses = s.net.Addresses()
+	return nil
+}
+
+// ClearOffers removes the offers with the given IDs, or all offers if none are given, and stops
+// advertising them.
+func (s *NetService) ClearOffers(_ *http.Request, req *rpctypes.ClearOffersRequest, _ *interface{}) error {
+	ids := make([]types.Hash, len(req.OfferIDs))
+	for i, offerID := range req.OfferIDs {
+		id, err := types.HexToHash(offerID)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	if err := s.xmrmaker.ClearOffers(ids); err != nil {
+		return err
+	}
+
+	s.net.Advertise()
+	return nil
+}
+
+func (s *NetService) makeOffer(req *rpctypes.MakeOfferRequest) (string, *types.OfferExtra, error) {
+	o := newOffer(req)
+	if err := net.ValidateTakerFilter(req.Takers); err != nil {
+		return "", nil, err
+	}
+
+	offerExtra, err := s.xmrmaker.MakeOffer(o, req.Relist, req.Peg, req.Takers)
 	if err != nil {
 		return "", nil, err
 	}
 
 	return o.GetID().String(), offerExtra, nil
 }
+
+// newOffer returns the offer with the request's terms.
+func newOffer(req *rpctypes.MakeOfferRequest) *types.Offer {
+	o := &types.Offer{
+		Provides:      types.ProvidesXMR,
+		MinimumAmount: req.MinimumAmount,
+		MaximumAmount: req.MaximumAmount,
+		ExchangeRate:  req.ExchangeRate,
+		SpeedTiers:    req.SpeedTiers,
+	}
+
+	if req.OfferTerms != nil {
+		o.MinimumTakerAmount = req.MinimumTakerAmount
+		o.Spread = req.Spread
+		o.RequiredConfirmations = req.RequiredConfirmations
+		if req.TTL != 0 {
+			expiry := time.Now().Add(time.Duration(req.TTL) * time.Second)
+			o.ExpiresAt = &expiry
+		}
+	}
+
+	return o
+}
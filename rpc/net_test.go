@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
 
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,6 +24,59 @@ func TestNet_Discover(t *testing.T) {
 	require.Equal(t, 0, len(resp.Peers))
 }
 
+func TestNet_GetDHTStatus(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	resp := new(rpctypes.GetDHTStatusResponse)
+	err := ns.GetDHTStatus(nil, nil, resp)
+	require.NoError(t, err)
+	require.Equal(t, "auto", resp.Mode)
+	require.Nil(t, resp.LastAdvertised)
+}
+
+func TestNet_Orderbook(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	resp := new(rpctypes.OrderbookResponse)
+	err := ns.Orderbook(nil, nil, resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Peers, 1)
+	require.Equal(t, peer.ID("a").String(), resp.Peers[0].PeerID)
+	require.Len(t, resp.Peers[0].Offers, 1)
+	require.False(t, resp.Peers[0].UpdatedAt.IsZero())
+	require.Nil(t, resp.LastCrawl)
+}
+
+func TestNet_PeerStats(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	resp := new(rpctypes.GetPeerStatsResponse)
+	err := ns.PeerStats(nil, nil, resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Peers, 1)
+	require.Equal(t, peer.ID("a").String(), resp.Peers[0].PeerID)
+	require.True(t, resp.Peers[0].Connected)
+	require.Equal(t, int64(100), resp.Peers[0].BytesIn)
+	require.Equal(t, int64(200), resp.Peers[0].BytesOut)
+	require.Equal(t, float64(1500), resp.Peers[0].LatencyMs)
+}
+
+func TestNet_Peers(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	resp := new(rpctypes.GetPeersResponse)
+	err := ns.Peers(nil, nil, resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Peers, 1)
+	require.Equal(t, peer.ID("a").String(), resp.Peers[0].PeerID)
+	require.Equal(t, []string{"/ip4/127.0.0.1/tcp/9934"}, resp.Peers[0].Multiaddrs)
+	require.Equal(t, []string{}, resp.Peers[0].Protocols)
+	require.Equal(t, "inbound", resp.Peers[0].Direction)
+	require.GreaterOrEqual(t, resp.Peers[0].AgeSeconds, float64(60))
+	require.Equal(t, int64(-5), resp.Peers[0].Score)
+	require.True(t, resp.Peers[0].Deprioritized)
+}
+
 func TestNet_Query(t *testing.T) {
 	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
 
@@ -51,6 +106,26 @@ func TestNet_TakeOffer(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNet_TakePrivateOffer(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	req := &rpctypes.TakePrivateOfferRequest{
+		Multiaddr:      "/ip4/127.0.0.1/tcp/9900/p2p/12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
+		Token:          "token",
+		ProvidesAmount: 1,
+	}
+
+	resp := new(rpctypes.TakePrivateOfferResponse)
+
+	err := ns.TakePrivateOffer(nil, req, resp)
+	require.NoError(t, err)
+	require.Equal(t, testSwapID.String(), resp.OfferID)
+
+	req.Token = "wrong"
+	err = ns.TakePrivateOffer(nil, req, resp)
+	require.Error(t, err)
+}
+
 func TestNet_TakeOfferSync(t *testing.T) {
 	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
 
@@ -65,3 +140,73 @@ func TestNet_TakeOfferSync(t *testing.T) {
 	err := ns.TakeOfferSync(nil, req, resp)
 	require.NoError(t, err)
 }
+
+func TestNet_GetQuote_TakeOffer(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	quoteReq := &rpctypes.GetQuoteRequest{
+		Multiaddr:      "/ip4/127.0.0.1/tcp/9900/p2p/12D3KooWDqCzbjexHEa8Rut7bzxHFpRMZyDRW1L6TGkL1KY24JH5",
+		OfferID:        testSwapID.String(),
+		ProvidesAmount: 1,
+	}
+
+	quote := new(rpctypes.GetQuoteResponse)
+	err := ns.GetQuote(nil, quoteReq, quote)
+	require.NoError(t, err)
+	require.Equal(t, types.ExchangeRate(0.1), quote.ExchangeRate)
+	require.Equal(t, float64(10), quote.ReceivedAmount)
+
+	req := &rpctypes.TakeOfferRequest{
+		Multiaddr:      quoteReq.Multiaddr,
+		OfferID:        testSwapID.String(),
+		ProvidesAmount: 2,
+		QuoteID:        quote.ID,
+	}
+
+	// the quote is for a different amount
+	err = ns.TakeOffer(nil, req, new(rpctypes.TakeOfferResponse))
+	require.ErrorIs(t, err, errQuoteMismatch)
+
+	err = ns.GetQuote(nil, quoteReq, quote)
+	require.NoError(t, err)
+	req.ProvidesAmount = 1
+	err = ns.TakeOffer(nil, req, new(rpctypes.TakeOfferResponse))
+	require.NoError(t, err)
+
+	// quotes can only be used once
+	err = ns.TakeOffer(nil, req, new(rpctypes.TakeOfferResponse))
+	require.ErrorIs(t, err, errNoQuoteWithID)
+}
+
+func TestNet_TakeRoute(t *testing.T) {
+	n := &selectionNet{
+		offers: map[peer.ID][]*types.Offer{
+			peer.ID("a"): {newTestOffer(1, 0.1)},
+			peer.ID("b"): {newTestOffer(2, 0.1)},
+		},
+	}
+	ns := NewNetService(n, new(mockXMRTaker), nil, new(mockSwapManager))
+
+	// each offer can sell at most 100 XMR, ie. 10 ETH
+	req := &rpctypes.TakeRouteRequest{
+		ProvidesAmount: 15,
+	}
+
+	resp := new(rpctypes.RouteResponse)
+	err := ns.TakeRoute(nil, req, resp)
+	require.NoError(t, err)
+	require.Len(t, resp.Legs, 2)
+	require.Equal(t, float64(15), resp.Legs[0].ProvidesAmount+resp.Legs[1].ProvidesAmount)
+
+	got := new(rpctypes.RouteResponse)
+	err = ns.GetRoute(nil, &rpctypes.GetRouteRequest{ID: resp.ID}, got)
+	require.NoError(t, err)
+	require.Equal(t, resp.ID, got.ID)
+
+	err = ns.GetRoute(nil, &rpctypes.GetRouteRequest{ID: types.Hash{9}}, got)
+	require.ErrorIs(t, err, errNoRouteWithID)
+
+	req.ProvidesAmount = 25
+	err = ns.TakeRoute(nil, req, resp)
+	require.Error(t, err)
+}
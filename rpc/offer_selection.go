@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+const (
+	// success rate of makers we haven't swapped with before
+	defaultSuccessRate = 0.5
+
+	// fraction of an offer's score lost for each second its maker takes to respond to a query
+	latencyPenaltyPerSecond = 0.01
+)
+
+// rankedOffer is an offer that matches a take request, along with what we know about its maker.
+type rankedOffer struct {
+	who         peer.AddrInfo
+	offer       *types.Offer
+	latency     time.Duration
+	successRate float64
+	score       float64
+	// whether the maker's reputation is poor, in which case its offers are tried last
+	deprioritized bool
+}
+
+// offerSelector ranks makers' offers by exchange rate, the maker's latency, and how our past swaps with
+// the maker went. Offers from makers with a poor reputation are ranked after all others.
+type offerSelector struct {
+	net Net
+	sm  SwapManager
+
+	mu sync.Mutex
+	// offers we've taken from each peer, used to look up how the swaps ended
+	taken map[peer.ID][]types.Hash
+}
+
+func newOfferSelector(net Net, sm SwapManager) *offerSelector {
+	return &offerSelector{
+		net:   net,
+		sm:    sm,
+		taken: make(map[peer.ID][]types.Hash),
+	}
+}
+
+// recordTake records that we took the offer with the given ID from the peer.
+func (s *offerSelector) recordTake(who peer.ID, offerID types.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.taken[who] = append(s.taken[who], offerID)
+}
+
+// successRate returns the fraction of our completed swaps with the peer that succeeded, or
+// defaultSuccessRate if none have completed.
+func (s *offerSelector) successRate(who peer.ID) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var completed, succeeded int
+	for _, id := range s.taken[who] {
+		info := s.sm.GetPastSwap(id)
+		if info == nil {
+			continue
+		}
+
+		completed++
+		if info.Status() == types.CompletedSuccess {
+			succeeded++
+		}
+	}
+
+	if completed == 0 {
+		return defaultSuccessRate
+	}
+
+	return float64(succeeded) / float64(completed)
+}
+
+// discoverOffers discovers makers and returns all their offers, along with what we know about them.
+// Makers whose offers we've received on the offers topic aren't queried.
+func (s *offerSelector) discoverOffers(searchTime time.Duration) ([]*rankedOffer, error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		offers []*rankedOffer
+	)
+
+	market := s.net.MarketOffers()
+	known := make(map[peer.ID]bool, len(market))
+	for _, po := range market {
+		known[po.Peer.ID] = true
+		successRate := s.successRate(po.Peer.ID)
+		deprioritized := s.net.PeerScore(po.Peer.ID).Deprioritized()
+		for _, offer := range po.Offers {
+			offers = append(offers, &rankedOffer{
+				who:           po.Peer,
+				offer:         offer,
+				latency:       po.Latency,
+				successRate:   successRate,
+				deprioritized: deprioritized,
+			})
+		}
+	}
+
+	peers, err := s.net.Discover(types.ProvidesXMR, searchTime)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, who := range peers {
+		if known[who.ID] {
+			continue
+		}
+
+		wg.Add(1)
+		go func(who peer.AddrInfo) {
+			defer wg.Done()
+
+			start := time.Now()
+			resp, err := s.net.Query(who)
+			if err != nil {
+				log.Debugf("failed to query peer %s: %s", who.ID, err)
+				return
+			}
+
+			latency := time.Since(start)
+			successRate := s.successRate(who.ID)
+			deprioritized := s.net.PeerScore(who.ID).Deprioritized()
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, offer := range resp.Offers {
+				offers = append(offers, &rankedOffer{
+					who:           who,
+					offer:         offer,
+					latency:       latency,
+					successRate:   successRate,
+					deprioritized: deprioritized,
+				})
+			}
+		}(who)
+	}
+
+	wg.Wait()
+	return offers, nil
+}
+
+// rank discovers makers and returns the offers that can be taken with the given request, best first.
+func (s *offerSelector) rank(req *rpctypes.TakeBestOfferRequest, searchTime time.Duration) ([]*rankedOffer, error) {
+	offers, err := s.discoverOffers(searchTime)
+	if err != nil {
+		return nil, err
+	}
+
+	var ranked []*rankedOffer
+	for _, o := range offers {
+		if !offerMatches(o.offer, req) {
+			continue
+		}
+
+		o.score = scoreOffer(o.offer, req.ProvidesAmount, o.latency, o.successRate)
+		ranked = append(ranked, o)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].deprioritized != ranked[j].deprioritized {
+			return !ranked[i].deprioritized
+		}
+		return ranked[i].score > ranked[j].score
+	})
+	return ranked, nil
+}
+
+// offerMatches returns whether the offer can be taken with the given request.
+func offerMatches(offer *types.Offer, req *rpctypes.TakeBestOfferRequest) bool {
+	if offer.Provides != types.ProvidesXMR || offer.ExchangeRate <= 0 {
+		return false
+	}
+
+	received := offer.ExchangeRate.ToXMR(req.ProvidesAmount)
+	if received < offer.MinimumAmount || received > offer.MaximumAmount {
+		return false
+	}
+
+	if req.MaxExchangeRate != 0 && offer.ExchangeRate > req.MaxExchangeRate {
+		return false
+	}
+
+	if req.MinReceivedXMR != 0 && received < req.MinReceivedXMR {
+		return false
+	}
+
+	if offer.CheckTake(req.ProvidesAmount) != nil {
+		return false
+	}
+
+	_, err := offer.GetSpeedTier(req.SpeedTier)
+	return err == nil
+}
+
+// scoreOffer weighs the amount of XMR we'd receive by how reliable and responsive the maker is.
+func scoreOffer(offer *types.Offer, providesAmount float64, latency time.Duration, successRate float64) float64 {
+	latencyFactor := 1 - latency.Seconds()*latencyPenaltyPerSecond
+	if latencyFactor < 0 {
+		latencyFactor = 0
+	}
+
+	return offer.ExchangeRate.ToXMR(providesAmount) * (0.5 + successRate/2) * latencyFactor
+}
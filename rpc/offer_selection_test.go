@@ -0,0 +1,177 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/net"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+// selectionNet is a mockNet with a set of makers and their offers, and the offers makers have
+// published on the offers topic.
+type selectionNet struct {
+	mockNet
+	offers  map[peer.ID][]*types.Offer
+	market  []*net.PeerOffers
+	queried []peer.ID
+	scores  map[peer.ID]*net.PeerScore
+}
+
+func (n *selectionNet) PeerScore(who peer.ID) *net.PeerScore {
+	if score, has := n.scores[who]; has {
+		return score
+	}
+	return &net.PeerScore{Peer: who}
+}
+
+func (n *selectionNet) MarketOffers() []*net.PeerOffers {
+	return n.market
+}
+
+func (n *selectionNet) Discover(provides types.ProvidesCoin, searchTime time.Duration) ([]peer.AddrInfo, error) {
+	peers := make([]peer.AddrInfo, 0, len(n.offers))
+	for id := range n.offers {
+		peers = append(peers, peer.AddrInfo{ID: id})
+	}
+	return peers, nil
+}
+
+func (n *selectionNet) Query(who peer.AddrInfo) (*net.QueryResponse, error) {
+	n.queried = append(n.queried, who.ID)
+	return &net.QueryResponse{Offers: n.offers[who.ID]}, nil
+}
+
+// selectionSwapManager is a mockSwapManager that returns past swaps with the given statuses.
+type selectionSwapManager struct {
+	mockSwapManager
+	past map[types.Hash]types.Status
+}
+
+func (m *selectionSwapManager) GetPastSwap(id types.Hash) *swap.Info {
+	status, has := m.past[id]
+	if !has {
+		return nil
+	}
+
+	return swap.NewInfo(id, types.ProvidesETH, 1, 1, 1, status, nil)
+}
+
+func newTestOffer(id byte, rate types.ExchangeRate) *types.Offer {
+	return &types.Offer{
+		ID:            types.Hash{id},
+		Provides:      types.ProvidesXMR,
+		MinimumAmount: 1,
+		MaximumAmount: 100,
+		ExchangeRate:  rate,
+	}
+}
+
+func TestOfferMatches(t *testing.T) {
+	req := &rpctypes.TakeBestOfferRequest{
+		ProvidesAmount: 1,
+	}
+
+	offer := newTestOffer(1, 0.1)
+	require.True(t, offerMatches(offer, req))
+
+	// we'd receive 10 XMR, which is below the offer's minimum
+	offer.MinimumAmount = 11
+	require.False(t, offerMatches(offer, req))
+	offer.MinimumAmount = 1
+
+	req.MaxExchangeRate = 0.05
+	require.False(t, offerMatches(offer, req))
+	req.MaxExchangeRate = 0
+
+	req.MinReceivedXMR = 11
+	require.False(t, offerMatches(offer, req))
+	req.MinReceivedXMR = 0
+
+	req.SpeedTier = "fast"
+	require.False(t, offerMatches(offer, req))
+}
+
+func TestOfferSelector_Rank(t *testing.T) {
+	peerA, peerB := peer.ID("a"), peer.ID("b")
+	n := &selectionNet{
+		offers: map[peer.ID][]*types.Offer{
+			peerA: {newTestOffer(1, 0.1), newTestOffer(2, 0.5)},
+			peerB: {newTestOffer(3, 0.09)},
+		},
+	}
+	sm := &selectionSwapManager{past: make(map[types.Hash]types.Status)}
+	s := newOfferSelector(n, sm)
+
+	req := &rpctypes.TakeBestOfferRequest{
+		ProvidesAmount: 1,
+	}
+
+	ranked, err := s.rank(req, time.Second)
+	require.NoError(t, err)
+	require.Len(t, ranked, 3)
+	require.Equal(t, types.Hash{3}, ranked[0].offer.ID)
+	require.Equal(t, types.Hash{1}, ranked[1].offer.ID)
+	require.Equal(t, types.Hash{2}, ranked[2].offer.ID)
+
+	// our past swaps with peer b failed, so peer a's slightly worse rate wins
+	for i := byte(10); i < 13; i++ {
+		s.recordTake(peerB, types.Hash{i})
+		sm.past[types.Hash{i}] = types.CompletedRefund
+	}
+	require.Zero(t, s.successRate(peerB))
+	require.Equal(t, defaultSuccessRate, s.successRate(peerA))
+
+	ranked, err = s.rank(req, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, types.Hash{1}, ranked[0].offer.ID)
+	require.Equal(t, peerA, ranked[0].who.ID)
+
+	// peer a's poor reputation puts all its offers after peer b's
+	n.scores = map[peer.ID]*net.PeerScore{peerA: {Peer: peerA, Score: -1}}
+	ranked, err = s.rank(req, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, types.Hash{3}, ranked[0].offer.ID)
+	require.Equal(t, types.Hash{1}, ranked[1].offer.ID)
+	require.Equal(t, types.Hash{2}, ranked[2].offer.ID)
+}
+
+func TestOfferSelector_Rank_MarketOffers(t *testing.T) {
+	peerA, peerB := peer.ID("a"), peer.ID("b")
+	n := &selectionNet{
+		offers: map[peer.ID][]*types.Offer{
+			peerA: {newTestOffer(1, 0.1)},
+			peerB: {newTestOffer(2, 0.09)},
+		},
+		market: []*net.PeerOffers{{
+			Peer:   peer.AddrInfo{ID: peerA},
+			Offers: []*types.Offer{newTestOffer(3, 0.08)},
+		}},
+	}
+	s := newOfferSelector(n, &selectionSwapManager{})
+
+	// makers whose offers were published aren't queried
+	ranked, err := s.rank(&rpctypes.TakeBestOfferRequest{ProvidesAmount: 1}, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, []peer.ID{peerB}, n.queried)
+	require.Len(t, ranked, 2)
+	require.Equal(t, types.Hash{3}, ranked[0].offer.ID)
+	require.Equal(t, peerA, ranked[0].who.ID)
+	require.Equal(t, types.Hash{2}, ranked[1].offer.ID)
+}
+
+func TestNet_TakeBestOffer_NoOffers(t *testing.T) {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, new(mockSwapManager))
+
+	req := &rpctypes.TakeBestOfferRequest{
+		ProvidesAmount: 1,
+	}
+
+	err := ns.TakeBestOffer(nil, req, new(rpctypes.TakeBestOfferResponse))
+	require.ErrorIs(t, err, errNoMatchingOffers)
+}
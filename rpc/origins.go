@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"net/http"
+	"strings"
+)
+
+// AnyOrigin is the allowed origin that allows requests from any website.
+const AnyOrigin = "*"
+
+// DefaultAllowedOrigins are the origins browsers may drive the daemon from by default: those the UI
+// is served from when it's run locally.
+var DefaultAllowedOrigins = []string{
+	"http://localhost:8080",
+	"http://127.0.0.1:8080",
+}
+
+// originChecker decides which websites a browser may make requests to the servers from. Requests
+// without an Origin header don't come from a website, eg. swapcli's, so they're always allowed.
+type originChecker struct {
+	any     bool
+	allowed map[string]struct{}
+}
+
+func newOriginChecker(origins []string) *originChecker {
+	c := &originChecker{
+		allowed: make(map[string]struct{}),
+	}
+
+	for _, origin := range origins {
+		if origin == AnyOrigin {
+			c.any = true
+			continue
+		}
+
+		c.allowed[normalizeOrigin(origin)] = struct{}{}
+	}
+
+	return c
+}
+
+// normalizeOrigin lower-cases the origin and strips any trailing slash, since browsers send neither.
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
+
+// isAllowed returns whether the request may be served; it's the websockets upgrader's CheckOrigin.
+func (c *originChecker) isAllowed(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || c.isAllowedOrigin(origin)
+}
+
+// isAllowedOrigin is the CORS handler's origin validator.
+func (c *originChecker) isAllowedOrigin(origin string) bool {
+	if c.any {
+		return true
+	}
+
+	_, has := c.allowed[normalizeOrigin(origin)]
+	return has
+}
+
+// filter rejects requests from websites that aren't allowed. The CORS headers alone only stop
+// the browser from reading the response, not the request from being served.
+func (c *originChecker) filter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.isAllowed(r) {
+			log.Debugf("rejected request from disallowed origin %q", r.Header.Get("Origin"))
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
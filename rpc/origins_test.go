@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOriginChecker(t *testing.T) {
+	c := newOriginChecker([]string{"https://Example.com/", "http://localhost:8080"})
+	require.True(t, c.isAllowedOrigin("https://example.com"))
+	require.True(t, c.isAllowedOrigin("http://localhost:8080"))
+	require.False(t, c.isAllowedOrigin("http://localhost:8081"))
+	require.False(t, c.isAllowedOrigin("http://example.com"))
+	require.False(t, c.isAllowedOrigin("null"))
+
+	r, err := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	require.NoError(t, err)
+	// requests from outside a browser have no origin
+	require.True(t, c.isAllowed(r))
+	r.Header.Set("Origin", "https://evil.com")
+	require.False(t, c.isAllowed(r))
+
+	anyChecker := newOriginChecker([]string{AnyOrigin})
+	require.True(t, anyChecker.isAllowed(r))
+
+	none := newOriginChecker(nil)
+	require.False(t, none.isAllowed(r))
+}
+
+func postWithOrigin(t *testing.T, origin string) *http.Response {
+	body := strings.NewReader(`{"jsonrpc":"2.0","id":"0","method":"net_addresses","params":{}}`)
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://localhost:%d", defaultRPCPort), body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", origin)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp
+}
+
+func TestServer_AllowedOrigins(t *testing.T) {
+	_ = newServer(t)
+
+	resp := postWithOrigin(t, DefaultAllowedOrigins[0])
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, DefaultAllowedOrigins[0], resp.Header.Get("Access-Control-Allow-Origin"))
+
+	resp = postWithOrigin(t, "https://evil.com")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+	header := http.Header{}
+	header.Set("Origin", "https://evil.com")
+	_, resp, err := websocket.DefaultDialer.Dial(defaultWSEndpoint(), header) //nolint:bodyclose
+	require.Equal(t, websocket.ErrBadHandshake, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header.Set("Origin", DefaultAllowedOrigins[1])
+	conn, _, err := websocket.DefaultDialer.Dial(defaultWSEndpoint(), header) //nolint:bodyclose
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
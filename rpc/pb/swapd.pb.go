@@ -0,0 +1,1682 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        v3.17.3
+// source: rpc/pb/swapd.proto
+
+// The gRPC API of swapd, which covers offers, swaps and subscriptions to their progress. It's
+// served alongside the JSON-RPC API when swapd is started with --grpc-port.
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SpeedTier struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// the number of blocks the taker waits for after the maker locks their XMR
+	MoneroConfirmations uint64 `protobuf:"varint,2,opt,name=monero_confirmations,json=moneroConfirmations,proto3" json:"monero_confirmations,omitempty"`
+	// the time in seconds between the swap being initiated on-chain and t0, and between t0 and t1
+	Timeout uint64 `protobuf:"varint,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (x *SpeedTier) Reset() {
+	*x = SpeedTier{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SpeedTier) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeedTier) ProtoMessage() {}
+
+func (x *SpeedTier) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeedTier.ProtoReflect.Descriptor instead.
+func (*SpeedTier) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SpeedTier) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SpeedTier) GetMoneroConfirmations() uint64 {
+	if x != nil {
+		return x.MoneroConfirmations
+	}
+	return 0
+}
+
+func (x *SpeedTier) GetTimeout() uint64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+type Offer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// the coin the maker provides, eg. "XMR"
+	Provides      string       `protobuf:"bytes,2,opt,name=provides,proto3" json:"provides,omitempty"`
+	MinimumAmount float64      `protobuf:"fixed64,3,opt,name=minimum_amount,json=minimumAmount,proto3" json:"minimum_amount,omitempty"`
+	MaximumAmount float64      `protobuf:"fixed64,4,opt,name=maximum_amount,json=maximumAmount,proto3" json:"maximum_amount,omitempty"`
+	ExchangeRate  float64      `protobuf:"fixed64,5,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	SpeedTiers    []*SpeedTier `protobuf:"bytes,6,rep,name=speed_tiers,json=speedTiers,proto3" json:"speed_tiers,omitempty"`
+	// unset if the offer doesn't expire
+	ExpiresAt             *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	MinimumTakerAmount    float64                `protobuf:"fixed64,8,opt,name=minimum_taker_amount,json=minimumTakerAmount,proto3" json:"minimum_taker_amount,omitempty"`
+	Spread                float64                `protobuf:"fixed64,9,opt,name=spread,proto3" json:"spread,omitempty"`
+	RequiredConfirmations uint64                 `protobuf:"varint,10,opt,name=required_confirmations,json=requiredConfirmations,proto3" json:"required_confirmations,omitempty"`
+	// the libp2p peer ID of the maker, if the offer is signed
+	Maker string `protobuf:"bytes,11,opt,name=maker,proto3" json:"maker,omitempty"`
+}
+
+func (x *Offer) Reset() {
+	*x = Offer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Offer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Offer) ProtoMessage() {}
+
+func (x *Offer) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Offer.ProtoReflect.Descriptor instead.
+func (*Offer) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Offer) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Offer) GetProvides() string {
+	if x != nil {
+		return x.Provides
+	}
+	return ""
+}
+
+func (x *Offer) GetMinimumAmount() float64 {
+	if x != nil {
+		return x.MinimumAmount
+	}
+	return 0
+}
+
+func (x *Offer) GetMaximumAmount() float64 {
+	if x != nil {
+		return x.MaximumAmount
+	}
+	return 0
+}
+
+func (x *Offer) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+func (x *Offer) GetSpeedTiers() []*SpeedTier {
+	if x != nil {
+		return x.SpeedTiers
+	}
+	return nil
+}
+
+func (x *Offer) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *Offer) GetMinimumTakerAmount() float64 {
+	if x != nil {
+		return x.MinimumTakerAmount
+	}
+	return 0
+}
+
+func (x *Offer) GetSpread() float64 {
+	if x != nil {
+		return x.Spread
+	}
+	return 0
+}
+
+func (x *Offer) GetRequiredConfirmations() uint64 {
+	if x != nil {
+		return x.RequiredConfirmations
+	}
+	return 0
+}
+
+func (x *Offer) GetMaker() string {
+	if x != nil {
+		return x.Maker
+	}
+	return ""
+}
+
+type MakeOfferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MinimumAmount         float64      `protobuf:"fixed64,1,opt,name=minimum_amount,json=minimumAmount,proto3" json:"minimum_amount,omitempty"`
+	MaximumAmount         float64      `protobuf:"fixed64,2,opt,name=maximum_amount,json=maximumAmount,proto3" json:"maximum_amount,omitempty"`
+	ExchangeRate          float64      `protobuf:"fixed64,3,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	SpeedTiers            []*SpeedTier `protobuf:"bytes,4,rep,name=speed_tiers,json=speedTiers,proto3" json:"speed_tiers,omitempty"`
+	MinimumTakerAmount    float64      `protobuf:"fixed64,5,opt,name=minimum_taker_amount,json=minimumTakerAmount,proto3" json:"minimum_taker_amount,omitempty"`
+	Spread                float64      `protobuf:"fixed64,6,opt,name=spread,proto3" json:"spread,omitempty"`
+	RequiredConfirmations uint64       `protobuf:"varint,7,opt,name=required_confirmations,json=requiredConfirmations,proto3" json:"required_confirmations,omitempty"`
+	// how long, in seconds, the offer is listed for before it expires
+	Ttl uint64 `protobuf:"varint,8,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (x *MakeOfferRequest) Reset() {
+	*x = MakeOfferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MakeOfferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MakeOfferRequest) ProtoMessage() {}
+
+func (x *MakeOfferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MakeOfferRequest.ProtoReflect.Descriptor instead.
+func (*MakeOfferRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MakeOfferRequest) GetMinimumAmount() float64 {
+	if x != nil {
+		return x.MinimumAmount
+	}
+	return 0
+}
+
+func (x *MakeOfferRequest) GetMaximumAmount() float64 {
+	if x != nil {
+		return x.MaximumAmount
+	}
+	return 0
+}
+
+func (x *MakeOfferRequest) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+func (x *MakeOfferRequest) GetSpeedTiers() []*SpeedTier {
+	if x != nil {
+		return x.SpeedTiers
+	}
+	return nil
+}
+
+func (x *MakeOfferRequest) GetMinimumTakerAmount() float64 {
+	if x != nil {
+		return x.MinimumTakerAmount
+	}
+	return 0
+}
+
+func (x *MakeOfferRequest) GetSpread() float64 {
+	if x != nil {
+		return x.Spread
+	}
+	return 0
+}
+
+func (x *MakeOfferRequest) GetRequiredConfirmations() uint64 {
+	if x != nil {
+		return x.RequiredConfirmations
+	}
+	return 0
+}
+
+func (x *MakeOfferRequest) GetTtl() uint64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+type MakeOfferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OfferId  string `protobuf:"bytes,1,opt,name=offer_id,json=offerId,proto3" json:"offer_id,omitempty"`
+	InfoFile string `protobuf:"bytes,2,opt,name=info_file,json=infoFile,proto3" json:"info_file,omitempty"`
+}
+
+func (x *MakeOfferResponse) Reset() {
+	*x = MakeOfferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MakeOfferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MakeOfferResponse) ProtoMessage() {}
+
+func (x *MakeOfferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MakeOfferResponse.ProtoReflect.Descriptor instead.
+func (*MakeOfferResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MakeOfferResponse) GetOfferId() string {
+	if x != nil {
+		return x.OfferId
+	}
+	return ""
+}
+
+func (x *MakeOfferResponse) GetInfoFile() string {
+	if x != nil {
+		return x.InfoFile
+	}
+	return ""
+}
+
+type GetOffersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetOffersRequest) Reset() {
+	*x = GetOffersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOffersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOffersRequest) ProtoMessage() {}
+
+func (x *GetOffersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOffersRequest.ProtoReflect.Descriptor instead.
+func (*GetOffersRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{4}
+}
+
+type GetOffersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Offers []*Offer `protobuf:"bytes,1,rep,name=offers,proto3" json:"offers,omitempty"`
+}
+
+func (x *GetOffersResponse) Reset() {
+	*x = GetOffersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetOffersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOffersResponse) ProtoMessage() {}
+
+func (x *GetOffersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOffersResponse.ProtoReflect.Descriptor instead.
+func (*GetOffersResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetOffersResponse) GetOffers() []*Offer {
+	if x != nil {
+		return x.Offers
+	}
+	return nil
+}
+
+type ClearOffersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OfferIds []string `protobuf:"bytes,1,rep,name=offer_ids,json=offerIds,proto3" json:"offer_ids,omitempty"`
+}
+
+func (x *ClearOffersRequest) Reset() {
+	*x = ClearOffersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearOffersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOffersRequest) ProtoMessage() {}
+
+func (x *ClearOffersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOffersRequest.ProtoReflect.Descriptor instead.
+func (*ClearOffersRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ClearOffersRequest) GetOfferIds() []string {
+	if x != nil {
+		return x.OfferIds
+	}
+	return nil
+}
+
+type ClearOffersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ClearOffersResponse) Reset() {
+	*x = ClearOffersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClearOffersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearOffersResponse) ProtoMessage() {}
+
+func (x *ClearOffersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearOffersResponse.ProtoReflect.Descriptor instead.
+func (*ClearOffersResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{7}
+}
+
+type QueryPeerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Multiaddr string `protobuf:"bytes,1,opt,name=multiaddr,proto3" json:"multiaddr,omitempty"`
+}
+
+func (x *QueryPeerRequest) Reset() {
+	*x = QueryPeerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryPeerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryPeerRequest) ProtoMessage() {}
+
+func (x *QueryPeerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryPeerRequest.ProtoReflect.Descriptor instead.
+func (*QueryPeerRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *QueryPeerRequest) GetMultiaddr() string {
+	if x != nil {
+		return x.Multiaddr
+	}
+	return ""
+}
+
+type QueryPeerResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Offers []*Offer `protobuf:"bytes,1,rep,name=offers,proto3" json:"offers,omitempty"`
+}
+
+func (x *QueryPeerResponse) Reset() {
+	*x = QueryPeerResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryPeerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryPeerResponse) ProtoMessage() {}
+
+func (x *QueryPeerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryPeerResponse.ProtoReflect.Descriptor instead.
+func (*QueryPeerResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QueryPeerResponse) GetOffers() []*Offer {
+	if x != nil {
+		return x.Offers
+	}
+	return nil
+}
+
+type TakeOfferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Multiaddr      string  `protobuf:"bytes,1,opt,name=multiaddr,proto3" json:"multiaddr,omitempty"`
+	OfferId        string  `protobuf:"bytes,2,opt,name=offer_id,json=offerId,proto3" json:"offer_id,omitempty"`
+	ProvidesAmount float64 `protobuf:"fixed64,3,opt,name=provides_amount,json=providesAmount,proto3" json:"provides_amount,omitempty"`
+	// the name of the offer's speed tier to use; if empty, its first tier is used
+	SpeedTier string `protobuf:"bytes,4,opt,name=speed_tier,json=speedTier,proto3" json:"speed_tier,omitempty"`
+	// optional limits on the swap's terms, checked before our ether is locked
+	MaxExchangeRate float64 `protobuf:"fixed64,5,opt,name=max_exchange_rate,json=maxExchangeRate,proto3" json:"max_exchange_rate,omitempty"`
+	MinReceivedXmr  float64 `protobuf:"fixed64,6,opt,name=min_received_xmr,json=minReceivedXmr,proto3" json:"min_received_xmr,omitempty"`
+	// the ID of a quote for the offer and amount, whose exchange rate is used instead of the offer's
+	QuoteId string `protobuf:"bytes,7,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+}
+
+func (x *TakeOfferRequest) Reset() {
+	*x = TakeOfferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TakeOfferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TakeOfferRequest) ProtoMessage() {}
+
+func (x *TakeOfferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TakeOfferRequest.ProtoReflect.Descriptor instead.
+func (*TakeOfferRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TakeOfferRequest) GetMultiaddr() string {
+	if x != nil {
+		return x.Multiaddr
+	}
+	return ""
+}
+
+func (x *TakeOfferRequest) GetOfferId() string {
+	if x != nil {
+		return x.OfferId
+	}
+	return ""
+}
+
+func (x *TakeOfferRequest) GetProvidesAmount() float64 {
+	if x != nil {
+		return x.ProvidesAmount
+	}
+	return 0
+}
+
+func (x *TakeOfferRequest) GetSpeedTier() string {
+	if x != nil {
+		return x.SpeedTier
+	}
+	return ""
+}
+
+func (x *TakeOfferRequest) GetMaxExchangeRate() float64 {
+	if x != nil {
+		return x.MaxExchangeRate
+	}
+	return 0
+}
+
+func (x *TakeOfferRequest) GetMinReceivedXmr() float64 {
+	if x != nil {
+		return x.MinReceivedXmr
+	}
+	return 0
+}
+
+func (x *TakeOfferRequest) GetQuoteId() string {
+	if x != nil {
+		return x.QuoteId
+	}
+	return ""
+}
+
+type TakeOfferResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	InfoFile string `protobuf:"bytes,1,opt,name=info_file,json=infoFile,proto3" json:"info_file,omitempty"`
+}
+
+func (x *TakeOfferResponse) Reset() {
+	*x = TakeOfferResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TakeOfferResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TakeOfferResponse) ProtoMessage() {}
+
+func (x *TakeOfferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TakeOfferResponse.ProtoReflect.Descriptor instead.
+func (*TakeOfferResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TakeOfferResponse) GetInfoFile() string {
+	if x != nil {
+		return x.InfoFile
+	}
+	return ""
+}
+
+type SwapRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *SwapRequest) Reset() {
+	*x = SwapRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SwapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwapRequest) ProtoMessage() {}
+
+func (x *SwapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwapRequest.ProtoReflect.Descriptor instead.
+func (*SwapRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SwapRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Swap struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// the coin we provide, eg. "ETH"
+	Provided       string   `protobuf:"bytes,2,opt,name=provided,proto3" json:"provided,omitempty"`
+	ProvidedAmount float64  `protobuf:"fixed64,3,opt,name=provided_amount,json=providedAmount,proto3" json:"provided_amount,omitempty"`
+	ReceivedAmount float64  `protobuf:"fixed64,4,opt,name=received_amount,json=receivedAmount,proto3" json:"received_amount,omitempty"`
+	ExchangeRate   float64  `protobuf:"fixed64,5,opt,name=exchange_rate,json=exchangeRate,proto3" json:"exchange_rate,omitempty"`
+	Status         string   `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	TxHashes       []string `protobuf:"bytes,7,rep,name=tx_hashes,json=txHashes,proto3" json:"tx_hashes,omitempty"`
+	// the libp2p peer ID of the counterparty, if it's known
+	PeerId    string                 `protobuf:"bytes,8,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// unset while the swap is ongoing
+	EndTime *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+}
+
+func (x *Swap) Reset() {
+	*x = Swap{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Swap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Swap) ProtoMessage() {}
+
+func (x *Swap) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Swap.ProtoReflect.Descriptor instead.
+func (*Swap) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Swap) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Swap) GetProvided() string {
+	if x != nil {
+		return x.Provided
+	}
+	return ""
+}
+
+func (x *Swap) GetProvidedAmount() float64 {
+	if x != nil {
+		return x.ProvidedAmount
+	}
+	return 0
+}
+
+func (x *Swap) GetReceivedAmount() float64 {
+	if x != nil {
+		return x.ReceivedAmount
+	}
+	return 0
+}
+
+func (x *Swap) GetExchangeRate() float64 {
+	if x != nil {
+		return x.ExchangeRate
+	}
+	return 0
+}
+
+func (x *Swap) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Swap) GetTxHashes() []string {
+	if x != nil {
+		return x.TxHashes
+	}
+	return nil
+}
+
+func (x *Swap) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *Swap) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Swap) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type CancelSwapResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the status the swap ended with
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+}
+
+func (x *CancelSwapResponse) Reset() {
+	*x = CancelSwapResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CancelSwapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelSwapResponse) ProtoMessage() {}
+
+func (x *CancelSwapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelSwapResponse.ProtoReflect.Descriptor instead.
+func (*CancelSwapResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CancelSwapResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type SwapStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Status  string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Ongoing bool   `protobuf:"varint,2,opt,name=ongoing,proto3" json:"ongoing,omitempty"`
+}
+
+func (x *SwapStatus) Reset() {
+	*x = SwapStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SwapStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwapStatus) ProtoMessage() {}
+
+func (x *SwapStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwapStatus.ProtoReflect.Descriptor instead.
+func (*SwapStatus) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SwapStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *SwapStatus) GetOngoing() bool {
+	if x != nil {
+		return x.Ongoing
+	}
+	return false
+}
+
+type SubscribeEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SubscribeEventsRequest) Reset() {
+	*x = SubscribeEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeEventsRequest) ProtoMessage() {}
+
+func (x *SubscribeEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeEventsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeEventsRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{16}
+}
+
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type   string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	SwapId string `protobuf:"bytes,2,opt,name=swap_id,json=swapId,proto3" json:"swap_id,omitempty"`
+	// the swap's status; it's empty for events about offers that aren't being taken
+	Status string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Time   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=time,proto3" json:"time,omitempty"`
+	Offer  *Offer                 `protobuf:"bytes,5,opt,name=offer,proto3" json:"offer,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_pb_swapd_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_pb_swapd_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_rpc_pb_swapd_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetSwapId() string {
+	if x != nil {
+		return x.SwapId
+	}
+	return ""
+}
+
+func (x *Event) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetOffer() *Offer {
+	if x != nil {
+		return x.Offer
+	}
+	return nil
+}
+
+var File_rpc_pb_swapd_proto protoreflect.FileDescriptor
+
+var file_rpc_pb_swapd_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62, 0x2f, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x73, 0x77, 0x61, 0x70, 0x64, 0x1a, 0x1f, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x6c, 0x0a, 0x09,
+	0x53, 0x70, 0x65, 0x65, 0x64, 0x54, 0x69, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x31, 0x0a,
+	0x14, 0x6d, 0x6f, 0x6e, 0x65, 0x72, 0x6f, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x13, 0x6d, 0x6f, 0x6e,
+	0x65, 0x72, 0x6f, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x18, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0xab, 0x03, 0x0a, 0x05, 0x4f,
+	0x66, 0x66, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x73,
+	0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
+	0x6d, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x69, 0x6d,
+	0x75, 0x6d, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0d, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23,
+	0x0a, 0x0d, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52,
+	0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x0b, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x65,
+	0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64,
+	0x2e, 0x53, 0x70, 0x65, 0x65, 0x64, 0x54, 0x69, 0x65, 0x72, 0x52, 0x0a, 0x73, 0x70, 0x65, 0x65,
+	0x64, 0x54, 0x69, 0x65, 0x72, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x73, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41,
+	0x74, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x74, 0x61, 0x6b,
+	0x65, 0x72, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x12, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x54, 0x61, 0x6b, 0x65, 0x72, 0x41, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x12, 0x35, 0x0a, 0x16, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x15, 0x72, 0x65, 0x71,
+	0x75, 0x69, 0x72, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6d, 0x61, 0x6b, 0x65, 0x72, 0x22, 0xcb, 0x02, 0x0a, 0x10, 0x4d, 0x61, 0x6b,
+	0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a,
+	0x0e, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x41, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x69, 0x6d, 0x75, 0x6d, 0x5f,
+	0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6d, 0x61,
+	0x78, 0x69, 0x6d, 0x75, 0x6d, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x65,
+	0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0c, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65,
+	0x12, 0x31, 0x0a, 0x0b, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x65, 0x72, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x53, 0x70,
+	0x65, 0x65, 0x64, 0x54, 0x69, 0x65, 0x72, 0x52, 0x0a, 0x73, 0x70, 0x65, 0x65, 0x64, 0x54, 0x69,
+	0x65, 0x72, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x5f, 0x74,
+	0x61, 0x6b, 0x65, 0x72, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x12, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x54, 0x61, 0x6b, 0x65, 0x72, 0x41,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x12, 0x35, 0x0a,
+	0x16, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x72,
+	0x6d, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x15, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x22, 0x4b, 0x0a, 0x11, 0x4d, 0x61, 0x6b, 0x65, 0x4f, 0x66,
+	0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x6f,
+	0x66, 0x66, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6f,
+	0x66, 0x66, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x66, 0x6f, 0x5f, 0x66,
+	0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x66, 0x6f, 0x46,
+	0x69, 0x6c, 0x65, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x39, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4f, 0x66,
+	0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x06,
+	0x6f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73,
+	0x77, 0x61, 0x70, 0x64, 0x2e, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x65,
+	0x72, 0x73, 0x22, 0x31, 0x0a, 0x12, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x66, 0x66, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6f, 0x66, 0x66,
+	0x65, 0x72, 0x49, 0x64, 0x73, 0x22, 0x15, 0x0a, 0x13, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x66,
+	0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x30, 0x0a, 0x10,
+	0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x61, 0x64, 0x64, 0x72, 0x22, 0x39,
+	0x0a, 0x11, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x22, 0x84, 0x02, 0x0a, 0x10, 0x54, 0x61,
+	0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x61, 0x64, 0x64, 0x72, 0x12, 0x19, 0x0a, 0x08,
+	0x6f, 0x66, 0x66, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6f, 0x66, 0x66, 0x65, 0x72, 0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x76, 0x69,
+	0x64, 0x65, 0x73, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x73, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x65, 0x72, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x70, 0x65, 0x65, 0x64, 0x54, 0x69, 0x65, 0x72, 0x12,
+	0x2a, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x5f,
+	0x72, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6d, 0x61, 0x78, 0x45,
+	0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x6d,
+	0x69, 0x6e, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x78, 0x6d, 0x72, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x6d, 0x69, 0x6e, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x58, 0x6d, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x49, 0x64,
+	0x22, 0x30, 0x0a, 0x11, 0x54, 0x61, 0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6e, 0x66, 0x6f, 0x5f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x66, 0x6f, 0x46, 0x69,
+	0x6c, 0x65, 0x22, 0x1d, 0x0a, 0x0b, 0x53, 0x77, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0xe9, 0x02, 0x0a, 0x04, 0x53, 0x77, 0x61, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72,
+	0x6f, 0x76, 0x69, 0x64, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72,
+	0x6f, 0x76, 0x69, 0x64, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64,
+	0x65, 0x64, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0e, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x64, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12,
+	0x27, 0x0a, 0x0f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x5f, 0x61, 0x6d, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x64, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x78, 0x63, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0c, 0x65, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x61, 0x74, 0x65, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x78, 0x5f, 0x68, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x74, 0x78, 0x48, 0x61, 0x73, 0x68,
+	0x65, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x65, 0x65, 0x72, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x07, 0x65, 0x6e, 0x64, 0x54, 0x69, 0x6d, 0x65, 0x22, 0x2c, 0x0a,
+	0x12, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x53, 0x77, 0x61, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x3e, 0x0a, 0x0a, 0x53,
+	0x77, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x18, 0x0a, 0x07, 0x6f, 0x6e, 0x67, 0x6f, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x6f, 0x6e, 0x67, 0x6f, 0x69, 0x6e, 0x67, 0x22, 0x18, 0x0a, 0x16, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xa0, 0x01, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x77, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x77, 0x61, 0x70, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04,
+	0x74, 0x69, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x05, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x52, 0x05, 0x6f, 0x66, 0x66, 0x65, 0x72, 0x32, 0xef, 0x04, 0x0a, 0x05, 0x53, 0x77, 0x61,
+	0x70, 0x64, 0x12, 0x3e, 0x0a, 0x09, 0x4d, 0x61, 0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x12,
+	0x17, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x4d, 0x61, 0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64,
+	0x2e, 0x4d, 0x61, 0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x3e, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x12,
+	0x17, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x47, 0x65, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64,
+	0x2e, 0x47, 0x65, 0x74, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x44, 0x0a, 0x0b, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x73, 0x12, 0x19, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f,
+	0x66, 0x66, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x73,
+	0x77, 0x61, 0x70, 0x64, 0x2e, 0x43, 0x6c, 0x65, 0x61, 0x72, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x09, 0x51, 0x75, 0x65, 0x72,
+	0x79, 0x50, 0x65, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x50, 0x65, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
+	0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x50, 0x65, 0x65, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x09, 0x54, 0x61, 0x6b, 0x65,
+	0x4f, 0x66, 0x66, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x54, 0x61,
+	0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
+	0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x54, 0x61, 0x6b, 0x65, 0x4f, 0x66, 0x66, 0x65, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4f,
+	0x6e, 0x67, 0x6f, 0x69, 0x6e, 0x67, 0x53, 0x77, 0x61, 0x70, 0x12, 0x12, 0x2e, 0x73, 0x77, 0x61,
+	0x70, 0x64, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b,
+	0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x12, 0x2e, 0x0a, 0x0b, 0x47,
+	0x65, 0x74, 0x50, 0x61, 0x73, 0x74, 0x53, 0x77, 0x61, 0x70, 0x12, 0x12, 0x2e, 0x73, 0x77, 0x61,
+	0x70, 0x64, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0b,
+	0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x12, 0x3b, 0x0a, 0x0a, 0x43,
+	0x61, 0x6e, 0x63, 0x65, 0x6c, 0x53, 0x77, 0x61, 0x70, 0x12, 0x12, 0x2e, 0x73, 0x77, 0x61, 0x70,
+	0x64, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e,
+	0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x53, 0x77, 0x61, 0x70,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3e, 0x0a, 0x13, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x53, 0x77, 0x61, 0x70, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x12, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x53, 0x77, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x73, 0x77, 0x61, 0x70, 0x64, 0x2e, 0x53, 0x77, 0x61, 0x70,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x30, 0x01, 0x12, 0x40, 0x0a, 0x0f, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x73, 0x77,
+	0x61, 0x70, 0x64, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x73, 0x77, 0x61,
+	0x70, 0x64, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x24, 0x5a, 0x22, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6e, 0x6f, 0x6f, 0x74, 0x2f, 0x61, 0x74,
+	0x6f, 0x6d, 0x69, 0x63, 0x2d, 0x73, 0x77, 0x61, 0x70, 0x2f, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x62,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_pb_swapd_proto_rawDescOnce sync.Once
+	file_rpc_pb_swapd_proto_rawDescData = file_rpc_pb_swapd_proto_rawDesc
+)
+
+func file_rpc_pb_swapd_proto_rawDescGZIP() []byte {
+	file_rpc_pb_swapd_proto_rawDescOnce.Do(func() {
+		file_rpc_pb_swapd_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_pb_swapd_proto_rawDescData)
+	})
+	return file_rpc_pb_swapd_proto_rawDescData
+}
+
+var file_rpc_pb_swapd_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_rpc_pb_swapd_proto_goTypes = []interface{}{
+	(*SpeedTier)(nil),              // 0: swapd.SpeedTier
+	(*Offer)(nil),                  // 1: swapd.Offer
+	(*MakeOfferRequest)(nil),       // 2: swapd.MakeOfferRequest
+	(*MakeOfferResponse)(nil),      // 3: swapd.MakeOfferResponse
+	(*GetOffersRequest)(nil),       // 4: swapd.GetOffersRequest
+	(*GetOffersResponse)(nil),      // 5: swapd.GetOffersResponse
+	(*ClearOffersRequest)(nil),     // 6: swapd.ClearOffersRequest
+	(*ClearOffersResponse)(nil),    // 7: swapd.ClearOffersResponse
+	(*QueryPeerRequest)(nil),       // 8: swapd.QueryPeerRequest
+	(*QueryPeerResponse)(nil),      // 9: swapd.QueryPeerResponse
+	(*TakeOfferRequest)(nil),       // 10: swapd.TakeOfferRequest
+	(*TakeOfferResponse)(nil),      // 11: swapd.TakeOfferResponse
+	(*SwapRequest)(nil),            // 12: swapd.SwapRequest
+	(*Swap)(nil),                   // 13: swapd.Swap
+	(*CancelSwapResponse)(nil),     // 14: swapd.CancelSwapResponse
+	(*SwapStatus)(nil),             // 15: swapd.SwapStatus
+	(*SubscribeEventsRequest)(nil), // 16: swapd.SubscribeEventsRequest
+	(*Event)(nil),                  // 17: swapd.Event
+	(*timestamppb.Timestamp)(nil),  // 18: google.protobuf.Timestamp
+}
+var file_rpc_pb_swapd_proto_depIdxs = []int32{
+	0,  // 0: swapd.Offer.speed_tiers:type_name -> swapd.SpeedTier
+	18, // 1: swapd.Offer.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: swapd.MakeOfferRequest.speed_tiers:type_name -> swapd.SpeedTier
+	1,  // 3: swapd.GetOffersResponse.offers:type_name -> swapd.Offer
+	1,  // 4: swapd.QueryPeerResponse.offers:type_name -> swapd.Offer
+	18, // 5: swapd.Swap.start_time:type_name -> google.protobuf.Timestamp
+	18, // 6: swapd.Swap.end_time:type_name -> google.protobuf.Timestamp
+	18, // 7: swapd.Event.time:type_name -> google.protobuf.Timestamp
+	1,  // 8: swapd.Event.offer:type_name -> swapd.Offer
+	2,  // 9: swapd.Swapd.MakeOffer:input_type -> swapd.MakeOfferRequest
+	4,  // 10: swapd.Swapd.GetOffers:input_type -> swapd.GetOffersRequest
+	6,  // 11: swapd.Swapd.ClearOffers:input_type -> swapd.ClearOffersRequest
+	8,  // 12: swapd.Swapd.QueryPeer:input_type -> swapd.QueryPeerRequest
+	10, // 13: swapd.Swapd.TakeOffer:input_type -> swapd.TakeOfferRequest
+	12, // 14: swapd.Swapd.GetOngoingSwap:input_type -> swapd.SwapRequest
+	12, // 15: swapd.Swapd.GetPastSwap:input_type -> swapd.SwapRequest
+	12, // 16: swapd.Swapd.CancelSwap:input_type -> swapd.SwapRequest
+	12, // 17: swapd.Swapd.SubscribeSwapStatus:input_type -> swapd.SwapRequest
+	16, // 18: swapd.Swapd.SubscribeEvents:input_type -> swapd.SubscribeEventsRequest
+	3,  // 19: swapd.Swapd.MakeOffer:output_type -> swapd.MakeOfferResponse
+	5,  // 20: swapd.Swapd.GetOffers:output_type -> swapd.GetOffersResponse
+	7,  // 21: swapd.Swapd.ClearOffers:output_type -> swapd.ClearOffersResponse
+	9,  // 22: swapd.Swapd.QueryPeer:output_type -> swapd.QueryPeerResponse
+	11, // 23: swapd.Swapd.TakeOffer:output_type -> swapd.TakeOfferResponse
+	13, // 24: swapd.Swapd.GetOngoingSwap:output_type -> swapd.Swap
+	13, // 25: swapd.Swapd.GetPastSwap:output_type -> swapd.Swap
+	14, // 26: swapd.Swapd.CancelSwap:output_type -> swapd.CancelSwapResponse
+	15, // 27: swapd.Swapd.SubscribeSwapStatus:output_type -> swapd.SwapStatus
+	17, // 28: swapd.Swapd.SubscribeEvents:output_type -> swapd.Event
+	19, // [19:29] is the sub-list for method output_type
+	9,  // [9:19] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_rpc_pb_swapd_proto_init() }
+func file_rpc_pb_swapd_proto_init() {
+	if File_rpc_pb_swapd_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_pb_swapd_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SpeedTier); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Offer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MakeOfferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MakeOfferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOffersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetOffersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClearOffersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClearOffersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryPeerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryPeerResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TakeOfferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TakeOfferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SwapRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Swap); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CancelSwapResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SwapStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_pb_swapd_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_pb_swapd_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_rpc_pb_swapd_proto_goTypes,
+		DependencyIndexes: file_rpc_pb_swapd_proto_depIdxs,
+		MessageInfos:      file_rpc_pb_swapd_proto_msgTypes,
+	}.Build()
+	File_rpc_pb_swapd_proto = out.File
+	file_rpc_pb_swapd_proto_rawDesc = nil
+	file_rpc_pb_swapd_proto_goTypes = nil
+	file_rpc_pb_swapd_proto_depIdxs = nil
+}
@@ -0,0 +1,502 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SwapdClient is the client API for Swapd service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SwapdClient interface {
+	// MakeOffer creates and advertises a new offer to swap our XMR for ETH.
+	MakeOffer(ctx context.Context, in *MakeOfferRequest, opts ...grpc.CallOption) (*MakeOfferResponse, error)
+	// GetOffers returns the offers we've made that are still available.
+	GetOffers(ctx context.Context, in *GetOffersRequest, opts ...grpc.CallOption) (*GetOffersResponse, error)
+	// ClearOffers removes the offers with the given IDs, or all offers if none are given.
+	ClearOffers(ctx context.Context, in *ClearOffersRequest, opts ...grpc.CallOption) (*ClearOffersResponse, error)
+	// QueryPeer returns the offers of the peer at the given multiaddress.
+	QueryPeer(ctx context.Context, in *QueryPeerRequest, opts ...grpc.CallOption) (*QueryPeerResponse, error)
+	// TakeOffer starts a swap taking an offer made by the peer at the given multiaddress.
+	TakeOffer(ctx context.Context, in *TakeOfferRequest, opts ...grpc.CallOption) (*TakeOfferResponse, error)
+	// GetOngoingSwap returns the swap with the given ID, if it's ongoing.
+	GetOngoingSwap(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (*Swap, error)
+	// GetPastSwap returns the swap with the given ID, if it's completed.
+	GetPastSwap(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (*Swap, error)
+	// CancelSwap cancels the ongoing swap with the given ID, refunding our funds if they're locked.
+	CancelSwap(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (*CancelSwapResponse, error)
+	// SubscribeSwapStatus sends the swap's status, then every status it moves to. The stream ends
+	// once the swap completes.
+	SubscribeSwapStatus(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (Swapd_SubscribeSwapStatusClient, error)
+	// SubscribeEvents sends every swap and offer event until the stream is cancelled.
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (Swapd_SubscribeEventsClient, error)
+}
+
+type swapdClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSwapdClient(cc grpc.ClientConnInterface) SwapdClient {
+	return &swapdClient{cc}
+}
+
+func (c *swapdClient) MakeOffer(ctx context.Context, in *MakeOfferRequest, opts ...grpc.CallOption) (*MakeOfferResponse, error) {
+	out := new(MakeOfferResponse)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/MakeOffer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) GetOffers(ctx context.Context, in *GetOffersRequest, opts ...grpc.CallOption) (*GetOffersResponse, error) {
+	out := new(GetOffersResponse)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/GetOffers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) ClearOffers(ctx context.Context, in *ClearOffersRequest, opts ...grpc.CallOption) (*ClearOffersResponse, error) {
+	out := new(ClearOffersResponse)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/ClearOffers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) QueryPeer(ctx context.Context, in *QueryPeerRequest, opts ...grpc.CallOption) (*QueryPeerResponse, error) {
+	out := new(QueryPeerResponse)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/QueryPeer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) TakeOffer(ctx context.Context, in *TakeOfferRequest, opts ...grpc.CallOption) (*TakeOfferResponse, error) {
+	out := new(TakeOfferResponse)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/TakeOffer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) GetOngoingSwap(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (*Swap, error) {
+	out := new(Swap)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/GetOngoingSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) GetPastSwap(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (*Swap, error) {
+	out := new(Swap)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/GetPastSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) CancelSwap(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (*CancelSwapResponse, error) {
+	out := new(CancelSwapResponse)
+	err := c.cc.Invoke(ctx, "/swapd.Swapd/CancelSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapdClient) SubscribeSwapStatus(ctx context.Context, in *SwapRequest, opts ...grpc.CallOption) (Swapd_SubscribeSwapStatusClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Swapd_ServiceDesc.Streams[0], "/swapd.Swapd/SubscribeSwapStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &swapdSubscribeSwapStatusClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Swapd_SubscribeSwapStatusClient interface {
+	Recv() (*SwapStatus, error)
+	grpc.ClientStream
+}
+
+type swapdSubscribeSwapStatusClient struct {
+	grpc.ClientStream
+}
+
+func (x *swapdSubscribeSwapStatusClient) Recv() (*SwapStatus, error) {
+	m := new(SwapStatus)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *swapdClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (Swapd_SubscribeEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Swapd_ServiceDesc.Streams[1], "/swapd.Swapd/SubscribeEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &swapdSubscribeEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Swapd_SubscribeEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type swapdSubscribeEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *swapdSubscribeEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SwapdServer is the server API for Swapd service.
+// All implementations must embed UnimplementedSwapdServer
+// for forward compatibility
+type SwapdServer interface {
+	// MakeOffer creates and advertises a new offer to swap our XMR for ETH.
+	MakeOffer(context.Context, *MakeOfferRequest) (*MakeOfferResponse, error)
+	// GetOffers returns the offers we've made that are still available.
+	GetOffers(context.Context, *GetOffersRequest) (*GetOffersResponse, error)
+	// ClearOffers removes the offers with the given IDs, or all offers if none are given.
+	ClearOffers(context.Context, *ClearOffersRequest) (*ClearOffersResponse, error)
+	// QueryPeer returns the offers of the peer at the given multiaddress.
+	QueryPeer(context.Context, *QueryPeerRequest) (*QueryPeerResponse, error)
+	// TakeOffer starts a swap taking an offer made by the peer at the given multiaddress.
+	TakeOffer(context.Context, *TakeOfferRequest) (*TakeOfferResponse, error)
+	// GetOngoingSwap returns the swap with the given ID, if it's ongoing.
+	GetOngoingSwap(context.Context, *SwapRequest) (*Swap, error)
+	// GetPastSwap returns the swap with the given ID, if it's completed.
+	GetPastSwap(context.Context, *SwapRequest) (*Swap, error)
+	// CancelSwap cancels the ongoing swap with the given ID, refunding our funds if they're locked.
+	CancelSwap(context.Context, *SwapRequest) (*CancelSwapResponse, error)
+	// SubscribeSwapStatus sends the swap's status, then every status it moves to. The stream ends
+	// once the swap completes.
+	SubscribeSwapStatus(*SwapRequest, Swapd_SubscribeSwapStatusServer) error
+	// SubscribeEvents sends every swap and offer event until the stream is cancelled.
+	SubscribeEvents(*SubscribeEventsRequest, Swapd_SubscribeEventsServer) error
+	mustEmbedUnimplementedSwapdServer()
+}
+
+// UnimplementedSwapdServer must be embedded to have forward compatible implementations.
+type UnimplementedSwapdServer struct {
+}
+
+func (UnimplementedSwapdServer) MakeOffer(context.Context, *MakeOfferRequest) (*MakeOfferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MakeOffer not implemented")
+}
+func (UnimplementedSwapdServer) GetOffers(context.Context, *GetOffersRequest) (*GetOffersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOffers not implemented")
+}
+func (UnimplementedSwapdServer) ClearOffers(context.Context, *ClearOffersRequest) (*ClearOffersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearOffers not implemented")
+}
+func (UnimplementedSwapdServer) QueryPeer(context.Context, *QueryPeerRequest) (*QueryPeerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryPeer not implemented")
+}
+func (UnimplementedSwapdServer) TakeOffer(context.Context, *TakeOfferRequest) (*TakeOfferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TakeOffer not implemented")
+}
+func (UnimplementedSwapdServer) GetOngoingSwap(context.Context, *SwapRequest) (*Swap, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOngoingSwap not implemented")
+}
+func (UnimplementedSwapdServer) GetPastSwap(context.Context, *SwapRequest) (*Swap, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPastSwap not implemented")
+}
+func (UnimplementedSwapdServer) CancelSwap(context.Context, *SwapRequest) (*CancelSwapResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelSwap not implemented")
+}
+func (UnimplementedSwapdServer) SubscribeSwapStatus(*SwapRequest, Swapd_SubscribeSwapStatusServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeSwapStatus not implemented")
+}
+func (UnimplementedSwapdServer) SubscribeEvents(*SubscribeEventsRequest, Swapd_SubscribeEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedSwapdServer) mustEmbedUnimplementedSwapdServer() {}
+
+// UnsafeSwapdServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwapdServer will
+// result in compilation errors.
+type UnsafeSwapdServer interface {
+	mustEmbedUnimplementedSwapdServer()
+}
+
+func RegisterSwapdServer(s grpc.ServiceRegistrar, srv SwapdServer) {
+	s.RegisterService(&Swapd_ServiceDesc, srv)
+}
+
+func _Swapd_MakeOffer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MakeOfferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).MakeOffer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/MakeOffer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).MakeOffer(ctx, req.(*MakeOfferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_GetOffers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOffersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).GetOffers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/GetOffers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).GetOffers(ctx, req.(*GetOffersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_ClearOffers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearOffersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).ClearOffers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/ClearOffers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).ClearOffers(ctx, req.(*ClearOffersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_QueryPeer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryPeerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).QueryPeer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/QueryPeer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).QueryPeer(ctx, req.(*QueryPeerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_TakeOffer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TakeOfferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).TakeOffer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/TakeOffer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).TakeOffer(ctx, req.(*TakeOfferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_GetOngoingSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).GetOngoingSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/GetOngoingSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).GetOngoingSwap(ctx, req.(*SwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_GetPastSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).GetPastSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/GetPastSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).GetPastSwap(ctx, req.(*SwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_CancelSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapdServer).CancelSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swapd.Swapd/CancelSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapdServer).CancelSwap(ctx, req.(*SwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swapd_SubscribeSwapStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SwapRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwapdServer).SubscribeSwapStatus(m, &swapdSubscribeSwapStatusServer{stream})
+}
+
+type Swapd_SubscribeSwapStatusServer interface {
+	Send(*SwapStatus) error
+	grpc.ServerStream
+}
+
+type swapdSubscribeSwapStatusServer struct {
+	grpc.ServerStream
+}
+
+func (x *swapdSubscribeSwapStatusServer) Send(m *SwapStatus) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Swapd_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwapdServer).SubscribeEvents(m, &swapdSubscribeEventsServer{stream})
+}
+
+type Swapd_SubscribeEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type swapdSubscribeEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *swapdSubscribeEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Swapd_ServiceDesc is the grpc.ServiceDesc for Swapd service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Swapd_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swapd.Swapd",
+	HandlerType: (*SwapdServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MakeOffer",
+			Handler:    _Swapd_MakeOffer_Handler,
+		},
+		{
+			MethodName: "GetOffers",
+			Handler:    _Swapd_GetOffers_Handler,
+		},
+		{
+			MethodName: "ClearOffers",
+			Handler:    _Swapd_ClearOffers_Handler,
+		},
+		{
+			MethodName: "QueryPeer",
+			Handler:    _Swapd_QueryPeer_Handler,
+		},
+		{
+			MethodName: "TakeOffer",
+			Handler:    _Swapd_TakeOffer_Handler,
+		},
+		{
+			MethodName: "GetOngoingSwap",
+			Handler:    _Swapd_GetOngoingSwap_Handler,
+		},
+		{
+			MethodName: "GetPastSwap",
+			Handler:    _Swapd_GetPastSwap_Handler,
+		},
+		{
+			MethodName: "CancelSwap",
+			Handler:    _Swapd_CancelSwap_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeSwapStatus",
+			Handler:       _Swapd_SubscribeSwapStatus_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _Swapd_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "rpc/pb/swapd.proto",
+}
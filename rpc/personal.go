@@ -3,19 +3,30 @@ package rpc
 import (
 	"net/http"
 	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/protocol/preflight"
+	"github.com/noot/atomic-swap/protocol/txsender"
+	"github.com/noot/atomic-swap/protocol/utilization"
 )
 
 // PersonalService handles private keys and wallets.
 type PersonalService struct {
-	xmrmaker XMRMaker
-	pb       ProtocolBackend
+	xmrmaker    XMRMaker
+	pb          ProtocolBackend
+	utilization UtilizationTracker
+	preflight   PreflightChecker
 }
 
 // NewPersonalService ...
-func NewPersonalService(xmrmaker XMRMaker, pb ProtocolBackend) *PersonalService {
+func NewPersonalService(xmrmaker XMRMaker, pb ProtocolBackend, utilization UtilizationTracker,
+	preflight PreflightChecker) *PersonalService {
 	return &PersonalService{
-		xmrmaker: xmrmaker,
-		pb:       pb,
+		xmrmaker:    xmrmaker,
+		pb:          pb,
+		utilization: utilization,
+		preflight:   preflight,
 	}
 }
 
@@ -51,6 +62,85 @@ type SetGasPriceRequest struct {
 // SetGasPrice sets the gas price (in wei) to be used for ethereum transactions.
 func (s *PersonalService) SetGasPrice(_ *http.Request, req *SetGasPriceRequest, _ *interface{}) error {
 	s.pb.SetGasPrice(req.GasPrice)
-	s.pb.SetGasPrice(req.GasPrice)
+	return nil
+}
+
+// SetGasConfigRequest ...
+type SetGasConfigRequest struct {
+	txsender.GasConfig
+}
+
+// SetGasConfig replaces the gas settings used by new ethereum transactions, without restarting the
+// daemon. Settings that aren't given are suggested by the ethereum node.
+func (s *PersonalService) SetGasConfig(_ *http.Request, req *SetGasConfigRequest, _ *interface{}) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	s.pb.SetGasConfig(&req.GasConfig)
+	return nil
+}
+
+// SetMoneroFeePriorityRequest ...
+type SetMoneroFeePriorityRequest struct {
+	// Priority is the name of the priority, eg. "elevated", or its number, from 0 to 4
+	Priority string `json:"priority"`
+}
+
+// SetMoneroFeePriority sets the fee priority of new monero transfers, without restarting the daemon.
+func (s *PersonalService) SetMoneroFeePriority(_ *http.Request, req *SetMoneroFeePriorityRequest,
+	_ *interface{}) error {
+	priority, err := monero.NewTransferPriority(req.Priority)
+	if err != nil {
+		return err
+	}
+
+	return s.pb.SetTransferPriority(priority)
+}
+
+// GetCapitalUtilizationResponse ...
+type GetCapitalUtilizationResponse struct {
+	Current *utilization.Snapshot   `json:"current"`
+	History []*utilization.Snapshot `json:"history"`
+}
+
+// GetCapitalUtilization returns how much ETH and XMR is currently locked in ongoing swaps, idle in
+// our wallets and reserved by our offers, along with the utilization sampled over time.
+func (s *PersonalService) GetCapitalUtilization(r *http.Request, _ *interface{},
+	resp *GetCapitalUtilizationResponse) error {
+	if s.utilization == nil {
+		return errNoUtilizationTracker
+	}
+
+	current, err := s.utilization.Snapshot(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp.Current = current
+	resp.History = s.utilization.History()
+	return nil
+}
+
+// PreflightRequest ...
+type PreflightRequest struct {
+	Provides types.ProvidesCoin `json:"provides"`
+	Amount   float64            `json:"amount"`
+}
+
+// Preflight checks that a swap providing the given amount of the given coin could be completed
+// right now: that our nodes are reachable and synced, and that our balances cover the amount
+// and the gas for our contract calls. The same checks are run before every swap begins.
+func (s *PersonalService) Preflight(r *http.Request, req *PreflightRequest, resp *preflight.Report) error {
+	if s.preflight == nil {
+		return errNoPreflightChecker
+	}
+
+	report, err := s.preflight.Run(r.Context(), req.Provides, req.Amount)
+	if err != nil {
+		return err
+	}
+
+	*resp = *report
 	return nil
 }
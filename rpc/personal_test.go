@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/protocol/txsender"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersonalService_SetGasConfig(t *testing.T) {
+	pb := newMockProtocolBackend()
+	s := NewPersonalService(nil, pb, nil, nil)
+
+	req := &SetGasConfigRequest{
+		GasConfig: txsender.GasConfig{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(2)},
+	}
+	require.NoError(t, s.SetGasConfig(nil, req, nil))
+	require.Equal(t, big.NewInt(100), pb.gas.GasFeeCap)
+
+	// invalid configs aren't set
+	req = &SetGasConfigRequest{
+		GasConfig: txsender.GasConfig{GasPrice: big.NewInt(50), GasFeeCap: big.NewInt(100)},
+	}
+	require.Error(t, s.SetGasConfig(nil, req, nil))
+	require.Nil(t, pb.gas.GasPrice)
+}
+
+func TestPersonalService_SetMoneroFeePriority(t *testing.T) {
+	pb := newMockProtocolBackend()
+	s := NewPersonalService(nil, pb, nil, nil)
+
+	require.NoError(t, s.SetMoneroFeePriority(nil, &SetMoneroFeePriorityRequest{Priority: "priority"}, nil))
+	require.Equal(t, monero.PriorityPriority, pb.priority)
+
+	require.Error(t, s.SetMoneroFeePriority(nil, &SetMoneroFeePriorityRequest{Priority: "urgent"}, nil))
+	require.Equal(t, monero.PriorityPriority, pb.priority)
+}
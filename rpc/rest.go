@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+
+	"github.com/gorilla/mux"
+)
+
+// restService serves the most common operations as plain REST endpoints on the RPC server, for
+// frontends and scripts that don't want to deal with JSON-RPC framing. Each endpoint calls the
+// JSON-RPC method it's mapped onto, so they behave the same.
+type restService struct {
+	ns *NetService
+	ss *SwapService
+}
+
+func newRESTService(ns *NetService, ss *SwapService) *restService {
+	return &restService{
+		ns: ns,
+		ss: ss,
+	}
+}
+
+// restError is the body of REST responses to requests that failed.
+type restError struct {
+	Error string `json:"error"`
+}
+
+// register adds the REST endpoints to the router.
+func (s *restService) register(r *mux.Router) {
+	r.HandleFunc("/offers", s.getOffers).Methods(http.MethodGet)
+	r.HandleFunc("/offers", s.makeOffer).Methods(http.MethodPost)
+	r.HandleFunc("/offers", s.clearOffers).Methods(http.MethodDelete)
+	r.HandleFunc("/market", s.getMarketOffers).Methods(http.MethodGet)
+	r.HandleFunc("/queryPeer", s.queryPeer).Methods(http.MethodPost)
+	r.HandleFunc("/takeOffer", s.takeOffer).Methods(http.MethodPost)
+	r.HandleFunc("/swaps", s.getSwaps).Methods(http.MethodGet)
+	r.HandleFunc("/swaps/{id}", s.getSwap).Methods(http.MethodGet)
+	r.HandleFunc("/swaps/{id}/cancel", s.cancel).Methods(http.MethodPost)
+	r.HandleFunc("/swaps/{id}/refund", s.refund).Methods(http.MethodPost)
+	r.HandleFunc("/swaps/{id}/claim", s.claim).Methods(http.MethodPost)
+}
+
+// GET /offers returns the offers we've made, as swap_getOffers does.
+func (s *restService) getOffers(w http.ResponseWriter, r *http.Request) {
+	resp := new(GetOffersResponse)
+	writeREST(w, resp, s.ss.GetOffers(r, nil, resp))
+}
+
+// POST /offers makes an offer, as net_makeOffer does.
+func (s *restService) makeOffer(w http.ResponseWriter, r *http.Request) {
+	req := new(rpctypes.MakeOfferRequest)
+	if !readREST(w, r, req) {
+		return
+	}
+
+	resp := new(rpctypes.MakeOfferResponse)
+	writeREST(w, resp, s.ns.MakeOffer(r, req, resp))
+}
+
+// DELETE /offers clears the offers whose IDs are given in id query parameters, or all offers if
+// none are given, as net_clearOffers does.
+func (s *restService) clearOffers(w http.ResponseWriter, r *http.Request) {
+	req := &rpctypes.ClearOffersRequest{OfferIDs: r.URL.Query()["id"]}
+	writeREST(w, nil, s.ns.ClearOffers(r, req, nil))
+}
+
+// GET /market returns the offers makers have published, as net_getMarketOffers does.
+func (s *restService) getMarketOffers(w http.ResponseWriter, r *http.Request) {
+	resp := new(rpctypes.GetMarketOffersResponse)
+	writeREST(w, resp, s.ns.GetMarketOffers(r, nil, resp))
+}
+
+// POST /queryPeer returns a peer's offers, as net_queryPeer does.
+func (s *restService) queryPeer(w http.ResponseWriter, r *http.Request) {
+	req := new(rpctypes.QueryPeerRequest)
+	if !readREST(w, r, req) {
+		return
+	}
+
+	resp := new(rpctypes.QueryPeerResponse)
+	writeREST(w, resp, s.ns.QueryPeer(r, req, resp))
+}
+
+// POST /takeOffer takes a peer's offer, as net_takeOffer does.
+func (s *restService) takeOffer(w http.ResponseWriter, r *http.Request) {
+	req := new(rpctypes.TakeOfferRequest)
+	if !readREST(w, r, req) {
+		return
+	}
+
+	resp := new(rpctypes.TakeOfferResponse)
+	writeREST(w, resp, s.ns.TakeOffer(r, req, resp))
+}
+
+// GET /swaps returns the statuses of the ongoing swaps, as swap_getStatuses does.
+func (s *restService) getSwaps(w http.ResponseWriter, r *http.Request) {
+	resp := new(GetStatusesResponse)
+	writeREST(w, resp, s.ss.GetStatuses(r, new(GetStatusesRequest), resp))
+}
+
+// GET /swaps/{id} returns the swap, as swap_getOngoing does if it's ongoing and swap_getPast does
+// otherwise.
+func (s *restService) getSwap(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	ongoing := new(GetOngoingResponse)
+	err := s.ss.GetOngoing(r, &GetOngoingRequest{OfferID: id}, ongoing)
+	if !errors.Is(err, errNoOngoingSwap) {
+		writeREST(w, ongoing, err)
+		return
+	}
+
+	past := new(GetPastResponse)
+	writeREST(w, past, s.ss.GetPast(r, &GetPastRequest{OfferID: id}, past))
+}
+
+// POST /swaps/{id}/cancel cancels the swap, as swap_cancel does.
+func (s *restService) cancel(w http.ResponseWriter, r *http.Request) {
+	resp := new(CancelResponse)
+	writeREST(w, resp, s.ss.Cancel(r, &CancelRequest{OfferID: mux.Vars(r)["id"]}, resp))
+}
+
+// POST /swaps/{id}/refund refunds the swap, as swap_refund does.
+func (s *restService) refund(w http.ResponseWriter, r *http.Request) {
+	resp := new(RefundResponse)
+	writeREST(w, resp, s.ss.Refund(r, &RefundRequest{OfferID: mux.Vars(r)["id"]}, resp))
+}
+
+// POST /swaps/{id}/claim claims the swap, as swap_claim does.
+func (s *restService) claim(w http.ResponseWriter, r *http.Request) {
+	resp := new(ClaimResponse)
+	writeREST(w, resp, s.ss.Claim(r, &ClaimRequest{OfferID: mux.Vars(r)["id"]}, resp))
+}
+
+// readREST decodes the request's JSON body into req. If it can't, it writes the error and returns
+// false.
+func readREST(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeRESTError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+
+	return true
+}
+
+// writeREST writes the response, or the error if it isn't nil. Responses without a body are
+// written as 204 No Content.
+func writeREST(w http.ResponseWriter, resp interface{}, err error) {
+	switch {
+	case errors.Is(err, errNoSwapWithID), errors.Is(err, errNoOngoingSwap), errors.Is(err, errNoOfferWithID):
+		writeRESTError(w, http.StatusNotFound, err)
+	case errors.Is(err, errInvalidSwapID):
+		writeRESTError(w, http.StatusBadRequest, err)
+	case err != nil:
+		writeRESTError(w, http.StatusInternalServerError, err)
+	case resp == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeRESTJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeRESTError(w http.ResponseWriter, code int, err error) {
+	writeRESTJSON(w, code, &restError{Error: err.Error()})
+}
+
+func writeRESTJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Debugf("failed to write REST response: %s", err)
+	}
+}
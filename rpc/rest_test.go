@@ -0,0 +1,109 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRESTServer(t *testing.T, sm SwapManager) *httptest.Server {
+	ns := NewNetService(new(mockNet), new(mockXMRTaker), nil, sm)
+	ss := NewSwapService(sm, new(mockXMRTaker), nil, new(mockNet), nil, nil, nil)
+
+	r := mux.NewRouter()
+	newRESTService(ns, ss).register(r)
+	s := httptest.NewServer(r)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// doREST makes the request, decodes the response's body into resp if it's not nil, and returns
+// the response's status code.
+func doREST(t *testing.T, method, url string, body interface{}, resp interface{}) int {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(reqBody))
+	require.NoError(t, err)
+	httpResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer httpResp.Body.Close() //nolint:errcheck
+
+	if resp != nil {
+		require.NoError(t, json.NewDecoder(httpResp.Body).Decode(resp))
+	}
+	return httpResp.StatusCode
+}
+
+func TestREST_Swaps(t *testing.T) {
+	sm := swap.NewManager()
+	past := swap.NewInfo(types.Hash{1}, types.ProvidesETH, 1, 10, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(past))
+	past.SetStatus(types.CompletedSuccess)
+	sm.CompleteOngoingSwap(past.ID())
+
+	ongoing := swap.NewInfo(types.Hash{2}, types.ProvidesETH, 1, 10, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(ongoing))
+
+	s := newTestRESTServer(t, sm)
+
+	statuses := new(GetStatusesResponse)
+	require.Equal(t, http.StatusOK, doREST(t, http.MethodGet, s.URL+"/swaps", nil, statuses))
+	require.Len(t, statuses.Statuses, 1)
+	require.Equal(t, ongoing.ID(), statuses.Statuses[0].ID)
+
+	ongoingResp := new(GetOngoingResponse)
+	code := doREST(t, http.MethodGet, s.URL+"/swaps/"+ongoing.ID().String(), nil, ongoingResp)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, types.ExpectingKeys.String(), ongoingResp.Status)
+
+	pastResp := new(GetPastResponse)
+	code = doREST(t, http.MethodGet, s.URL+"/swaps/"+past.ID().String(), nil, pastResp)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, types.CompletedSuccess.String(), pastResp.Status)
+	require.NotNil(t, pastResp.EndTime)
+
+	errResp := new(restError)
+	code = doREST(t, http.MethodGet, s.URL+"/swaps/"+types.Hash{3}.String(), nil, errResp)
+	require.Equal(t, http.StatusNotFound, code)
+	require.Equal(t, errNoSwapWithID.Error(), errResp.Error)
+	code = doREST(t, http.MethodGet, s.URL+"/swaps/notanid", nil, errResp)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	// only ongoing swaps can be cancelled
+	code = doREST(t, http.MethodPost, s.URL+"/swaps/"+past.ID().String()+"/cancel", nil, errResp)
+	require.Equal(t, http.StatusNotFound, code)
+}
+
+func TestREST_QueryPeer(t *testing.T) {
+	s := newTestRESTServer(t, swap.NewManager())
+
+	resp := new(rpctypes.QueryPeerResponse)
+	code := doREST(t, http.MethodPost, s.URL+"/queryPeer", &rpctypes.QueryPeerRequest{Multiaddr: testMultiaddr}, resp)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Offers, 1)
+
+	// the body must be JSON
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/queryPeer", bytes.NewReader([]byte("multiaddr")))
+	require.NoError(t, err)
+	httpResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = httpResp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, httpResp.StatusCode)
+
+	// endpoints only accept their own methods
+	require.Equal(t, http.StatusMethodNotAllowed, doREST(t, http.MethodGet, s.URL+"/queryPeer", nil, nil))
+}
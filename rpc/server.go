@@ -2,32 +2,53 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"math/big"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/db"
+	"github.com/noot/atomic-swap/monero"
+	"github.com/noot/atomic-swap/protocol/preflight"
 	"github.com/noot/atomic-swap/protocol/swap"
 	"github.com/noot/atomic-swap/protocol/txsender"
+	"github.com/noot/atomic-swap/protocol/utilization"
+	recovery "github.com/noot/atomic-swap/recover"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/rpc/v2"
+	"google.golang.org/grpc"
 
 	logging "github.com/ipfs/go-log"
 )
 
 var log = logging.Logger("rpc")
 
+// how long a client may take to send a request's headers
+const readHeaderTimeout = time.Minute
+
 // Server represents the JSON-RPC server
 type Server struct {
 	s        *rpc.Server
+	ns       *NetService
+	rest     *restService
 	wsServer *wsServer
 	port     uint16
 	wsPort   uint16
+	// grpcServer is nil if the gRPC API isn't served
+	grpcServer *grpc.Server
+	grpcPort   uint16
+	// tlsConfig is nil if the servers aren't served over TLS
+	tlsConfig *tls.Config
+	origins   *originChecker
 }
 
 // Config ...
@@ -39,6 +60,36 @@ type Config struct {
 	XMRTaker        XMRTaker
 	XMRMaker        XMRMaker
 	ProtocolBackend ProtocolBackend
+	// Utilization is optional; if it's nil, personal_getCapitalUtilization returns an error
+	Utilization UtilizationTracker
+	// Preflight is optional; if it's nil, personal_preflight returns an error
+	Preflight PreflightChecker
+	// Database is optional; if it's nil, swap_exportRecoveryBundle and the admin_ methods return
+	// an error
+	Database db.Database
+	// PendingRecovery is optional; if it's nil, there are never any swaps pending recovery
+	PendingRecovery PendingRecovery
+	// AdminToken must be given as a bearer token in the Authorization header to call the methods
+	// that reveal swap secrets or touch the database's files; if it's empty, they can't be called
+	AdminToken string
+	// BackupDir is the directory admin_backupDB writes backups to and admin_restoreDB restores them
+	// from; if it's empty, they return an error
+	BackupDir string
+	// TLSCertFile and TLSKeyFile are optional; if they're set, the RPC and websockets servers are
+	// served over HTTPS and WSS with the PEM-encoded certificate and key in them, which are loaded
+	// again whenever they change
+	TLSCertFile string
+	TLSKeyFile  string
+	// AllowedOrigins are the websites browsers may make requests to the servers from; AnyOrigin
+	// allows any. If it's nil, DefaultAllowedOrigins are allowed. Requests from outside a browser
+	// are always allowed.
+	AllowedOrigins []string
+	// GRPCPort is optional; if it's set, the gRPC API is served on it too, over TLS if the other
+	// servers are
+	GRPCPort uint16
+	// WsLimits limit what websockets clients may do; if it's nil, or for limits that aren't set,
+	// DefaultWsLimits are used
+	WsLimits *WsLimits
 }
 
 // NewServer ...
@@ -51,20 +102,72 @@ func NewServer(cfg *Config) (*Server, error) {
 		return nil, err
 	}
 
-	if err := s.RegisterService(NewPersonalService(cfg.XMRMaker, cfg.ProtocolBackend), "personal"); err != nil {
+	ps := NewPersonalService(cfg.XMRMaker, cfg.ProtocolBackend, cfg.Utilization, cfg.Preflight)
+	if err := s.RegisterService(ps, "personal"); err != nil {
+		return nil, err
+	}
+
+	if err := s.RegisterService(NewDaemonService(cfg.ProtocolBackend), "daemon"); err != nil {
+		return nil, err
+	}
+
+	ss := NewSwapService(cfg.ProtocolBackend.SwapManager(), cfg.XMRTaker, cfg.XMRMaker, cfg.Net, cfg.ProtocolBackend,
+		cfg.Database, cfg.PendingRecovery)
+	ss.adminToken = cfg.AdminToken
+	if err := s.RegisterService(ss, "swap"); err != nil {
 		return nil, err
 	}
 
-	if err := s.RegisterService(NewSwapService(cfg.ProtocolBackend.SwapManager(), cfg.XMRTaker, cfg.XMRMaker, cfg.Net), "swap"); err != nil { //nolint:lll
+	as := NewAdminService(cfg.ProtocolBackend.SwapManager(), cfg.Database, cfg.Net)
+	as.adminToken = cfg.AdminToken
+	as.backupDir = cfg.BackupDir
+	if err := s.RegisterService(as, "admin"); err != nil {
 		return nil, err
 	}
 
-	return &Server{
+	server := &Server{
 		s:        s,
+		ns:       ns,
+		rest:     newRESTService(ns, ss),
 		wsServer: newWsServer(cfg.Ctx, cfg.ProtocolBackend.SwapManager(), ns, cfg.ProtocolBackend, cfg.ProtocolBackend.ExternalSender()), //nolint:lll
 		port:     cfg.Port,
 		wsPort:   cfg.WsPort,
-	}, nil
+	}
+
+	allowedOrigins := cfg.AllowedOrigins
+	if allowedOrigins == nil {
+		allowedOrigins = DefaultAllowedOrigins
+	}
+	server.origins = newOriginChecker(allowedOrigins)
+	server.wsServer.upgrader.CheckOrigin = server.origins.isAllowed
+	if cfg.WsLimits != nil {
+		server.wsServer.limits = cfg.WsLimits.withDefaults()
+	}
+
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load RPC TLS certificate: %w", err)
+		}
+		server.tlsConfig = reloader.tlsConfig()
+	}
+
+	if cfg.GRPCPort != 0 {
+		server.grpcServer = newGRPCServer(cfg.Ctx, ns, ss, cfg.ProtocolBackend.SwapManager(), server.tlsConfig)
+		server.grpcPort = cfg.GRPCPort
+		go func() {
+			<-cfg.Ctx.Done()
+			server.grpcServer.Stop()
+		}()
+	}
+
+	return server, nil
+}
+
+// NetService returns the server's net_ service, so that the daemon can take offers itself, eg. to
+// rebalance.
+func (s *Server) NetService() *NetService {
+	return s.ns
 }
 
 // Start starts the JSON-RPC server.
@@ -74,14 +177,16 @@ func (s *Server) Start() <-chan error {
 	go func() {
 		r := mux.NewRouter()
 		r.Handle("/", s.s)
+		s.rest.register(r)
 
 		headersOk := handlers.AllowedHeaders([]string{"content-type", "username", "password"})
-		methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
-		originsOk := handlers.AllowedOrigins([]string{"*"})
+		methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"})
+		originsOk := handlers.AllowedOriginValidator(s.origins.isAllowedOrigin)
 
-		log.Infof("starting RPC server on http://localhost:%d", s.port)
+		log.Infof("starting RPC server on %s://localhost:%d", s.scheme("http"), s.port)
 
-		if err := http.ListenAndServe(fmt.Sprintf(":%d", s.port), handlers.CORS(headersOk, methodsOk, originsOk)(r)); err != nil { //nolint:lll
+		handler := handlers.CORS(headersOk, methodsOk, originsOk)(s.origins.filter(r))
+		if err := s.listenAndServe(s.port, handler); err != nil {
 			log.Errorf("failed to start http RPC server: %s", err)
 			errCh <- err
 		}
@@ -93,19 +198,62 @@ func (s *Server) Start() <-chan error {
 
 		headersOk := handlers.AllowedHeaders([]string{"content-type", "username", "password"})
 		methodsOk := handlers.AllowedMethods([]string{"GET", "HEAD", "POST", "PUT", "OPTIONS"})
-		originsOk := handlers.AllowedOrigins([]string{"*"})
+		originsOk := handlers.AllowedOriginValidator(s.origins.isAllowedOrigin)
 
-		log.Infof("starting websockets server on ws://localhost:%d", s.wsPort)
+		log.Infof("starting websockets server on %s://localhost:%d", s.scheme("ws"), s.wsPort)
 
-		if err := http.ListenAndServe(fmt.Sprintf(":%d", s.wsPort), handlers.CORS(headersOk, methodsOk, originsOk)(r)); err != nil { //nolint:lll
+		// the websockets upgrader checks the origin itself
+		if err := s.listenAndServe(s.wsPort, handlers.CORS(headersOk, methodsOk, originsOk)(r)); err != nil {
 			log.Errorf("failed to start websockets RPC server: %s", err)
 			errCh <- err
 		}
 	}()
 
+	if s.grpcServer != nil {
+		go func() {
+			log.Infof("starting gRPC server on localhost:%d (TLS: %t)", s.grpcPort, s.tlsConfig != nil)
+
+			ln, err := net.Listen("tcp", fmt.Sprintf(":%d", s.grpcPort))
+			if err == nil {
+				err = s.grpcServer.Serve(ln)
+			}
+			// the server is stopped when the context is cancelled
+			if err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				log.Errorf("failed to start gRPC server: %s", err)
+				errCh <- err
+			}
+		}()
+	}
+
 	return errCh
 }
 
+// listenAndServe serves the handler on the given port, over TLS if it's configured.
+func (s *Server) listenAndServe(port uint16, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		TLSConfig:         s.tlsConfig,
+	}
+
+	if s.tlsConfig != nil {
+		// the certificate comes from the TLS config
+		return srv.ListenAndServeTLS("", "")
+	}
+
+	return srv.ListenAndServe()
+}
+
+// scheme returns the URL scheme that the servers are served with: the given one, or its secure
+// version if they're served over TLS.
+func (s *Server) scheme(plain string) string {
+	if s.tlsConfig != nil {
+		return plain + "s"
+	}
+	return plain
+}
+
 // Protocol represents the functions required by the rpc service into the protocol handler.
 type Protocol interface {
 	Provides() types.ProvidesCoin
@@ -114,7 +262,11 @@ type Protocol interface {
 
 // ProtocolBackend represents protocol/backend.Backend
 type ProtocolBackend interface {
+	Env() common.Environment
+	ChainID() *big.Int
 	SetGasPrice(uint64)
+	SetGasConfig(*txsender.GasConfig)
+	SetTransferPriority(monero.TransferPriority) error
 	SetSwapTimeout(timeout time.Duration)
 	SwapManager() swap.Manager
 	ExternalSender() *txsender.ExternalSender
@@ -125,17 +277,39 @@ type ProtocolBackend interface {
 // XMRTaker ...
 type XMRTaker interface {
 	Protocol
-	InitiateProtocol(providesAmount float64, offer *types.Offer) (common.SwapState, error)
+	InitiateProtocol(providesAmount float64, offer *types.Offer, speedTier string,
+		limits *types.SlippageLimits) (common.SwapState, error)
 	Refund(types.Hash) (ethcommon.Hash, error)
+	ConfirmReady(types.Hash) error
 }
 
 // XMRMaker ...
 type XMRMaker interface {
 	Protocol
-	MakeOffer(offer *types.Offer) (*types.OfferExtra, error)
+	MakeOffer(offer *types.Offer, relist *types.RelistPolicy, peg *types.RatePeg,
+		takers *types.TakerFilter) (*types.OfferExtra, error)
+	MakePrivateOffer(offer *types.Offer, peg *types.RatePeg) (*types.OfferExtra, string, error)
 	SetMoneroWalletFile(file, password string) error
 	GetOffers() []*types.Offer
-	ClearOffers()
+	ClearOffers(ids []types.Hash) error
+	Claim(types.Hash) (ethcommon.Hash, error)
+}
+
+// UtilizationTracker reports the daemon's capital utilization.
+type UtilizationTracker interface {
+	Snapshot(ctx context.Context) (*utilization.Snapshot, error)
+	History() []*utilization.Snapshot
+}
+
+// PreflightChecker runs the checks made before a swap begins.
+type PreflightChecker interface {
+	Run(ctx context.Context, provides types.ProvidesCoin, amount float64) (*preflight.Report, error)
+}
+
+// PendingRecovery tracks the swaps abandoned when the daemon stopped that haven't been recovered yet.
+type PendingRecovery interface {
+	Pending() []*recovery.PendingSwap
+	Resolve(id types.Hash) error
 }
 
 // SwapManager ...
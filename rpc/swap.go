@@ -1,12 +1,25 @@
 package rpc
 
 import (
-	"encoding/hex"
+	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/db"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/protocol/export"
+	"github.com/noot/atomic-swap/protocol/stats"
+	"github.com/noot/atomic-swap/protocol/swap"
+	recovery "github.com/noot/atomic-swap/recover"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
 )
 
 // SwapService handles information about ongoing or past swaps.
@@ -15,15 +28,24 @@ type SwapService struct {
 	xmrtaker XMRTaker
 	xmrmaker XMRMaker
 	net      Net
+	pb       ProtocolBackend
+	db       db.Database
+	pending  PendingRecovery
+	// adminToken must be given to call the methods that reveal swap secrets
+	adminToken string
 }
 
 // NewSwapService ...
-func NewSwapService(sm SwapManager, xmrtaker XMRTaker, xmrmaker XMRMaker, net Net) *SwapService {
+func NewSwapService(sm SwapManager, xmrtaker XMRTaker, xmrmaker XMRMaker, net Net, pb ProtocolBackend,
+	d db.Database, pending PendingRecovery) *SwapService {
 	return &SwapService{
 		sm:       sm,
 		xmrtaker: xmrtaker,
 		xmrmaker: xmrmaker,
 		net:      net,
+		pb:       pb,
+		db:       d,
+		pending:  pending,
 	}
 }
 
@@ -32,7 +54,9 @@ type GetPastIDsResponse struct {
 	IDs []string `json:"ids"`
 }
 
-// GetPastIDs returns all past swap IDs
+// GetPastIDs returns all past swap IDs.
+//
+// Deprecated: use GetPastSwaps, which filters the swaps and pages through them.
 func (s *SwapService) GetPastIDs(_ *http.Request, _ *interface{}, resp *GetPastIDsResponse) error {
 	ids := s.sm.GetPastIDs()
 	resp.IDs = make([]string, len(ids))
@@ -54,11 +78,18 @@ type GetPastResponse struct {
 	ReceivedAmount float64            `json:"receivedAmount"`
 	ExchangeRate   types.ExchangeRate `json:"exchangeRate"`
 	Status         string             `json:"status"`
+	TxHashes       []string           `json:"txHashes,omitempty"`
+	// PeerVersion is the version info the counterparty sent, if any
+	PeerVersion *types.VersionInfo `json:"peerVersion,omitempty"`
+	// PeerID is the libp2p peer ID of the counterparty, if it's known
+	PeerID    string     `json:"peerID,omitempty"`
+	StartTime time.Time  `json:"startTime"`
+	EndTime   *time.Time `json:"endTime,omitempty"`
 }
 
 // GetPast returns information about a past swap, given its ID.
 func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPastResponse) error {
-	offerID, err := offerIDStringToHash(req.OfferID)
+	offerID, err := parseSwapID(s.sm, req.OfferID)
 	if err != nil {
 		return err
 	}
@@ -68,11 +99,191 @@ func (s *SwapService) GetPast(_ *http.Request, req *GetPastRequest, resp *GetPas
 		return errNoSwapWithID
 	}
 
-	resp.Provided = info.Provides()
-	resp.ProvidedAmount = info.ProvidedAmount()
-	resp.ReceivedAmount = info.ReceivedAmount()
-	resp.ExchangeRate = info.ExchangeRate()
-	resp.Status = info.Status().String()
+	*resp = *newGetPastResponse(info)
+	return nil
+}
+
+func newGetPastResponse(info *swap.Info) *GetPastResponse {
+	resp := &GetPastResponse{
+		Provided:       info.Provides(),
+		ProvidedAmount: info.ProvidedAmount(),
+		ReceivedAmount: info.ReceivedAmount(),
+		ExchangeRate:   info.ExchangeRate(),
+		Status:         info.Status().String(),
+		TxHashes:       txHashStrings(info.TxHashes()),
+		PeerVersion:    info.PeerVersion(),
+		PeerID:         info.PeerID(),
+		StartTime:      info.StartTime(),
+	}
+
+	if end := info.EndTime(); !end.IsZero() {
+		resp.EndTime = &end
+	}
+
+	return resp
+}
+
+// GetPastSwapsRequest ...
+type GetPastSwapsRequest struct {
+	// From and To restrict the swaps to those started in [From, To); either may be omitted
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+	// Statuses restricts the swaps to those with one of the given statuses, eg. "Success"
+	Statuses []string `json:"statuses,omitempty"`
+	PeerID   string   `json:"peerID,omitempty"`
+	// Provides restricts the swaps to those in which we provided the given coin, "ETH" or "XMR"
+	Provides string `json:"provides,omitempty"`
+	// Limit is the most swaps to return, if it's positive
+	Limit int `json:"limit,omitempty"`
+	// Cursor, if set, is the NextCursor of the previous page of swaps
+	Cursor string `json:"cursor,omitempty"`
+}
+
+func (req *GetPastSwapsRequest) historyFilter() (*swap.HistoryFilter, error) {
+	if req.Limit < 0 {
+		return nil, errInvalidLimit
+	}
+
+	filter := &swap.HistoryFilter{
+		PeerID: req.PeerID,
+		After:  req.Cursor,
+		Limit:  req.Limit,
+	}
+
+	if req.Provides != "" {
+		provides, err := types.NewProvidesCoin(req.Provides)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.Provides = provides
+	}
+
+	if req.From != nil {
+		filter.From = *req.From
+	}
+
+	if req.To != nil {
+		filter.To = *req.To
+	}
+
+	for _, str := range req.Statuses {
+		status := types.NewStatus(str)
+		if status == types.UnknownStatus {
+			return nil, fmt.Errorf("%w: %s", errInvalidStatus, str)
+		}
+
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	return filter, nil
+}
+
+// PastSwap is a swap returned by swap_getPastSwaps.
+type PastSwap struct {
+	ID types.Hash `json:"id"`
+	GetPastResponse
+}
+
+// GetPastSwapsResponse ...
+type GetPastSwapsResponse struct {
+	Swaps []*PastSwap `json:"swaps"`
+	// NextCursor is set if there may be more swaps; it's passed as the Cursor of the next request
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// GetPastSwaps returns the past swaps matching the request's filters, in the order they were
+// started, a page at a time if the request has a limit.
+func (s *SwapService) GetPastSwaps(_ *http.Request, req *GetPastSwapsRequest, resp *GetPastSwapsResponse) error {
+	filter, err := req.historyFilter()
+	if err != nil {
+		return err
+	}
+
+	swaps, err := s.sm.GetPastSwaps(filter)
+	if err != nil {
+		return err
+	}
+
+	resp.Swaps = make([]*PastSwap, len(swaps))
+	for i, info := range swaps {
+		resp.Swaps[i] = &PastSwap{
+			ID:              info.ID(),
+			GetPastResponse: *newGetPastResponse(info),
+		}
+	}
+
+	if req.Limit > 0 && len(swaps) == req.Limit {
+		resp.NextCursor = swap.HistoryCursor(swaps[len(swaps)-1])
+	}
+
+	return nil
+}
+
+// ExportHistoryRequest ...
+type ExportHistoryRequest struct {
+	GetPastSwapsRequest
+	// Format is "csv" or "json"
+	Format string `json:"format"`
+}
+
+// ExportHistoryResponse ...
+type ExportHistoryResponse struct {
+	Data string `json:"data"`
+}
+
+// ExportHistory exports the past swaps matching the request's filters, in the order they were
+// started, as CSV or JSON for accounting.
+func (s *SwapService) ExportHistory(_ *http.Request, req *ExportHistoryRequest, resp *ExportHistoryResponse) error {
+	filter, err := req.historyFilter()
+	if err != nil {
+		return err
+	}
+
+	swaps, err := s.sm.GetPastSwaps(filter)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = export.Write(&buf, req.Format, swaps); err != nil {
+		return err
+	}
+
+	resp.Data = buf.String()
+	return nil
+}
+
+// StatsRequest ...
+type StatsRequest struct {
+	// From and To restrict the statistics to swaps started in [From, To); either may be omitted
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+// StatsResponse ...
+type StatsResponse struct {
+	stats.Stats
+}
+
+// Stats returns statistics about our past swaps: their volume, how they completed, how long
+// they took, and what their transactions cost.
+func (s *SwapService) Stats(_ *http.Request, req *StatsRequest, resp *StatsResponse) error {
+	filter := &swap.HistoryFilter{}
+	if req.From != nil {
+		filter.From = *req.From
+	}
+
+	if req.To != nil {
+		filter.To = *req.To
+	}
+
+	st, err := stats.Compute(s.sm, filter)
+	if err != nil {
+		return err
+	}
+
+	resp.Stats = *st
 	return nil
 }
 
@@ -83,6 +294,12 @@ type GetOngoingResponse struct {
 	ReceivedAmount float64            `json:"receivedAmount"`
 	ExchangeRate   types.ExchangeRate `json:"exchangeRate"`
 	Status         string             `json:"status"`
+	TxHashes       []string           `json:"txHashes,omitempty"`
+	// MoneroHeight and MoneroTargetHeight are set while the swap is waiting for monero blocks
+	MoneroHeight       uint `json:"moneroHeight,omitempty"`
+	MoneroTargetHeight uint `json:"moneroTargetHeight,omitempty"`
+	// PeerVersion is the version info the counterparty sent, if any
+	PeerVersion *types.VersionInfo `json:"peerVersion,omitempty"`
 }
 
 // GetOngoingRequest ...
@@ -92,7 +309,7 @@ type GetOngoingRequest struct {
 
 // GetOngoing returns information about the ongoing swap, if there is one.
 func (s *SwapService) GetOngoing(_ *http.Request, req *GetOngoingRequest, resp *GetOngoingResponse) error {
-	offerID, err := offerIDStringToHash(req.OfferID)
+	offerID, err := parseSwapID(s.sm, req.OfferID)
 	if err != nil {
 		return err
 	}
@@ -107,6 +324,9 @@ func (s *SwapService) GetOngoing(_ *http.Request, req *GetOngoingRequest, resp *
 	resp.ReceivedAmount = info.ReceivedAmount()
 	resp.ExchangeRate = info.ExchangeRate()
 	resp.Status = info.Status().String()
+	resp.TxHashes = txHashStrings(info.TxHashes())
+	resp.MoneroHeight, resp.MoneroTargetHeight = info.MoneroProgress()
+	resp.PeerVersion = info.PeerVersion()
 	return nil
 }
 
@@ -118,19 +338,23 @@ type RefundRequest struct {
 // RefundResponse ...
 type RefundResponse struct {
 	TxHash string `json:"transactionHash"`
+	// Recovering is set if the swap was pending recovery, in which case it's refunded in the
+	// background and TxHash isn't set
+	Recovering bool `json:"recovering,omitempty"`
 }
 
-// Refund refunds the ongoing swap if we are the ETH provider.
+// Refund refunds the ongoing swap, or the swap pending recovery, if we are the ETH provider and
+// the swap contract allows it now.
 // TODO: remove in favour of swap_cancel?
 func (s *SwapService) Refund(_ *http.Request, req *RefundRequest, resp *RefundResponse) error {
-	offerID, err := offerIDStringToHash(req.OfferID)
+	offerID, err := parseSwapID(s.sm, req.OfferID)
 	if err != nil {
 		return err
 	}
 
 	info := s.sm.GetOngoingSwap(offerID)
 	if info == nil {
-		return errNoOngoingSwap
+		return s.refundPendingRecovery(offerID, resp)
 	}
 
 	if info.Provides() != types.ProvidesETH {
@@ -146,6 +370,129 @@ func (s *SwapService) Refund(_ *http.Request, req *RefundRequest, resp *RefundRe
 	return nil
 }
 
+// refundPendingRecovery starts refunding the given swap pending recovery, if it can be refunded now.
+func (s *SwapService) refundPendingRecovery(offerID types.Hash, resp *RefundResponse) error {
+	p := s.findPendingRecovery(offerID)
+	switch {
+	case p == nil:
+		return errNoOngoingSwap
+	case p.Provides == types.ProvidesXMR:
+		return errCannotRefund
+	case p.Class == recovery.ClassWaiting:
+		return fmt.Errorf("%w: it can be refunded from %s", errCannotRefundYet, p.Timeout1.Format(time.RFC3339))
+	case p.Class != recovery.ClassRefundable:
+		return fmt.Errorf("%w: it's %s", errCannotRefundYet, p.Class)
+	}
+
+	if err := s.pending.Resolve(offerID); err != nil {
+		return err
+	}
+
+	resp.Recovering = true
+	return nil
+}
+
+// ClaimRequest ...
+type ClaimRequest struct {
+	OfferID string `json:"id"`
+}
+
+// ClaimResponse ...
+type ClaimResponse struct {
+	TxHash string `json:"transactionHash"`
+	// Recovering is set if the swap was pending recovery, in which case it's claimed in the
+	// background and TxHash isn't set
+	Recovering bool `json:"recovering,omitempty"`
+}
+
+// Claim claims the ongoing swap now, or the swap pending recovery, if we are the XMR provider and
+// the swap contract allows it now. It's for when the automatic claim hasn't succeeded.
+func (s *SwapService) Claim(_ *http.Request, req *ClaimRequest, resp *ClaimResponse) error {
+	offerID, err := parseSwapID(s.sm, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	info := s.sm.GetOngoingSwap(offerID)
+	if info == nil {
+		return s.claimPendingRecovery(offerID, resp)
+	}
+
+	if info.Provides() != types.ProvidesXMR || s.xmrmaker == nil {
+		return errCannotClaim
+	}
+
+	txHash, err := s.xmrmaker.Claim(offerID)
+	if err != nil {
+		return fmt.Errorf("failed to claim: %w", err)
+	}
+
+	resp.TxHash = txHash.String()
+	return nil
+}
+
+// claimPendingRecovery starts claiming the given swap pending recovery, if it can be claimed now.
+func (s *SwapService) claimPendingRecovery(offerID types.Hash, resp *ClaimResponse) error {
+	p := s.findPendingRecovery(offerID)
+	switch {
+	case p == nil:
+		return errNoOngoingSwap
+	case p.Provides == types.ProvidesETH:
+		return errCannotClaim
+	case p.Class == recovery.ClassWaiting && time.Now().Before(p.Timeout0):
+		return fmt.Errorf("%w: it can be claimed from %s", errCannotClaimYet, p.Timeout0.Format(time.RFC3339))
+	case p.Class != recovery.ClassClaimable:
+		return fmt.Errorf("%w: it's %s", errCannotClaimYet, p.Class)
+	}
+
+	if err := s.pending.Resolve(offerID); err != nil {
+		return err
+	}
+
+	resp.Recovering = true
+	return nil
+}
+
+// findPendingRecovery returns the swap pending recovery with the given ID, or nil if there isn't one.
+func (s *SwapService) findPendingRecovery(offerID types.Hash) *recovery.PendingSwap {
+	if s.pending == nil {
+		return nil
+	}
+
+	for _, p := range s.pending.Pending() {
+		if p.ID == offerID {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// ConfirmReadyRequest ...
+type ConfirmReadyRequest struct {
+	OfferID string `json:"id"`
+}
+
+// ConfirmReady allows the ongoing swap to be set to ready, if we're the ETH provider and
+// swaps are only set to ready once confirmed.
+func (s *SwapService) ConfirmReady(_ *http.Request, req *ConfirmReadyRequest, _ *interface{}) error {
+	offerID, err := parseSwapID(s.sm, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	info := s.sm.GetOngoingSwap(offerID)
+	if info == nil {
+		return errNoOngoingSwap
+	}
+
+	if info.Provides() != types.ProvidesETH {
+		return errCannotConfirmReady
+	}
+
+	return s.xmrtaker.ConfirmReady(offerID)
+}
+
 // GetStageRequest ...
 type GetStageRequest struct {
 	OfferID string `json:"id"`
@@ -159,7 +506,7 @@ type GetStageResponse struct {
 
 // GetStage returns the stage of the ongoing swap, if there is one.
 func (s *SwapService) GetStage(_ *http.Request, req *GetStageRequest, resp *GetStageResponse) error {
-	offerID, err := offerIDStringToHash(req.OfferID)
+	offerID, err := parseSwapID(s.sm, req.OfferID)
 	if err != nil {
 		return err
 	}
@@ -174,6 +521,87 @@ func (s *SwapService) GetStage(_ *http.Request, req *GetStageRequest, resp *GetS
 	return nil
 }
 
+// GetStatusesRequest ...
+type GetStatusesRequest struct {
+	// OfferIDs are the swaps to get the statuses of, ongoing or past; if it's empty, the statuses of
+	// all the ongoing swaps are returned
+	OfferIDs []string `json:"ids,omitempty"`
+}
+
+// SwapStatus is a swap's status, as returned by swap_getStatuses.
+type SwapStatus struct {
+	ID      types.Hash `json:"id"`
+	Stage   string     `json:"stage"`
+	Info    string     `json:"info"`
+	Ongoing bool       `json:"ongoing"`
+	// Since is when the swap reached its stage
+	Since time.Time `json:"since"`
+}
+
+// GetStatusesResponse ...
+type GetStatusesResponse struct {
+	Statuses []*SwapStatus `json:"statuses"`
+}
+
+// GetStatuses returns the statuses of the given swaps, or of all the ongoing swaps, in one call.
+func (s *SwapService) GetStatuses(_ *http.Request, req *GetStatusesRequest, resp *GetStatusesResponse) error {
+	if len(req.OfferIDs) == 0 {
+		swaps := s.sm.GetOngoingSwaps()
+		sort.Slice(swaps, func(i, j int) bool {
+			ti, tj := swaps[i].StartTime(), swaps[j].StartTime()
+			if ti.Equal(tj) {
+				idi, idj := swaps[i].ID(), swaps[j].ID()
+				return bytes.Compare(idi[:], idj[:]) < 0
+			}
+			return ti.Before(tj)
+		})
+
+		resp.Statuses = make([]*SwapStatus, len(swaps))
+		for i, info := range swaps {
+			resp.Statuses[i] = newSwapStatus(info, true)
+		}
+		return nil
+	}
+
+	resp.Statuses = make([]*SwapStatus, len(req.OfferIDs))
+	for i, str := range req.OfferIDs {
+		offerID, err := parseSwapID(s.sm, str)
+		if err != nil {
+			return err
+		}
+
+		if info := s.sm.GetOngoingSwap(offerID); info != nil {
+			resp.Statuses[i] = newSwapStatus(info, true)
+			continue
+		}
+
+		info := s.sm.GetPastSwap(offerID)
+		if info == nil {
+			return fmt.Errorf("%w: %s", errNoSwapWithID, str)
+		}
+
+		resp.Statuses[i] = newSwapStatus(info, false)
+	}
+
+	return nil
+}
+
+func newSwapStatus(info *swap.Info, ongoing bool) *SwapStatus {
+	status := &SwapStatus{
+		ID:      info.ID(),
+		Stage:   info.Status().String(),
+		Info:    info.Status().Info(),
+		Ongoing: ongoing,
+		Since:   info.StartTime(),
+	}
+
+	if times := info.StatusTimes(); len(times) != 0 {
+		status.Since = times[len(times)-1].Time
+	}
+
+	return status
+}
+
 // GetOffersResponse ...
 type GetOffersResponse struct {
 	Offers []*types.Offer `json:"offers"`
@@ -195,9 +623,10 @@ type CancelResponse struct {
 	Status types.Status `json:"status"`
 }
 
-// Cancel attempts to cancel the currently ongoing swap, if there is one.
+// Cancel attempts to cancel the currently ongoing swap, if there is one. If our funds aren't
+// locked yet, the counterparty is notified and the swap ends as Aborted; otherwise we refund.
 func (s *SwapService) Cancel(_ *http.Request, req *CancelRequest, resp *CancelResponse) error {
-	offerID, err := offerIDStringToHash(req.OfferID)
+	offerID, err := parseSwapID(s.sm, req.OfferID)
 	if err != nil {
 		return err
 	}
@@ -210,12 +639,21 @@ func (s *SwapService) Cancel(_ *http.Request, req *CancelRequest, resp *CancelRe
 	var ss common.SwapState
 	switch info.Provides() {
 	case types.ProvidesETH:
-		ss = s.xmrtaker.GetOngoingSwapState(offerID)
+		if s.xmrtaker != nil {
+			ss = s.xmrtaker.GetOngoingSwapState(offerID)
+		}
 	case types.ProvidesXMR:
-		ss = s.xmrmaker.GetOngoingSwapState(offerID)
+		if s.xmrmaker != nil {
+			ss = s.xmrmaker.GetOngoingSwapState(offerID)
+		}
 	}
 
-	if err := ss.Exit(); err != nil {
+	// the swap may have completed since we looked it up
+	if ss == nil {
+		return errNoOngoingSwap
+	}
+
+	if err := ss.Cancel(); err != nil {
 		return err
 	}
 	s.net.CloseProtocolStream(offerID)
@@ -225,12 +663,118 @@ func (s *SwapService) Cancel(_ *http.Request, req *CancelRequest, resp *CancelRe
 	return nil
 }
 
-func offerIDStringToHash(s string) (types.Hash, error) {
-	offerIDBytes, err := hex.DecodeString(s)
+// ExportRecoveryBundleRequest ...
+type ExportRecoveryBundleRequest struct {
+	OfferID    string `json:"id"`
+	Passphrase string `json:"passphrase"`
+}
+
+// ExportRecoveryBundleResponse ...
+type ExportRecoveryBundleResponse struct {
+	Bundle []byte `json:"bundle"`
+}
+
+// ExportRecoveryBundle returns everything needed to recover the given swap's funds without this
+// daemon, encrypted with the given passphrase. It can be imported with `swapcli recover`. As the
+// bundle holds the swap's private keys, it requires the admin token.
+func (s *SwapService) ExportRecoveryBundle(r *http.Request, req *ExportRecoveryBundleRequest,
+	resp *ExportRecoveryBundleResponse) error {
+	if err := checkAdminToken(r, s.adminToken); err != nil {
+		return err
+	}
+
+	if s.db == nil {
+		return errNoDatabase
+	}
+
+	offerID, err := parseSwapID(s.sm, req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	info, err := pcommon.FindInfoInDatabase(s.db, offerID)
+	if errors.Is(err, db.ErrNotFound) {
+		return errNoSwapWithID
+	}
+	if err != nil {
+		return err
+	}
+
+	resp.Bundle, err = pcommon.ExportRecoveryBundle(s.pb.Env(), info, []byte(req.Passphrase))
+	return err
+}
+
+// GetPendingRecoveryResponse ...
+type GetPendingRecoveryResponse struct {
+	Swaps []*recovery.PendingSwap `json:"swaps"`
+}
+
+// GetPendingRecovery returns the swaps that were abandoned when the daemon last stopped and haven't
+// been recovered yet, classified by whether they can be claimed or refunded now.
+func (s *SwapService) GetPendingRecovery(_ *http.Request, _ *interface{}, resp *GetPendingRecoveryResponse) error {
+	resp.Swaps = []*recovery.PendingSwap{}
+	if s.pending != nil {
+		resp.Swaps = s.pending.Pending()
+	}
+	return nil
+}
+
+// ResolvePendingRecoveryRequest ...
+type ResolvePendingRecoveryRequest struct {
+	OfferID string `json:"id"`
+}
+
+// ResolvePendingRecovery starts recovering a swap returned by swap_getPendingRecovery: its funds are
+// claimed or refunded, waiting for the contract's timeouts if need be.
+func (s *SwapService) ResolvePendingRecovery(_ *http.Request, req *ResolvePendingRecoveryRequest,
+	_ *interface{}) error {
+	if s.pending == nil {
+		return errNoSwapWithID
+	}
+
+	offerID, err := parseOfferID(req.OfferID)
+	if err != nil {
+		return err
+	}
+
+	return s.pending.Resolve(offerID)
+}
+
+// parseOfferID parses a hex-encoded offer ID.
+func parseOfferID(s string) (types.Hash, error) {
+	hexID := strings.TrimPrefix(s, "0x")
+	if len(hexID) != 2*len(types.Hash{}) {
+		return types.Hash{}, errInvalidSwapID
+	}
+
+	return types.HexToHash(hexID)
+}
+
+// parseSwapID parses a hex-encoded swap ID. During the deprecation window, legacy numeric
+// swap IDs are also accepted and resolved to the swap's hash ID using the swap manager.
+func txHashStrings(hashes []ethcommon.Hash) []string {
+	strs := make([]string, len(hashes))
+	for i, h := range hashes {
+		strs[i] = h.String()
+	}
+	return strs
+}
+
+func parseSwapID(sm SwapManager, s string) (types.Hash, error) {
+	if id, err := parseOfferID(s); err == nil {
+		return id, nil
+	}
+
+	legacyID, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
-		return types.Hash{}, err
+		return types.Hash{}, errInvalidSwapID
 	}
-	var offerID types.Hash
-	copy(offerID[:], offerIDBytes)
-	return offerID, nil
+
+	log.Warnf("numeric swap IDs are deprecated, use the swap's hex-encoded ID instead: id=%d", legacyID)
+	id, has := sm.GetIDByLegacyID(legacyID)
+	if !has {
+		return types.Hash{}, errNoSwapWithID
+	}
+
+	return id, nil
 }
@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/swap"
+	recovery "github.com/noot/atomic-swap/recover"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mockCompletedXMRTaker has no swap state for any swap, as if they'd all just completed.
+type mockCompletedXMRTaker struct {
+	mockXMRTaker
+}
+
+func (*mockCompletedXMRTaker) GetOngoingSwapState(types.Hash) common.SwapState {
+	return nil
+}
+
+// mockNoSwapManager has no ongoing or past swaps.
+type mockNoSwapManager struct {
+	mockSwapManager
+}
+
+func (*mockNoSwapManager) GetOngoingSwap(types.Hash) *swap.Info {
+	return nil
+}
+
+type mockPendingRecovery struct {
+	swaps    []*recovery.PendingSwap
+	resolved []types.Hash
+}
+
+func (m *mockPendingRecovery) Pending() []*recovery.PendingSwap {
+	return m.swaps
+}
+
+func (m *mockPendingRecovery) Resolve(id types.Hash) error {
+	m.resolved = append(m.resolved, id)
+	return nil
+}
+
+func TestSwapService_Cancel(t *testing.T) {
+	req := &CancelRequest{OfferID: testSwapID.String()}
+
+	s := NewSwapService(new(mockSwapManager), new(mockXMRTaker), nil, new(mockNet), nil, nil, nil)
+	require.NoError(t, s.Cancel(nil, req, new(CancelResponse)))
+
+	// the swap completed after it was looked up
+	s = NewSwapService(new(mockSwapManager), new(mockCompletedXMRTaker), nil, new(mockNet), nil, nil, nil)
+	require.Equal(t, errNoOngoingSwap, s.Cancel(nil, req, new(CancelResponse)))
+
+	// we aren't running as the ETH provider
+	s = NewSwapService(new(mockSwapManager), nil, nil, new(mockNet), nil, nil, nil)
+	require.Equal(t, errNoOngoingSwap, s.Cancel(nil, req, new(CancelResponse)))
+}
+
+func TestSwapService_Refund_PendingRecovery(t *testing.T) {
+	refundable := types.Hash{1}
+	waiting := types.Hash{2}
+	claimable := types.Hash{3}
+	pending := &mockPendingRecovery{
+		swaps: []*recovery.PendingSwap{
+			{ID: refundable, Class: recovery.ClassRefundable, Provides: types.ProvidesETH},
+			{ID: waiting, Class: recovery.ClassWaiting, Provides: types.ProvidesETH, Timeout1: time.Now()},
+			{ID: claimable, Class: recovery.ClassClaimable, Provides: types.ProvidesXMR},
+		},
+	}
+
+	s := NewSwapService(new(mockNoSwapManager), new(mockXMRTaker), nil, new(mockNet), nil, nil, pending)
+	refund := func(id types.Hash) (*RefundResponse, error) {
+		resp := new(RefundResponse)
+		return resp, s.Refund(nil, &RefundRequest{OfferID: id.String()}, resp)
+	}
+
+	resp, err := refund(refundable)
+	require.NoError(t, err)
+	require.True(t, resp.Recovering)
+	require.Empty(t, resp.TxHash)
+	require.Equal(t, []types.Hash{refundable}, pending.resolved)
+
+	// the swaps that can't be refunded now aren't recovered
+	_, err = refund(waiting)
+	require.ErrorIs(t, err, errCannotRefundYet)
+	_, err = refund(claimable)
+	require.Equal(t, errCannotRefund, err)
+	_, err = refund(types.Hash{4})
+	require.Equal(t, errNoOngoingSwap, err)
+	require.Len(t, pending.resolved, 1)
+}
+
+func TestSwapService_Claim_NotXMRProvider(t *testing.T) {
+	s := NewSwapService(new(mockSwapManager), new(mockXMRTaker), nil, new(mockNet), nil, nil, nil)
+	err := s.Claim(nil, &ClaimRequest{OfferID: testSwapID.String()}, new(ClaimResponse))
+	require.Equal(t, errCannotClaim, err)
+}
+
+func TestSwapService_Claim_PendingRecovery(t *testing.T) {
+	claimable := types.Hash{1}
+	waiting := types.Hash{2}
+	pastT1 := types.Hash{3}
+	refundable := types.Hash{4}
+	pending := &mockPendingRecovery{
+		swaps: []*recovery.PendingSwap{
+			{ID: claimable, Class: recovery.ClassClaimable, Provides: types.ProvidesXMR},
+			{ID: waiting, Class: recovery.ClassWaiting, Provides: types.ProvidesXMR, Timeout0: time.Now().Add(time.Hour)},
+			{ID: pastT1, Class: recovery.ClassWaiting, Provides: types.ProvidesXMR, Timeout0: time.Now().Add(-time.Hour)},
+			{ID: refundable, Class: recovery.ClassRefundable, Provides: types.ProvidesETH},
+		},
+	}
+
+	s := NewSwapService(new(mockNoSwapManager), nil, nil, new(mockNet), nil, nil, pending)
+	claim := func(id types.Hash) (*ClaimResponse, error) {
+		resp := new(ClaimResponse)
+		return resp, s.Claim(nil, &ClaimRequest{OfferID: id.String()}, resp)
+	}
+
+	resp, err := claim(claimable)
+	require.NoError(t, err)
+	require.True(t, resp.Recovering)
+	require.Empty(t, resp.TxHash)
+	require.Equal(t, []types.Hash{claimable}, pending.resolved)
+
+	// the swaps that can't be claimed now aren't recovered
+	_, err = claim(waiting)
+	require.ErrorIs(t, err, errCannotClaimYet)
+	require.Contains(t, err.Error(), "can be claimed from")
+	_, err = claim(pastT1)
+	require.ErrorIs(t, err, errCannotClaimYet)
+	_, err = claim(refundable)
+	require.Equal(t, errCannotClaim, err)
+	_, err = claim(types.Hash{5})
+	require.Equal(t, errNoOngoingSwap, err)
+	require.Len(t, pending.resolved, 1)
+}
+
+func TestSwapService_GetPastSwaps_Pages(t *testing.T) {
+	sm := swap.NewManager()
+	for id := byte(1); id <= 3; id++ {
+		info := swap.NewInfo(types.Hash{id}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil)
+		require.NoError(t, sm.AddSwap(info))
+		info.SetStatus(types.CompletedSuccess)
+		sm.CompleteOngoingSwap(info.ID())
+	}
+
+	s := NewSwapService(sm, nil, nil, new(mockNet), nil, nil, nil)
+	getPast := func(req *GetPastSwapsRequest) (*GetPastSwapsResponse, error) {
+		resp := new(GetPastSwapsResponse)
+		return resp, s.GetPastSwaps(nil, req, resp)
+	}
+
+	req := &GetPastSwapsRequest{Provides: "xmr", Limit: 2}
+	resp, err := getPast(req)
+	require.NoError(t, err)
+	require.Len(t, resp.Swaps, 2)
+	require.NotEmpty(t, resp.NextCursor)
+
+	req.Cursor = resp.NextCursor
+	resp, err = getPast(req)
+	require.NoError(t, err)
+	require.Len(t, resp.Swaps, 1)
+	require.Empty(t, resp.NextCursor)
+
+	resp, err = getPast(&GetPastSwapsRequest{Provides: "ETH"})
+	require.NoError(t, err)
+	require.Empty(t, resp.Swaps)
+
+	_, err = getPast(&GetPastSwapsRequest{Limit: -1})
+	require.Equal(t, errInvalidLimit, err)
+	_, err = getPast(&GetPastSwapsRequest{Provides: "BTC"})
+	require.Error(t, err)
+	_, err = getPast(&GetPastSwapsRequest{Cursor: "not a cursor"})
+	require.ErrorIs(t, err, swap.ErrInvalidCursor)
+}
+
+func TestSwapService_GetStatuses(t *testing.T) {
+	sm := swap.NewManager()
+	past := swap.NewInfo(types.Hash{1}, types.ProvidesXMR, 1, 1, 0.1, types.ExpectingKeys, nil)
+	require.NoError(t, sm.AddSwap(past))
+	past.SetStatus(types.CompletedSuccess)
+	sm.CompleteOngoingSwap(past.ID())
+
+	for id := byte(2); id <= 3; id++ {
+		info := swap.NewInfo(types.Hash{id}, types.ProvidesETH, 1, 1, 0.1, types.ExpectingKeys, nil)
+		require.NoError(t, sm.AddSwap(info))
+	}
+	sm.GetOngoingSwap(types.Hash{3}).SetStatus(types.ETHLocked)
+
+	s := NewSwapService(sm, nil, nil, new(mockNet), nil, nil, nil)
+	getStatuses := func(ids ...types.Hash) ([]*SwapStatus, error) {
+		req := new(GetStatusesRequest)
+		for _, id := range ids {
+			req.OfferIDs = append(req.OfferIDs, id.String())
+		}
+
+		resp := new(GetStatusesResponse)
+		return resp.Statuses, s.GetStatuses(nil, req, resp)
+	}
+
+	// all the ongoing swaps, in the order they were started
+	statuses, err := getStatuses()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, types.Hash{2}, statuses[0].ID)
+	require.Equal(t, types.ExpectingKeys.String(), statuses[0].Stage)
+	require.Equal(t, types.ETHLocked.String(), statuses[1].Stage)
+	require.True(t, statuses[1].Ongoing)
+
+	statuses, err = getStatuses(types.Hash{3}, types.Hash{1})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, types.Hash{3}, statuses[0].ID)
+	require.Equal(t, types.CompletedSuccess.String(), statuses[1].Stage)
+	require.False(t, statuses[1].Ongoing)
+	require.Equal(t, types.CompletedSuccess.Info(), statuses[1].Info)
+
+	_, err = getStatuses(types.Hash{1}, types.Hash{4})
+	require.ErrorIs(t, err, errNoSwapWithID)
+}
@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader serves the TLS certificate in the given files, and loads it again when either file
+// changes, so that the certificate can be rotated without restarting swapd.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	certMod time.Time
+	keyMod  time.Time
+}
+
+// newCertReloader loads the certificate in the given files, which must be PEM-encoded.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+
+	if err := r.reloadIfChanged(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// reloadIfChanged loads the certificate again if either file has been modified since it was last
+// loaded. If the new files can't be loaded, eg. because the certificate has been replaced but its
+// key hasn't yet, the old certificate is kept and loading is tried again next time.
+func (r *certReloader) reloadIfChanged() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cert != nil && certInfo.ModTime().Equal(r.certMod) && keyInfo.ModTime().Equal(r.keyMod) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	if r.cert != nil {
+		log.Infof("reloaded RPC TLS certificate from %s", r.certFile)
+	}
+
+	r.cert = &cert
+	r.certMod = certInfo.ModTime()
+	r.keyMod = keyInfo.ModTime()
+	return nil
+}
+
+func (r *certReloader) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if err := r.reloadIfChanged(); err != nil {
+		log.Warnf("failed to reload RPC TLS certificate, serving the previous one: %s", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cert, nil
+}
+
+// tlsConfig returns the TLS config that the RPC servers are served with.
+func (r *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: r.getCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
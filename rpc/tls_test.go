@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/rpcclient/wsclient"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCert writes a self-signed certificate for localhost and its key to the given files,
+// and returns the certificate.
+func writeTestCert(t *testing.T, certFile, keyFile string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	require.NoError(t, err)
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	require.NoError(t, err)
+	return cert
+}
+
+// touch sets the file's modification time to the given time from now, so that changes made within
+// the filesystem's timestamp resolution are noticed.
+func touch(t *testing.T, file string, d time.Duration) {
+	mod := time.Now().Add(d)
+	require.NoError(t, os.Chtimes(file, mod, mod))
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	first := writeTestCert(t, certFile, keyFile)
+
+	r, err := newCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	cert, err := r.getCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, first.Raw, cert.Certificate[0])
+
+	// the certificate is rotated
+	second := writeTestCert(t, certFile, keyFile)
+	touch(t, certFile, time.Second)
+	touch(t, keyFile, time.Second)
+	cert, err = r.getCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, second.Raw, cert.Certificate[0])
+
+	// a half-written rotation leaves the previous certificate in place
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0600))
+	touch(t, keyFile, time.Second*2)
+	cert, err = r.getCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, second.Raw, cert.Certificate[0])
+
+	_, err = newCertReloader(certFile, keyFile)
+	require.Error(t, err)
+}
+
+func TestServer_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ca := writeTestCert(t, certFile, keyFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	defaultRPCPort++
+	defaultWSPort++
+
+	s, err := NewServer(&Config{
+		Ctx:             ctx,
+		Port:            defaultRPCPort,
+		WsPort:          defaultWSPort,
+		Net:             new(mockNet),
+		ProtocolBackend: newMockProtocolBackend(),
+		XMRTaker:        new(mockXMRTaker),
+		TLSCertFile:     certFile,
+		TLSKeyFile:      keyFile,
+	})
+	require.NoError(t, err)
+	errCh := s.Start()
+	go func() {
+		err := <-errCh
+		require.NoError(t, err)
+	}()
+	time.Sleep(time.Millisecond * 300) // let server start up
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	tlsConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+
+	endpoint := fmt.Sprintf("https://localhost:%d", defaultRPCPort)
+	resp, err := rpctypes.PostRPCWithClient(rpctypes.NewHTTPClient(tlsConfig), endpoint, "net_addresses", "{}")
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+
+	// the certificate isn't trusted without pinning its CA
+	_, err = rpctypes.PostRPC(endpoint, "net_addresses", "{}")
+	require.Error(t, err)
+
+	c, err := wsclient.NewWsClientWithTLS(ctx, fmt.Sprintf("wss://localhost:%d", defaultWSPort), tlsConfig)
+	require.NoError(t, err)
+	c.Close()
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/protocol/events"
 	"github.com/noot/atomic-swap/protocol/txsender"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -21,23 +24,24 @@ const (
 	subscribeMakeOffer  = "net_makeOfferAndSubscribe"
 	subscribeTakeOffer  = "net_takeOfferAndSubscribe"
 	subscribeSwapStatus = "swap_subscribeStatus"
+	subscribeEvents     = "swap_subscribeEvents"
+	subscribeOffers     = "net_subscribeOffers"
 	subscribeSigner     = "signer_subscribe"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: checkOriginFunc,
-}
-
-func checkOriginFunc(r *http.Request) bool {
-	return true
-}
-
 type wsServer struct {
 	ctx     context.Context
 	sm      SwapManager
 	ns      *NetService
 	backend ProtocolBackend
 	signer  *txsender.ExternalSender
+	// upgrader's CheckOrigin is set by NewServer; if it's nil, only same-origin requests are
+	// upgraded
+	upgrader websocket.Upgrader
+	limits   WsLimits
+
+	connsMu sync.Mutex
+	conns   int
 }
 
 func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend ProtocolBackend,
@@ -48,6 +52,7 @@ func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend Pr
 		ns:      ns,
 		backend: backend,
 		signer:  signer,
+		limits:  DefaultWsLimits,
 	}
 
 	return s
@@ -55,14 +60,26 @@ func newWsServer(ctx context.Context, sm SwapManager, ns *NetService, backend Pr
 
 // ServeHTTP ...
 func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	if !s.addConn() {
+		log.Debugf("rejected websockets connection from %s, as there are too many", r.RemoteAddr)
+		http.Error(w, "too many websockets connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer s.removeConn()
+
+	c, err := s.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Warnf("failed to update connection to websockets: %s", err)
 		return
 	}
 
+	conn := newWsConn(c, &s.limits)
 	defer conn.Close() //nolint:errcheck
 
+	// the connection's subscriptions end when it's closed
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -70,6 +87,11 @@ func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		if !conn.limiter.allow(time.Now()) {
+			_ = writeError(conn, errRateLimited)
+			continue
+		}
+
 		var req *rpctypes.Request
 		err = json.Unmarshal(message, &req)
 		if err != nil {
@@ -78,14 +100,55 @@ func (s *wsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		log.Debugf("received message over websockets: %s", message)
-		err = s.handleRequest(conn, req)
+		err = s.handleRequest(ctx, conn, req)
 		if err != nil {
 			_ = writeError(conn, err)
 		}
 	}
 }
 
-func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) error {
+// addConn reserves one of the server's connections, returning false if there are none left.
+func (s *wsServer) addConn() bool {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+
+	if s.conns >= s.limits.MaxConnections {
+		return false
+	}
+
+	s.conns++
+	return true
+}
+
+func (s *wsServer) removeConn() {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	s.conns--
+}
+
+// subscribe runs the subscription in the background, so that the connection can make other
+// requests meanwhile, if the connection has a subscription left.
+func (s *wsServer) subscribe(conn *wsConn, run func() error) error {
+	if !conn.addSubscription() {
+		return errTooManySubscriptions
+	}
+
+	runSubscription(conn, run)
+	return nil
+}
+
+// runSubscription runs the subscription, which has already been added to the connection, in the
+// background.
+func runSubscription(conn *wsConn, run func() error) {
+	go func() {
+		defer conn.removeSubscription()
+		if err := run(); err != nil {
+			_ = writeError(conn, err)
+		}
+	}()
+}
+
+func (s *wsServer) handleRequest(ctx context.Context, conn *wsConn, req *rpctypes.Request) error {
 	switch req.Method {
 	case subscribeSigner:
 		var params *rpctypes.SignerRequest
@@ -93,7 +156,7 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
-		return s.handleSigner(s.ctx, conn, params.OfferID, params.EthAddress, params.XMRAddress)
+		return s.handleSigner(ctx, conn, params.OfferID, params.EthAddress, params.XMRAddress)
 	case subscribeNewPeer:
 		return errUnimplemented
 	case "net_discover":
@@ -123,43 +186,89 @@ func (s *wsServer) handleRequest(conn *websocket.Conn, req *rpctypes.Request) er
 
 		return writeResponse(conn, resp)
 	case subscribeSwapStatus:
-		var params *rpctypes.SubscribeSwapStatusRequest
-		if err := json.Unmarshal(req.Params, &params); err != nil {
-			return fmt.Errorf("failed to unmarshal parameters: %w", err)
+		id, err := s.parseSubscribeSwapStatusRequest(req.Params)
+		if err != nil {
+			return err
 		}
 
-		return s.subscribeSwapStatus(s.ctx, conn, params.ID)
+		return s.subscribe(conn, func() error {
+			return s.subscribeSwapStatus(ctx, conn, id)
+		})
+	case subscribeEvents:
+		return s.subscribe(conn, func() error {
+			return s.subscribeEvents(ctx, conn)
+		})
+	case subscribeOffers:
+		return s.subscribe(conn, func() error {
+			return s.subscribeOffers(ctx, conn)
+		})
 	case subscribeTakeOffer:
 		var params *rpctypes.TakeOfferRequest
 		if err := json.Unmarshal(req.Params, &params); err != nil {
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
-		ch, infofile, err := s.ns.takeOffer(params.Multiaddr, params.OfferID, params.ProvidesAmount)
+		// the subscription is reserved first, so that the offer isn't taken if it can't be
+		if !conn.addSubscription() {
+			return errTooManySubscriptions
+		}
+
+		ch, infofile, err := s.ns.takeOffer(params)
 		if err != nil {
+			conn.removeSubscription()
 			return err
 		}
 
-		return s.subscribeTakeOffer(s.ctx, conn, ch, infofile)
+		runSubscription(conn, func() error {
+			return s.subscribeTakeOffer(ctx, conn, ch, infofile)
+		})
+		return nil
 	case subscribeMakeOffer:
 		var params *rpctypes.MakeOfferRequest
 		if err := json.Unmarshal(req.Params, &params); err != nil {
 			return fmt.Errorf("failed to unmarshal parameters: %w", err)
 		}
 
+		if !conn.addSubscription() {
+			return errTooManySubscriptions
+		}
+
 		offerID, offerExtra, err := s.ns.makeOffer(params)
 		if err != nil {
+			conn.removeSubscription()
 			return err
 		}
 
 		s.ns.net.Advertise()
-		return s.subscribeMakeOffer(s.ctx, conn, offerID, offerExtra)
+		runSubscription(conn, func() error {
+			return s.subscribeMakeOffer(ctx, conn, offerID, offerExtra)
+		})
+		return nil
 	default:
 		return errInvalidMethod
 	}
 }
 
-func (s *wsServer) handleSigner(ctx context.Context, conn *websocket.Conn, offerIDStr, ethAddress,
+// parseSubscribeSwapStatusRequest returns the swap ID from the given swap_subscribeStatus parameters.
+// During the deprecation window, legacy numeric swap IDs are also accepted.
+func (s *wsServer) parseSubscribeSwapStatusRequest(params json.RawMessage) (types.Hash, error) {
+	var req *rpctypes.SubscribeSwapStatusRequest
+	err := json.Unmarshal(params, &req)
+	if err == nil {
+		return req.ID, nil
+	}
+
+	var legacyReq struct {
+		ID uint64 `json:"id"`
+	}
+	if json.Unmarshal(params, &legacyReq) != nil {
+		return types.Hash{}, fmt.Errorf("failed to unmarshal parameters: %w", err)
+	}
+
+	return parseSwapID(s.sm, strconv.FormatUint(legacyReq.ID, 10))
+}
+
+func (s *wsServer) handleSigner(ctx context.Context, conn *wsConn, offerIDStr, ethAddress,
 	xmrAddr string) error {
 	if s.signer == nil {
 		return errSignerNotRequired
@@ -169,17 +278,23 @@ func (s *wsServer) handleSigner(ctx context.Context, conn *websocket.Conn, offer
 		return err
 	}
 
-	s.backend.SetEthAddress(ethcommon.HexToAddress(ethAddress))
+	signerAddr := ethcommon.HexToAddress(ethAddress)
+	s.backend.SetEthAddress(signerAddr)
 
-	offerID, err := offerIDStringToHash(offerIDStr)
+	offerID, err := parseSwapID(s.sm, offerIDStr)
 	if err != nil {
 		return err
 	}
 
 	s.backend.SetXMRDepositAddress(mcrypto.Address(xmrAddr), offerID)
 
-	s.signer.AddID(offerID)
-	defer s.signer.DeleteID(offerID)
+	// the session is kept by the signer until the swap exits, so if this connection drops,
+	// the front-end can call signer_subscribe again to resume where it left off.
+	disconnected, err := s.signer.SignerConnected(offerID, signerAddr)
+	if err != nil {
+		return err
+	}
+	defer disconnected()
 
 	txsOutCh, err := s.signer.OngoingCh(offerID)
 	if err != nil {
@@ -191,13 +306,29 @@ func (s *wsServer) handleSigner(ctx context.Context, conn *websocket.Conn, offer
 		return err
 	}
 
+	doneCh, err := s.signer.DoneCh(offerID)
+	if err != nil {
+		return err
+	}
+
+	// the connection is owned by the reader goroutine from here on, so it's closed when
+	// this session returns.
+	quit := make(chan struct{})
+	defer close(quit)
+	defer conn.Close() //nolint:errcheck
+	msgCh, readErrCh := readMessages(conn, quit)
+
 	for {
 		select {
-		// TODO: check if conn closes or swap exited
-		case <-time.After(time.Hour): // TODO: vary timeout based on env
-			return fmt.Errorf("signer timed out")
 		case <-ctx.Done():
 			return nil
+		case <-doneCh:
+			return nil
+		case err := <-readErrCh:
+			log.Infof("signer for swap %s disconnected: %s", offerID, err)
+			return nil
+		case message := <-msgCh:
+			log.Warnf("ignoring unexpected message from signer: %s", message)
 		case tx := <-txsOutCh:
 			log.Debugf("outbound tx: %v", tx)
 			resp := &rpctypes.SignerResponse{
@@ -207,14 +338,21 @@ func (s *wsServer) handleSigner(ctx context.Context, conn *websocket.Conn, offer
 				Value:   tx.Value,
 			}
 
-			err := conn.WriteJSON(resp)
-			if err != nil {
+			if err := conn.WriteJSON(resp); err != nil {
 				return err
 			}
 
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				return err
+			var message []byte
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-doneCh:
+				return nil
+			case err := <-readErrCh:
+				// the transaction will be sent again when the signer reconnects
+				log.Infof("signer for swap %s disconnected before signing: %s", offerID, err)
+				return nil
+			case message = <-msgCh:
 			}
 
 			var params *rpctypes.SignerTxSigned
@@ -226,12 +364,42 @@ func (s *wsServer) handleSigner(ctx context.Context, conn *websocket.Conn, offer
 				return fmt.Errorf("got unexpected offerID %s, expected %s", params.OfferID, offerID)
 			}
 
-			txsInCh <- ethcommon.HexToHash(params.TxHash)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-doneCh:
+				return nil
+			case txsInCh <- ethcommon.HexToHash(params.TxHash):
+			}
 		}
 	}
 }
 
-func (s *wsServer) subscribeTakeOffer(ctx context.Context, conn *websocket.Conn,
+// readMessages reads messages from the connection until it fails or quit is closed.
+func readMessages(conn *wsConn, quit <-chan struct{}) (<-chan []byte, <-chan error) {
+	msgCh := make(chan []byte)
+	errCh := make(chan error, 1)
+
+	go func() {
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			select {
+			case msgCh <- message:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return msgCh, errCh
+}
+
+func (s *wsServer) subscribeTakeOffer(ctx context.Context, conn *wsConn,
 	statusCh <-chan types.Status, infofile string) error {
 	resp := &rpctypes.TakeOfferResponse{
 		InfoFile: infofile,
@@ -265,7 +433,7 @@ func (s *wsServer) subscribeTakeOffer(ctx context.Context, conn *websocket.Conn,
 	}
 }
 
-func (s *wsServer) subscribeMakeOffer(ctx context.Context, conn *websocket.Conn,
+func (s *wsServer) subscribeMakeOffer(ctx context.Context, conn *wsConn,
 	offerID string, offerExtra *types.OfferExtra) error {
 	resp := &rpctypes.MakeOfferResponse{
 		ID:       offerID,
@@ -303,13 +471,13 @@ func (s *wsServer) subscribeMakeOffer(ctx context.Context, conn *websocket.Conn,
 // subscribeSwapStatus writes the swap's stage to the connection every time it updates.
 // when the swap completes, it writes the final status then closes the connection.
 // example: `{"jsonrpc":"2.0", "method":"swap_subscribeStatus", "params": {"id": 0}, "id": 0}`
-func (s *wsServer) subscribeSwapStatus(ctx context.Context, conn *websocket.Conn, id types.Hash) error {
+func (s *wsServer) subscribeSwapStatus(ctx context.Context, conn *wsConn, id types.Hash) error {
 	info := s.sm.GetOngoingSwap(id)
 	if info == nil {
 		return s.writeSwapExitStatus(conn, id)
 	}
 
-	statusCh := info.StatusCh()
+	statusCh := info.Subscribe()
 	for {
 		select {
 		case status, ok := <-statusCh:
@@ -334,7 +502,61 @@ func (s *wsServer) subscribeSwapStatus(ctx context.Context, conn *websocket.Conn
 	}
 }
 
-func (s *wsServer) writeSwapExitStatus(conn *websocket.Conn, id types.Hash) error {
+// subscribeEvents writes every swap and offer event to the connection until it's closed.
+// example: `{"jsonrpc":"2.0", "method":"swap_subscribeEvents", "params": {}, "id": 0}`
+func (s *wsServer) subscribeEvents(ctx context.Context, conn *wsConn) error {
+	eventCh, unsubscribe := s.sm.Events().Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case e := <-eventCh:
+			resp := &rpctypes.SubscribeEventsResponse{
+				Type:   string(e.Type),
+				SwapID: e.SwapID,
+				Time:   e.Time,
+				Offer:  e.Offer,
+			}
+			if e.Type != events.OfferMade {
+				resp.Status = e.Status.String()
+			}
+
+			if err := writeResponse(conn, resp); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// subscribeOffers writes every offer the daemon learns about from other peers to the connection,
+// until it's closed.
+// example: `{"jsonrpc":"2.0", "method":"net_subscribeOffers", "params": {}, "id": 0}`
+func (s *wsServer) subscribeOffers(ctx context.Context, conn *wsConn) error {
+	offerCh, unsubscribe := s.ns.net.SubscribeOffers()
+	defer unsubscribe()
+
+	for {
+		select {
+		case seen := <-offerCh:
+			resp := &rpctypes.SubscribeOffersResponse{
+				PeerID: seen.Peer.String(),
+				Offer:  seen.Offer,
+				Source: string(seen.Source),
+				Time:   seen.Time,
+			}
+
+			if err := writeResponse(conn, resp); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *wsServer) writeSwapExitStatus(conn *wsConn, id types.Hash) error {
 	info := s.sm.GetPastSwap(id)
 	if info == nil {
 		return errNoSwapWithID
@@ -351,7 +573,7 @@ func (s *wsServer) writeSwapExitStatus(conn *websocket.Conn, id types.Hash) erro
 	return nil
 }
 
-func writeResponse(conn *websocket.Conn, result interface{}) error {
+func writeResponse(conn *wsConn, result interface{}) error {
 	bz, err := json.Marshal(result)
 	if err != nil {
 		return err
@@ -365,7 +587,7 @@ func writeResponse(conn *websocket.Conn, result interface{}) error {
 	return conn.WriteJSON(resp)
 }
 
-func writeError(conn *websocket.Conn, err error) error {
+func writeError(conn *wsConn, err error) error {
 	resp := &rpctypes.Response{
 		Version: rpctypes.DefaultJSONRPCVersion,
 		Error: &rpctypes.Error{
@@ -0,0 +1,154 @@
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WsLimits limit what websockets clients may do, so that one misbehaving client can't starve the
+// daemon. Limits that aren't set are taken from DefaultWsLimits.
+type WsLimits struct {
+	// MaxConnections is the most websockets connections that may be open at once; clients
+	// connecting over it get a 503
+	MaxConnections int
+	// MaxSubscriptionsPerConn is the most subscriptions each connection may have running at once
+	MaxSubscriptionsPerConn int
+	// MaxMessageSize is the largest message, in bytes, a client may send; clients that send larger
+	// messages are disconnected
+	MaxMessageSize int64
+	// MessagesPerSecond is how many messages each client may send per second, on average, with
+	// bursts of up to MessageBurst; messages over the limit are rejected
+	MessagesPerSecond float64
+	MessageBurst      int
+	// WriteTimeout is how long a message to a client may take to send; clients that don't keep up
+	// with their subscriptions are disconnected
+	WriteTimeout time.Duration
+}
+
+// DefaultWsLimits are the limits used when none are configured.
+var DefaultWsLimits = WsLimits{
+	MaxConnections:          64,
+	MaxSubscriptionsPerConn: 16,
+	MaxMessageSize:          1 << 16,
+	MessagesPerSecond:       10,
+	MessageBurst:            20,
+	WriteTimeout:            time.Second * 10,
+}
+
+// withDefaults returns the limits, with those that aren't set taken from DefaultWsLimits.
+func (l WsLimits) withDefaults() WsLimits {
+	if l.MaxConnections == 0 {
+		l.MaxConnections = DefaultWsLimits.MaxConnections
+	}
+	if l.MaxSubscriptionsPerConn == 0 {
+		l.MaxSubscriptionsPerConn = DefaultWsLimits.MaxSubscriptionsPerConn
+	}
+	if l.MaxMessageSize == 0 {
+		l.MaxMessageSize = DefaultWsLimits.MaxMessageSize
+	}
+	if l.MessagesPerSecond == 0 {
+		l.MessagesPerSecond = DefaultWsLimits.MessagesPerSecond
+	}
+	if l.MessageBurst == 0 {
+		l.MessageBurst = DefaultWsLimits.MessageBurst
+	}
+	if l.WriteTimeout == 0 {
+		l.WriteTimeout = DefaultWsLimits.WriteTimeout
+	}
+	return l
+}
+
+// wsConn is a websockets connection that may be written to by several subscriptions at once.
+type wsConn struct {
+	*websocket.Conn
+	writeTimeout time.Duration
+	limiter      *rateLimiter
+
+	writeMu sync.Mutex
+
+	subsMu  sync.Mutex
+	subs    int
+	maxSubs int
+}
+
+func newWsConn(conn *websocket.Conn, limits *WsLimits) *wsConn {
+	conn.SetReadLimit(limits.MaxMessageSize)
+	return &wsConn{
+		Conn:         conn,
+		writeTimeout: limits.WriteTimeout,
+		limiter:      newRateLimiter(limits.MessagesPerSecond, limits.MessageBurst),
+		maxSubs:      limits.MaxSubscriptionsPerConn,
+	}
+}
+
+// WriteJSON writes the message to the connection. If it can't be written in time, the client
+// isn't keeping up, so it's disconnected.
+func (c *wsConn) WriteJSON(v interface{}) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_ = c.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	if err := c.Conn.WriteJSON(v); err != nil {
+		log.Debugf("disconnecting websockets client %s: %s", c.RemoteAddr(), err)
+		_ = c.Conn.Close()
+		return err
+	}
+
+	return nil
+}
+
+// addSubscription reserves one of the connection's subscriptions, returning false if it has
+// none left.
+func (c *wsConn) addSubscription() bool {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	if c.subs >= c.maxSubs {
+		return false
+	}
+
+	c.subs++
+	return true
+}
+
+func (c *wsConn) removeSubscription() {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	c.subs--
+}
+
+// rateLimiter is a token bucket: it holds up to burst tokens, which refill at rate per second,
+// and each message takes one.
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// allow takes a token, returning false if there are none left.
+func (l *rateLimiter) allow(now time.Time) bool {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+
+	l.tokens--
+	return true
+}
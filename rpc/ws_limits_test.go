@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func newServerWithLimits(t *testing.T, limits *WsLimits) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	defaultRPCPort++
+	defaultWSPort++
+
+	s, err := NewServer(&Config{
+		Ctx:             ctx,
+		Port:            defaultRPCPort,
+		WsPort:          defaultWSPort,
+		Net:             new(mockNet),
+		ProtocolBackend: newMockProtocolBackend(),
+		XMRTaker:        new(mockXMRTaker),
+		WsLimits:        limits,
+	})
+	require.NoError(t, err)
+	_ = s.Start()
+	time.Sleep(time.Millisecond * 300) // let server start up
+}
+
+func dialWs(t *testing.T) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(defaultWSEndpoint(), nil) //nolint:bodyclose
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func writeWsRequest(t *testing.T, conn *websocket.Conn, method string) {
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  method,
+		Params:  []byte("{}"),
+	}
+	require.NoError(t, conn.WriteJSON(req))
+}
+
+// readWsError reads responses from the connection until it reads an error, which it returns.
+func readWsError(t *testing.T, conn *websocket.Conn) string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(testTImeout)))
+	for {
+		resp := new(rpctypes.Response)
+		require.NoError(t, conn.ReadJSON(resp))
+		if resp.Error != nil {
+			return resp.Error.Message
+		}
+	}
+}
+
+func TestWsLimits_MaxConnections(t *testing.T) {
+	newServerWithLimits(t, &WsLimits{MaxConnections: 1})
+
+	conn := dialWs(t)
+	_, resp, err := websocket.DefaultDialer.Dial(defaultWSEndpoint(), nil) //nolint:bodyclose
+	require.Equal(t, websocket.ErrBadHandshake, err)
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// once a connection closes, another can be made
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		conn, _, err := websocket.DefaultDialer.Dial(defaultWSEndpoint(), nil) //nolint:bodyclose
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		return true
+	}, testTImeout, time.Millisecond*50)
+}
+
+func TestWsLimits_MaxSubscriptions(t *testing.T) {
+	newServerWithLimits(t, &WsLimits{MaxSubscriptionsPerConn: 2})
+
+	// subscriptions run alongside each other
+	conn := dialWs(t)
+	writeWsRequest(t, conn, subscribeEvents)
+	writeWsRequest(t, conn, subscribeOffers)
+	writeWsRequest(t, conn, subscribeEvents)
+	require.Equal(t, errTooManySubscriptions.Error(), readWsError(t, conn))
+}
+
+func TestWsLimits_RateLimit(t *testing.T) {
+	newServerWithLimits(t, &WsLimits{MessagesPerSecond: 0.1, MessageBurst: 1})
+
+	conn := dialWs(t)
+	writeWsRequest(t, conn, subscribeNewPeer)
+	require.Equal(t, errUnimplemented.Error(), readWsError(t, conn))
+	writeWsRequest(t, conn, subscribeNewPeer)
+	require.Equal(t, errRateLimited.Error(), readWsError(t, conn))
+}
+
+func TestWsLimits_MaxMessageSize(t *testing.T) {
+	newServerWithLimits(t, &WsLimits{MaxMessageSize: 64})
+
+	conn := dialWs(t)
+	big := `{"jsonrpc":"2.0","method":"` + strings.Repeat("a", 64) + `"}`
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(big)))
+
+	// the client is disconnected
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(testTImeout)))
+	_, _, err := conn.ReadMessage()
+	require.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), err)
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(2, 2)
+	l.last = now
+
+	require.True(t, l.allow(now))
+	require.True(t, l.allow(now))
+	require.False(t, l.allow(now))
+
+	// tokens refill at the rate, up to the burst
+	require.True(t, l.allow(now.Add(time.Millisecond*500)))
+	require.False(t, l.allow(now.Add(time.Millisecond*500)))
+	later := now.Add(time.Hour)
+	require.True(t, l.allow(later))
+	require.True(t, l.allow(later))
+	require.False(t, l.allow(later))
+}
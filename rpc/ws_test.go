@@ -2,7 +2,9 @@ package rpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"testing"
 	"time"
@@ -10,14 +12,18 @@ import (
 	"github.com/noot/atomic-swap/common"
 	"github.com/noot/atomic-swap/common/types"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	"github.com/noot/atomic-swap/monero"
 	"github.com/noot/atomic-swap/net"
 	"github.com/noot/atomic-swap/net/message"
+	"github.com/noot/atomic-swap/protocol/events"
 	"github.com/noot/atomic-swap/protocol/swap"
 	"github.com/noot/atomic-swap/protocol/txsender"
 	"github.com/noot/atomic-swap/rpcclient/wsclient"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	libp2pnetwork "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/require"
 )
 
@@ -52,6 +58,77 @@ func (*mockNet) Query(who peer.AddrInfo) (*net.QueryResponse, error) {
 		},
 	}, nil
 }
+func (*mockNet) MarketOffers() []*net.PeerOffers {
+	return nil
+}
+func (*mockNet) SubscribeOffers() (<-chan *net.SeenOffer, func()) {
+	ch := make(chan *net.SeenOffer, 1)
+	ch <- &net.SeenOffer{
+		Peer:   "12D3KooWAYn1T8Lu122Pav4zAogjpeU61usLTNZpLRNh9gCqY6X2",
+		Offer:  &types.Offer{ID: testSwapID},
+		Source: net.OfferSourceGossip,
+		Time:   time.Now(),
+	}
+	return ch, func() {}
+}
+func (*mockNet) Orderbook() (*net.Orderbook, error) {
+	return &net.Orderbook{
+		Makers: []*net.OrderbookEntry{
+			{Peer: peer.AddrInfo{ID: peer.ID("a")}, Offers: []*types.Offer{{ID: testSwapID}}, UpdatedAt: time.Now()},
+		},
+	}, nil
+}
+func (*mockNet) QueryPrivateOffer(who peer.AddrInfo, token string) (*types.Offer, error) {
+	if token != "token" {
+		return nil, errors.New("no private offer for token")
+	}
+	return &types.Offer{ID: testSwapID}, nil
+}
+func (*mockNet) PeerScore(who peer.ID) *net.PeerScore {
+	return &net.PeerScore{Peer: who}
+}
+func (*mockNet) PeerScores() []*net.PeerScore {
+	return nil
+}
+func (*mockNet) PeerStats() []*net.PeerStats {
+	return []*net.PeerStats{
+		{Peer: peer.ID("a"), Connected: true, BytesIn: 100, BytesOut: 200, Latency: time.Millisecond * 1500},
+	}
+}
+func (*mockNet) ConnectedPeers() []*net.ConnectedPeer {
+	addr, _ := ma.NewMultiaddr("/ip4/127.0.0.1/tcp/9934")
+	return []*net.ConnectedPeer{
+		{
+			Peer:        peer.ID("a"),
+			Addrs:       []ma.Multiaddr{addr},
+			Direction:   libp2pnetwork.DirInbound,
+			ConnectedAt: time.Now().Add(-time.Minute),
+			Score:       &net.PeerScore{Peer: peer.ID("a"), Score: -5},
+		},
+	}
+}
+func (*mockNet) BanPeer(peer.ID, time.Duration) {}
+func (*mockNet) UnbanPeer(peer.ID) error {
+	return nil
+}
+func (*mockNet) BanIP(string, time.Duration) error {
+	return nil
+}
+func (*mockNet) UnbanIP(string) error {
+	return nil
+}
+func (*mockNet) DHTStatus() *net.DHTStatus {
+	return &net.DHTStatus{Mode: net.DHTModeAuto}
+}
+func (*mockNet) RequestQuote(who peer.AddrInfo, req *net.QuoteRequest) (*net.Quote, error) {
+	return &net.Quote{
+		ID:             types.Hash{1},
+		OfferID:        req.OfferID,
+		ProvidedAmount: req.ProvidedAmount,
+		ExchangeRate:   0.1,
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}, nil
+}
 func (*mockNet) Initiate(who peer.AddrInfo, msg *net.SendKeysMessage, s common.SwapStateNet) error {
 	return nil
 }
@@ -79,10 +156,25 @@ func (*mockSwapManager) GetOngoingSwap(id types.Hash) *swap.Info {
 		statusCh,
 	)
 }
+func (*mockSwapManager) GetOngoingSwaps() []*swap.Info {
+	return nil
+}
 func (*mockSwapManager) AddSwap(*swap.Info) error {
 	return nil
 }
 func (*mockSwapManager) CompleteOngoingSwap(types.Hash) {}
+func (*mockSwapManager) GetPastSwaps(*swap.HistoryFilter) ([]*swap.Info, error) {
+	return nil, nil
+}
+func (*mockSwapManager) Events() *events.Bus {
+	return events.NewBus()
+}
+func (*mockSwapManager) GetIDByLegacyID(legacyID uint64) (types.Hash, bool) {
+	if legacyID != 0 {
+		return types.Hash{}, false
+	}
+	return testSwapID, true
+}
 
 type mockXMRTaker struct{}
 
@@ -93,12 +185,16 @@ func (*mockXMRTaker) SetGasPrice(gasPrice uint64) {}
 func (*mockXMRTaker) GetOngoingSwapState(types.Hash) common.SwapState {
 	return new(mockSwapState)
 }
-func (*mockXMRTaker) InitiateProtocol(providesAmount float64, _ *types.Offer) (common.SwapState, error) {
+func (*mockXMRTaker) InitiateProtocol(providesAmount float64, _ *types.Offer, _ string,
+	_ *types.SlippageLimits) (common.SwapState, error) {
 	return new(mockSwapState), nil
 }
 func (*mockXMRTaker) Refund(types.Hash) (ethcommon.Hash, error) {
 	return ethcommon.Hash{}, nil
 }
+func (*mockXMRTaker) ConfirmReady(types.Hash) error {
+	return nil
+}
 func (*mockXMRTaker) SetSwapTimeout(_ time.Duration) {}
 
 type mockSwapState struct{}
@@ -109,6 +205,9 @@ func (*mockSwapState) HandleProtocolMessage(msg message.Message) (resp message.M
 func (*mockSwapState) Exit() error {
 	return nil
 }
+func (*mockSwapState) Cancel() error {
+	return nil
+}
 func (*mockSwapState) SendKeysMessage() (*message.SendKeysMessage, error) {
 	return &message.SendKeysMessage{}, nil
 }
@@ -120,7 +219,9 @@ func (*mockSwapState) InfoFile() string {
 }
 
 type mockProtocolBackend struct {
-	sm *mockSwapManager
+	sm       *mockSwapManager
+	gas      txsender.GasConfig
+	priority monero.TransferPriority
 }
 
 func newMockProtocolBackend() *mockProtocolBackend {
@@ -129,8 +230,21 @@ func newMockProtocolBackend() *mockProtocolBackend {
 	}
 }
 
+func (*mockProtocolBackend) Env() common.Environment {
+	return common.Development
+}
+func (*mockProtocolBackend) ChainID() *big.Int {
+	return big.NewInt(common.GanacheChainID)
+}
 func (*mockProtocolBackend) SetGasPrice(uint64)                   {}
 func (*mockProtocolBackend) SetSwapTimeout(timeout time.Duration) {}
+func (b *mockProtocolBackend) SetGasConfig(cfg *txsender.GasConfig) {
+	b.gas = *cfg
+}
+func (b *mockProtocolBackend) SetTransferPriority(priority monero.TransferPriority) error {
+	b.priority = priority
+	return nil
+}
 func (b *mockProtocolBackend) SwapManager() swap.Manager {
 	return b.sm
 }
@@ -191,6 +305,66 @@ func TestSubscribeSwapStatus(t *testing.T) {
 	}
 }
 
+func TestSubscribeOffers(t *testing.T) {
+	_ = newServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+	})
+	c, err := wsclient.NewWsClient(ctx, defaultWSEndpoint())
+	require.NoError(t, err)
+
+	ch, err := c.SubscribeOffers()
+	require.NoError(t, err)
+
+	select {
+	case offer := <-ch:
+		require.Equal(t, testSwapID, offer.Offer.ID)
+		require.Equal(t, string(net.OfferSourceGossip), offer.Source)
+		require.NotEmpty(t, offer.PeerID)
+	case <-time.After(testTImeout):
+		t.Fatal("test timed out")
+	}
+}
+
+func TestSubscribeSwapStatus_LegacyID(t *testing.T) {
+	s := newWsServer(context.Background(), new(mockSwapManager), nil, nil, nil)
+
+	id, err := s.parseSubscribeSwapStatusRequest([]byte(`{"id":0}`))
+	require.NoError(t, err)
+	require.Equal(t, testSwapID, id)
+
+	_, err = s.parseSubscribeSwapStatusRequest([]byte(`{"id":1}`))
+	require.Equal(t, errNoSwapWithID, err)
+
+	id, err = s.parseSubscribeSwapStatusRequest([]byte(`{"id":"` + testSwapID.String() + `"}`))
+	require.NoError(t, err)
+	require.Equal(t, testSwapID, id)
+}
+
+func TestParseSwapID(t *testing.T) {
+	sm := new(mockSwapManager)
+
+	id, err := parseSwapID(sm, testSwapID.String())
+	require.NoError(t, err)
+	require.Equal(t, testSwapID, id)
+
+	id, err = parseSwapID(sm, "0x"+testSwapID.String())
+	require.NoError(t, err)
+	require.Equal(t, testSwapID, id)
+
+	id, err = parseSwapID(sm, "0")
+	require.NoError(t, err)
+	require.Equal(t, testSwapID, id)
+
+	_, err = parseSwapID(sm, "1")
+	require.Equal(t, errNoSwapWithID, err)
+
+	_, err = parseSwapID(sm, "abcd")
+	require.Equal(t, errInvalidSwapID, err)
+}
+
 // TODO: add unit test
 // func TestSubscribeMakeOffer(t *testing.T) {
 // 	_ = newServer(t)
@@ -223,7 +397,7 @@ func TestSubscribeTakeOffer(t *testing.T) {
 	c, err := wsclient.NewWsClient(ctx, defaultWSEndpoint())
 	require.NoError(t, err)
 
-	ch, err := c.TakeOfferAndSubscribe(testMultiaddr, testSwapID.String(), 1)
+	ch, err := c.TakeOfferAndSubscribe(testMultiaddr, testSwapID.String(), 1, "", "", nil)
 	require.NoError(t, err)
 
 	select {
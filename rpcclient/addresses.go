@@ -3,7 +3,6 @@ package rpcclient
 import (
 	"encoding/json"
 
-	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/rpc"
 )
 
@@ -13,7 +12,7 @@ func (c *Client) Addresses() ([]string, error) {
 		method = "net_addresses"
 	)
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,117 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/rpc"
+)
+
+// BackupDB calls admin_backupDB. It requires the admin token.
+func (c *Client) BackupDB(name string) (uint64, error) {
+	const (
+		method = "admin_backupDB"
+	)
+
+	req := &rpc.BackupDBRequest{
+		Name: name,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.Error != nil {
+		return 0, resp.Error
+	}
+
+	var res *rpc.BackupDBResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return 0, err
+	}
+
+	return res.SchemaVersion, nil
+}
+
+// RestoreDB calls admin_restoreDB. It requires the admin token.
+func (c *Client) RestoreDB(name string) (uint64, error) {
+	const (
+		method = "admin_restoreDB"
+	)
+
+	req := &rpc.RestoreDBRequest{
+		Name: name,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.Error != nil {
+		return 0, resp.Error
+	}
+
+	var res *rpc.RestoreDBResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return 0, err
+	}
+
+	return res.SchemaVersion, nil
+}
+
+// BanPeer calls admin_banPeer. It requires the admin token.
+func (c *Client) BanPeer(req *rpc.BanPeerRequest) error {
+	const (
+		method = "admin_banPeer"
+	)
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
+
+// UnbanPeer calls admin_unbanPeer. It requires the admin token.
+func (c *Client) UnbanPeer(req *rpc.UnbanPeerRequest) error {
+	const (
+		method = "admin_unbanPeer"
+	)
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
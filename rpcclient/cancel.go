@@ -3,7 +3,6 @@ package rpcclient
 import (
 	"encoding/json"
 
-	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/rpc"
 )
@@ -23,7 +22,7 @@ func (c *Client) Cancel(id string) (types.Status, error) {
 		return 0, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,34 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
+// ClearOffers calls net_clearOffers.
+func (c *Client) ClearOffers(offerIDs []string) error {
+	const (
+		method = "net_clearOffers"
+	)
+
+	req := &rpctypes.ClearOffersRequest{
+		OfferIDs: offerIDs,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
@@ -1,8 +1,17 @@
 package rpcclient
 
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
 // Client represents a swap RPC client, used to interact with a swap daemon via JSON-RPC calls.
 type Client struct {
-	endpoint string
+	endpoint   string
+	httpClient *http.Client
+	adminToken string
 }
 
 // NewClient ...
@@ -11,3 +20,32 @@ func NewClient(endpoint string) *Client {
 		endpoint: endpoint,
 	}
 }
+
+// NewClientWithTLS returns a client that connects to an HTTPS endpoint with the given TLS config,
+// eg. one from TLSConfig that pins the daemon's certificate authority. If the config is nil, it's
+// the same as NewClient.
+func NewClientWithTLS(endpoint string, tlsConfig *tls.Config) *Client {
+	c := NewClient(endpoint)
+	if tlsConfig != nil {
+		c.httpClient = rpctypes.NewHTTPClient(tlsConfig)
+	}
+	return c
+}
+
+// SetAdminToken sets the admin token sent with calls, which the methods that reveal swap secrets
+// or touch the daemon's database files require. swapd writes it to the rpc-admin-token file in
+// its basepath.
+func (c *Client) SetAdminToken(token string) {
+	c.adminToken = token
+	if c.httpClient == nil {
+		c.httpClient = rpctypes.NewHTTPClient(nil)
+	}
+}
+
+func (c *Client) post(method, params string) (*rpctypes.Response, error) {
+	if c.httpClient == nil {
+		return rpctypes.PostRPC(c.endpoint, method, params)
+	}
+
+	return rpctypes.PostRPCWithToken(c.httpClient, c.endpoint, c.adminToken, method, params)
+}
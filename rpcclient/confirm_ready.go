@@ -0,0 +1,34 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/rpc"
+)
+
+// ConfirmReady calls swap_confirmReady.
+func (c *Client) ConfirmReady(id string) error {
+	const (
+		method = "swap_confirmReady"
+	)
+
+	req := &rpc.ConfirmReadyRequest{
+		OfferID: id,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// DaemonVersion calls daemon_version.
+func (c *Client) DaemonVersion() (*types.VersionInfo, error) {
+	const (
+		method = "daemon_version"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *types.VersionInfo
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
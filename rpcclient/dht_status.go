@@ -0,0 +1,30 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
+// GetDHTStatus calls net_getDHTStatus.
+func (c *Client) GetDHTStatus() (*rpctypes.GetDHTStatusResponse, error) {
+	const (
+		method = "net_getDHTStatus"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpctypes.GetDHTStatusResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -23,7 +23,7 @@ func (c *Client) Discover(provides types.ProvidesCoin, searchTime uint64) ([][]s
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,35 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/rpc"
+)
+
+// ExportHistory calls swap_exportHistory.
+func (c *Client) ExportHistory(req *rpc.ExportHistoryRequest) (string, error) {
+	const (
+		method = "swap_exportHistory"
+	)
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	var res *rpc.ExportHistoryResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return "", err
+	}
+
+	return res.Data, nil
+}
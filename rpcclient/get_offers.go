@@ -3,7 +3,6 @@ package rpcclient
 import (
 	"encoding/json"
 
-	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/common/types"
 	"github.com/noot/atomic-swap/rpc"
 )
@@ -14,7 +13,7 @@ func (c *Client) GetOffers() ([]*types.Offer, error) {
 		method = "swap_getOffers"
 	)
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,42 @@
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
+// GetQuote calls net_getQuote.
+func (c *Client) GetQuote(maddr, offerID string, providesAmount float64) (*rpctypes.GetQuoteResponse, error) {
+	const (
+		method = "net_getQuote"
+	)
+
+	req := &rpctypes.GetQuoteRequest{
+		Multiaddr:      maddr,
+		OfferID:        offerID,
+		ProvidesAmount: providesAmount,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpctypes.GetQuoteResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
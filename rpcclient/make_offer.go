@@ -9,7 +9,9 @@ import (
 )
 
 // MakeOffer calls net_makeOffer.
-func (c *Client) MakeOffer(min, max, exchangeRate float64) (string, error) {
+func (c *Client) MakeOffer(min, max, exchangeRate float64, speedTiers []*types.SpeedTier,
+	relist *types.RelistPolicy, peg *types.RatePeg, takers *types.TakerFilter,
+	terms *rpctypes.OfferTerms) (string, error) {
 	const (
 		method = "net_makeOffer"
 	)
@@ -18,6 +20,11 @@ func (c *Client) MakeOffer(min, max, exchangeRate float64) (string, error) {
 		MinimumAmount: min,
 		MaximumAmount: max,
 		ExchangeRate:  types.ExchangeRate(exchangeRate),
+		SpeedTiers:    speedTiers,
+		Relist:        relist,
+		Peg:           peg,
+		Takers:        takers,
+		OfferTerms:    terms,
 	}
 
 	params, err := json.Marshal(req)
@@ -25,7 +32,7 @@ func (c *Client) MakeOffer(min, max, exchangeRate float64) (string, error) {
 		return "", err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,47 @@
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// MakePrivateOffer calls net_makePrivateOffer.
+func (c *Client) MakePrivateOffer(min, max, exchangeRate float64, speedTiers []*types.SpeedTier,
+	peg *types.RatePeg, terms *rpctypes.OfferTerms) (*rpctypes.MakePrivateOfferResponse, error) {
+	const (
+		method = "net_makePrivateOffer"
+	)
+
+	req := &rpctypes.MakePrivateOfferRequest{
+		MinimumAmount: min,
+		MaximumAmount: max,
+		ExchangeRate:  types.ExchangeRate(exchangeRate),
+		SpeedTiers:    speedTiers,
+		Peg:           peg,
+		OfferTerms:    terms,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpctypes.MakePrivateOfferResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
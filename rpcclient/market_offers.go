@@ -0,0 +1,30 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
+// GetMarketOffers calls net_getMarketOffers.
+func (c *Client) GetMarketOffers() ([]*rpctypes.PeerOffers, error) {
+	const (
+		method = "net_getMarketOffers"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpctypes.GetMarketOffersResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Peers, nil
+}
@@ -0,0 +1,30 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
+// Orderbook calls net_orderbook.
+func (c *Client) Orderbook() (*rpctypes.OrderbookResponse, error) {
+	const (
+		method = "net_orderbook"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpctypes.OrderbookResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
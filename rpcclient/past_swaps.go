@@ -0,0 +1,35 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/rpc"
+)
+
+// GetPastSwaps calls swap_getPastSwaps.
+func (c *Client) GetPastSwaps(req *rpc.GetPastSwapsRequest) (*rpc.GetPastSwapsResponse, error) {
+	const (
+		method = "swap_getPastSwaps"
+	)
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpc.GetPastSwapsResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,30 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+)
+
+// GetPeerScores calls net_getPeerScores.
+func (c *Client) GetPeerScores() ([]*rpctypes.PeerScore, error) {
+	const (
+		method = "net_getPeerScores"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpctypes.GetPeerScoresResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Peers, nil
+}
@@ -0,0 +1,58 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	recovery "github.com/noot/atomic-swap/recover"
+	"github.com/noot/atomic-swap/rpc"
+)
+
+// GetPendingRecovery calls swap_getPendingRecovery.
+func (c *Client) GetPendingRecovery() ([]*recovery.PendingSwap, error) {
+	const (
+		method = "swap_getPendingRecovery"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpc.GetPendingRecoveryResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Swaps, nil
+}
+
+// ResolvePendingRecovery calls swap_resolvePendingRecovery.
+func (c *Client) ResolvePendingRecovery(id string) error {
+	const (
+		method = "swap_resolvePendingRecovery"
+	)
+
+	req := &rpc.ResolvePendingRecoveryRequest{
+		OfferID: id,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
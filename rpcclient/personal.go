@@ -3,7 +3,9 @@ package rpcclient
 import (
 	"encoding/json"
 
-	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/preflight"
+	"github.com/noot/atomic-swap/protocol/txsender"
 	"github.com/noot/atomic-swap/rpc"
 )
 
@@ -22,7 +24,7 @@ func (c *Client) SetSwapTimeout(duration uint64) error {
 		return err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return err
 	}
@@ -33,3 +35,113 @@ func (c *Client) SetSwapTimeout(duration uint64) error {
 
 	return nil
 }
+
+// SetGasConfig calls personal_setGasConfig.
+func (c *Client) SetGasConfig(cfg *txsender.GasConfig) error {
+	const (
+		method = "personal_setGasConfig"
+	)
+
+	req := &rpc.SetGasConfigRequest{
+		GasConfig: *cfg,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
+
+// SetMoneroFeePriority calls personal_setMoneroFeePriority.
+func (c *Client) SetMoneroFeePriority(priority string) error {
+	const (
+		method = "personal_setMoneroFeePriority"
+	)
+
+	req := &rpc.SetMoneroFeePriorityRequest{
+		Priority: priority,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	return nil
+}
+
+// GetCapitalUtilization calls personal_getCapitalUtilization.
+func (c *Client) GetCapitalUtilization() (*rpc.GetCapitalUtilizationResponse, error) {
+	const (
+		method = "personal_getCapitalUtilization"
+	)
+
+	resp, err := c.post(method, "{}")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpc.GetCapitalUtilizationResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Preflight calls personal_preflight.
+func (c *Client) Preflight(provides types.ProvidesCoin, amount float64) (*preflight.Report, error) {
+	const (
+		method = "personal_preflight"
+	)
+
+	req := &rpc.PreflightRequest{
+		Provides: provides,
+		Amount:   amount,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *preflight.Report
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -21,7 +21,7 @@ func (c *Client) Query(maddr string) (*rpctypes.QueryPeerResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
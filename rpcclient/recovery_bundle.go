@@ -0,0 +1,40 @@
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/noot/atomic-swap/rpc"
+)
+
+// ExportRecoveryBundle calls swap_exportRecoveryBundle.
+func (c *Client) ExportRecoveryBundle(id, passphrase string) ([]byte, error) {
+	const (
+		method = "swap_exportRecoveryBundle"
+	)
+
+	req := &rpc.ExportRecoveryBundleRequest{
+		OfferID:    id,
+		Passphrase: passphrase,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	var res *rpc.ExportRecoveryBundleResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Bundle, nil
+}
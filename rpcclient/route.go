@@ -0,0 +1,49 @@
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// TakeRoute calls net_takeRoute. maxExchangeRate is optional.
+func (c *Client) TakeRoute(providesAmount float64, searchTime uint64,
+	maxExchangeRate types.ExchangeRate) (*rpctypes.RouteResponse, error) {
+	req := &rpctypes.TakeRouteRequest{
+		ProvidesAmount:  providesAmount,
+		SearchTime:      searchTime,
+		MaxExchangeRate: maxExchangeRate,
+	}
+
+	return c.callRoute("net_takeRoute", req)
+}
+
+// GetRoute calls net_getRoute.
+func (c *Client) GetRoute(id types.Hash) (*rpctypes.RouteResponse, error) {
+	return c.callRoute("net_getRoute", &rpctypes.GetRouteRequest{ID: id})
+}
+
+func (c *Client) callRoute(method string, req interface{}) (*rpctypes.RouteResponse, error) {
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpctypes.RouteResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
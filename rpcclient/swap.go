@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 
-	"github.com/noot/atomic-swap/common/rpctypes"
 	"github.com/noot/atomic-swap/rpc"
 )
 
@@ -14,7 +13,7 @@ func (c *Client) GetPastSwapIDs() ([]string, error) {
 		method = "swap_getPastIDs"
 	)
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, "{}")
+	resp, err := c.post(method, "{}")
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +45,7 @@ func (c *Client) GetOngoingSwap(id string) (*rpc.GetOngoingResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +77,7 @@ func (c *Client) GetPastSwap(id string) (*rpc.GetPastResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -110,7 +109,7 @@ func (c *Client) Refund(id string) (*rpc.RefundResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -127,6 +126,70 @@ func (c *Client) Refund(id string) (*rpc.RefundResponse, error) {
 	return res, nil
 }
 
+// Claim calls swap_claim
+func (c *Client) Claim(id string) (*rpc.ClaimResponse, error) {
+	const (
+		method = "swap_claim"
+	)
+
+	req := &rpc.ClaimRequest{
+		OfferID: id,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpc.ClaimResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// GetStatuses calls swap_getStatuses
+func (c *Client) GetStatuses(ids []string) ([]*rpc.SwapStatus, error) {
+	const (
+		method = "swap_getStatuses"
+	)
+
+	req := &rpc.GetStatusesRequest{
+		OfferIDs: ids,
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpc.GetStatusesResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Statuses, nil
+}
+
 // GetStage calls swap_getStage
 func (c *Client) GetStage(id string) (*rpc.GetStageResponse, error) {
 	const (
@@ -142,7 +205,7 @@ func (c *Client) GetStage(id string) (*rpc.GetStageResponse, error) {
 		return nil, err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,48 @@
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// TakeBestOffer calls net_takeBestOffer. limits is optional.
+func (c *Client) TakeBestOffer(providesAmount float64, searchTime uint64, speedTier string,
+	limits *types.SlippageLimits) (*rpctypes.TakeBestOfferResponse, error) {
+	const (
+		method = "net_takeBestOffer"
+	)
+
+	req := &rpctypes.TakeBestOfferRequest{
+		ProvidesAmount: providesAmount,
+		SearchTime:     searchTime,
+		SpeedTier:      speedTier,
+	}
+	if limits != nil {
+		req.MaxExchangeRate = limits.MaxExchangeRate
+		req.MinReceivedXMR = limits.MinReceivedXMR
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpctypes.TakeBestOfferResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -5,10 +5,12 @@ import (
 	"fmt"
 
 	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
 )
 
-// TakeOffer calls net_takeOffer.
-func (c *Client) TakeOffer(maddr string, offerID string, providesAmount float64) error {
+// TakeOffer calls net_takeOffer. quoteID and limits are optional.
+func (c *Client) TakeOffer(maddr string, offerID string, providesAmount float64, speedTier, quoteID string,
+	limits *types.SlippageLimits) error {
 	const (
 		method = "net_takeOffer"
 	)
@@ -17,6 +19,12 @@ func (c *Client) TakeOffer(maddr string, offerID string, providesAmount float64)
 		Multiaddr:      maddr,
 		OfferID:        offerID,
 		ProvidesAmount: providesAmount,
+		SpeedTier:      speedTier,
+		QuoteID:        quoteID,
+	}
+	if limits != nil {
+		req.MaxExchangeRate = limits.MaxExchangeRate
+		req.MinReceivedXMR = limits.MinReceivedXMR
 	}
 
 	params, err := json.Marshal(req)
@@ -24,7 +32,7 @@ func (c *Client) TakeOffer(maddr string, offerID string, providesAmount float64)
 		return err
 	}
 
-	resp, err := rpctypes.PostRPC(c.endpoint, method, string(params))
+	resp, err := c.post(method, string(params))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,50 @@
+package rpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/noot/atomic-swap/common/rpctypes"
+	"github.com/noot/atomic-swap/common/types"
+)
+
+// TakePrivateOffer calls net_takePrivateOffer, and returns the ID of the offer taken. limits is
+// optional.
+func (c *Client) TakePrivateOffer(maddr, token string, providesAmount float64, speedTier string,
+	limits *types.SlippageLimits) (string, error) {
+	const (
+		method = "net_takePrivateOffer"
+	)
+
+	req := &rpctypes.TakePrivateOfferRequest{
+		Multiaddr:      maddr,
+		Token:          token,
+		ProvidesAmount: providesAmount,
+		SpeedTier:      speedTier,
+	}
+	if limits != nil {
+		req.MaxExchangeRate = limits.MaxExchangeRate
+		req.MinReceivedXMR = limits.MinReceivedXMR
+	}
+
+	params, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.post(method, string(params))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Error != nil {
+		return "", fmt.Errorf("failed to call %s: %w", method, resp.Error)
+	}
+
+	var res *rpctypes.TakePrivateOfferResponse
+	if err = json.Unmarshal(resp.Result, &res); err != nil {
+		return "", err
+	}
+
+	return res.OfferID, nil
+}
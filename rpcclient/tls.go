@@ -0,0 +1,30 @@
+package rpcclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+var errNoCACertificates = errors.New("no certificates found in CA file")
+
+// TLSConfig returns a TLS config that only trusts certificates issued by the certificate authority
+// in the given PEM file, such as the CA that signed swapd's RPC certificate, rather than the
+// system's certificate authorities.
+func TLSConfig(caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errNoCACertificates
+	}
+
+	return &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
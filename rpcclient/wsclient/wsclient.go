@@ -2,6 +2,7 @@ package wsclient
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -20,13 +21,18 @@ type WsClient interface {
 	Close()
 	Discover(provides types.ProvidesCoin, searchTime uint64) ([][]string, error)
 	Query(maddr string) (*rpctypes.QueryPeerResponse, error)
-	SubscribeSwapStatus(id uint64) (<-chan types.Status, error)
-	TakeOfferAndSubscribe(multiaddr, offerID string,
-		providesAmount float64) (id uint64, ch <-chan types.Status, err error)
-	MakeOfferAndSubscribe(min, max float64,
-		exchangeRate types.ExchangeRate) (string, <-chan types.Status, error)
+	SubscribeSwapStatus(id types.Hash) (<-chan types.Status, error)
+	SubscribeEvents() (<-chan *rpctypes.SubscribeEventsResponse, error)
+	SubscribeOffers() (<-chan *rpctypes.SubscribeOffersResponse, error)
+	TakeOfferAndSubscribe(multiaddr, offerID string, providesAmount float64, speedTier, quoteID string,
+		limits *types.SlippageLimits) (ch <-chan types.Status, err error)
+	MakeOfferAndSubscribe(min, max float64, exchangeRate types.ExchangeRate,
+		speedTiers []*types.SpeedTier, relist *types.RelistPolicy, peg *types.RatePeg,
+		takers *types.TakerFilter, terms *rpctypes.OfferTerms) (string, <-chan types.Status, error)
 }
 
+var _ WsClient = (*wsClient)(nil)
+
 type wsClient struct {
 	wmu  sync.Mutex
 	rmu  sync.Mutex
@@ -35,7 +41,21 @@ type wsClient struct {
 
 // NewWsClient ...
 func NewWsClient(ctx context.Context, endpoint string) (*wsClient, error) { ///nolint:revive
-	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	return NewWsClientWithTLS(ctx, endpoint, nil)
+}
+
+// NewWsClientWithTLS returns a client that connects to a WSS endpoint with the given TLS config,
+// eg. one from rpcclient.TLSConfig that pins the daemon's certificate authority. If the config is
+// nil, it's the same as NewWsClient.
+func NewWsClientWithTLS(ctx context.Context, endpoint string, tlsConfig *tls.Config) (*wsClient, error) { ///nolint:revive
+	dialer := websocket.DefaultDialer
+	if tlsConfig != nil {
+		withTLS := *websocket.DefaultDialer
+		withTLS.TLSClientConfig = tlsConfig
+		dialer = &withTLS
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial endpoint: %w", err)
 	}
@@ -227,12 +247,122 @@ func (c *wsClient) SubscribeSwapStatus(id types.Hash) (<-chan types.Status, erro
 	return respCh, nil
 }
 
-func (c *wsClient) TakeOfferAndSubscribe(multiaddr, offerID string,
-	providesAmount float64) (ch <-chan types.Status, err error) {
+// SubscribeEvents subscribes to every swap and offer event. The returned channel is closed when
+// the connection is.
+func (c *wsClient) SubscribeEvents() (<-chan *rpctypes.SubscribeEventsResponse, error) {
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  "swap_subscribeEvents",
+		Params:  []byte("{}"),
+		ID:      0,
+	}
+
+	if err := c.writeJSON(req); err != nil {
+		return nil, err
+	}
+
+	respCh := make(chan *rpctypes.SubscribeEventsResponse)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Warnf("failed to read websockets message: %s", err)
+				break
+			}
+
+			var resp *rpctypes.Response
+			err = json.Unmarshal(message, &resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				break
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			var event *rpctypes.SubscribeEventsResponse
+			if err := json.Unmarshal(resp.Result, &event); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			respCh <- event
+		}
+	}()
+
+	return respCh, nil
+}
+
+// SubscribeOffers subscribes to the offers the daemon learns about from other peers. The returned
+// channel is closed when the connection is.
+func (c *wsClient) SubscribeOffers() (<-chan *rpctypes.SubscribeOffersResponse, error) {
+	req := &rpctypes.Request{
+		JSONRPC: rpctypes.DefaultJSONRPCVersion,
+		Method:  "net_subscribeOffers",
+		Params:  []byte("{}"),
+		ID:      0,
+	}
+
+	if err := c.writeJSON(req); err != nil {
+		return nil, err
+	}
+
+	respCh := make(chan *rpctypes.SubscribeOffersResponse)
+
+	go func() {
+		defer close(respCh)
+
+		for {
+			message, err := c.read()
+			if err != nil {
+				log.Warnf("failed to read websockets message: %s", err)
+				break
+			}
+
+			var resp *rpctypes.Response
+			err = json.Unmarshal(message, &resp)
+			if err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			if resp.Error != nil {
+				log.Warnf("websocket server returned error: %s", resp.Error)
+				break
+			}
+
+			log.Debugf("received message over websockets: %s", message)
+			var offer *rpctypes.SubscribeOffersResponse
+			if err := json.Unmarshal(resp.Result, &offer); err != nil {
+				log.Warnf("failed to unmarshal response: %s", err)
+				break
+			}
+
+			respCh <- offer
+		}
+	}()
+
+	return respCh, nil
+}
+
+func (c *wsClient) TakeOfferAndSubscribe(multiaddr, offerID string, providesAmount float64, speedTier, quoteID string,
+	limits *types.SlippageLimits) (ch <-chan types.Status, err error) {
 	params := &rpctypes.TakeOfferRequest{
 		Multiaddr:      multiaddr,
 		OfferID:        offerID,
 		ProvidesAmount: providesAmount,
+		SpeedTier:      speedTier,
+		QuoteID:        quoteID,
+	}
+	if limits != nil {
+		params.MaxExchangeRate = limits.MaxExchangeRate
+		params.MinReceivedXMR = limits.MinReceivedXMR
 	}
 
 	bz, err := json.Marshal(params)
@@ -315,12 +445,18 @@ func (c *wsClient) TakeOfferAndSubscribe(multiaddr, offerID string,
 	return respCh, nil
 }
 
-func (c *wsClient) MakeOfferAndSubscribe(min, max float64,
-	exchangeRate types.ExchangeRate) (string, <-chan types.Status, error) {
+func (c *wsClient) MakeOfferAndSubscribe(min, max float64, exchangeRate types.ExchangeRate,
+	speedTiers []*types.SpeedTier, relist *types.RelistPolicy, peg *types.RatePeg,
+	takers *types.TakerFilter, terms *rpctypes.OfferTerms) (string, <-chan types.Status, error) {
 	params := &rpctypes.MakeOfferRequest{
 		MinimumAmount: min,
 		MaximumAmount: max,
 		ExchangeRate:  exchangeRate,
+		SpeedTiers:    speedTiers,
+		Relist:        relist,
+		Peg:           peg,
+		Takers:        takers,
+		OfferTerms:    terms,
 	}
 
 	bz, err := json.Marshal(params)
@@ -31,6 +31,14 @@ fi
 mv swaprecover ../..
 echo "done building swaprecover."
 
+echo "building swapwatchtower..."
+cd ../watchtower
+if ! go build -o swapwatchtower-amd64-darwin ; then 
+	exit 1
+fi
+mv swapwatchtower ../..
+echo "done building swapwatchtower."
+
 echo "building swaptester..."
 cd ../tester
 if ! go build -o swaptester-amd64-darwin ; then 
@@ -1,8 +1,10 @@
 #!/bin/bash
 
+LDFLAGS="-X github.com/noot/atomic-swap/common.GitCommit=$(git rev-parse --short HEAD 2>/dev/null || echo unknown)"
+
 echo "building swapd..."
 cd cmd/daemon 
-if ! go build -o swapd ; then
+if ! go build -ldflags "${LDFLAGS}" -o swapd ; then
 	exit 1
 fi
 mv swapd ../..
@@ -28,6 +30,14 @@ fi
 mv swaprecover ../..
 echo "done building swaprecover."
 
+echo "building swapwatchtower..."
+cd ../watchtower
+if ! go build -o swapwatchtower ; then 
+	exit 1
+fi
+mv swapwatchtower ../..
+echo "done building swapwatchtower."
+
 echo "building swaptester..."
 cd ../tester
 if ! go build -o swaptester ; then 
@@ -0,0 +1,6 @@
+#!/bin/bash
+
+# this requires protoc, protoc-gen-go v1.27.1 and protoc-gen-go-grpc v1.1.0
+protoc --go_out=. --go_opt=paths=source_relative \
+	--go-grpc_out=. --go-grpc_opt=paths=source_relative \
+	rpc/pb/swapd.proto
@@ -0,0 +1,212 @@
+package swapfactory
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const swapIDTestIterations = 50
+
+// newTestRand returns a seeded source of randomness, logging the seed so failures can be reproduced.
+func newTestRand(t *testing.T) *rand.Rand {
+	seed := time.Now().UnixNano()
+	t.Logf("seed: %d", seed)
+	return rand.New(rand.NewSource(seed)) //nolint:gosec
+}
+
+func randomBytes32(r *rand.Rand) [32]byte {
+	var b [32]byte
+	_, _ = r.Read(b[:])
+	return b
+}
+
+func randomAddress(r *rand.Rand) ethcommon.Address {
+	var a ethcommon.Address
+	_, _ = r.Read(a[:])
+	return a
+}
+
+// randomUint returns a random integer with up to the given number of bits.
+func randomUint(r *rand.Rand, bits int) *big.Int {
+	b := make([]byte, bits/8)
+	_, _ = r.Read(b)
+	return new(big.Int).SetBytes(b)
+}
+
+func randomSwap(r *rand.Rand) SwapFactorySwap {
+	return SwapFactorySwap{
+		Owner:        randomAddress(r),
+		Claimer:      randomAddress(r),
+		PubKeyClaim:  randomBytes32(r),
+		PubKeyRefund: randomBytes32(r),
+		Timeout0:     randomUint(r, 256),
+		Timeout1:     randomUint(r, 256),
+		Value:        randomUint(r, 256),
+		Nonce:        randomUint(r, 256),
+	}
+}
+
+// TestSwapID_MatchesBindings checks that SwapID encodes swaps the same way the generated bindings
+// encode the Swap struct passed to the contract's methods.
+func TestSwapID_MatchesBindings(t *testing.T) {
+	swapABI, err := SwapFactoryMetaData.GetAbi()
+	require.NoError(t, err)
+
+	// set_ready(Swap) takes the struct as its only argument
+	args := abi.Arguments{{Type: swapABI.Methods["set_ready"].Inputs[0].Type}}
+
+	r := newTestRand(t)
+	for i := 0; i < swapIDTestIterations; i++ {
+		swap := randomSwap(r)
+
+		encoded, err := args.Pack(swap)
+		require.NoError(t, err)
+
+		id, err := SwapID(swap)
+		require.NoError(t, err)
+		require.Equal(t, crypto.Keccak256Hash(encoded), ethcommon.Hash(id), "swap %+v", swap)
+	}
+}
+
+// TestSwapID_MatchesContract creates random swaps on a simulated chain and checks that the ID
+// computed in Go matches the ID the contract emits, and that the contract finds the swap when
+// given the Go struct.
+func TestSwapID_MatchesContract(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(pk.PublicKey)
+
+	balance := new(big.Int).Lsh(big.NewInt(1), 128)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{owner: {Balance: balance}}, 30_000_000)
+	defer sim.Close()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(pk, big.NewInt(1337))
+	require.NoError(t, err)
+
+	_, _, contract, err := DeploySwapFactory(auth, sim)
+	require.NoError(t, err)
+	sim.Commit()
+
+	ctx := context.Background()
+	r := newTestRand(t)
+	for i := 0; i < swapIDTestIterations; i++ {
+		claimer := randomAddress(r)
+		pubKeyClaim := randomBytes32(r)
+		pubKeyRefund := randomBytes32(r)
+		nonce := randomUint(r, 256)
+		// keep timeout_1 = block.timestamp + 2 * duration from overflowing
+		duration := randomUint(r, 64)
+		value := randomUint(r, 64)
+
+		auth.Value = value
+		tx, err := contract.NewSwap(auth, pubKeyClaim, pubKeyRefund, claimer, duration, nonce)
+		auth.Value = nil
+		require.NoError(t, err)
+		sim.Commit()
+
+		receipt, err := sim.TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, ethtypes.ReceiptStatusSuccessful, receipt.Status)
+		require.Len(t, receipt.Logs, 1)
+
+		contractID, err := GetIDFromLog(receipt.Logs[0])
+		require.NoError(t, err)
+
+		block, err := sim.BlockByHash(ctx, receipt.BlockHash)
+		require.NoError(t, err)
+		timestamp := new(big.Int).SetUint64(block.Time())
+
+		swap := SwapFactorySwap{
+			Owner:        owner,
+			Claimer:      claimer,
+			PubKeyClaim:  pubKeyClaim,
+			PubKeyRefund: pubKeyRefund,
+			Timeout0:     new(big.Int).Add(timestamp, duration),
+			Timeout1:     new(big.Int).Add(timestamp, new(big.Int).Mul(duration, big.NewInt(2))),
+			Value:        value,
+			Nonce:        nonce,
+		}
+
+		id, err := SwapID(swap)
+		require.NoError(t, err)
+		require.Equal(t, contractID, id, "swap %+v", swap)
+
+		// set_ready hashes the struct it's given on-chain; it only succeeds if that hash is a
+		// pending swap, so this checks the bindings' encoding of the struct too
+		tx, err = contract.SetReady(auth, swap)
+		require.NoError(t, err)
+		sim.Commit()
+
+		receipt, err = sim.TransactionReceipt(ctx, tx.Hash())
+		require.NoError(t, err)
+		require.Equal(t, ethtypes.ReceiptStatusSuccessful, receipt.Status)
+
+		ready, err := contract.IsReady(&bind.CallOpts{Context: ctx}, id)
+		require.NoError(t, err)
+		require.True(t, ready)
+	}
+}
+
+// TestGetSwapFromNewSwapTx checks that the swap decoded from a new_swap transaction and its New log
+// has the ID the contract emitted.
+func TestGetSwapFromNewSwapTx(t *testing.T) {
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	owner := crypto.PubkeyToAddress(pk.PublicKey)
+
+	balance := new(big.Int).Lsh(big.NewInt(1), 128)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{owner: {Balance: balance}}, 30_000_000)
+	defer sim.Close()
+
+	auth, err := bind.NewKeyedTransactorWithChainID(pk, big.NewInt(1337))
+	require.NoError(t, err)
+
+	_, _, contract, err := DeploySwapFactory(auth, sim)
+	require.NoError(t, err)
+	sim.Commit()
+
+	ctx := context.Background()
+	r := newTestRand(t)
+	auth.Value = randomUint(r, 64)
+	tx, err := contract.NewSwap(auth, randomBytes32(r), randomBytes32(r), randomAddress(r), big.NewInt(3600),
+		randomUint(r, 256))
+	auth.Value = nil
+	require.NoError(t, err)
+	sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Len(t, receipt.Logs, 1)
+
+	contractID, err := GetIDFromLog(receipt.Logs[0])
+	require.NoError(t, err)
+	t0, t1, err := GetTimeoutsFromLog(receipt.Logs[0])
+	require.NoError(t, err)
+
+	sent, _, err := sim.TransactionByHash(ctx, tx.Hash())
+	require.NoError(t, err)
+
+	swap, err := GetSwapFromNewSwapTx(sent, owner, t0, t1)
+	require.NoError(t, err)
+	id, err := SwapID(swap)
+	require.NoError(t, err)
+	require.Equal(t, contractID, id)
+
+	// a transaction that isn't a new_swap call can't be decoded
+	tx, err = contract.SetReady(auth, swap)
+	require.NoError(t, err)
+	_, err = GetSwapFromNewSwapTx(tx, owner, t0, t1)
+	require.Error(t, err)
+}
@@ -7,7 +7,9 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/noot/atomic-swap/common"
 	mcrypto "github.com/noot/atomic-swap/crypto/monero"
@@ -20,6 +22,43 @@ const (
 	StageCompleted
 )
 
+// swapArguments are the ABI types of the Swap struct's fields, in order. A static struct is
+// ABI-encoded the same way as its fields.
+var swapArguments = func() abi.Arguments {
+	addressTy, _ := abi.NewType("address", "", nil)
+	bytes32Ty, _ := abi.NewType("bytes32", "", nil)
+	uint256Ty, _ := abi.NewType("uint256", "", nil)
+	return abi.Arguments{
+		{Type: addressTy}, // owner
+		{Type: addressTy}, // claimer
+		{Type: bytes32Ty}, // pubKeyClaim
+		{Type: bytes32Ty}, // pubKeyRefund
+		{Type: uint256Ty}, // timeout_0
+		{Type: uint256Ty}, // timeout_1
+		{Type: uint256Ty}, // value
+		{Type: uint256Ty}, // nonce
+	}
+}()
+
+// SwapID returns the ID the contract uses for the given swap, keccak256(abi.encode(swap)).
+func SwapID(swap SwapFactorySwap) ([32]byte, error) {
+	args, err := swapArguments.Pack(
+		swap.Owner,
+		swap.Claimer,
+		swap.PubKeyClaim,
+		swap.PubKeyRefund,
+		swap.Timeout0,
+		swap.Timeout1,
+		swap.Value,
+		swap.Nonce,
+	)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return crypto.Keccak256Hash(args), nil
+}
+
 // GetSecretFromLog returns the secret from a Claimed or Refunded log
 func GetSecretFromLog(log *ethtypes.Log, event string) (*mcrypto.PrivateSpendKey, error) {
 	if event != "Refunded" && event != "Claimed" {
@@ -130,3 +169,44 @@ func GetTimeoutsFromLog(log *ethtypes.Log) (*big.Int, *big.Int, error) {
 	return t0, t1, nil
 
 }
+
+// GetSwapFromNewSwapTx returns the swap created by the given new_swap transaction, which was sent by
+// the given owner and whose New event had the given timeouts. The swap's parameters aren't all in the
+// New event, so they're decoded from the transaction's calldata.
+func GetSwapFromNewSwapTx(tx *ethtypes.Transaction, owner ethcommon.Address, t0, t1 *big.Int) (SwapFactorySwap,
+	error) {
+	abi, err := abi.JSON(strings.NewReader(SwapFactoryABI))
+	if err != nil {
+		return SwapFactorySwap{}, err
+	}
+
+	data := tx.Data()
+	if len(data) < 4 {
+		return SwapFactorySwap{}, errors.New("transaction is not a call to new_swap")
+	}
+
+	method, err := abi.MethodById(data[:4])
+	if err != nil || method.RawName != "new_swap" {
+		return SwapFactorySwap{}, errors.New("transaction is not a call to new_swap")
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return SwapFactorySwap{}, err
+	}
+
+	if len(args) < 5 {
+		return SwapFactorySwap{}, errors.New("transaction didn't have enough parameters")
+	}
+
+	return SwapFactorySwap{
+		Owner:        owner,
+		Claimer:      args[2].(ethcommon.Address),
+		PubKeyClaim:  args[0].([32]byte),
+		PubKeyRefund: args[1].([32]byte),
+		Timeout0:     t0,
+		Timeout1:     t1,
+		Value:        tx.Value(),
+		Nonce:        args[4].(*big.Int),
+	}, nil
+}
@@ -95,7 +95,7 @@ func generateBlocksAsync() {
 
 func TestXMRTaker_Discover(t *testing.T) {
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
-	_, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate)
+	_, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	c := rpcclient.NewClient(defaultXMRTakerDaemonEndpoint)
@@ -114,7 +114,7 @@ func TestXMRMaker_Discover(t *testing.T) {
 
 func TestXMRTaker_Query(t *testing.T) {
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
-	_, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate)
+	_, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	c := rpcclient.NewClient(defaultXMRTakerDaemonEndpoint)
@@ -142,7 +142,7 @@ func TestSuccess(t *testing.T) {
 	require.NoError(t, err)
 
 	offerID, statusCh, err := bwsc.MakeOfferAndSubscribe(0.1, xmrmakerProvideAmount,
-		types.ExchangeRate(exchangeRate))
+		types.ExchangeRate(exchangeRate), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
@@ -186,7 +186,7 @@ func TestSuccess(t *testing.T) {
 	require.Equal(t, 1, len(providers))
 	require.GreaterOrEqual(t, len(providers[0]), 2)
 
-	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 	require.NoError(t, err)
 
 	go func() {
@@ -235,7 +235,7 @@ func TestRefund_XMRTakerCancels(t *testing.T) {
 	require.NoError(t, err)
 
 	offerID, statusCh, err := bwsc.MakeOfferAndSubscribe(0.1, xmrmakerProvideAmount,
-		types.ExchangeRate(exchangeRate))
+		types.ExchangeRate(exchangeRate), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
@@ -281,7 +281,7 @@ func TestRefund_XMRTakerCancels(t *testing.T) {
 	require.Equal(t, 1, len(providers))
 	require.GreaterOrEqual(t, len(providers[0]), 2)
 
-	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 	require.NoError(t, err)
 
 	go func() {
@@ -352,7 +352,7 @@ func testRefundXMRMakerCancels(t *testing.T, swapTimeout uint64, expectedExitSta
 	require.NoError(t, err)
 
 	offerID, statusCh, err := bwsc.MakeOfferAndSubscribe(0.1, xmrmakerProvideAmount,
-		types.ExchangeRate(exchangeRate))
+		types.ExchangeRate(exchangeRate), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	offersBefore, err := bcli.GetOffers()
@@ -406,7 +406,7 @@ func testRefundXMRMakerCancels(t *testing.T, swapTimeout uint64, expectedExitSta
 	require.Equal(t, 1, len(providers))
 	require.GreaterOrEqual(t, len(providers[0]), 2)
 
-	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 	require.NoError(t, err)
 
 	go func() {
@@ -461,7 +461,7 @@ func TestAbort_XMRTakerCancels(t *testing.T) {
 	require.NoError(t, err)
 
 	offerID, statusCh, err := bwsc.MakeOfferAndSubscribe(0.1, xmrmakerProvideAmount,
-		types.ExchangeRate(exchangeRate))
+		types.ExchangeRate(exchangeRate), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
@@ -504,7 +504,7 @@ func TestAbort_XMRTakerCancels(t *testing.T) {
 	require.Equal(t, 1, len(providers))
 	require.GreaterOrEqual(t, len(providers[0]), 2)
 
-	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 	require.NoError(t, err)
 
 	go func() {
@@ -562,7 +562,7 @@ func TestAbort_XMRMakerCancels(t *testing.T) {
 	require.NoError(t, err)
 
 	offerID, statusCh, err := bwsc.MakeOfferAndSubscribe(0.1, xmrmakerProvideAmount,
-		types.ExchangeRate(exchangeRate))
+		types.ExchangeRate(exchangeRate), nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
@@ -614,7 +614,7 @@ func TestAbort_XMRMakerCancels(t *testing.T) {
 	require.Equal(t, 1, len(providers))
 	require.GreaterOrEqual(t, len(providers[0]), 2)
 
-	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+	takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 	require.NoError(t, err)
 
 	go func() {
@@ -658,7 +658,7 @@ func TestError_ShouldOnlyTakeOfferOnce(t *testing.T) {
 	defer cancel()
 
 	bc := rpcclient.NewClient(defaultXMRMakerDaemonEndpoint)
-	offerID, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate)
+	offerID, err := bc.MakeOffer(xmrmakerProvideAmount, xmrmakerProvideAmount, exchangeRate, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	ac := rpcclient.NewClient(defaultXMRTakerDaemonEndpoint)
@@ -674,7 +674,7 @@ func TestError_ShouldOnlyTakeOfferOnce(t *testing.T) {
 		wsc, err := wsclient.NewWsClient(ctx, defaultXMRTakerDaemonWSEndpoint)
 		require.NoError(t, err)
 
-		takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+		takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 		if err != nil {
 			errCh <- err
 			return
@@ -700,7 +700,7 @@ func TestError_ShouldOnlyTakeOfferOnce(t *testing.T) {
 		wsc, err := wsclient.NewWsClient(ctx, defaultCharlieDaemonWSEndpoint)
 		require.NoError(t, err)
 
-		takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+		takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 		if err != nil {
 			errCh <- err
 			return
@@ -757,7 +757,7 @@ func TestSuccess_ConcurrentSwaps(t *testing.T) {
 		require.NoError(t, err)
 
 		offerID, statusCh, err := bwsc.MakeOfferAndSubscribe(0.1, xmrmakerProvideAmount,
-			types.ExchangeRate(exchangeRate))
+			types.ExchangeRate(exchangeRate), nil, nil, nil, nil, nil)
 		require.NoError(t, err)
 
 		fmt.Println("maker made offer ", offerID)
@@ -819,7 +819,7 @@ func TestSuccess_ConcurrentSwaps(t *testing.T) {
 		require.GreaterOrEqual(t, len(providers[0]), 2)
 
 		offerID := makerTests[i].offerID
-		takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05)
+		takerStatusCh, err := wsc.TakeOfferAndSubscribe(providers[0][0], offerID, 0.05, "", "", nil)
 		require.NoError(t, err)
 
 		fmt.Println("taker took offer ", offerID)
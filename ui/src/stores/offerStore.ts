@@ -33,7 +33,7 @@ export const getOffers = async (peerAddress: string) => {
 
             return result?.offers.map(off => ({
                 peer: peerAddress,
-                id: intToHexString(off.ID),
+                id: typeof off.ID === 'string' ? off.ID : intToHexString(off.ID),
                 exchangeRate: off.ExchangeRate,
                 maxAmount: off.MaximumAmount,
                 minAmount: off.MinimumAmount,
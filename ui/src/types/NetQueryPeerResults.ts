@@ -1,11 +1,20 @@
 export type Currency = 'ETH' | 'XMR'
 
+export interface SpeedTier {
+    Name: string
+    MoneroConfirmations: number
+    // contract timeout, in seconds
+    Timeout: number
+}
+
 export interface OfferRaw {
-    ID: number[]
+    // hex-encoded; older daemons encode the ID as an array of bytes
+    ID: string | number[]
     Provides: Currency
     MinimumAmount: number
     MaximumAmount: number
     ExchangeRate: number
+    SpeedTiers: SpeedTier[] | null
 }
 
 export interface NetQueryPeerResult {
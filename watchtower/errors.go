@@ -0,0 +1,13 @@
+package watchtower
+
+import (
+	"errors"
+)
+
+var (
+	errNoContractSwap = errors.New("swap has no contract swap to watch")
+	errInvalidAction  = errors.New("action must be one of claim or refund")
+	errNoSecretOrTx   = errors.New("registration must have a secret or a signed transaction")
+	errNoKey          = errors.New("no ethereum key for the swap's address")
+	errTxFailed       = errors.New("transaction failed")
+)
@@ -0,0 +1,168 @@
+package watchtower
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const registrationSuffix = ".json"
+
+// Action is the transaction the watchtower sends for a swap.
+type Action string
+
+// Actions the watchtower can take.
+const (
+	// ActionClaim claims the ether, for swaps where we provide XMR
+	ActionClaim Action = "claim"
+	// ActionRefund refunds the ether, for swaps where we provide ETH
+	ActionRefund Action = "refund"
+)
+
+// Registration is a swap registered with the watchtower.
+type Registration struct {
+	SwapID          types.Hash                  `json:"swapID"`
+	ContractAddress ethcommon.Address           `json:"contractAddress"`
+	ContractSwapID  ethcommon.Hash              `json:"contractSwapID"`
+	ContractSwap    swapfactory.SwapFactorySwap `json:"contractSwap"`
+	Action          Action                      `json:"action"`
+	// Secret is the secret passed to the contract's claim or refund function. It's used with the
+	// watchtower's ethereum key, and must be set unless SignedTx is.
+	Secret *ethcommon.Hash `json:"secret,omitempty"`
+	// EthereumKeyIndex is the HD wallet account index of the swap's ethereum address, if any
+	EthereumKeyIndex *uint32 `json:"ethereumKeyIndex,omitempty"`
+	// SignedTx is a claim or refund transaction signed in advance. If it's set, it's broadcast
+	// as-is, and the watchtower needs no key for the swap.
+	SignedTx hexutil.Bytes `json:"signedTx,omitempty"`
+}
+
+// NewRegistration returns the registration of the swap with the given info, which the watchtower
+// takes the given action for.
+func NewRegistration(id types.Hash, info *pcommon.InfoFileContents, action Action) (*Registration, error) {
+	if (info.ContractSwapID == [32]byte{}) || info.PrivateKeyInfo == nil {
+		return nil, errNoContractSwap
+	}
+
+	sk, err := hex.DecodeString(info.PrivateKeyInfo.PrivateSpendKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// the contract takes the secret in big-endian order
+	secret := ethcommon.BytesToHash(common.Reverse(sk))
+	return &Registration{
+		SwapID:           id,
+		ContractAddress:  ethcommon.HexToAddress(info.ContractAddress),
+		ContractSwapID:   info.ContractSwapID,
+		ContractSwap:     info.ContractSwap,
+		Action:           action,
+		Secret:           &secret,
+		EthereumKeyIndex: info.EthereumKeyIndex,
+	}, nil
+}
+
+func (r *Registration) validate() error {
+	switch r.Action {
+	case ActionClaim, ActionRefund:
+	default:
+		return errInvalidAction
+	}
+
+	if r.Secret == nil && len(r.SignedTx) == 0 {
+		return errNoSecretOrTx
+	}
+
+	if r.ContractSwap.Timeout0 == nil || r.ContractSwap.Timeout1 == nil {
+		return errNoContractSwap
+	}
+
+	return nil
+}
+
+func registrationPath(dir string, id types.Hash) string {
+	return filepath.Join(dir, id.String()+registrationSuffix)
+}
+
+// WriteRegistration writes the registration to the directory the watchtower reads them from,
+// replacing any previous registration of the swap.
+func WriteRegistration(dir string, r *Registration) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	bz, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that the watchtower can't read a partial registration
+	path := registrationPath(dir, r.SwapID)
+	tmp := path + ".tmp"
+	if err = os.WriteFile(filepath.Clean(tmp), bz, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// RemoveRegistration removes the swap's registration from the directory, if it's there.
+func RemoveRegistration(dir string, id types.Hash) error {
+	err := os.Remove(registrationPath(dir, id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// ReadRegistrations reads the registrations in the directory. Registrations that can't be read
+// are skipped with a warning, so that one bad file doesn't stop the others being watched.
+func ReadRegistrations(dir string) ([]*Registration, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var registrations []*Registration
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), registrationSuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		bz, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			log.Warnf("failed to read registration %s: %s", path, err)
+			continue
+		}
+
+		var r *Registration
+		if err = json.Unmarshal(bz, &r); err != nil {
+			log.Warnf("failed to decode registration %s: %s", path, err)
+			continue
+		}
+
+		if err = r.validate(); err != nil {
+			log.Warnf("invalid registration %s: %s", path, err)
+			continue
+		}
+
+		registrations = append(registrations, r)
+	}
+
+	return registrations, nil
+}
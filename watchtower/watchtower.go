@@ -0,0 +1,305 @@
+// Package watchtower implements a companion process to swapd that watches the chain for the swaps
+// registered with it, and claims or refunds them if swapd is down when they need it.
+package watchtower
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/crypto/hdwallet"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	logging "github.com/ipfs/go-log"
+)
+
+const (
+	defaultPollInterval = time.Second * 30
+	defaultMargin       = time.Minute * 10
+)
+
+var log = logging.Logger("watchtower")
+
+// Config contains the configuration of a Watchtower.
+type Config struct {
+	EthereumClient *ethclient.Client
+	ChainID        *big.Int
+	// PrivateKey and HDWallet are the keys transactions are signed with; the one whose address is
+	// the swap's claimer or owner is used. Either may be nil.
+	PrivateKey *ecdsa.PrivateKey
+	HDWallet   *hdwallet.Wallet
+	// Dir is the directory registrations are read from; it's reread every poll
+	Dir string
+	// PollInterval defaults to 30 seconds
+	PollInterval time.Duration
+	// Margin is how long before a deadline the watchtower acts; defaults to 10 minutes
+	Margin time.Duration
+	// DaemonUp returns whether swapd is running; if it's nil, swapd is assumed to be down
+	DaemonUp func() bool
+}
+
+// Watchtower claims or refunds registered swaps at the critical moments if swapd doesn't.
+type Watchtower struct {
+	ec           *ethclient.Client
+	chainID      *big.Int
+	privateKey   *ecdsa.PrivateKey
+	hdWallet     *hdwallet.Wallet
+	dir          string
+	pollInterval time.Duration
+	margin       time.Duration
+	daemonUp     func() bool
+
+	mu sync.Mutex
+	// swaps whose contract swaps are completed, which no longer need watching
+	done map[types.Hash]struct{}
+}
+
+// NewWatchtower returns a new *Watchtower.
+func NewWatchtower(cfg *Config) *Watchtower {
+	if cfg.PrivateKey == nil && cfg.HDWallet == nil {
+		log.Warn("no ethereum key loaded, only registrations with signed transactions can be acted on")
+	}
+
+	w := &Watchtower{
+		ec:           cfg.EthereumClient,
+		chainID:      cfg.ChainID,
+		privateKey:   cfg.PrivateKey,
+		hdWallet:     cfg.HDWallet,
+		dir:          cfg.Dir,
+		pollInterval: cfg.PollInterval,
+		margin:       cfg.Margin,
+		daemonUp:     cfg.DaemonUp,
+		done:         make(map[types.Hash]struct{}),
+	}
+
+	if w.pollInterval == 0 {
+		w.pollInterval = defaultPollInterval
+	}
+
+	if w.margin == 0 {
+		w.margin = defaultMargin
+	}
+
+	if w.daemonUp == nil {
+		w.daemonUp = func() bool { return false }
+	}
+
+	return w
+}
+
+// Run watches the registered swaps until the context is cancelled.
+func (w *Watchtower) Run(ctx context.Context) {
+	log.Infof("watching swaps registered in %s", w.dir)
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+func (w *Watchtower) poll(ctx context.Context) {
+	registrations, err := ReadRegistrations(w.dir)
+	if err != nil {
+		log.Errorf("failed to read registrations: %s", err)
+		return
+	}
+
+	header, err := w.ec.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Errorf("failed to get latest block: %s", err)
+		return
+	}
+
+	// the contract's timeouts are compared to block timestamps, not our clock
+	now := time.Unix(int64(header.Time), 0)
+
+	var daemonUp *bool
+	for _, r := range registrations {
+		w.mu.Lock()
+		_, done := w.done[r.SwapID]
+		w.mu.Unlock()
+		if done {
+			continue
+		}
+
+		stage, err := w.stage(ctx, r)
+		if err != nil {
+			log.Warnf("failed to get stage of swap %s: %s", r.SwapID, err)
+			continue
+		}
+
+		if stage == swapfactory.StageInvalid || stage == swapfactory.StageCompleted {
+			log.Infof("swap %s is completed, no longer watching it", r.SwapID)
+			w.mu.Lock()
+			w.done[r.SwapID] = struct{}{}
+			w.mu.Unlock()
+			continue
+		}
+
+		// swapd is only checked for if a swap reaches a critical moment, and only once per poll
+		if !shouldAct(r.Action, stage, r.ContractSwap, now, w.margin, false) {
+			continue
+		}
+
+		if daemonUp == nil {
+			up := w.daemonUp()
+			daemonUp = &up
+		}
+
+		if !shouldAct(r.Action, stage, r.ContractSwap, now, w.margin, *daemonUp) {
+			log.Debugf("swap %s needs a %s soon, leaving it to swapd", r.SwapID, r.Action)
+			continue
+		}
+
+		if err = w.act(ctx, r); err != nil {
+			log.Errorf("failed to %s swap %s: %s", r.Action, r.SwapID, err)
+		}
+	}
+}
+
+func (w *Watchtower) stage(ctx context.Context, r *Registration) (byte, error) {
+	contract, err := swapfactory.NewSwapFactory(r.ContractAddress, w.ec)
+	if err != nil {
+		return 0, err
+	}
+
+	return contract.Swaps(&bind.CallOpts{Context: ctx}, r.ContractSwapID)
+}
+
+// act sends the registration's claim or refund transaction, and waits for it to be included.
+func (w *Watchtower) act(ctx context.Context, r *Registration) error {
+	var (
+		tx  *ethtypes.Transaction
+		err error
+	)
+
+	if len(r.SignedTx) != 0 {
+		tx = new(ethtypes.Transaction)
+		if err = tx.UnmarshalBinary(r.SignedTx); err != nil {
+			return err
+		}
+
+		if err = w.ec.SendTransaction(ctx, tx); err != nil {
+			return err
+		}
+	} else {
+		tx, err = w.send(ctx, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("sent %s of swap %s: txHash=%s", r.Action, r.SwapID, tx.Hash())
+	receipt, err := bind.WaitMined(ctx, w.ec, tx)
+	if err != nil {
+		return err
+	}
+
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		return errTxFailed
+	}
+
+	log.Infof("%s of swap %s was included in block %s", r.Action, r.SwapID, receipt.BlockNumber)
+	return nil
+}
+
+func (w *Watchtower) send(ctx context.Context, r *Registration) (*ethtypes.Transaction, error) {
+	from := r.ContractSwap.Claimer
+	if r.Action == ActionRefund {
+		from = r.ContractSwap.Owner
+	}
+
+	key, err := w.keyFor(from, r.EthereumKeyIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(key, w.chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+
+	contract, err := swapfactory.NewSwapFactory(r.ContractAddress, w.ec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.Action {
+	case ActionClaim:
+		return contract.Claim(opts, r.ContractSwap, *r.Secret)
+	default:
+		return contract.Refund(opts, r.ContractSwap, *r.Secret)
+	}
+}
+
+// keyFor returns the key of the given address, which is either the base key or derived from the
+// HD wallet.
+func (w *Watchtower) keyFor(addr ethcommon.Address, index *uint32) (*ecdsa.PrivateKey, error) {
+	if w.privateKey != nil && common.EthereumPrivateKeyToAddress(w.privateKey) == addr {
+		return w.privateKey, nil
+	}
+
+	if w.hdWallet == nil {
+		return nil, errNoKey
+	}
+
+	// the HD wallet's first account is used as the base account
+	var i uint32
+	if index != nil {
+		i = *index
+	}
+
+	key, err := w.hdWallet.PrivateKey(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if common.EthereumPrivateKeyToAddress(key) != addr {
+		return nil, errNoKey
+	}
+
+	return key, nil
+}
+
+// shouldAct returns whether the watchtower should take the given action for a contract swap in the
+// given stage at the given time.
+func shouldAct(action Action, stage byte, swap swapfactory.SwapFactorySwap, now time.Time, margin time.Duration,
+	daemonUp bool) bool {
+	t0 := time.Unix(swap.Timeout0.Int64(), 0)
+	t1 := time.Unix(swap.Timeout1.Int64(), 0)
+
+	if stage != swapfactory.StagePending && stage != swapfactory.StageReady {
+		return false
+	}
+
+	switch action {
+	case ActionClaim:
+		// the ether must be claimed before t1, or the counterparty can refund it
+		canClaim := now.Before(t1) && (stage == swapfactory.StageReady || !now.Before(t0))
+		return canClaim && (!daemonUp || !now.Before(t1.Add(-margin)))
+	case ActionRefund:
+		// if the swap isn't ready by t0, the counterparty can claim the ether without having locked
+		// its monero, so it must be refunded before then
+		if stage == swapfactory.StagePending && now.Before(t0) && !now.Before(t0.Add(-margin)) {
+			return !daemonUp
+		}
+
+		// after t1 the ether can be refunded at any time; swapd is given a margin to do so
+		return !now.Before(t1) && (!daemonUp || !now.Before(t1.Add(margin)))
+	default:
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package watchtower
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common"
+	"github.com/noot/atomic-swap/common/types"
+	mcrypto "github.com/noot/atomic-swap/crypto/monero"
+	pcommon "github.com/noot/atomic-swap/protocol"
+	"github.com/noot/atomic-swap/swapfactory"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShouldAct(t *testing.T) {
+	now := time.Now()
+	margin := time.Minute * 10
+	swap := swapfactory.SwapFactorySwap{
+		Timeout0: big.NewInt(now.Add(time.Hour).Unix()),
+		Timeout1: big.NewInt(now.Add(time.Hour * 2).Unix()),
+	}
+
+	var (
+		early      = now
+		beforeT0   = now.Add(time.Hour - margin/2)
+		afterT0    = now.Add(time.Hour * 3 / 2)
+		beforeT1   = now.Add(time.Hour*2 - margin/2)
+		afterT1    = now.Add(time.Hour*2 + margin/2)
+		wellPastT1 = now.Add(time.Hour*2 + margin*2)
+	)
+
+	for _, tc := range []struct {
+		action   Action
+		stage    byte
+		now      time.Time
+		daemonUp bool
+		act      bool
+	}{
+		// claims are left to swapd until just before t1
+		{ActionClaim, swapfactory.StagePending, early, false, false},
+		{ActionClaim, swapfactory.StageReady, early, false, true},
+		{ActionClaim, swapfactory.StageReady, early, true, false},
+		{ActionClaim, swapfactory.StagePending, afterT0, false, true},
+		{ActionClaim, swapfactory.StagePending, afterT0, true, false},
+		{ActionClaim, swapfactory.StageReady, beforeT1, true, true},
+		{ActionClaim, swapfactory.StageReady, afterT1, false, false},
+		{ActionClaim, swapfactory.StageCompleted, beforeT1, false, false},
+		// refunds are made just before t0 if swapd is down, and after t1
+		{ActionRefund, swapfactory.StagePending, early, false, false},
+		{ActionRefund, swapfactory.StagePending, beforeT0, false, true},
+		{ActionRefund, swapfactory.StagePending, beforeT0, true, false},
+		{ActionRefund, swapfactory.StageReady, beforeT0, false, false},
+		{ActionRefund, swapfactory.StageReady, afterT0, false, false},
+		{ActionRefund, swapfactory.StageReady, afterT1, false, true},
+		{ActionRefund, swapfactory.StageReady, afterT1, true, false},
+		{ActionRefund, swapfactory.StageReady, wellPastT1, true, true},
+		{ActionRefund, swapfactory.StageCompleted, wellPastT1, false, false},
+	} {
+		require.Equal(t, tc.act, shouldAct(tc.action, tc.stage, swap, tc.now, margin, tc.daemonUp),
+			"action=%s stage=%d now=%s daemonUp=%v", tc.action, tc.stage, tc.now.Sub(now), tc.daemonUp)
+	}
+}
+
+func TestRegistrations(t *testing.T) {
+	kp, err := mcrypto.GenerateKeys()
+	require.NoError(t, err)
+
+	info := &pcommon.InfoFileContents{
+		ContractAddress: "0xabcd",
+		ContractSwapID:  [32]byte{9},
+		ContractSwap: swapfactory.SwapFactorySwap{
+			Timeout0: big.NewInt(1),
+			Timeout1: big.NewInt(2),
+			Value:    big.NewInt(3),
+			Nonce:    big.NewInt(4),
+		},
+		PrivateKeyInfo: kp.Info(common.Development),
+	}
+
+	_, err = NewRegistration(types.Hash{1}, &pcommon.InfoFileContents{}, ActionClaim)
+	require.ErrorIs(t, err, errNoContractSwap)
+
+	r, err := NewRegistration(types.Hash{1}, info, ActionClaim)
+	require.NoError(t, err)
+	require.Equal(t, common.Reverse(kp.SpendKey().Bytes()), r.Secret.Bytes())
+
+	dir := t.TempDir()
+	require.NoError(t, WriteRegistration(dir, r))
+	registrations, err := ReadRegistrations(dir)
+	require.NoError(t, err)
+	require.Equal(t, []*Registration{r}, registrations)
+
+	require.NoError(t, RemoveRegistration(dir, r.SwapID))
+	require.NoError(t, RemoveRegistration(dir, r.SwapID))
+	registrations, err = ReadRegistrations(dir)
+	require.NoError(t, err)
+	require.Empty(t, registrations)
+}
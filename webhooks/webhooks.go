@@ -0,0 +1,317 @@
+// Package webhooks POSTs swap events to HTTP endpoints as signed JSON, so that trading bots and
+// alerting systems can follow the daemon's swaps without holding a websocket open.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/events"
+
+	logging "github.com/ipfs/go-log"
+)
+
+var log = logging.Logger("webhooks")
+
+const (
+	// SignatureHeader is the header that payloads' signatures are sent in, as "sha256=" followed by
+	// the hex-encoded HMAC-SHA256 of the body, keyed with the webhook secret
+	SignatureHeader = "X-Swapd-Signature"
+	// EventHeader is the header that payloads' event is sent in
+	EventHeader = "X-Swapd-Event"
+
+	signaturePrefix = "sha256="
+
+	// how long a target has to respond to each delivery
+	deliveryTimeout = time.Second * 10
+	// how many times delivering a payload is attempted before it's dropped
+	deliveryAttempts = 5
+	// how many payloads can be waiting for delivery to a target before new ones are dropped, so a
+	// slow target never holds up the others
+	queueSize = 64
+)
+
+// Event is a swap status transition that's sent to webhooks.
+type Event string
+
+// The events sent to webhooks.
+const (
+	// Taken is sent when a swap taking an offer begins, whether we made or took the offer
+	Taken Event = "taken"
+	// Locked is sent when either side's funds are locked
+	Locked Event = "locked"
+	// Ready is sent when the swap contract is set to ready, so the ether can be claimed
+	Ready Event = "ready"
+	// Claimed is sent when a swap completes successfully
+	Claimed Event = "claimed"
+	// Refunded is sent when a swap is refunded
+	Refunded Event = "refunded"
+	// NeedsRecovery is sent when a swap interrupted by a restart is waiting to be recovered by hand
+	NeedsRecovery Event = "needs-recovery"
+)
+
+// AllEvents are all the events sent to webhooks.
+var AllEvents = []Event{Taken, Locked, Ready, Claimed, Refunded, NeedsRecovery}
+
+var (
+	errInvalidEvent = errors.New("invalid webhook event")
+	errNoSecret     = errors.New("webhook secret must not be empty")
+)
+
+// ParseEvents parses a comma-separated list of events, eg. "claimed,refunded".
+func ParseEvents(s string) ([]Event, error) {
+	var evts []Event
+	for _, name := range strings.Split(s, ",") {
+		e := Event(strings.TrimSpace(name))
+		if !isEvent(e) {
+			return nil, fmt.Errorf("%w: %q", errInvalidEvent, name)
+		}
+
+		evts = append(evts, e)
+	}
+
+	return evts, nil
+}
+
+func isEvent(e Event) bool {
+	for _, evt := range AllEvents {
+		if e == evt {
+			return true
+		}
+	}
+
+	return false
+}
+
+// webhookEvent returns the webhook event that's sent for the given bus event, which is empty if none is.
+func webhookEvent(t events.Type) Event {
+	switch t {
+	case events.OfferTaken:
+		return Taken
+	case events.ETHLocked, events.XMRLocked:
+		return Locked
+	case events.Ready:
+		return Ready
+	case events.Claimed:
+		return Claimed
+	case events.Refunded:
+		return Refunded
+	case events.NeedsRecovery:
+		return NeedsRecovery
+	default:
+		return ""
+	}
+}
+
+// Payload is the JSON body POSTed to webhooks.
+type Payload struct {
+	Event  Event      `json:"event"`
+	SwapID types.Hash `json:"swapID"`
+	// Status is the swap's status, eg. "ETHLocked"
+	Status string `json:"status"`
+	// PeerID is the libp2p peer ID of the swap's counterparty, if it's known
+	PeerID string    `json:"peerID,omitempty"`
+	Time   time.Time `json:"time"`
+	// Offer is the offer the swap takes; it's only set for taken events
+	Offer *types.Offer `json:"offer,omitempty"`
+}
+
+// Sign returns the signature of the body that's sent in the SignatureHeader.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns whether the signature, from the SignatureHeader, is of the body.
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Config is the config for a Notifier.
+type Config struct {
+	Ctx context.Context
+	Bus *events.Bus
+	// Targets are the URLs that payloads are POSTed to
+	Targets []string
+	// Secret is the key that payloads are signed with
+	Secret []byte
+	// Events are the events sent to the targets; if it's nil, all of them are sent
+	Events []Event
+}
+
+// Notifier POSTs the swap events published on the bus to its targets.
+type Notifier struct {
+	ctx     context.Context
+	bus     *events.Bus
+	targets []*target
+	secret  []byte
+	events  map[Event]bool
+	// how long to wait before retrying a failed delivery; it doubles with each attempt
+	retryInterval time.Duration
+}
+
+// target is a webhook URL, with the payloads waiting to be delivered to it. Each target's payloads
+// are delivered in order.
+type target struct {
+	url   string
+	queue chan *Payload
+}
+
+// NewNotifier returns a new *Notifier.
+func NewNotifier(cfg *Config) (*Notifier, error) {
+	if len(cfg.Secret) == 0 {
+		return nil, errNoSecret
+	}
+
+	evts := cfg.Events
+	if evts == nil {
+		evts = AllEvents
+	}
+
+	n := &Notifier{
+		ctx:           cfg.Ctx,
+		bus:           cfg.Bus,
+		secret:        cfg.Secret,
+		events:        make(map[Event]bool),
+		retryInterval: time.Second,
+	}
+
+	for _, e := range evts {
+		n.events[e] = true
+	}
+
+	for _, url := range cfg.Targets {
+		n.targets = append(n.targets, &target{
+			url:   url,
+			queue: make(chan *Payload, queueSize),
+		})
+	}
+
+	return n, nil
+}
+
+// Start starts sending the events published on the bus to the targets, until the context is
+// cancelled.
+func (n *Notifier) Start() {
+	ch, unsubscribe := n.bus.Subscribe()
+	go func() {
+		<-n.ctx.Done()
+		unsubscribe()
+	}()
+
+	for _, t := range n.targets {
+		go n.deliverAll(t)
+	}
+
+	go func() {
+		for e := range ch {
+			n.notify(e)
+		}
+	}()
+}
+
+// notify queues the payload for the event to each target, if the event is sent to webhooks.
+func (n *Notifier) notify(e *events.Event) {
+	evt := webhookEvent(e.Type)
+	if !n.events[evt] {
+		return
+	}
+
+	p := &Payload{
+		Event:  evt,
+		SwapID: e.SwapID,
+		Status: e.Status.String(),
+		PeerID: e.PeerID,
+		Time:   e.Time,
+		Offer:  e.Offer,
+	}
+
+	for _, t := range n.targets {
+		select {
+		case t.queue <- p:
+		default:
+			log.Warnf("dropped %s webhook for swap %s, as %s has too many pending", evt, e.SwapID, t.url)
+		}
+	}
+}
+
+// deliverAll delivers the target's payloads as they're queued.
+func (n *Notifier) deliverAll(t *target) {
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case p := <-t.queue:
+			n.deliver(t.url, p)
+		}
+	}
+}
+
+// deliver POSTs the payload to the URL, retrying with increasing delays if it fails.
+func (n *Notifier) deliver(url string, p *Payload) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Warnf("failed to encode %s webhook for swap %s: %s", p.Event, p.SwapID, err)
+		return
+	}
+
+	wait := n.retryInterval
+	for attempt := 1; ; attempt++ {
+		err = n.post(url, p.Event, body)
+		if err == nil {
+			return
+		}
+
+		if attempt == deliveryAttempts {
+			log.Warnf("failed to deliver %s webhook for swap %s to %s, giving up: %s", p.Event, p.SwapID, url, err)
+			return
+		}
+
+		log.Debugf("failed to deliver %s webhook for swap %s to %s, retrying in %s: %s",
+			p.Event, p.SwapID, url, wait, err)
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+func (n *Notifier) post(url string, evt Event, body []byte) error {
+	ctx, cancel := context.WithTimeout(n.ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(evt))
+	req.Header.Set(SignatureHeader, Sign(n.secret, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/noot/atomic-swap/common/types"
+	"github.com/noot/atomic-swap/protocol/events"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("secret")
+
+type delivery struct {
+	payload   *Payload
+	event     string
+	signature string
+	body      []byte
+}
+
+// newTarget returns a server that fails the first failures requests it receives, and sends the
+// rest to the returned channel.
+func newTarget(t *testing.T, failures int32) (*httptest.Server, <-chan *delivery) {
+	ch := make(chan *delivery, 16)
+	var received int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&received, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		d := &delivery{
+			payload:   new(Payload),
+			event:     r.Header.Get(EventHeader),
+			signature: r.Header.Get(SignatureHeader),
+			body:      body,
+		}
+		require.NoError(t, json.Unmarshal(body, d.payload))
+		ch <- d
+	}))
+	t.Cleanup(s.Close)
+	return s, ch
+}
+
+func newTestNotifier(t *testing.T, bus *events.Bus, evts []Event, targets ...string) *Notifier {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	n, err := NewNotifier(&Config{
+		Ctx:     ctx,
+		Bus:     bus,
+		Targets: targets,
+		Secret:  testSecret,
+		Events:  evts,
+	})
+	require.NoError(t, err)
+	n.retryInterval = time.Millisecond * 10
+	n.Start()
+	return n
+}
+
+func TestNotifier(t *testing.T) {
+	s, ch := newTarget(t, 0)
+	bus := events.NewBus()
+	newTestNotifier(t, bus, nil, s.URL)
+
+	bus.Publish(&events.Event{Type: events.StatusChanged, SwapID: types.Hash{1}, Status: types.KeysExchanged})
+	bus.Publish(&events.Event{Type: events.ETHLocked, SwapID: types.Hash{1}, Status: types.ETHLocked, PeerID: "a"})
+	bus.Publish(&events.Event{Type: events.NeedsRecovery, SwapID: types.Hash{2}, Status: types.XMRLocked})
+
+	// events that aren't sent to webhooks are skipped
+	d := <-ch
+	require.Equal(t, string(Locked), d.event)
+	require.Equal(t, Locked, d.payload.Event)
+	require.Equal(t, types.Hash{1}, d.payload.SwapID)
+	require.Equal(t, types.ETHLocked.String(), d.payload.Status)
+	require.Equal(t, "a", d.payload.PeerID)
+	require.True(t, Verify(testSecret, d.body, d.signature))
+	require.False(t, Verify([]byte("other"), d.body, d.signature))
+
+	d = <-ch
+	require.Equal(t, NeedsRecovery, d.payload.Event)
+	require.Equal(t, types.Hash{2}, d.payload.SwapID)
+}
+
+func TestNotifier_Events(t *testing.T) {
+	s, ch := newTarget(t, 0)
+	bus := events.NewBus()
+	newTestNotifier(t, bus, []Event{Claimed}, s.URL)
+
+	bus.Publish(&events.Event{Type: events.Refunded, SwapID: types.Hash{1}, Status: types.CompletedRefund})
+	bus.Publish(&events.Event{Type: events.Claimed, SwapID: types.Hash{2}, Status: types.CompletedSuccess})
+
+	d := <-ch
+	require.Equal(t, Claimed, d.payload.Event)
+	require.Equal(t, types.Hash{2}, d.payload.SwapID)
+}
+
+func TestNotifier_Retry(t *testing.T) {
+	failing, failingCh := newTarget(t, 2)
+	working, workingCh := newTarget(t, 0)
+	bus := events.NewBus()
+	newTestNotifier(t, bus, nil, failing.URL, working.URL)
+
+	bus.Publish(&events.Event{Type: events.Ready, SwapID: types.Hash{1}, Status: types.ContractReady})
+	for _, ch := range []<-chan *delivery{failingCh, workingCh} {
+		select {
+		case d := <-ch:
+			require.Equal(t, Ready, d.payload.Event)
+		case <-time.After(time.Second * 5):
+			t.Fatal("webhook wasn't delivered")
+		}
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	evts, err := ParseEvents("claimed, needs-recovery")
+	require.NoError(t, err)
+	require.Equal(t, []Event{Claimed, NeedsRecovery}, evts)
+
+	_, err = ParseEvents("claimed,aborted")
+	require.ErrorIs(t, err, errInvalidEvent)
+}
+
+func TestNewNotifier_NoSecret(t *testing.T) {
+	_, err := NewNotifier(&Config{Ctx: context.Background(), Bus: events.NewBus()})
+	require.Equal(t, errNoSecret, err)
+}